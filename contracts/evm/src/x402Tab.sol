@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+pragma solidity ^0.8.20;
+
+/// @notice EIP-3009 receiveWithAuthorization, as implemented by USDC
+interface IERC3009 {
+    function receiveWithAuthorization(
+        address from,
+        address to,
+        uint256 value,
+        uint256 validAfter,
+        uint256 validBefore,
+        bytes32 nonce,
+        bytes memory signature
+    ) external;
+}
+
+interface IERC20 {
+    function transfer(address to, uint256 value) external returns (bool);
+}
+
+interface IERC1271 {
+    function isValidSignature(bytes32 hash, bytes memory signature) external view returns (bytes4);
+}
+
+/// @title x402Tab
+/// @notice Payment channels for the x402 `tab` scheme (specs/schemes/tab).
+/// An owner deposits tokens into a tab for one payee, then pays per request
+/// with EIP-712 vouchers for the tab's running total. Closing the tab with
+/// the latest voucher pays the payee its amount and refunds the rest; after
+/// expiry, a tab that was never closed can be reclaimed by its owner.
+contract x402Tab {
+    struct Tab {
+        address token;
+        address owner;
+        address payee;
+        address operator;
+        uint256 deposit;
+        uint256 expiry;
+        bool closed;
+    }
+
+    bytes32 private constant DOMAIN_TYPEHASH =
+        keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)");
+    bytes32 private constant VOUCHER_TYPEHASH = keccak256("Voucher(bytes32 channelId,uint256 amount)");
+    bytes32 private constant NAME_HASH = keccak256("x402 Tab");
+    bytes32 private constant VERSION_HASH = keccak256("1");
+
+    // secp256k1n / 2, the largest s of a non-malleable signature
+    uint256 private constant MAX_S = 0x7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF5D576E7357A4501DDFE92F46681B20A0;
+
+    mapping(bytes32 => Tab) public tabs;
+
+    event TabOpened(bytes32 indexed channelId, address indexed owner, address indexed payee, address token, uint256 deposit, uint256 expiry);
+    event TabClosed(bytes32 indexed channelId, uint256 amount, uint256 refund);
+    event TabReclaimed(bytes32 indexed channelId, uint256 refund);
+
+    error TabExists();
+    error UnknownTab();
+    error TabIsClosed();
+    error TabExpired();
+    error TabNotExpired();
+    error NotPayeeOrOperator();
+    error AmountExceedsDeposit();
+    error InvalidSignature();
+    error TransferFailed();
+
+    /// @notice The id of a tab, which is also the nonce of its deposit
+    /// authorization, so the payee and expiry are fixed by the owner's signature
+    function channelId(address token, address payee, uint256 expiry, bytes32 salt) public pure returns (bytes32) {
+        return keccak256(abi.encode(token, payee, expiry, salt));
+    }
+
+    /// @notice Opens a tab, pulling the deposit with the owner's EIP-3009
+    /// receiveWithAuthorization. The caller becomes the tab's operator and
+    /// may close it alongside the payee.
+    function open(
+        address token,
+        address owner,
+        address payee,
+        uint256 deposit,
+        uint256 expiry,
+        uint256 validAfter,
+        uint256 validBefore,
+        bytes32 salt,
+        bytes calldata signature
+    ) external {
+        bytes32 id = channelId(token, payee, expiry, salt);
+        if (tabs[id].owner != address(0)) revert TabExists();
+        if (expiry <= block.timestamp) revert TabExpired();
+
+        tabs[id] = Tab({
+            token: token,
+            owner: owner,
+            payee: payee,
+            operator: msg.sender,
+            deposit: deposit,
+            expiry: expiry,
+            closed: false
+        });
+        IERC3009(token).receiveWithAuthorization(owner, address(this), deposit, validAfter, validBefore, id, signature);
+
+        emit TabOpened(id, owner, payee, token, deposit, expiry);
+    }
+
+    /// @notice Closes a tab with the owner's voucher for `amount`, paying the
+    /// payee and refunding the rest of the deposit to the owner. Only the
+    /// payee or the operator can close, so the owner cannot close with an
+    /// older, lower voucher.
+    function close(bytes32 id, uint256 amount, bytes calldata signature) external {
+        Tab storage tab = tabs[id];
+        if (tab.owner == address(0)) revert UnknownTab();
+        if (tab.closed) revert TabIsClosed();
+        if (msg.sender != tab.payee && msg.sender != tab.operator) revert NotPayeeOrOperator();
+        if (amount > tab.deposit) revert AmountExceedsDeposit();
+        if (!_isValidSignature(tab.owner, voucherHash(id, amount), signature)) revert InvalidSignature();
+
+        tab.closed = true;
+        uint256 refund = tab.deposit - amount;
+        _transfer(tab.token, tab.payee, amount);
+        _transfer(tab.token, tab.owner, refund);
+
+        emit TabClosed(id, amount, refund);
+    }
+
+    /// @notice Refunds the whole deposit of a tab that expired unclosed
+    function reclaim(bytes32 id) external {
+        Tab storage tab = tabs[id];
+        if (tab.owner == address(0)) revert UnknownTab();
+        if (tab.closed) revert TabIsClosed();
+        if (block.timestamp < tab.expiry) revert TabNotExpired();
+
+        tab.closed = true;
+        _transfer(tab.token, tab.owner, tab.deposit);
+
+        emit TabReclaimed(id, tab.deposit);
+    }
+
+    /// @notice The EIP-712 hash of a voucher
+    function voucherHash(bytes32 id, uint256 amount) public view returns (bytes32) {
+        bytes32 domainSeparator = keccak256(abi.encode(DOMAIN_TYPEHASH, NAME_HASH, VERSION_HASH, block.chainid, address(this)));
+        bytes32 structHash = keccak256(abi.encode(VOUCHER_TYPEHASH, id, amount));
+        return keccak256(abi.encodePacked("\x19\x01", domainSeparator, structHash));
+    }
+
+    function _transfer(address token, address to, uint256 amount) private {
+        if (amount == 0) return;
+        if (!IERC20(token).transfer(to, amount)) revert TransferFailed();
+    }
+
+    /// @dev ERC-1271 for contract owners, ECDSA otherwise
+    function _isValidSignature(address signer, bytes32 hash, bytes calldata signature) private view returns (bool) {
+        if (signer.code.length > 0) {
+            try IERC1271(signer).isValidSignature(hash, signature) returns (bytes4 magic) {
+                return magic == IERC1271.isValidSignature.selector;
+            } catch {
+                return false;
+            }
+        }
+        if (signature.length != 65) return false;
+        bytes32 r = bytes32(signature[0:32]);
+        bytes32 s = bytes32(signature[32:64]);
+        uint8 v = uint8(signature[64]);
+        if (v < 27) v += 27;
+        if (uint256(s) > MAX_S) return false;
+        address recovered = ecrecover(hash, v, r, s);
+        return recovered != address(0) && recovered == signer;
+    }
+}
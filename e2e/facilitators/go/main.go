@@ -550,7 +550,7 @@ func (s *realFacilitatorSvmSigner) SimulateTransaction(ctx context.Context, tx *
 	opts := rpc.SimulateTransactionOpts{
 		SigVerify:              true,
 		ReplaceRecentBlockhash: false,
-		Commitment:             svmmech.DefaultCommitment,
+		Commitment:             svmmech.CommitmentFromContext(ctx),
 	}
 
 	simResult, err := rpcClient.SimulateTransactionWithOpts(ctx, tx, &opts)
@@ -573,7 +573,7 @@ func (s *realFacilitatorSvmSigner) SendTransaction(ctx context.Context, tx *sola
 
 	sig, err := rpcClient.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
 		SkipPreflight:       true,
-		PreflightCommitment: svmmech.DefaultCommitment,
+		PreflightCommitment: svmmech.CommitmentFromContext(ctx),
 	})
 	if err != nil {
 		return solana.Signature{}, fmt.Errorf("failed to send transaction: %w", err)
@@ -604,8 +604,7 @@ func (s *realFacilitatorSvmSigner) ConfirmTransaction(ctx context.Context, signa
 				if status.Err != nil {
 					return fmt.Errorf("transaction failed on-chain")
 				}
-				if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
-					status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				if svmmech.CommitmentReached(status.ConfirmationStatus, svmmech.CommitmentFromContext(ctx)) {
 					return nil
 				}
 			}
@@ -615,7 +614,7 @@ func (s *realFacilitatorSvmSigner) ConfirmTransaction(ctx context.Context, signa
 		if err != nil {
 			txResult, txErr := rpcClient.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
 				Encoding:   solana.EncodingBase58,
-				Commitment: svmmech.DefaultCommitment,
+				Commitment: svmmech.CommitmentFromContext(ctx),
 			})
 
 			if txErr == nil && txResult != nil && txResult.Meta != nil {
@@ -765,7 +764,7 @@ func main() {
 	facilitator.RegisterV1([]x402.Network{x402.Network(getV1EvmNetwork(evmNetwork))}, evmFacilitatorV1Scheme)
 
 	// Register SVM schemes with dynamic network
-	svmFacilitatorScheme := svm.NewExactSvmScheme(svmSigner)
+	svmFacilitatorScheme := svm.NewExactSvmScheme(svmSigner, nil)
 	facilitator.Register([]x402.Network{x402.Network(svmNetwork)}, svmFacilitatorScheme)
 
 	svmFacilitatorV1Scheme := svmv1.NewExactSvmSchemeV1(svmSigner)
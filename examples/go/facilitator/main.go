@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/costs"
+	x402http "github.com/coinbase/x402/go/http"
 	evm "github.com/coinbase/x402/go/mechanisms/evm/exact/facilitator"
 	evmv1 "github.com/coinbase/x402/go/mechanisms/evm/exact/v1/facilitator"
 	svm "github.com/coinbase/x402/go/mechanisms/svm/exact/facilitator"
 	svmv1 "github.com/coinbase/x402/go/mechanisms/svm/exact/v1/facilitator"
+	"github.com/coinbase/x402/go/x402test"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -61,10 +65,24 @@ func main() {
 	facilitator.RegisterV1([]x402.Network{"base-sepolia"}, evmv1.NewExactEvmSchemeV1(evmSigner, evmV1Config))
 
 	if svmSigner != nil {
-		facilitator.Register([]x402.Network{svmNetwork}, svm.NewExactSvmScheme(svmSigner))
+		facilitator.Register([]x402.Network{svmNetwork}, svm.NewExactSvmScheme(svmSigner, nil))
 		facilitator.RegisterV1([]x402.Network{"solana-devnet"}, svmv1.NewExactSvmSchemeV1(svmSigner))
 	}
 
+	// Quote a fee on top of payments when FACILITATOR_FEE_BPS is set (e.g. 50 = 0.5%)
+	if feeBps, err := strconv.ParseInt(os.Getenv("FACILITATOR_FEE_BPS"), 10, 64); err == nil && feeBps > 0 {
+		facilitator.SetFeeQuoter(x402.BasisPointsFeeQuoter(feeBps, 5*time.Minute))
+	}
+
+	// Sandbox mode: refuse mainnet payments and fund empty test wallets
+	if os.Getenv("TEST_MODE") == "true" {
+		faucets := map[x402.Network]x402.Faucet{svmNetwork: x402test.NewSolanaAirdropFaucet(DefaultSvmRPC, 0)}
+		if circleAPIKey := os.Getenv("CIRCLE_API_KEY"); circleAPIKey != "" {
+			faucets[evmNetwork] = x402test.NewCircleFaucet(circleAPIKey)
+		}
+		facilitator.EnableTestMode(x402.TestModeConfig{Faucets: faucets})
+	}
+
 	facilitator.OnAfterVerify(func(ctx x402.FacilitatorVerifyResultContext) error {
 		fmt.Printf("✅ Payment verified\n")
 		return nil
@@ -75,6 +93,10 @@ func main() {
 		return nil
 	})
 
+	// Track what settlements cost the facilitator per merchant
+	costLedger := costs.NewLedger(costs.Config{})
+	facilitator.OnAfterSettle(costLedger.AfterSettle)
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
@@ -86,6 +108,11 @@ func main() {
 		c.JSON(http.StatusOK, supported)
 	})
 
+	// Costs endpoint - returns settlement gas and fees per merchant
+	r.GET("/costs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, costLedger.Totals())
+	})
+
 	// Verify endpoint - verifies payment signatures
 	r.POST("/verify", func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
@@ -128,6 +155,7 @@ func main() {
 		var reqBody struct {
 			PaymentPayload      json.RawMessage `json:"paymentPayload"`
 			PaymentRequirements json.RawMessage `json:"paymentRequirements"`
+			AmountToSettle      string          `json:"amountToSettle,omitempty"`
 		}
 
 		if err := c.BindJSON(&reqBody); err != nil {
@@ -135,8 +163,14 @@ func main() {
 			return
 		}
 
-		// Settle payment
-		result, err := facilitator.Settle(ctx, reqBody.PaymentPayload, reqBody.PaymentRequirements)
+		// Settle payment, for less than the authorized amount if requested
+		var result *x402.SettleResponse
+		var err error
+		if reqBody.AmountToSettle != "" {
+			result, err = facilitator.SettlePartial(ctx, reqBody.PaymentPayload, reqBody.PaymentRequirements, reqBody.AmountToSettle)
+		} else {
+			result, err = facilitator.Settle(ctx, reqBody.PaymentPayload, reqBody.PaymentRequirements)
+		}
 		if err != nil {
 			// All failures (business logic and system errors) are returned as errors
 			// You can extract structured information from SettleError if needed:
@@ -152,6 +186,66 @@ func main() {
 		c.JSON(http.StatusOK, result)
 	})
 
+	// Settlement status endpoint - reports the outcome of pending settlements
+	r.GET("/settle/status", gin.WrapH(x402http.SettlementStatusHandler(facilitator)))
+
+	// Simulate endpoint - verifies payments and projects settlement cost without settling
+	r.POST("/simulate", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		// Read request body
+		var reqBody struct {
+			PaymentPayload      json.RawMessage `json:"paymentPayload"`
+			PaymentRequirements json.RawMessage `json:"paymentRequirements"`
+		}
+
+		if err := c.BindJSON(&reqBody); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		// Simulate settlement
+		result, err := facilitator.Simulate(ctx, reqBody.PaymentPayload, reqBody.PaymentRequirements)
+		if err != nil {
+			// Return the reason so clients get a typed VerifyError
+			if ve, ok := err.(*x402.VerifyError); ok {
+				c.JSON(http.StatusBadRequest, x402.SimulateResponse{
+					InvalidReason:  ve.InvalidReason,
+					InvalidMessage: ve.InvalidMessage,
+					Payer:          ve.Payer,
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Success! result carries the projected gas and fee
+		c.JSON(http.StatusOK, result)
+	})
+
+	// Quote endpoint - returns the facilitator's fee for a payment
+	r.POST("/quote", func(c *gin.Context) {
+		var request x402.FeeQuoteRequest
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		quote, err := facilitator.QuoteFee(c.Request.Context(), request)
+		if err != nil {
+			if pe, ok := err.(*x402.PaymentError); ok {
+				c.JSON(http.StatusBadRequest, pe)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, quote)
+	})
+
 	fmt.Printf("🚀 Facilitator listening on http://localhost:%s\n", DefaultPort)
 	fmt.Printf("   EVM: %s on %s\n", evmSigner.GetAddresses()[0], evmNetwork)
 	if svmSigner != nil {
@@ -159,7 +253,15 @@ func main() {
 	}
 	fmt.Println()
 
-	if err := r.Run(":" + DefaultPort); err != nil {
+	// Shed load before it reaches the chain RPC: at most 64 requests at once,
+	// 256 queued, and 600 requests per minute per API key or IP
+	shedder := x402http.NewLoadShedder(x402http.LoadSheddingConfig{
+		MaxConcurrent: 64,
+		MaxQueue:      256,
+		Quota:         600,
+	})
+
+	if err := http.ListenAndServe(":"+DefaultPort, shedder.Handler(r)); err != nil {
 		fmt.Printf("Error starting server: %v\n", err)
 		os.Exit(1)
 	}
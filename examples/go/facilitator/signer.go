@@ -295,6 +295,37 @@ func (s *facilitatorEvmSigner) WriteContract(
 	return signedTx.Hash().Hex(), nil
 }
 
+// EstimateContractGas implements evm.GasEstimator for the settlement gas preflight
+func (s *facilitatorEvmSigner) EstimateContractGas(
+	ctx context.Context,
+	contractAddress string,
+	abiJSON []byte,
+	method string,
+	args ...interface{},
+) (uint64, error) {
+	contractABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack method call: %w", err)
+	}
+
+	to := common.HexToAddress(contractAddress)
+	return s.client.EstimateGas(ctx, ethereum.CallMsg{
+		From: s.address,
+		To:   &to,
+		Data: data,
+	})
+}
+
+// SuggestGasPrice implements evm.GasPriceReader for settlement simulation
+func (s *facilitatorEvmSigner) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return s.client.SuggestGasPrice(ctx)
+}
+
 func (s *facilitatorEvmSigner) SendTransaction(
 	ctx context.Context,
 	to string,
@@ -346,9 +377,11 @@ func (s *facilitatorEvmSigner) WaitForTransactionReceipt(ctx context.Context, tx
 		receipt, err := s.client.TransactionReceipt(ctx, hash)
 		if err == nil && receipt != nil {
 			return &evmmech.TransactionReceipt{
-				Status:      uint64(receipt.Status),
-				BlockNumber: receipt.BlockNumber.Uint64(),
-				TxHash:      receipt.TxHash.Hex(),
+				Status:            uint64(receipt.Status),
+				BlockNumber:       receipt.BlockNumber.Uint64(),
+				TxHash:            receipt.TxHash.Hex(),
+				GasUsed:           receipt.GasUsed,
+				EffectiveGasPrice: receipt.EffectiveGasPrice,
 			}, nil
 		}
 		time.Sleep(1 * time.Second)
@@ -485,7 +518,7 @@ func (s *facilitatorSvmSigner) SimulateTransaction(ctx context.Context, tx *sola
 	opts := rpc.SimulateTransactionOpts{
 		SigVerify:              true,
 		ReplaceRecentBlockhash: false,
-		Commitment:             svmmech.DefaultCommitment,
+		Commitment:             svmmech.CommitmentFromContext(ctx),
 	}
 
 	simResult, err := rpcClient.SimulateTransactionWithOpts(ctx, tx, &opts)
@@ -508,7 +541,7 @@ func (s *facilitatorSvmSigner) SendTransaction(ctx context.Context, tx *solana.T
 
 	sig, err := rpcClient.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
 		SkipPreflight:       true,
-		PreflightCommitment: svmmech.DefaultCommitment,
+		PreflightCommitment: svmmech.CommitmentFromContext(ctx),
 	})
 	if err != nil {
 		return solana.Signature{}, fmt.Errorf("failed to send transaction: %w", err)
@@ -539,8 +572,7 @@ func (s *facilitatorSvmSigner) ConfirmTransaction(ctx context.Context, signature
 				if status.Err != nil {
 					return fmt.Errorf("transaction failed on-chain")
 				}
-				if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
-					status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				if svmmech.CommitmentReached(status.ConfirmationStatus, svmmech.CommitmentFromContext(ctx)) {
 					return nil
 				}
 			}
@@ -550,7 +582,7 @@ func (s *facilitatorSvmSigner) ConfirmTransaction(ctx context.Context, signature
 		if err != nil {
 			txResult, txErr := rpcClient.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
 				Encoding:   solana.EncodingBase58,
-				Commitment: svmmech.DefaultCommitment,
+				Commitment: svmmech.CommitmentFromContext(ctx),
 			})
 
 			if txErr == nil && txResult != nil && txResult.Meta != nil {
@@ -568,6 +600,33 @@ func (s *facilitatorSvmSigner) ConfirmTransaction(ctx context.Context, signature
 	return fmt.Errorf("transaction confirmation timed out after %d attempts", svmmech.MaxConfirmAttempts)
 }
 
+// GetTransactionFee reads the fee and compute units of a confirmed transaction
+func (s *facilitatorSvmSigner) GetTransactionFee(ctx context.Context, signature solana.Signature, network string) (uint64, uint64, error) {
+	rpcClient, err := s.getRPC(ctx, network)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	maxVersion := uint64(0)
+	txResult, err := rpcClient.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase58,
+		Commitment:                     svmmech.CommitmentFromContext(ctx),
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if txResult == nil || txResult.Meta == nil {
+		return 0, 0, fmt.Errorf("transaction metadata not found")
+	}
+
+	var computeUnits uint64
+	if txResult.Meta.ComputeUnitsConsumed != nil {
+		computeUnits = *txResult.Meta.ComputeUnitsConsumed
+	}
+	return txResult.Meta.Fee, computeUnits, nil
+}
+
 func (s *facilitatorSvmSigner) GetAddresses(ctx context.Context, network string) []solana.PublicKey {
 	return []solana.PublicKey{s.privateKey.PublicKey()}
 }
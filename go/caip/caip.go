@@ -0,0 +1,237 @@
+// Package caip parses and formats chain-agnostic identifiers: CAIP-2 chain IDs
+// ("eip155:8453"), CAIP-10 account IDs ("eip155:8453:0xabc..."), and CAIP-19
+// asset IDs ("eip155:8453/erc20:0x833..."). The eip155 and solana namespaces
+// get additional validation of their references and addresses.
+package caip
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Namespaces
+const (
+	NamespaceEIP155 = "eip155"
+	NamespaceSolana = "solana"
+)
+
+// Asset namespaces
+const (
+	AssetNamespaceERC20  = "erc20"
+	AssetNamespaceToken  = "token" // Solana SPL tokens
+	AssetNamespaceSlip44 = "slip44"
+)
+
+var (
+	namespacePattern      = regexp.MustCompile(`^[-a-z0-9]{3,8}$`)
+	referencePattern      = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
+	addressPattern        = regexp.MustCompile(`^[-.%a-zA-Z0-9]{1,128}$`)
+	assetReferencePattern = regexp.MustCompile(`^[-.%a-zA-Z0-9]{1,128}$`)
+
+	eip155ReferencePattern = regexp.MustCompile(`^[1-9][0-9]*$`)
+	eip155AddressPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	base58Pattern          = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]+$`)
+)
+
+// ============================================================================
+// CAIP-2 Chain ID
+// ============================================================================
+
+// ChainID is a CAIP-2 blockchain identifier
+type ChainID struct {
+	Namespace string
+	Reference string
+}
+
+// ParseChainID parses a CAIP-2 chain ID such as "eip155:8453"
+func ParseChainID(s string) (ChainID, error) {
+	namespace, reference, ok := strings.Cut(s, ":")
+	if !ok {
+		return ChainID{}, fmt.Errorf("invalid CAIP-2 chain ID %q: expected namespace:reference", s)
+	}
+	chain := ChainID{Namespace: namespace, Reference: reference}
+	if err := chain.Validate(); err != nil {
+		return ChainID{}, err
+	}
+	return chain, nil
+}
+
+// EIP155 returns the CAIP-2 chain ID of an EVM chain
+func EIP155(chainID *big.Int) ChainID {
+	return ChainID{Namespace: NamespaceEIP155, Reference: chainID.String()}
+}
+
+// String formats the chain ID as namespace:reference
+func (c ChainID) String() string {
+	return c.Namespace + ":" + c.Reference
+}
+
+// IsEVM reports whether the chain is in the eip155 namespace
+func (c ChainID) IsEVM() bool {
+	return c.Namespace == NamespaceEIP155
+}
+
+// IsSolana reports whether the chain is in the solana namespace
+func (c ChainID) IsSolana() bool {
+	return c.Namespace == NamespaceSolana
+}
+
+// EVMChainID returns the numeric chain ID of an eip155 chain
+func (c ChainID) EVMChainID() (*big.Int, error) {
+	if !c.IsEVM() {
+		return nil, fmt.Errorf("%s is not an eip155 chain", c)
+	}
+	chainID, ok := new(big.Int).SetString(c.Reference, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid eip155 chain reference: %s", c.Reference)
+	}
+	return chainID, nil
+}
+
+// Validate checks the chain ID against CAIP-2 and, for known namespaces, the
+// namespace's reference format
+func (c ChainID) Validate() error {
+	if !namespacePattern.MatchString(c.Namespace) {
+		return fmt.Errorf("invalid CAIP-2 namespace %q", c.Namespace)
+	}
+	if !referencePattern.MatchString(c.Reference) {
+		return fmt.Errorf("invalid CAIP-2 reference %q", c.Reference)
+	}
+	switch c.Namespace {
+	case NamespaceEIP155:
+		if !eip155ReferencePattern.MatchString(c.Reference) {
+			return fmt.Errorf("invalid eip155 chain reference %q: expected a decimal chain ID", c.Reference)
+		}
+	case NamespaceSolana:
+		if !base58Pattern.MatchString(c.Reference) {
+			return fmt.Errorf("invalid solana chain reference %q: expected a base58 genesis hash", c.Reference)
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// CAIP-10 Account ID
+// ============================================================================
+
+// AccountID is a CAIP-10 account identifier
+type AccountID struct {
+	ChainID ChainID
+	Address string
+}
+
+// ParseAccountID parses a CAIP-10 account ID such as "eip155:8453:0xabc..."
+func ParseAccountID(s string) (AccountID, error) {
+	separator := strings.LastIndex(s, ":")
+	if separator < 0 {
+		return AccountID{}, fmt.Errorf("invalid CAIP-10 account ID %q: expected chain_id:address", s)
+	}
+	chain, err := ParseChainID(s[:separator])
+	if err != nil {
+		return AccountID{}, fmt.Errorf("invalid CAIP-10 account ID %q: %w", s, err)
+	}
+	account := AccountID{ChainID: chain, Address: s[separator+1:]}
+	if err := account.Validate(); err != nil {
+		return AccountID{}, err
+	}
+	return account, nil
+}
+
+// String formats the account ID as chain_id:address
+func (a AccountID) String() string {
+	return a.ChainID.String() + ":" + a.Address
+}
+
+// Validate checks the address against CAIP-10 and the chain's address format
+func (a AccountID) Validate() error {
+	if !addressPattern.MatchString(a.Address) {
+		return fmt.Errorf("invalid CAIP-10 address %q", a.Address)
+	}
+	switch a.ChainID.Namespace {
+	case NamespaceEIP155:
+		if !eip155AddressPattern.MatchString(a.Address) {
+			return fmt.Errorf("invalid eip155 address %q", a.Address)
+		}
+	case NamespaceSolana:
+		if !isSolanaAddress(a.Address) {
+			return fmt.Errorf("invalid solana address %q", a.Address)
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// CAIP-19 Asset ID
+// ============================================================================
+
+// AssetID is a CAIP-19 asset type identifier
+type AssetID struct {
+	ChainID        ChainID
+	AssetNamespace string
+	AssetReference string
+}
+
+// ParseAssetID parses a CAIP-19 asset ID such as "eip155:8453/erc20:0x833..."
+func ParseAssetID(s string) (AssetID, error) {
+	chainPart, assetPart, ok := strings.Cut(s, "/")
+	if !ok {
+		return AssetID{}, fmt.Errorf("invalid CAIP-19 asset ID %q: expected chain_id/asset_namespace:asset_reference", s)
+	}
+	chain, err := ParseChainID(chainPart)
+	if err != nil {
+		return AssetID{}, fmt.Errorf("invalid CAIP-19 asset ID %q: %w", s, err)
+	}
+	assetNamespace, assetReference, ok := strings.Cut(assetPart, ":")
+	if !ok {
+		return AssetID{}, fmt.Errorf("invalid CAIP-19 asset ID %q: expected asset_namespace:asset_reference", s)
+	}
+	asset := AssetID{ChainID: chain, AssetNamespace: assetNamespace, AssetReference: assetReference}
+	if err := asset.Validate(); err != nil {
+		return AssetID{}, err
+	}
+	return asset, nil
+}
+
+// ERC20 returns the CAIP-19 asset ID of an ERC-20 token
+func ERC20(chain ChainID, address string) AssetID {
+	return AssetID{ChainID: chain, AssetNamespace: AssetNamespaceERC20, AssetReference: address}
+}
+
+// SPLToken returns the CAIP-19 asset ID of a Solana SPL token mint
+func SPLToken(chain ChainID, mint string) AssetID {
+	return AssetID{ChainID: chain, AssetNamespace: AssetNamespaceToken, AssetReference: mint}
+}
+
+// String formats the asset ID as chain_id/asset_namespace:asset_reference
+func (a AssetID) String() string {
+	return a.ChainID.String() + "/" + a.AssetNamespace + ":" + a.AssetReference
+}
+
+// Validate checks the asset against CAIP-19 and, for token assets on known
+// namespaces, the token address format
+func (a AssetID) Validate() error {
+	if !namespacePattern.MatchString(a.AssetNamespace) {
+		return fmt.Errorf("invalid CAIP-19 asset namespace %q", a.AssetNamespace)
+	}
+	if !assetReferencePattern.MatchString(a.AssetReference) {
+		return fmt.Errorf("invalid CAIP-19 asset reference %q", a.AssetReference)
+	}
+	switch {
+	case a.ChainID.IsEVM() && a.AssetNamespace == AssetNamespaceERC20:
+		if !eip155AddressPattern.MatchString(a.AssetReference) {
+			return fmt.Errorf("invalid erc20 token address %q", a.AssetReference)
+		}
+	case a.ChainID.IsSolana() && a.AssetNamespace == AssetNamespaceToken:
+		if !isSolanaAddress(a.AssetReference) {
+			return fmt.Errorf("invalid solana token mint %q", a.AssetReference)
+		}
+	}
+	return nil
+}
+
+// isSolanaAddress checks the base58 shape of a Solana public key (32-44 characters)
+func isSolanaAddress(address string) bool {
+	return len(address) >= 32 && len(address) <= 44 && base58Pattern.MatchString(address)
+}
@@ -0,0 +1,123 @@
+package caip
+
+import (
+	"math/big"
+	"testing"
+)
+
+const (
+	usdcBase      = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	solanaMainnet = "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"
+	usdcSolana    = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+func TestParseChainID(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"eip155:8453", false},
+		{solanaMainnet, false},
+		{"cosmos:cosmoshub-4", false},
+		{"eip155", true},
+		{"eip155:", true},
+		{"eip155:base", true},
+		{"eip155:08453", true},
+		{"EIP155:1", true},
+		{"ab:1", true},
+		{"solana:0OIl", true},
+		{"eip155:1:0xabc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			chain, err := ParseChainID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChainID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && chain.String() != tt.input {
+				t.Errorf("expected round trip to %q, got %q", tt.input, chain.String())
+			}
+		})
+	}
+}
+
+func TestChainIDEVMChainID(t *testing.T) {
+	chain, err := ParseChainID("eip155:8453")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	id, err := chain.EVMChainID()
+	if err != nil || id.Int64() != 8453 {
+		t.Fatalf("expected 8453, got %v (%v)", id, err)
+	}
+	if EIP155(big.NewInt(8453)) != chain {
+		t.Errorf("expected EIP155(8453) to equal %v", chain)
+	}
+
+	solana, _ := ParseChainID(solanaMainnet)
+	if _, err := solana.EVMChainID(); err == nil {
+		t.Error("expected error for non-eip155 chain")
+	}
+}
+
+func TestParseAccountID(t *testing.T) {
+	account, err := ParseAccountID("eip155:8453:" + usdcBase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.ChainID.Reference != "8453" || account.Address != usdcBase {
+		t.Errorf("unexpected account %+v", account)
+	}
+
+	if _, err := ParseAccountID(solanaMainnet + ":" + usdcSolana); err != nil {
+		t.Errorf("unexpected error for solana account: %v", err)
+	}
+
+	for _, invalid := range []string{
+		"eip155:8453",
+		"eip155:8453:0x1234",
+		solanaMainnet + ":0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		"0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+	} {
+		if _, err := ParseAccountID(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestParseAssetID(t *testing.T) {
+	input := "eip155:8453/erc20:" + usdcBase
+	asset, err := ParseAssetID(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asset != ERC20(ChainID{Namespace: NamespaceEIP155, Reference: "8453"}, usdcBase) {
+		t.Errorf("unexpected asset %+v", asset)
+	}
+	if asset.String() != input {
+		t.Errorf("expected round trip to %q, got %q", input, asset.String())
+	}
+
+	solana, _ := ParseChainID(solanaMainnet)
+	spl, err := ParseAssetID(solanaMainnet + "/token:" + usdcSolana)
+	if err != nil || spl != SPLToken(solana, usdcSolana) {
+		t.Errorf("unexpected SPL asset %+v (%v)", spl, err)
+	}
+
+	if _, err := ParseAssetID("eip155:1/slip44:60"); err != nil {
+		t.Errorf("unexpected error for slip44 asset: %v", err)
+	}
+
+	for _, invalid := range []string{
+		"eip155:8453",
+		"eip155:8453/erc20",
+		"eip155:8453/erc20:0x1234",
+		solanaMainnet + "/token:not-a-mint",
+		"eip155:base/erc20:" + usdcBase,
+	} {
+		if _, err := ParseAssetID(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/coinbase/x402/go/types"
 )
@@ -25,6 +26,9 @@ type x402Client struct {
 	beforePaymentCreationHooks    []BeforePaymentCreationHook
 	afterPaymentCreationHooks     []AfterPaymentCreationHook
 	onPaymentCreationFailureHooks []OnPaymentCreationFailureHook
+
+	// approval confirms large payments before signing (see RequireApproval)
+	approval *ApprovalConfig
 }
 
 // ClientOption configures the client
@@ -209,6 +213,10 @@ func (c *x402Client) CreatePaymentPayloadV1(
 	ctx context.Context,
 	requirements types.PaymentRequirementsV1,
 ) (types.PaymentPayloadV1, error) {
+	if err := c.awaitApproval(ctx, requirements); err != nil {
+		return types.PaymentPayloadV1{}, err
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -243,6 +251,17 @@ func (c *x402Client) CreatePaymentPayload(
 	resource *types.ResourceInfo,
 	extensions map[string]interface{},
 ) (types.PaymentPayload, error) {
+	if requirements.Expired(time.Now()) {
+		return types.PaymentPayload{}, &PaymentError{
+			Code:    ErrCodePaymentExpired,
+			Message: fmt.Sprintf("payment requirements expired at %d", requirements.ExpiresAt),
+		}
+	}
+
+	if err := c.awaitApproval(ctx, requirements); err != nil {
+		return types.PaymentPayload{}, err
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -272,10 +291,17 @@ func (c *x402Client) CreatePaymentPayload(
 		return types.PaymentPayload{}, err
 	}
 
+	// Commit to the accepted requirements so servers can check they were offered unchanged
+	requirementsHash, err := types.RequirementsHash(requirements)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
 	// Wrap with accepted/resource/extensions
 	partial.Accepted = requirements
 	partial.Resource = resource
 	partial.Extensions = extensions
+	partial.RequirementsHash = requirementsHash
 
 	return partial, nil
 }
@@ -0,0 +1,113 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ============================================================================
+// Payment Approval
+// ============================================================================
+
+// defaultApprovalTimeout is how long a payment waits for approval by default
+const defaultApprovalTimeout = 5 * time.Minute
+
+// ApprovalFunc confirms a payment before it is signed, e.g. by asking an
+// operator through a chat bot or CLI prompt. It may block until an answer
+// arrives; ctx is cancelled when the approval times out.
+type ApprovalFunc func(ctx context.Context, requirements PaymentRequirementsView) (bool, error)
+
+// ApprovalConfig requires approval for large payments
+type ApprovalConfig struct {
+	// Threshold is the amount in atomic units above which payments need
+	// approval (empty = every payment)
+	Threshold string
+
+	// Timeout is how long to wait for approval before the payment is denied
+	// (default: 5 minutes)
+	Timeout time.Duration
+
+	// Approve confirms or denies payments
+	Approve ApprovalFunc
+}
+
+// WithApproval requires approval for payments above a threshold at creation time
+func WithApproval(config ApprovalConfig) ClientOption {
+	return func(c *x402Client) {
+		c.approval = &config
+	}
+}
+
+// RequireApproval requires approval for payments above a threshold. Payments
+// that are denied or time out fail with ErrCodePaymentNotApproved.
+func (c *x402Client) RequireApproval(config ApprovalConfig) *x402Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.approval = &config
+	return c
+}
+
+// awaitApproval blocks until a payment above the approval threshold is
+// approved, denied, or times out. The client lock is not held while waiting.
+func (c *x402Client) awaitApproval(ctx context.Context, requirements PaymentRequirementsView) error {
+	c.mu.RLock()
+	approval := c.approval
+	c.mu.RUnlock()
+
+	if approval == nil || approval.Approve == nil || !exceedsThreshold(requirements.GetAmount(), approval.Threshold) {
+		return nil
+	}
+
+	timeout := approval.Timeout
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type answer struct {
+		approved bool
+		err      error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		approved, err := approval.Approve(ctx, requirements)
+		answers <- answer{approved, err}
+	}()
+
+	details := map[string]interface{}{
+		"network": requirements.GetNetwork(),
+		"amount":  requirements.GetAmount(),
+	}
+	select {
+	case a := <-answers:
+		if a.err != nil {
+			return NewPaymentError(ErrCodePaymentNotApproved, fmt.Sprintf("approval failed: %v", a.err), details)
+		}
+		if !a.approved {
+			return NewPaymentError(ErrCodePaymentNotApproved, "payment was denied", details)
+		}
+		return nil
+	case <-ctx.Done():
+		return NewPaymentError(ErrCodePaymentNotApproved, fmt.Sprintf("approval not given: %v", ctx.Err()), details)
+	}
+}
+
+// exceedsThreshold reports whether amount is above threshold; an empty
+// threshold or unparseable amount always needs approval
+func exceedsThreshold(amount, threshold string) bool {
+	if threshold == "" {
+		return true
+	}
+	limit, ok := new(big.Int).SetString(threshold, 10)
+	if !ok {
+		return true
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return true
+	}
+	return value.Cmp(limit) > 0
+}
@@ -0,0 +1,87 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestClientApproval(t *testing.T) {
+	ctx := context.Background()
+	requirements := func(amount string) types.PaymentRequirements {
+		return types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: amount, PayTo: "0xrecipient"}
+	}
+	notApproved := func(err error) bool {
+		var paymentErr *PaymentError
+		return errors.As(err, &paymentErr) && paymentErr.Code == ErrCodePaymentNotApproved
+	}
+
+	var asked []string
+	client := Newx402Client(WithApproval(ApprovalConfig{
+		Threshold: "1000000",
+		Approve: func(ctx context.Context, requirements PaymentRequirementsView) (bool, error) {
+			asked = append(asked, requirements.GetAmount())
+			return requirements.GetAmount() != "9000000", nil
+		},
+	}))
+	client.Register("eip155:1", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+	t.Run("below threshold", func(t *testing.T) {
+		if _, err := client.CreatePaymentPayload(ctx, requirements("1000000"), nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(asked) != 0 {
+			t.Errorf("Expected no approval below the threshold, asked for %v", asked)
+		}
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		if _, err := client.CreatePaymentPayload(ctx, requirements("2000000"), nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(asked) != 1 {
+			t.Errorf("Expected one approval, got %v", asked)
+		}
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		_, err := client.CreatePaymentPayload(ctx, requirements("9000000"), nil, nil)
+		if !notApproved(err) {
+			t.Fatalf("Expected %s, got %v", ErrCodePaymentNotApproved, err)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		client.RequireApproval(ApprovalConfig{
+			Timeout: 10 * time.Millisecond,
+			Approve: func(ctx context.Context, requirements PaymentRequirementsView) (bool, error) {
+				time.Sleep(time.Second) // An approver ignoring ctx
+				return true, nil
+			},
+		})
+		start := time.Now()
+		_, err := client.CreatePaymentPayload(ctx, requirements("1"), nil, nil)
+		if !notApproved(err) {
+			t.Fatalf("Expected %s, got %v", ErrCodePaymentNotApproved, err)
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Errorf("Expected the timeout to end the wait, took %v", time.Since(start))
+		}
+	})
+
+	t.Run("V1", func(t *testing.T) {
+		client.RegisterV1("eip155:1", &mockSchemeNetworkClientV1{scheme: "exact"})
+		client.RequireApproval(ApprovalConfig{
+			Approve: func(ctx context.Context, requirements PaymentRequirementsView) (bool, error) {
+				return false, errors.New("operator unavailable")
+			},
+		})
+		_, err := client.CreatePaymentPayloadV1(ctx, types.PaymentRequirementsV1{Scheme: "exact", Network: "eip155:1", MaxAmountRequired: "1"})
+		if !notApproved(err) {
+			t.Fatalf("Expected %s, got %v", ErrCodePaymentNotApproved, err)
+		}
+	})
+}
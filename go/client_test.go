@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/coinbase/x402/go/types"
 )
@@ -352,3 +353,28 @@ func TestClientNetworkPatternMatching(t *testing.T) {
 		t.Fatal("Expected payload to be created with pattern match")
 	}
 }
+
+func TestClientCreatePaymentPayloadExpired(t *testing.T) {
+	client := Newx402Client()
+	client.Register("eip155:1", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+	requirements := types.PaymentRequirements{
+		Scheme:    "exact",
+		Network:   "eip155:1",
+		Asset:     "USDC",
+		Amount:    "1000000",
+		PayTo:     "0xrecipient",
+		ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+	}
+
+	_, err := client.CreatePaymentPayload(context.Background(), requirements, nil, nil)
+	var paymentErr *PaymentError
+	if !errors.As(err, &paymentErr) || paymentErr.Code != ErrCodePaymentExpired {
+		t.Fatalf("Expected %s error, got %v", ErrCodePaymentExpired, err)
+	}
+
+	requirements.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	if _, err := client.CreatePaymentPayload(context.Background(), requirements, nil, nil); err != nil {
+		t.Fatalf("Unexpected error for unexpired requirements: %v", err)
+	}
+}
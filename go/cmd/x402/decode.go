@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/http/headers"
+
+	// Registers the SVM payload inspector
+	_ "github.com/coinbase/x402/go/mechanisms/svm"
+)
+
+// runDecode implements "x402 decode"
+func runDecode(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	encoding := flags.String("encoding", "", "the PAYMENT-ENCODING of the value (gzip or cbor-v1)")
+	raw := flags.Bool("raw", false, "print the decoded payload instead of its summary")
+	flags.Usage = func() {
+		fmt.Fprintf(stdout, "Usage: x402 decode [flags] [value]\n\nDecodes a PAYMENT-SIGNATURE or X-PAYMENT header value, or a payload's JSON,\nand prints its version, scheme, network, payer, amount, asset and expiry.\nThe value is read from standard input when not given. Nothing is verified.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("decode takes one value, got %d", flags.NArg())
+	}
+
+	value := flags.Arg(0)
+	if flags.NArg() == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		value = string(input)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		flags.Usage()
+		return nil
+	}
+
+	payload := []byte(value)
+	if !strings.HasPrefix(value, "{") {
+		var err error
+		if payload, err = headers.LenientCodec.DecodeBytes(value, *encoding); err != nil {
+			return fmt.Errorf("failed to decode header value: %w", err)
+		}
+	}
+
+	var out interface{}
+	if *raw {
+		out = json.RawMessage(payload)
+	} else {
+		inspection, err := x402.InspectPayment(payload)
+		if err != nil {
+			return err
+		}
+		out = inspection
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestRunDecode(t *testing.T) {
+	payload := `{"x402Version":2,"accepted":{"scheme":"exact","network":"eip155:8453","asset":"0xusdc","amount":"10000","payTo":"0xbob"},` +
+		`"payload":{"authorization":{"from":"0xalice","value":"10000","validBefore":"1767225600"}}}`
+
+	var out bytes.Buffer
+	header := base64.StdEncoding.EncodeToString([]byte(payload))
+	if err := runDecode([]string{header}, strings.NewReader(""), &out); err != nil || !strings.Contains(out.String(), `"payer": "0xalice"`) || !strings.Contains(out.String(), `"expiry": "2026-01-01T00:00:00Z"`) {
+		t.Errorf("Expected the header summarized, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := runDecode([]string{"-raw"}, strings.NewReader(payload+"\n"), &out); err != nil || !strings.Contains(out.String(), `"validBefore": "1767225600"`) {
+		t.Errorf("Expected the payload from standard input, got %q (%v)", out.String(), err)
+	}
+
+	if err := runDecode([]string{"!!"}, strings.NewReader(""), &out); err == nil {
+		t.Error("Expected an error for an invalid value")
+	}
+}
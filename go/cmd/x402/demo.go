@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/coinbase/x402/go/examples"
+)
+
+// runDemo implements "x402 demo"
+func runDemo(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("demo", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	source := flags.Bool("source", false, "print the example's source instead of running it")
+	testnet := flags.Bool("testnet", false, "pay on Base Sepolia (needs PAYEE_ADDRESS, EVM_PRIVATE_KEY, and FACILITATOR_URL) instead of the in-memory sandbox")
+	flags.Usage = func() {
+		fmt.Fprintf(stdout, "Usage: x402 demo [flags] [name]\n\nRuns an example program: it serves a paid API and pays for it.\n\n")
+		flags.PrintDefaults()
+		fmt.Fprintf(stdout, "\nExamples:\n")
+		for _, example := range examples.All() {
+			fmt.Fprintf(stdout, "  %-12s %s\n", example.Name, example.Description)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return nil
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("demo takes one example, got %d", flags.NArg())
+	}
+
+	example, ok := examples.Lookup(flags.Arg(0))
+	if !ok {
+		return fmt.Errorf("unknown example %q; run \"x402 demo\" to list them", flags.Arg(0))
+	}
+	if *source {
+		code, err := example.Source()
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(code)
+		return err
+	}
+
+	env := examples.Sandbox()
+	if *testnet {
+		var err error
+		env, err = examples.Testnet(os.Getenv("PAYEE_ADDRESS"), os.Getenv("EVM_PRIVATE_KEY"), os.Getenv("FACILITATOR_URL"))
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return example.Run(ctx, env, stdout)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunDemo(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"demo"}, &out); err != nil || !strings.Contains(out.String(), "llm-gateway") {
+		t.Errorf("Expected the examples listed, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := run([]string{"demo", "-source", "proxy"}, &out); err != nil || !strings.HasPrefix(out.String(), "package examples") {
+		t.Errorf("Expected the proxy source, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := run([]string{"demo", "download"}, &out); err != nil || !strings.Contains(out.String(), "settled by") {
+		t.Errorf("Expected the download demo to settle, got %q (%v)", out.String(), err)
+	}
+
+	if err := run([]string{"demo", "nope"}, &out); err == nil {
+		t.Error("Expected an error for an unknown example")
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/coinbase/x402/go/networks"
+)
+
+//go:embed all:templates
+var templates embed.FS
+
+// Testnet defaults for scaffolded projects
+const (
+	defaultNetwork        = "eip155:84532"
+	defaultPrice          = "$0.001"
+	defaultFacilitatorURL = "https://x402.org/facilitator"
+)
+
+// frameworks maps each -framework value to its server template directory
+var frameworks = map[string]string{
+	"gin": "templates/gin",
+}
+
+// initOptions configures a scaffolded project
+type initOptions struct {
+	Module         string
+	Framework      string
+	Network        string
+	NetworkName    string
+	Price          string
+	FacilitatorURL string
+	Force          bool
+}
+
+// runInit implements "x402 init"
+func runInit(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	opts := initOptions{}
+	flags.StringVar(&opts.Module, "module", "", "Go module path (default: the directory name)")
+	flags.StringVar(&opts.Framework, "framework", "gin", "server framework: "+strings.Join(frameworkNames(), ", "))
+	flags.StringVar(&opts.Price, "price", defaultPrice, "price of the paid endpoint")
+	flags.StringVar(&opts.FacilitatorURL, "facilitator", defaultFacilitatorURL, "facilitator URL")
+	flags.BoolVar(&opts.Force, "force", false, "overwrite existing files")
+	flags.Usage = func() {
+		fmt.Fprintf(stdout, "Usage: x402 init [flags] [directory]\n\nScaffolds a paid API server, a paying client, and env templates on %s.\n\n", networkName(defaultNetwork))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("init takes one directory, got %d", flags.NArg())
+	}
+
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	opts.Network = defaultNetwork
+	written, err := scaffold(dir, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range written {
+		fmt.Fprintf(stdout, "  created %s\n", filepath.Join(dir, file))
+	}
+	fmt.Fprintf(stdout, "\nNext steps:\n")
+	if dir != "." {
+		fmt.Fprintf(stdout, "  cd %s\n", dir)
+	}
+	fmt.Fprintf(stdout, "  cp .env.example .env   # then set PAYEE_ADDRESS and EVM_PRIVATE_KEY\n")
+	fmt.Fprintf(stdout, "  go mod tidy\n")
+	fmt.Fprintf(stdout, "  go run ./server\n")
+	fmt.Fprintf(stdout, "  go run ./client         # in another terminal\n")
+	return nil
+}
+
+// scaffold renders the project and server templates into dir and returns
+// the files it wrote, relative to dir. It refuses to overwrite existing files
+// unless opts.Force is set.
+func scaffold(dir string, opts initOptions) ([]string, error) {
+	serverTemplates, ok := frameworks[opts.Framework]
+	if !ok {
+		return nil, fmt.Errorf("unsupported framework %q (supported: %s)", opts.Framework, strings.Join(frameworkNames(), ", "))
+	}
+	if opts.Module == "" {
+		absolute, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		opts.Module = filepath.Base(absolute)
+	}
+	if opts.Network == "" {
+		opts.Network = defaultNetwork
+	}
+	if opts.NetworkName == "" {
+		opts.NetworkName = networkName(opts.Network)
+	}
+	if opts.Price == "" {
+		opts.Price = defaultPrice
+	}
+	if opts.FacilitatorURL == "" {
+		opts.FacilitatorURL = defaultFacilitatorURL
+	}
+
+	// Render everything before writing, so a bad template or an existing
+	// file leaves the directory untouched
+	files := make(map[string][]byte)
+	for _, root := range []string{"templates/project", serverTemplates} {
+		if err := renderTemplates(root, opts, files); err != nil {
+			return nil, err
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil && !opts.Force {
+			return nil, fmt.Errorf("%s already exists (use -force to overwrite)", filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, files[name], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// renderTemplates renders every .tmpl file under root into files, keyed by
+// its path relative to root without the .tmpl suffix
+func renderTemplates(root string, opts initOptions, files map[string][]byte) error {
+	return fs.WalkDir(templates, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(name, ".tmpl") {
+			return err
+		}
+		source, err := templates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(path.Base(name)).Parse(string(source))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, opts); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		relative := strings.TrimSuffix(strings.TrimPrefix(name, root+"/"), ".tmpl")
+		files[filepath.FromSlash(relative)] = []byte(rendered.String())
+		return nil
+	})
+}
+
+// frameworkNames lists the supported frameworks
+func frameworkNames() []string {
+	names := make([]string, 0, len(frameworks))
+	for name := range frameworks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// networkName returns a network's display name, or the network itself
+func networkName(network string) string {
+	if info, ok := networks.Lookup(network); ok && info.Name != "" {
+		return info.Name
+	}
+	return network
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "weather-api")
+	written, err := scaffold(dir, initOptions{Framework: "gin", Price: "$0.01"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{".env.example", ".gitignore", "README.md", "client/main.go", "go.mod", "server/main.go"}
+	if strings.Join(written, ",") != filepath.FromSlash(strings.Join(want, ",")) {
+		t.Errorf("Expected %v, got %v", want, written)
+	}
+
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.HasPrefix(string(goMod), "module weather-api\n") {
+		t.Errorf("Expected the module to default to the directory name, got %q", goMod)
+	}
+	env, _ := os.ReadFile(filepath.Join(dir, ".env.example"))
+	if !strings.Contains(string(env), "NETWORK=eip155:84532") || !strings.Contains(string(env), "PRICE=$0.01") {
+		t.Errorf("Expected testnet defaults in the env template, got %q", env)
+	}
+
+	for _, file := range []string{"server/main.go", "client/main.go"} {
+		if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file), nil, parser.AllErrors); err != nil {
+			t.Errorf("Generated %s does not parse: %v", file, err)
+		}
+	}
+
+	// Existing files are never overwritten without -force
+	if _, err := scaffold(dir, initOptions{Framework: "gin"}); err == nil {
+		t.Error("Expected an error scaffolding over an existing project")
+	}
+	if _, err := scaffold(dir, initOptions{Framework: "gin", Force: true}); err != nil {
+		t.Errorf("Expected -force to overwrite, got %v", err)
+	}
+}
+
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	if err := run([]string{"init", "-module", "example.com/paid", filepath.Join(dir, "app")}, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "go run ./server") {
+		t.Errorf("Expected next steps, got %q", out.String())
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "app", "go.mod"))
+	if !strings.HasPrefix(string(goMod), "module example.com/paid\n") {
+		t.Errorf("Expected the -module path, got %q", goMod)
+	}
+
+	if err := run([]string{"init", "-framework", "rails", dir}, &out); err == nil || !strings.Contains(err.Error(), "unsupported framework") {
+		t.Errorf("Expected an unsupported framework error, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Command x402 is a command-line tool for building with x402.
+//
+// Usage:
+//
+//	x402 init [flags] [directory]
+//	x402 demo [flags] [name]
+//	x402 decode [flags] [value]
+//
+// Install it with:
+//
+//	go install github.com/coinbase/x402/go/cmd/x402@latest
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `x402 is a tool for building with x402.
+
+Usage:
+
+	x402 <command> [arguments]
+
+Commands:
+
+	init    scaffold a paid API server and a paying client
+	demo    run an example program end to end
+	decode  show what a payment header or payload contains
+
+Run "x402 <command> -h" for a command's flags.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "x402: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches a command
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	switch args[0] {
+	case "init":
+		return runInit(args[1:], stdout)
+	case "demo":
+		return runDemo(args[1:], stdout)
+	case "decode":
+		return runDecode(args[1:], os.Stdin, stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q; run \"x402 help\"", args[0])
+	}
+}
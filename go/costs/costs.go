@@ -0,0 +1,198 @@
+// Package costs records what settlements cost a facilitator in native tokens
+// (gas on EVM, transaction fees on Solana) and totals them per merchant, so
+// operators can track their operating costs.
+//
+//	ledger := costs.NewLedger(costs.Config{
+//		OnRecord: func(ctx context.Context, r costs.Record) {
+//			gasHistogram.WithLabelValues(r.Network).Observe(float64(r.GasUsed))
+//		},
+//	})
+//	facilitator.OnAfterSettle(ledger.AfterSettle)
+//	...
+//	totals := ledger.Totals()
+//
+// Costs are read by the mechanisms from EVM receipts (signers report GasUsed
+// and EffectiveGasPrice) and Solana transaction metadata (signers implement
+// svm.TransactionFeeReader). Settlements without a reported cost are ignored.
+package costs
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// Record is the cost of one settlement
+type Record struct {
+	Network     string    `json:"network"`
+	PayTo       string    `json:"payTo"`
+	Asset       string    `json:"asset"`
+	Payer       string    `json:"payer,omitempty"`
+	Transaction string    `json:"transaction"`
+	GasUsed     uint64    `json:"gasUsed"`            // Gas (EVM) or compute units (SVM)
+	GasPrice    string    `json:"gasPrice,omitempty"` // Effective price per gas unit (EVM)
+	Fee         string    `json:"fee,omitempty"`      // Smallest native unit (wei, lamports)
+	SettledAt   time.Time `json:"settledAt"`
+}
+
+// Total is the cost of all settlements to one merchant on one network
+type Total struct {
+	Network     string `json:"network"`
+	PayTo       string `json:"payTo"`
+	GasUsed     uint64 `json:"gasUsed"`
+	Fee         string `json:"fee"` // Smallest native unit
+	Settlements int    `json:"settlements"`
+}
+
+// Store persists cost records, e.g. to an audit database
+type Store interface {
+	Append(ctx context.Context, record Record) error
+}
+
+// MemoryStore keeps cost records in memory
+type MemoryStore struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append implements Store
+func (s *MemoryStore) Append(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns all records, oldest first
+func (s *MemoryStore) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Record(nil), s.records...)
+}
+
+// Config configures a Ledger
+type Config struct {
+	// Store receives every record (default: in-memory store)
+	Store Store
+
+	// OnRecord is called for every record, e.g. to export metrics
+	OnRecord func(context.Context, Record)
+}
+
+type totalKey struct{ network, payTo string }
+
+// Ledger totals settlement costs and passes each record to its store and
+// hook. It is safe for concurrent use.
+type Ledger struct {
+	store    Store
+	onRecord func(context.Context, Record)
+	now      func() time.Time
+
+	mu     sync.Mutex
+	gas    map[totalKey]uint64
+	fees   map[totalKey]*big.Int
+	counts map[totalKey]int
+}
+
+// NewLedger creates an empty ledger
+func NewLedger(config Config) *Ledger {
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Ledger{
+		store:    store,
+		onRecord: config.OnRecord,
+		now:      time.Now,
+		gas:      make(map[totalKey]uint64),
+		fees:     make(map[totalKey]*big.Int),
+		counts:   make(map[totalKey]int),
+	}
+}
+
+// Store returns the ledger's store
+func (l *Ledger) Store() Store {
+	return l.store
+}
+
+// AfterSettle is an x402.FacilitatorAfterSettleHook recording the cost of
+// successful settlements. Store failures are returned but do not fail the
+// settlement.
+func (l *Ledger) AfterSettle(ctx x402.FacilitatorSettleResultContext) error {
+	if ctx.Result == nil || !ctx.Result.Success || ctx.Result.Cost == nil {
+		return nil
+	}
+
+	network := string(ctx.Result.Network)
+	if network == "" {
+		network = ctx.Requirements.GetNetwork()
+	}
+	return l.Record(ctx.Ctx, Record{
+		Network:     network,
+		PayTo:       ctx.Requirements.GetPayTo(),
+		Asset:       ctx.Requirements.GetAsset(),
+		Payer:       ctx.Result.Payer,
+		Transaction: ctx.Result.Transaction,
+		GasUsed:     ctx.Result.Cost.GasUsed,
+		GasPrice:    ctx.Result.Cost.GasPrice,
+		Fee:         ctx.Result.Cost.Fee,
+	})
+}
+
+// Record adds a record to the totals, stamping SettledAt if unset, and passes
+// it to the hook and store
+func (l *Ledger) Record(ctx context.Context, record Record) error {
+	if record.SettledAt.IsZero() {
+		record.SettledAt = l.now()
+	}
+
+	k := totalKey{record.Network, record.PayTo}
+	l.mu.Lock()
+	l.gas[k] += record.GasUsed
+	l.counts[k]++
+	if l.fees[k] == nil {
+		l.fees[k] = new(big.Int)
+	}
+	if fee, ok := new(big.Int).SetString(record.Fee, 10); ok {
+		l.fees[k].Add(l.fees[k], fee)
+	}
+	l.mu.Unlock()
+
+	if l.onRecord != nil {
+		l.onRecord(ctx, record)
+	}
+	return l.store.Append(ctx, record)
+}
+
+// Totals returns the cost per network and merchant, sorted in that order
+func (l *Ledger) Totals() []Total {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	totals := make([]Total, 0, len(l.counts))
+	for k, count := range l.counts {
+		totals = append(totals, Total{
+			Network:     k.network,
+			PayTo:       k.payTo,
+			GasUsed:     l.gas[k],
+			Fee:         l.fees[k].String(),
+			Settlements: count,
+		})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Network != totals[j].Network {
+			return totals[i].Network < totals[j].Network
+		}
+		return totals[i].PayTo < totals[j].PayTo
+	})
+	return totals
+}
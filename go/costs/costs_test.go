@@ -0,0 +1,98 @@
+package costs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func settleContext(payTo string, result *x402.SettleResponse) x402.FacilitatorSettleResultContext {
+	return x402.FacilitatorSettleResultContext{
+		FacilitatorSettleContext: x402.FacilitatorSettleContext{
+			Ctx: context.Background(),
+			Requirements: types.PaymentRequirements{
+				Scheme:  "exact",
+				Network: "eip155:8453",
+				Asset:   "0xusdc",
+				Amount:  "1000000",
+				PayTo:   payTo,
+			},
+		},
+		Result: result,
+	}
+}
+
+func settled(gasUsed uint64, fee string) *x402.SettleResponse {
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: "0xtx",
+		Network:     "eip155:8453",
+		Payer:       "0xpayer",
+		Cost:        &x402.SettlementCost{GasUsed: gasUsed, GasPrice: "1000", Fee: fee},
+	}
+}
+
+func TestLedgerTotalsPerMerchant(t *testing.T) {
+	var recorded []Record
+	store := NewMemoryStore()
+	ledger := NewLedger(Config{
+		Store:    store,
+		OnRecord: func(_ context.Context, r Record) { recorded = append(recorded, r) },
+	})
+
+	_ = ledger.AfterSettle(settleContext("0xmerchantB", settled(60000, "60000000")))
+	_ = ledger.AfterSettle(settleContext("0xmerchantA", settled(50000, "50000000")))
+	_ = ledger.AfterSettle(settleContext("0xmerchantA", settled(70000, "70000000")))
+
+	totals := ledger.Totals()
+	if len(totals) != 2 {
+		t.Fatalf("Expected 2 totals, got %d", len(totals))
+	}
+	if totals[0].PayTo != "0xmerchantA" || totals[0].GasUsed != 120000 || totals[0].Fee != "120000000" || totals[0].Settlements != 2 {
+		t.Errorf("Unexpected total for merchant A: %+v", totals[0])
+	}
+	if totals[1].PayTo != "0xmerchantB" || totals[1].Fee != "60000000" || totals[1].Settlements != 1 {
+		t.Errorf("Unexpected total for merchant B: %+v", totals[1])
+	}
+
+	if len(recorded) != 3 || len(store.Records()) != 3 {
+		t.Fatalf("Expected 3 records in the hook and store, got %d and %d", len(recorded), len(store.Records()))
+	}
+	record := store.Records()[0]
+	if record.PayTo != "0xmerchantB" || record.Asset != "0xusdc" || record.GasPrice != "1000" || record.SettledAt.IsZero() {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+}
+
+func TestLedgerIgnoresSettlementsWithoutCost(t *testing.T) {
+	ledger := NewLedger(Config{})
+
+	_ = ledger.AfterSettle(settleContext("0xmerchant", &x402.SettleResponse{Success: true, Transaction: "0xtx"}))
+	failed := settled(50000, "50000000")
+	failed.Success = false
+	_ = ledger.AfterSettle(settleContext("0xmerchant", failed))
+
+	if totals := ledger.Totals(); len(totals) != 0 {
+		t.Errorf("Expected no totals, got %+v", totals)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Append(context.Context, Record) error {
+	return errors.New("database unavailable")
+}
+
+func TestLedgerReportsStoreFailures(t *testing.T) {
+	ledger := NewLedger(Config{Store: failingStore{}})
+
+	if err := ledger.AfterSettle(settleContext("0xmerchant", settled(50000, "50000000"))); err == nil {
+		t.Fatal("Expected the store failure to be returned")
+	}
+	if totals := ledger.Totals(); len(totals) != 1 || totals[0].Settlements != 1 {
+		t.Errorf("Expected the settlement to be totaled, got %+v", totals)
+	}
+}
@@ -25,6 +25,7 @@ const (
 	ErrCodeSettlementFailed   = "settlement_failed"
 	ErrCodeUnsupportedScheme  = "unsupported_scheme"
 	ErrCodeUnsupportedNetwork = "unsupported_network"
+	ErrCodePaymentNotApproved = "payment_not_approved"
 )
 
 // Facilitator error constants
@@ -36,12 +37,21 @@ const (
 	ErrInvalidV2Requirements   = "invalid_v2_requirements"
 	ErrNoFacilitatorForNetwork = "no_facilitator_for_network"
 	ErrInvalidResponse         = "invalid_response"
+	ErrRequirementsMismatch    = "payment_requirements_mismatch"
+	ErrSimulationNotSupported  = "simulation_not_supported"
+	ErrFeeQuoteNotSupported    = "fee_quote_not_supported"
+	ErrInvalidFeeQuoteRequest  = "invalid_fee_quote_request"
+
+	ErrPartialSettlementNotSupported = "partial_settlement_not_supported"
+	ErrInvalidAmountToSettle         = "invalid_amount_to_settle"
+	ErrMainnetInTestMode             = "mainnet_in_test_mode"
 )
 
 // Server error constants
 const (
 	ErrFailedToMarshalPayload      = "failed_to_marshal_payload"
 	ErrFailedToMarshalRequirements = "failed_to_marshal_requirements"
+	ErrRequirementsExpired         = "payment_requirements_expired"
 )
 
 // NewPaymentError creates a new payment error
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/gin-gonic/gin"
+)
+
+// The paid file download sells files at a price per file. The route covers
+// every file; the price is looked up from the path.
+func init() {
+	register(Example{
+		Name:        "download",
+		Description: "file downloads priced per file",
+		File:        "download.go",
+		Handler:     downloadHandler,
+		Request: func(ctx context.Context, baseURL string) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/files/report.csv", nil)
+		},
+	})
+}
+
+type paidFile struct {
+	price   string
+	content []byte
+}
+
+// catalog is the files for sale
+var catalog = map[string]paidFile{
+	"report.csv":  {price: "$0.01", content: []byte("quarter,revenue\nQ1,1200\nQ2,1850\n")},
+	"dataset.csv": {price: "$0.05", content: []byte("id,value\n1,42\n2,17\n3,99\n")},
+}
+
+// downloadHandler serves GET /files/:name at each file's price
+func downloadHandler(env Env) (http.Handler, error) {
+	r := paidRouter(env, x402http.RoutesConfig{
+		"GET /files/*": {
+			Accepts: x402http.PaymentOptions{
+				{Scheme: env.Scheme.Scheme(), Price: x402http.DynamicPriceFunc(filePrice), Network: env.Network, PayTo: env.PayTo},
+			},
+			Description: "File download",
+		},
+	})
+	r.GET("/files/:name", func(c *gin.Context) {
+		file, ok := catalog[c.Param("name")]
+		if !ok {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", c.Param("name")))
+		c.Data(http.StatusOK, "text/csv", file.content)
+	})
+	return r, nil
+}
+
+// filePrice prices a download by the requested file
+func filePrice(ctx context.Context, reqCtx x402http.HTTPRequestContext) (x402.Price, error) {
+	file, ok := catalog[path.Base(reqCtx.Path)]
+	if !ok {
+		return nil, fmt.Errorf("no file at %s", reqCtx.Path)
+	}
+	return file.price, nil
+}
@@ -0,0 +1,250 @@
+// Package examples contains runnable example programs built on the public
+// x402 API: a paid reverse proxy, a paid LLM gateway, and paid file
+// downloads. Each example serves a paid API and pays for it with an x402
+// client, so running one exercises the whole payment flow.
+//
+// Examples run in a sandbox by default, settling in memory so they need no
+// keys or funds, or on Base Sepolia with Testnet. They are compiled and run
+// by this package's tests, and the x402 command runs them with
+// "x402 demo <name>" and prints their source with "x402 demo -source <name>".
+package examples
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	ginmw "github.com/coinbase/x402/go/http/gin"
+	"github.com/coinbase/x402/go/http/headers"
+	evmclient "github.com/coinbase/x402/go/mechanisms/evm/exact/client"
+	evmserver "github.com/coinbase/x402/go/mechanisms/evm/exact/server"
+	evmsigners "github.com/coinbase/x402/go/signers/evm"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed proxy.go llm_gateway.go download.go
+var sources embed.FS
+
+// Env is what an example needs to charge for its API and to pay for it
+type Env struct {
+	// Network payments are made on
+	Network x402.Network
+
+	// PayTo receives payments
+	PayTo string
+
+	// Facilitator verifies and settles payments
+	Facilitator x402.FacilitatorClient
+
+	// Scheme prices and builds requirements on Network
+	Scheme x402.SchemeNetworkServer
+
+	// Client pays for requests
+	Client *x402.X402Client
+
+	// Upstream is the origin a proxying example forwards to. Run starts the
+	// example's Origin when it is empty.
+	Upstream string
+}
+
+// SandboxNetwork is the network sandbox payments are made on
+const SandboxNetwork x402.Network = "x402:cash"
+
+// Sandbox returns an Env that verifies and settles payments in memory with
+// the cash test scheme, so examples run without keys or funds
+func Sandbox() Env {
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{SandboxNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	client := x402.Newx402Client()
+	client.Register(SandboxNetwork, cash.NewSchemeNetworkClient("Alice"))
+
+	return Env{
+		Network:     SandboxNetwork,
+		PayTo:       "Bob",
+		Facilitator: cash.NewFacilitatorClient(facilitator),
+		Scheme:      cash.NewSchemeNetworkServer(),
+		Client:      client,
+	}
+}
+
+// Testnet returns an Env that pays payTo in USDC on Base Sepolia from the
+// wallet of privateKey, through the facilitator at facilitatorURL
+func Testnet(payTo string, privateKey string, facilitatorURL string) (Env, error) {
+	signer, err := evmsigners.NewClientSignerFromPrivateKey(privateKey)
+	if err != nil {
+		return Env{}, err
+	}
+	client := x402.Newx402Client()
+	client.Register("eip155:*", evmclient.NewExactEvmScheme(signer))
+
+	return Env{
+		Network:     "eip155:84532",
+		PayTo:       payTo,
+		Facilitator: x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: facilitatorURL}),
+		Scheme:      evmserver.NewExactEvmScheme(),
+		Client:      client,
+	}, nil
+}
+
+// Example is a runnable example program
+type Example struct {
+	// Name selects the example (e.g. "proxy")
+	Name string
+
+	// Description says what the example charges for
+	Description string
+
+	// File is the example's source file in this package
+	File string
+
+	// Handler builds the example's paid API
+	Handler func(env Env) (http.Handler, error)
+
+	// Origin is the upstream API a proxying example forwards to in a demo
+	Origin http.Handler
+
+	// Request builds the paid request the example makes against baseURL
+	Request func(ctx context.Context, baseURL string) (*http.Request, error)
+}
+
+// registry holds the examples by name
+var registry = map[string]Example{}
+
+// register adds an example to the registry
+func register(example Example) {
+	registry[example.Name] = example
+}
+
+// All returns the examples sorted by name
+func All() []Example {
+	all := make([]Example, 0, len(registry))
+	for _, example := range registry {
+		all = append(all, example)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Lookup returns the example with a name
+func Lookup(name string) (Example, bool) {
+	example, ok := registry[name]
+	return example, ok
+}
+
+// Source returns the example's source code
+func (e Example) Source() ([]byte, error) {
+	return sources.ReadFile(e.File)
+}
+
+// Run serves the example's paid API on a local port and makes its paid
+// request with env.Client, reporting each step to out. It fails unless the
+// request is paid for and served.
+func (e Example) Run(ctx context.Context, env Env, out io.Writer) error {
+	if env.Upstream == "" && e.Origin != nil {
+		upstream, stop, err := serve(e.Origin)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		env.Upstream = upstream
+	}
+
+	handler, err := e.Handler(env)
+	if err != nil {
+		return err
+	}
+	baseURL, stop, err := serve(handler)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	fmt.Fprintf(out, "🚀 %s: %s\n   serving on %s, paying on %s\n\n", e.Name, e.Description, baseURL, env.Network)
+
+	// Without payment, the API asks for one
+	req, err := e.Request(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	fmt.Fprintf(out, "→ %s %s without payment: %s\n", req.Method, req.URL.Path, resp.Status)
+	if resp.StatusCode != http.StatusPaymentRequired {
+		return fmt.Errorf("expected 402 without payment, got %s", resp.Status)
+	}
+
+	// The x402 client pays and retries
+	req, err = e.Request(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+	paying := x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(env.Client))
+	resp, err = paying.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "→ %s %s with payment: %s\n%s\n", req.Method, req.URL.Path, resp.Status, indent(body))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected 200 after paying, got %s", resp.Status)
+	}
+
+	settlement, err := headers.DecodePaymentResponse(resp.Header.Get("PAYMENT-RESPONSE"))
+	if err != nil {
+		return fmt.Errorf("missing payment response: %w", err)
+	}
+	if !settlement.Success {
+		return errors.New("payment was not settled")
+	}
+	fmt.Fprintf(out, "💰 settled by %s: %s\n", settlement.Payer, settlement.Transaction)
+	return nil
+}
+
+// paidRouter returns a Gin router charging for routes with env's facilitator and scheme
+func paidRouter(env Env, routes x402http.RoutesConfig) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(ginmw.X402Payment(ginmw.Config{
+		Routes:                 routes,
+		Facilitator:            env.Facilitator,
+		Schemes:                []ginmw.SchemeConfig{{Network: env.Network, Server: env.Scheme}},
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}))
+	return r
+}
+
+// serve serves handler on a free local port until stop is called
+func serve(handler http.Handler) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener) //nolint:errcheck // Serve returns http.ErrServerClosed once stopped
+	return "http://" + listener.Addr().String(), func() { server.Close() }, nil
+}
+
+// indent indents a response body for display
+func indent(body []byte) string {
+	lines := strings.Split(strings.TrimRight(string(body), "\n"), "\n")
+	return "   " + strings.Join(lines, "\n   ")
+}
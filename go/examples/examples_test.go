@@ -0,0 +1,31 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestExamples runs every example end to end in the sandbox
+func TestExamples(t *testing.T) {
+	if len(All()) != 3 {
+		t.Fatalf("Expected 3 examples, got %d", len(All()))
+	}
+	for _, example := range All() {
+		t.Run(example.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := example.Run(context.Background(), Sandbox(), &out); err != nil {
+				t.Fatalf("Example failed: %v\n%s", err, out.String())
+			}
+			if !strings.Contains(out.String(), "402 Payment Required") || !strings.Contains(out.String(), "settled by") {
+				t.Errorf("Expected a 402 then a settled payment, got:\n%s", out.String())
+			}
+
+			source, err := example.Source()
+			if err != nil || !bytes.Contains(source, []byte("Name:        \""+example.Name+"\"")) {
+				t.Errorf("Expected the example's source, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/gin-gonic/gin"
+)
+
+// The paid LLM gateway charges for completions by prompt size. The price is
+// computed from the request body, and the payment is bound to that body so a
+// payment for a short prompt cannot be replayed with a long one.
+func init() {
+	register(Example{
+		Name:        "llm-gateway",
+		Description: "an LLM gateway charging per 1,000 prompt characters",
+		File:        "llm_gateway.go",
+		Handler:     llmGatewayHandler,
+		Request: func(ctx context.Context, baseURL string) (*http.Request, error) {
+			body, _ := json.Marshal(completionRequest{Model: "echo-1", Prompt: "Write a haiku about paying for APIs."})
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/completions", bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			return req, nil
+		},
+	})
+}
+
+// pricePer1000Chars is what the gateway charges per 1,000 prompt characters, in USD
+const pricePer1000Chars = 0.002
+
+type completionRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// llmGatewayHandler serves POST /v1/completions, priced by prompt length
+func llmGatewayHandler(env Env) (http.Handler, error) {
+	r := paidRouter(env, x402http.RoutesConfig{
+		"POST /v1/completions": {
+			Accepts: x402http.PaymentOptions{
+				{Scheme: env.Scheme.Scheme(), Price: x402http.DynamicPriceFunc(promptPrice), Network: env.Network, PayTo: env.PayTo},
+			},
+			Description:     "Text completion",
+			MimeType:        "application/json",
+			BindRequestBody: true,
+		},
+	})
+	r.POST("/v1/completions", func(c *gin.Context) {
+		var req completionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"model": req.Model, "completion": complete(req.Prompt)})
+	})
+	return r, nil
+}
+
+// promptPrice prices a completion request by its prompt length, rounded up
+// to the next 1,000 characters
+func promptPrice(ctx context.Context, reqCtx x402http.HTTPRequestContext) (x402.Price, error) {
+	adapter, ok := reqCtx.Adapter.(x402http.HTTPBodyAdapter)
+	if !ok {
+		return nil, fmt.Errorf("pricing needs the request body")
+	}
+	body, err := adapter.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	var req completionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid completion request: %w", err)
+	}
+	units := (len(req.Prompt) + 999) / 1000
+	if units == 0 {
+		units = 1
+	}
+	return fmt.Sprintf("$%.3f", float64(units)*pricePer1000Chars), nil
+}
+
+// complete stands in for a model; a real gateway forwards to a provider here
+func complete(prompt string) string {
+	return "You asked: " + strings.TrimSpace(prompt)
+}
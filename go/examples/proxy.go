@@ -0,0 +1,49 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/proxy"
+)
+
+// The paid proxy puts a price on an existing API without changing it: every
+// request through the proxy is paid for, then forwarded to the upstream.
+func init() {
+	register(Example{
+		Name:        "proxy",
+		Description: "a reverse proxy charging $0.001 per request to an upstream API",
+		File:        "proxy.go",
+		Handler:     proxyHandler,
+		Origin:      http.HandlerFunc(quotesOrigin),
+		Request: func(ctx context.Context, baseURL string) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/quotes/today", nil)
+		},
+	})
+}
+
+// proxyHandler charges for every GET under /api/ and forwards it upstream
+func proxyHandler(env Env) (http.Handler, error) {
+	return proxy.New(context.Background(), proxy.Config{
+		Upstream: env.Upstream,
+		Routes: x402http.RoutesConfig{
+			"GET /api/**": {
+				Accepts: x402http.PaymentOptions{
+					{Scheme: env.Scheme.Scheme(), Price: "$0.001", Network: env.Network, PayTo: env.PayTo},
+				},
+				Description: "Proxied API request",
+			},
+		},
+		Facilitator:    env.Facilitator,
+		Schemes:        []proxy.SchemeConfig{{Network: env.Network, Server: env.Scheme}},
+		PaidRoutesOnly: true,
+	})
+}
+
+// quotesOrigin is the upstream API the demo proxies to
+func quotesOrigin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"path":%q,"quote":"Simplicity is prerequisite for reliability."}`, r.URL.Path)
+}
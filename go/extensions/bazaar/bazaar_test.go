@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/extensions"
 	"github.com/coinbase/x402/go/extensions/bazaar"
 	v1 "github.com/coinbase/x402/go/extensions/v1"
 	x402http "github.com/coinbase/x402/go/http"
@@ -1800,3 +1801,12 @@ func TestBazaarResourceServerExtension(t *testing.T) {
 		assert.Equal(t, extension.Info, resultExt.Info)
 	})
 }
+
+func TestBazaarRegistryExtension(t *testing.T) {
+	registry := extensions.NewRegistry().MustRegister(bazaar.Extension)
+	assert.Equal(t, map[string]string{"bazaar": bazaar.Version}, registry.Supported())
+
+	assert.NoError(t, registry.Validate(map[string]interface{}{"bazaar": map[string]interface{}{"info": map[string]interface{}{}}}))
+	assert.Error(t, registry.Validate(map[string]interface{}{"bazaar": "not an object"}))
+	assert.Error(t, registry.Validate(map[string]interface{}{"bazaar": map[string]interface{}{}}))
+}
@@ -1,6 +1,10 @@
 package bazaar
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/x402/go/extensions"
 	"github.com/coinbase/x402/go/extensions/types"
 	"github.com/coinbase/x402/go/http"
 )
@@ -46,7 +50,14 @@ func (e *bazaarResourceServerExtension) EnrichDeclaration(
 					}
 				}
 				if !hasMethod {
-					input["required"] = append(required, "method")
+					// Copy the path being changed so the route's declaration is never mutated
+					input = copyMap(input)
+					input["required"] = append(append([]string(nil), required...), "method")
+					inputSchema = copyMap(inputSchema)
+					inputSchema["input"] = input
+					schema := copyMap(extension.Schema)
+					schema["properties"] = inputSchema
+					extension.Schema = schema
 				}
 			}
 		}
@@ -56,3 +67,32 @@ func (e *bazaarResourceServerExtension) EnrichDeclaration(
 }
 
 var BazaarResourceServerExtension = &bazaarResourceServerExtension{}
+
+// Version is the version of the bazaar extension advertised by an extensions.Registry
+const Version = "1.0.0"
+
+// Extension registers bazaar discovery with an extensions.Registry. Presented
+// values must be objects carrying the discovery info.
+var Extension = extensions.Extension{
+	Key:     types.BAZAAR,
+	Version: Version,
+	Validate: func(value interface{}) error {
+		declaration, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object, got %T", value)
+		}
+		if _, ok := declaration["info"]; !ok {
+			return errors.New("info is required")
+		}
+		return nil
+	},
+	Enrich: BazaarResourceServerExtension.EnrichDeclaration,
+}
+
+func copyMap[M ~map[string]interface{}](m M) M {
+	copied := make(M, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,264 @@
+// Package extensions provides a versioned registry of protocol extensions.
+//
+// Extensions such as bazaar discovery, sign-in, quotes or splits register a
+// key, a semantic version, the extensions they depend on, a validation
+// function for the values clients present in PaymentPayload.extensions and an
+// enrichment hook for the declarations servers send in PaymentRequired.
+//
+//	registry := extensions.NewRegistry()
+//	registry.MustRegister(bazaar.Extension)
+//	server.SetExtensionRegistry(registry)
+package extensions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// SupportedKey is the PaymentRequired.extensions entry in which a server
+// advertises its registered extensions as a map of key to version, e.g.
+// {"supported": {"bazaar": "1.0.0"}}. It cannot be used as an extension key.
+const SupportedKey = "supported"
+
+// ValidateFunc checks the value a client presented for an extension
+type ValidateFunc func(value interface{}) error
+
+// EnrichFunc adds transport-specific details to a declaration before it is
+// sent to clients. It must not modify the declaration it is given.
+type EnrichFunc func(declaration interface{}, transportContext interface{}) interface{}
+
+// Dependency names an extension that must be registered first, at a version
+// with the same major version and no lower than Version
+type Dependency struct {
+	Key     string
+	Version string
+}
+
+// Extension describes a registered protocol extension
+type Extension struct {
+	// Key is the extensions map key, e.g. "bazaar"
+	Key string
+
+	// Version is the semantic version of the extension, e.g. "1.2.0"
+	Version string
+
+	// Requires lists the extensions this one depends on
+	Requires []Dependency
+
+	// Validate checks client-presented values (optional)
+	Validate ValidateFunc
+
+	// Enrich adds transport details to route declarations (optional)
+	Enrich EnrichFunc
+}
+
+// FromResourceServerExtension adapts a types.ResourceServerExtension, using
+// its EnrichDeclaration as the enrichment hook
+func FromResourceServerExtension(extension types.ResourceServerExtension, version string) Extension {
+	return Extension{
+		Key:     extension.Key(),
+		Version: version,
+		Enrich:  extension.EnrichDeclaration,
+	}
+}
+
+// Registry holds registered extensions. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	extensions map[string]Extension
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{extensions: make(map[string]Extension)}
+}
+
+// Register adds an extension. Its dependencies must already be registered at
+// a compatible version, so registration order follows the dependency graph.
+func (r *Registry) Register(extension Extension) error {
+	if extension.Key == "" {
+		return errors.New("extension key is required")
+	}
+	if extension.Key == SupportedKey {
+		return fmt.Errorf("extension key %q is reserved", SupportedKey)
+	}
+	if _, err := parseVersion(extension.Version); err != nil {
+		return fmt.Errorf("extension %q: %w", extension.Key, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.extensions[extension.Key]; exists {
+		return fmt.Errorf("extension %q is already registered", extension.Key)
+	}
+	for _, dependency := range extension.Requires {
+		registered, ok := r.extensions[dependency.Key]
+		if !ok {
+			return fmt.Errorf("extension %q requires %q, which is not registered", extension.Key, dependency.Key)
+		}
+		compatible, err := versionSatisfies(registered.Version, dependency.Version)
+		if err != nil {
+			return fmt.Errorf("extension %q dependency %q: %w", extension.Key, dependency.Key, err)
+		}
+		if !compatible {
+			return fmt.Errorf("extension %q requires %q %s, but %s is registered", extension.Key, dependency.Key, dependency.Version, registered.Version)
+		}
+	}
+
+	r.extensions[extension.Key] = extension
+	return nil
+}
+
+// MustRegister is like Register but panics on error
+func (r *Registry) MustRegister(extension Extension) *Registry {
+	if err := r.Register(extension); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Get returns the extension registered under key
+func (r *Registry) Get(key string) (Extension, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	extension, ok := r.extensions[key]
+	return extension, ok
+}
+
+// Keys returns the registered keys in sorted order
+func (r *Registry) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.extensions))
+	for key := range r.extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Supported returns the registered extensions as a map of key to version,
+// the value advertised under SupportedKey
+func (r *Registry) Supported() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.supportedLocked()
+}
+
+func (r *Registry) supportedLocked() map[string]string {
+	supported := make(map[string]string, len(r.extensions))
+	for key, extension := range r.extensions {
+		supported[key] = extension.Version
+	}
+	return supported
+}
+
+// Enrich returns a copy of declarations with every registered extension's
+// enrichment hook applied and the registered set advertised under
+// SupportedKey. The declarations map is not modified.
+func (r *Registry) Enrich(declarations map[string]interface{}, transportContext interface{}) map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(declarations) == 0 && len(r.extensions) == 0 {
+		return declarations
+	}
+
+	enriched := make(map[string]interface{}, len(declarations)+1)
+	for key, declaration := range declarations {
+		if extension, ok := r.extensions[key]; ok && extension.Enrich != nil {
+			declaration = extension.Enrich(declaration, transportContext)
+		}
+		enriched[key] = declaration
+	}
+
+	if len(r.extensions) > 0 {
+		enriched[SupportedKey] = r.supportedLocked()
+	}
+	return enriched
+}
+
+// Validate checks the extensions a client presented in a payment payload.
+// Registered extensions are checked by their Validate function, and their
+// dependencies must be presented too. Unregistered keys are ignored, since
+// clients echo whatever the server declared.
+func (r *Registry) Validate(presented map[string]interface{}) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(presented))
+	for key := range presented {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		extension, ok := r.extensions[key]
+		if !ok {
+			continue
+		}
+		for _, dependency := range extension.Requires {
+			if _, ok := presented[dependency.Key]; !ok {
+				errs = append(errs, fmt.Errorf("extension %q requires %q", key, dependency.Key))
+			}
+		}
+		if extension.Validate == nil {
+			continue
+		}
+		if err := extension.Validate(presented[key]); err != nil {
+			errs = append(errs, fmt.Errorf("extension %q: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ============================================================================
+// Versions
+// ============================================================================
+
+// parseVersion parses "MAJOR.MINOR.PATCH"; a leading "v" is allowed
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// versionSatisfies reports whether version has the same major version as
+// minimum and is no lower than it
+func versionSatisfies(version, minimum string) (bool, error) {
+	have, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	want, err := parseVersion(minimum)
+	if err != nil {
+		return false, err
+	}
+	if have[0] != want[0] {
+		return false, nil
+	}
+	for i := 1; i < 3; i++ {
+		if have[i] != want[i] {
+			return have[i] > want[i], nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package extensions_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coinbase/x402/go/extensions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	registry := extensions.NewRegistry()
+	require.NoError(t, registry.Register(extensions.Extension{Key: "quotes", Version: "1.2.0"}))
+
+	tests := []struct {
+		name      string
+		extension extensions.Extension
+		wantErr   string
+	}{
+		{"missing key", extensions.Extension{Version: "1.0.0"}, "key is required"},
+		{"reserved key", extensions.Extension{Key: extensions.SupportedKey, Version: "1.0.0"}, "reserved"},
+		{"invalid version", extensions.Extension{Key: "splits", Version: "1.0"}, "invalid version"},
+		{"duplicate", extensions.Extension{Key: "quotes", Version: "1.3.0"}, "already registered"},
+		{"missing dependency", extensions.Extension{Key: "splits", Version: "1.0.0", Requires: []extensions.Dependency{{Key: "sign-in", Version: "1.0.0"}}}, "not registered"},
+		{"newer dependency", extensions.Extension{Key: "splits", Version: "1.0.0", Requires: []extensions.Dependency{{Key: "quotes", Version: "1.3.0"}}}, "but 1.2.0 is registered"},
+		{"other major", extensions.Extension{Key: "splits", Version: "1.0.0", Requires: []extensions.Dependency{{Key: "quotes", Version: "2.0.0"}}}, "but 1.2.0 is registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.extension)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	require.NoError(t, registry.Register(extensions.Extension{
+		Key:      "splits",
+		Version:  "v1.0.0",
+		Requires: []extensions.Dependency{{Key: "quotes", Version: "1.1.5"}},
+	}))
+	assert.Equal(t, []string{"quotes", "splits"}, registry.Keys())
+	assert.Equal(t, map[string]string{"quotes": "1.2.0", "splits": "v1.0.0"}, registry.Supported())
+}
+
+func TestRegistryValidate(t *testing.T) {
+	registry := extensions.NewRegistry().
+		MustRegister(extensions.Extension{
+			Key:     "quotes",
+			Version: "1.0.0",
+			Validate: func(value interface{}) error {
+				if value != "q1" {
+					return errors.New("unknown quote")
+				}
+				return nil
+			},
+		}).
+		MustRegister(extensions.Extension{
+			Key:      "splits",
+			Version:  "1.0.0",
+			Requires: []extensions.Dependency{{Key: "quotes", Version: "1.0.0"}},
+		})
+
+	assert.NoError(t, registry.Validate(nil))
+	assert.NoError(t, registry.Validate(map[string]interface{}{"quotes": "q1", "splits": true, "unregistered": 1}))
+
+	err := registry.Validate(map[string]interface{}{"quotes": "q2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `extension "quotes": unknown quote`)
+
+	err = registry.Validate(map[string]interface{}{"splits": true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `extension "splits" requires "quotes"`)
+}
+
+func TestRegistryEnrich(t *testing.T) {
+	registry := extensions.NewRegistry().MustRegister(extensions.Extension{
+		Key:     "quotes",
+		Version: "1.0.0",
+		Enrich: func(declaration interface{}, transportContext interface{}) interface{} {
+			return map[string]interface{}{"id": declaration, "method": transportContext}
+		},
+	})
+
+	declarations := map[string]interface{}{"quotes": "q1", "custom": "as-is"}
+	enriched := registry.Enrich(declarations, "GET")
+
+	assert.Equal(t, map[string]interface{}{"id": "q1", "method": "GET"}, enriched["quotes"])
+	assert.Equal(t, "as-is", enriched["custom"])
+	assert.Equal(t, map[string]string{"quotes": "1.0.0"}, enriched[extensions.SupportedKey])
+	assert.Equal(t, map[string]interface{}{"quotes": "q1", "custom": "as-is"}, declarations, "input must not be modified")
+
+	assert.Nil(t, extensions.NewRegistry().Enrich(nil, nil))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/coinbase/x402/go/types"
 )
@@ -34,6 +35,31 @@ type x402Facilitator struct {
 	beforeSettleHooks    []FacilitatorBeforeSettleHook
 	afterSettleHooks     []FacilitatorAfterSettleHook
 	onSettleFailureHooks []FacilitatorOnSettleFailureHook
+
+	// Fee quotes (optional)
+	feeQuoter FeeQuoter
+
+	// Pending settlements (optional status endpoint and hooks)
+	statusURL                string
+	statusMu                 sync.Mutex
+	statuses                 map[string]*SettlementStatus
+	settlementFinalizedHooks []FacilitatorSettlementFinalizedHook
+
+	// Settlement batching (optional)
+	batchConfig *SettlementBatchConfig
+	batchMu     sync.Mutex
+	batches     map[string]*settlementBatch
+
+	// Settlement deduplication (optional)
+	settlementCache SettlementCache
+	settlementTTL   time.Duration
+	settlingMu      sync.Mutex
+	settling        map[string]chan struct{}
+
+	// Test mode (optional): test networks only, with faucet funding
+	testMode *TestModeConfig
+	fundedMu sync.Mutex
+	funded   map[string]time.Time
 }
 
 func Newx402Facilitator() *x402Facilitator {
@@ -46,6 +72,7 @@ func Newx402Facilitator() *x402Facilitator {
 
 // RegisterV1 registers a V1 facilitator mechanism for multiple networks (legacy)
 // Networks are stored and used for GetSupported() - no need to specify them later.
+// Wildcard families (e.g. "eip155:*") are accepted; see Register for precedence.
 func (f *x402Facilitator) RegisterV1(networks []Network, facilitator SchemeNetworkFacilitatorV1) *x402Facilitator {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -68,6 +95,16 @@ func (f *x402Facilitator) RegisterV1(networks []Network, facilitator SchemeNetwo
 
 // Register registers a facilitator mechanism for multiple networks (V2, default)
 // Networks are stored and used for GetSupported() - no need to specify them later.
+//
+// Networks may be CAIP-2 family wildcards, so one facilitator serves every chain
+// in a namespace:
+//
+//	facilitator.Register([]x402.Network{"eip155:*"}, evmFacilitator)
+//	facilitator.Register([]x402.Network{"eip155:8453"}, baseFacilitator)
+//
+// A registration naming the exact network beats a wildcard match regardless of
+// order (above, Base payments go to baseFacilitator); otherwise the earliest
+// matching registration wins.
 func (f *x402Facilitator) Register(networks []Network, facilitator SchemeNetworkFacilitator) *x402Facilitator {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -200,7 +237,9 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 		}
 
 		// Call mechanism
-		verifyResult, verifyErr := f.verifyV1(ctx, *payload, *requirements)
+		verifyResult, verifyErr := f.verifyFunded(ctx, Network(requirements.Network), requirements.Asset, func() (*VerifyResponse, error) {
+			return f.verifyV1(ctx, *payload, *requirements)
+		})
 
 		// Handle failure
 		if verifyErr != nil {
@@ -254,7 +293,9 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 		}
 
 		// Call mechanism
-		verifyResult, verifyErr := f.verifyV2(ctx, *payload, *requirements)
+		verifyResult, verifyErr := f.verifyFunded(ctx, Network(requirements.Network), requirements.Asset, func() (*VerifyResponse, error) {
+			return f.verifyV2(ctx, *payload, *requirements)
+		})
 
 		// Handle failure
 		if verifyErr != nil {
@@ -283,6 +324,11 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 
 // Settle settles a payment (detects version from bytes, routes to typed mechanism)
 func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+	return f.settle(ctx, payloadBytes, requirementsBytes, "")
+}
+
+// settle settles a payment for amountToSettle, or the full amount when empty
+func (f *x402Facilitator) settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*SettleResponse, error) {
 	// Detect version
 	version, err := types.DetectVersion(payloadBytes)
 	if err != nil {
@@ -305,9 +351,25 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 			return nil, NewSettleError(ErrInvalidV1Requirements, "", "", "", err.Error())
 		}
 
+		if amountToSettle != "" && amountToSettle != requirements.MaxAmountRequired {
+			return nil, NewSettleError(ErrPartialSettlementNotSupported, "", Network(requirements.Network), "", "partial settlement is not supported for version 1")
+		}
+
 		hookPayload = *payload
 		hookRequirements = *requirements
 
+		// Return the original result of a retried settlement
+		dedupeKey := settlementKey(requirements.Network, requirements.Asset, payload.Payload)
+		cacheKey := settlementCacheKey(dedupeKey, payload, requirements, amountToSettle)
+		cached, done, err := f.beginSettlement(ctx, dedupeKey, cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil {
+			return cached, nil
+		}
+		defer done()
+
 		// Execute beforeSettle hooks
 		hookCtx := FacilitatorSettleContext{
 			Ctx:               ctx,
@@ -335,11 +397,14 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 			for _, hook := range f.onSettleFailureHooks {
 				result, _ := hook(failureCtx)
 				if result != nil && result.Recovered {
+					f.rememberSettlement(ctx, cacheKey, result.Result)
 					return result.Result, nil
 				}
 			}
 			return nil, settleErr
 		}
+		f.markTestMode(settleResult)
+		f.rememberSettlement(ctx, cacheKey, settleResult)
 
 		// Execute afterSettle hooks
 		resultCtx := FacilitatorSettleResultContext{FacilitatorSettleContext: hookCtx, Result: settleResult}
@@ -359,9 +424,25 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 			return nil, NewSettleError(ErrInvalidV2Requirements, "", "", "", err.Error())
 		}
 
+		if err := checkAmountToSettle(amountToSettle, requirements.Amount); err != nil {
+			return nil, NewSettleError(ErrInvalidAmountToSettle, "", Network(requirements.Network), "", err.Error())
+		}
+
 		hookPayload = *payload
 		hookRequirements = *requirements
 
+		// Return the original result of a retried settlement
+		dedupeKey := settlementKey(requirements.Network, requirements.Asset, payload.Payload)
+		cacheKey := settlementCacheKey(dedupeKey, payload, requirements, amountToSettle)
+		cached, done, err := f.beginSettlement(ctx, dedupeKey, cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil {
+			return cached, nil
+		}
+		defer done()
+
 		// Execute beforeSettle hooks
 		hookCtx := FacilitatorSettleContext{
 			Ctx:               ctx,
@@ -381,7 +462,7 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 		}
 
 		// Call mechanism
-		settleResult, settleErr := f.settleV2(ctx, *payload, *requirements)
+		settleResult, settleErr := f.settleV2(ctx, *payload, *requirements, amountToSettle)
 
 		// Handle failure
 		if settleErr != nil {
@@ -389,11 +470,15 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 			for _, hook := range f.onSettleFailureHooks {
 				result, _ := hook(failureCtx)
 				if result != nil && result.Recovered {
+					f.rememberSettlement(ctx, cacheKey, result.Result)
 					return result.Result, nil
 				}
 			}
 			return nil, settleErr
 		}
+		f.trackPendingSettlement(*requirements, settleResult)
+		f.markTestMode(settleResult)
+		f.rememberSettlement(ctx, cacheKey, settleResult)
 
 		// Execute afterSettle hooks
 		resultCtx := FacilitatorSettleResultContext{FacilitatorSettleContext: hookCtx, Result: settleResult}
@@ -408,6 +493,32 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 	}
 }
 
+// Simulate runs all verification and a full settlement simulation for a V2
+// payment, returning the projected settlement cost without broadcasting.
+// The mechanism must implement SchemeNetworkSimulator. Verify and settle
+// hooks are not run, since nothing is verified for or settled on behalf of
+// a resource server.
+func (f *x402Facilitator) Simulate(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SimulateResponse, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, NewVerifyError(ErrInvalidVersion, "", fmt.Sprintf("failed to detect version: %s", err.Error()))
+	}
+	if version != 2 {
+		return nil, NewVerifyError(ErrSimulationNotSupported, "", fmt.Sprintf("simulation is not supported for version %d", version))
+	}
+
+	payload, err := types.ToPaymentPayload(payloadBytes)
+	if err != nil {
+		return nil, NewVerifyError(ErrInvalidV2Payload, "", err.Error())
+	}
+	requirements, err := types.ToPaymentRequirements(requirementsBytes)
+	if err != nil {
+		return nil, NewVerifyError(ErrInvalidV2Requirements, "", err.Error())
+	}
+
+	return f.simulateV2(ctx, *payload, *requirements)
+}
+
 // ============================================================================
 // Internal Typed Methods (called after version detection)
 // ============================================================================
@@ -419,18 +530,13 @@ func (f *x402Facilitator) verifyV1(ctx context.Context, payload types.PaymentPay
 
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
+	if f.rejectedInTestMode(network) {
+		return nil, NewVerifyError(ErrMainnetInTestMode, "", testModeMessage(network))
+	}
 
-	// Find matching facilitator from array
-	for _, data := range f.schemesV1 {
-		facilitator := data.facilitator.(SchemeNetworkFacilitatorV1)
-		if facilitator.Scheme() != scheme {
-			continue
-		}
-
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Verify(ctx, payload, requirements)
-		}
+	// Find matching facilitator (exact network beats wildcard family)
+	if data := findSchemeData(f.schemesV1, scheme, network); data != nil {
+		return data.facilitator.(SchemeNetworkFacilitatorV1).Verify(ctx, payload, requirements)
 	}
 
 	return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
@@ -441,23 +547,68 @@ func (f *x402Facilitator) verifyV2(ctx context.Context, payload types.PaymentPay
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
+	if err := checkV2Requirements(payload, requirements); err != nil {
+		return nil, err
+	}
+
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
+	if f.rejectedInTestMode(network) {
+		return nil, NewVerifyError(ErrMainnetInTestMode, "", testModeMessage(network))
+	}
 
-	// Find matching facilitator from array
-	for _, data := range f.schemes {
-		facilitator := data.facilitator.(SchemeNetworkFacilitator)
-		if facilitator.Scheme() != scheme {
-			continue
-		}
+	// Find matching facilitator (exact network beats wildcard family)
+	if data := findSchemeData(f.schemes, scheme, network); data != nil {
+		return data.facilitator.(SchemeNetworkFacilitator).Verify(ctx, payload, requirements)
+	}
+
+	return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
+}
 
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Verify(ctx, payload, requirements)
+// checkV2Requirements checks expiry and the payload's commitment to the
+// requirements the server sent, before the mechanism is called
+func checkV2Requirements(payload types.PaymentPayload, requirements types.PaymentRequirements) error {
+	if err := checkExpiryPresent(payload.Accepted, requirements); err != nil {
+		return err
+	}
+	if payload.Accepted.Expired(time.Now()) {
+		return NewVerifyError(ErrRequirementsExpired, "", fmt.Sprintf("payment requirements expired at %d", payload.Accepted.ExpiresAt))
+	}
+	if err := types.VerifyRequirementsBinding(payload, requirements); err != nil {
+		return NewVerifyError(ErrRequirementsMismatch, "", err.Error())
+	}
+	if payload.RequirementsHash != "" {
+		if hash, err := types.RequirementsHash(requirements); err != nil || hash != payload.RequirementsHash {
+			return NewVerifyError(ErrRequirementsMismatch, "", "payload requirementsHash does not match the requirements sent by the server")
 		}
 	}
+	return nil
+}
 
-	return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
+// simulateV2 simulates settlement of a V2 payment (internal, typed)
+func (f *x402Facilitator) simulateV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SimulateResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if err := checkV2Requirements(payload, requirements); err != nil {
+		return nil, err
+	}
+
+	scheme := requirements.Scheme
+	network := Network(requirements.Network)
+	if f.rejectedInTestMode(network) {
+		return nil, NewVerifyError(ErrMainnetInTestMode, "", testModeMessage(network))
+	}
+
+	data := findSchemeData(f.schemes, scheme, network)
+	if data == nil {
+		return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
+	}
+	simulator, ok := data.facilitator.(SchemeNetworkSimulator)
+	if !ok {
+		return nil, NewVerifyError(ErrSimulationNotSupported, "", fmt.Sprintf("scheme %s on network %s does not support simulation", scheme, network))
+	}
+	return simulator.Simulate(ctx, payload, requirements)
 }
 
 // settleV1 settles a V1 payment (internal, typed)
@@ -467,45 +618,49 @@ func (f *x402Facilitator) settleV1(ctx context.Context, payload types.PaymentPay
 
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
+	if f.rejectedInTestMode(network) {
+		return nil, NewSettleError(ErrMainnetInTestMode, "", network, "", testModeMessage(network))
+	}
 
-	// Find matching facilitator from array
-	for _, data := range f.schemesV1 {
-		facilitator := data.facilitator.(SchemeNetworkFacilitatorV1)
-		if facilitator.Scheme() != scheme {
-			continue
-		}
-
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Settle(ctx, payload, requirements)
-		}
+	// Find matching facilitator (exact network beats wildcard family)
+	if data := findSchemeData(f.schemesV1, scheme, network); data != nil {
+		return data.facilitator.(SchemeNetworkFacilitatorV1).Settle(ctx, payload, requirements)
 	}
 
 	return nil, NewSettleError(ErrNoFacilitatorForNetwork, "", network, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
 }
 
-// settleV2 settles a V2 payment (internal, typed)
-func (f *x402Facilitator) settleV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
-
+// settleV2 settles a V2 payment (internal, typed), for amountToSettle when it
+// is set and below the maximum
+func (f *x402Facilitator) settleV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error) {
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
 
-	// Find matching facilitator from array
-	for _, data := range f.schemes {
-		facilitator := data.facilitator.(SchemeNetworkFacilitator)
-		if facilitator.Scheme() != scheme {
-			continue
-		}
+	// Find matching facilitator (exact network beats wildcard family)
+	f.mu.RLock()
+	data := findSchemeData(f.schemes, scheme, network)
+	batchConfig := f.batchConfig
+	rejected := f.rejectedInTestMode(network)
+	f.mu.RUnlock()
+	if rejected {
+		return nil, NewSettleError(ErrMainnetInTestMode, "", network, "", testModeMessage(network))
+	}
+	if data == nil {
+		return nil, NewSettleError(ErrNoFacilitatorForNetwork, "", network, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
+	}
 
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Settle(ctx, payload, requirements)
+	facilitator := data.facilitator.(SchemeNetworkFacilitator)
+	if amountToSettle == "" || amountToSettle == requirements.Amount {
+		if batcher, ok := data.facilitator.(SchemeNetworkBatchSettler); ok && batchConfig != nil {
+			return f.settleBatched(ctx, *batchConfig, facilitator, batcher, payload, requirements)
 		}
+		return facilitator.Settle(ctx, payload, requirements)
 	}
-
-	return nil, NewSettleError(ErrNoFacilitatorForNetwork, "", network, "", fmt.Sprintf("no facilitator for scheme %s on network %s", scheme, network))
+	settler, ok := data.facilitator.(SchemeNetworkPartialSettler)
+	if !ok {
+		return nil, NewSettleError(ErrPartialSettlementNotSupported, "", network, "", fmt.Sprintf("scheme %s on network %s does not support partial settlement", scheme, network))
+	}
+	return settler.SettlePartial(ctx, payload, requirements, amountToSettle)
 }
 
 // GetSupported returns supported payment kinds
@@ -590,6 +745,7 @@ func (f *x402Facilitator) GetSupported() SupportedResponse {
 		Kinds:      kinds,
 		Extensions: f.extensions,
 		Signers:    signers,
+		TestMode:   f.testMode != nil,
 	}
 }
 
@@ -624,6 +780,34 @@ func derivePattern(networks []Network) Network {
 	return networks[0]
 }
 
+// findSchemeData returns the registration handling a scheme on a network.
+// A registration listing the exact network wins over wildcard matches (explicit
+// families like "eip155:*" or patterns derived from the registered networks);
+// among wildcard matches the earliest registration wins.
+func findSchemeData(registrations []*schemeData, scheme string, network Network) *schemeData {
+	var wildcard *schemeData
+	for _, data := range registrations {
+		if data.scheme() != scheme {
+			continue
+		}
+		if data.networks[network] {
+			return data
+		}
+		if wildcard == nil && matchesSchemeData(data, network) {
+			wildcard = data
+		}
+	}
+	return wildcard
+}
+
+// scheme returns the scheme name of the registered facilitator
+func (d *schemeData) scheme() string {
+	if facilitator, ok := d.facilitator.(interface{ Scheme() string }); ok {
+		return facilitator.Scheme()
+	}
+	return ""
+}
+
 // matchesSchemeData checks if a network matches the scheme data
 // Returns true if network is in registered networks or matches the pattern
 func matchesSchemeData(data *schemeData, network Network) bool {
@@ -632,6 +816,13 @@ func matchesSchemeData(data *schemeData, network Network) bool {
 		return true
 	}
 
+	// Registered wildcard families (e.g. "eip155:*")
+	for registered := range data.networks {
+		if IsWildcardNetwork(registered) && MatchesNetwork(registered, network) {
+			return true
+		}
+	}
+
 	// Try pattern matching
 	return matchesNetworkPattern(string(network), string(data.pattern))
 }
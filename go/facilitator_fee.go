@@ -0,0 +1,204 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Facilitator Fee (Fee-on-Top)
+// ============================================================================
+
+// FacilitatorFeeExtraKey is the requirements extra key describing the
+// facilitator fee included in the amount
+const FacilitatorFeeExtraKey = "facilitatorFee"
+
+// FacilitatorFee charges a facilitator's fee on top of the resource price.
+// The payer is asked for price + fee; the fee portion is described in the
+// requirements extra so it can be accounted for after settlement.
+type FacilitatorFee struct {
+	// Facilitator is the identifier of the facilitator client the fee is owed to
+	Facilitator string
+
+	// BasisPoints of the price added as the fee (100 = 1%)
+	BasisPoints int64
+
+	// Quote asks the facilitator for its current fee (see
+	// FeeQuotingFacilitatorClient) instead of charging BasisPoints. Quotes are
+	// cached until they expire; BasisPoints applies when quoting fails.
+	Quote bool
+}
+
+// defaultFeeQuoteTTL is how long quotes without an expiry are cached
+const defaultFeeQuoteTTL = time.Minute
+
+// FacilitatorFeeDetails is the fee portion of a payment, as recorded in the requirements extra
+type FacilitatorFeeDetails struct {
+	Facilitator string `json:"facilitator"`
+	Amount      string `json:"amount"` // Fee in atomic units
+	Price       string `json:"price"`  // Resource price in atomic units, before the fee
+}
+
+// WithFacilitatorFee enables fee-on-top for requirements built with the
+// facilitator client named by fee.Facilitator (see FacilitatorIdentifier)
+func WithFacilitatorFee(fee FacilitatorFee) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		if s.facilitatorFees == nil {
+			s.facilitatorFees = make(map[string]FacilitatorFee)
+		}
+		s.facilitatorFees[fee.Facilitator] = fee
+	}
+}
+
+// FacilitatorFeeFromRequirements returns the facilitator fee included in the
+// requirements, if fee-on-top applied when they were built
+func FacilitatorFeeFromRequirements(requirements PaymentRequirementsView) (FacilitatorFeeDetails, bool) {
+	raw, ok := requirements.GetExtra()[FacilitatorFeeExtraKey].(map[string]interface{})
+	if !ok {
+		return FacilitatorFeeDetails{}, false
+	}
+	details := FacilitatorFeeDetails{}
+	details.Facilitator, _ = raw["facilitator"].(string)
+	details.Amount, _ = raw["amount"].(string)
+	details.Price, _ = raw["price"].(string)
+	if details.Facilitator == "" || details.Amount == "" {
+		return FacilitatorFeeDetails{}, false
+	}
+	return details, true
+}
+
+// facilitatorFeeFor returns the fee configured for the facilitator that will
+// handle the payment, along with its client. Callers must hold s.mu.
+func (s *x402ResourceServer) facilitatorFeeFor(ctx context.Context, network Network, scheme string) (FacilitatorFee, FacilitatorClient, bool) {
+	if len(s.facilitatorFees) == 0 {
+		return FacilitatorFee{}, nil, false
+	}
+	var client FacilitatorClient
+	identifier := FacilitatorFromContext(ctx)
+	if identifier != "" {
+		client, _ = s.namedFacilitatorClient(identifier)
+	} else {
+		client = s.facilitatorClientFor(network, scheme)
+		named, ok := client.(FacilitatorIdentifier)
+		if !ok {
+			return FacilitatorFee{}, nil, false
+		}
+		identifier = named.Identifier()
+	}
+	fee, ok := s.facilitatorFees[identifier]
+	return fee, client, ok && (fee.BasisPoints > 0 || fee.Quote)
+}
+
+// chargeFacilitatorFee adds the facilitator's quoted fee to the requirement
+// when quoting applies and succeeds, and BasisPoints of the price otherwise
+func (s *x402ResourceServer) chargeFacilitatorFee(ctx context.Context, requirement *types.PaymentRequirements, fee FacilitatorFee, client FacilitatorClient) error {
+	if amount, ok := s.quotedFacilitatorFee(ctx, fee, client, *requirement); ok {
+		return addFacilitatorFee(requirement, fee.Facilitator, amount)
+	}
+	return applyFacilitatorFee(requirement, fee)
+}
+
+// applyFacilitatorFee adds BasisPoints of the price to the requirement amount
+// and records the fee in extra
+func applyFacilitatorFee(requirement *types.PaymentRequirements, fee FacilitatorFee) error {
+	price, ok := new(big.Int).SetString(requirement.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q for facilitator fee", requirement.Amount)
+	}
+	amount := new(big.Int).Mul(price, big.NewInt(fee.BasisPoints))
+	amount.Quo(amount, big.NewInt(10000))
+	return addFacilitatorFee(requirement, fee.Facilitator, amount)
+}
+
+// addFacilitatorFee adds a fee amount to the requirement amount and records it in extra
+func addFacilitatorFee(requirement *types.PaymentRequirements, facilitator string, amount *big.Int) error {
+	price, ok := new(big.Int).SetString(requirement.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q for facilitator fee", requirement.Amount)
+	}
+	if amount.Sign() <= 0 {
+		return nil
+	}
+
+	// Copy extra so cached supported kinds are never modified
+	extra := make(map[string]interface{}, len(requirement.Extra)+1)
+	for k, v := range requirement.Extra {
+		extra[k] = v
+	}
+	extra[FacilitatorFeeExtraKey] = map[string]interface{}{
+		"facilitator": facilitator,
+		"amount":      amount.String(),
+		"price":       requirement.Amount,
+	}
+	requirement.Extra = extra
+	requirement.Amount = new(big.Int).Add(price, amount).String()
+	return nil
+}
+
+// feeQuoteKey identifies a cached fee quote
+type feeQuoteKey struct {
+	facilitator, scheme, network, asset, amount string
+}
+
+// feeQuoteCache caches facilitator fee quotes until they expire
+type feeQuoteCache struct {
+	mu     sync.Mutex
+	quotes map[feeQuoteKey]*big.Int
+	expiry map[feeQuoteKey]time.Time
+}
+
+// quotedFacilitatorFee returns the facilitator's quoted fee for a requirement
+// when fee.Quote is set, reporting false if the client cannot quote or the
+// quote fails
+func (s *x402ResourceServer) quotedFacilitatorFee(ctx context.Context, fee FacilitatorFee, client FacilitatorClient, requirement types.PaymentRequirements) (*big.Int, bool) {
+	if !fee.Quote {
+		return nil, false
+	}
+	quoter, ok := client.(FeeQuotingFacilitatorClient)
+	if !ok {
+		return nil, false
+	}
+
+	key := feeQuoteKey{fee.Facilitator, requirement.Scheme, requirement.Network, requirement.Asset, requirement.Amount}
+	now := s.now()
+	s.feeQuotes.mu.Lock()
+	if amount, ok := s.feeQuotes.quotes[key]; ok && now.Before(s.feeQuotes.expiry[key]) {
+		s.feeQuotes.mu.Unlock()
+		return amount, true
+	}
+	s.feeQuotes.mu.Unlock()
+
+	quote, err := quoter.QuoteFee(ctx, FeeQuoteRequest{
+		X402Version: 2,
+		Scheme:      requirement.Scheme,
+		Network:     requirement.Network,
+		Asset:       requirement.Asset,
+		Amount:      requirement.Amount,
+	})
+	if err != nil || quote == nil {
+		return nil, false
+	}
+	amount, ok := new(big.Int).SetString(quote.Fee, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, false
+	}
+
+	expiry := now.Add(defaultFeeQuoteTTL)
+	if quote.ExpiresAt > 0 {
+		expiry = time.Unix(quote.ExpiresAt, 0)
+	}
+	s.feeQuotes.mu.Lock()
+	if s.feeQuotes.quotes == nil {
+		s.feeQuotes.quotes = make(map[feeQuoteKey]*big.Int)
+		s.feeQuotes.expiry = make(map[feeQuoteKey]time.Time)
+	}
+	s.feeQuotes.quotes[key] = amount
+	s.feeQuotes.expiry[key] = expiry
+	s.feeQuotes.mu.Unlock()
+	return amount, true
+}
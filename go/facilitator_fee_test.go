@@ -0,0 +1,173 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+type namedServerFacilitatorClient struct {
+	mockServerFacilitatorClient
+	name string
+}
+
+func (n *namedServerFacilitatorClient) Identifier() string {
+	return n.name
+}
+
+func TestFacilitatorFeeOnTop(t *testing.T) {
+	ctx := context.Background()
+	client := &namedServerFacilitatorClient{
+		mockServerFacilitatorClient: mockServerFacilitatorClient{
+			kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+		},
+		name: "https://facilitator.example",
+	}
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(client),
+		WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+		WithFacilitatorFee(FacilitatorFee{Facilitator: "https://facilitator.example", BasisPoints: 50}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	config := ResourceConfig{Scheme: "exact", PayTo: "0xrecipient", Price: "$1.00", Network: "eip155:1"}
+	requirements, err := server.BuildPaymentRequirementsFromConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requirements[0].Amount != "1005000" {
+		t.Errorf("Expected price plus 0.5%% fee, got %s", requirements[0].Amount)
+	}
+
+	fee, ok := FacilitatorFeeFromRequirements(requirements[0])
+	if !ok {
+		t.Fatal("Expected fee details in extra")
+	}
+	if fee.Facilitator != "https://facilitator.example" || fee.Amount != "5000" || fee.Price != "1000000" {
+		t.Errorf("Unexpected fee details %+v", fee)
+	}
+}
+
+func TestFacilitatorFeeOnlyForNamedFacilitator(t *testing.T) {
+	ctx := context.Background()
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(&mockServerFacilitatorClient{
+			kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+		}),
+		WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+		WithFacilitatorFee(FacilitatorFee{Facilitator: "https://facilitator.example", BasisPoints: 50}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	config := ResourceConfig{Scheme: "exact", PayTo: "0xrecipient", Price: "$1.00", Network: "eip155:1"}
+	requirements, err := server.BuildPaymentRequirementsFromConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requirements[0].Amount != "1000000" {
+		t.Errorf("Expected no fee for an unnamed facilitator, got %s", requirements[0].Amount)
+	}
+	if _, ok := FacilitatorFeeFromRequirements(requirements[0]); ok {
+		t.Error("Expected no fee details")
+	}
+}
+
+func TestApplyFacilitatorFeeRoundsDown(t *testing.T) {
+	requirement := types.PaymentRequirements{Amount: "199"}
+	if err := applyFacilitatorFee(&requirement, FacilitatorFee{Facilitator: "f", BasisPoints: 50}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requirement.Amount != "199" || requirement.Extra != nil {
+		t.Errorf("Expected fee below one atomic unit to be skipped, got %+v", requirement)
+	}
+
+	requirement = types.PaymentRequirements{Amount: "1.5"}
+	if err := applyFacilitatorFee(&requirement, FacilitatorFee{Facilitator: "f", BasisPoints: 50}); err == nil {
+		t.Error("Expected error for a non-integer amount")
+	}
+}
+
+// quotingServerFacilitatorClient quotes a fixed fee and counts quotes
+type quotingServerFacilitatorClient struct {
+	namedServerFacilitatorClient
+	fee       string
+	expiresAt int64
+	err       error
+	quotes    int
+}
+
+func (q *quotingServerFacilitatorClient) QuoteFee(ctx context.Context, request FeeQuoteRequest) (*FeeQuote, error) {
+	q.quotes++
+	if q.err != nil {
+		return nil, q.err
+	}
+	return &FeeQuote{Scheme: request.Scheme, Network: request.Network, Amount: request.Amount, Fee: q.fee, ExpiresAt: q.expiresAt}, nil
+}
+
+func TestFacilitatorFeeQuoted(t *testing.T) {
+	ctx := context.Background()
+	config := ResourceConfig{Scheme: "exact", PayTo: "0xrecipient", Price: "$1.00", Network: "eip155:1"}
+	newServer := func(client *quotingServerFacilitatorClient) *x402ResourceServer {
+		client.namedServerFacilitatorClient = namedServerFacilitatorClient{
+			mockServerFacilitatorClient: mockServerFacilitatorClient{
+				kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+			},
+			name: "https://facilitator.example",
+		}
+		server := Newx402ResourceServer(
+			WithFacilitatorClient(client),
+			WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+			WithFacilitatorFee(FacilitatorFee{Facilitator: "https://facilitator.example", BasisPoints: 50, Quote: true}),
+		)
+		if err := server.Initialize(ctx); err != nil {
+			t.Fatalf("Failed to initialize: %v", err)
+		}
+		return server
+	}
+
+	t.Run("charges the quoted fee and caches it until expiry", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		client := &quotingServerFacilitatorClient{fee: "12000", expiresAt: now.Add(time.Minute).Unix()}
+		server := newServer(client)
+		server.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			requirements, err := server.BuildPaymentRequirementsFromConfig(ctx, config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if requirements[0].Amount != "1012000" {
+				t.Errorf("Expected price plus quoted fee, got %s", requirements[0].Amount)
+			}
+		}
+		if client.quotes != 1 {
+			t.Errorf("Expected one cached quote, got %d", client.quotes)
+		}
+
+		now = now.Add(2 * time.Minute)
+		if _, err := server.BuildPaymentRequirementsFromConfig(ctx, config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if client.quotes != 2 {
+			t.Errorf("Expected an expired quote to be refreshed, got %d quotes", client.quotes)
+		}
+	})
+
+	t.Run("falls back to basis points when quoting fails", func(t *testing.T) {
+		server := newServer(&quotingServerFacilitatorClient{err: errors.New("facilitator unavailable")})
+		requirements, err := server.BuildPaymentRequirementsFromConfig(ctx, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if requirements[0].Amount != "1005000" {
+			t.Errorf("Expected price plus 0.5%% fee, got %s", requirements[0].Amount)
+		}
+	})
+}
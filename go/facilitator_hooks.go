@@ -54,6 +54,14 @@ type FacilitatorSettleFailureContext struct {
 	Error error
 }
 
+// FacilitatorSettlementFinalizedContext contains the final outcome of a
+// settlement that was pending when Settle returned
+type FacilitatorSettlementFinalizedContext struct {
+	Ctx        context.Context
+	Settlement SubmittedSettlement
+	Result     *SettleResponse
+}
+
 // ============================================================================
 // Facilitator Hook Result Types
 // ============================================================================
@@ -110,3 +118,8 @@ type FacilitatorAfterSettleHook func(FacilitatorSettleResultContext) error
 // If it returns a result with Recovered=true, the provided SettleResponse
 // will be returned instead of the error
 type FacilitatorOnSettleFailureHook func(FacilitatorSettleFailureContext) (*FacilitatorSettleFailureHookResult, error)
+
+// FacilitatorSettlementFinalizedHook is called when a pending settlement
+// confirms or fails, e.g. to deliver a webhook to the resource server
+// Any error returned will be logged but will not affect the settlement
+type FacilitatorSettlementFinalizedHook func(FacilitatorSettlementFinalizedContext) error
@@ -0,0 +1,54 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+)
+
+// ============================================================================
+// Per-Request Facilitator Selection
+// ============================================================================
+
+// FacilitatorIdentifier is implemented by facilitator clients that can be
+// selected by name with ContextWithFacilitator
+type FacilitatorIdentifier interface {
+	Identifier() string
+}
+
+type facilitatorKey struct{}
+
+// ContextWithFacilitator returns a context that routes requirement building,
+// verification, and settlement through the registered facilitator client with
+// the given identifier instead of the one chosen by scheme and network
+func ContextWithFacilitator(ctx context.Context, identifier string) context.Context {
+	if identifier == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, facilitatorKey{}, identifier)
+}
+
+// FacilitatorFromContext returns the identifier stored by ContextWithFacilitator, if any
+func FacilitatorFromContext(ctx context.Context) string {
+	identifier, _ := ctx.Value(facilitatorKey{}).(string)
+	return identifier
+}
+
+// namedFacilitatorClient returns the registered client with the identifier.
+// Callers must hold s.mu.
+func (s *x402ResourceServer) namedFacilitatorClient(identifier string) (FacilitatorClient, error) {
+	for _, client := range s.tempFacilitatorClients {
+		if named, ok := client.(FacilitatorIdentifier); ok && named.Identifier() == identifier {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("facilitator %q is not registered", identifier)
+}
+
+// selectFacilitatorClient returns the facilitator named by the context, or
+// the client registered for the scheme and network. Callers must hold s.mu.
+func (s *x402ResourceServer) selectFacilitatorClient(ctx context.Context, network Network, scheme string) (FacilitatorClient, error) {
+	if identifier := FacilitatorFromContext(ctx); identifier != "" {
+		return s.namedFacilitatorClient(identifier)
+	}
+	return s.facilitatorClientFor(network, scheme), nil
+}
@@ -590,3 +590,217 @@ func TestFacilitatorNetworkPatternMatching(t *testing.T) {
 		t.Fatal("Expected valid verification with pattern match")
 	}
 }
+
+func TestFacilitatorWildcardFamilyPrecedence(t *testing.T) {
+	ctx := context.Background()
+	facilitator := Newx402Facilitator()
+
+	verifyAs := func(payer string) *mockSchemeNetworkFacilitator {
+		return &mockSchemeNetworkFacilitator{
+			scheme: "exact",
+			verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+				return &VerifyResponse{IsValid: true, Payer: payer}, nil
+			},
+		}
+	}
+
+	// The wildcard is registered first, but exact registrations still win
+	facilitator.Register([]Network{"eip155:*", "solana:*"}, verifyAs("family"))
+	facilitator.Register([]Network{"eip155:8453"}, verifyAs("base"))
+
+	tests := []struct {
+		network string
+		want    string
+	}{
+		{"eip155:8453", "base"},
+		{"eip155:10", "family"},
+		{"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "family"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			requirements := types.PaymentRequirements{Scheme: "exact", Network: tt.network, Asset: "USDC", Amount: "1", PayTo: "0xrecipient"}
+			payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+			requirementsBytes, _ := json.Marshal(requirements)
+
+			response, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.Payer != tt.want {
+				t.Errorf("Expected %s facilitator, got %s", tt.want, response.Payer)
+			}
+		})
+	}
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "cosmos:cosmoshub-4", Asset: "USDC", Amount: "1", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+	if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Error("Expected error for network outside the registered families")
+	}
+}
+
+func TestFacilitatorVerifyRequirementsBinding(t *testing.T) {
+	ctx := context.Background()
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	sent := types.PaymentRequirements{
+		Scheme:    "exact",
+		Network:   "eip155:1",
+		Asset:     "USDC",
+		Amount:    "1000000",
+		PayTo:     "0xmerchant",
+		ExpiresAt: 2000000000,
+		Extra:     map[string]interface{}{types.ResourceOriginExtraKey: "https://api.example.com"},
+	}
+	resource := &types.ResourceInfo{URL: "https://api.example.com/data"}
+
+	verify := func(accepted types.PaymentRequirements, resource *types.ResourceInfo) error {
+		payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: accepted, Resource: resource, Payload: map[string]interface{}{}})
+		requirementsBytes, _ := json.Marshal(sent)
+		_, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+		return err
+	}
+
+	// The server regenerates expiresAt per request, so it is not part of the binding
+	accepted := sent
+	accepted.ExpiresAt = 1999999000
+	if err := verify(accepted, resource); err != nil {
+		t.Fatalf("Expected matching requirements to verify, got %v", err)
+	}
+
+	var verifyErr *VerifyError
+	stripped := sent
+	stripped.ExpiresAt = 0
+	if err := verify(stripped, resource); !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsExpired {
+		t.Errorf("Expected %s for a stripped expiry, got %v", ErrRequirementsExpired, err)
+	}
+
+	substituted := sent
+	substituted.PayTo = "0xattacker"
+	if err := verify(substituted, resource); !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsMismatch {
+		t.Errorf("Expected %s for a substituted payTo, got %v", ErrRequirementsMismatch, err)
+	}
+	if err := verify(sent, &types.ResourceInfo{URL: "https://phishing.example/data"}); !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsMismatch {
+		t.Errorf("Expected %s for another origin, got %v", ErrRequirementsMismatch, err)
+	}
+}
+
+func TestFacilitatorVerifyRequirementsHash(t *testing.T) {
+	ctx := context.Background()
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xmerchant"}
+	hash, _ := types.RequirementsHash(requirements)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, RequirementsHash: hash, Payload: map[string]interface{}{}})
+	if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Expected committed payment to verify, got %v", err)
+	}
+
+	otherHash, _ := types.RequirementsHash(types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xattacker"})
+	payloadBytes, _ = json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, RequirementsHash: otherHash, Payload: map[string]interface{}{}})
+	_, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsMismatch {
+		t.Errorf("Expected %s, got %v", ErrRequirementsMismatch, err)
+	}
+}
+
+// mockSimulatingFacilitator adds SchemeNetworkSimulator to the V2 mock
+type mockSimulatingFacilitator struct {
+	mockSchemeNetworkFacilitator
+	simulateFunc func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SimulateResponse, error)
+}
+
+func (m *mockSimulatingFacilitator) Simulate(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SimulateResponse, error) {
+	return m.simulateFunc(ctx, payload, requirements)
+}
+
+func TestFacilitatorSimulate(t *testing.T) {
+	ctx := context.Background()
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{"signature": "test"},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	t.Run("returns the mechanism's projection", func(t *testing.T) {
+		facilitator := Newx402Facilitator()
+		facilitator.Register([]Network{"eip155:1"}, &mockSimulatingFacilitator{
+			mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"},
+			simulateFunc: func(_ context.Context, _ types.PaymentPayload, r types.PaymentRequirements) (*SimulateResponse, error) {
+				return &SimulateResponse{IsValid: true, Payer: "0xmockpayer", Network: Network(r.Network), GasUsed: 65000, GasPrice: "2", Fee: "130000"}, nil
+			},
+		})
+
+		response, err := facilitator.Simulate(ctx, payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !response.IsValid || response.GasUsed != 65000 || response.Fee != "130000" {
+			t.Fatalf("Unexpected simulation response: %+v", response)
+		}
+	})
+
+	t.Run("rejects mechanisms without simulation", func(t *testing.T) {
+		facilitator := Newx402Facilitator()
+		facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+		_, err := facilitator.Simulate(ctx, payloadBytes, requirementsBytes)
+		var verifyErr *VerifyError
+		if !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrSimulationNotSupported {
+			t.Fatalf("Expected %s, got %v", ErrSimulationNotSupported, err)
+		}
+	})
+
+	t.Run("rejects V1 payloads", func(t *testing.T) {
+		facilitator := Newx402Facilitator()
+		facilitator.RegisterV1([]Network{"eip155:1"}, &mockSchemeNetworkFacilitatorV1{scheme: "exact"})
+
+		v1Payload, _ := json.Marshal(map[string]interface{}{"x402Version": 1, "scheme": "exact", "network": "eip155:1", "payload": map[string]interface{}{}})
+		_, err := facilitator.Simulate(ctx, v1Payload, requirementsBytes)
+		var verifyErr *VerifyError
+		if !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrSimulationNotSupported {
+			t.Fatalf("Expected %s, got %v", ErrSimulationNotSupported, err)
+		}
+	})
+
+	t.Run("checks requirements before simulating", func(t *testing.T) {
+		facilitator := Newx402Facilitator()
+		called := false
+		facilitator.Register([]Network{"eip155:1"}, &mockSimulatingFacilitator{
+			mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"},
+			simulateFunc: func(context.Context, types.PaymentPayload, types.PaymentRequirements) (*SimulateResponse, error) {
+				called = true
+				return &SimulateResponse{IsValid: true}, nil
+			},
+		})
+
+		other := requirements
+		other.PayTo = "0xattacker"
+		otherHash, _ := types.RequirementsHash(other)
+		committed := payload
+		committed.RequirementsHash = otherHash
+		committedBytes, _ := json.Marshal(committed)
+		if _, err := facilitator.Simulate(ctx, committedBytes, requirementsBytes); err == nil {
+			t.Fatal("Expected mismatched requirements to fail")
+		}
+		if called {
+			t.Fatal("Expected the mechanism not to be called")
+		}
+	})
+}
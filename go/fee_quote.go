@@ -0,0 +1,94 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ============================================================================
+// Facilitator Fee Quotes
+// ============================================================================
+
+// FeeQuoteRequest asks a facilitator for its fee on a payment
+type FeeQuoteRequest struct {
+	X402Version int    `json:"x402Version"`
+	Scheme      string `json:"scheme"`
+	Network     string `json:"network"`
+	Asset       string `json:"asset,omitempty"`
+	Amount      string `json:"amount"` // Payment amount in atomic units, before the fee
+}
+
+// FeeQuote is a facilitator's current fee for a payment
+type FeeQuote struct {
+	Scheme  string `json:"scheme"`
+	Network string `json:"network"`
+	Asset   string `json:"asset,omitempty"`
+	Amount  string `json:"amount"` // Payment amount the fee was quoted for
+	Fee     string `json:"fee"`    // Fee in atomic units of the asset
+
+	// ExpiresAt is the unix time (seconds) until which the quote holds; 0 means until further notice
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// FeeQuoter prices a payment for a facilitator's fee quotes. The request's
+// scheme and network are registered with the facilitator.
+type FeeQuoter func(ctx context.Context, request FeeQuoteRequest) (*FeeQuote, error)
+
+// BasisPointsFeeQuoter quotes a fee of basisPoints of the amount (100 = 1%),
+// valid for ttl (0 for no expiry)
+func BasisPointsFeeQuoter(basisPoints int64, ttl time.Duration) FeeQuoter {
+	return func(ctx context.Context, request FeeQuoteRequest) (*FeeQuote, error) {
+		amount, ok := new(big.Int).SetString(request.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, NewPaymentError(ErrInvalidFeeQuoteRequest, fmt.Sprintf("invalid amount %q", request.Amount), nil)
+		}
+		fee := new(big.Int).Mul(amount, big.NewInt(basisPoints))
+		fee.Quo(fee, big.NewInt(10000))
+
+		quote := &FeeQuote{
+			Scheme:  request.Scheme,
+			Network: request.Network,
+			Asset:   request.Asset,
+			Amount:  request.Amount,
+			Fee:     fee.String(),
+		}
+		if ttl > 0 {
+			quote.ExpiresAt = time.Now().Add(ttl).Unix()
+		}
+		return quote, nil
+	}
+}
+
+// SetFeeQuoter enables fee quotes (QuoteFee and the /quote endpoint)
+func (f *x402Facilitator) SetFeeQuoter(quoter FeeQuoter) *x402Facilitator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.feeQuoter = quoter
+	return f
+}
+
+// QuoteFee returns the facilitator's fee for a payment of a registered scheme
+// and network. Failures are returned as *PaymentError.
+func (f *x402Facilitator) QuoteFee(ctx context.Context, request FeeQuoteRequest) (*FeeQuote, error) {
+	f.mu.RLock()
+	quoter := f.feeQuoter
+	schemes := f.schemes
+	if request.X402Version == 1 {
+		schemes = f.schemesV1
+	}
+	registered := findSchemeData(schemes, request.Scheme, Network(request.Network)) != nil
+	f.mu.RUnlock()
+
+	if quoter == nil {
+		return nil, NewPaymentError(ErrFeeQuoteNotSupported, "facilitator does not quote fees", nil)
+	}
+	if request.Scheme == "" || request.Network == "" || request.Amount == "" {
+		return nil, NewPaymentError(ErrInvalidFeeQuoteRequest, "scheme, network and amount are required", nil)
+	}
+	if !registered {
+		return nil, NewPaymentError(ErrNoFacilitatorForNetwork, fmt.Sprintf("no facilitator for scheme %s on network %s", request.Scheme, request.Network), nil)
+	}
+	return quoter(ctx, request)
+}
@@ -0,0 +1,57 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFacilitatorQuoteFee(t *testing.T) {
+	ctx := context.Background()
+	request := FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000"}
+
+	t.Run("quotes registered schemes", func(t *testing.T) {
+		facilitator := Newx402Facilitator()
+		facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+		facilitator.SetFeeQuoter(BasisPointsFeeQuoter(50, time.Minute))
+
+		quote, err := facilitator.QuoteFee(ctx, request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if quote.Fee != "5000" || quote.Amount != "1000000" || quote.Network != "eip155:1" {
+			t.Errorf("Unexpected quote: %+v", quote)
+		}
+		if quote.ExpiresAt <= time.Now().Unix() {
+			t.Errorf("Expected the quote to expire in the future, got %d", quote.ExpiresAt)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		quoter  FeeQuoter
+		request FeeQuoteRequest
+		code    string
+	}{
+		{name: "without a quoter", request: request, code: ErrFeeQuoteNotSupported},
+		{name: "unregistered network", quoter: BasisPointsFeeQuoter(50, 0), request: FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:8453", Amount: "1"}, code: ErrNoFacilitatorForNetwork},
+		{name: "missing amount", quoter: BasisPointsFeeQuoter(50, 0), request: FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:1"}, code: ErrInvalidFeeQuoteRequest},
+		{name: "invalid amount", quoter: BasisPointsFeeQuoter(50, 0), request: FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:1", Amount: "1.5"}, code: ErrInvalidFeeQuoteRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facilitator := Newx402Facilitator()
+			facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+			if tt.quoter != nil {
+				facilitator.SetFeeQuoter(tt.quoter)
+			}
+
+			_, err := facilitator.QuoteFee(ctx, tt.request)
+			var paymentErr *PaymentError
+			if !errors.As(err, &paymentErr) || paymentErr.Code != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/types"
+)
+
+// PaymentRequiredError is returned by Gate.RequirePayment when the operation
+// carries no valid payment for a field. Its Extensions method puts the code
+// and challenge in the GraphQL error's extensions, both with gqlgen
+// (graphql.ExtendedError) and graph-gophers/graphql-go (ResolverError).
+type PaymentRequiredError struct {
+	// Message describes why the payment was refused
+	Message string
+
+	// PaymentRequired is the challenge the client can pay
+	PaymentRequired types.PaymentRequired
+}
+
+func (e *PaymentRequiredError) Error() string {
+	return e.Message
+}
+
+// Extensions returns the GraphQL error extensions: code PAYMENT_REQUIRED and
+// the challenge under paymentRequired, as the Pricer's handler returns them
+func (e *PaymentRequiredError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":            CodePaymentRequired,
+		"paymentRequired": e.PaymentRequired,
+	}
+}
+
+// Gate lets resolvers charge for their own fields, for schemas whose prices
+// are known to the resolvers rather than declared with @paid:
+//
+//	gate := graphql.NewGate(server)
+//	http.Handle(graphql.DefaultPath, gate.Middleware(gqlgenServer))
+//
+//	func (r *queryResolver) Report(ctx context.Context, id string) (*model.Report, error) {
+//	    if _, err := r.gate.RequirePayment(ctx, x402http.PaymentOption{
+//	        Scheme: "exact", Network: "eip155:8453", PayTo: payTo, Price: "$0.05",
+//	    }); err != nil {
+//	        return nil, err
+//	    }
+//	    ...
+//	}
+//
+// One payment per operation: it covers every field gated by the same option.
+type Gate struct {
+	server *x402http.HTTPServer
+}
+
+// NewGate creates a gate. server must be initialized; its routes are not used.
+func NewGate(server *x402http.HTTPServer) *Gate {
+	return &Gate{server: server}
+}
+
+type gateContextKey struct{}
+
+// gateState is an operation's payment, shared by its resolvers
+type gateState struct {
+	reqCtx  x402http.HTTPRequestContext
+	payload *types.PaymentPayload
+	invalid string // why the payment header could not be decoded
+
+	mu           sync.Mutex
+	requirements *types.PaymentRequirements // set once a field accepted the payment
+	facilitator  string
+	payer        string
+	challenge    *types.PaymentRequired // set when a field refused the operation
+}
+
+// Middleware makes the request's payment available to RequirePayment, and
+// settles it once the operation has run. Operations in which a field required
+// a payment it did not get are answered with a 402 and the PAYMENT-REQUIRED
+// header, so x402 HTTP clients pay and retry; they are not settled. Paid
+// operations that return no data are not settled either.
+func (g *Gate) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &gateState{reqCtx: x402http.HTTPRequestContext{
+			Adapter: &requestAdapter{r: r},
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}}
+		names := g.server.HeaderNames()
+		signature := r.Header.Get(names.PaymentSignature)
+		if signature == "" {
+			signature = r.Header.Get(headers.PaymentSignature)
+		}
+		if signature != "" {
+			payload, err := headers.DecodePaymentSignature(signature, r.Header.Get(x402http.PaymentEncodingHeader))
+			switch {
+			case err != nil:
+				state.invalid = fmt.Sprintf("Invalid payment: %v", err)
+			case payload.X402Version != 2:
+				state.invalid = fmt.Sprintf("Unsupported x402 version: %d", payload.X402Version)
+			default:
+				state.payload = &payload
+			}
+		}
+
+		ctx := r.Context()
+		capture := &responseCapture{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(capture, r.WithContext(context.WithValue(ctx, gateContextKey{}, state)))
+
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		if state.challenge != nil {
+			if encoded, err := headers.EncodePaymentRequired(*state.challenge); err == nil {
+				capture.header.Set(names.PaymentRequired, encoded)
+			}
+			capture.status = http.StatusPaymentRequired
+			capture.flush(w)
+			return
+		}
+		if state.requirements == nil || !capture.producedData() {
+			capture.flush(w)
+			return
+		}
+
+		settlement := g.server.ProcessSettlement(x402.ContextWithFacilitator(ctx, state.facilitator), *state.payload, *state.requirements)
+		if !settlement.Success {
+			reason := settlement.ErrorReason
+			if reason == "" {
+				reason = "Settlement failed"
+			}
+			writeErrors(w, http.StatusPaymentRequired, CodeSettlementFailed, reason, nil)
+			return
+		}
+		for name, value := range settlement.Headers {
+			w.Header().Set(name, value)
+		}
+		capture.flush(w)
+	})
+}
+
+// RequirePayment charges for a field. Resolvers call it with the options the
+// field can be paid with and return its error as is; it returns the payer
+// once the operation's payment is verified against one of them. Calls outside
+// Middleware fail.
+func (g *Gate) RequirePayment(ctx context.Context, options ...x402http.PaymentOption) (string, error) {
+	state, ok := ctx.Value(gateContextKey{}).(*gateState)
+	if !ok {
+		return "", fmt.Errorf("graphql: RequirePayment called outside the gate's middleware")
+	}
+
+	requirements, err := g.server.BuildPaymentRequirementsFromOptions(ctx, options, state.reqCtx)
+	if err != nil {
+		return "", fmt.Errorf("failed to build payment requirements: %w", err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	refuse := func(message string) error {
+		resource := &types.ResourceInfo{URL: state.reqCtx.Adapter.GetURL(), MimeType: "application/json"}
+		required := g.server.CreatePaymentRequiredResponse(requirements, resource, message, nil)
+		if state.challenge == nil {
+			state.challenge = &required
+		}
+		return &PaymentRequiredError{Message: message, PaymentRequired: required}
+	}
+
+	switch {
+	case state.invalid != "":
+		return "", refuse(state.invalid)
+	case state.payload == nil:
+		return "", refuse("Payment required")
+	}
+	matching := g.server.FindMatchingRequirements(requirements, *state.payload)
+	if matching == nil {
+		return "", refuse("No matching payment requirements found")
+	}
+	if state.requirements != nil {
+		// Already verified for another field gated by the same option
+		return state.payer, nil
+	}
+
+	facilitator := optionFacilitator(options, *matching)
+	verified, err := g.server.VerifyPayment(x402.ContextWithFacilitator(ctx, facilitator), *state.payload, *matching)
+	if err != nil {
+		return "", refuse(err.Error())
+	}
+	if !verified.IsValid {
+		return "", refuse(verified.InvalidReason)
+	}
+	state.requirements = matching
+	state.facilitator = facilitator
+	state.payer = verified.Payer
+	return verified.Payer, nil
+}
+
+// optionFacilitator returns the facilitator named by the option the
+// requirements were built from
+func optionFacilitator(options []x402http.PaymentOption, requirements types.PaymentRequirements) string {
+	for _, option := range options {
+		if option.Facilitator != "" && option.Scheme == requirements.Scheme && string(option.Network) == requirements.Network {
+			return option.Facilitator
+		}
+	}
+	return ""
+}
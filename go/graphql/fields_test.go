@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+// newGatedEndpoint serves a GraphQL stand-in whose "report" resolver charges $1
+func newGatedEndpoint(t *testing.T) *httptest.Server {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+	server := x402http.Newx402HTTPResourceServer(nil,
+		x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)),
+		x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	gate := NewGate(server)
+
+	resolvers := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(req.Query, "report") {
+			_, _ = w.Write([]byte(`{"data":{"weather":"sunny"}}`))
+			return
+		}
+		// A resolver error becomes a GraphQL error with its extensions
+		payer, err := gate.RequirePayment(r.Context(), x402http.PaymentOption{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"})
+		var paymentErr *PaymentRequiredError
+		if errors.As(err, &paymentErr) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":   map[string]interface{}{"report": nil},
+				"errors": []gqlError{{Message: err.Error(), Extensions: paymentErr.Extensions()}},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"payer": payer}})
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(DefaultPath, gate.Middleware(resolvers))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGateChargesResolvers(t *testing.T) {
+	endpoint := newGatedEndpoint(t)
+
+	if resp, response := post(t, http.DefaultClient, endpoint.URL, `{ weather }`); resp.StatusCode != http.StatusOK || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected ungated fields to be served unsettled, got %d %+v", resp.StatusCode, response)
+	}
+
+	resp, response := post(t, http.DefaultClient, endpoint.URL, `{ report }`)
+	if resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" || len(response.Errors) != 1 {
+		t.Fatalf("Expected a 402 GraphQL error, got %d %+v", resp.StatusCode, response)
+	}
+	extensions := response.Errors[0].Extensions
+	required, _ := extensions["paymentRequired"].(map[string]interface{})
+	accepts, _ := required["accepts"].([]interface{})
+	if extensions["code"] != CodePaymentRequired || len(accepts) != 1 {
+		t.Errorf("Expected the field's challenge in the extensions, got %v", extensions)
+	}
+
+	resp, response = post(t, payingClient(), endpoint.URL, `{ report }`)
+	if resp.StatusCode != http.StatusOK || response.Data["payer"] != "~Alice" {
+		t.Fatalf("Expected the paid field to be served, got %d %+v", resp.StatusCode, response)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected the operation to be settled")
+	}
+}
+
+func TestRequirePaymentOutsideMiddleware(t *testing.T) {
+	gate := NewGate(x402http.Newx402HTTPResourceServer(nil))
+	if _, err := gate.RequirePayment(context.Background(), x402http.PaymentOption{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}); err == nil {
+		t.Error("Expected an error outside the middleware")
+	}
+}
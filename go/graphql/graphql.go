@@ -0,0 +1,514 @@
+// Package graphql charges for GraphQL operations with x402. Operations are
+// priced by the fields they select: fields marked with a @paid directive in
+// the schema (or listed in Config.Prices) cost their amount each time they
+// are selected, and every selected field can add a complexity price. Unpaid
+// operations get a 402 whose challenge is in the GraphQL error extensions;
+// paid ones are executed and then settled.
+//
+// Handler wraps any GraphQL http.Handler, such as a gqlgen handler.Server:
+//
+//	//go:embed schema.graphqls
+//	var schemaSDL string
+//
+//	pricer, _ := graphql.NewPricer(graphql.Config{
+//	    Schema:     schemaSDL, // type Query { report(id: ID!): Report @paid(amount: "0.05") }
+//	    FieldPrice: "$0.0001",
+//	    Accepts:    x402http.PaymentOptions{{Scheme: "exact", Network: "eip155:8453", PayTo: payTo}},
+//	})
+//	server := x402http.Newx402HTTPResourceServer(pricer.Routes(), x402.WithFacilitatorClient(facilitator), ...)
+//	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolvers}))
+//	http.Handle(graphql.DefaultPath, pricer.Handler(server, srv))
+//
+// The schema must declare the directive (directive @paid(amount: String!) on
+// FIELD_DEFINITION); with gqlgen, mark it skip_runtime in gqlgen.yml since it
+// needs no resolver.
+//
+// Resolvers that know their own prices can charge instead through a Gate,
+// whose RequirePayment returns an error carrying the challenge in its
+// extensions.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+)
+
+// DefaultPath is where Routes serves the GraphQL endpoint by default
+const DefaultPath = "/graphql"
+
+// Error codes in the extensions of the GraphQL errors the handler returns
+const (
+	CodePaymentRequired  = "PAYMENT_REQUIRED"
+	CodeSettlementFailed = "SETTLEMENT_FAILED"
+	CodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	CodeTooComplex       = "QUERY_TOO_COMPLEX"
+)
+
+// maxRequestBytes bounds request bodies
+const maxRequestBytes = 1 << 20
+
+// Config configures a Pricer
+type Config struct {
+	// Schema is the schema in SDL. Fields with @paid(amount: "0.01") cost
+	// their amount, in USD, each time they are selected. It also gives the
+	// types of nested fields, so Prices can name them.
+	Schema string
+
+	// Prices are USD prices of fields by "Type.field" (e.g. "Query.report"),
+	// overriding @paid
+	Prices map[string]string
+
+	// FieldPrice is a USD price per selected field, charging by query
+	// complexity. Introspection fields are free.
+	FieldPrice string
+
+	// MaxFields refuses operations selecting more fields (0 = no limit)
+	MaxFields int
+
+	// Accepts lists how operations can be paid. Price is set by the pricer.
+	Accepts x402http.PaymentOptions
+
+	// Path is the GraphQL endpoint (default: DefaultPath)
+	Path string
+}
+
+// Pricer prices GraphQL operations and charges for them
+type Pricer struct {
+	config     Config
+	schema     *schema
+	prices     map[string]*big.Rat
+	fieldPrice *big.Rat
+}
+
+// Quote is the price of an operation
+type Quote struct {
+	// Fields is the number of fields the operation selects
+	Fields int
+
+	// Paid lists the priced fields selected, as "Type.field"
+	Paid []string
+
+	// Price is the USD cost (e.g. "$0.050100"), or "" when the operation is free
+	Price string
+}
+
+// NewPricer creates a pricer
+func NewPricer(config Config) (*Pricer, error) {
+	if len(config.Accepts) == 0 {
+		return nil, errors.New("graphql pricing needs at least one payment option")
+	}
+	if config.Path == "" {
+		config.Path = DefaultPath
+	}
+
+	p := &Pricer{config: config, prices: make(map[string]*big.Rat)}
+	var err error
+	if p.schema, err = parseSchema(config.Schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	for field, amount := range p.schema.prices {
+		if p.prices[field], err = parseUSD(amount); err != nil {
+			return nil, fmt.Errorf("@paid on %s: %w", field, err)
+		}
+	}
+	for field, amount := range config.Prices {
+		if p.prices[field], err = parseUSD(amount); err != nil {
+			return nil, fmt.Errorf("price of %s: %w", field, err)
+		}
+	}
+	if config.FieldPrice != "" {
+		if p.fieldPrice, err = parseUSD(config.FieldPrice); err != nil {
+			return nil, fmt.Errorf("invalid field price: %w", err)
+		}
+	}
+	if len(p.prices) == 0 && p.fieldPrice == nil {
+		return nil, errors.New("graphql pricing needs @paid fields, Prices, or a FieldPrice")
+	}
+	return p, nil
+}
+
+// Routes returns the route configuration for the GraphQL endpoint, pricing
+// each operation and binding POSTed payments to the request body
+func (p *Pricer) Routes() x402http.RoutesConfig {
+	accepts := make(x402http.PaymentOptions, len(p.config.Accepts))
+	for i, option := range p.config.Accepts {
+		option.Price = x402http.DynamicPriceFunc(p.price)
+		accepts[i] = option
+	}
+	return x402http.RoutesConfig{
+		"POST " + p.config.Path: {
+			Accepts:         accepts,
+			Description:     "GraphQL operation, priced by the fields it selects",
+			MimeType:        "application/json",
+			BindRequestBody: true,
+		},
+		"GET " + p.config.Path: {
+			Accepts:     accepts,
+			Description: "GraphQL operation, priced by the fields it selects",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// Quote prices an operation of a query document
+func (p *Pricer) Quote(query, operationName string) (*Quote, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return nil, err
+	}
+	op, err := doc.operation(operationName)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Rat)
+	paid := make(map[string]bool)
+	quote := &Quote{}
+	var walk func(selections []selection, parent string, fragments map[string]bool)
+	walk = func(selections []selection, parent string, fragments map[string]bool) {
+		for _, sel := range selections {
+			switch {
+			case sel.spread != "":
+				frag, ok := doc.fragments[sel.spread]
+				if !ok || fragments[sel.spread] {
+					continue
+				}
+				fragments[sel.spread] = true
+				walk(frag.selections, frag.typeCond, fragments)
+				delete(fragments, sel.spread)
+			case sel.field == "":
+				typeCond := sel.typeCond
+				if typeCond == "" {
+					typeCond = parent
+				}
+				walk(sel.children, typeCond, fragments)
+			case strings.HasPrefix(sel.field, "__"):
+				// Introspection is free
+			default:
+				key := parent + "." + sel.field
+				quote.Fields++
+				if price, ok := p.prices[key]; ok {
+					total.Add(total, price)
+					paid[key] = true
+				}
+				walk(sel.children, p.schema.fieldTypes[key], fragments)
+			}
+		}
+	}
+	walk(op.selections, p.schema.roots[op.kind], make(map[string]bool))
+
+	if p.config.MaxFields > 0 && quote.Fields > p.config.MaxFields {
+		return nil, &tooComplexError{fields: quote.Fields, max: p.config.MaxFields}
+	}
+	if p.fieldPrice != nil {
+		total.Add(total, new(big.Rat).Mul(p.fieldPrice, big.NewRat(int64(quote.Fields), 1)))
+	}
+	for key := range paid {
+		quote.Paid = append(quote.Paid, key)
+	}
+	sort.Strings(quote.Paid)
+	if total.Sign() > 0 {
+		quote.Price = formatUSD(total)
+	}
+	return quote, nil
+}
+
+// tooComplexError refuses operations selecting more than MaxFields
+type tooComplexError struct {
+	fields, max int
+}
+
+func (e *tooComplexError) Error() string {
+	return fmt.Sprintf("operation selects %d fields, more than %d", e.fields, e.max)
+}
+
+type quoteContextKey struct{}
+
+// price is the routes' DynamicPriceFunc. Handler quotes each request before
+// processing it; other callers are quoted from the POSTed body.
+func (p *Pricer) price(ctx context.Context, reqCtx x402http.HTTPRequestContext) (x402.Price, error) {
+	quote, ok := ctx.Value(quoteContextKey{}).(*Quote)
+	if !ok {
+		adapter, isBody := reqCtx.Adapter.(x402http.HTTPBodyAdapter)
+		if !isBody {
+			return nil, errors.New("pricing needs the request body")
+		}
+		body, err := adapter.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req, err := decodeRequest(body)
+		if err != nil {
+			return nil, err
+		}
+		if quote, err = p.Quote(req.Query, req.OperationName); err != nil {
+			return nil, err
+		}
+	}
+	if quote.Price == "" {
+		return nil, errors.New("operation is free")
+	}
+	return quote.Price, nil
+}
+
+// request is the part of a GraphQL request the pricer reads
+type request struct {
+	Query         string `json:"query"`
+	OperationName string `json:"operationName"`
+}
+
+func decodeRequest(body []byte) (request, error) {
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return req, fmt.Errorf("invalid GraphQL request: %w", err)
+	}
+	if req.Query == "" {
+		return req, errors.New("GraphQL request has no query (persisted queries cannot be priced)")
+	}
+	return req, nil
+}
+
+// Handler charges for operations served by next, which must be a GraphQL
+// server on Path. server must have been created with Routes and initialized.
+// Free operations (e.g. introspection) pass straight through. Paid
+// operations are executed, then settled if they produced data; operations
+// that fail entirely are not charged.
+func (p *Pricer) Handler(server *x402http.HTTPServer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		adapter := &requestAdapter{r: r}
+		switch r.Method {
+		case http.MethodPost:
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+			body, err := adapter.GetBody()
+			if err != nil {
+				writeErrors(w, http.StatusRequestEntityTooLarge, CodeParseFailed, "request body too large", nil)
+				return
+			}
+			if req, err = decodeRequest(body); err != nil {
+				writeErrors(w, http.StatusBadRequest, CodeParseFailed, err.Error(), nil)
+				return
+			}
+		case http.MethodGet:
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		quote, err := p.Quote(req.Query, req.OperationName)
+		var tooComplex *tooComplexError
+		switch {
+		case errors.As(err, &tooComplex):
+			writeErrors(w, http.StatusBadRequest, CodeTooComplex, err.Error(), nil)
+			return
+		case err != nil:
+			// An operation that cannot be priced is never executed for free
+			writeErrors(w, http.StatusBadRequest, CodeParseFailed, err.Error(), nil)
+			return
+		case quote.Price == "":
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		result := server.ProcessHTTPRequest(context.WithValue(ctx, quoteContextKey{}, quote), x402http.HTTPRequestContext{
+			Adapter: adapter,
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}, nil)
+		switch result.Type {
+		case x402http.ResultPaymentError:
+			writePaymentError(w, result.Response)
+			return
+		case x402http.ResultNoPaymentRequired:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r = r.WithContext(x402http.ContextWithPaymentIdentity(ctx, x402http.NewPaymentIdentity(result)))
+		capture := &responseCapture{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(capture, r)
+		if !capture.producedData() {
+			server.ReleasePayment(ctx, *result.PaymentPayload)
+			capture.flush(w)
+			return
+		}
+
+		settleCtx := x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator)
+		settlement := server.ProcessSettlement(settleCtx, *result.PaymentPayload, *result.PaymentRequirements)
+		if !settlement.Success {
+			reason := settlement.ErrorReason
+			if reason == "" {
+				reason = "Settlement failed"
+			}
+			writeErrors(w, http.StatusPaymentRequired, CodeSettlementFailed, reason, nil)
+			return
+		}
+		for name, value := range settlement.Headers {
+			w.Header().Set(name, value)
+		}
+		capture.flush(w)
+	})
+}
+
+// gqlError is a GraphQL error
+type gqlError struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// writeErrors writes a GraphQL response carrying one error
+func writeErrors(w http.ResponseWriter, status int, code, message string, extensions map[string]interface{}) {
+	if extensions == nil {
+		extensions = make(map[string]interface{}, 1)
+	}
+	extensions["code"] = code
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []gqlError{{Message: message, Extensions: extensions}},
+	})
+}
+
+// writePaymentError writes the server's payment error as a GraphQL error,
+// with the x402 challenge in extensions.paymentRequired and in the
+// PAYMENT-REQUIRED header
+func writePaymentError(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		if key != "Content-Type" {
+			w.Header().Set(key, value)
+		}
+	}
+	header, ok := response.Headers["PAYMENT-REQUIRED"]
+	if !ok {
+		message := http.StatusText(response.Status)
+		if body, ok := response.Body.(map[string]string); ok && body["error"] != "" {
+			message = body["error"]
+		}
+		writeErrors(w, response.Status, CodePaymentRequired, message, nil)
+		return
+	}
+	required, err := headers.DecodePaymentRequired(header, response.Headers[x402http.PaymentEncodingHeader])
+	if err != nil {
+		writeErrors(w, http.StatusInternalServerError, CodePaymentRequired, err.Error(), nil)
+		return
+	}
+	message := "Payment required"
+	if required.Error != "" {
+		message = required.Error
+	}
+	writeErrors(w, response.Status, CodePaymentRequired, message, map[string]interface{}{"paymentRequired": required})
+}
+
+// requestAdapter adapts a net/http request for the x402 HTTP server
+type requestAdapter struct {
+	r *http.Request
+}
+
+func (a *requestAdapter) GetHeader(name string) string { return a.r.Header.Get(name) }
+func (a *requestAdapter) GetMethod() string            { return a.r.Method }
+func (a *requestAdapter) GetPath() string              { return a.r.URL.Path }
+func (a *requestAdapter) GetAcceptHeader() string      { return a.r.Header.Get("Accept") }
+func (a *requestAdapter) GetUserAgent() string         { return a.r.UserAgent() }
+func (a *requestAdapter) GetClientIP() string          { return x402http.RemoteIP(a.r.RemoteAddr) }
+
+func (a *requestAdapter) GetURL() string {
+	scheme := "http"
+	if a.r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + a.r.Host + a.r.URL.Path
+}
+
+// GetBody reads the request body and restores it for the GraphQL server
+func (a *requestAdapter) GetBody() ([]byte, error) {
+	if a.r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(a.r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = a.r.Body.Close()
+	a.r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// responseCapture buffers the GraphQL response until settlement completes
+type responseCapture struct {
+	header  http.Header
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (c *responseCapture) Header() http.Header { return c.header }
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.written {
+		c.status = status
+		c.written = true
+	}
+}
+
+func (c *responseCapture) Write(data []byte) (int, error) {
+	c.written = true
+	return c.body.Write(data)
+}
+
+// producedData reports whether the operation succeeded at least in part
+func (c *responseCapture) producedData() bool {
+	if c.status >= 400 {
+		return false
+	}
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(c.body.Bytes(), &response); err != nil {
+		return false
+	}
+	return len(response.Data) > 0 && string(response.Data) != "null"
+}
+
+func (c *responseCapture) flush(w http.ResponseWriter) {
+	for key, values := range c.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(c.status)
+	_, _ = w.Write(c.body.Bytes())
+}
+
+// parseUSD parses a USD price like "$0.01"
+func parseUSD(price string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimPrefix(strings.TrimSpace(price), "$"))
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid USD price %q", price)
+	}
+	return value, nil
+}
+
+// formatUSD formats a cost as a USD price, rounded up to the micro-dollar
+func formatUSD(cost *big.Rat) string {
+	micros := new(big.Rat).Mul(cost, big.NewRat(1_000_000, 1))
+	whole := new(big.Int).Quo(micros.Num(), micros.Denom())
+	if new(big.Rat).SetInt(whole).Cmp(micros) < 0 {
+		whole.Add(whole, big.NewInt(1))
+	}
+	dollars, rest := new(big.Int).QuoRem(whole, big.NewInt(1_000_000), new(big.Int))
+	return fmt.Sprintf("$%s.%06d", dollars, rest.Int64())
+}
@@ -0,0 +1,217 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+const testSchema = `
+directive @paid(amount: String!) on FIELD_DEFINITION
+
+"""The root query"""
+type Query {
+  weather(city: String!): Weather
+  report(id: ID!): Report @paid(amount: "0.05")
+  users(first: Int = 10): [User!]!
+}
+
+type Mutation {
+  createReport(input: ReportInput!): Report @paid(amount: "$0.25")
+}
+
+type Weather { temperature: Float, forecast: [Forecast!] @paid(amount: 0.01) }
+type Forecast { day: String, high: Float }
+interface Node { id: ID! }
+type Report implements Node & Named @key(fields: "id") { id: ID! name: String pdf: String @paid(amount: "0.02") }
+type User implements Node { id: ID! reports: [Report] }
+input ReportInput { name: String! }
+enum Unit { C F }
+union Result = | Report | User
+scalar Time
+`
+
+func newTestPricer(t *testing.T, config Config) *Pricer {
+	t.Helper()
+	if config.Schema == "" {
+		config.Schema = testSchema
+	}
+	config.Accepts = x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob"}}
+	pricer, err := NewPricer(config)
+	if err != nil {
+		t.Fatalf("Failed to create pricer: %v", err)
+	}
+	return pricer
+}
+
+func TestQuote(t *testing.T) {
+	pricer := newTestPricer(t, Config{FieldPrice: "$0.0001", Prices: map[string]string{"Forecast.high": "0.001"}})
+
+	cases := []struct {
+		query, operation string
+		fields           int
+		price            string
+		paid             []string
+	}{
+		{`{ weather(city: "Paris") { temperature } }`, "", 2, "$0.000200", nil},
+		{`query Q($id: ID!) { r1: report(id: $id) { name pdf } r2: report(id: "2") { id } }`, "", 5, "$0.120500", []string{"Query.report", "Report.pdf"}},
+		{`mutation { createReport(input: {name: "q3"}) { id } }`, "", 2, "$0.250200", []string{"Mutation.createReport"}},
+		{`{ weather(city: "Oslo") { forecast { ...Day } } } fragment Day on Forecast { day high }`, "", 4, "$0.011400", []string{"Forecast.high", "Weather.forecast"}},
+		{`{ users { ... on User { reports { pdf } } ... @include(if: true) { id } } }`, "", 4, "$0.020400", []string{"Report.pdf"}},
+		{`{ __schema { types { name } } __typename }`, "", 0, "", nil},
+		{`query A { users { id } } query B { report(id: "1") { id } }`, "B", 2, "$0.050200", []string{"Query.report"}},
+	}
+	for _, c := range cases {
+		quote, err := pricer.Quote(c.query, c.operation)
+		if err != nil {
+			t.Errorf("Quote(%q): %v", c.query, err)
+			continue
+		}
+		if quote.Fields != c.fields || quote.Price != c.price || !reflect.DeepEqual(quote.Paid, c.paid) {
+			t.Errorf("Quote(%q) = %+v, want %d fields at %q paying for %v", c.query, quote, c.fields, c.price, c.paid)
+		}
+	}
+
+	for _, query := range []string{`{ weather { `, `query A { id } query B { id }`, `{ ...Loop } fragment Loop on Query { ...Loop }`} {
+		if quote, err := pricer.Quote(query, ""); err == nil && quote.Fields != 0 {
+			t.Errorf("Quote(%q) = %+v, expected an error or nothing selected", query, quote)
+		}
+	}
+	if _, err := pricer.Quote(`{ weather { `, ""); err == nil {
+		t.Error("Expected a parse error")
+	}
+	if _, err := pricer.Quote(strings.Repeat("{ a ", 100)+strings.Repeat("}", 100), ""); err == nil {
+		t.Error("Expected deeply nested selections to be refused")
+	}
+
+	limited := newTestPricer(t, Config{FieldPrice: "0.0001", MaxFields: 2})
+	if _, err := limited.Quote(`{ users { id reports { id } } }`, ""); err == nil {
+		t.Error("Expected operations over MaxFields to be refused")
+	}
+}
+
+func TestNewPricerValidatesPrices(t *testing.T) {
+	accepts := x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob"}}
+	for _, config := range []Config{
+		{Schema: `type Query { a: String }`, Accepts: accepts},
+		{Schema: `type Query { a: String @paid }`, Accepts: accepts},
+		{Schema: `type Query { a: String @paid(amount: "free") }`, Accepts: accepts},
+		{Schema: `type Query { a: String`, Accepts: accepts, FieldPrice: "0.01"},
+		{Schema: `type Query { a: String }`, FieldPrice: "0.01"},
+	} {
+		if _, err := NewPricer(config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
+
+// testGraphQLServer answers every operation with data, except those asking
+// for a failure
+var testGraphQLServer = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var req request
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	w.Header().Set("Content-Type", "application/json")
+	if strings.Contains(req.Query, "fail") {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"resolver failed"}],"data":null}`))
+		return
+	}
+	payer := ""
+	if identity, ok := x402http.PaymentIdentityFromContext(r.Context()); ok {
+		payer = identity.Payer
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"payer": payer}})
+})
+
+func newTestEndpoint(t *testing.T) *httptest.Server {
+	t.Helper()
+	pricer := newTestPricer(t, Config{})
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+	server := x402http.Newx402HTTPResourceServer(pricer.Routes(),
+		x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)),
+		x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(DefaultPath, pricer.Handler(server, testGraphQLServer))
+	return httptest.NewServer(mux)
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+type gqlResponse struct {
+	Data   map[string]string `json:"data"`
+	Errors []gqlError        `json:"errors"`
+}
+
+func post(t *testing.T, client *http.Client, url, query string) (*http.Response, gqlResponse) {
+	t.Helper()
+	body, _ := json.Marshal(request{Query: query})
+	resp, err := client.Post(url+DefaultPath, "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	var response gqlResponse
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
+
+func TestHandlerChallengesInErrorExtensions(t *testing.T) {
+	endpoint := newTestEndpoint(t)
+	defer endpoint.Close()
+
+	resp, response := post(t, http.DefaultClient, endpoint.URL, `{ report(id: "1") { pdf } }`)
+	if resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" || len(response.Errors) != 1 {
+		t.Fatalf("Expected a 402 GraphQL error, got %d %+v", resp.StatusCode, response)
+	}
+	extensions := response.Errors[0].Extensions
+	required, _ := extensions["paymentRequired"].(map[string]interface{})
+	accepts, _ := required["accepts"].([]interface{})
+	if extensions["code"] != CodePaymentRequired || len(accepts) != 1 || accepts[0].(map[string]interface{})["amount"] != "0.070000" {
+		t.Errorf("Expected the challenge for $0.07 in the extensions, got %v", extensions)
+	}
+
+	// Free operations are served without payment
+	if resp, response := post(t, http.DefaultClient, endpoint.URL, `{ weather(city: "Oslo") { temperature } }`); resp.StatusCode != http.StatusOK || response.Data == nil {
+		t.Errorf("Expected a free operation to be served, got %d %+v", resp.StatusCode, response)
+	}
+
+	// Unparseable operations are never served for free
+	if resp, response := post(t, http.DefaultClient, endpoint.URL, `{ report(id: "1") { pdf `); resp.StatusCode != http.StatusBadRequest || response.Errors[0].Extensions["code"] != CodeParseFailed {
+		t.Errorf("Expected a parse failure, got %d %+v", resp.StatusCode, response)
+	}
+}
+
+func TestHandlerSettlesAfterExecution(t *testing.T) {
+	endpoint := newTestEndpoint(t)
+	defer endpoint.Close()
+
+	resp, response := post(t, payingClient(), endpoint.URL, `{ report(id: "1") { pdf } }`)
+	if resp.StatusCode != http.StatusOK || response.Data["payer"] != "~Alice" {
+		t.Fatalf("Expected the paid operation to be served, got %d %+v", resp.StatusCode, response)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected the operation to be settled")
+	}
+
+	resp, response = post(t, payingClient(), endpoint.URL, `{ report(id: "fail") { pdf } }`)
+	if resp.StatusCode != http.StatusOK || len(response.Errors) != 1 || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected a failed operation to be relayed unsettled, got %d %+v", resp.StatusCode, response)
+	}
+}
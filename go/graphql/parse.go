@@ -0,0 +1,627 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The parser reads just enough GraphQL to price operations: the schema's
+// field types and @paid directives, and the fields an operation selects.
+// Values, variables, and directives other than @paid are skipped.
+
+// maxDepth bounds selection set nesting, so hostile queries cannot exhaust the stack
+const maxDepth = 64
+
+// ============================================================================
+// Lexer
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenNumber
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// lex splits GraphQL source into tokens, dropping whitespace, commas, and comments
+func lex(source string) ([]token, error) {
+	var tokens []token
+	source = strings.TrimPrefix(source, "\ufeff")
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' && source[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, token{tokenPunct, "..."})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, token{tokenPunct, string(c)})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(source) && (source[i] == '_' || isLetter(source[i]) || isDigit(source[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, source[start:i]})
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+			for i < len(source) && (isDigit(source[i]) || strings.IndexByte(".eE+-", source[i]) >= 0) {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, source[start:i]})
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(source[i+3:], `"""`)
+			for end >= 0 && strings.HasSuffix(source[:i+3+end], `\`) {
+				next := strings.Index(source[i+3+end+3:], `"""`)
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += 3 + next
+			}
+			if end < 0 {
+				return nil, errors.New("unterminated block string")
+			}
+			tokens = append(tokens, token{tokenString, source[i+3 : i+3+end]})
+			i += end + 6
+		case c == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(source) || source[i] == '\n' {
+					return nil, errors.New("unterminated string")
+				}
+				if source[i] == '"' {
+					i++
+					break
+				}
+				if source[i] == '\\' && i+1 < len(source) {
+					switch source[i+1] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(source[i+1])
+					}
+					i += 2
+					continue
+				}
+				b.WriteByte(source[i])
+				i++
+			}
+			tokens = append(tokens, token{tokenString, b.String()})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// parser walks a token stream
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the punctuator or name
+func (p *parser) is(value string) bool {
+	t := p.peek()
+	return (t.kind == tokenPunct || t.kind == tokenName) && t.value == value
+}
+
+func (p *parser) expect(value string) error {
+	if t := p.next(); t.value != value || (t.kind != tokenPunct && t.kind != tokenName) {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return "", fmt.Errorf("expected a name, got %q", t.value)
+	}
+	return t.value, nil
+}
+
+// skipBalanced skips a bracketed group starting at the next token
+func (p *parser) skipBalanced(open, close string) error {
+	if err := p.expect(open); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("unterminated %q", open)
+		case t.kind == tokenPunct && t.value == open:
+			depth++
+		case t.kind == tokenPunct && t.value == close:
+			depth--
+		}
+	}
+	return nil
+}
+
+// directives reads directives, returning the arguments of @paid if present
+func (p *parser) directives() (map[string]token, error) {
+	var paid map[string]token
+	for p.is("@") {
+		p.next()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is("(") {
+			continue
+		}
+		if name != "paid" {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		paid = make(map[string]token)
+		p.next()
+		for !p.is(")") {
+			arg, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			paid[arg] = p.next()
+		}
+		p.next()
+	}
+	return paid, nil
+}
+
+// ============================================================================
+// Schema
+// ============================================================================
+
+// schema holds what pricing needs from a schema: each field's named type and
+// @paid amount
+type schema struct {
+	roots      map[string]string // operation type ("query") to root type name
+	fieldTypes map[string]string // "Type.field" to the field's named type
+	prices     map[string]string // "Type.field" to its @paid amount
+}
+
+// parseSchema reads a schema in SDL
+func parseSchema(sdl string) (*schema, error) {
+	tokens, err := lex(sdl)
+	if err != nil {
+		return nil, err
+	}
+	s := &schema{
+		roots:      map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"},
+		fieldTypes: make(map[string]string),
+		prices:     make(map[string]string),
+	}
+	p := &parser{tokens: tokens}
+	for p.peek().kind != tokenEOF {
+		if p.peek().kind == tokenString {
+			p.next() // description
+			continue
+		}
+		keyword, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "extend":
+			continue
+		case "schema":
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if !p.is("{") {
+				continue
+			}
+			p.next()
+			for !p.is("}") {
+				operation, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if s.roots[operation], err = p.name(); err != nil {
+					return nil, err
+				}
+			}
+			p.next()
+		case "type", "interface":
+			if err := s.parseObject(p); err != nil {
+				return nil, err
+			}
+		case "input", "enum":
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if p.is("{") {
+				if err := p.skipBalanced("{", "}"); err != nil {
+					return nil, err
+				}
+			}
+		case "scalar":
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+		case "union":
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if p.is("=") {
+				p.next()
+				if err := p.nameList(); err != nil {
+					return nil, err
+				}
+			}
+		case "directive":
+			if err := p.expect("@"); err != nil {
+				return nil, err
+			}
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if p.is("(") {
+				if err := p.skipBalanced("(", ")"); err != nil {
+					return nil, err
+				}
+			}
+			if p.is("repeatable") {
+				p.next()
+			}
+			if err := p.expect("on"); err != nil {
+				return nil, err
+			}
+			if err := p.nameList(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in schema", keyword)
+		}
+	}
+	return s, nil
+}
+
+// nameList reads "A | B | C", with an optional leading "|"
+func (p *parser) nameList() error {
+	if p.is("|") {
+		p.next()
+	}
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if !p.is("|") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+// parseObject reads a type or interface definition's fields
+func (s *schema) parseObject(p *parser) error {
+	typeName, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.is("implements") {
+		p.next()
+		if p.is("&") {
+			p.next()
+		}
+		for p.peek().kind == tokenName {
+			p.next()
+			if !p.is("&") {
+				break
+			}
+			p.next()
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if !p.is("{") {
+		return nil
+	}
+	p.next()
+	for !p.is("}") {
+		if p.peek().kind == tokenString {
+			p.next() // description
+			continue
+		}
+		field, err := p.name()
+		if err != nil {
+			return err
+		}
+		if p.is("(") {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		fieldType, err := p.typeRef()
+		if err != nil {
+			return err
+		}
+		paid, err := p.directives()
+		if err != nil {
+			return err
+		}
+		key := typeName + "." + field
+		s.fieldTypes[key] = fieldType
+		if paid != nil {
+			amount, ok := paid["amount"]
+			if !ok || (amount.kind != tokenString && amount.kind != tokenNumber) {
+				return fmt.Errorf("@paid on %s needs an amount", key)
+			}
+			s.prices[key] = amount.value
+		}
+	}
+	p.next()
+	return nil
+}
+
+// typeRef reads a type reference like "[User!]!", returning the named type
+func (p *parser) typeRef() (string, error) {
+	if p.is("[") {
+		p.next()
+		named, err := p.typeRef()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		if p.is("!") {
+			p.next()
+		}
+		return named, nil
+	}
+	named, err := p.name()
+	if err != nil {
+		return "", err
+	}
+	if p.is("!") {
+		p.next()
+	}
+	return named, nil
+}
+
+// ============================================================================
+// Operations
+// ============================================================================
+
+// selection is a field, fragment spread, or inline fragment
+type selection struct {
+	field    string // field name, for fields
+	spread   string // fragment name, for fragment spreads
+	typeCond string // type condition, for inline fragments (may be empty)
+	children []selection
+}
+
+// operation is an executable definition
+type operation struct {
+	kind       string // "query", "mutation", or "subscription"
+	name       string
+	selections []selection
+}
+
+// fragment is a named fragment definition
+type fragment struct {
+	typeCond   string
+	selections []selection
+}
+
+// document is a parsed executable document
+type document struct {
+	operations []operation
+	fragments  map[string]fragment
+}
+
+// parseDocument reads an executable document (queries, mutations, fragments)
+func parseDocument(query string) (*document, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{fragments: make(map[string]fragment)}
+	p := &parser{tokens: tokens}
+	for p.peek().kind != tokenEOF {
+		if p.is("{") {
+			selections, err := p.selectionSet(0)
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, operation{kind: "query", selections: selections})
+			continue
+		}
+		keyword, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "query", "mutation", "subscription":
+			op := operation{kind: keyword}
+			if p.peek().kind == tokenName {
+				op.name = p.next().value
+			}
+			if p.is("(") {
+				if err := p.skipBalanced("(", ")"); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if op.selections, err = p.selectionSet(0); err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("on"); err != nil {
+				return nil, err
+			}
+			var frag fragment
+			if frag.typeCond, err = p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if frag.selections, err = p.selectionSet(0); err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = frag
+		default:
+			return nil, fmt.Errorf("unexpected %q in document", keyword)
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, errors.New("document has no operations")
+	}
+	return doc, nil
+}
+
+// selectionSet reads "{ ... }"
+func (p *parser) selectionSet(depth int) ([]selection, error) {
+	if depth > maxDepth {
+		return nil, errors.New("selections are nested too deeply")
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.is("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, errors.New("unterminated selection set")
+		}
+		var sel selection
+		var err error
+		if p.is("...") {
+			p.next()
+			switch {
+			case p.is("on"):
+				p.next()
+				if sel.typeCond, err = p.name(); err != nil {
+					return nil, err
+				}
+				fallthrough
+			case p.is("@"), p.is("{"):
+				if _, err := p.directives(); err != nil {
+					return nil, err
+				}
+				if sel.children, err = p.selectionSet(depth + 1); err != nil {
+					return nil, err
+				}
+			default:
+				if sel.spread, err = p.name(); err != nil {
+					return nil, err
+				}
+				if _, err := p.directives(); err != nil {
+					return nil, err
+				}
+			}
+			selections = append(selections, sel)
+			continue
+		}
+
+		if sel.field, err = p.name(); err != nil {
+			return nil, err
+		}
+		if p.is(":") {
+			p.next() // the name read was an alias
+			if sel.field, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if p.is("(") {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+		if p.is("{") {
+			if sel.children, err = p.selectionSet(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		selections = append(selections, sel)
+	}
+	p.next()
+	return selections, nil
+}
+
+// operation returns the operation to execute, by name when there are several
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, errors.New("operationName is required for documents with several operations")
+		}
+		return &d.operations[0], nil
+	}
+	for i := range d.operations {
+		if d.operations[i].name == name {
+			return &d.operations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
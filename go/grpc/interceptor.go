@@ -0,0 +1,201 @@
+// Package grpc gates gRPC methods behind x402 payments.
+//
+// UnaryServerInterceptor plays the role the HTTP middleware plays for routes.
+// The payment travels in request metadata under the lowercase HTTP header
+// names, encoded exactly as the headers are (see http/headers):
+//
+//   - a call without a valid payment fails with PaymentRequiredCode, and the
+//     payment-required trailer carries the encoded PaymentRequired challenge
+//   - a paid call runs the handler, settles if it succeeded, and returns the
+//     encoded settlement in the payment-response trailer
+//
+// Methods are priced by their full name:
+//
+//	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(facilitator))
+//	server.Register("eip155:*", evm.NewExactEvmScheme())
+//	_ = server.Initialize(ctx)
+//
+//	s := grpc.NewServer(grpc.UnaryInterceptor(x402grpc.UnaryServerInterceptor(server, x402grpc.MethodsConfig{
+//	    "/weather.v1.Weather/Forecast": {Accepts: []x402.ResourceConfig{{Scheme: "exact", Network: "eip155:8453", PayTo: "0x...", Price: "$0.01"}}},
+//	})))
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/types"
+)
+
+// Metadata keys, the gRPC equivalents of the x402 HTTP headers
+const (
+	// PaymentSignatureKey carries the payment payload (client to server)
+	PaymentSignatureKey = "payment-signature"
+
+	// PaymentEncodingKey marks the payment payload as compressed (gzip or cbor-v1)
+	PaymentEncodingKey = "payment-encoding"
+
+	// PaymentRequiredKey is the trailer carrying the payment challenge
+	PaymentRequiredKey = "payment-required"
+
+	// PaymentResponseKey is the trailer carrying the settlement result
+	PaymentResponseKey = "payment-response"
+)
+
+// PaymentRequiredCode is the status code of calls that need a payment, in
+// place of HTTP's 402
+const PaymentRequiredCode = codes.PermissionDenied
+
+// MethodConfig prices a gRPC method
+type MethodConfig struct {
+	// Accepts lists the payment options for the method
+	Accepts []x402.ResourceConfig
+
+	// Description describes the method in the payment challenge
+	Description string
+
+	// Messages is how many messages a payment buys on a streaming method
+	// (see StreamServerInterceptor). Zero covers the whole stream.
+	Messages int
+}
+
+// MethodsConfig maps full method names ("/package.Service/Method") to their prices.
+// Methods not listed are free.
+type MethodsConfig map[string]MethodConfig
+
+// payerKey is the context key holding the payer of a verified call
+type payerKey struct{}
+
+// PayerFromContext returns the payer of the call, for handlers behind the interceptor
+func PayerFromContext(ctx context.Context) (string, bool) {
+	payer, ok := ctx.Value(payerKey{}).(string)
+	return payer, ok
+}
+
+// UnaryServerInterceptor returns an interceptor charging for the configured
+// methods. The server must be initialized. Calls whose handler returns an
+// error are not settled.
+func UnaryServerInterceptor(server *x402.X402ResourceServer, methods MethodsConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		config, ok := methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		payment, err := verifyPayment(ctx, server, info.FullMethod, config)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(context.WithValue(ctx, payerKey{}, payment.payer), req)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := payment.settle(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(PaymentResponseKey, encoded))
+		return resp, nil
+	}
+}
+
+// verifiedPayment is a payment verified for a call
+type verifiedPayment struct {
+	payload      types.PaymentPayload
+	requirements types.PaymentRequirements
+	payer        string
+}
+
+// verifyPayment verifies the payment in the call's metadata. Without a valid
+// payment it sets the payment-required trailer and returns a
+// PaymentRequiredCode status.
+func verifyPayment(ctx context.Context, server *x402.X402ResourceServer, method string, config MethodConfig) (*verifiedPayment, error) {
+	requirements, err := buildRequirements(ctx, server, config)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build payment requirements: %v", err)
+	}
+	paymentRequired := func(message string) error {
+		return challenge(ctx, server, method, config, requirements, PaymentRequiredCode, message)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	signature := firstValue(md, PaymentSignatureKey)
+	if signature == "" {
+		return nil, paymentRequired("Payment required")
+	}
+	payload, err := headers.DecodePaymentSignature(signature, firstValue(md, PaymentEncodingKey))
+	if err != nil {
+		return nil, paymentRequired(fmt.Sprintf("Invalid payment: %v", err))
+	}
+	if payload.X402Version != 2 {
+		return nil, paymentRequired(fmt.Sprintf("Unsupported x402 version: %d", payload.X402Version))
+	}
+
+	matching := server.FindMatchingRequirements(requirements, payload)
+	if matching == nil {
+		return nil, paymentRequired("No matching payment requirements found")
+	}
+
+	verified, err := server.VerifyPayment(ctx, payload, *matching)
+	if err != nil {
+		return nil, paymentRequired(err.Error())
+	}
+	if !verified.IsValid {
+		return nil, paymentRequired(verified.InvalidReason)
+	}
+	return &verifiedPayment{payload: payload, requirements: *matching, payer: verified.Payer}, nil
+}
+
+// settle settles the payment and returns the encoded settlement
+func (p *verifiedPayment) settle(ctx context.Context, server *x402.X402ResourceServer) (string, error) {
+	settled, err := server.SettlePayment(ctx, p.payload, p.requirements)
+	if err != nil {
+		return "", status.Errorf(PaymentRequiredCode, "settlement failed: %v", err)
+	}
+	encoded, err := headers.EncodePaymentResponse(*settled)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to encode settlement: %v", err)
+	}
+	return encoded, nil
+}
+
+// challenge sets the payment-required trailer and returns a status error
+// with the given code
+func challenge(ctx context.Context, server *x402.X402ResourceServer, method string, config MethodConfig, requirements []types.PaymentRequirements, code codes.Code, message string) error {
+	resource := &types.ResourceInfo{URL: method, Description: config.Description}
+	required := server.CreatePaymentRequiredResponse(requirements, resource, message, nil)
+	if encoded, err := headers.EncodePaymentRequired(required); err == nil {
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(PaymentRequiredKey, encoded))
+	}
+	return status.Error(code, message)
+}
+
+// buildRequirements builds the payment requirements for every accepted option
+func buildRequirements(ctx context.Context, server *x402.X402ResourceServer, config MethodConfig) ([]types.PaymentRequirements, error) {
+	var requirements []types.PaymentRequirements
+	for _, option := range config.Accepts {
+		built, err := server.BuildPaymentRequirementsFromConfig(ctx, option)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, built...)
+	}
+	return requirements, nil
+}
+
+// firstValue returns the first metadata value for a key
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// transportStream records the trailers an interceptor sets
+type transportStream struct {
+	method  string
+	trailer metadata.MD
+}
+
+func (s *transportStream) Method() string                  { return s.method }
+func (s *transportStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *transportStream) SendHeader(md metadata.MD) error { return nil }
+func (s *transportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func newTestServer(t *testing.T) *x402.X402ResourceServer {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+	server := x402.Newx402ResourceServer(
+		x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)),
+		x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	return server
+}
+
+var testAccepts = []x402.ResourceConfig{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}
+
+func newTestInterceptor(t *testing.T) grpc.UnaryServerInterceptor {
+	t.Helper()
+	return UnaryServerInterceptor(newTestServer(t), MethodsConfig{
+		"/weather.v1.Weather/Forecast": {Accepts: testAccepts, Description: "Forecast"},
+		"/weather.v1.Weather/Broken":   {Accepts: testAccepts},
+	})
+}
+
+// pay answers a payment-required trailer with payment metadata from ~Alice
+func pay(t *testing.T, trailer metadata.MD) metadata.MD {
+	t.Helper()
+	challenge, err := headers.DecodePaymentRequired(trailer.Get(PaymentRequiredKey)[0], "")
+	if err != nil || len(challenge.Accepts) != 1 {
+		t.Fatalf("Expected a payment challenge, got %+v, %v", challenge, err)
+	}
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	payload, err := client.CreatePaymentPayload(context.Background(), challenge.Accepts[0], challenge.Resource, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+	signature, err := headers.EncodePaymentSignature(payload)
+	if err != nil {
+		t.Fatalf("Failed to encode payment: %v", err)
+	}
+	return metadata.Pairs(PaymentSignatureKey, signature)
+}
+
+// call runs the interceptor for a method with the given request metadata
+func call(interceptor grpc.UnaryServerInterceptor, method string, md metadata.MD) (interface{}, *transportStream, error) {
+	stream := &transportStream{method: method}
+	ctx := grpc.NewContextWithServerTransportStream(metadata.NewIncomingContext(context.Background(), md), stream)
+	resp, err := interceptor(ctx, "request", &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if method == "/weather.v1.Weather/Broken" {
+			return nil, errors.New("boom")
+		}
+		payer, ok := PayerFromContext(ctx)
+		if !ok {
+			payer = "free"
+		}
+		return payer, nil
+	})
+	return resp, stream, err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := newTestInterceptor(t)
+
+	if resp, _, err := call(interceptor, "/weather.v1.Weather/Today", nil); err != nil || resp != "free" {
+		t.Errorf("Expected an unpriced method to pass through, got %v, %v", resp, err)
+	}
+
+	// An unpaid call fails with the challenge in its trailer
+	_, stream, err := call(interceptor, "/weather.v1.Weather/Forecast", nil)
+	if status.Code(err) != PaymentRequiredCode {
+		t.Fatalf("Expected %v, got %v", PaymentRequiredCode, err)
+	}
+	challenge, err := headers.DecodePaymentRequired(stream.trailer.Get(PaymentRequiredKey)[0], "")
+	if err != nil || challenge.Resource.URL != "/weather.v1.Weather/Forecast" {
+		t.Fatalf("Expected a payment challenge for the method, got %+v, %v", challenge, err)
+	}
+	paid := pay(t, stream.trailer)
+
+	// A paid call reaches the handler and is settled
+	resp, stream, err := call(interceptor, "/weather.v1.Weather/Forecast", paid)
+	if err != nil || resp != "~Alice" {
+		t.Fatalf("Expected the handler to see the payer, got %v, %v", resp, err)
+	}
+	settled, err := headers.DecodePaymentResponse(stream.trailer.Get(PaymentResponseKey)[0])
+	if err != nil || !settled.Success || settled.Payer != "~Alice" {
+		t.Errorf("Expected a settlement trailer, got %+v, %v", settled, err)
+	}
+
+	// A failed handler is not settled
+	if _, stream, err := call(interceptor, "/weather.v1.Weather/Broken", paid); err == nil || len(stream.trailer.Get(PaymentResponseKey)) != 0 {
+		t.Errorf("Expected a failed call not to settle, got %v", err)
+	}
+
+	// A malformed payment gets the challenge again
+	if _, stream, err := call(interceptor, "/weather.v1.Weather/Forecast", metadata.Pairs(PaymentSignatureKey, "not-a-payment")); status.Code(err) != PaymentRequiredCode || len(stream.trailer.Get(PaymentRequiredKey)) != 1 {
+		t.Errorf("Expected a malformed payment to be rejected with a challenge, got %v", err)
+	}
+}
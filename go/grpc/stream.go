@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// QuotaExhaustedCode is the status code ending a stream whose paid messages
+// are used up. The payment-required trailer carries a new challenge, so the
+// client can pay again on a new stream.
+const QuotaExhaustedCode = codes.ResourceExhausted
+
+// StreamServerInterceptor returns an interceptor charging for the configured
+// streaming methods. The payment is verified and settled when the stream
+// opens, with the settlement in the payment-response header, and buys
+// MethodConfig.Messages messages from the server. Once they are sent, further
+// sends fail and the stream ends with QuotaExhaustedCode.
+func StreamServerInterceptor(server *x402.X402ResourceServer, methods MethodsConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		config, ok := methods[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		ctx := ss.Context()
+		payment, err := verifyPayment(ctx, server, info.FullMethod, config)
+		if err != nil {
+			return err
+		}
+
+		// Messages are delivered as they are sent, so the payment is settled up front
+		encoded, err := payment.settle(ctx, server)
+		if err != nil {
+			return err
+		}
+		if err := ss.SetHeader(metadata.Pairs(PaymentResponseKey, encoded)); err != nil {
+			return err
+		}
+
+		stream := &meteredStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ctx, payerKey{}, payment.payer),
+			metered:      config.Messages > 0,
+			remaining:    config.Messages,
+		}
+		err = handler(srv, stream)
+		if !stream.isExhausted() {
+			return err
+		}
+
+		requirements, buildErr := buildRequirements(ctx, server, config)
+		if buildErr != nil {
+			return status.Error(QuotaExhaustedCode, errQuotaExhausted)
+		}
+		return challenge(ctx, server, info.FullMethod, config, requirements, QuotaExhaustedCode, errQuotaExhausted)
+	}
+}
+
+// errQuotaExhausted is the status message of a stream out of paid messages
+const errQuotaExhausted = "Paid message quota exhausted"
+
+// meteredStream counts the messages the handler sends against the paid quota
+type meteredStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	metered bool
+
+	mu        sync.Mutex
+	remaining int
+	exhausted bool
+}
+
+// Context returns the stream context carrying the payer
+func (s *meteredStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends a message if the quota has room for it
+func (s *meteredStream) SendMsg(m interface{}) error {
+	if s.metered {
+		s.mu.Lock()
+		if s.remaining == 0 {
+			s.exhausted = true
+			s.mu.Unlock()
+			return status.Error(QuotaExhaustedCode, errQuotaExhausted)
+		}
+		s.remaining--
+		s.mu.Unlock()
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// isExhausted reports whether the handler tried to send past the quota
+func (s *meteredStream) isExhausted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exhausted
+}
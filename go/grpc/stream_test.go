@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/coinbase/x402/go/http/headers"
+)
+
+// serverStream is a stream whose messages and metadata are recorded
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+	sent   []interface{}
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+func (s *serverStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+func (s *serverStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+// openStream runs the interceptor for a handler that sends five messages
+func openStream(interceptor grpc.StreamServerInterceptor, method string, md metadata.MD) (*serverStream, *transportStream, string, error) {
+	transport := &transportStream{method: method}
+	ss := &serverStream{ctx: grpc.NewContextWithServerTransportStream(metadata.NewIncomingContext(context.Background(), md), transport)}
+	var payer string
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+		payer, _ = PayerFromContext(stream.Context())
+		for i := 0; i < 5; i++ {
+			if err := stream.SendMsg(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ss, transport, payer, err
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(newTestServer(t), MethodsConfig{
+		"/ticker.v1.Ticker/Quotes": {Accepts: testAccepts, Messages: 3},
+		"/ticker.v1.Ticker/Feed":   {Accepts: testAccepts},
+	})
+
+	if ss, _, _, err := openStream(interceptor, "/ticker.v1.Ticker/Free", nil); err != nil || len(ss.sent) != 5 {
+		t.Errorf("Expected an unpriced stream to pass through, got %d messages, %v", len(ss.sent), err)
+	}
+
+	_, transport, _, err := openStream(interceptor, "/ticker.v1.Ticker/Quotes", nil)
+	if status.Code(err) != PaymentRequiredCode {
+		t.Fatalf("Expected %v, got %v", PaymentRequiredCode, err)
+	}
+	paid := pay(t, transport.trailer)
+
+	// The payment is settled up front and buys three messages
+	ss, transport, payer, err := openStream(interceptor, "/ticker.v1.Ticker/Quotes", paid)
+	if status.Code(err) != QuotaExhaustedCode || len(ss.sent) != 3 || payer != "~Alice" {
+		t.Fatalf("Expected the stream to end after 3 messages, got %d messages, payer %q, %v", len(ss.sent), payer, err)
+	}
+	if settled, err := headers.DecodePaymentResponse(ss.header.Get(PaymentResponseKey)[0]); err != nil || !settled.Success {
+		t.Errorf("Expected a settlement header, got %+v, %v", settled, err)
+	}
+	if len(transport.trailer.Get(PaymentRequiredKey)) != 1 {
+		t.Error("Expected a new payment challenge when the quota ran out")
+	}
+
+	// Without a message count the payment covers the whole stream
+	if ss, _, _, err := openStream(interceptor, "/ticker.v1.Ticker/Feed", paid); err != nil || len(ss.sent) != 5 {
+		t.Errorf("Expected an unmetered stream to run to the end, got %d messages, %v", len(ss.sent), err)
+	}
+}
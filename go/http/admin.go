@@ -0,0 +1,340 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Admin API
+// ============================================================================
+
+// Admin endpoint names, served under any mount prefix (e.g. /admin/routes)
+const (
+	AdminEndpointRoutes             = "routes"
+	AdminEndpointPendingSettlements = "pending-settlements"
+	AdminEndpointFailures           = "failures"
+	AdminEndpointCredits            = "credits"
+	AdminEndpointLiabilities        = "liabilities"
+)
+
+// CreditBalanceSource reports prepaid credit balances for the admin API
+type CreditBalanceSource interface {
+	// CreditBalances returns balances keyed by client identifier
+	CreditBalances(ctx context.Context) (map[string]string, error)
+}
+
+// AdminConfig configures the admin API
+type AdminConfig struct {
+	// Token is the bearer token required on every request. An empty token
+	// rejects all requests, so the API is never accidentally left open.
+	Token string
+
+	// MaxRecentFailures bounds the failure log (default: 100)
+	MaxRecentFailures int
+
+	// PendingSettlementTTL drops verified payments that were never settled,
+	// e.g. because the handler failed (default: 10 minutes)
+	PendingSettlementTTL time.Duration
+
+	// Credits supplies credit balances (optional)
+	Credits CreditBalanceSource
+}
+
+// AdminRoute describes a protected route and its prices
+type AdminRoute struct {
+	Method      string              `json:"method"`
+	Host        string              `json:"host,omitempty"`
+	Pattern     string              `json:"pattern"`
+	Resource    string              `json:"resource,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Accepts     []AdminPaymentPrice `json:"accepts"`
+}
+
+// AdminPaymentPrice describes one payment option. Dynamic payTo or price
+// functions are reported as "dynamic".
+type AdminPaymentPrice struct {
+	Scheme  string      `json:"scheme"`
+	Network string      `json:"network"`
+	PayTo   interface{} `json:"payTo"`
+	Price   interface{} `json:"price"`
+}
+
+// AdminPendingSettlement is a verified payment awaiting settlement
+type AdminPendingSettlement struct {
+	Payer      string    `json:"payer,omitempty"`
+	Scheme     string    `json:"scheme"`
+	Network    string    `json:"network"`
+	Amount     string    `json:"amount"`
+	PayTo      string    `json:"payTo"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// AdminFailure is a recent verify or settle failure
+type AdminFailure struct {
+	Operation string    `json:"operation"` // "verify" or "settle"
+	Scheme    string    `json:"scheme"`
+	Network   string    `json:"network"`
+	Amount    string    `json:"amount"`
+	Error     string    `json:"error"`
+	At        time.Time `json:"at"`
+}
+
+// AdminHandler serves the admin API for a resource server.
+//
+// Mount it under a prefix of your choice:
+//
+//	admin := x402http.NewAdminHandler(server, x402http.AdminConfig{Token: os.Getenv("X402_ADMIN_TOKEN")})
+//	mux.Handle("/admin/", admin)
+type AdminHandler struct {
+	server *x402HTTPResourceServer
+	config AdminConfig
+
+	mu       sync.Mutex
+	pending  map[string]AdminPendingSettlement
+	failures []AdminFailure
+	now      func() time.Time
+}
+
+// NewAdminHandler creates the admin API and registers the hooks it uses to
+// track pending settlements and failures on the server
+func NewAdminHandler(server *x402HTTPResourceServer, config AdminConfig) *AdminHandler {
+	if config.MaxRecentFailures <= 0 {
+		config.MaxRecentFailures = 100
+	}
+	if config.PendingSettlementTTL <= 0 {
+		config.PendingSettlementTTL = 10 * time.Minute
+	}
+
+	h := &AdminHandler{
+		server:  server,
+		config:  config,
+		pending: make(map[string]AdminPendingSettlement),
+		now:     time.Now,
+	}
+
+	server.OnAfterVerify(h.onAfterVerify)
+	server.OnVerifyFailure(h.onVerifyFailure)
+	server.OnAfterSettle(h.onAfterSettle)
+	server.OnSettleFailure(h.onSettleFailure)
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeAdminJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeAdminJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	switch path.Base(r.URL.Path) {
+	case AdminEndpointRoutes:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"routes": h.Routes()})
+	case AdminEndpointPendingSettlements:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"pendingSettlements": h.PendingSettlements()})
+	case AdminEndpointFailures:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"failures": h.RecentFailures()})
+	case AdminEndpointLiabilities:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"liabilities": h.server.AccruedLiabilities()})
+	case AdminEndpointCredits:
+		if h.config.Credits == nil {
+			writeAdminJSON(w, http.StatusNotImplemented, map[string]string{"error": "credit balances are not configured"})
+			return
+		}
+		balances, err := h.config.Credits.CreditBalances(r.Context())
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"credits": balances})
+	default:
+		writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+// Routes returns the protected routes and their prices, sorted by pattern
+func (h *AdminHandler) Routes() []AdminRoute {
+	routes := make([]AdminRoute, 0, len(h.server.compiledRoutes))
+	for _, compiled := range h.server.compiledRoutes {
+		route := AdminRoute{
+			Method:      compiled.Verb,
+			Host:        compiled.Host,
+			Pattern:     compiled.Pattern,
+			Resource:    compiled.Config.Resource,
+			Description: compiled.Config.Description,
+			Accepts:     make([]AdminPaymentPrice, 0, len(compiled.Config.Accepts)),
+		}
+		for _, option := range compiled.Config.Accepts {
+			price := AdminPaymentPrice{
+				Scheme:  option.Scheme,
+				Network: string(option.Network),
+				PayTo:   option.PayTo,
+				Price:   option.Price,
+			}
+			if _, ok := option.PayTo.(DynamicPayToFunc); ok {
+				price.PayTo = "dynamic"
+			}
+			if _, ok := option.Price.(DynamicPriceFunc); ok {
+				price.Price = "dynamic"
+			}
+			route.Accepts = append(route.Accepts, price)
+		}
+		routes = append(routes, route)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Host != routes[j].Host {
+			return routes[i].Host < routes[j].Host
+		}
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// PendingSettlements returns verified payments not yet settled, oldest first
+func (h *AdminHandler) PendingSettlements() []AdminPendingSettlement {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prunePendingLocked()
+
+	pending := make([]AdminPendingSettlement, 0, len(h.pending))
+	for _, p := range h.pending {
+		pending = append(pending, p)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].VerifiedAt.Before(pending[j].VerifiedAt)
+	})
+	return pending
+}
+
+// RecentFailures returns recent verify and settle failures, newest first
+func (h *AdminHandler) RecentFailures() []AdminFailure {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	failures := make([]AdminFailure, len(h.failures))
+	for i, f := range h.failures {
+		failures[len(h.failures)-1-i] = f
+	}
+	return failures
+}
+
+// ============================================================================
+// Hooks
+// ============================================================================
+
+func (h *AdminHandler) onAfterVerify(ctx x402.VerifyResultContext) error {
+	payer := ""
+	if ctx.Result != nil {
+		payer = ctx.Result.Payer
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prunePendingLocked()
+	h.pending[adminPaymentKey(ctx.PayloadBytes)] = AdminPendingSettlement{
+		Payer:      payer,
+		Scheme:     ctx.Requirements.GetScheme(),
+		Network:    ctx.Requirements.GetNetwork(),
+		Amount:     ctx.Requirements.GetAmount(),
+		PayTo:      ctx.Requirements.GetPayTo(),
+		VerifiedAt: h.now(),
+	}
+	return nil
+}
+
+func (h *AdminHandler) onVerifyFailure(ctx x402.VerifyFailureContext) (*x402.VerifyFailureHookResult, error) {
+	h.recordFailure("verify", ctx.Requirements, ctx.Error)
+	return nil, nil
+}
+
+func (h *AdminHandler) onAfterSettle(ctx x402.SettleResultContext) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.pending, adminPaymentKey(ctx.PayloadBytes))
+	return nil
+}
+
+func (h *AdminHandler) onSettleFailure(ctx x402.SettleFailureContext) (*x402.SettleFailureHookResult, error) {
+	h.mu.Lock()
+	delete(h.pending, adminPaymentKey(ctx.PayloadBytes))
+	h.mu.Unlock()
+
+	h.recordFailure("settle", ctx.Requirements, ctx.Error)
+	return nil, nil
+}
+
+// recordFailure appends to the bounded failure log
+func (h *AdminHandler) recordFailure(operation string, requirements x402.PaymentRequirementsView, err error) {
+	failure := AdminFailure{
+		Operation: operation,
+		Scheme:    requirements.GetScheme(),
+		Network:   requirements.GetNetwork(),
+		Amount:    requirements.GetAmount(),
+		At:        h.now(),
+	}
+	if err != nil {
+		failure.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures = append(h.failures, failure)
+	if overflow := len(h.failures) - h.config.MaxRecentFailures; overflow > 0 {
+		h.failures = append([]AdminFailure(nil), h.failures[overflow:]...)
+	}
+}
+
+// prunePendingLocked drops pending settlements older than the TTL
+func (h *AdminHandler) prunePendingLocked() {
+	cutoff := h.now().Add(-h.config.PendingSettlementTTL)
+	for key, p := range h.pending {
+		if p.VerifiedAt.Before(cutoff) {
+			delete(h.pending, key)
+		}
+	}
+}
+
+// authorized checks the bearer token in constant time
+func (h *AdminHandler) authorized(r *http.Request) bool {
+	if h.config.Token == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Token)) == 1
+}
+
+// adminPaymentKey identifies a payment across its verify and settle hooks
+func adminPaymentKey(payloadBytes []byte) string {
+	sum := sha256.Sum256(payloadBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
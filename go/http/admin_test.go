@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+type staticCredits map[string]string
+
+func (s staticCredits) CreditBalances(ctx context.Context) (map[string]string, error) {
+	return s, nil
+}
+
+func newAdminTestServer(t *testing.T, facilitator *mockFacilitatorClient) *x402HTTPResourceServer {
+	t.Helper()
+	routes := RoutesConfig{
+		"GET /weather": {
+			Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$0.01", Network: "eip155:1"},
+			},
+			Description: "Weather",
+		},
+		"POST /generate": {
+			Accepts: PaymentOptions{
+				{
+					Scheme:  "exact",
+					PayTo:   "0xtest",
+					Network: "eip155:1",
+					Price: DynamicPriceFunc(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+						return "$1.00", nil
+					}),
+				},
+			},
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(facilitator),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func adminGet(t *testing.T, handler http.Handler, path string, token string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("invalid JSON from %s: %v", path, err)
+		}
+	}
+	return w.Code
+}
+
+func TestAdminHandlerAuth(t *testing.T) {
+	server := newAdminTestServer(t, &mockFacilitatorClient{})
+
+	admin := NewAdminHandler(server, AdminConfig{Token: "secret"})
+	if code := adminGet(t, admin, "/admin/routes", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code := adminGet(t, admin, "/admin/routes", "wrong", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", code)
+	}
+	if code := adminGet(t, admin, "/admin/routes", "secret", nil); code != http.StatusOK {
+		t.Errorf("expected 200 with token, got %d", code)
+	}
+
+	open := NewAdminHandler(server, AdminConfig{})
+	if code := adminGet(t, open, "/admin/routes", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected empty token to reject all requests, got %d", code)
+	}
+}
+
+func TestAdminHandlerRoutes(t *testing.T) {
+	admin := NewAdminHandler(newAdminTestServer(t, &mockFacilitatorClient{}), AdminConfig{Token: "secret"})
+
+	var body struct {
+		Routes []AdminRoute `json:"routes"`
+	}
+	adminGet(t, admin, "/admin/routes", "secret", &body)
+
+	if len(body.Routes) != 2 {
+		t.Fatalf("expected 2 routes, got %+v", body.Routes)
+	}
+	prices := map[string]interface{}{}
+	for _, route := range body.Routes {
+		prices[route.Method] = route.Accepts[0].Price
+	}
+	if prices["GET"] != "$0.01" || prices["POST"] != "dynamic" {
+		t.Errorf("unexpected prices %v", prices)
+	}
+	if body.Routes[0].Pattern != "GET /weather" || body.Routes[1].Pattern != "POST /generate" {
+		t.Errorf("expected configured route patterns, got %q and %q", body.Routes[0].Pattern, body.Routes[1].Pattern)
+	}
+}
+
+func TestAdminHandlerTracksSettlementsAndFailures(t *testing.T) {
+	facilitator := &mockFacilitatorClient{}
+	server := newAdminTestServer(t, facilitator)
+	admin := NewAdminHandler(server, AdminConfig{Token: "secret", MaxRecentFailures: 2})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Amount: "10000", PayTo: "0xtest"}
+	payload := types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"sig": "1"}, Accepted: requirements}
+
+	if _, err := server.VerifyPayment(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+
+	var pending struct {
+		PendingSettlements []AdminPendingSettlement `json:"pendingSettlements"`
+	}
+	adminGet(t, admin, "/admin/pending-settlements", "secret", &pending)
+	if len(pending.PendingSettlements) != 1 || pending.PendingSettlements[0].Payer != "0xmock" {
+		t.Fatalf("expected one pending settlement, got %+v", pending.PendingSettlements)
+	}
+
+	if _, err := server.SettlePayment(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("unexpected settle error: %v", err)
+	}
+	adminGet(t, admin, "/admin/pending-settlements", "secret", &pending)
+	if len(pending.PendingSettlements) != 0 {
+		t.Errorf("expected settlement to clear pending entry, got %+v", pending.PendingSettlements)
+	}
+
+	facilitator.settle = func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+		return nil, errors.New("facilitator unavailable")
+	}
+	for i := 0; i < 3; i++ {
+		_, _ = server.SettlePayment(context.Background(), payload, requirements)
+	}
+
+	var failures struct {
+		Failures []AdminFailure `json:"failures"`
+	}
+	adminGet(t, admin, "/admin/failures", "secret", &failures)
+	if len(failures.Failures) != 2 {
+		t.Fatalf("expected failure log bounded to 2, got %d", len(failures.Failures))
+	}
+	if failures.Failures[0].Operation != "settle" || failures.Failures[0].Error != "facilitator unavailable" {
+		t.Errorf("unexpected failure %+v", failures.Failures[0])
+	}
+}
+
+func TestAdminHandlerPendingTTL(t *testing.T) {
+	server := newAdminTestServer(t, &mockFacilitatorClient{})
+	admin := NewAdminHandler(server, AdminConfig{Token: "secret", PendingSettlementTTL: time.Minute})
+
+	now := time.Now()
+	admin.now = func() time.Time { return now }
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Amount: "10000", PayTo: "0xtest"}
+	payload := types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"sig": "1"}, Accepted: requirements}
+	_, _ = server.VerifyPayment(context.Background(), payload, requirements)
+
+	now = now.Add(2 * time.Minute)
+	if pending := admin.PendingSettlements(); len(pending) != 0 {
+		t.Errorf("expected stale pending settlement to be dropped, got %+v", pending)
+	}
+}
+
+func TestAdminHandlerCredits(t *testing.T) {
+	server := newAdminTestServer(t, &mockFacilitatorClient{})
+
+	without := NewAdminHandler(server, AdminConfig{Token: "secret"})
+	if code := adminGet(t, without, "/admin/credits", "secret", nil); code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without credit source, got %d", code)
+	}
+
+	with := NewAdminHandler(server, AdminConfig{Token: "secret", Credits: staticCredits{"key-1": "5.00"}})
+	var body struct {
+		Credits map[string]string `json:"credits"`
+	}
+	adminGet(t, with, "/admin/credits", "secret", &body)
+	if body.Credits["key-1"] != "5.00" {
+		t.Errorf("unexpected credits %v", body.Credits)
+	}
+}
+
+func TestAdminHandlerLiabilities(t *testing.T) {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithDeferredSettlement(x402.DeferredSettlementConfig{Threshold: "1000"}),
+	)
+	_ = server.Initialize(context.Background())
+	admin := NewAdminHandler(server, AdminConfig{Token: "secret"})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "0xusdc", Amount: "10", PayTo: "0xtest", MaxTimeoutSeconds: 600}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{
+		"authorization": map[string]interface{}{"from": "0xpayer", "nonce": "0x01"},
+	}}
+	result := server.ProcessSettlement(context.Background(), payload, requirements)
+	if !result.Success || result.Transaction != "" {
+		t.Fatalf("expected a deferred settlement, got %+v", result)
+	}
+
+	var body struct {
+		Liabilities []x402.AccruedLiability `json:"liabilities"`
+	}
+	if code := adminGet(t, admin, "/admin/liabilities", "secret", &body); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(body.Liabilities) != 1 || body.Liabilities[0].Payer != "0xpayer" || body.Liabilities[0].Amount != "10" {
+		t.Errorf("unexpected liabilities %+v", body.Liabilities)
+	}
+	_ = server.SettleAccrued(context.Background())
+}
@@ -0,0 +1,422 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// API Keys
+// ============================================================================
+
+// APIKeyPrefix starts every API key secret, which has the form x402_<id>_<secret>
+const APIKeyPrefix = "x402_"
+
+// Prefixes namespacing API key records, usage counters, and rate windows in the store
+const (
+	apiKeyStorePrefix = "x402:api-key:"
+	apiKeyUsagePrefix = "x402:api-key-usage:"
+	apiKeyRatePrefix  = "x402:api-key-rate:"
+)
+
+// APIKeyScope allows a key to be used for a scheme and network. Empty fields
+// match anything, and Network may be a wildcard family (e.g. "eip155:*").
+type APIKeyScope struct {
+	Scheme  string `json:"scheme,omitempty"`
+	Network string `json:"network,omitempty"`
+}
+
+// APIKeySpec describes a key to create
+type APIKeySpec struct {
+	// Name labels the key, e.g. with the customer it was issued to
+	Name string
+
+	// Scopes limit the schemes and networks the key may be used for (default: all)
+	Scopes []APIKeyScope
+
+	// RateLimit is the number of requests per minute the key may make (0 = unlimited)
+	RateLimit int64
+}
+
+// APIKey is a stored API key. The secret itself is never stored.
+type APIKey struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes,omitempty"`
+	RateLimit int64         `json:"rateLimit,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	RotatedAt time.Time     `json:"rotatedAt,omitempty"`
+	RevokedAt time.Time     `json:"revokedAt,omitempty"`
+
+	// SecretHash is the SHA-256 of the current secret
+	SecretHash string `json:"secretHash"`
+
+	// PreviousSecretHash keeps the secret replaced by a rotation valid until PreviousExpiresAt
+	PreviousSecretHash string    `json:"previousSecretHash,omitempty"`
+	PreviousExpiresAt  time.Time `json:"previousExpiresAt,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Allows reports whether the key's scopes include the scheme and network
+func (k *APIKey) Allows(scheme, network string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range k.Scopes {
+		if scope.Scheme != "" && scope.Scheme != scheme {
+			continue
+		}
+		if scope.Network == "" || scope.Network == network ||
+			(x402.IsWildcardNetwork(x402.Network(scope.Network)) && x402.MatchesNetwork(x402.Network(scope.Network), x402.Network(network))) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyConfig configures an APIKeyManager
+type APIKeyConfig struct {
+	// Store holds keys and usage counters (default: in-memory). Use a shared,
+	// persistent Store in production.
+	Store Store
+
+	// RotationGrace keeps a rotated key's previous secret valid for this long
+	// so clients can switch over (default: 0, invalid immediately)
+	RotationGrace time.Duration
+}
+
+// APIKeyManager creates, rotates, and revokes facilitator API keys and
+// authenticates requests with them.
+//
+//	keys := x402http.NewAPIKeyManager(x402http.APIKeyConfig{Store: store})
+//	secret, key, _ := keys.Create(ctx, x402http.APIKeySpec{Name: "acme", RateLimit: 600})
+//	http.ListenAndServe(":4022", keys.Handler(mux))
+type APIKeyManager struct {
+	store         Store
+	rotationGrace time.Duration
+	now           func() time.Time
+}
+
+// NewAPIKeyManager creates an API key manager
+func NewAPIKeyManager(config APIKeyConfig) *APIKeyManager {
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &APIKeyManager{store: store, rotationGrace: config.RotationGrace, now: time.Now}
+}
+
+// Create issues a new key, returning its secret. The secret is only available
+// here; store it with the client.
+func (m *APIKeyManager) Create(ctx context.Context, spec APIKeySpec) (string, *APIKey, error) {
+	id, err := randomToken(9)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, hash, err := newAPIKeySecret(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &APIKey{
+		ID:         id,
+		Name:       spec.Name,
+		Scopes:     spec.Scopes,
+		RateLimit:  spec.RateLimit,
+		CreatedAt:  m.now().UTC(),
+		SecretHash: hash,
+	}
+	if err := m.save(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Get returns a key by ID
+func (m *APIKeyManager) Get(ctx context.Context, id string) (*APIKey, error) {
+	raw, ok, err := m.store.Get(ctx, apiKeyStorePrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key %s: %w", id, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("api key %s not found", id)
+	}
+	var key APIKey
+	if err := json.Unmarshal([]byte(raw), &key); err != nil {
+		return nil, fmt.Errorf("failed to decode api key %s: %w", id, err)
+	}
+	return &key, nil
+}
+
+// Rotate replaces a key's secret, keeping its ID, scopes, limits, and usage.
+// The previous secret stays valid for RotationGrace.
+func (m *APIKeyManager) Rotate(ctx context.Context, id string) (string, *APIKey, error) {
+	key, err := m.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if key.Revoked() {
+		return "", nil, fmt.Errorf("api key %s is revoked", id)
+	}
+	secret, hash, err := newAPIKeySecret(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := m.now().UTC()
+	key.PreviousSecretHash, key.PreviousExpiresAt = "", time.Time{}
+	if m.rotationGrace > 0 {
+		key.PreviousSecretHash = key.SecretHash
+		key.PreviousExpiresAt = now.Add(m.rotationGrace)
+	}
+	key.SecretHash = hash
+	key.RotatedAt = now
+	if err := m.save(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Revoke permanently disables a key
+func (m *APIKeyManager) Revoke(ctx context.Context, id string) error {
+	key, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.Revoked() {
+		return nil
+	}
+	key.RevokedAt = m.now().UTC()
+	return m.save(ctx, key)
+}
+
+// Authenticate returns the active key a secret belongs to
+func (m *APIKeyManager) Authenticate(ctx context.Context, secret string) (*APIKey, error) {
+	id, ok := apiKeyID(secret)
+	if !ok {
+		return nil, fmt.Errorf("malformed api key")
+	}
+	key, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked() {
+		return nil, fmt.Errorf("api key %s is revoked", id)
+	}
+
+	hash := hashAPIKeySecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(key.SecretHash)) == 1 {
+		return key, nil
+	}
+	if key.PreviousSecretHash != "" && m.now().Before(key.PreviousExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(hash), []byte(key.PreviousSecretHash)) == 1 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("invalid secret for api key %s", id)
+}
+
+// RecordUsage counts a request made with a key, in total and per operation
+func (m *APIKeyManager) RecordUsage(ctx context.Context, id, operation string) error {
+	if _, err := m.store.Increment(ctx, apiKeyUsagePrefix+id, 1, 0); err != nil {
+		return err
+	}
+	if operation == "" {
+		return nil
+	}
+	_, err := m.store.Increment(ctx, apiKeyUsagePrefix+id+":"+operation, 1, 0)
+	return err
+}
+
+// Usage returns the number of requests made with a key for an operation, or
+// in total when operation is empty
+func (m *APIKeyManager) Usage(ctx context.Context, id, operation string) (int64, error) {
+	key := apiKeyUsagePrefix + id
+	if operation != "" {
+		key += ":" + operation
+	}
+	raw, ok, err := m.store.Get(ctx, key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+// Handler authenticates requests by the Authorization bearer token or the
+// X-API-Key header, enforcing the key's scopes (from the request's payment
+// requirements, or scheme and network for /quote) and rate limit, and counting
+// usage per endpoint. Scoped keys are denied requests other than GETs whose
+// scheme and network cannot be read. Rejections are 401, 403, 413, or 429
+// responses.
+// The key is available to next via APIKeyFromContext.
+func (m *APIKeyManager) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		key, err := m.Authenticate(ctx, apiKeyFromRequest(r))
+		if err != nil {
+			writeAPIKeyError(w, http.StatusUnauthorized, "invalid_api_key")
+			return
+		}
+
+		// Scoped keys may only reach read-only endpoints such as /supported and
+		// requests whose scheme and network are within scope
+		if len(key.Scopes) > 0 && r.Method != http.MethodGet {
+			scheme, network, err := requestSchemeNetwork(r)
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				writeAPIKeyError(w, http.StatusRequestEntityTooLarge, "request_body_too_large")
+				return
+			}
+			if err != nil {
+				writeAPIKeyError(w, http.StatusBadRequest, "invalid_request_body")
+				return
+			}
+			if !key.Allows(scheme, network) {
+				writeAPIKeyError(w, http.StatusForbidden, "api_key_scope")
+				return
+			}
+		}
+
+		if key.RateLimit > 0 {
+			if retryAfter, ok := m.allowRate(ctx, key); !ok {
+				writeTooManyRequests(w, "rate_limited", retryAfter)
+				return
+			}
+		}
+
+		_ = m.RecordUsage(ctx, key.ID, path.Base(r.URL.Path))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, apiKeyContextKey{}, key)))
+	})
+}
+
+type apiKeyContextKey struct{}
+
+// APIKeyFromContext returns the key that authenticated a request served by
+// APIKeyManager.Handler
+func APIKeyFromContext(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key, ok
+}
+
+// allowRate counts the request in the key's per-minute window. Store errors
+// let requests through.
+func (m *APIKeyManager) allowRate(ctx context.Context, key *APIKey) (time.Duration, bool) {
+	now := m.now()
+	start := now.Truncate(time.Minute)
+	reset := start.Add(time.Minute).Sub(now)
+	used, err := m.store.Increment(ctx, apiKeyRatePrefix+key.ID+":"+strconv.FormatInt(start.Unix(), 10), 1, reset)
+	if err != nil {
+		return 0, true
+	}
+	return reset, used <= key.RateLimit
+}
+
+func (m *APIKeyManager) save(ctx context.Context, key *APIKey) error {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key %s: %w", key.ID, err)
+	}
+	if err := m.store.Set(ctx, apiKeyStorePrefix+key.ID, string(raw), 0); err != nil {
+		return fmt.Errorf("failed to store api key %s: %w", key.ID, err)
+	}
+	return nil
+}
+
+// newAPIKeySecret generates a secret for a key ID and its hash
+func newAPIKeySecret(id string) (string, string, error) {
+	token, err := randomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+	secret := APIKeyPrefix + id + "_" + token
+	return secret, hashAPIKeySecret(secret), nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyID extracts the key ID from a secret
+func apiKeyID(secret string) (string, bool) {
+	rest, ok := strings.CutPrefix(secret, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	id, token, ok := strings.Cut(rest, "_")
+	return id, ok && id != "" && token != ""
+}
+
+// randomToken returns n random bytes, base64url encoded with "_" replaced so
+// it can separate the parts of a secret
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return strings.ReplaceAll(base64.RawURLEncoding.EncodeToString(b), "_", "-"), nil
+}
+
+// apiKeyFromRequest reads the key from the Authorization bearer token or X-API-Key header
+func apiKeyFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
+
+// requestSchemeNetwork reads the scheme and network a facilitator request is
+// for from a body of at most MaxRequestBodySize bytes, restoring the body for
+// the next handler. They are empty when the request does not name them.
+func requestSchemeNetwork(r *http.Request) (string, string, error) {
+	if r.Body == nil {
+		return "", "", nil
+	}
+	body, replay, err := ReadBody(r.Body)
+	if err != nil {
+		return "", "", err
+	}
+	r.Body = replay
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "", "", nil
+	}
+
+	var request struct {
+		Scheme              string `json:"scheme"`
+		Network             string `json:"network"`
+		PaymentRequirements struct {
+			Scheme  string `json:"scheme"`
+			Network string `json:"network"`
+		} `json:"paymentRequirements"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return "", "", err
+	}
+	if request.PaymentRequirements.Scheme != "" || request.PaymentRequirements.Network != "" {
+		return request.PaymentRequirements.Scheme, request.PaymentRequirements.Network, nil
+	}
+	return request.Scheme, request.Network, nil
+}
+
+func writeAPIKeyError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": reason})
+}
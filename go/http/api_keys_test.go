@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	keys := NewAPIKeyManager(APIKeyConfig{RotationGrace: time.Hour})
+	keys.now = func() time.Time { return now }
+
+	secret, key, err := keys.Create(ctx, APIKeySpec{Name: "acme"})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if !strings.HasPrefix(secret, APIKeyPrefix+key.ID+"_") {
+		t.Errorf("Expected the secret to embed the key ID, got %s", secret)
+	}
+	if strings.Contains(key.SecretHash, secret) {
+		t.Error("Expected only the secret's hash to be stored")
+	}
+	if got, err := keys.Authenticate(ctx, secret); err != nil || got.Name != "acme" {
+		t.Fatalf("Expected the secret to authenticate, got %v", err)
+	}
+	if _, err := keys.Authenticate(ctx, APIKeyPrefix+key.ID+"_guess"); err == nil {
+		t.Error("Expected a wrong secret to fail")
+	}
+
+	rotated, _, err := keys.Rotate(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	if _, err := keys.Authenticate(ctx, rotated); err != nil {
+		t.Errorf("Expected the rotated secret to authenticate, got %v", err)
+	}
+	if _, err := keys.Authenticate(ctx, secret); err != nil {
+		t.Errorf("Expected the previous secret to work during the grace period, got %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := keys.Authenticate(ctx, secret); err == nil {
+		t.Error("Expected the previous secret to expire after the grace period")
+	}
+
+	if err := keys.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Failed to revoke key: %v", err)
+	}
+	if _, err := keys.Authenticate(ctx, rotated); err == nil {
+		t.Error("Expected a revoked key to fail")
+	}
+	if _, _, err := keys.Rotate(ctx, key.ID); err == nil {
+		t.Error("Expected rotating a revoked key to fail")
+	}
+}
+
+func TestAPIKeyAllows(t *testing.T) {
+	key := &APIKey{Scopes: []APIKeyScope{{Scheme: "exact", Network: "eip155:*"}, {Network: "solana:devnet"}}}
+	tests := []struct {
+		scheme, network string
+		allowed         bool
+	}{
+		{"exact", "eip155:8453", true},
+		{"upto", "eip155:8453", false},
+		{"upto", "solana:devnet", true},
+		{"exact", "solana:mainnet", false},
+	}
+	for _, tt := range tests {
+		if got := key.Allows(tt.scheme, tt.network); got != tt.allowed {
+			t.Errorf("Allows(%s, %s) = %v, want %v", tt.scheme, tt.network, got, tt.allowed)
+		}
+	}
+	if !(&APIKey{}).Allows("exact", "eip155:1") {
+		t.Error("Expected a key without scopes to allow everything")
+	}
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	ctx := context.Background()
+	keys := NewAPIKeyManager(APIKeyConfig{})
+	keys.now = func() time.Time { return time.Unix(1700000010, 0) }
+	secret, key, err := keys.Create(ctx, APIKeySpec{
+		Scopes:    []APIKeyScope{{Scheme: "exact", Network: "eip155:8453"}},
+		RateLimit: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	var seen *APIKey
+	handler := keys.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/verify", strings.NewReader(body))
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	inScope := `{"paymentRequirements":{"scheme":"exact","network":"eip155:8453"}}`
+
+	if rec := serve("", inScope); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a key, got %d", rec.Code)
+	}
+	if rec := serve(secret, `{"paymentRequirements":{"scheme":"exact","network":"eip155:1"}}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 out of scope, got %d", rec.Code)
+	}
+	if rec := serve(secret, `{}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 when the scheme and network are unknown, got %d", rec.Code)
+	}
+	if rec := serve(secret, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an empty body, got %d", rec.Code)
+	}
+	if rec := serve(secret, `{"paymentRequirements":{"scheme":"exact","network":"`+strings.Repeat("a", MaxRequestBodySize)+`"}}`); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized body, got %d", rec.Code)
+	}
+	if rec := serve(secret, inScope); rec.Code != http.StatusOK || seen == nil || seen.ID != key.ID {
+		t.Fatalf("Expected an in-scope request to pass with the key in context, got %d", rec.Code)
+	}
+	if rec := serve(secret, inScope); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("Expected 429 with Retry-After 30 over the rate limit, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	if total, _ := keys.Usage(ctx, key.ID, ""); total != 1 {
+		t.Errorf("Expected 1 request counted, got %d", total)
+	}
+	if verify, _ := keys.Usage(ctx, key.ID, "verify"); verify != 1 {
+		t.Errorf("Expected 1 verify counted, got %d", verify)
+	}
+}
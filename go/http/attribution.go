@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Attribution
+// ============================================================================
+
+// AttributionFunc returns metadata tying a request's payment to a business
+// object, e.g. {"orderId": "ord_123"}. It runs for both the 402 and the paid
+// retry, so it must return the same attribution for the same request (derive
+// it from the path, query, or body rather than generating IDs).
+type AttributionFunc func(ctx context.Context, reqCtx HTTPRequestContext) (map[string]string, error)
+
+// attributeRequirements adds the attribution to each requirements extra
+// (types.AttributionExtraKey), from where clients put it in SVM payment memos
+// and facilitators in settlement records
+func attributeRequirements(requirements []types.PaymentRequirements, attribution map[string]string) {
+	if len(attribution) == 0 {
+		return
+	}
+	for i := range requirements {
+		// Copy so a shared Extra map from the route config is never mutated
+		extra := make(map[string]interface{}, len(requirements[i].Extra)+1)
+		for k, v := range requirements[i].Extra {
+			extra[k] = v
+		}
+		value := make(map[string]interface{}, len(attribution))
+		for k, v := range attribution {
+			value[k] = v
+		}
+		extra[types.AttributionExtraKey] = value
+		requirements[i].Extra = extra
+	}
+}
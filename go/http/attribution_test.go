@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func newAttributionTestServer(attribution AttributionFunc, settled *[]byte) *x402HTTPResourceServer {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /orders/[id]/pay": {
+			Accepts:     PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Attribution: attribution,
+		}},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{
+			settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+				*settled = requirementsBytes
+				return &x402.SettleResponse{Success: true, Transaction: "0xmock", Network: "eip155:1", Payer: "0xmock"}, nil
+			},
+		}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func TestAttributionFlowsIntoRequirements(t *testing.T) {
+	var settled []byte
+	server := newAttributionTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (map[string]string, error) {
+		return map[string]string{"orderId": strings.Split(reqCtx.Path, "/")[2]}, nil
+	}, &settled)
+	reqCtx := HTTPRequestContext{Path: "/orders/ord_123/pay", Method: "GET", Adapter: &mockHTTPAdapter{method: "GET", path: "/orders/ord_123/pay", url: "http://example.com/orders/ord_123/pay"}}
+
+	result := server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+	}
+	if attribution := types.Attribution(required.Accepts[0].Extra); attribution["orderId"] != "ord_123" {
+		t.Errorf("Expected the attribution in requirements extra, got %v", required.Accepts[0].Extra)
+	}
+
+	reqCtx.Adapter = &mockHTTPAdapter{method: "GET", path: "/orders/ord_123/pay", url: "http://example.com/orders/ord_123/pay", headers: map[string]string{"PAYMENT-SIGNATURE": acceptingPaymentHeader(required.Accepts[0])}}
+	result = server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	if result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected a verified payment, got %s", result.Type)
+	}
+	if identity := NewPaymentIdentity(result); identity.Attribution["orderId"] != "ord_123" {
+		t.Errorf("Expected the attribution on the payment identity, got %+v", identity)
+	}
+
+	server.ProcessSettlement(context.Background(), *result.PaymentPayload, *result.PaymentRequirements)
+	if !strings.Contains(string(settled), `"attribution":{"orderId":"ord_123"}`) {
+		t.Errorf("Expected the attribution in the settled requirements, got %s", settled)
+	}
+}
+
+func TestAttributionErrors(t *testing.T) {
+	var settled []byte
+	reqCtx := HTTPRequestContext{Path: "/orders/ord_123/pay", Method: "GET", Adapter: &mockHTTPAdapter{method: "GET", path: "/orders/ord_123/pay", url: "http://example.com/orders/ord_123/pay"}}
+
+	failing := newAttributionTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (map[string]string, error) {
+		return nil, errors.New("order not found")
+	}, &settled)
+	if result := failing.ProcessHTTPRequest(context.Background(), reqCtx, nil); result.Response.Status != 500 {
+		t.Errorf("Expected 500 when attribution fails, got %d", result.Response.Status)
+	}
+
+	oversized := newAttributionTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (map[string]string, error) {
+		return map[string]string{"note": strings.Repeat("x", types.MaxAttributionBytes)}, nil
+	}, &settled)
+	if result := oversized.ProcessHTTPRequest(context.Background(), reqCtx, nil); result.Response.Status != 500 {
+		t.Errorf("Expected 500 for oversized attribution, got %d", result.Response.Status)
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Request Body Binding
+// ============================================================================
+
+// RequestBodyHashExtraKey is the requirements extra field carrying the request body hash
+// for routes with RouteConfig.BindRequestBody
+const RequestBodyHashExtraKey = "requestBodyHash"
+
+// MaxRequestBodySize bounds the request bodies HTTPBodyAdapter implementations read
+const MaxRequestBodySize = 10 << 20
+
+// ErrRequestBodyTooLarge is returned by GetBody for bodies over MaxRequestBodySize
+var ErrRequestBodyTooLarge = fmt.Errorf("request body exceeds %d bytes", MaxRequestBodySize)
+
+// HTTPBodyAdapter is implemented by adapters that can expose the raw request body.
+// It is required for routes with RouteConfig.BindRequestBody.
+type HTTPBodyAdapter interface {
+	// GetBody returns the request body, or ErrRequestBodyTooLarge for bodies
+	// over MaxRequestBodySize. Implementations must leave the body readable
+	// for the downstream handler.
+	GetBody() ([]byte, error)
+}
+
+// ReadBody reads a request body of at most MaxRequestBodySize bytes for
+// HTTPBodyAdapter implementations, returning it with a body that replays it
+// for the downstream handler
+func ReadBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	if body == nil {
+		return nil, nil, nil
+	}
+	defer body.Close()
+	read, err := io.ReadAll(io.LimitReader(body, MaxRequestBodySize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(read) > MaxRequestBodySize {
+		return nil, nil, ErrRequestBodyTooLarge
+	}
+	return read, io.NopCloser(bytes.NewReader(read)), nil
+}
+
+// HashRequestBody returns the body hash placed in requirements extra ("sha256:<hex>")
+func HashRequestBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// requestBodyHash hashes the request body exposed by the adapter
+func requestBodyHash(adapter HTTPAdapter) (string, error) {
+	bodyAdapter, ok := adapter.(HTTPBodyAdapter)
+	if !ok {
+		return "", fmt.Errorf("route binds the request body but %T does not implement HTTPBodyAdapter", adapter)
+	}
+	body, err := bodyAdapter.GetBody()
+	if err == nil && len(body) > MaxRequestBodySize {
+		err = ErrRequestBodyTooLarge
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	return HashRequestBody(body), nil
+}
+
+// requestBodyError is the response for a body that could not be hashed
+func requestBodyError(err error) *HTTPResponseInstructions {
+	status := 500
+	if errors.Is(err, ErrRequestBodyTooLarge) {
+		status = 413
+	}
+	return &HTTPResponseInstructions{
+		Status:  status,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    map[string]string{"error": err.Error()},
+	}
+}
+
+// bindRequirementsToBody adds the body hash and the server's proof of it
+// (types.RequestBodyProofExtraKey) to a copy of each requirement's extra
+func bindRequirementsToBody(core *x402.X402ResourceServer, requirements []types.PaymentRequirements, bodyHash string) {
+	for i := range requirements {
+		// Copy so a shared Extra map from the route config is never mutated
+		extra := make(map[string]interface{}, len(requirements[i].Extra)+2)
+		for k, v := range requirements[i].Extra {
+			extra[k] = v
+		}
+		extra[RequestBodyHashExtraKey] = bodyHash
+		extra[types.RequestBodyProofExtraKey] = core.RequirementsProof(requirements[i], bodyHash)
+		requirements[i].Extra = extra
+	}
+}
+
+// acceptedBodyMatches reports whether the payload's accepted requirements
+// carry the hash of the request body and this server's proof of it. The hash
+// is echoed by the client, so only the proof shows the server issued the
+// accepted requirements for this body. The proof is issued to whoever asks and
+// is not signed by the payer, so this is advisory (see RouteConfig.BindRequestBody).
+func acceptedBodyMatches(core *x402.X402ResourceServer, accepted types.PaymentRequirements, bodyHash string) bool {
+	hash, ok := types.ExtraValue[string](accepted.Extra, RequestBodyHashExtraKey)
+	if !ok || hash != bodyHash {
+		return false
+	}
+	proof, _ := types.ExtraValue[string](accepted.Extra, types.RequestBodyProofExtraKey)
+	return hmac.Equal([]byte(proof), []byte(core.RequirementsProof(accepted, bodyHash)))
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// mockBodyAdapter is a mockHTTPAdapter that also exposes a request body
+type mockBodyAdapter struct {
+	mockHTTPAdapter
+	body []byte
+}
+
+func (m *mockBodyAdapter) GetBody() ([]byte, error) {
+	return m.body, nil
+}
+
+func newBodyBindingServer(t *testing.T) *x402HTTPResourceServer {
+	t.Helper()
+	routes := RoutesConfig{
+		"POST /generate": {
+			Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+			BindRequestBody: true,
+		},
+	}
+	mockClient := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func bodyRequest(adapter HTTPAdapter) HTTPRequestContext {
+	return HTTPRequestContext{Adapter: adapter, Path: "/generate", Method: "POST"}
+}
+
+func TestBindRequestBodyAddsHashToRequirements(t *testing.T) {
+	server := newBodyBindingServer(t)
+	body := []byte(`{"prompt":"a cat"}`)
+
+	result := server.ProcessHTTPRequest(context.Background(), bodyRequest(&mockBodyAdapter{
+		mockHTTPAdapter: mockHTTPAdapter{method: "POST", path: "/generate", url: "http://example.com/generate"},
+		body:            body,
+	}), nil)
+
+	if result.Response == nil || result.Response.Status != 402 {
+		t.Fatalf("Expected 402, got %+v", result.Response)
+	}
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	if got := required.Accepts[0].Extra[RequestBodyHashExtraKey]; got != HashRequestBody(body) {
+		t.Errorf("Expected body hash %s in extra, got %v", HashRequestBody(body), got)
+	}
+}
+
+func TestBindRequestBodyValidatesPayment(t *testing.T) {
+	server := newBodyBindingServer(t)
+	signedBody := []byte(`{"prompt":"a cat"}`)
+
+	// Build the payment the client would sign after receiving the 402
+	unpaid := server.ProcessHTTPRequest(context.Background(), bodyRequest(&mockBodyAdapter{
+		mockHTTPAdapter: mockHTTPAdapter{method: "POST", path: "/generate", url: "http://example.com/generate"},
+		body:            signedBody,
+	}), nil)
+	required, _ := decodePaymentRequiredHeader(unpaid.Response.Headers["PAYMENT-REQUIRED"], "")
+	otherBody := []byte(`{"prompt":"a thousand cats"}`)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		extra      map[string]interface{}
+		wantResult string
+	}{
+		{"same body", signedBody, nil, ResultPaymentVerified},
+		{"different body", otherBody, nil, ResultPaymentError},
+		{"hash rewritten to the body sent", otherBody, map[string]interface{}{RequestBodyHashExtraKey: HashRequestBody(otherBody)}, ResultPaymentError},
+		{"proof stripped", signedBody, map[string]interface{}{types.RequestBodyProofExtraKey: nil}, ResultPaymentError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted := required.Accepts[0]
+			accepted.Extra = make(map[string]interface{}, len(required.Accepts[0].Extra))
+			for k, v := range required.Accepts[0].Extra {
+				accepted.Extra[k] = v
+			}
+			for k, v := range tt.extra {
+				if v == nil {
+					delete(accepted.Extra, k)
+					continue
+				}
+				accepted.Extra[k] = v
+			}
+			payloadJSON, _ := json.Marshal(types.PaymentPayload{
+				X402Version: 2,
+				Payload:     map[string]interface{}{"sig": "test"},
+				Accepted:    accepted,
+			})
+
+			result := server.ProcessHTTPRequest(context.Background(), bodyRequest(&mockBodyAdapter{
+				mockHTTPAdapter: mockHTTPAdapter{
+					method:  "POST",
+					path:    "/generate",
+					url:     "http://example.com/generate",
+					headers: map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadJSON)},
+				},
+				body: tt.body,
+			}), nil)
+
+			if result.Type != tt.wantResult {
+				t.Fatalf("Expected %s, got %s (%+v)", tt.wantResult, result.Type, result.Response)
+			}
+			if tt.wantResult == ResultPaymentError && result.Response.Status != 402 {
+				t.Errorf("Expected 402 for mismatched body, got %d", result.Response.Status)
+			}
+		})
+	}
+}
+
+func TestBindRequestBodyTooLarge(t *testing.T) {
+	server := newBodyBindingServer(t)
+
+	result := server.ProcessHTTPRequest(context.Background(), bodyRequest(&mockBodyAdapter{
+		mockHTTPAdapter: mockHTTPAdapter{method: "POST", path: "/generate", url: "http://example.com/generate"},
+		body:            make([]byte, MaxRequestBodySize+1),
+	}), nil)
+
+	if result.Response == nil || result.Response.Status != 413 {
+		t.Errorf("Expected 413 for an oversized body, got %+v", result.Response)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	body, replay, err := ReadBody(io.NopCloser(strings.NewReader("hello")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replayed, _ := io.ReadAll(replay)
+	if string(body) != "hello" || string(replayed) != "hello" {
+		t.Errorf("Expected body and replay to be hello, got %q and %q", body, replayed)
+	}
+
+	_, _, err = ReadBody(io.NopCloser(bytes.NewReader(make([]byte, MaxRequestBodySize+1))))
+	if !errors.Is(err, ErrRequestBodyTooLarge) {
+		t.Errorf("Expected ErrRequestBodyTooLarge, got %v", err)
+	}
+}
+
+func TestBindRequestBodyRequiresBodyAdapter(t *testing.T) {
+	server := newBodyBindingServer(t)
+
+	result := server.ProcessHTTPRequest(context.Background(), bodyRequest(&mockHTTPAdapter{
+		method: "POST",
+		path:   "/generate",
+		url:    "http://example.com/generate",
+	}), nil)
+
+	if result.Response == nil || result.Response.Status != 500 {
+		t.Errorf("Expected 500 when the adapter cannot read the body, got %+v", result.Response)
+	}
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Browser Detection
+// ============================================================================
+
+// BrowserDetector decides whether a 402 response is rendered as the HTML
+// paywall (browsers) or as the PAYMENT-REQUIRED header with a JSON body (API clients)
+type BrowserDetector interface {
+	IsBrowser(reqCtx HTTPRequestContext) bool
+}
+
+// BrowserDetectorFunc adapts a function to BrowserDetector
+type BrowserDetectorFunc func(reqCtx HTTPRequestContext) bool
+
+// IsBrowser implements BrowserDetector
+func (f BrowserDetectorFunc) IsBrowser(reqCtx HTTPRequestContext) bool {
+	return f(reqCtx)
+}
+
+// DefaultBrowserDetector treats requests accepting text/html from a
+// Mozilla-compatible user agent as browsers
+var DefaultBrowserDetector BrowserDetector = BrowserDetectorFunc(func(reqCtx HTTPRequestContext) bool {
+	if reqCtx.Adapter == nil {
+		return false
+	}
+	return strings.Contains(reqCtx.Adapter.GetAcceptHeader(), "text/html") &&
+		strings.Contains(reqCtx.Adapter.GetUserAgent(), "Mozilla")
+})
+
+// DefaultJSONUserAgents are command-line and library clients that should get
+// JSON even when they send browser-like headers
+var DefaultJSONUserAgents = []string{"curl/", "Wget/", "HTTPie/", "python-requests/", "python-httpx/", "Go-http-client/", "axios/", "node-fetch/"}
+
+// BrowserDetectionConfig configures NewBrowserDetector
+type BrowserDetectionConfig struct {
+	// JSONUserAgents always get JSON; entries match user agents by
+	// case-insensitive substring (e.g. DefaultJSONUserAgents)
+	JSONUserAgents []string
+
+	// PaywallQueryParam, when set, lets a query parameter force the result for
+	// testing: "?x402-paywall=1" renders the paywall, "?x402-paywall=0" returns JSON
+	PaywallQueryParam string
+
+	// Fallback decides all other requests (default: DefaultBrowserDetector)
+	Fallback BrowserDetector
+}
+
+// NewBrowserDetector creates a detector applying, in order, the query
+// parameter override, the JSON user agents, and the fallback detector
+func NewBrowserDetector(config BrowserDetectionConfig) BrowserDetector {
+	if config.Fallback == nil {
+		config.Fallback = DefaultBrowserDetector
+	}
+	jsonUserAgents := make([]string, len(config.JSONUserAgents))
+	for i, agent := range config.JSONUserAgents {
+		jsonUserAgents[i] = strings.ToLower(agent)
+	}
+
+	return BrowserDetectorFunc(func(reqCtx HTTPRequestContext) bool {
+		if reqCtx.Adapter == nil {
+			return false
+		}
+		if config.PaywallQueryParam != "" {
+			if forced, ok := queryFlag(reqCtx.Adapter.GetURL(), config.PaywallQueryParam); ok {
+				return forced
+			}
+		}
+		userAgent := strings.ToLower(reqCtx.Adapter.GetUserAgent())
+		for _, agent := range jsonUserAgents {
+			if strings.Contains(userAgent, agent) {
+				return false
+			}
+		}
+		return config.Fallback.IsBrowser(reqCtx)
+	})
+}
+
+// SetBrowserDetector replaces the strategy deciding between the HTML paywall
+// and a JSON 402 response
+func (s *x402HTTPResourceServer) SetBrowserDetector(detector BrowserDetector) *x402HTTPResourceServer {
+	s.browserDetector = detector
+	return s
+}
+
+// queryFlag parses a boolean query parameter from a URL
+func queryFlag(rawURL string, name string) (bool, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false, false
+	}
+	value := parsed.Query().Get(name)
+	if value == "" {
+		return false, false
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false
+	}
+	return flag, true
+}
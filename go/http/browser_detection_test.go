@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestNewBrowserDetector(t *testing.T) {
+	detector := NewBrowserDetector(BrowserDetectionConfig{
+		JSONUserAgents:    DefaultJSONUserAgents,
+		PaywallQueryParam: "x402-paywall",
+	})
+
+	const browser = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)"
+	tests := []struct {
+		name   string
+		accept string
+		agent  string
+		url    string
+		want   bool
+	}{
+		{"browser", "text/html,application/xhtml+xml", browser, "https://example.com/api", true},
+		{"api client", "application/json", browser, "https://example.com/api", false},
+		{"curl with html accept", "text/html", "curl/8.4.0", "https://example.com/api", false},
+		{"python requests", "text/html", "python-requests/2.31.0 Mozilla", "https://example.com/api", false},
+		{"forced paywall", "*/*", "curl/8.4.0", "https://example.com/api?x402-paywall=1", true},
+		{"forced json", "text/html", browser, "https://example.com/api?x402-paywall=false", false},
+		{"invalid flag ignored", "text/html", browser, "https://example.com/api?x402-paywall=maybe", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := HTTPRequestContext{Adapter: &mockHTTPAdapter{accept: tt.accept, agent: tt.agent, url: tt.url}}
+			if got := detector.IsBrowser(reqCtx); got != tt.want {
+				t.Errorf("IsBrowser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetBrowserDetector(t *testing.T) {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{
+			"GET /api": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}},
+		},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).SetBrowserDetector(BrowserDetectorFunc(func(reqCtx HTTPRequestContext) bool {
+		return reqCtx.Adapter.GetHeader("X-Paywall") == "yes"
+	}))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", headers: map[string]string{"X-Paywall": "yes"}}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+	if result.Response == nil || result.Response.Headers["Content-Type"] != "text/html" {
+		t.Fatalf("Expected paywall from custom detector, got %+v", result.Response)
+	}
+
+	adapter.headers = nil
+	result = server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+	if result.Response == nil || result.Response.Headers["Content-Type"] == "text/html" {
+		t.Fatalf("Expected JSON response, got %+v", result.Response)
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Route Exclusions and Payment Bypass
+// ============================================================================
+
+// ServiceTokenHeader carries a signed service-to-service token (see BypassServiceToken)
+const ServiceTokenHeader = "X-402-Service-Token"
+
+// BypassFunc reports whether a request skips payment. Bypasses are evaluated
+// after route matching and before rollout, free tier, and payment processing.
+type BypassFunc func(ctx context.Context, reqCtx HTTPRequestContext) bool
+
+// ExcludeRoutes exempts requests matching the patterns from payment, even
+// when a protected route also matches them. Patterns use the RoutesConfig
+// syntax, e.g. protect "/api/*" and exclude "GET /api/health".
+func (s *x402HTTPResourceServer) ExcludeRoutes(patterns ...string) *x402HTTPResourceServer {
+	for _, pattern := range patterns {
+		host, hostlessPattern := splitRouteHost(pattern)
+		verb, regex, rank, err := compileRoutePattern(hostlessPattern)
+		s.excludedRoutes = append(s.excludedRoutes, CompiledRoute{
+			Pattern:   pattern,
+			Verb:      verb,
+			Host:      host,
+			HostRegex: compileHostPattern(host),
+			Regex:     regex,
+			rank:      rank,
+			err:       err,
+		})
+	}
+	return s
+}
+
+// AddBypass registers a predicate that lets matching requests through without payment
+func (s *x402HTTPResourceServer) AddBypass(bypass BypassFunc) *x402HTTPResourceServer {
+	s.bypasses = append(s.bypasses, bypass)
+	return s
+}
+
+// isExcluded reports whether an excluded route matches the request
+func (s *x402HTTPResourceServer) isExcluded(host, normalizedPath, method string) bool {
+	for _, route := range s.excludedRoutes {
+		if route.matches(host, normalizedPath, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// bypassed reports whether any bypass predicate accepts the request
+func (s *x402HTTPResourceServer) bypassed(ctx context.Context, reqCtx HTTPRequestContext) bool {
+	for _, bypass := range s.bypasses {
+		if bypass(ctx, reqCtx) {
+			return true
+		}
+	}
+	return false
+}
+
+// BypassIPRanges lets requests from the given CIDR ranges (e.g. "10.0.0.0/8")
+// through. The client IP must come from an adapter implementing
+// HTTPClientIPAdapter, which honors the framework's trusted proxies;
+// forwarding headers alone are never trusted for a bypass.
+func BypassIPRanges(cidrs ...string) (BypassFunc, error) {
+	ranges, err := parseCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, reqCtx HTTPRequestContext) bool {
+		ipAdapter, ok := reqCtx.Adapter.(HTTPClientIPAdapter)
+		if !ok {
+			return false
+		}
+		return inRanges(ranges, ipAdapter.GetClientIP())
+	}, nil
+}
+
+// parseCIDRs parses CIDR ranges such as "10.0.0.0/8"
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	ranges := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges, nil
+}
+
+// inRanges reports whether ip parses and lies in one of ranges
+func inRanges(ranges []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range ranges {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// BypassServiceToken lets requests carrying a valid ServiceTokenHeader through.
+// Tokens are created with SignServiceToken using the same secret, are bound to
+// the request method and path, and expire after maxAge.
+func BypassServiceToken(secret []byte, maxAge time.Duration) BypassFunc {
+	return func(ctx context.Context, reqCtx HTTPRequestContext) bool {
+		if reqCtx.Adapter == nil {
+			return false
+		}
+		token := reqCtx.Adapter.GetHeader(ServiceTokenHeader)
+		timestamp, signature, ok := strings.Cut(token, ".")
+		if !ok {
+			return false
+		}
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+		age := time.Since(time.Unix(unix, 0))
+		if age > maxAge || age < -time.Minute {
+			return false
+		}
+
+		expected := serviceTokenSignature(secret, timestamp, strings.ToUpper(reqCtx.Method), normalizePath(reqCtx.Path))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+}
+
+// SignServiceToken creates a token for ServiceTokenHeader authorizing one
+// method and path for callers trusted to skip payment
+func SignServiceToken(secret []byte, method string, path string, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return timestamp + "." + serviceTokenSignature(secret, timestamp, strings.ToUpper(method), normalizePath(path))
+}
+
+// serviceTokenSignature is the hex HMAC-SHA256 of the timestamp, method, and path
+func serviceTokenSignature(secret []byte, timestamp, method, path string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path))
+	return hex.EncodeToString(mac.Sum(nil))
+}
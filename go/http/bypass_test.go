@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+type clientIPAdapter struct {
+	mockHTTPAdapter
+	ip string
+}
+
+func (c *clientIPAdapter) GetClientIP() string {
+	return c.ip
+}
+
+func newBypassTestServer() *x402HTTPResourceServer {
+	return Newx402HTTPResourceServer(
+		RoutesConfig{
+			"/api/*": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}},
+		},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+}
+
+func TestExcludeRoutes(t *testing.T) {
+	server := newBypassTestServer().ExcludeRoutes("GET /api/health", "/api/internal/**")
+
+	tests := []struct {
+		method, path string
+		requires     bool
+	}{
+		{"GET", "/api/data", true},
+		{"GET", "/api/health", false},
+		{"POST", "/api/health", true},
+		{"GET", "/api/internal/metrics/cpu", false},
+	}
+	for _, tt := range tests {
+		reqCtx := HTTPRequestContext{Path: tt.path, Method: tt.method, Adapter: &mockHTTPAdapter{path: tt.path, method: tt.method}}
+		if server.RequiresPayment(reqCtx) != tt.requires {
+			t.Errorf("%s %s: expected RequiresPayment=%v", tt.method, tt.path, tt.requires)
+		}
+		if result := server.ProcessHTTPRequest(context.Background(), reqCtx, nil); (result.Type != ResultNoPaymentRequired) != tt.requires {
+			t.Errorf("%s %s: unexpected result %s", tt.method, tt.path, result.Type)
+		}
+	}
+}
+
+func TestBypassIPRanges(t *testing.T) {
+	bypass, err := BypassIPRanges("10.0.0.0/8", "fd00::/8")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := newBypassTestServer().AddBypass(bypass)
+
+	process := func(adapter HTTPAdapter) string {
+		reqCtx := HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: adapter}
+		return server.ProcessHTTPRequest(context.Background(), reqCtx, nil).Type
+	}
+
+	if result := process(&clientIPAdapter{ip: "10.1.2.3"}); result != ResultNoPaymentRequired {
+		t.Errorf("expected internal IP to bypass payment, got %s", result)
+	}
+	if result := process(&clientIPAdapter{ip: "203.0.113.7"}); result == ResultNoPaymentRequired {
+		t.Error("expected external IP to require payment")
+	}
+	spoofed := &mockHTTPAdapter{headers: map[string]string{"X-Forwarded-For": "10.1.2.3"}}
+	if result := process(spoofed); result == ResultNoPaymentRequired {
+		t.Error("expected forwarding headers to be ignored")
+	}
+
+	if _, err := BypassIPRanges("10.0.0.0"); err == nil {
+		t.Error("expected error for an invalid CIDR")
+	}
+}
+
+func TestBypassServiceToken(t *testing.T) {
+	secret := []byte("shared-secret")
+	server := newBypassTestServer().AddBypass(BypassServiceToken(secret, time.Minute))
+
+	process := func(token string) string {
+		adapter := &mockHTTPAdapter{headers: map[string]string{ServiceTokenHeader: token}}
+		reqCtx := HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: adapter}
+		return server.ProcessHTTPRequest(context.Background(), reqCtx, nil).Type
+	}
+
+	now := time.Now()
+	if result := process(SignServiceToken(secret, "GET", "/api/data", now)); result != ResultNoPaymentRequired {
+		t.Errorf("expected valid token to bypass payment, got %s", result)
+	}
+	for name, token := range map[string]string{
+		"other path":   SignServiceToken(secret, "GET", "/api/other", now),
+		"other secret": SignServiceToken([]byte("wrong"), "GET", "/api/data", now),
+		"expired":      SignServiceToken(secret, "GET", "/api/data", now.Add(-2*time.Minute)),
+		"malformed":    "not-a-token",
+	} {
+		if result := process(token); result == ResultNoPaymentRequired {
+			t.Errorf("%s: expected payment to be required", name)
+		}
+	}
+}
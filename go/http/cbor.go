@@ -0,0 +1,19 @@
+package http
+
+import (
+	"encoding/json"
+
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/types"
+)
+
+// EncodePaymentRequiredCBOR encodes the payment requirements as CBOR
+// (RFC 8949) for application/x402+cbor responses. The data model matches the
+// JSON encoding, with text keys in deterministic order.
+func EncodePaymentRequiredCBOR(paymentRequired types.PaymentRequired) ([]byte, error) {
+	data, err := json.Marshal(paymentRequired)
+	if err != nil {
+		return nil, err
+	}
+	return headers.EncodeCBOR(data)
+}
@@ -0,0 +1,491 @@
+// Package chi provides x402 payment middleware for go-chi routers.
+//
+// Routes may be written in chi's pattern syntax, so the paid routes read the
+// same as the router's:
+//
+//	routes := chimw.Routes(x402http.RoutesConfig{
+//	    "GET /articles/{slug}":          {Accepts: accepts},
+//	    "GET /invoices/{id:[0-9]+}/pdf": {Accepts: accepts},
+//	    "GET /files/*":                  {Accepts: accepts},
+//	})
+//	server := x402http.Newx402HTTPResourceServer(routes, x402.WithFacilitatorClient(facilitator), ...)
+//	_ = server.Initialize(ctx)
+//
+//	r := chi.NewRouter()
+//	r.Use(chimw.Middleware(server, nil))
+//	r.Get("/articles/{slug}", handler)
+//
+// The middleware is a plain func(http.Handler) http.Handler, so the package
+// does not depend on chi and also works with net/http.
+package chi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// ============================================================================
+// Route Patterns
+// ============================================================================
+
+// Pattern converts a route written with a chi path ("GET /api/{id}") to the
+// x402 route pattern syntax ("GET /api/[id]"). The method and host are kept.
+//
+//	{name}        one path segment, like "[name]"
+//	{name:regex}  a regular expression, like "{regex}"
+//	*             the rest of the path, as in x402
+//
+// Characters x402 treats as syntax ("[", "]", "\") are escaped. Unlike chi,
+// a regular expression parameter is not confined to one segment.
+func Pattern(route string) string {
+	fields := strings.Fields(route)
+	if len(fields) == 0 {
+		return route
+	}
+	path := fields[len(fields)-1]
+	slash := strings.Index(path, "/")
+	if slash < 0 {
+		return route
+	}
+	fields[len(fields)-1] = path[:slash] + convertPath(path[slash:])
+	return strings.Join(fields, " ")
+}
+
+// Routes converts every route pattern of a config written with chi paths
+func Routes(routes x402http.RoutesConfig) x402http.RoutesConfig {
+	converted := make(x402http.RoutesConfig, len(routes))
+	for pattern, config := range routes {
+		converted[Pattern(pattern)] = config
+	}
+	return converted
+}
+
+// convertPath converts a chi path to an x402 path pattern
+func convertPath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			end := closingBrace(path[i:])
+			if end < 0 {
+				// Left for route validation to report
+				b.WriteString(path[i:])
+				return b.String()
+			}
+			name, regex, hasRegex := strings.Cut(path[i+1:i+end], ":")
+			if hasRegex {
+				b.WriteString("{" + regex + "}")
+			} else {
+				b.WriteString("[" + name + "]")
+			}
+			i += end
+		case '[', ']', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(path[i])
+		default:
+			b.WriteByte(path[i])
+		}
+	}
+	return b.String()
+}
+
+// closingBrace returns the index of the brace closing the one at s[0], or -1.
+// Regular expressions may nest braces, e.g. "{id:[0-9]{3}}".
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ============================================================================
+// Request Adapter
+// ============================================================================
+
+// ChiAdapter implements HTTPAdapter for net/http requests
+type ChiAdapter struct {
+	r *http.Request
+}
+
+// NewChiAdapter creates a new adapter
+func NewChiAdapter(r *http.Request) *ChiAdapter {
+	return &ChiAdapter{r: r}
+}
+
+// GetHeader gets a request header
+func (a *ChiAdapter) GetHeader(name string) string {
+	return a.r.Header.Get(name)
+}
+
+// GetMethod gets the HTTP method
+func (a *ChiAdapter) GetMethod() string {
+	return a.r.Method
+}
+
+// GetPath gets the request path
+func (a *ChiAdapter) GetPath() string {
+	return a.r.URL.Path
+}
+
+// GetURL gets the full request URL
+func (a *ChiAdapter) GetURL() string {
+	scheme := "http"
+	if a.r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, a.r.Host, a.r.URL.Path)
+}
+
+// GetAcceptHeader gets the Accept header
+func (a *ChiAdapter) GetAcceptHeader() string {
+	return a.r.Header.Get("Accept")
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *ChiAdapter) GetUserAgent() string {
+	return a.r.UserAgent()
+}
+
+// GetClientIP gets the request's remote address, which chi's RealIP
+// middleware sets from proxy headers when installed
+func (a *ChiAdapter) GetClientIP() string {
+	return x402http.RemoteIP(a.r.RemoteAddr)
+}
+
+// GetBody reads the request body and restores it for downstream handlers
+func (a *ChiAdapter) GetBody() ([]byte, error) {
+	if a.r.Body == nil {
+		return nil, nil
+	}
+	body, replay, err := x402http.ReadBody(a.r.Body)
+	if err != nil {
+		return nil, err
+	}
+	a.r.Body = replay
+	return body, nil
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware
+type MiddlewareConfig struct {
+	// Paywall configuration
+	PaywallConfig *x402http.PaywallConfig
+
+	// Sync with facilitator on start (PaymentMiddleware only)
+	SyncFacilitatorOnStart bool
+
+	// Custom error handler, called when settlement fails
+	ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+	// Custom settlement handler, called before the response is written
+	SettlementHandler func(http.ResponseWriter, *http.Request, *x402.SettleResponse)
+
+	// Context timeout for payment operations
+	Timeout time.Duration
+
+	// IdentityHeaders lists the X-402-* headers injected into verified requests
+	// (X-402-Tx is set on the response after settlement). Nil disables injection.
+	IdentityHeaders []string
+}
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption func(*MiddlewareConfig)
+
+// WithPaywallConfig sets the paywall configuration
+func WithPaywallConfig(config *x402http.PaywallConfig) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallConfig = config
+	}
+}
+
+// WithSyncFacilitatorOnStart sets whether to sync with facilitator on startup
+func WithSyncFacilitatorOnStart(sync bool) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SyncFacilitatorOnStart = sync
+	}
+}
+
+// WithErrorHandler sets a custom error handler
+func WithErrorHandler(handler func(http.ResponseWriter, *http.Request, error)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithSettlementHandler sets a custom settlement handler
+func WithSettlementHandler(handler func(http.ResponseWriter, *http.Request, *x402.SettleResponse)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SettlementHandler = handler
+	}
+}
+
+// WithIdentityHeaders enables payer identity headers for downstream handlers.
+// With no arguments, all of x402http.DefaultIdentityHeaders are injected.
+func WithIdentityHeaders(headers ...string) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		if len(headers) == 0 {
+			headers = x402http.DefaultIdentityHeaders
+		}
+		c.IdentityHeaders = headers
+	}
+}
+
+// WithTimeout sets the context timeout for payment operations
+func WithTimeout(timeout time.Duration) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// PaymentMiddleware creates middleware for routes written with chi paths (see
+// Pattern) using a pre-configured resource server
+func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceServer, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	config := &MiddlewareConfig{
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	httpServer := x402http.Wrappedx402HTTPResourceServer(Routes(routes), server)
+
+	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
+	if config.SyncFacilitatorOnStart {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+		if err := httpServer.Initialize(ctx); err != nil {
+			fmt.Printf("Warning: failed to initialize x402 server: %v\n", err)
+		}
+	}
+
+	return createMiddleware(httpServer, config)
+}
+
+// Middleware creates middleware for an HTTP server that is already configured
+// and initialized. Build its routes with Routes to write them with chi paths.
+// Verified requests carry the payer (see x402http.PaymentIdentityFromContext)
+// and are settled after the handler returns.
+func Middleware(server *x402http.HTTPServer, paywallConfig *x402http.PaywallConfig, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	config := &MiddlewareConfig{
+		PaywallConfig: paywallConfig,
+		Timeout:       30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return createMiddleware(server, config)
+}
+
+// createMiddleware creates the middleware handler
+func createMiddleware(server *x402http.HTTPServer, config *MiddlewareConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqCtx := x402http.HTTPRequestContext{
+				Adapter: NewChiAdapter(r),
+				Host:    r.Host,
+				Path:    r.URL.Path,
+				Method:  r.Method,
+			}
+
+			// Check if route requires payment before waiting for initialization
+			if !server.RequiresPayment(reqCtx) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), config.Timeout)
+			defer cancel()
+
+			result := server.ProcessHTTPRequest(ctx, reqCtx, config.PaywallConfig)
+			switch result.Type {
+			case x402http.ResultNoPaymentRequired:
+				next.ServeHTTP(w, r)
+			case x402http.ResultPaymentError:
+				writeInstructions(w, result.Response)
+			case x402http.ResultPaymentVerified:
+				handlePaymentVerified(w, r, next, server, ctx, result, config)
+			}
+		})
+	}
+}
+
+// handlePaymentVerified runs the handler and settles if it succeeded
+func handlePaymentVerified(w http.ResponseWriter, r *http.Request, next http.Handler, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) {
+	// Expose the payer to the protected handler
+	identity := x402http.NewPaymentIdentity(result)
+	r = r.WithContext(x402http.ContextWithPaymentIdentity(r.Context(), identity))
+	if config.IdentityHeaders != nil {
+		x402http.StripIdentityHeaders(r.Header)
+		for key, value := range identity.Headers(config.IdentityHeaders) {
+			r.Header.Set(key, value)
+		}
+	}
+
+	// Capture the response so nothing is sent before settlement
+	capture := &responseCapture{header: http.Header{}, statusCode: http.StatusOK}
+	next.ServeHTTP(capture, r)
+
+	// Don't settle if response failed; let the client retry with the same authorization
+	if capture.statusCode >= 400 {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		capture.flush(w)
+		return
+	}
+
+	// Per-item routes settle only for the items the handler returned
+	amount := x402http.ItemSettlementAmount(result, identity)
+	if amount == "0" {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		capture.flush(w)
+		return
+	}
+
+	settleResult := server.ProcessSettlementAmount(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+		amount,
+	)
+
+	// Requests let through under the verify grace period are served even if
+	// settlement fails; they were flagged for reconciliation
+	if !settleResult.Success && result.Reconcile != nil {
+		capture.flush(w)
+		return
+	}
+
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(w, r, fmt.Errorf("settlement failed: %s", errorReason))
+		} else {
+			writeJSON(w, http.StatusPaymentRequired, map[string]string{
+				"error":   "Settlement failed",
+				"details": errorReason,
+			})
+		}
+		return
+	}
+
+	for key, value := range settleResult.Headers {
+		w.Header().Set(key, value)
+	}
+
+	// Record the transaction for outer layers (X-402-Tx, if allowlisted)
+	identity.SetSettlement(settleResult)
+	if config.IdentityHeaders != nil {
+		if tx, ok := identity.Headers(config.IdentityHeaders)[http.CanonicalHeaderKey(x402http.TransactionIdentityHeader)]; ok {
+			w.Header().Set(x402http.TransactionIdentityHeader, tx)
+		}
+	}
+
+	if config.SettlementHandler != nil {
+		config.SettlementHandler(w, r, &x402.SettleResponse{
+			Success:     true,
+			Transaction: settleResult.Transaction,
+			Network:     settleResult.Network,
+			Payer:       settleResult.Payer,
+		})
+	}
+
+	capture.flush(w)
+}
+
+// writeInstructions writes a payment error response
+func writeInstructions(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case nil:
+		w.WriteHeader(response.Status)
+	case string:
+		if response.IsHTML {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.WriteHeader(response.Status)
+		_, _ = io.WriteString(w, body)
+	case []byte:
+		// Encoded body for a negotiated media type (e.g. application/x402+cbor)
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(body)
+	default:
+		writeJSON(w, response.Status, body)
+	}
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// ============================================================================
+// Response Capture
+// ============================================================================
+
+// responseCapture buffers the handler's response until settlement completes
+type responseCapture struct {
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+	written    bool
+}
+
+// Header returns the captured response headers
+func (c *responseCapture) Header() http.Header {
+	return c.header
+}
+
+// WriteHeader captures the status code
+func (c *responseCapture) WriteHeader(code int) {
+	if !c.written {
+		c.statusCode = code
+		c.written = true
+	}
+}
+
+// Write captures the response body
+func (c *responseCapture) Write(data []byte) (int, error) {
+	c.written = true
+	return c.body.Write(data)
+}
+
+// flush writes the captured response
+func (c *responseCapture) flush(w http.ResponseWriter) {
+	for key, values := range c.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(c.statusCode)
+	_, _ = w.Write(c.body.Bytes())
+}
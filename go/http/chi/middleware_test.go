@@ -0,0 +1,112 @@
+package chi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+func TestPattern(t *testing.T) {
+	cases := map[string]string{
+		"GET /api/{id}":                  "GET /api/[id]",
+		"GET|POST /users/{id}/posts/{p}": "GET|POST /users/[id]/posts/[p]",
+		"GET /invoices/{id:[0-9]+}/pdf":  "GET /invoices/{[0-9]+}/pdf",
+		"GET /codes/{code:[A-Z]{3}}":     "GET /codes/{[A-Z]{3}}",
+		"/files/*":                       "/files/*",
+		"GET api.acme.com/items/{id}":    "GET api.acme.com/items/[id]",
+		"GET /literal[1]":                `GET /literal\[1\]`,
+		"GET /broken/{id":                "GET /broken/{id",
+	}
+	for chi, want := range cases {
+		if got := Pattern(chi); got != want {
+			t.Errorf("Pattern(%q) = %q, want %q", chi, got, want)
+		}
+	}
+}
+
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	accepts := x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}
+	server := x402http.Newx402HTTPResourceServer(Routes(x402http.RoutesConfig{
+		"GET /articles/{slug}":        {Accepts: accepts},
+		"GET /invoices/{id:[0-9]+}":   {Accepts: accepts},
+		"GET /articles/{slug}/errors": {Accepts: accepts},
+	}), x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/articles/hello/errors" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		payer := "free"
+		if identity, ok := x402http.PaymentIdentityFromContext(r.Context()); ok {
+			payer = identity.Payer
+		}
+		w.Header().Set("X-Handler", "yes")
+		_, _ = io.WriteString(w, payer)
+	})
+	return Middleware(server, nil)(handler)
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+func TestMiddlewareChargesChiRoutes(t *testing.T) {
+	ts := httptest.NewServer(newTestHandler(t))
+	defer ts.Close()
+
+	get := func(client *http.Client, path string) (*http.Response, string) {
+		resp, err := client.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, _ := get(http.DefaultClient, "/articles/hello"); resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" {
+		t.Errorf("Expected a 402 for a {slug} route, got %d", resp.StatusCode)
+	}
+	if resp, body := get(http.DefaultClient, "/invoices/abc"); resp.StatusCode != http.StatusOK || body != "free" {
+		t.Errorf("Expected a path failing the {id:[0-9]+} regex to be free, got %d %q", resp.StatusCode, body)
+	}
+
+	resp, body := get(payingClient(), "/invoices/42")
+	if resp.StatusCode != http.StatusOK || body != "~Alice" {
+		t.Fatalf("Expected the paid handler to see the payer, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" || resp.Header.Get("X-Handler") != "yes" {
+		t.Errorf("Expected settlement and handler headers, got %v", resp.Header)
+	}
+
+	if resp, _ := get(payingClient(), "/articles/hello/errors"); resp.StatusCode != http.StatusInternalServerError || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected a failed handler not to settle, got %d", resp.StatusCode)
+	}
+}
+
+func TestChiAdapterClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+	r.RemoteAddr = "203.0.113.7:51234"
+	r.Header.Set("X-Forwarded-For", "10.1.2.3")
+	if ip := NewChiAdapter(r).GetClientIP(); ip != "203.0.113.7" {
+		t.Errorf("Expected the remote address, got %q", ip)
+	}
+}
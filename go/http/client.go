@@ -1,16 +1,20 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 
 	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/http/headers"
 	"github.com/coinbase/x402/go/types"
 )
 
@@ -21,6 +25,22 @@ import (
 // x402HTTPClient wraps x402Client with HTTP-specific payment handling
 type x402HTTPClient struct {
 	client *x402.X402Client
+
+	// cborPaymentHeaders enables CBOR payment headers (see EnableCBORPaymentHeaders)
+	cborPaymentHeaders bool
+
+	// metrics receives spend events (see SetMetrics)
+	metrics ClientMetrics
+
+	// spendTracker limits spend, applying budgetPolicy when exhausted (see SetSpendTracker)
+	spendTracker *SpendTracker
+	budgetPolicy BudgetPolicy
+
+	// hostPolicies restricts payments per host (see SetHostPolicies)
+	hostPolicies *hostPolicies
+
+	// retryPolicy resends failed paid requests (see SetRetryPolicy)
+	retryPolicy *RetryPolicy
 }
 
 // Newx402HTTPClient creates a new HTTP-aware x402 client
@@ -30,6 +50,22 @@ func Newx402HTTPClient(client *x402.X402Client) *x402HTTPClient {
 	}
 }
 
+// EnableCBORPaymentHeaders advertises compact CBOR payment headers to servers
+// and sends PAYMENT-SIGNATURE as CBOR when the server accepts it and it is
+// smaller than the alternatives. CBOR PAYMENT-REQUIRED headers are always decoded.
+func (c *x402HTTPClient) EnableCBORPaymentHeaders() *x402HTTPClient {
+	c.cborPaymentHeaders = true
+	return c
+}
+
+// paymentHeaderEncodings returns the payment header encodings the client offers
+func (c *x402HTTPClient) paymentHeaderEncodings() []string {
+	if c.cborPaymentHeaders {
+		return []string{PaymentEncodingGzip, PaymentEncodingCBOR}
+	}
+	return []string{PaymentEncodingGzip}
+}
+
 // ============================================================================
 // Header Encoding/Decoding
 // ============================================================================
@@ -70,9 +106,13 @@ func (c *x402HTTPClient) GetPaymentRequiredResponse(headers map[string]string, b
 		normalizedHeaders[strings.ToUpper(k)] = v
 	}
 
-	// Check v2 header first
-	if header, exists := normalizedHeaders["PAYMENT-REQUIRED"]; exists {
-		return decodePaymentRequiredHeader(header)
+	// Check v2 header first (possibly split across chunk headers)
+	header, exists, err := readPaymentRequiredHeader(normalizedHeaders)
+	if err != nil {
+		return x402.PaymentRequired{}, err
+	}
+	if exists {
+		return decodePaymentRequiredHeader(header, normalizedHeaders[PaymentEncodingHeader])
 	}
 
 	// Fall back to v1 body format
@@ -121,21 +161,30 @@ func WrapHTTPClientWithPayment(client *http.Client, x402Client *x402HTTPClient)
 	}
 
 	// Wrap the transport with payment handling
-	originalTransport := client.Transport
-	if originalTransport == nil {
-		originalTransport = http.DefaultTransport
-	}
+	client.Transport = NewPaymentRoundTripper(client.Transport, x402Client)
+
+	return client
+}
 
-	client.Transport = &PaymentRoundTripper{
-		Transport:  originalTransport,
+// NewPaymentRoundTripper returns an http.RoundTripper that answers 402
+// responses by paying with x402Client's registered schemes and retrying the
+// request with the payment attached. transport sends the requests
+// (http.DefaultTransport if nil). Use it where transports are composed
+// rather than an http.Client wrapped, e.g. under other RoundTrippers or as
+// an httputil.ReverseProxy transport.
+func NewPaymentRoundTripper(transport http.RoundTripper, x402Client *x402HTTPClient) *PaymentRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &PaymentRoundTripper{
+		Transport:  transport,
 		x402Client: x402Client,
 		retryCount: &sync.Map{},
 	}
-
-	return client
 }
 
-// PaymentRoundTripper implements http.RoundTripper with x402 payment handling
+// PaymentRoundTripper implements http.RoundTripper with x402 payment handling.
+// Create it with NewPaymentRoundTripper or WrapHTTPClientWithPayment.
 type PaymentRoundTripper struct {
 	Transport  http.RoundTripper
 	x402Client *x402HTTPClient
@@ -155,8 +204,28 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		return nil, fmt.Errorf("payment retry limit exceeded")
 	}
 
-	// Make initial request
-	resp, err := t.Transport.RoundTrip(req)
+	// Buffer a body that cannot be replayed, so the paid retry can resend it
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		buffered, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			t.retryCount.Delete(requestID)
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buffered)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	// Make initial request, advertising the payment header encodings that can be decoded
+	initialReq := req
+	if req.Header.Get(PaymentAcceptEncodingHeader) == "" {
+		initialReq = req.Clone(req.Context())
+		initialReq.Header.Set(PaymentAcceptEncodingHeader, strings.Join(t.x402Client.paymentHeaderEncodings(), ", "))
+	}
+	resp, err := t.Transport.RoundTrip(initialReq)
 	if err != nil {
 		t.retryCount.Delete(requestID)
 		return nil, err
@@ -171,6 +240,15 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 	// Increment retry count
 	t.retryCount.Store(requestID, retries+1)
 
+	//nolint:contextcheck // Intentionally using request's context for payment flow
+	ctx := req.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if t.x402Client.metrics != nil {
+		t.x402Client.metrics.PaymentRequired(ctx, req.URL.Host)
+	}
+
 	// Extract headers
 	headers := make(map[string]string)
 	for k, v := range resp.Header {
@@ -194,86 +272,140 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		return nil, fmt.Errorf("failed to detect payment version: %w", err)
 	}
 
-	//nolint:contextcheck // Intentionally using request's context for payment flow
-	ctx := req.Context()
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
 	// Fork based on version
 	var payloadBytes []byte
+	var selected x402.PaymentRequirementsView
 	if version == 1 {
 		// V1 flow: body-based PaymentRequired, V1 types
-		payloadBytes, err = t.handleV1Payment(ctx, body)
-		if err != nil {
-			t.retryCount.Delete(requestID)
-			return nil, err
-		}
+		payloadBytes, selected, err = t.handleV1Payment(ctx, req.URL, body)
 	} else {
 		// V2 flow: header-based PaymentRequired, V2 types
-		payloadBytes, err = t.handleV2Payment(ctx, headers, body)
-		if err != nil {
-			t.retryCount.Delete(requestID)
-			return nil, err
+		payloadBytes, selected, err = t.handleV2Payment(ctx, req.URL, headers, body)
+	}
+	if err != nil {
+		t.retryCount.Delete(requestID)
+		var budgetErr *BudgetExceededError
+		if errors.As(err, &budgetErr) && t.x402Client.budgetPolicy == BudgetPolicyFallback {
+			// Stop paying and hand back the unpaid response
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
 		}
+		return nil, err
 	}
 
 	// Encode payment header (works for both V1 and V2)
 	paymentHeaders, err := t.x402Client.EncodePaymentSignatureHeader(payloadBytes)
 	if err != nil {
 		t.retryCount.Delete(requestID)
+		t.x402Client.releaseBudget(selected)
 		return nil, fmt.Errorf("failed to encode payment header: %w", err)
 	}
 
-	// Create new request with payment header
-	paymentReq := req.Clone(ctx)
-	for k, v := range paymentHeaders {
-		paymentReq.Header.Set(k, v)
-	}
-
-	// Replenish body for retry if possible
-	if req.GetBody != nil {
-		body, err := req.GetBody()
-		if err != nil {
-			t.retryCount.Delete(requestID)
-			return nil, fmt.Errorf("failed to get body for payment retry: %w", err)
+	// Re-encode the signature when the server accepts an encoding that actually saves space
+	if version == 2 {
+		smallest, encoding := smallestPaymentEncoding(payloadBytes, paymentHeaders["PAYMENT-SIGNATURE"], t.x402Client.paymentHeaderEncodings(), resp.Header.Get(PaymentAcceptEncodingHeader))
+		if encoding != "" {
+			paymentHeaders["PAYMENT-SIGNATURE"] = smallest
+			paymentHeaders[PaymentEncodingHeader] = encoding
 		}
-		paymentReq.Body = body
 	}
 
 	// Retry with payment
-	newResp, err := t.Transport.RoundTrip(paymentReq)
+	newResp, err := t.sendPaid(ctx, req, paymentHeaders)
 	t.retryCount.Delete(requestID)
+	if err != nil || newResp.StatusCode >= http.StatusBadRequest {
+		t.x402Client.releaseBudget(selected)
+	}
+	if err == nil {
+		t.x402Client.reportPaymentResult(ctx, newResp, paymentEvent(req.URL.Host, selected))
+	}
 
 	return newResp, err
 }
 
 // handleV1Payment processes V1 PaymentRequired and creates V1 payload
-func (t *PaymentRoundTripper) handleV1Payment(ctx context.Context, body []byte) ([]byte, error) {
-	// Parse V1 PaymentRequired from body
-	var paymentRequiredV1 types.PaymentRequiredV1
-	if err := json.Unmarshal(body, &paymentRequiredV1); err != nil {
-		return nil, fmt.Errorf("failed to parse V1 payment required: %w", err)
-	}
-
+func (t *PaymentRoundTripper) handleV1Payment(ctx context.Context, requestURL *url.URL, body []byte) ([]byte, x402.PaymentRequirementsView, error) {
 	// Select V1 requirements
-	selectedV1, err := t.x402Client.client.SelectPaymentRequirementsV1(paymentRequiredV1.Accepts)
+	selectedV1, err := t.x402Client.selectRequirementsV1(body)
 	if err != nil {
-		return nil, fmt.Errorf("cannot fulfill V1 payment requirements: %w", err)
+		return nil, nil, err
+	}
+
+	// Enforce the host's spending policy
+	if err := t.x402Client.checkHostPolicy(ctx, requestURL.Host, selectedV1); err != nil {
+		return nil, nil, err
+	}
+
+	// Stop paying once the budget is exhausted
+	if err := t.x402Client.reserveBudget(selectedV1); err != nil {
+		return nil, nil, err
 	}
 
 	// Create V1 payment payload
 	payloadV1, err := t.x402Client.client.CreatePaymentPayloadV1(ctx, selectedV1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create V1 payment: %w", err)
+		t.x402Client.releaseBudget(selectedV1)
+		return nil, nil, fmt.Errorf("failed to create V1 payment: %w", err)
 	}
 
 	// Marshal to bytes
-	return json.Marshal(payloadV1)
+	payloadBytes, err := json.Marshal(payloadV1)
+	return payloadBytes, selectedV1, err
 }
 
 // handleV2Payment processes V2 PaymentRequired and creates V2 payload
-func (t *PaymentRoundTripper) handleV2Payment(ctx context.Context, headers map[string]string, body []byte) ([]byte, error) {
+func (t *PaymentRoundTripper) handleV2Payment(ctx context.Context, requestURL *url.URL, headers map[string]string, body []byte) ([]byte, x402.PaymentRequirementsView, error) {
+	// Select V2 requirements
+	paymentRequiredV2, selectedV2, err := t.x402Client.selectRequirementsV2(requestURL, headers, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Enforce the host's spending policy
+	if err := t.x402Client.checkHostPolicy(ctx, requestURL.Host, selectedV2); err != nil {
+		return nil, nil, err
+	}
+
+	// Stop paying once the budget is exhausted
+	if err := t.x402Client.reserveBudget(selectedV2); err != nil {
+		return nil, nil, err
+	}
+
+	// Create V2 payment payload
+	payloadV2, err := t.x402Client.client.CreatePaymentPayload(
+		ctx,
+		selectedV2,
+		paymentRequiredV2.Resource,
+		paymentRequiredV2.Extensions,
+	)
+	if err != nil {
+		t.x402Client.releaseBudget(selectedV2)
+		return nil, nil, fmt.Errorf("failed to create V2 payment: %w", err)
+	}
+
+	// Marshal to bytes
+	payloadBytes, err := json.Marshal(payloadV2)
+	return payloadBytes, selectedV2, err
+}
+
+// selectRequirementsV1 parses a V1 PaymentRequired body and selects requirements
+func (c *x402HTTPClient) selectRequirementsV1(body []byte) (types.PaymentRequirementsV1, error) {
+	// Parse V1 PaymentRequired from body
+	var paymentRequiredV1 types.PaymentRequiredV1
+	if err := json.Unmarshal(body, &paymentRequiredV1); err != nil {
+		return types.PaymentRequirementsV1{}, fmt.Errorf("failed to parse V1 payment required: %w", err)
+	}
+
+	selectedV1, err := c.client.SelectPaymentRequirementsV1(paymentRequiredV1.Accepts)
+	if err != nil {
+		return types.PaymentRequirementsV1{}, fmt.Errorf("cannot fulfill V1 payment requirements: %w", err)
+	}
+	return selectedV1, nil
+}
+
+// selectRequirementsV2 parses a V2 PaymentRequired and selects requirements
+// the client may pay for the requested URL
+func (c *x402HTTPClient) selectRequirementsV2(requestURL *url.URL, headers map[string]string, body []byte) (types.PaymentRequired, types.PaymentRequirements, error) {
 	// Parse V2 PaymentRequired (from header or body)
 	var paymentRequiredV2 types.PaymentRequired
 
@@ -283,41 +415,43 @@ func (t *PaymentRoundTripper) handleV2Payment(ctx context.Context, headers map[s
 		normalizedHeaders[strings.ToUpper(k)] = v
 	}
 
-	// Try header first (V2 standard)
-	if header, exists := normalizedHeaders["PAYMENT-REQUIRED"]; exists {
-		decoded, err := decodePaymentRequiredHeader(header)
+	// Try header first (V2 standard, possibly split across chunk headers)
+	header, exists, err := readPaymentRequiredHeader(normalizedHeaders)
+	if err != nil {
+		return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("failed to read V2 header: %w", err)
+	}
+	if exists {
+		decoded, err := decodePaymentRequiredHeader(header, normalizedHeaders[PaymentEncodingHeader])
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode V2 header: %w", err)
+			return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("failed to decode V2 header: %w", err)
 		}
 		paymentRequiredV2 = decoded
 	} else if len(body) > 0 {
 		// Fall back to body (some V2 servers might use body)
 		if err := json.Unmarshal(body, &paymentRequiredV2); err != nil {
-			return nil, fmt.Errorf("failed to parse V2 payment required: %w", err)
+			return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("failed to parse V2 payment required: %w", err)
 		}
 	} else {
-		return nil, fmt.Errorf("no V2 payment required information found")
+		return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("no V2 payment required information found")
 	}
 
-	// Select V2 requirements
-	selectedV2, err := t.x402Client.client.SelectPaymentRequirements(paymentRequiredV2.Accepts)
-	if err != nil {
-		return nil, fmt.Errorf("cannot fulfill V2 payment requirements: %w", err)
+	// Refuse requirements bound to another origin, e.g. relayed by a phishing site
+	accepts := filterRequirementsByOrigin(paymentRequiredV2.Accepts, requestURL)
+	if len(accepts) == 0 && len(paymentRequiredV2.Accepts) > 0 {
+		return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("payment requirements are bound to a different origin than %s", requestURL.Host)
 	}
 
-	// Create V2 payment payload
-	payloadV2, err := t.x402Client.client.CreatePaymentPayload(
-		ctx,
-		selectedV2,
-		paymentRequiredV2.Resource,
-		paymentRequiredV2.Extensions,
-	)
+	// Skip options the host's spending policy does not allow
+	accepts, err = c.filterRequirementsByHostPolicy(requestURL.Host, accepts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create V2 payment: %w", err)
+		return paymentRequiredV2, types.PaymentRequirements{}, err
 	}
 
-	// Marshal to bytes
-	return json.Marshal(payloadV2)
+	selectedV2, err := c.client.SelectPaymentRequirements(accepts)
+	if err != nil {
+		return paymentRequiredV2, types.PaymentRequirements{}, fmt.Errorf("cannot fulfill V2 payment requirements: %w", err)
+	}
+	return paymentRequiredV2, selectedV2, nil
 }
 
 // detectPaymentRequiredVersion detects protocol version from HTTP response
@@ -328,8 +462,11 @@ func detectPaymentRequiredVersion(headers map[string]string, body []byte) (int,
 		normalizedHeaders[strings.ToUpper(k)] = v
 	}
 
-	// V2 uses PAYMENT-REQUIRED header
-	if _, exists := normalizedHeaders["PAYMENT-REQUIRED"]; exists {
+	// V2 uses PAYMENT-REQUIRED header (or PAYMENT-REQUIRED-1..N chunks)
+	if _, exists := normalizedHeaders[PaymentRequiredHeader]; exists {
+		return 2, nil
+	}
+	if _, exists := normalizedHeaders[PaymentRequiredChunksHeader]; exists {
 		return 2, nil
 	}
 
@@ -359,11 +496,7 @@ func detectPaymentRequiredVersion(headers map[string]string, body []byte) (int,
 func (c *x402HTTPClient) DoWithPayment(ctx context.Context, req *http.Request) (*http.Response, error) {
 	// Create a client with our transport
 	client := &http.Client{
-		Transport: &PaymentRoundTripper{
-			Transport:  http.DefaultTransport,
-			x402Client: c,
-			retryCount: &sync.Map{},
-		},
+		Transport: NewPaymentRoundTripper(http.DefaultTransport, c),
 	}
 
 	return client.Do(req.WithContext(ctx))
@@ -393,48 +526,25 @@ func (c *x402HTTPClient) PostWithPayment(ctx context.Context, url string, body i
 
 // encodePaymentRequiredHeader encodes payment requirements as base64
 func encodePaymentRequiredHeader(required x402.PaymentRequired) (string, error) {
-	data, err := json.Marshal(required)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal payment required: %w", err)
-	}
-	return base64.StdEncoding.EncodeToString(data), nil
+	return headers.EncodePaymentRequired(required)
 }
 
-// decodePaymentRequiredHeader decodes a base64 payment required header
-func decodePaymentRequiredHeader(header string) (x402.PaymentRequired, error) {
-	data, err := base64.StdEncoding.DecodeString(header)
-	if err != nil {
-		return x402.PaymentRequired{}, fmt.Errorf("invalid base64 encoding: %w", err)
-	}
-
-	var required x402.PaymentRequired
-	if err := json.Unmarshal(data, &required); err != nil {
-		return x402.PaymentRequired{}, fmt.Errorf("invalid payment required JSON: %w", err)
-	}
-
-	return required, nil
+// decodePaymentRequiredHeader decodes a base64 payment required header,
+// decompressing it when encoding (the PAYMENT-ENCODING header) is gzip
+func decodePaymentRequiredHeader(header string, encoding string) (x402.PaymentRequired, error) {
+	return headers.DecodePaymentRequired(header, encoding)
 }
 
 // encodePaymentResponseHeader encodes a settlement response as base64
 func encodePaymentResponseHeader(response x402.SettleResponse) (string, error) {
-	data, err := json.Marshal(response)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal settle response: %w", err)
-	}
-	return base64.StdEncoding.EncodeToString(data), nil
+	return headers.EncodePaymentResponse(response)
 }
 
 // decodePaymentResponseHeader decodes a base64 payment response header
 func decodePaymentResponseHeader(header string) (*x402.SettleResponse, error) {
-	data, err := base64.StdEncoding.DecodeString(header)
+	response, err := headers.DecodePaymentResponse(header)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base64 encoding: %w", err)
-	}
-
-	var response x402.SettleResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("invalid settle response JSON: %w", err)
+		return nil, err
 	}
-
 	return &response, nil
 }
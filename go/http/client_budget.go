@@ -0,0 +1,177 @@
+package http
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Client Spend Budgets
+// ============================================================================
+
+// BudgetPolicy decides what a client does once its budget is exhausted
+type BudgetPolicy int
+
+const (
+	// BudgetPolicyError fails the request with *BudgetExceededError
+	BudgetPolicyError BudgetPolicy = iota
+
+	// BudgetPolicyFallback returns the server's unpaid 402 response, so callers
+	// continue with whatever the server serves without payment
+	BudgetPolicyFallback
+)
+
+// BudgetExceededError is returned when a payment would exceed the client's
+// budget for its network and asset
+type BudgetExceededError struct {
+	Network   string
+	Asset     string
+	Amount    string // Atomic units of the payment
+	Remaining string // Atomic units left in the budget
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("payment of %s %s on %s exceeds the remaining budget of %s", e.Amount, e.Asset, e.Network, e.Remaining)
+}
+
+// SpendTracker limits how much a client pays per network and asset. Payments
+// reserve their amount when created and release it if the server does not
+// accept them. Networks and assets without a budget are not limited.
+// It is safe for concurrent use.
+//
+//	tracker := x402http.NewSpendTracker()
+//	tracker.SetBudget("eip155:8453", usdcAddress, "5000000") // 5 USDC
+//	client := x402http.Newx402HTTPClient(x402Client).SetSpendTracker(tracker, x402http.BudgetPolicyError)
+type SpendTracker struct {
+	mu      sync.Mutex
+	budgets map[spendKey]*big.Int
+	spent   map[spendKey]*big.Int
+}
+
+// NewSpendTracker creates a tracker without budgets
+func NewSpendTracker() *SpendTracker {
+	return &SpendTracker{
+		budgets: make(map[spendKey]*big.Int),
+		spent:   make(map[spendKey]*big.Int),
+	}
+}
+
+// SetBudget limits the total paid in atomic units of asset on network
+func (t *SpendTracker) SetBudget(network, asset, amount string) error {
+	budget, ok := new(big.Int).SetString(amount, 10)
+	if !ok || budget.Sign() < 0 {
+		return fmt.Errorf("invalid budget %q", amount)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budgets[spendKey{network, asset}] = budget
+	return nil
+}
+
+// Spent returns the amount paid or reserved in atomic units of asset on network
+func (t *SpendTracker) Spent(network, asset string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if spent := t.spent[spendKey{network, asset}]; spent != nil {
+		return spent.String()
+	}
+	return "0"
+}
+
+// Remaining returns the amount left in the budget for asset on network, and
+// false when it has no budget
+func (t *SpendTracker) Remaining(network, asset string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k := spendKey{network, asset}
+	budget := t.budgets[k]
+	if budget == nil {
+		return "", false
+	}
+	return t.remaining(k, budget).String(), true
+}
+
+// Reset clears amounts spent, e.g. at the start of a new budget period
+func (t *SpendTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spent = make(map[spendKey]*big.Int)
+}
+
+// reserve counts a payment against its budget, failing when it does not fit
+func (t *SpendTracker) reserve(network, asset, amount string) error {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		value = new(big.Int)
+	}
+	k := spendKey{network, asset}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if budget := t.budgets[k]; budget != nil {
+		if remaining := t.remaining(k, budget); value.Cmp(remaining) > 0 {
+			return &BudgetExceededError{Network: network, Asset: asset, Amount: value.String(), Remaining: remaining.String()}
+		}
+	}
+	if t.spent[k] == nil {
+		t.spent[k] = new(big.Int)
+	}
+	t.spent[k].Add(t.spent[k], value)
+	return nil
+}
+
+// release returns a reserved amount to its budget
+func (t *SpendTracker) release(network, asset, amount string) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if spent := t.spent[spendKey{network, asset}]; spent != nil {
+		spent.Sub(spent, value)
+		if spent.Sign() < 0 {
+			spent.SetInt64(0) // Reset while the payment was in flight
+		}
+	}
+}
+
+// remaining is the budget left after amounts spent; the caller holds the lock
+func (t *SpendTracker) remaining(k spendKey, budget *big.Int) *big.Int {
+	remaining := new(big.Int).Set(budget)
+	if spent := t.spent[k]; spent != nil {
+		remaining.Sub(remaining, spent)
+	}
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	return remaining
+}
+
+// SetSpendTracker limits payments made through the client to the tracker's
+// budgets, applying policy once a payment would exceed them
+func (c *x402HTTPClient) SetSpendTracker(tracker *SpendTracker, policy BudgetPolicy) *x402HTTPClient {
+	c.spendTracker = tracker
+	c.budgetPolicy = policy
+	return c
+}
+
+// reserveBudget reserves a payment of the selected requirements against the
+// client's spend tracker, if any
+func (c *x402HTTPClient) reserveBudget(requirements x402.PaymentRequirementsView) error {
+	if c.spendTracker == nil {
+		return nil
+	}
+	return c.spendTracker.reserve(requirements.GetNetwork(), requirements.GetAsset(), requirements.GetAmount())
+}
+
+// releaseBudget releases a reservation made by reserveBudget
+func (c *x402HTTPClient) releaseBudget(requirements x402.PaymentRequirementsView) {
+	if c.spendTracker == nil || requirements == nil {
+		return
+	}
+	c.spendTracker.release(requirements.GetNetwork(), requirements.GetAsset(), requirements.GetAmount())
+}
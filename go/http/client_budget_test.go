@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func newBudgetTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PAYMENT-SIGNATURE") != "" {
+			_, _ = w.Write([]byte("paid"))
+			return
+		}
+		required := x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirements{
+				{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"},
+			},
+		}
+		reqJSON, _ := json.Marshal(required)
+		w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+		w.WriteHeader(http.StatusPaymentRequired)
+		_, _ = w.Write([]byte("preview"))
+	}))
+}
+
+func newBudgetTestClient(tracker *SpendTracker, policy BudgetPolicy) *http.Client {
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	return WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client).SetSpendTracker(tracker, policy))
+}
+
+func TestSpendTrackerBudgetExceeded(t *testing.T) {
+	server := newBudgetTestServer(t)
+	defer server.Close()
+
+	tracker := NewSpendTracker()
+	if err := tracker.SetBudget("test:1", "TEST", "2500"); err != nil {
+		t.Fatalf("Failed to set budget: %v", err)
+	}
+	client := newBudgetTestClient(tracker, BudgetPolicyError)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected payment %d within budget, got %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(server.URL)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Expected BudgetExceededError, got %v", err)
+	}
+	if budgetErr.Amount != "1000" || budgetErr.Remaining != "500" {
+		t.Errorf("Expected amount 1000 with 500 remaining, got %s and %s", budgetErr.Amount, budgetErr.Remaining)
+	}
+	if spent := tracker.Spent("test:1", "TEST"); spent != "2000" {
+		t.Errorf("Expected 2000 spent, got %s", spent)
+	}
+
+	tracker.Reset()
+	if remaining, _ := tracker.Remaining("test:1", "TEST"); remaining != "2500" {
+		t.Errorf("Expected the full budget after reset, got %s", remaining)
+	}
+}
+
+func TestSpendTrackerFallback(t *testing.T) {
+	server := newBudgetTestServer(t)
+	defer server.Close()
+
+	tracker := NewSpendTracker()
+	_ = tracker.SetBudget("test:1", "TEST", "0")
+	client := newBudgetTestClient(tracker, BudgetPolicyFallback)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the unpaid response, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPaymentRequired || string(body) != "preview" {
+		t.Errorf("Expected the unpaid 402 response, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestSpendTrackerReleasesRejectedPayments(t *testing.T) {
+	tracker := NewSpendTracker()
+	_ = tracker.SetBudget("test:1", "TEST", "1000")
+	if err := tracker.reserve("test:1", "TEST", "1000"); err != nil {
+		t.Fatalf("Expected the reservation to fit, got %v", err)
+	}
+	tracker.release("test:1", "TEST", "1000")
+	if err := tracker.reserve("test:1", "TEST", "1000"); err != nil {
+		t.Errorf("Expected a released amount to be available again, got %v", err)
+	}
+	if _, ok := tracker.Remaining("test:2", "TEST"); ok {
+		t.Error("Expected no budget for an unconfigured network")
+	}
+	if err := tracker.SetBudget("test:1", "TEST", "-1"); err == nil {
+		t.Error("Expected a negative budget to be rejected")
+	}
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// ============================================================================
+// Payment Catalog
+// ============================================================================
+
+// defaultPrefetchConcurrency is how many URLs PrefetchPayments probes at once by default
+const defaultPrefetchConcurrency = 8
+
+// CatalogEntry is the preview of one URL in a PaymentCatalog
+type CatalogEntry struct {
+	URL     string          `json:"url"`
+	Preview *PaymentPreview `json:"preview,omitempty"`
+	Error   string          `json:"error,omitempty"` // Why the URL could not be previewed
+}
+
+// CatalogTotal is the cost of calling every cataloged URL that takes one asset
+// on one network, once each
+type CatalogTotal struct {
+	Network string `json:"network"`
+	Asset   string `json:"asset"`
+	Amount  string `json:"amount"` // Atomic units
+	Calls   int    `json:"calls"`
+}
+
+// PaymentCatalog prices a list of URLs, so agents can plan the budget of a
+// workflow before running it
+type PaymentCatalog struct {
+	Entries []CatalogEntry `json:"entries"` // In the order the URLs were given
+}
+
+// PrefetchPayments previews the payment of each URL, probing up to concurrency
+// URLs at once (default: 8). Failures are recorded per entry.
+func (c *x402HTTPClient) PrefetchPayments(ctx context.Context, urls []string, concurrency int) *PaymentCatalog {
+	if concurrency <= 0 {
+		concurrency = defaultPrefetchConcurrency
+	}
+
+	catalog := &PaymentCatalog{Entries: make([]CatalogEntry, len(urls))}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			entry := CatalogEntry{URL: url}
+			preview, err := c.PreviewPayment(ctx, url)
+			if err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.Preview = preview
+			}
+			catalog.Entries[i] = entry
+		}(i, url)
+	}
+	wg.Wait()
+	return catalog
+}
+
+// Totals sums the payments required per network and asset, sorted in that order
+func (cat *PaymentCatalog) Totals() []CatalogTotal {
+	amounts := make(map[spendKey]*big.Int)
+	calls := make(map[spendKey]int)
+	for _, entry := range cat.Entries {
+		if entry.Preview == nil || !entry.Preview.Required {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(entry.Preview.Amount, 10)
+		if !ok {
+			continue
+		}
+		k := spendKey{entry.Preview.Network, entry.Preview.Asset}
+		if amounts[k] == nil {
+			amounts[k] = new(big.Int)
+		}
+		amounts[k].Add(amounts[k], amount)
+		calls[k]++
+	}
+
+	totals := make([]CatalogTotal, 0, len(amounts))
+	for k, amount := range amounts {
+		totals = append(totals, CatalogTotal{Network: k.network, Asset: k.asset, Amount: amount.String(), Calls: calls[k]})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Network != totals[j].Network {
+			return totals[i].Network < totals[j].Network
+		}
+		return totals[i].Asset < totals[j].Asset
+	})
+	return totals
+}
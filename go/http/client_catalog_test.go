@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestPrefetchPayments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		amount := strings.TrimPrefix(r.URL.Path, "/price/")
+		switch {
+		case r.URL.Path == "/free":
+			w.WriteHeader(http.StatusOK)
+			return
+		case r.URL.Path == "/broken":
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		required := x402.PaymentRequired{
+			X402Version: 2,
+			Accepts:     []x402.PaymentRequirements{{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: amount, PayTo: "0xtest"}},
+		}
+		reqJSON, _ := json.Marshal(required)
+		w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	urls := []string{server.URL + "/price/1000", server.URL + "/free", server.URL + "/price/250", server.URL + "/broken"}
+
+	catalog := Newx402HTTPClient(x402Client).PrefetchPayments(context.Background(), urls, 2)
+
+	if len(catalog.Entries) != len(urls) {
+		t.Fatalf("Expected %d entries, got %d", len(urls), len(catalog.Entries))
+	}
+	for i, entry := range catalog.Entries {
+		if entry.URL != urls[i] {
+			t.Errorf("Expected entry %d for %s, got %s", i, urls[i], entry.URL)
+		}
+	}
+	if catalog.Entries[1].Preview == nil || catalog.Entries[1].Preview.Required {
+		t.Error("Expected the free URL to need no payment")
+	}
+	if catalog.Entries[3].Error == "" {
+		t.Error("Expected an error for the URL without requirements")
+	}
+
+	totals := catalog.Totals()
+	if len(totals) != 1 || totals[0].Amount != "1250" || totals[0].Calls != 2 {
+		t.Errorf("Expected 1250 over 2 calls, got %+v", totals)
+	}
+}
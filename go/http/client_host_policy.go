@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Per-Host Spending Policies
+// ============================================================================
+
+// Host policy refusal reasons, reported in PaymentRefusedError.Reason
+const (
+	RefusedHostBlocked     = "host_blocked"       // The host's policy blocks payments
+	RefusedAmountOverLimit = "amount_over_limit"  // Every option costs more than the host's MaxAmount
+	RefusedApprovalDenied  = "approval_denied"    // Approval was required and not given
+	RefusedApprovalMissing = "approval_not_setup" // Approval was required but no Approve callback is set
+)
+
+// HostPolicy restricts payments to a host
+type HostPolicy struct {
+	// Blocked refuses every payment to the host
+	Blocked bool
+
+	// MaxAmount caps a single payment in atomic units (empty = no cap). Options
+	// above it are skipped, so a cheaper one may be chosen.
+	MaxAmount string
+
+	// ApprovalThreshold requires HostPolicyConfig.Approve for payments above it,
+	// in atomic units (empty = never)
+	ApprovalThreshold string
+}
+
+// PaymentApprovalRequest describes a payment that needs approval
+type PaymentApprovalRequest struct {
+	Host         string
+	Requirements x402.PaymentRequirementsView
+}
+
+// PaymentApprovalFunc approves or denies a payment above a host's approval
+// threshold, e.g. by asking a human operator
+type PaymentApprovalFunc func(ctx context.Context, request PaymentApprovalRequest) (bool, error)
+
+// HostPolicyConfig configures per-host spending policies
+type HostPolicyConfig struct {
+	// Hosts maps hosts to their policies. Keys are hosts ("api.example.com",
+	// with a port if it is not the default), origins ("https://api.example.com"),
+	// or subdomain wildcards ("*.example.com").
+	Hosts map[string]HostPolicy
+
+	// Default applies to hosts not listed (nil = no restrictions). Use
+	// &HostPolicy{Blocked: true} to only pay listed hosts.
+	Default *HostPolicy
+
+	// Approve is called for payments above a policy's ApprovalThreshold
+	Approve PaymentApprovalFunc
+}
+
+// PaymentRefusedError is returned when a host policy refuses a payment
+type PaymentRefusedError struct {
+	Host   string
+	Reason string
+	Err    error // Approval callback failure, if any
+}
+
+func (e *PaymentRefusedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("payment to %s refused: %s: %v", e.Host, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("payment to %s refused: %s", e.Host, e.Reason)
+}
+
+func (e *PaymentRefusedError) Unwrap() error {
+	return e.Err
+}
+
+// hostPolicies is a HostPolicyConfig with normalized host keys
+type hostPolicies struct {
+	hosts   map[string]HostPolicy
+	def     *HostPolicy
+	approve PaymentApprovalFunc
+}
+
+// SetHostPolicies restricts payments made through the client per host, so an
+// agent can be limited to pre-approved services with a cap for each
+func (c *x402HTTPClient) SetHostPolicies(config HostPolicyConfig) *x402HTTPClient {
+	policies := &hostPolicies{
+		hosts:   make(map[string]HostPolicy, len(config.Hosts)),
+		def:     config.Default,
+		approve: config.Approve,
+	}
+	for key, policy := range config.Hosts {
+		if u, err := url.Parse(key); err == nil && u.Host != "" {
+			key = u.Host
+		}
+		policies.hosts[strings.ToLower(key)] = policy
+	}
+	c.hostPolicies = policies
+	return c
+}
+
+// policyFor returns the policy for a host: an exact match, then the host
+// without its port, then the closest wildcard, then the default
+func (p *hostPolicies) policyFor(host string) *HostPolicy {
+	host = strings.ToLower(host)
+	if policy, ok := p.hosts[host]; ok {
+		return &policy
+	}
+	hostname := host
+	if u, err := url.Parse("//" + host); err == nil && u.Hostname() != "" {
+		hostname = u.Hostname()
+	}
+	if policy, ok := p.hosts[hostname]; ok {
+		return &policy
+	}
+	for domain := hostname; strings.Contains(domain, "."); {
+		domain = domain[strings.Index(domain, ".")+1:]
+		if policy, ok := p.hosts["*."+domain]; ok {
+			return &policy
+		}
+	}
+	return p.def
+}
+
+// filterRequirementsByHostPolicy drops options the host's policy does not allow
+func (c *x402HTTPClient) filterRequirementsByHostPolicy(host string, accepts []x402.PaymentRequirements) ([]x402.PaymentRequirements, error) {
+	if c.hostPolicies == nil {
+		return accepts, nil
+	}
+	policy := c.hostPolicies.policyFor(host)
+	if policy == nil {
+		return accepts, nil
+	}
+	if policy.Blocked {
+		return nil, &PaymentRefusedError{Host: host, Reason: RefusedHostBlocked}
+	}
+	if policy.MaxAmount == "" {
+		return accepts, nil
+	}
+
+	allowed := make([]x402.PaymentRequirements, 0, len(accepts))
+	for _, requirements := range accepts {
+		if amountAtMost(requirements.Amount, policy.MaxAmount) {
+			allowed = append(allowed, requirements)
+		}
+	}
+	if len(allowed) == 0 && len(accepts) > 0 {
+		return nil, &PaymentRefusedError{Host: host, Reason: RefusedAmountOverLimit}
+	}
+	return allowed, nil
+}
+
+// checkHostPolicy enforces the host's policy on the selected requirements,
+// asking for approval above its threshold
+func (c *x402HTTPClient) checkHostPolicy(ctx context.Context, host string, requirements x402.PaymentRequirementsView) error {
+	if c.hostPolicies == nil {
+		return nil
+	}
+	policy := c.hostPolicies.policyFor(host)
+	if policy == nil {
+		return nil
+	}
+	if policy.Blocked {
+		return &PaymentRefusedError{Host: host, Reason: RefusedHostBlocked}
+	}
+	if policy.MaxAmount != "" && !amountAtMost(requirements.GetAmount(), policy.MaxAmount) {
+		return &PaymentRefusedError{Host: host, Reason: RefusedAmountOverLimit}
+	}
+	if policy.ApprovalThreshold == "" || amountAtMost(requirements.GetAmount(), policy.ApprovalThreshold) {
+		return nil
+	}
+
+	if c.hostPolicies.approve == nil {
+		return &PaymentRefusedError{Host: host, Reason: RefusedApprovalMissing}
+	}
+	approved, err := c.hostPolicies.approve(ctx, PaymentApprovalRequest{Host: host, Requirements: requirements})
+	if err != nil || !approved {
+		return &PaymentRefusedError{Host: host, Reason: RefusedApprovalDenied, Err: err}
+	}
+	return nil
+}
+
+// amountAtMost reports whether amount is at most limit; unparseable amounts are not
+func amountAtMost(amount, limit string) bool {
+	a, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return false
+	}
+	l, ok := new(big.Int).SetString(limit, 10)
+	if !ok {
+		return false
+	}
+	return a.Cmp(l) <= 0
+}
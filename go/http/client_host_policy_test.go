@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestHostPolicyFor(t *testing.T) {
+	c := Newx402HTTPClient(x402.Newx402Client()).SetHostPolicies(HostPolicyConfig{
+		Hosts: map[string]HostPolicy{
+			"https://api.example.com": {MaxAmount: "100"},
+			"*.example.com":           {MaxAmount: "50"},
+			"localhost:8080":          {MaxAmount: "10"},
+		},
+		Default: &HostPolicy{Blocked: true},
+	})
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "100"},
+		{"API.example.com:443", "100"},
+		{"cdn.eu.example.com", "50"},
+		{"localhost:8080", "10"},
+		{"localhost:9090", ""},
+		{"other.com", ""},
+	}
+	for _, tt := range tests {
+		policy := c.hostPolicies.policyFor(tt.host)
+		if policy == nil {
+			t.Fatalf("Expected a policy for %s", tt.host)
+		}
+		if policy.MaxAmount != tt.want {
+			t.Errorf("policyFor(%s).MaxAmount = %q, want %q", tt.host, policy.MaxAmount, tt.want)
+		}
+	}
+	if !c.hostPolicies.policyFor("other.com").Blocked {
+		t.Error("Expected unlisted hosts to get the default policy")
+	}
+}
+
+func TestHostPolicyRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PAYMENT-SIGNATURE") != "" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		required := x402.PaymentRequired{
+			X402Version: 2,
+			Accepts: []x402.PaymentRequirements{
+				{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "5000", PayTo: "0xtest"},
+				{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "800", PayTo: "0xtest"},
+			},
+		}
+		reqJSON, _ := json.Marshal(required)
+		w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+	host := mustHost(t, server.URL)
+
+	get := func(policy HostPolicy, approve PaymentApprovalFunc) error {
+		x402Client := x402.Newx402Client()
+		x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+		client := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client).SetHostPolicies(HostPolicyConfig{
+			Hosts:   map[string]HostPolicy{host: policy},
+			Approve: approve,
+		}))
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	refusal := func(err error) string {
+		var refused *PaymentRefusedError
+		if !errors.As(err, &refused) {
+			return ""
+		}
+		return refused.Reason
+	}
+
+	if reason := refusal(get(HostPolicy{Blocked: true}, nil)); reason != RefusedHostBlocked {
+		t.Errorf("Expected %s, got %q", RefusedHostBlocked, reason)
+	}
+	if reason := refusal(get(HostPolicy{MaxAmount: "500"}, nil)); reason != RefusedAmountOverLimit {
+		t.Errorf("Expected %s, got %q", RefusedAmountOverLimit, reason)
+	}
+	if err := get(HostPolicy{MaxAmount: "1000"}, nil); err != nil {
+		t.Errorf("Expected the cheaper option within the cap to be paid, got %v", err)
+	}
+	if reason := refusal(get(HostPolicy{ApprovalThreshold: "100"}, nil)); reason != RefusedApprovalMissing {
+		t.Errorf("Expected %s, got %q", RefusedApprovalMissing, reason)
+	}
+
+	var approvedAmount string
+	approve := func(ctx context.Context, request PaymentApprovalRequest) (bool, error) {
+		approvedAmount = request.Requirements.GetAmount()
+		return request.Host == host, nil
+	}
+	if err := get(HostPolicy{ApprovalThreshold: "100"}, approve); err != nil || approvedAmount == "" {
+		t.Errorf("Expected an approved payment, got %v", err)
+	}
+	deny := func(ctx context.Context, request PaymentApprovalRequest) (bool, error) { return false, nil }
+	if reason := refusal(get(HostPolicy{ApprovalThreshold: "100"}, deny)); reason != RefusedApprovalDenied {
+		t.Errorf("Expected %s, got %q", RefusedApprovalDenied, reason)
+	}
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", rawURL, err)
+	}
+	return u.Host
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Client Info
+// ============================================================================
+
+// GeoLocation is where an IP address is located
+type GeoLocation struct {
+	// Country is the ISO 3166-1 alpha-2 code (e.g. "US")
+	Country string `json:"country"`
+
+	// Region is the ISO 3166-2 subdivision code (e.g. "US-NY"), if known
+	Region string `json:"region,omitempty"`
+
+	// City is the city name, if known
+	City string `json:"city,omitempty"`
+}
+
+// GeoIPProvider locates IP addresses, e.g. with a MaxMind database or a
+// lookup service. Lookups run once per request to a paid route, so remote
+// providers should cache.
+type GeoIPProvider interface {
+	Lookup(ctx context.Context, ip net.IP) (*GeoLocation, error)
+}
+
+// GeoIPProviderFunc adapts a function to a GeoIPProvider
+type GeoIPProviderFunc func(ctx context.Context, ip net.IP) (*GeoLocation, error)
+
+// Lookup implements GeoIPProvider
+func (f GeoIPProviderFunc) Lookup(ctx context.Context, ip net.IP) (*GeoLocation, error) {
+	return f(ctx, ip)
+}
+
+// ClientInfo describes the client making a request, as far as the server can
+// tell. It is available to DynamicPriceFunc, DynamicPayToFunc, bypasses,
+// payment option filters, and lifecycle hooks through ClientInfoFromContext.
+type ClientInfo struct {
+	// IP is the client address: the adapter's (see HTTPClientIPAdapter), or the
+	// one a trusted proxy forwarded for (see SetTrustedProxies)
+	IP string
+
+	// Location is set when a GeoIP provider is configured and located the IP
+	Location *GeoLocation
+}
+
+// Country returns the client's ISO 3166-1 alpha-2 country code, or "" when unknown
+func (c ClientInfo) Country() string {
+	if c.Location == nil {
+		return ""
+	}
+	return strings.ToUpper(c.Location.Country)
+}
+
+type clientInfoContextKey struct{}
+
+// ContextWithClientInfo returns a context carrying the client info, e.g. for
+// testing a DynamicPriceFunc
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, info)
+}
+
+// ClientInfoFromContext returns the client info of the request being processed
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return info, ok
+}
+
+// SetGeoIPProvider locates clients by IP for requests to paid routes.
+// Failed lookups leave ClientInfo.Location nil.
+func (s *x402HTTPResourceServer) SetGeoIPProvider(provider GeoIPProvider) *x402HTTPResourceServer {
+	s.geoIP = provider
+	return s
+}
+
+// withClientInfo adds the request's client info to ctx, unless an outer
+// layer already did
+func (s *x402HTTPResourceServer) withClientInfo(ctx context.Context, reqCtx HTTPRequestContext) context.Context {
+	if _, ok := ClientInfoFromContext(ctx); ok {
+		return ctx
+	}
+	info := ClientInfo{IP: s.clientIP(reqCtx.Adapter)}
+	if ip := net.ParseIP(info.IP); ip != nil && s.geoIP != nil {
+		if location, err := s.geoIP.Lookup(ctx, ip); err == nil {
+			info.Location = location
+		}
+	}
+	return ContextWithClientInfo(ctx, info)
+}
+
+// SetTrustedProxies lets requests arriving from the given CIDR ranges (e.g. a
+// load balancer's "10.0.0.0/8") name the client with X-Forwarded-For or
+// X-Real-IP. The forwarding headers of any other request are ignored, and the
+// adapter's address (HTTPClientIPAdapter) is the client IP. Adapters that
+// already resolve proxies, like Gin's, need no trusted proxies here.
+func (s *x402HTTPResourceServer) SetTrustedProxies(cidrs ...string) error {
+	proxies, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	s.trustedProxies = proxies
+	return nil
+}
+
+// clientIP returns the request's client IP: the adapter's address, or, when
+// that is a trusted proxy, the nearest untrusted address it forwarded for
+func (s *x402HTTPResourceServer) clientIP(adapter HTTPAdapter) string {
+	ip := clientIP(adapter)
+	if !inRanges(s.trustedProxies, ip) {
+		return ip
+	}
+	if forwarded := adapter.GetHeader("X-Forwarded-For"); forwarded != "" {
+		// Hops are appended, so the last untrusted one was seen by a trusted proxy
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			ip = hop
+			if !inRanges(s.trustedProxies, hop) {
+				break
+			}
+		}
+		return ip
+	}
+	if realIP := strings.TrimSpace(adapter.GetHeader("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return ip
+}
+
+// clientIP returns the client IP from HTTPClientIPAdapter when implemented
+func clientIP(adapter HTTPAdapter) string {
+	if ipAdapter, ok := adapter.(HTTPClientIPAdapter); ok {
+		return strings.TrimSpace(ipAdapter.GetClientIP())
+	}
+	return ""
+}
+
+// ============================================================================
+// Payment Option Filters
+// ============================================================================
+
+// PaymentOptionFilter reports whether a route's payment option may be offered
+// for a request. Filtered options are neither advertised nor accepted.
+type PaymentOptionFilter func(ctx context.Context, reqCtx HTTPRequestContext, option PaymentOption) bool
+
+// AddPaymentOptionFilter restricts the payment options offered on every route.
+// When a request is left with none, it is refused with 451 Unavailable For
+// Legal Reasons.
+func (s *x402HTTPResourceServer) AddPaymentOptionFilter(filter PaymentOptionFilter) *x402HTTPResourceServer {
+	s.optionFilters = append(s.optionFilters, filter)
+	return s
+}
+
+// filterPaymentOptions returns the options every filter allows
+func (s *x402HTTPResourceServer) filterPaymentOptions(ctx context.Context, reqCtx HTTPRequestContext, options []PaymentOption) []PaymentOption {
+	if len(s.optionFilters) == 0 {
+		return options
+	}
+	allowed := make([]PaymentOption, 0, len(options))
+	for _, option := range options {
+		offered := true
+		for _, filter := range s.optionFilters {
+			if !filter(ctx, reqCtx, option) {
+				offered = false
+				break
+			}
+		}
+		if offered {
+			allowed = append(allowed, option)
+		}
+	}
+	return allowed
+}
+
+// RestrictNetworksByCountry withholds networks from clients in the given
+// countries. Rules map ISO 3166-1 alpha-2 codes to the networks (or wildcard
+// families, e.g. "eip155:*") that must not be offered there; the "" rule
+// applies to clients whose country is unknown. Requires a GeoIP provider.
+//
+//	server.AddPaymentOptionFilter(x402http.RestrictNetworksByCountry(map[string][]x402.Network{
+//	    "US": {"solana:*"},
+//	    "":   {"solana:*"}, // unknown location: be conservative
+//	}))
+func RestrictNetworksByCountry(rules map[string][]x402.Network) PaymentOptionFilter {
+	normalized := make(map[string][]x402.Network, len(rules))
+	for country, networks := range rules {
+		normalized[strings.ToUpper(country)] = networks
+	}
+	return func(ctx context.Context, reqCtx HTTPRequestContext, option PaymentOption) bool {
+		info, _ := ClientInfoFromContext(ctx)
+		for _, pattern := range normalized[info.Country()] {
+			if x402.MatchesNetwork(pattern, option.Network) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// paymentUnavailableResponse refuses requests no payment option is offered for
+func paymentUnavailableResponse() HTTPProcessResult {
+	return HTTPProcessResult{
+		Type: ResultPaymentError,
+		Response: &HTTPResponseInstructions{
+			Status:  http.StatusUnavailableForLegalReasons,
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    map[string]string{"error": "Payment is not available in your region"},
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// testGeoIP places 203.0.113.0/24 in the US and 198.51.100.0/24 in Germany
+var testGeoIP = GeoIPProviderFunc(func(ctx context.Context, ip net.IP) (*GeoLocation, error) {
+	switch {
+	case ip.Equal(net.ParseIP("203.0.113.7")):
+		return &GeoLocation{Country: "us", Region: "US-NY"}, nil
+	case ip.Equal(net.ParseIP("198.51.100.7")):
+		return &GeoLocation{Country: "DE"}, nil
+	}
+	return nil, errors.New("not found")
+})
+
+func newRegionalTestServer(price DynamicPriceFunc) *x402HTTPResourceServer {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{
+			"GET /api/data": {Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: price, Network: "eip155:8453"},
+				{Scheme: "exact", PayTo: "Sol1", Price: price, Network: "solana:mainnet"},
+			}},
+		},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{
+			supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+				return x402.SupportedResponse{Kinds: []x402.SupportedKind{
+					{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+					{X402Version: 2, Scheme: "exact", Network: "solana:mainnet"},
+				}}, nil
+			},
+		}),
+		x402.WithSchemeServer("eip155:8453", &mockSchemeServer{scheme: "exact"}),
+		x402.WithSchemeServer("solana:mainnet", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server.SetGeoIPProvider(testGeoIP)
+}
+
+func TestClientInfoReachesDynamicPrice(t *testing.T) {
+	var seen []ClientInfo
+	server := newRegionalTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+		info, _ := ClientInfoFromContext(ctx)
+		seen = append(seen, info)
+		if info.Country() == "DE" {
+			return "$0.80", nil
+		}
+		return "$1.00", nil
+	})
+
+	process := func(adapter HTTPAdapter) {
+		server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: adapter}, nil)
+	}
+	process(&clientIPAdapter{ip: "203.0.113.7"})
+	process(&mockHTTPAdapter{headers: map[string]string{"X-Forwarded-For": "198.51.100.7, 10.0.0.1"}})
+	process(&clientIPAdapter{ip: "192.0.2.1"})
+
+	if len(seen) != 6 {
+		t.Fatalf("Expected the price resolved for both options of 3 requests, got %d", len(seen))
+	}
+	if seen[0].IP != "203.0.113.7" || seen[0].Country() != "US" || seen[0].Location.Region != "US-NY" {
+		t.Errorf("Unexpected client info %+v", seen[0])
+	}
+	if seen[2].IP != "" || seen[2].Location != nil {
+		t.Errorf("Expected the forwarded address not to be trusted, got %+v", seen[2])
+	}
+	if seen[4].IP != "192.0.2.1" || seen[4].Location != nil || seen[4].Country() != "" {
+		t.Errorf("Expected an unlocated client, got %+v", seen[4])
+	}
+
+	// Client info set by an outer layer is kept
+	ctx := ContextWithClientInfo(context.Background(), ClientInfo{IP: "1.1.1.1", Location: &GeoLocation{Country: "DE"}})
+	server.ProcessHTTPRequest(ctx, HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: &clientIPAdapter{ip: "203.0.113.7"}}, nil)
+	if seen[6].IP != "1.1.1.1" {
+		t.Errorf("Expected the outer client info, got %+v", seen[6])
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	var seen ClientInfo
+	server := newRegionalTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+		seen, _ = ClientInfoFromContext(ctx)
+		return "$1.00", nil
+	})
+	if err := server.SetTrustedProxies("10.0.0.0/8", "nope"); err == nil {
+		t.Error("Expected an invalid CIDR to be rejected")
+	}
+	if err := server.SetTrustedProxies("10.0.0.0/8"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"direct client", "203.0.113.7", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "203.0.113.7"},
+		{"forwarded by proxy", "10.0.0.5", map[string]string{"X-Forwarded-For": "198.51.100.7, 10.0.0.9"}, "198.51.100.7"},
+		{"spoofed hop before proxy", "10.0.0.5", map[string]string{"X-Forwarded-For": "203.0.113.7, 198.51.100.7"}, "198.51.100.7"},
+		{"real ip from proxy", "10.0.0.5", map[string]string{"X-Real-IP": "198.51.100.7"}, "198.51.100.7"},
+		{"proxy without headers", "10.0.0.5", nil, "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &clientIPAdapter{mockHTTPAdapter: mockHTTPAdapter{headers: tt.headers}, ip: tt.remote}
+			reqCtx := HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: adapter}
+			server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+			if seen.IP != tt.want {
+				t.Errorf("Expected client IP %s, got %s", tt.want, seen.IP)
+			}
+			if key := DefaultClientKey(ContextWithClientInfo(context.Background(), seen), reqCtx); key != "ip:"+tt.want {
+				t.Errorf("Expected client key ip:%s, got %s", tt.want, key)
+			}
+		})
+	}
+}
+
+func TestRestrictNetworksByCountry(t *testing.T) {
+	server := newRegionalTestServer(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+		return "$1.00", nil
+	}).AddPaymentOptionFilter(RestrictNetworksByCountry(map[string][]x402.Network{
+		"us": {"solana:*"},
+		"":   {"solana:*", "eip155:*"},
+	}))
+
+	offered := func(ip string) ([]string, int) {
+		result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Path: "/api/data", Method: "GET", Adapter: &clientIPAdapter{ip: ip}}, nil)
+		if result.Response.Status != http.StatusPaymentRequired {
+			return nil, result.Response.Status
+		}
+		required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+		if err != nil {
+			t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+		}
+		networks := make([]string, len(required.Accepts))
+		for i, accept := range required.Accepts {
+			networks[i] = accept.Network
+		}
+		return networks, http.StatusPaymentRequired
+	}
+
+	if networks, _ := offered("203.0.113.7"); len(networks) != 1 || networks[0] != "eip155:8453" {
+		t.Errorf("Expected Solana withheld in the US, got %v", networks)
+	}
+	if networks, _ := offered("198.51.100.7"); len(networks) != 2 {
+		t.Errorf("Expected every network offered in Germany, got %v", networks)
+	}
+	if _, status := offered("192.0.2.1"); status != http.StatusUnavailableForLegalReasons {
+		t.Errorf("Expected 451 when no network may be offered, got %d", status)
+	}
+}
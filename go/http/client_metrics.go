@@ -0,0 +1,237 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Client Spend Metrics
+// ============================================================================
+
+// ClientPaymentEvent describes a payment sent by a client
+type ClientPaymentEvent struct {
+	Host    string
+	Scheme  string
+	Network string
+	Asset   string
+	Amount  string // Atomic units
+
+	// Reason is the server's rejection reason, when it gave one (rejections only)
+	Reason string
+}
+
+// ClientMetrics receives spend events from clients set up with SetMetrics.
+// Implement it to export to Prometheus or OpenTelemetry; SpendMetrics is an
+// in-memory implementation with Prometheus text exposition.
+type ClientMetrics interface {
+	// PaymentRequired is called for every 402 response to a request without payment
+	PaymentRequired(ctx context.Context, host string)
+
+	// PaymentMade is called when the server accepts a payment (a non-error response)
+	PaymentMade(ctx context.Context, event ClientPaymentEvent)
+
+	// PaymentRejected is called when the server answers a payment with another 402,
+	// e.g. because verification failed
+	PaymentRejected(ctx context.Context, event ClientPaymentEvent)
+}
+
+// SetMetrics reports payments made through the client to metrics
+func (c *x402HTTPClient) SetMetrics(metrics ClientMetrics) *x402HTTPClient {
+	c.metrics = metrics
+	return c
+}
+
+// reportPaymentResult reports the response to a paid request to the client's metrics
+func (c *x402HTTPClient) reportPaymentResult(ctx context.Context, resp *http.Response, event ClientPaymentEvent) {
+	switch {
+	case c.metrics == nil:
+	case resp.StatusCode == http.StatusPaymentRequired:
+		event.Reason = paymentRejectionReason(resp)
+		c.metrics.PaymentRejected(ctx, event)
+	case resp.StatusCode < http.StatusBadRequest:
+		c.metrics.PaymentMade(ctx, event)
+	}
+}
+
+// paymentEvent describes the selected requirements of a payment to host
+func paymentEvent(host string, requirements x402.PaymentRequirementsView) ClientPaymentEvent {
+	return ClientPaymentEvent{
+		Host:    host,
+		Scheme:  requirements.GetScheme(),
+		Network: requirements.GetNetwork(),
+		Asset:   requirements.GetAsset(),
+		Amount:  requirements.GetAmount(),
+	}
+}
+
+// paymentRejectionReason reads the error of a 402 response from its
+// PAYMENT-REQUIRED header, or its body (V1), leaving the body readable
+func paymentRejectionReason(resp *http.Response) string {
+	normalizedHeaders := make(map[string]string)
+	for k, v := range resp.Header {
+		if len(v) > 0 {
+			normalizedHeaders[strings.ToUpper(k)] = v[0]
+		}
+	}
+	if header, exists, err := readPaymentRequiredHeader(normalizedHeaders); err == nil && exists {
+		if required, err := decodePaymentRequiredHeader(header, normalizedHeaders[PaymentEncodingHeader]); err == nil {
+			return required.Error
+		}
+	}
+
+	if resp.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var required struct {
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(body, &required)
+	return required.Error
+}
+
+type spendKey struct{ network, asset string }
+
+type rejectionKey struct{ host, network, reason string }
+
+// SpendMetrics counts client payments in memory and serves them in the
+// Prometheus text format. It is safe for concurrent use.
+//
+//	metrics := x402http.NewSpendMetrics()
+//	client := x402http.Newx402HTTPClient(x402Client).SetMetrics(metrics)
+//	http.Handle("/metrics", metrics)
+type SpendMetrics struct {
+	mu         sync.Mutex
+	required   map[string]int64
+	payments   map[spendKey]int64
+	spent      map[spendKey]*big.Int
+	rejections map[rejectionKey]int64
+}
+
+// NewSpendMetrics creates empty spend metrics
+func NewSpendMetrics() *SpendMetrics {
+	return &SpendMetrics{
+		required:   make(map[string]int64),
+		payments:   make(map[spendKey]int64),
+		spent:      make(map[spendKey]*big.Int),
+		rejections: make(map[rejectionKey]int64),
+	}
+}
+
+// PaymentRequired implements ClientMetrics
+func (m *SpendMetrics) PaymentRequired(ctx context.Context, host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.required[host]++
+}
+
+// PaymentMade implements ClientMetrics
+func (m *SpendMetrics) PaymentMade(ctx context.Context, event ClientPaymentEvent) {
+	k := spendKey{event.Network, event.Asset}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payments[k]++
+	if m.spent[k] == nil {
+		m.spent[k] = new(big.Int)
+	}
+	if amount, ok := new(big.Int).SetString(event.Amount, 10); ok {
+		m.spent[k].Add(m.spent[k], amount)
+	}
+}
+
+// PaymentRejected implements ClientMetrics
+func (m *SpendMetrics) PaymentRejected(ctx context.Context, event ClientPaymentEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejections[rejectionKey{event.Host, event.Network, event.Reason}]++
+}
+
+// Spent returns the total paid in atomic units of asset on network
+func (m *SpendMetrics) Spent(network, asset string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if spent := m.spent[spendKey{network, asset}]; spent != nil {
+		return spent.String()
+	}
+	return "0"
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *SpendMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP x402_client_payment_required_total 402 responses to requests without payment.\n")
+	b.WriteString("# TYPE x402_client_payment_required_total counter\n")
+	for _, host := range sortedKeys(m.required, func(a, b string) bool { return a < b }) {
+		fmt.Fprintf(&b, "x402_client_payment_required_total{host=%q} %d\n", host, m.required[host])
+	}
+
+	spendLess := func(a, b spendKey) bool {
+		if a.network != b.network {
+			return a.network < b.network
+		}
+		return a.asset < b.asset
+	}
+	b.WriteString("# HELP x402_client_payments_total Payments accepted by servers.\n")
+	b.WriteString("# TYPE x402_client_payments_total counter\n")
+	for _, k := range sortedKeys(m.payments, spendLess) {
+		fmt.Fprintf(&b, "x402_client_payments_total{network=%q,asset=%q} %d\n", k.network, k.asset, m.payments[k])
+	}
+	b.WriteString("# HELP x402_client_payment_amount_total Amount paid in atomic units of the asset.\n")
+	b.WriteString("# TYPE x402_client_payment_amount_total counter\n")
+	for _, k := range sortedKeys(m.spent, spendLess) {
+		fmt.Fprintf(&b, "x402_client_payment_amount_total{network=%q,asset=%q} %s\n", k.network, k.asset, m.spent[k])
+	}
+
+	b.WriteString("# HELP x402_client_payment_rejections_total Payments rejected by servers.\n")
+	b.WriteString("# TYPE x402_client_payment_rejections_total counter\n")
+	rejections := sortedKeys(m.rejections, func(a, b rejectionKey) bool {
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.network != b.network {
+			return a.network < b.network
+		}
+		return a.reason < b.reason
+	})
+	for _, k := range rejections {
+		fmt.Fprintf(&b, "x402_client_payment_rejections_total{host=%q,network=%q,reason=%q} %d\n", k.host, k.network, k.reason, m.rejections[k])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics for Prometheus to scrape
+func (m *SpendMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_ = m.WritePrometheus(w)
+}
+
+// sortedKeys returns the keys of a map in the order given by less
+func sortedKeys[K comparable, V any](m map[K]V, less func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestClientSpendMetrics(t *testing.T) {
+	writeRequired := func(w http.ResponseWriter, reason string) {
+		required := x402.PaymentRequired{
+			X402Version: 2,
+			Error:       reason,
+			Accepts: []x402.PaymentRequirements{
+				{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"},
+			},
+		}
+		reqJSON, _ := json.Marshal(required)
+		w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("PAYMENT-SIGNATURE") == "":
+			writeRequired(w, "Payment required")
+		case r.URL.Path == "/rejected":
+			writeRequired(w, "invalid_signature")
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	metrics := NewSpendMetrics()
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client).SetMetrics(metrics))
+
+	for _, path := range []string{"/paid", "/paid", "/rejected"} {
+		req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL+path, nil)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if spent := metrics.Spent("test:1", "TEST"); spent != "2000" {
+		t.Errorf("Expected 2000 spent, got %s", spent)
+	}
+
+	host, _ := url.Parse(server.URL)
+	var out strings.Builder
+	if err := metrics.WritePrometheus(&out); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	for _, line := range []string{
+		`x402_client_payment_required_total{host="` + host.Host + `"} 3`,
+		`x402_client_payments_total{network="test:1",asset="TEST"} 2`,
+		`x402_client_payment_amount_total{network="test:1",asset="TEST"} 2000`,
+		`x402_client_payment_rejections_total{host="` + host.Host + `",network="test:1",reason="invalid_signature"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPaymentRejectionReasonV1Body(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(http.StatusPaymentRequired)
+	_, _ = rec.WriteString(`{"x402Version":1,"error":"insufficient_funds","accepts":[]}`)
+	resp := rec.Result()
+
+	if reason := paymentRejectionReason(resp); reason != "insufficient_funds" {
+		t.Errorf("Expected insufficient_funds, got %q", reason)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "insufficient_funds") {
+		t.Error("Expected the body to remain readable")
+	}
+}
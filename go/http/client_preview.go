@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Payment Preview
+// ============================================================================
+
+// PaymentPreview describes what a request would pay, for display in UIs and
+// agent reasoning
+type PaymentPreview struct {
+	// Required is false when the server served the resource without payment
+	Required bool `json:"required"`
+
+	X402Version int    `json:"x402Version,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+	Network     string `json:"network,omitempty"`
+	Asset       string `json:"asset,omitempty"`
+	Amount      string `json:"amount,omitempty"` // Atomic units
+	PayTo       string `json:"payTo,omitempty"`
+
+	// Resource is the resource the payment is for (V2)
+	Resource *types.ResourceInfo `json:"resource,omitempty"`
+
+	// Options is the number of payment options the server offered (V2)
+	Options int `json:"options,omitempty"`
+}
+
+// PreviewPayment requests url without payment and, if the server answers 402,
+// selects requirements the way a paid request would. Nothing is signed, so
+// previews never spend funds or count against budgets.
+func (c *x402HTTPClient) PreviewPayment(ctx context.Context, url string) (*PaymentPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(PaymentAcceptEncodingHeader, strings.Join(c.paymentHeaderEncodings(), ", "))
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPaymentRequired {
+		return &PaymentPreview{Required: false}, nil
+	}
+
+	headers := make(map[string]string)
+	for k, v := range resp.Header {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	version, err := detectPaymentRequiredVersion(headers, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect payment version: %w", err)
+	}
+
+	if version == 1 {
+		selectedV1, err := c.selectRequirementsV1(body)
+		if err != nil {
+			return nil, err
+		}
+		return &PaymentPreview{
+			Required:    true,
+			X402Version: 1,
+			Scheme:      selectedV1.Scheme,
+			Network:     selectedV1.Network,
+			Asset:       selectedV1.Asset,
+			Amount:      selectedV1.MaxAmountRequired,
+			PayTo:       selectedV1.PayTo,
+		}, nil
+	}
+
+	paymentRequiredV2, selectedV2, err := c.selectRequirementsV2(req.URL, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentPreview{
+		Required:    true,
+		X402Version: 2,
+		Scheme:      selectedV2.Scheme,
+		Network:     selectedV2.Network,
+		Asset:       selectedV2.Asset,
+		Amount:      selectedV2.Amount,
+		PayTo:       selectedV2.PayTo,
+		Resource:    paymentRequiredV2.Resource,
+		Options:     len(paymentRequiredV2.Accepts),
+	}, nil
+}
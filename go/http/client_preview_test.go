@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestPreviewPayment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PAYMENT-SIGNATURE") != "" {
+			t.Error("Expected previews not to send a payment")
+		}
+		if r.URL.Path == "/free" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		required := x402.PaymentRequired{
+			X402Version: 2,
+			Resource:    &types.ResourceInfo{URL: "http://" + r.Host + "/paid", Description: "Weather"},
+			Accepts: []x402.PaymentRequirements{
+				{Scheme: "other", Network: "test:2", Asset: "OTHER", Amount: "1", PayTo: "0xother"},
+				{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"},
+			},
+		}
+		reqJSON, _ := json.Marshal(required)
+		w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	client := Newx402HTTPClient(x402Client)
+	ctx := context.Background()
+
+	preview, err := client.PreviewPayment(ctx, server.URL+"/paid")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !preview.Required || preview.X402Version != 2 || preview.Options != 2 {
+		t.Errorf("Expected a required V2 payment with 2 options, got %+v", preview)
+	}
+	if preview.Network != "test:1" || preview.Asset != "TEST" || preview.Amount != "1000" || preview.PayTo != "0xtest" {
+		t.Errorf("Expected the payable option to be selected, got %+v", preview)
+	}
+	if preview.Resource == nil || preview.Resource.Description != "Weather" {
+		t.Errorf("Expected the resource, got %+v", preview.Resource)
+	}
+
+	preview, err = client.PreviewPayment(ctx, server.URL+"/free")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if preview.Required {
+		t.Error("Expected no payment for a free resource")
+	}
+}
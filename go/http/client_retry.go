@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Client Retry Policy
+// ============================================================================
+
+// DefaultRetryStatuses are the paid request statuses retried when a
+// RetryPolicy does not list its own: a rejected payment (verification or
+// settlement may have failed at the facilitator or RPC), rate limiting, and
+// gateway errors.
+var DefaultRetryStatuses = []int{
+	http.StatusPaymentRequired,
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy retries paid requests that fail transiently. Each retry sends
+// the same payment again: servers only settle successful responses, so an
+// authorization that failed was not charged and can settle at most once.
+// Transport errors are not retried, since the payment may have settled
+// before the connection failed.
+//
+//	client := x402http.Newx402HTTPClient(x402Client).SetRetryPolicy(x402http.RetryPolicy{
+//	    MaxAttempts: 3,
+//	    Backoff:     time.Second,
+//	})
+type RetryPolicy struct {
+	// MaxAttempts bounds how many times the paid request is sent, including
+	// the first (values below 2 disable retries)
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, doubling for each one after
+	// Default: 500 milliseconds
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay, including one asked for with Retry-After
+	// Default: 10 seconds
+	MaxBackoff time.Duration
+
+	// RetryOn lists the statuses that are retried (default: DefaultRetryStatuses)
+	RetryOn []int
+}
+
+// SetRetryPolicy retries paid requests under policy
+func (c *x402HTTPClient) SetRetryPolicy(policy RetryPolicy) *x402HTTPClient {
+	if policy.Backoff <= 0 {
+		policy.Backoff = 500 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.RetryOn == nil {
+		policy.RetryOn = DefaultRetryStatuses
+	}
+	c.retryPolicy = &policy
+	return c
+}
+
+// retries reports whether a paid request answered with status is sent again
+// after attempt attempts
+func (p *RetryPolicy) retries(attempt int, status int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	for _, code := range p.RetryOn {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before retrying after attempt attempts,
+// preferring the server's Retry-After seconds
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+	if delay > p.MaxBackoff || delay < 0 {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// sendPaid sends req with paymentHeaders, sending the same payment again
+// under the client's retry policy while it fails with a retryable status
+func (t *PaymentRoundTripper) sendPaid(ctx context.Context, req *http.Request, paymentHeaders map[string]string) (*http.Response, error) {
+	policy := t.x402Client.retryPolicy
+	for attempt := 1; ; attempt++ {
+		paymentReq := req.Clone(ctx)
+		for k, v := range paymentHeaders {
+			paymentReq.Header.Set(k, v)
+		}
+
+		// Replenish body for retry if possible
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get body for payment retry: %w", err)
+			}
+			paymentReq.Body = body
+		}
+
+		resp, err := t.Transport.RoundTrip(paymentReq)
+		if err != nil || !policy.retries(attempt, resp.StatusCode) {
+			return resp, err
+		}
+
+		delay := policy.delay(attempt, resp)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
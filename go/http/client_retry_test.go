@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// newRetryTestServer requires payment, then fails paid requests with status
+// until failures of them have been answered; it records payment headers
+func newRetryTestServer(t *testing.T, status int, failures int, payments *[]string, bodies *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payment := r.Header.Get("PAYMENT-SIGNATURE")
+		if payment == "" {
+			reqJSON, _ := json.Marshal(x402.PaymentRequired{
+				X402Version: 2,
+				Accepts:     []x402.PaymentRequirements{{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"}},
+			})
+			w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		*payments = append(*payments, payment)
+		*bodies = append(*bodies, string(body))
+		if len(*payments) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("paid"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newRetryTestClient(policy *RetryPolicy) *http.Client {
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	client := Newx402HTTPClient(x402Client)
+	if policy != nil {
+		client.SetRetryPolicy(*policy)
+	}
+	return WrapHTTPClientWithPayment(&http.Client{}, client)
+}
+
+func TestRetryPolicyResendsPayment(t *testing.T) {
+	var payments, bodies []string
+	server := newRetryTestServer(t, http.StatusServiceUnavailable, 2, &payments, &bodies)
+	client := newRetryTestClient(&RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("order=1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the third attempt to succeed, got %d", resp.StatusCode)
+	}
+	if len(payments) != 3 || payments[1] != payments[0] || payments[2] != payments[0] {
+		t.Errorf("Expected the same payment sent three times, got %d payments", len(payments))
+	}
+	for _, body := range bodies {
+		if body != "order=1" {
+			t.Errorf("Expected the body resent with each attempt, got %q", bodies)
+			break
+		}
+	}
+}
+
+func TestRetryPolicyLimits(t *testing.T) {
+	t.Run("attempts exhausted", func(t *testing.T) {
+		var payments, bodies []string
+		server := newRetryTestServer(t, http.StatusPaymentRequired, 5, &payments, &bodies)
+		resp, err := newRetryTestClient(&RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusPaymentRequired || len(payments) != 2 {
+			t.Errorf("Expected the last failure after 2 attempts, got %d after %d", resp.StatusCode, len(payments))
+		}
+	})
+
+	t.Run("status not retried", func(t *testing.T) {
+		var payments, bodies []string
+		server := newRetryTestServer(t, http.StatusInternalServerError, 1, &payments, &bodies)
+		resp, err := newRetryTestClient(&RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusInternalServerError || len(payments) != 1 {
+			t.Errorf("Expected a 500 not to be retried, got %d after %d attempts", resp.StatusCode, len(payments))
+		}
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		var payments, bodies []string
+		server := newRetryTestServer(t, http.StatusServiceUnavailable, 1, &payments, &bodies)
+		resp, err := newRetryTestClient(nil).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || len(payments) != 1 {
+			t.Errorf("Expected no retries without a policy, got %d after %d attempts", resp.StatusCode, len(payments))
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	client := Newx402HTTPClient(x402.Newx402Client()).SetRetryPolicy(RetryPolicy{MaxAttempts: 10, Backoff: time.Second, MaxBackoff: 5 * time.Second})
+	policy := client.retryPolicy
+	if len(policy.RetryOn) != len(DefaultRetryStatuses) {
+		t.Errorf("Expected the default retry statuses, got %v", policy.RetryOn)
+	}
+
+	noHeader := &http.Response{Header: http.Header{}}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 60: 5 * time.Second} {
+		if got := policy.delay(attempt, noHeader); got != want {
+			t.Errorf("Expected a delay of %v after attempt %d, got %v", want, attempt, got)
+		}
+	}
+	for header, want := range map[string]time.Duration{"0": 0, "3": 3 * time.Second, "120": 5 * time.Second, "soon": time.Second} {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{header}}}
+		if got := policy.delay(1, resp); got != want {
+			t.Errorf("Expected Retry-After %q to delay %v, got %v", header, want, got)
+		}
+	}
+}
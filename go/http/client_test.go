@@ -313,6 +313,49 @@ func TestPaymentRoundTripperNoRetryOn200(t *testing.T) {
 	}
 }
 
+func TestNewPaymentRoundTripper(t *testing.T) {
+	// Server that requires payment and echoes the request body it was paid for
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("PAYMENT-SIGNATURE") == "" {
+			reqJSON, _ := json.Marshal(x402.PaymentRequired{
+				X402Version: 2,
+				Accepts:     []x402.PaymentRequirements{{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"}},
+			})
+			w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	transport := NewPaymentRoundTripper(nil, Newx402HTTPClient(x402Client))
+
+	// A reader without GetBody cannot be replayed by net/http
+	req, _ := http.NewRequest(http.MethodPost, server.URL, io.MultiReader(strings.NewReader("order=1")))
+	if req.GetBody != nil {
+		t.Fatal("Expected a request body that cannot be replayed")
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "order=1" {
+		t.Errorf("Expected the paid response, got %d %q", resp.StatusCode, body)
+	}
+	if len(bodies) != 2 || bodies[0] != "order=1" || bodies[1] != "order=1" {
+		t.Errorf("Expected the body sent with both requests, got %q", bodies)
+	}
+}
+
 func TestDoWithPayment(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,248 @@
+// Package connect provides an x402 payment interceptor for Connect services
+// (connectrpc.com/connect).
+//
+// Procedures are priced with the same RoutesConfig as HTTP routes, keyed by
+// procedure path, so one config can cover a Connect service and plain HTTP
+// routes served by the same x402http.HTTPServer. Payments travel in the
+// PAYMENT-SIGNATURE request header; a call without a valid payment fails with
+// PaymentRequiredCode and the PAYMENT-REQUIRED challenge in the error
+// metadata, and a paid call returns PAYMENT-RESPONSE in the response headers.
+//
+//	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+//	    "POST /weather.v1.WeatherService/Forecast": {Accepts: accepts, Description: "7-day forecast"},
+//	}, x402.WithFacilitatorClient(facilitator))
+//	_ = server.Initialize(ctx)
+//
+//	path, handler := weatherv1connect.NewWeatherServiceHandler(svc,
+//	    connect.WithInterceptors(connectmw.NewInterceptor(server)))
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"connectrpc.com/connect"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+)
+
+// PaymentRequiredCode is the Connect code of calls that need a payment, in
+// place of HTTP's 402
+const PaymentRequiredCode = connect.CodePermissionDenied
+
+// ============================================================================
+// Request Adapter
+// ============================================================================
+
+// ConnectAdapter implements HTTPAdapter for Connect requests
+type ConnectAdapter struct {
+	req connect.AnyRequest
+}
+
+// NewConnectAdapter creates a new adapter
+func NewConnectAdapter(req connect.AnyRequest) *ConnectAdapter {
+	return &ConnectAdapter{req: req}
+}
+
+// GetHeader gets a request header
+func (a *ConnectAdapter) GetHeader(name string) string {
+	return a.req.Header().Get(name)
+}
+
+// GetMethod gets the HTTP method (POST, or GET for idempotent procedures)
+func (a *ConnectAdapter) GetMethod() string {
+	return a.req.HTTPMethod()
+}
+
+// GetPath gets the procedure path ("/package.Service/Method")
+func (a *ConnectAdapter) GetPath() string {
+	return a.req.Spec().Procedure
+}
+
+// GetURL gets the procedure path; Connect requests don't expose the host
+func (a *ConnectAdapter) GetURL() string {
+	return a.req.Spec().Procedure
+}
+
+// GetAcceptHeader returns no Accept header, so challenges are never paywall HTML
+func (a *ConnectAdapter) GetAcceptHeader() string {
+	return ""
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *ConnectAdapter) GetUserAgent() string {
+	return a.req.Header().Get("User-Agent")
+}
+
+// GetClientIP gets the address of the call's peer
+func (a *ConnectAdapter) GetClientIP() string {
+	return x402http.RemoteIP(a.req.Peer().Addr)
+}
+
+// ============================================================================
+// Interceptor
+// ============================================================================
+
+// Interceptor charges for Connect procedures. It implements connect.Interceptor.
+type Interceptor struct {
+	server *x402http.HTTPServer
+}
+
+// NewInterceptor creates an interceptor for an HTTP server that is already
+// configured and initialized. Calls whose handler returns an error are not
+// settled. Paid streaming procedures are rejected, since only unary calls are
+// settled.
+func NewInterceptor(server *x402http.HTTPServer) *Interceptor {
+	return &Interceptor{server: server}
+}
+
+// WrapUnary charges for unary procedures on the handler side
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		reqCtx := i.requestContext(req)
+		if !i.server.RequiresPayment(reqCtx) {
+			return next(ctx, req)
+		}
+
+		result := i.server.ProcessHTTPRequest(ctx, reqCtx, nil)
+		switch result.Type {
+		case x402http.ResultPaymentError:
+			return nil, paymentError(result.Response)
+		case x402http.ResultPaymentVerified:
+			return i.handlePaymentVerified(ctx, req, next, result)
+		default:
+			return next(ctx, req)
+		}
+	}
+}
+
+// WrapStreamingClient leaves client streams unchanged
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler rejects streaming procedures that require payment
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		reqCtx := x402http.HTTPRequestContext{
+			Adapter: &streamAdapter{conn: conn},
+			Path:    conn.Spec().Procedure,
+			Method:  http.MethodPost,
+		}
+		if i.server.RequiresPayment(reqCtx) {
+			return connect.NewError(connect.CodeUnimplemented, errors.New("x402: paid streaming procedures are not supported"))
+		}
+		return next(ctx, conn)
+	}
+}
+
+// requestContext describes a unary request to the HTTP server
+func (i *Interceptor) requestContext(req connect.AnyRequest) x402http.HTTPRequestContext {
+	return x402http.HTTPRequestContext{
+		Adapter: NewConnectAdapter(req),
+		Path:    req.Spec().Procedure,
+		Method:  req.HTTPMethod(),
+	}
+}
+
+// handlePaymentVerified runs the handler and settles if it succeeded
+func (i *Interceptor) handlePaymentVerified(ctx context.Context, req connect.AnyRequest, next connect.UnaryFunc, result x402http.HTTPProcessResult) (connect.AnyResponse, error) {
+	// Expose the payer to the handler
+	identity := x402http.NewPaymentIdentity(result)
+	resp, err := next(x402http.ContextWithPaymentIdentity(ctx, identity), req)
+	if err != nil {
+		// Let the client retry with the same authorization
+		i.server.ReleasePayment(ctx, *result.PaymentPayload)
+		return nil, err
+	}
+
+	// Per-item procedures settle only for the items the handler returned
+	amount := x402http.ItemSettlementAmount(result, identity)
+	if amount == "0" {
+		i.server.ReleasePayment(ctx, *result.PaymentPayload)
+		return resp, nil
+	}
+
+	settleResult := i.server.ProcessSettlementAmount(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+		amount,
+	)
+
+	// Requests let through under the verify grace period are served even if
+	// settlement fails; they were flagged for reconciliation
+	if !settleResult.Success && result.Reconcile != nil {
+		return resp, nil
+	}
+
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+		return nil, connect.NewError(PaymentRequiredCode, fmt.Errorf("settlement failed: %s", errorReason))
+	}
+
+	for key, value := range settleResult.Headers {
+		resp.Header().Set(key, value)
+	}
+	identity.SetSettlement(settleResult)
+	return resp, nil
+}
+
+// paymentError converts a payment error response to a Connect error carrying
+// its headers (e.g. PAYMENT-REQUIRED) as metadata
+func paymentError(response *x402http.HTTPResponseInstructions) *connect.Error {
+	message := http.StatusText(response.Status)
+	if challenge, ok := response.Headers[headers.PaymentRequired]; ok {
+		if required, err := headers.DecodePaymentRequired(challenge, ""); err == nil && required.Error != "" {
+			message = required.Error
+		}
+	}
+
+	connectErr := connect.NewError(statusCode(response.Status), errors.New(message))
+	for key, value := range response.Headers {
+		if key != "Content-Type" {
+			connectErr.Meta().Set(key, value)
+		}
+	}
+	return connectErr
+}
+
+// statusCode maps the HTTP status of a payment error to a Connect code
+func statusCode(status int) connect.Code {
+	switch {
+	case status == http.StatusPaymentRequired || status == http.StatusForbidden:
+		return PaymentRequiredCode
+	case status == http.StatusTooManyRequests:
+		return connect.CodeResourceExhausted
+	case status == http.StatusServiceUnavailable:
+		return connect.CodeUnavailable
+	case status >= 500:
+		return connect.CodeInternal
+	default:
+		return connect.CodeInvalidArgument
+	}
+}
+
+// streamAdapter describes a streaming call to the HTTP server, for route matching
+type streamAdapter struct {
+	conn connect.StreamingHandlerConn
+}
+
+func (a *streamAdapter) GetHeader(name string) string { return a.conn.RequestHeader().Get(name) }
+func (a *streamAdapter) GetMethod() string            { return http.MethodPost }
+func (a *streamAdapter) GetPath() string              { return a.conn.Spec().Procedure }
+func (a *streamAdapter) GetURL() string               { return a.conn.Spec().Procedure }
+func (a *streamAdapter) GetAcceptHeader() string      { return "" }
+func (a *streamAdapter) GetUserAgent() string         { return a.conn.RequestHeader().Get("User-Agent") }
+func (a *streamAdapter) GetClientIP() string          { return x402http.RemoteIP(a.conn.Peer().Addr) }
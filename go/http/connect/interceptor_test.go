@@ -0,0 +1,103 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+func newTestService(t *testing.T) *httptest.Server {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	accepts := x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}
+	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+		"POST /weather.v1.Weather/Forecast": {Accepts: accepts, Description: "Forecast"},
+		"POST /weather.v1.Weather/Broken":   {Accepts: accepts},
+	}, x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	for _, procedure := range []string{"/weather.v1.Weather/Forecast", "/weather.v1.Weather/Broken", "/weather.v1.Weather/Today"} {
+		mux.Handle(procedure, connect.NewUnaryHandler(procedure, func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+			if req.Spec().Procedure == "/weather.v1.Weather/Broken" {
+				return nil, connect.NewError(connect.CodeInternal, errors.New("boom"))
+			}
+			payer := "free"
+			if identity, ok := x402http.PaymentIdentityFromContext(ctx); ok {
+				payer = identity.Payer
+			}
+			return connect.NewResponse(wrapperspb.String(payer)), nil
+		}, connect.WithInterceptors(NewInterceptor(server))))
+	}
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestInterceptorChargesProcedures(t *testing.T) {
+	ts := newTestService(t)
+	call := func(procedure string, signature string) (*connect.Response[wrapperspb.StringValue], error) {
+		client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](http.DefaultClient, ts.URL+procedure)
+		req := connect.NewRequest(wrapperspb.String("Paris"))
+		if signature != "" {
+			req.Header().Set(headers.PaymentSignature, signature)
+		}
+		return client.CallUnary(context.Background(), req)
+	}
+
+	if resp, err := call("/weather.v1.Weather/Today", ""); err != nil || resp.Msg.GetValue() != "free" {
+		t.Errorf("Expected an unpriced procedure to pass through, got %v, %v", resp, err)
+	}
+
+	// An unpaid call fails with the challenge in its error metadata
+	_, err := call("/weather.v1.Weather/Forecast", "")
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != PaymentRequiredCode {
+		t.Fatalf("Expected %v, got %v", PaymentRequiredCode, err)
+	}
+	challenge, err := headers.DecodePaymentRequired(connectErr.Meta().Get(headers.PaymentRequired), "")
+	if err != nil || len(challenge.Accepts) != 1 {
+		t.Fatalf("Expected a payment challenge, got %+v, %v", challenge, err)
+	}
+
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	payload, err := client.CreatePaymentPayload(context.Background(), challenge.Accepts[0], challenge.Resource, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+	signature, err := headers.EncodePaymentSignature(payload)
+	if err != nil {
+		t.Fatalf("Failed to encode payment: %v", err)
+	}
+
+	// A paid call reaches the handler and is settled
+	resp, err := call("/weather.v1.Weather/Forecast", signature)
+	if err != nil || resp.Msg.GetValue() != "~Alice" {
+		t.Fatalf("Expected the handler to see the payer, got %v, %v", resp, err)
+	}
+	if settled, err := headers.DecodePaymentResponse(resp.Header().Get(headers.PaymentResponse)); err != nil || !settled.Success {
+		t.Errorf("Expected a settlement header, got %+v, %v", settled, err)
+	}
+
+	// A failed handler is not settled
+	if _, err := call("/weather.v1.Weather/Broken", signature); connect.CodeOf(err) != connect.CodeInternal {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+}
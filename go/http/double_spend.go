@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// In-Flight Authorization Tracking
+// ============================================================================
+
+// inFlightKeyPrefix namespaces claimed payment authorizations in the store
+const inFlightKeyPrefix = "x402:authorization:"
+
+// defaultInFlightTTL bounds how long an authorization stays claimed when its
+// requirements set no timeout
+const defaultInFlightTTL = 5 * time.Minute
+
+// EnableDoubleSpendProtection serializes requests presenting the same signed
+// authorization (payer and nonce). The first claims it in the server's Store
+// before verification; concurrent or replayed requests with the same
+// authorization are rejected with a 402 before any handler runs, regardless
+// of how the facilitator treats them. Claims are released when verification,
+// the handler, or settlement fails, and otherwise expire after the
+// requirements' maxTimeoutSeconds.
+func (s *x402HTTPResourceServer) EnableDoubleSpendProtection() *x402HTTPResourceServer {
+	s.doubleSpendProtection = true
+	return s
+}
+
+// AuthorizationKey identifies the signed authorization in a payment: the payer
+// and nonce of EVM authorizations (EIP-3009 and Permit2), or a hash of the
+// payload for other schemes, such as signed Solana transactions
+func AuthorizationKey(payload types.PaymentPayload) string {
+	for _, field := range []string{"authorization", "permit2Authorization"} {
+		authorization, ok := payload.Payload[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		from, _ := authorization["from"].(string)
+		nonce, _ := authorization["nonce"].(string)
+		if from != "" && nonce != "" {
+			return payload.Accepted.Network + ":" + strings.ToLower(from) + ":" + strings.ToLower(nonce)
+		}
+	}
+
+	raw, err := json.Marshal(payload.Payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return payload.Accepted.Network + ":payload:" + hex.EncodeToString(sum[:])
+}
+
+// claimAuthorization claims the payment's authorization, returning false when
+// another request holds it. Store errors let requests through.
+func (s *x402HTTPResourceServer) claimAuthorization(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) bool {
+	if !s.doubleSpendProtection || s.store == nil {
+		return true
+	}
+	key := AuthorizationKey(payload)
+	if key == "" {
+		return true
+	}
+
+	ttl := time.Duration(requirements.MaxTimeoutSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultInFlightTTL
+	}
+	claims, err := s.store.Increment(ctx, inFlightKeyPrefix+key, 1, ttl)
+	if err != nil {
+		return true
+	}
+	return claims == 1
+}
+
+// ReleasePayment releases the authorization claimed for a verified payment
+// that was not settled, e.g. because the handler failed, so the client may
+// retry with it. It does nothing unless double-spend protection is enabled.
+func (s *x402HTTPResourceServer) ReleasePayment(ctx context.Context, payload types.PaymentPayload) {
+	if !s.doubleSpendProtection || s.store == nil {
+		return
+	}
+	key := AuthorizationKey(payload)
+	if key == "" {
+		return
+	}
+	// Delete rather than decrement: a decrement racing the claim's expiry
+	// would recreate the counter without a TTL and hold the authorization forever
+	_ = s.store.Delete(ctx, inFlightKeyPrefix+key)
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestAuthorizationKey(t *testing.T) {
+	eip3009 := types.PaymentPayload{
+		Accepted: types.PaymentRequirements{Network: "eip155:8453"},
+		Payload: map[string]interface{}{
+			"signature":     "0xsig",
+			"authorization": map[string]interface{}{"from": "0xABC", "nonce": "0xNONCE"},
+		},
+	}
+	if key := AuthorizationKey(eip3009); key != "eip155:8453:0xabc:0xnonce" {
+		t.Errorf("Expected payer and nonce key, got %s", key)
+	}
+
+	permit2 := types.PaymentPayload{
+		Accepted: types.PaymentRequirements{Network: "eip155:8453"},
+		Payload: map[string]interface{}{
+			"permit2Authorization": map[string]interface{}{"from": "0xabc", "nonce": "42"},
+		},
+	}
+	if key := AuthorizationKey(permit2); key != "eip155:8453:0xabc:42" {
+		t.Errorf("Expected Permit2 payer and nonce key, got %s", key)
+	}
+
+	svm := types.PaymentPayload{Payload: map[string]interface{}{"transaction": "AQID"}}
+	other := types.PaymentPayload{Payload: map[string]interface{}{"transaction": "BAUG"}}
+	if AuthorizationKey(svm) == AuthorizationKey(other) {
+		t.Error("Expected different payloads to get different keys")
+	}
+}
+
+func TestDoubleSpendProtection(t *testing.T) {
+	verifyErr := error(nil)
+	facilitator := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			if verifyErr != nil {
+				return nil, verifyErr
+			}
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "nonce_used"}, nil
+		},
+	}
+	server := newMonitorTestServer(t, facilitator, false).EnableDoubleSpendProtection()
+	ctx := context.Background()
+	process := func() HTTPProcessResult {
+		adapter := &mockHTTPAdapter{
+			method:  "GET",
+			path:    "/api",
+			url:     "http://example.com/api",
+			headers: map[string]string{},
+		}
+		reqCtx := HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}
+		adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+		return server.ProcessHTTPRequest(ctx, reqCtx, nil)
+	}
+
+	first := process()
+	if first.Type != ResultPaymentVerified {
+		t.Fatalf("Expected the first request to verify, got %s", first.Type)
+	}
+	second := process()
+	if second.Type != ResultPaymentError || second.Response.Status != 402 {
+		t.Fatalf("Expected the reused authorization to be rejected, got %s", second.Type)
+	}
+
+	// A failed settlement releases the authorization
+	if settled := server.ProcessSettlement(ctx, *first.PaymentPayload, *first.PaymentRequirements); settled.Success {
+		t.Fatal("Expected settlement to fail")
+	}
+	if result := process(); result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected the released authorization to verify again, got %s", result.Type)
+	}
+
+	// A failed verification releases it too
+	server.ReleasePayment(ctx, *first.PaymentPayload)
+	verifyErr = errors.New("invalid signature")
+	if result := process(); result.Type != ResultPaymentError {
+		t.Fatalf("Expected verification to fail, got %s", result.Type)
+	}
+	verifyErr = nil
+	if result := process(); result.Type != ResultPaymentVerified {
+		t.Errorf("Expected the authorization to be free after a failed verification, got %s", result.Type)
+	}
+
+	// Releasing more than was claimed never frees a held authorization twice
+	server.ReleasePayment(ctx, *first.PaymentPayload)
+	server.ReleasePayment(ctx, *first.PaymentPayload)
+	process()
+	if result := process(); result.Type != ResultPaymentError {
+		t.Errorf("Expected the reclaimed authorization to be held, got %s", result.Type)
+	}
+
+	// Releasing a claim that already expired leaves nothing behind that
+	// would hold the authorization without a TTL
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	server.SetStore(store)
+	if result := process(); result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected the authorization to verify, got %s", result.Type)
+	}
+	now = now.Add(24 * time.Hour)
+	server.ReleasePayment(ctx, *first.PaymentPayload)
+	if result := process(); result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected the authorization to verify after a late release, got %s", result.Type)
+	}
+	now = now.Add(24 * time.Hour)
+	if result := process(); result.Type != ResultPaymentVerified {
+		t.Errorf("Expected the new claim to expire, got %s", result.Type)
+	}
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"github.com/coinbase/x402/go/extensions"
+)
+
+// ============================================================================
+// Extension Registry
+// ============================================================================
+
+// SetExtensionRegistry enables versioned extensions. Route declarations are
+// enriched by the registered hooks, every 402 advertises the registered set
+// under extensions.SupportedKey, and payments whose extensions fail validation
+// are rejected with a 402 before verification.
+func (s *x402HTTPResourceServer) SetExtensionRegistry(registry *extensions.Registry) *x402HTTPResourceServer {
+	s.extensionRegistry = registry
+	return s
+}
+
+// enrichExtensions applies the registry to a route's extension declarations
+func (s *x402HTTPResourceServer) enrichExtensions(declarations map[string]interface{}, reqCtx HTTPRequestContext) map[string]interface{} {
+	if s.extensionRegistry == nil {
+		return declarations
+	}
+	return s.extensionRegistry.Enrich(declarations, reqCtx)
+}
+
+// validateExtensions checks the extensions presented in a payment payload
+func (s *x402HTTPResourceServer) validateExtensions(presented map[string]interface{}) error {
+	if s.extensionRegistry == nil {
+		return nil
+	}
+	return s.extensionRegistry.Validate(presented)
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/extensions"
+	"github.com/coinbase/x402/go/types"
+)
+
+func newExtensionRegistryServer(t *testing.T) *x402HTTPResourceServer {
+	t.Helper()
+	registry := extensions.NewRegistry().MustRegister(extensions.Extension{
+		Key:     "quotes",
+		Version: "1.0.0",
+		Validate: func(value interface{}) error {
+			quote, _ := value.(map[string]interface{})
+			if _, ok := quote["id"].(string); !ok {
+				return errors.New("quote id is required")
+			}
+			return nil
+		},
+		Enrich: func(declaration interface{}, transportContext interface{}) interface{} {
+			reqCtx := transportContext.(HTTPRequestContext)
+			return map[string]interface{}{"id": declaration.(map[string]interface{})["id"], "path": reqCtx.Path}
+		},
+	})
+
+	mockClient := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{
+			"GET /quoted": {
+				Accepts:    PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+				Extensions: map[string]interface{}{"quotes": map[string]interface{}{"id": "q1"}},
+			},
+		},
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).SetExtensionRegistry(registry)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func TestExtensionRegistryEnrichesAndAdvertises(t *testing.T) {
+	server := newExtensionRegistryServer(t)
+
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+		Adapter: &mockHTTPAdapter{method: "GET", path: "/quoted", url: "http://example.com/quoted"},
+		Path:    "/quoted",
+		Method:  "GET",
+	}, nil)
+	if result.Response == nil || result.Response.Status != 402 {
+		t.Fatalf("Expected 402, got %+v", result.Response)
+	}
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+
+	quote, _ := required.Extensions["quotes"].(map[string]interface{})
+	if quote["id"] != "q1" || quote["path"] != "/quoted" {
+		t.Errorf("Expected enriched quote declaration, got %v", required.Extensions["quotes"])
+	}
+	supported, _ := required.Extensions[extensions.SupportedKey].(map[string]interface{})
+	if supported["quotes"] != "1.0.0" {
+		t.Errorf("Expected quotes 1.0.0 to be advertised, got %v", required.Extensions[extensions.SupportedKey])
+	}
+}
+
+func TestExtensionRegistryValidatesPresentedExtensions(t *testing.T) {
+	server := newExtensionRegistryServer(t)
+	accepted := offeredRequirements(t, server, HTTPRequestContext{
+		Adapter: &mockHTTPAdapter{method: "GET", path: "/quoted", url: "http://example.com/quoted"},
+		Path:    "/quoted",
+		Method:  "GET",
+	})
+	request := func(presented map[string]interface{}) HTTPProcessResult {
+		payloadJSON, _ := json.Marshal(types.PaymentPayload{
+			X402Version: 2,
+			Payload:     map[string]interface{}{"sig": "test"},
+			Accepted:    accepted,
+			Extensions:  presented,
+		})
+		return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+			Adapter: &mockHTTPAdapter{
+				method:  "GET",
+				path:    "/quoted",
+				url:     "http://example.com/quoted",
+				headers: map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadJSON)},
+			},
+			Path:   "/quoted",
+			Method: "GET",
+		}, nil)
+	}
+
+	if result := request(map[string]interface{}{"quotes": map[string]interface{}{"id": "q1"}, "unknown": true}); result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected valid extensions to verify, got %s (%+v)", result.Type, result.Response)
+	}
+
+	result := request(map[string]interface{}{"quotes": map[string]interface{}{}})
+	if result.Type != ResultPaymentError || result.Response.Status != 402 {
+		t.Fatalf("Expected 402 for an invalid quote, got %s (%+v)", result.Type, result.Response)
+	}
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	if !strings.Contains(required.Error, "quote id is required") {
+		t.Errorf("Expected the validation error in the 402, got %q", required.Error)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	x402 "github.com/coinbase/x402/go"
@@ -17,6 +19,31 @@ import (
 // HTTP Facilitator Client
 // ============================================================================
 
+// FacilitatorUnavailableError reports that the facilitator could not be
+// reached or answered with a server error, so it never judged the payment.
+// Custom FacilitatorClients return it for the verify grace period to apply.
+type FacilitatorUnavailableError struct {
+	// Operation is the facilitator endpoint called, e.g. "verify"
+	Operation string
+
+	// StatusCode is the facilitator's response status, or 0 when the request failed
+	StatusCode int
+
+	// Err describes the failure
+	Err error
+}
+
+func (e *FacilitatorUnavailableError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s request failed: %v", e.Operation, e.Err)
+	}
+	return fmt.Sprintf("facilitator %s failed (%d): %v", e.Operation, e.StatusCode, e.Err)
+}
+
+func (e *FacilitatorUnavailableError) Unwrap() error {
+	return e.Err
+}
+
 // HTTPFacilitatorClient communicates with remote facilitator services over HTTP
 // Implements FacilitatorClient interface (supports both V1 and V2)
 type HTTPFacilitatorClient struct {
@@ -24,6 +51,7 @@ type HTTPFacilitatorClient struct {
 	httpClient   *http.Client
 	authProvider AuthProvider
 	identifier   string
+	profile      WireProfile
 }
 
 // AuthProvider generates authentication headers for facilitator requests
@@ -37,6 +65,12 @@ type AuthHeaders struct {
 	Verify    map[string]string
 	Settle    map[string]string
 	Supported map[string]string
+
+	// Simulate headers for /simulate (optional, defaults to the Verify headers)
+	Simulate map[string]string
+
+	// Quote headers for /quote (optional, defaults to the Supported headers)
+	Quote map[string]string
 }
 
 // FacilitatorConfig configures the HTTP facilitator client
@@ -55,6 +89,10 @@ type FacilitatorConfig struct {
 
 	// Identifier for this facilitator (optional)
 	Identifier string
+
+	// Profile adapts request and response bodies for facilitators that
+	// diverge from the spec (optional, defaults to WireProfileX402Org)
+	Profile WireProfile
 }
 
 // DefaultFacilitatorURL is the default public facilitator
@@ -92,9 +130,22 @@ func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient
 		httpClient:   httpClient,
 		authProvider: config.AuthProvider,
 		identifier:   identifier,
+		profile:      config.Profile,
 	}
 }
 
+// Identifier returns the name used to select this client per route
+// (FacilitatorConfig.Identifier, defaulting to the URL)
+func (c *HTTPFacilitatorClient) Identifier() string {
+	return c.identifier
+}
+
+// HeaderNames returns the payment header names of the client's profile,
+// defaulting to the current names
+func (c *HTTPFacilitatorClient) HeaderNames() HeaderNames {
+	return c.profile.Headers.withDefaults()
+}
+
 // ============================================================================
 // FacilitatorClient Implementation (Network Boundary - uses bytes)
 // ============================================================================
@@ -118,13 +169,114 @@ func (c *HTTPFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte,
 		return nil, fmt.Errorf("failed to detect version: %w", err)
 	}
 
-	return c.settleHTTP(ctx, version, payloadBytes, requirementsBytes)
+	return c.settleHTTP(ctx, version, payloadBytes, requirementsBytes, "")
+}
+
+// SettlePartial settles a V2 payment for amountToSettle (atomic units) instead
+// of the authorized maximum, sent as amountToSettle in the /settle request
+func (c *HTTPFacilitatorClient) SettlePartial(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*x402.SettleResponse, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect version: %w", err)
+	}
+	if version != 2 {
+		return nil, fmt.Errorf("partial settlement is not supported for version %d", version)
+	}
+
+	return c.settleHTTP(ctx, version, payloadBytes, requirementsBytes, amountToSettle)
+}
+
+// Simulate asks the facilitator to verify a V2 payment and simulate its
+// settlement, returning the projected cost without settling (POST /simulate)
+func (c *HTTPFacilitatorClient) Simulate(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SimulateResponse, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect version: %w", err)
+	}
+
+	return c.simulateHTTP(ctx, version, payloadBytes, requirementsBytes)
+}
+
+// QuoteFee asks the facilitator for its current fee on a payment (POST /quote)
+func (c *HTTPFacilitatorClient) QuoteFee(ctx context.Context, request x402.FeeQuoteRequest) (*x402.FeeQuote, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quote request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+"/quote", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quote request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Add auth headers if available
+	if c.authProvider != nil {
+		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth headers: %w", err)
+		}
+		headers := authHeaders.Quote
+		if headers == nil {
+			headers = authHeaders.Supported
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("quote request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// For non-200 responses, return the facilitator's error if it sent one
+	if resp.StatusCode != http.StatusOK {
+		var paymentErr x402.PaymentError
+		if json.Unmarshal(responseBody, &paymentErr) == nil && paymentErr.Code != "" {
+			return nil, &paymentErr
+		}
+		return nil, fmt.Errorf("facilitator quote failed (%d): %s", resp.StatusCode, string(responseBody))
+	}
+
+	var quote x402.FeeQuote
+	if err := json.Unmarshal(responseBody, &quote); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal quote response: %w", err)
+	}
+	return &quote, nil
 }
 
 // GetSupported gets supported payment kinds (shared by both V1 and V2)
 func (c *HTTPFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return c.supportedHTTP(ctx, nil)
+}
+
+// QuerySupported gets the supported payment kinds matching the query. The
+// filters and page are sent as query parameters, so the facilitator must
+// support them; see ParseSupportedQuery.
+func (c *HTTPFacilitatorClient) QuerySupported(ctx context.Context, query x402.SupportedQuery) (x402.SupportedResponse, error) {
+	return c.supportedHTTP(ctx, EncodeSupportedQuery(query))
+}
+
+// ============================================================================
+// Internal HTTP Methods (shared by V1 and V2)
+// ============================================================================
+
+func (c *HTTPFacilitatorClient) supportedHTTP(ctx context.Context, query url.Values) (x402.SupportedResponse, error) {
+	endpoint := c.url + "/supported"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", c.url+"/supported", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return x402.SupportedResponse{}, fmt.Errorf("failed to create supported request: %w", err)
 	}
@@ -164,10 +316,6 @@ func (c *HTTPFacilitatorClient) GetSupported(ctx context.Context) (x402.Supporte
 	return supportedResponse, nil
 }
 
-// ============================================================================
-// Internal HTTP Methods (shared by V1 and V2)
-// ============================================================================
-
 func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.VerifyResponse, error) {
 	// Build request body
 	var payloadMap, requirementsMap map[string]interface{}
@@ -178,11 +326,7 @@ func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, pay
 		return nil, fmt.Errorf("failed to unmarshal requirements: %w", err)
 	}
 
-	requestBody := map[string]interface{}{
-		"x402Version":         version,
-		"paymentPayload":      payloadMap,
-		"paymentRequirements": requirementsMap,
-	}
+	requestBody := c.profile.requestBody(version, payloadBytes, payloadMap, requirementsMap)
 
 	body, err := json.Marshal(requestBody)
 	if err != nil {
@@ -211,17 +355,21 @@ func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, pay
 	// Make request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("verify request failed: %w", err)
+		return nil, &FacilitatorUnavailableError{Operation: "verify", Err: err}
 	}
 	defer resp.Body.Close()
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, &FacilitatorUnavailableError{Operation: "verify", Err: fmt.Errorf("failed to read response body: %w", err)}
 	}
+	responseBody = c.profile.responseBody(responseBody)
 
 	var verifyResponse x402.VerifyResponse
 	if err := json.Unmarshal(responseBody, &verifyResponse); err != nil {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, &FacilitatorUnavailableError{Operation: "verify", StatusCode: resp.StatusCode, Err: errors.New(string(responseBody))}
+		}
 		return nil, x402.NewVerifyError(
 			x402.ErrInvalidResponse,
 			"",
@@ -238,13 +386,89 @@ func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, pay
 				verifyResponse.InvalidMessage,
 			)
 		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, &FacilitatorUnavailableError{Operation: "verify", StatusCode: resp.StatusCode, Err: errors.New(string(responseBody))}
+		}
 		return nil, fmt.Errorf("facilitator verify failed (%d): %s", resp.StatusCode, string(responseBody))
 	}
 
 	return &verifyResponse, nil
 }
 
-func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.SettleResponse, error) {
+func (c *HTTPFacilitatorClient) simulateHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte) (*x402.SimulateResponse, error) {
+	// Build request body
+	var payloadMap, requirementsMap map[string]interface{}
+	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	if err := json.Unmarshal(requirementsBytes, &requirementsMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal requirements: %w", err)
+	}
+
+	requestBody := c.profile.requestBody(version, payloadBytes, payloadMap, requirementsMap)
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal simulate request: %w", err)
+	}
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+"/simulate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create simulate request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Add auth headers if available
+	if c.authProvider != nil {
+		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth headers: %w", err)
+		}
+		headers := authHeaders.Simulate
+		if headers == nil {
+			headers = authHeaders.Verify
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	// Make request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("simulate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	responseBody = c.profile.responseBody(responseBody)
+
+	var simulateResponse x402.SimulateResponse
+	if err := json.Unmarshal(responseBody, &simulateResponse); err != nil {
+		return nil, fmt.Errorf("facilitator simulate failed (%d): %s", resp.StatusCode, string(responseBody))
+	}
+
+	// For non-200 responses, return an error with the details from the response
+	if resp.StatusCode != http.StatusOK {
+		if simulateResponse.InvalidReason != "" {
+			return nil, x402.NewVerifyError(
+				simulateResponse.InvalidReason,
+				simulateResponse.Payer,
+				simulateResponse.InvalidMessage,
+			)
+		}
+		return nil, fmt.Errorf("facilitator simulate failed (%d): %s", resp.StatusCode, string(responseBody))
+	}
+
+	return &simulateResponse, nil
+}
+
+func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, payloadBytes, requirementsBytes []byte, amountToSettle string) (*x402.SettleResponse, error) {
 	// Build request body
 	var payloadMap, requirementsMap map[string]interface{}
 	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
@@ -254,10 +478,9 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 		return nil, fmt.Errorf("failed to unmarshal requirements: %w", err)
 	}
 
-	requestBody := map[string]interface{}{
-		"x402Version":         version,
-		"paymentPayload":      payloadMap,
-		"paymentRequirements": requirementsMap,
+	requestBody := c.profile.requestBody(version, payloadBytes, payloadMap, requirementsMap)
+	if amountToSettle != "" {
+		requestBody[c.profile.amountToSettleField()] = amountToSettle
 	}
 
 	body, err := json.Marshal(requestBody)
@@ -295,6 +518,7 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	responseBody = c.profile.responseBody(responseBody)
 
 	var settleResponse x402.SettleResponse
 	if err := json.Unmarshal(responseBody, &settleResponse); err != nil {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	x402 "github.com/coinbase/x402/go"
@@ -259,6 +260,41 @@ func TestHTTPFacilitatorClientVerifyInvalidResponse(t *testing.T) {
 	}
 }
 
+func TestHTTPFacilitatorClientVerifyUnavailable(t *testing.T) {
+	ctx := context.Background()
+	requirements := x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(x402.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"sig": "test"}})
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	status := http.StatusBadGateway
+	body := "<html>bad gateway</html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+
+	var unavailable *FacilitatorUnavailableError
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); !errors.As(err, &unavailable) || unavailable.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected FacilitatorUnavailableError for a 502, got %T (%v)", err, err)
+	}
+
+	status, body = http.StatusServiceUnavailable, `{"isValid":false}`
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); !errors.As(err, &unavailable) || unavailable.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected FacilitatorUnavailableError for a 503, got %T (%v)", err, err)
+	}
+
+	status, body = http.StatusBadRequest, `{"isValid":false}`
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err == nil || errors.As(err, &unavailable) {
+		t.Errorf("Expected a 400 not to be reported as unavailable, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); !errors.As(err, &unavailable) || unavailable.StatusCode != 0 {
+		t.Errorf("Expected FacilitatorUnavailableError for an unreachable facilitator, got %T (%v)", err, err)
+	}
+}
+
 func TestHTTPFacilitatorClientSettle(t *testing.T) {
 	ctx := context.Background()
 
@@ -315,6 +351,156 @@ func TestHTTPFacilitatorClientSettle(t *testing.T) {
 	}
 }
 
+func TestHTTPFacilitatorClientSettlePartial(t *testing.T) {
+	ctx := context.Background()
+
+	var amountToSettle interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		amountToSettle = body["amountToSettle"]
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xpartial", Network: "eip155:1"})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	requirements := x402.PaymentRequirements{Scheme: "upto", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(x402.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	if _, err := client.SettlePartial(ctx, payloadBytes, requirementsBytes, "250000"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amountToSettle != "250000" {
+		t.Errorf("Expected amountToSettle 250000 in the request, got %v", amountToSettle)
+	}
+
+	if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amountToSettle != nil {
+		t.Errorf("Expected no amountToSettle for a full settlement, got %v", amountToSettle)
+	}
+}
+
+func TestHTTPFacilitatorClientSimulate(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simulate" {
+			t.Errorf("Expected path /simulate, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		if body["paymentRequirements"].(map[string]interface{})["amount"] != "1000000" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(x402.SimulateResponse{
+				IsValid:       false,
+				InvalidReason: "invalid_exact_evm_gas_limit_exceeded",
+				Payer:         "0xpayer",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(x402.SimulateResponse{
+			IsValid:  true,
+			Payer:    "0xpayer",
+			Network:  "eip155:1",
+			GasUsed:  65000,
+			GasPrice: "1000000",
+			Fee:      "65000000000",
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL: server.URL,
+	})
+
+	requirements := x402.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{},
+	}
+
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	response, err := client.Simulate(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.IsValid || response.GasUsed != 65000 || response.Fee != "65000000000" {
+		t.Errorf("Unexpected simulation response: %+v", response)
+	}
+
+	requirements.Amount = "2000000"
+	requirementsBytes, _ = json.Marshal(requirements)
+	_, err = client.Simulate(ctx, payloadBytes, requirementsBytes)
+	var verifyErr *x402.VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("Expected VerifyError, got: %T (%v)", err, err)
+	}
+	if verifyErr.InvalidReason != "invalid_exact_evm_gas_limit_exceeded" || verifyErr.Payer != "0xpayer" {
+		t.Errorf("Unexpected VerifyError: %+v", verifyErr)
+	}
+}
+
+func TestHTTPFacilitatorClientQuoteFee(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/quote" {
+			t.Errorf("Expected path /quote, got %s", r.URL.Path)
+		}
+
+		var request x402.FeeQuoteRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		if request.Network != "eip155:1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(x402.NewPaymentError(x402.ErrNoFacilitatorForNetwork, "unsupported network", nil))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(x402.FeeQuote{
+			Scheme:  request.Scheme,
+			Network: request.Network,
+			Amount:  request.Amount,
+			Fee:     "5000",
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+		URL: server.URL,
+	})
+
+	quote, err := client.QuoteFee(ctx, x402.FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:1", Amount: "1000000"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quote.Fee != "5000" || quote.Amount != "1000000" {
+		t.Errorf("Unexpected quote: %+v", quote)
+	}
+
+	_, err = client.QuoteFee(ctx, x402.FeeQuoteRequest{X402Version: 2, Scheme: "exact", Network: "eip155:8453", Amount: "1000000"})
+	var paymentErr *x402.PaymentError
+	if !errors.As(err, &paymentErr) || paymentErr.Code != x402.ErrNoFacilitatorForNetwork {
+		t.Errorf("Expected PaymentError %s, got %v", x402.ErrNoFacilitatorForNetwork, err)
+	}
+}
+
 func TestHTTPFacilitatorClientGetSupported(t *testing.T) {
 	ctx := context.Background()
 
@@ -371,6 +557,54 @@ func TestHTTPFacilitatorClientGetSupported(t *testing.T) {
 	}
 }
 
+func TestHTTPFacilitatorClientQuerySupported(t *testing.T) {
+	ctx := context.Background()
+
+	var received x402.SupportedQuery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/supported" {
+			t.Errorf("Expected path /supported, got %s", r.URL.Path)
+		}
+		query, err := ParseSupportedQuery(r.URL.Query())
+		if err != nil {
+			t.Errorf("Unexpected query error: %v", err)
+		}
+		received = query
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(x402.SupportedResponse{
+			Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}},
+			Extensions: []string{},
+			Signers:    make(map[string][]string),
+			NextCursor: "25",
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	query := x402.SupportedQuery{Scheme: "exact", Network: "eip155:*", X402Version: 2, Limit: 25, Cursor: "0"}
+
+	response, err := client.QuerySupported(ctx, query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received != query {
+		t.Errorf("Expected facilitator to receive %+v, got %+v", query, received)
+	}
+	if response.NextCursor != "25" {
+		t.Errorf("Expected next cursor 25, got %q", response.NextCursor)
+	}
+}
+
+func TestParseSupportedQueryRejectsInvalidNumbers(t *testing.T) {
+	for _, raw := range []string{"x402Version=two", "limit=-1", "limit=ten"} {
+		values, _ := url.ParseQuery(raw)
+		if _, err := ParseSupportedQuery(values); err == nil {
+			t.Errorf("Expected error for %q", raw)
+		}
+	}
+}
+
 func TestHTTPFacilitatorClientWithAuth(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// namedFacilitator is a mock facilitator client selectable by identifier
+type namedFacilitator struct {
+	*mockFacilitatorClient
+	name string
+}
+
+func (n *namedFacilitator) Identifier() string {
+	return n.name
+}
+
+func TestRouteFacilitatorOverride(t *testing.T) {
+	var calls []string
+	option := PaymentOption{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}
+	optionOverride := option
+	optionOverride.Facilitator = "backup"
+
+	routes := RoutesConfig{
+		"GET /default": {Accepts: PaymentOptions{option}},
+		"GET /route":   {Accepts: PaymentOptions{option}, Facilitator: "backup"},
+		"GET /option":  {Accepts: PaymentOptions{optionOverride}},
+		"GET /unknown": {Accepts: PaymentOptions{option}, Facilitator: "missing"},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&namedFacilitator{countingFacilitator("primary", &calls), "primary"}),
+		x402.WithFacilitatorClient(&namedFacilitator{countingFacilitator("backup", &calls), "backup"}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	pay := func(path string) HTTPProcessResult {
+		adapter := &mockHTTPAdapter{
+			method:  "GET",
+			path:    path,
+			url:     "http://example.com" + path,
+			headers: map[string]string{},
+		}
+		reqCtx := HTTPRequestContext{Adapter: adapter, Path: path, Method: "GET"}
+		adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+		return server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/default", "primary"},
+		{"/route", "backup"},
+		{"/option", "backup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			calls = nil
+			result := pay(tt.path)
+			if result.Type != ResultPaymentVerified {
+				t.Fatalf("Expected verified payment, got %s (%+v)", result.Type, result.Response)
+			}
+			ctx := x402.ContextWithFacilitator(context.Background(), result.Facilitator)
+			if settle := server.ProcessSettlement(ctx, *result.PaymentPayload, *result.PaymentRequirements); !settle.Success {
+				t.Fatalf("Expected settlement, got %s", settle.ErrorReason)
+			}
+			if len(calls) != 2 || calls[0] != tt.want+":verify" || calls[1] != tt.want+":settle" {
+				t.Errorf("Expected %s to verify and settle, got %v", tt.want, calls)
+			}
+		})
+	}
+
+	unknown := &mockHTTPAdapter{
+		method:  "GET",
+		path:    "/unknown",
+		url:     "http://example.com/unknown",
+		headers: map[string]string{"PAYMENT-SIGNATURE": acceptingPaymentHeader(x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1"})},
+	}
+	if result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: unknown, Path: "/unknown", Method: "GET"}, nil); result.Response == nil || result.Response.Status != 500 {
+		t.Errorf("Expected 500 for an unregistered facilitator, got %+v", result)
+	}
+}
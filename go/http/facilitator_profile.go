@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// ============================================================================
+// Facilitator Wire Profiles
+// ============================================================================
+
+// WireProfile adapts the facilitator client's request and response bodies to
+// facilitators whose field names diverge from the spec. Empty fields keep the
+// spec's names.
+type WireProfile struct {
+	// Name identifies the profile (see LookupWireProfile)
+	Name string
+
+	// VersionField names the x402Version field of request bodies
+	VersionField string
+
+	// PayloadField names the payment payload object (default "paymentPayload").
+	// Set it to "-" to send only PayloadHeaderField.
+	PayloadField string
+
+	// PayloadHeaderField also sends the payload base64-encoded, as in an
+	// X-PAYMENT header, under this name (e.g. "paymentHeader")
+	PayloadHeaderField string
+
+	// RequirementsField names the payment requirements (default "paymentRequirements")
+	RequirementsField string
+
+	// AmountToSettleField names the partial settlement amount (default "amountToSettle")
+	AmountToSettleField string
+
+	// ResponseFields renames top-level fields of verify, settle and simulate
+	// responses to the spec's, e.g. {"is_valid": "isValid"}. Field names that
+	// differ from the spec's only in case need no mapping.
+	ResponseFields map[string]string
+
+	// Headers names the payment headers of resource servers that settle with
+	// this facilitator, for deployments still on older names (see
+	// HTTPFacilitatorClient.HeaderNames and SetHeaderNames)
+	Headers HeaderNames
+}
+
+var (
+	// WireProfileX402Org is the spec's wire format, spoken by the x402.org
+	// facilitator and the default
+	WireProfileX402Org = WireProfile{Name: "x402.org"}
+
+	// WireProfileCoinbase is the Coinbase (CDP) facilitator's: the spec's
+	// fields, plus the payload as paymentHeader, which its earliest API versions read
+	WireProfileCoinbase = WireProfile{Name: "coinbase", PayloadHeaderField: "paymentHeader"}
+)
+
+// LookupWireProfile returns a built-in profile by name ("x402.org" or "coinbase")
+func LookupWireProfile(name string) (WireProfile, bool) {
+	for _, profile := range []WireProfile{WireProfileX402Org, WireProfileCoinbase} {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return WireProfile{}, false
+}
+
+// requestBody builds a verify, settle or simulate request body
+func (p WireProfile) requestBody(version int, payloadBytes []byte, payload, requirements map[string]interface{}) map[string]interface{} {
+	body := map[string]interface{}{
+		orDefault(p.VersionField, "x402Version"):              version,
+		orDefault(p.RequirementsField, "paymentRequirements"): requirements,
+	}
+	if p.PayloadField != "-" {
+		body[orDefault(p.PayloadField, "paymentPayload")] = payload
+	}
+	if p.PayloadHeaderField != "" {
+		body[p.PayloadHeaderField] = base64.StdEncoding.EncodeToString(payloadBytes)
+	}
+	return body
+}
+
+// amountToSettleField names the partial settlement amount
+func (p WireProfile) amountToSettleField() string {
+	return orDefault(p.AmountToSettleField, "amountToSettle")
+}
+
+// responseBody renames a response's fields to the spec's. Bodies that are not
+// JSON objects are returned as is.
+func (p WireProfile) responseBody(body []byte) []byte {
+	if len(p.ResponseFields) == 0 {
+		return body
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	for from, to := range p.ResponseFields {
+		if value, ok := fields[from]; ok {
+			delete(fields, from)
+			fields[to] = value
+		}
+	}
+	renamed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return renamed
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestFacilitatorWireProfiles(t *testing.T) {
+	requirements := x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(x402.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	// serve records the request body and answers with a snake_case verify response
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{"is_valid":true,"Payer":"0xpayer"}`))
+	}))
+	defer server.Close()
+
+	t.Run("x402.org sends the spec's fields", func(t *testing.T) {
+		profile, ok := LookupWireProfile("x402.org")
+		if !ok {
+			t.Fatal("Expected the x402.org profile")
+		}
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Profile: profile})
+		if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := received["paymentPayload"]; !ok || received["paymentHeader"] != nil {
+			t.Errorf("Expected only paymentPayload, got %v", received)
+		}
+	})
+
+	t.Run("coinbase also sends paymentHeader", func(t *testing.T) {
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Profile: WireProfileCoinbase})
+		if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		header, _ := received["paymentHeader"].(string)
+		decoded, _ := base64.StdEncoding.DecodeString(header)
+		if _, ok := received["paymentPayload"]; !ok || string(decoded) != string(payloadBytes) {
+			t.Errorf("Expected paymentPayload and the base64 paymentHeader, got %v", received)
+		}
+	})
+
+	t.Run("custom mapping", func(t *testing.T) {
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Profile: WireProfile{
+			Name:                "snake",
+			VersionField:        "x402_version",
+			PayloadField:        "-",
+			PayloadHeaderField:  "payment_header",
+			RequirementsField:   "payment_requirements",
+			AmountToSettleField: "amount_to_settle",
+			ResponseFields:      map[string]string{"is_valid": "isValid"},
+		}})
+		response, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !response.IsValid || response.Payer != "0xpayer" {
+			t.Errorf("Expected the renamed response fields to be read, got %+v", response)
+		}
+		for _, field := range []string{"x402_version", "payment_header", "payment_requirements"} {
+			if _, ok := received[field]; !ok {
+				t.Errorf("Expected %s in the request, got %v", field, received)
+			}
+		}
+		if _, ok := received["paymentPayload"]; ok {
+			t.Errorf("Expected no paymentPayload, got %v", received)
+		}
+
+		_, _ = client.SettlePartial(context.Background(), payloadBytes, requirementsBytes, "500")
+		if received["amount_to_settle"] != "500" {
+			t.Errorf("Expected amount_to_settle, got %v", received)
+		}
+	})
+
+	if _, ok := LookupWireProfile("unknown"); ok {
+		t.Error("Expected no profile for an unknown name")
+	}
+}
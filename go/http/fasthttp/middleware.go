@@ -0,0 +1,390 @@
+// Package fasthttp provides x402 payment middleware for fasthttp servers.
+//
+// The middleware wraps a fasthttp.RequestHandler. Requests are read through
+// FastHTTPAdapter without building net/http requests, and the payment header
+// is decoded in place from the request buffer. fasthttp buffers the response
+// until the handler returns, so settlement happens after the handler and the
+// settlement headers are added before anything is sent.
+//
+//	server := x402http.Newx402HTTPResourceServer(routes, x402.WithFacilitatorClient(facilitator), ...)
+//	_ = server.Initialize(ctx)
+//
+//	handler := fasthttpmw.Middleware(server, nil)(func(ctx *fasthttp.RequestCtx) {
+//	    identity, _ := fasthttpmw.GetPaymentIdentity(ctx)
+//	    ctx.SetBodyString("paid by " + identity.Payer)
+//	})
+//	fasthttp.ListenAndServe(":8080", handler)
+package fasthttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/valyala/fasthttp"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// ============================================================================
+// Request Adapter
+// ============================================================================
+
+// FastHTTPAdapter implements HTTPAdapter for fasthttp requests
+type FastHTTPAdapter struct {
+	ctx *fasthttp.RequestCtx
+}
+
+// NewFastHTTPAdapter creates a new adapter
+func NewFastHTTPAdapter(ctx *fasthttp.RequestCtx) *FastHTTPAdapter {
+	return &FastHTTPAdapter{ctx: ctx}
+}
+
+// GetHeader gets a request header. The PAYMENT-SIGNATURE header, which
+// carries the whole signed payload, is returned without copying: the string
+// shares the request's buffer and is only valid until the handler returns.
+// The resource server decodes it right away.
+func (a *FastHTTPAdapter) GetHeader(name string) string {
+	value := a.ctx.Request.Header.Peek(name)
+	if strings.EqualFold(name, "PAYMENT-SIGNATURE") {
+		return unsafe.String(unsafe.SliceData(value), len(value))
+	}
+	return string(value)
+}
+
+// GetMethod gets the HTTP method
+func (a *FastHTTPAdapter) GetMethod() string {
+	return string(a.ctx.Method())
+}
+
+// GetPath gets the request path
+func (a *FastHTTPAdapter) GetPath() string {
+	return string(a.ctx.Path())
+}
+
+// GetURL gets the full request URL
+func (a *FastHTTPAdapter) GetURL() string {
+	scheme := "http"
+	if a.ctx.IsTLS() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, a.ctx.Host(), a.ctx.Path())
+}
+
+// GetAcceptHeader gets the Accept header
+func (a *FastHTTPAdapter) GetAcceptHeader() string {
+	return string(a.ctx.Request.Header.Peek("Accept"))
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *FastHTTPAdapter) GetUserAgent() string {
+	return string(a.ctx.UserAgent())
+}
+
+// GetClientIP gets the address of the connection's peer
+func (a *FastHTTPAdapter) GetClientIP() string {
+	return a.ctx.RemoteIP().String()
+}
+
+// GetBody returns the request body, up to x402http.MaxRequestBodySize.
+// fasthttp reads bodies in full, so it stays available to the handler.
+func (a *FastHTTPAdapter) GetBody() ([]byte, error) {
+	body := a.ctx.PostBody()
+	if len(body) > x402http.MaxRequestBodySize {
+		return nil, x402http.ErrRequestBodyTooLarge
+	}
+	return body, nil
+}
+
+// ============================================================================
+// Payer Identity
+// ============================================================================
+
+// PaymentIdentityKey is the user value key holding the
+// *x402http.PaymentIdentity of a verified request
+const PaymentIdentityKey = "x402.paymentIdentity"
+
+// GetPaymentIdentity returns who paid for the request, for handlers behind
+// the middleware. The transaction is filled in once the payment settles.
+func GetPaymentIdentity(ctx *fasthttp.RequestCtx) (*x402http.PaymentIdentity, bool) {
+	identity, ok := ctx.UserValue(PaymentIdentityKey).(*x402http.PaymentIdentity)
+	return identity, ok
+}
+
+// ReportItemCount records how many items the handler returned, for routes
+// priced per item (see x402http.ReportItemCount)
+func ReportItemCount(ctx *fasthttp.RequestCtx, count int) {
+	if identity, ok := GetPaymentIdentity(ctx); ok {
+		x402http.ReportItemCount(x402http.ContextWithPaymentIdentity(ctx, identity), count)
+	}
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware
+type MiddlewareConfig struct {
+	// Paywall configuration
+	PaywallConfig *x402http.PaywallConfig
+
+	// Sync with facilitator on start (PaymentMiddleware only)
+	SyncFacilitatorOnStart bool
+
+	// Custom error handler, called when settlement fails
+	ErrorHandler func(*fasthttp.RequestCtx, error)
+
+	// Custom settlement handler, called before the response is sent
+	SettlementHandler func(*fasthttp.RequestCtx, *x402.SettleResponse)
+
+	// Context timeout for payment operations
+	Timeout time.Duration
+
+	// IdentityHeaders lists the X-402-* headers injected into verified requests
+	// (X-402-Tx is set on the response after settlement). Nil disables injection.
+	IdentityHeaders []string
+}
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption func(*MiddlewareConfig)
+
+// WithPaywallConfig sets the paywall configuration
+func WithPaywallConfig(config *x402http.PaywallConfig) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallConfig = config
+	}
+}
+
+// WithSyncFacilitatorOnStart sets whether to sync with facilitator on startup
+func WithSyncFacilitatorOnStart(sync bool) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SyncFacilitatorOnStart = sync
+	}
+}
+
+// WithErrorHandler sets a custom error handler
+func WithErrorHandler(handler func(*fasthttp.RequestCtx, error)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithSettlementHandler sets a custom settlement handler
+func WithSettlementHandler(handler func(*fasthttp.RequestCtx, *x402.SettleResponse)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SettlementHandler = handler
+	}
+}
+
+// WithIdentityHeaders enables payer identity headers for downstream handlers.
+// With no arguments, all of x402http.DefaultIdentityHeaders are injected.
+func WithIdentityHeaders(headers ...string) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		if len(headers) == 0 {
+			headers = x402http.DefaultIdentityHeaders
+		}
+		c.IdentityHeaders = headers
+	}
+}
+
+// WithTimeout sets the context timeout for payment operations
+func WithTimeout(timeout time.Duration) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// PaymentMiddleware creates middleware using a pre-configured resource server
+func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceServer, opts ...MiddlewareOption) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	config := &MiddlewareConfig{
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	httpServer := x402http.Wrappedx402HTTPResourceServer(routes, server)
+
+	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
+	if config.SyncFacilitatorOnStart {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+		if err := httpServer.Initialize(ctx); err != nil {
+			fmt.Printf("Warning: failed to initialize x402 server: %v\n", err)
+		}
+	}
+
+	return createMiddleware(httpServer, config)
+}
+
+// Middleware creates middleware for an HTTP server that is already configured
+// and initialized. Verified requests carry the payer (see GetPaymentIdentity)
+// and are settled after the handler returns; failed responses (status 400
+// and above) release the payment instead.
+func Middleware(server *x402http.HTTPServer, paywallConfig *x402http.PaywallConfig, opts ...MiddlewareOption) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	config := &MiddlewareConfig{
+		PaywallConfig: paywallConfig,
+		Timeout:       30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return createMiddleware(server, config)
+}
+
+// createMiddleware creates the middleware handler
+func createMiddleware(server *x402http.HTTPServer, config *MiddlewareConfig) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(requestCtx *fasthttp.RequestCtx) {
+			reqCtx := x402http.HTTPRequestContext{
+				Adapter: NewFastHTTPAdapter(requestCtx),
+				Host:    string(requestCtx.Host()),
+				Path:    string(requestCtx.Path()),
+				Method:  string(requestCtx.Method()),
+			}
+
+			// Check if route requires payment before waiting for initialization
+			if !server.RequiresPayment(reqCtx) {
+				next(requestCtx)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(requestCtx, config.Timeout)
+			defer cancel()
+
+			result := server.ProcessHTTPRequest(ctx, reqCtx, config.PaywallConfig)
+			switch result.Type {
+			case x402http.ResultNoPaymentRequired:
+				next(requestCtx)
+			case x402http.ResultPaymentError:
+				writeInstructions(requestCtx, result.Response)
+			case x402http.ResultPaymentVerified:
+				handlePaymentVerified(requestCtx, next, server, ctx, result, config)
+			}
+		}
+	}
+}
+
+// handlePaymentVerified runs the handler and settles if it succeeded
+func handlePaymentVerified(requestCtx *fasthttp.RequestCtx, next fasthttp.RequestHandler, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) {
+	// Expose the payer to the protected handler
+	identity := x402http.NewPaymentIdentity(result)
+	requestCtx.SetUserValue(PaymentIdentityKey, identity)
+	if config.IdentityHeaders != nil {
+		for _, name := range x402http.DefaultIdentityHeaders {
+			requestCtx.Request.Header.Del(name)
+		}
+		for key, value := range identity.Headers(config.IdentityHeaders) {
+			requestCtx.Request.Header.Set(key, value)
+		}
+	}
+
+	// fasthttp sends the response after the handler returns, so nothing has
+	// been written yet
+	next(requestCtx)
+
+	// Don't settle if response failed; let the client retry with the same authorization
+	if requestCtx.Response.StatusCode() >= 400 {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		return
+	}
+
+	// Per-item routes settle only for the items the handler returned
+	amount := x402http.ItemSettlementAmount(result, identity)
+	if amount == "0" {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		return
+	}
+
+	settleResult := server.ProcessSettlementAmount(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+		amount,
+	)
+
+	// Requests let through under the verify grace period are served even if
+	// settlement fails; they were flagged for reconciliation
+	if !settleResult.Success && result.Reconcile != nil {
+		return
+	}
+
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+		// Replace the handler's response
+		requestCtx.Response.Reset()
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(requestCtx, fmt.Errorf("settlement failed: %s", errorReason))
+		} else {
+			writeJSON(requestCtx, http.StatusPaymentRequired, map[string]string{
+				"error":   "Settlement failed",
+				"details": errorReason,
+			})
+		}
+		return
+	}
+
+	for key, value := range settleResult.Headers {
+		requestCtx.Response.Header.Set(key, value)
+	}
+
+	// Record the transaction for outer layers (X-402-Tx, if allowlisted)
+	identity.SetSettlement(settleResult)
+	if config.IdentityHeaders != nil {
+		if tx, ok := identity.Headers(config.IdentityHeaders)[http.CanonicalHeaderKey(x402http.TransactionIdentityHeader)]; ok {
+			requestCtx.Response.Header.Set(x402http.TransactionIdentityHeader, tx)
+		}
+	}
+
+	if config.SettlementHandler != nil {
+		config.SettlementHandler(requestCtx, &x402.SettleResponse{
+			Success:     true,
+			Transaction: settleResult.Transaction,
+			Network:     settleResult.Network,
+			Payer:       settleResult.Payer,
+		})
+	}
+}
+
+// writeInstructions writes a payment error response
+func writeInstructions(requestCtx *fasthttp.RequestCtx, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		requestCtx.Response.Header.Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case nil:
+		requestCtx.SetStatusCode(response.Status)
+	case string:
+		if response.IsHTML {
+			requestCtx.SetContentType("text/html; charset=utf-8")
+		}
+		requestCtx.SetStatusCode(response.Status)
+		requestCtx.SetBodyString(body)
+	case []byte:
+		// Encoded body for a negotiated media type (e.g. application/x402+cbor)
+		requestCtx.SetStatusCode(response.Status)
+		requestCtx.SetBody(body)
+	default:
+		writeJSON(requestCtx, response.Status, body)
+	}
+}
+
+// writeJSON writes a JSON response
+func writeJSON(requestCtx *fasthttp.RequestCtx, status int, body interface{}) {
+	requestCtx.SetContentType("application/json")
+	requestCtx.SetStatusCode(status)
+	_ = json.NewEncoder(requestCtx).Encode(body)
+}
@@ -0,0 +1,112 @@
+package fasthttp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// serve runs the handler on an in-memory listener and returns a dialer for it
+func serve(t *testing.T, handler fasthttp.RequestHandler) func(context.Context, string, string) (net.Conn, error) {
+	t.Helper()
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = fasthttp.Serve(ln, handler) }()
+	t.Cleanup(func() { _ = ln.Close() })
+	return func(context.Context, string, string) (net.Conn, error) {
+		return ln.Dial()
+	}
+}
+
+func newTestHandler(t *testing.T) fasthttp.RequestHandler {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	accepts := x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}
+	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+		"GET /paid":   {Accepts: accepts},
+		"GET /errors": {Accepts: accepts},
+	}, x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	return Middleware(server, nil)(func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/errors" {
+			ctx.Error("boom", fasthttp.StatusInternalServerError)
+			return
+		}
+		payer := "free"
+		if identity, ok := GetPaymentIdentity(ctx); ok {
+			payer = identity.Payer
+		}
+		ctx.Response.Header.Set("X-Handler", "yes")
+		ctx.SetBodyString(payer)
+	})
+}
+
+func TestMiddlewareSettlesAfterHandler(t *testing.T) {
+	dial := serve(t, newTestHandler(t))
+
+	plain := &http.Client{Transport: &http.Transport{DialContext: dial}}
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	paying := x402http.WrapHTTPClientWithPayment(&http.Client{Transport: &http.Transport{DialContext: dial}}, x402http.Newx402HTTPClient(client))
+
+	get := func(client *http.Client, path string) (*http.Response, string) {
+		resp, err := client.Get("http://x402.test" + path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, _ := get(plain, "/paid"); resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" {
+		t.Errorf("Expected a 402 with payment requirements, got %d", resp.StatusCode)
+	}
+	if resp, body := get(plain, "/free"); resp.StatusCode != http.StatusOK || body != "free" {
+		t.Errorf("Expected an unpriced route to pass through, got %d %q", resp.StatusCode, body)
+	}
+
+	resp, body := get(paying, "/paid")
+	if resp.StatusCode != http.StatusOK || body != "~Alice" {
+		t.Fatalf("Expected the paid handler to see the payer, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" || resp.Header.Get("X-Handler") != "yes" {
+		t.Errorf("Expected settlement and handler headers, got %v", resp.Header)
+	}
+
+	if resp, _ := get(paying, "/errors"); resp.StatusCode != http.StatusInternalServerError || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected a failed handler not to settle, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetHeaderReadsPaymentSignatureInPlace(t *testing.T) {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.Set("PAYMENT-SIGNATURE", "eyJ4NDAyVmVyc2lvbiI6Mn0=")
+	ctx.Request.Header.Set("Accept", "text/html")
+
+	adapter := NewFastHTTPAdapter(&ctx)
+	if got := adapter.GetHeader("payment-signature"); got != "eyJ4NDAyVmVyc2lvbiI6Mn0=" {
+		t.Errorf("GetHeader(payment-signature) = %q", got)
+	}
+	if got := adapter.GetAcceptHeader(); got != "text/html" {
+		t.Errorf("GetAcceptHeader() = %q", got)
+	}
+	if got := adapter.GetHeader("X-Missing"); got != "" {
+		t.Errorf("Expected an empty missing header, got %q", got)
+	}
+}
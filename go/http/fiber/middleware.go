@@ -0,0 +1,320 @@
+// Package fiber provides x402 payment middleware for Fiber applications.
+//
+// Fiber runs on fasthttp, so requests are not net/http requests and handlers
+// write into a buffered response that is sent once the handler chain
+// returns. The middleware uses that: it verifies the payment, runs the rest
+// of the chain, settles, and adds the settlement headers before Fiber sends
+// the response.
+//
+// The middleware is written against the methods of *fiber.Ctx rather than
+// the type itself, so this package does not pull Fiber and its dependencies
+// into every x402 build. Instantiate it with Fiber's context and response
+// types:
+//
+//	server := x402http.Newx402HTTPResourceServer(routes, x402.WithFacilitatorClient(facilitator), ...)
+//	_ = server.Initialize(ctx)
+//
+//	app := fiber.New()
+//	app.Use(fibermw.Middleware[*fiber.Ctx, *fasthttp.Response](server, nil))
+//	app.Get("/weather", func(c *fiber.Ctx) error {
+//	    identity, _ := fibermw.GetPaymentIdentity(c)
+//	    return c.SendString("paid by " + identity.Payer)
+//	})
+package fiber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// ============================================================================
+// Fiber Context
+// ============================================================================
+
+// Ctx is the part of *fiber.Ctx the middleware uses. C is the context type
+// itself and R its response type (*fasthttp.Response).
+type Ctx[C any, R Response] interface {
+	Get(key string, defaultValue ...string) string
+	Method(override ...string) string
+	Path(override ...string) string
+	BaseURL() string
+	Hostname() string
+	IP() string
+	Body() []byte
+	UserContext() context.Context
+	SetUserContext(ctx context.Context)
+	Locals(key interface{}, value ...interface{}) interface{}
+	Set(key string, val string)
+	Status(status int) C
+	Send(body []byte) error
+	Response() R
+	Next() error
+}
+
+// Response is the part of *fasthttp.Response the middleware uses
+type Response interface {
+	StatusCode() int
+}
+
+// FiberAdapter implements HTTPAdapter for Fiber requests
+type FiberAdapter[C Ctx[C, R], R Response] struct {
+	ctx C
+}
+
+// NewFiberAdapter creates a new Fiber adapter
+func NewFiberAdapter[C Ctx[C, R], R Response](ctx C) *FiberAdapter[C, R] {
+	return &FiberAdapter[C, R]{ctx: ctx}
+}
+
+// GetHeader gets a request header
+func (a *FiberAdapter[C, R]) GetHeader(name string) string {
+	return a.ctx.Get(name)
+}
+
+// GetMethod gets the HTTP method
+func (a *FiberAdapter[C, R]) GetMethod() string {
+	return a.ctx.Method()
+}
+
+// GetPath gets the request path
+func (a *FiberAdapter[C, R]) GetPath() string {
+	return a.ctx.Path()
+}
+
+// GetURL gets the full request URL
+func (a *FiberAdapter[C, R]) GetURL() string {
+	return a.ctx.BaseURL() + a.ctx.Path()
+}
+
+// GetAcceptHeader gets the Accept header
+func (a *FiberAdapter[C, R]) GetAcceptHeader() string {
+	return a.ctx.Get("Accept")
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *FiberAdapter[C, R]) GetUserAgent() string {
+	return a.ctx.Get("User-Agent")
+}
+
+// GetClientIP gets the client IP, honoring the app's proxy settings
+func (a *FiberAdapter[C, R]) GetClientIP() string {
+	return a.ctx.IP()
+}
+
+// GetBody returns the request body, up to x402http.MaxRequestBodySize.
+// fasthttp reads bodies in full, so it stays available to handlers.
+func (a *FiberAdapter[C, R]) GetBody() ([]byte, error) {
+	body := a.ctx.Body()
+	if len(body) > x402http.MaxRequestBodySize {
+		return nil, x402http.ErrRequestBodyTooLarge
+	}
+	return body, nil
+}
+
+// ============================================================================
+// Payer Identity
+// ============================================================================
+
+// PaymentIdentityKey is the Fiber locals key holding the
+// *x402http.PaymentIdentity of a verified request
+const PaymentIdentityKey = "x402.paymentIdentity"
+
+// Locals is the part of *fiber.Ctx GetPaymentIdentity uses
+type Locals interface {
+	Locals(key interface{}, value ...interface{}) interface{}
+}
+
+// GetPaymentIdentity returns who paid for the request, for handlers behind
+// the middleware. The transaction is filled in once the payment settles.
+func GetPaymentIdentity(c Locals) (*x402http.PaymentIdentity, bool) {
+	identity, ok := c.Locals(PaymentIdentityKey).(*x402http.PaymentIdentity)
+	return identity, ok
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware
+type MiddlewareConfig struct {
+	// Paywall configuration
+	PaywallConfig *x402http.PaywallConfig
+
+	// SettlementHandler is called after a successful settlement, before the
+	// response is sent. Returning an error fails the request as Fiber does
+	// for handler errors.
+	SettlementHandler func(settlement *x402.SettleResponse) error
+
+	// Context timeout for payment operations
+	Timeout time.Duration
+}
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption func(*MiddlewareConfig)
+
+// WithSettlementHandler sets a handler called after successful settlement
+func WithSettlementHandler(handler func(settlement *x402.SettleResponse) error) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SettlementHandler = handler
+	}
+}
+
+// WithTimeout sets the context timeout for payment operations
+func WithTimeout(timeout time.Duration) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// Middleware creates Fiber middleware for an HTTP server that is already
+// configured and initialized. The returned function is a fiber.Handler.
+// Verified requests carry the payer (see GetPaymentIdentity, or
+// x402http.PaymentIdentityFromContext on c.UserContext()) and are settled
+// after the rest of the chain succeeds; failed responses (status 400 and
+// above, or a returned error) release the payment instead.
+func Middleware[C Ctx[C, R], R Response](server *x402http.HTTPServer, paywallConfig *x402http.PaywallConfig, opts ...MiddlewareOption) func(C) error {
+	config := &MiddlewareConfig{
+		PaywallConfig: paywallConfig,
+		Timeout:       30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(c C) error {
+		reqCtx := x402http.HTTPRequestContext{
+			Adapter: NewFiberAdapter[C, R](c),
+			Host:    c.Hostname(),
+			Path:    c.Path(),
+			Method:  c.Method(),
+		}
+
+		// Check if route requires payment before waiting for initialization
+		if !server.RequiresPayment(reqCtx) {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), config.Timeout)
+		defer cancel()
+
+		result := server.ProcessHTTPRequest(ctx, reqCtx, config.PaywallConfig)
+		switch result.Type {
+		case x402http.ResultPaymentError:
+			return writeInstructions(c, result.Response)
+		case x402http.ResultPaymentVerified:
+			return handlePaymentVerified[C, R](c, server, ctx, result, config)
+		}
+		return c.Next()
+	}
+}
+
+// handlePaymentVerified runs the rest of the chain and settles if it succeeded
+func handlePaymentVerified[C Ctx[C, R], R Response](c C, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) error {
+	// Expose the payer to the protected handler
+	identity := x402http.NewPaymentIdentity(result)
+	c.SetUserContext(x402http.ContextWithPaymentIdentity(c.UserContext(), identity))
+	c.Locals(PaymentIdentityKey, identity)
+
+	// Fiber buffers the response until the chain returns, so nothing has
+	// been sent yet. Don't settle if the handler failed; let the client
+	// retry with the same authorization.
+	if err := c.Next(); err != nil {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		return err
+	}
+	if c.Response().StatusCode() >= 400 {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		return nil
+	}
+
+	// Per-item routes settle only for the items the handler returned
+	amount := x402http.ItemSettlementAmount(result, identity)
+	if amount == "0" {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		return nil
+	}
+
+	settleResult := server.ProcessSettlementAmount(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+		amount,
+	)
+
+	// Requests let through under the verify grace period are served even if
+	// settlement fails; they were flagged for reconciliation
+	if !settleResult.Success && result.Reconcile != nil {
+		return nil
+	}
+
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+		return writeJSON(c, http.StatusPaymentRequired, map[string]string{
+			"error":   "Settlement failed",
+			"details": errorReason,
+		})
+	}
+
+	for key, value := range settleResult.Headers {
+		c.Set(key, value)
+	}
+	identity.SetSettlement(settleResult)
+
+	if config.SettlementHandler != nil {
+		return config.SettlementHandler(&x402.SettleResponse{
+			Success:     true,
+			Transaction: settleResult.Transaction,
+			Network:     settleResult.Network,
+			Payer:       settleResult.Payer,
+		})
+	}
+	return nil
+}
+
+// writeInstructions sends the payment instructions
+func writeInstructions[C Ctx[C, R], R Response](c C, response *x402http.HTTPResponseInstructions) error {
+	for key, value := range response.Headers {
+		c.Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case nil:
+		c.Status(response.Status)
+		return nil
+	case string:
+		if response.IsHTML {
+			c.Set("Content-Type", "text/html; charset=utf-8")
+		}
+		c.Status(response.Status)
+		return c.Send([]byte(body))
+	case []byte:
+		// Encoded body for a negotiated media type (e.g. application/x402+cbor)
+		c.Status(response.Status)
+		return c.Send(body)
+	default:
+		return writeJSON(c, response.Status, body)
+	}
+}
+
+// writeJSON replaces the response with a JSON body
+func writeJSON[C Ctx[C, R], R Response](c C, status int, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	c.Set("Content-Type", "application/json")
+	c.Status(status)
+	return c.Send(data)
+}
@@ -0,0 +1,189 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// fakeCtx mirrors the *fiber.Ctx methods the middleware uses, running a
+// handler chain over a buffered response as Fiber does
+type fakeCtx struct {
+	req      *http.Request
+	body     []byte
+	userCtx  context.Context
+	locals   map[interface{}]interface{}
+	header   http.Header
+	response *fakeResponse
+	handlers []func(*fakeCtx) error
+}
+
+type fakeResponse struct {
+	status int
+	body   []byte
+}
+
+func (r *fakeResponse) StatusCode() int { return r.status }
+
+func (c *fakeCtx) Get(key string, defaultValue ...string) string {
+	if value := c.req.Header.Get(key); value != "" || len(defaultValue) == 0 {
+		return value
+	}
+	return defaultValue[0]
+}
+func (c *fakeCtx) Method(override ...string) string   { return c.req.Method }
+func (c *fakeCtx) Path(override ...string) string     { return c.req.URL.Path }
+func (c *fakeCtx) BaseURL() string                    { return "http://" + c.req.Host }
+func (c *fakeCtx) Hostname() string                   { return c.req.Host }
+func (c *fakeCtx) IP() string                         { return "127.0.0.1" }
+func (c *fakeCtx) Body() []byte                       { return c.body }
+func (c *fakeCtx) UserContext() context.Context       { return c.userCtx }
+func (c *fakeCtx) SetUserContext(ctx context.Context) { c.userCtx = ctx }
+func (c *fakeCtx) Set(key string, val string)         { c.header.Set(key, val) }
+func (c *fakeCtx) Response() *fakeResponse            { return c.response }
+
+func (c *fakeCtx) Locals(key interface{}, value ...interface{}) interface{} {
+	if len(value) > 0 {
+		c.locals[key] = value[0]
+		return value[0]
+	}
+	return c.locals[key]
+}
+
+func (c *fakeCtx) Status(status int) *fakeCtx {
+	c.response.status = status
+	return c
+}
+
+func (c *fakeCtx) Send(body []byte) error {
+	c.response.body = body
+	return nil
+}
+
+func (c *fakeCtx) Next() error {
+	if len(c.handlers) == 0 {
+		return nil
+	}
+	handler := c.handlers[0]
+	c.handlers = c.handlers[1:]
+	return handler(c)
+}
+
+// newTestApp serves a middleware and handler chain over net/http
+func newTestApp(t *testing.T, settled *[]string) *httptest.Server {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	accepts := x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}
+	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+		"GET /weather":  {Accepts: accepts},
+		"GET /failing":  {Accepts: accepts},
+		"GET /erroring": {Accepts: accepts},
+	}, x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	middleware := Middleware[*fakeCtx, *fakeResponse](server, nil, WithSettlementHandler(func(settlement *x402.SettleResponse) error {
+		*settled = append(*settled, settlement.Payer)
+		return nil
+	}))
+	handler := func(c *fakeCtx) error {
+		switch c.Path() {
+		case "/failing":
+			c.Status(http.StatusInternalServerError)
+			return c.Send([]byte("boom"))
+		case "/erroring":
+			return errors.New("boom")
+		}
+		payer := "free"
+		if identity, ok := GetPaymentIdentity(c); ok {
+			payer = identity.Payer
+			if fromContext, ok := x402http.PaymentIdentityFromContext(c.UserContext()); !ok || fromContext != identity {
+				t.Error("Expected the payer in the user context")
+			}
+		}
+		return c.Send([]byte(payer))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		c := &fakeCtx{
+			req:      r,
+			body:     body,
+			userCtx:  context.Background(),
+			locals:   map[interface{}]interface{}{},
+			header:   http.Header{},
+			response: &fakeResponse{status: http.StatusOK},
+			handlers: []func(*fakeCtx) error{middleware, handler},
+		}
+		if err := c.Next(); err != nil {
+			c.Status(http.StatusInternalServerError)
+		}
+		for key, values := range c.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(c.response.status)
+		_, _ = w.Write(c.response.body)
+	}))
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+func TestMiddlewareSettlesAfterHandler(t *testing.T) {
+	var settled []string
+	ts := newTestApp(t, &settled)
+	defer ts.Close()
+
+	get := func(client *http.Client, path string) (*http.Response, string) {
+		resp, err := client.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, _ := get(http.DefaultClient, "/weather")
+	if resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" {
+		t.Fatalf("Expected a 402 challenge, got %d", resp.StatusCode)
+	}
+
+	resp, body := get(payingClient(), "/weather")
+	if resp.StatusCode != http.StatusOK || body != "~Alice" || resp.Header.Get("PAYMENT-RESPONSE") == "" {
+		t.Fatalf("Expected a settled response for ~Alice, got %d %q", resp.StatusCode, body)
+	}
+	if len(settled) != 1 || settled[0] != "~Alice" {
+		t.Errorf("Expected the settlement handler to see ~Alice, got %v", settled)
+	}
+
+	// Failed handlers are not charged, whether they set a status or return an error
+	for _, path := range []string{"/failing", "/erroring"} {
+		resp, _ = get(payingClient(), path)
+		if resp.StatusCode != http.StatusInternalServerError || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+			t.Errorf("Expected %s to fail unsettled, got %d", path, resp.StatusCode)
+		}
+	}
+	if len(settled) != 1 {
+		t.Errorf("Expected failed handlers to skip settlement, got %v", settled)
+	}
+
+	if resp, body = get(http.DefaultClient, "/free"); resp.StatusCode != http.StatusOK || body != "free" {
+		t.Errorf("Expected unpriced routes to pass through, got %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Free Tier
+// ============================================================================
+
+// FreeTierConfig lets each client make a number of requests per window for free
+// before payment is required. Usage is counted in the server's Store.
+//
+// Quotas are only as strong as the client identity, so ClientKey is required:
+// keyed on an address or header a client controls, the free tier could be
+// reset at will.
+type FreeTierConfig struct {
+	// Requests is the number of free requests per client per window
+	Requests int64 `json:"requests"`
+
+	// Window is the quota period (default: 24 hours). Windows are aligned to
+	// multiples of the duration, so a 24 hour window resets at midnight UTC.
+	Window time.Duration `json:"window,omitempty"`
+
+	// Scope names the quota. Routes with the same scope share a quota
+	// (default: one quota shared by all routes).
+	Scope string `json:"scope,omitempty"`
+
+	// ClientKey identifies the client from a credential the server trusts, e.g.
+	// a validated API key or session. The free tier is disabled without it.
+	// Unidentified clients get no free requests.
+	ClientKey ClientKeyFunc `json:"-"`
+}
+
+// freeTierKeyPrefix namespaces free-tier counters in the store
+const freeTierKeyPrefix = "x402:free-tier:"
+
+// allowsFree reports whether the request fits in the client's free quota and,
+// if so, counts it. Requests carrying a payment never consume the quota, and
+// store errors fall back to requiring payment.
+func (s *x402HTTPResourceServer) allowsFree(ctx context.Context, reqCtx HTTPRequestContext, freeTier *FreeTierConfig) bool {
+	if freeTier.Requests <= 0 || freeTier.ClientKey == nil || s.store == nil {
+		return false
+	}
+	if reqCtx.Adapter == nil || s.paymentSignatureHeader(reqCtx.Adapter) != "" {
+		return false
+	}
+
+	client := freeTier.ClientKey(ctx, reqCtx)
+	if client == "" {
+		return false
+	}
+
+	window := freeTier.Window
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+	start := time.Now().Truncate(window)
+	key := freeTierKeyPrefix + freeTier.Scope + ":" + client + ":" + strconv.FormatInt(start.Unix(), 10)
+
+	used, err := s.store.Increment(ctx, key, 1, time.Until(start.Add(window)))
+	if err != nil {
+		return false
+	}
+	return used <= freeTier.Requests
+}
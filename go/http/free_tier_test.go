@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+type failingStore struct {
+	*MemoryStore
+}
+
+func (f *failingStore) Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func newFreeTierServer(t *testing.T, freeTier *FreeTierConfig) *x402HTTPResourceServer {
+	t.Helper()
+	freeTier.ClientKey = apiKeyClient
+	routes := RoutesConfig{
+		"GET /api": {
+			Accepts:  PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			FreeTier: freeTier,
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func freeTierRequest(server *x402HTTPResourceServer, headers map[string]string) string {
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", headers: headers}
+	return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil).Type
+}
+
+func TestFreeTierQuota(t *testing.T) {
+	server := newFreeTierServer(t, &FreeTierConfig{Requests: 2})
+	alice := map[string]string{"X-API-Key": "alice"}
+	bob := map[string]string{"X-API-Key": "bob"}
+
+	for i := 0; i < 2; i++ {
+		if got := freeTierRequest(server, alice); got != ResultNoPaymentRequired {
+			t.Fatalf("Expected free request %d to pass, got %s", i+1, got)
+		}
+	}
+	if got := freeTierRequest(server, alice); got != ResultPaymentError {
+		t.Errorf("Expected 402 after quota, got %s", got)
+	}
+	if got := freeTierRequest(server, bob); got != ResultNoPaymentRequired {
+		t.Errorf("Expected separate quota per client, got %s", got)
+	}
+}
+
+func TestFreeTierSkipsPaidAndUnidentifiedRequests(t *testing.T) {
+	server := newFreeTierServer(t, &FreeTierConfig{Requests: 1})
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api"}
+	paid := map[string]string{"X-API-Key": "alice", "PAYMENT-SIGNATURE": paymentHeader(t, server, HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"})}
+	if got := freeTierRequest(server, paid); got != ResultPaymentVerified {
+		t.Fatalf("Expected paid request to be verified, got %s", got)
+	}
+	if got := freeTierRequest(server, map[string]string{"X-API-Key": "alice"}); got != ResultNoPaymentRequired {
+		t.Errorf("Expected paid request not to consume the quota, got %s", got)
+	}
+
+	if got := freeTierRequest(server, nil); got != ResultPaymentError {
+		t.Errorf("Expected unidentified client to pay, got %s", got)
+	}
+}
+
+func TestFreeTierRequiresClientKey(t *testing.T) {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /api": {
+			Accepts:  PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			FreeTier: &FreeTierConfig{Requests: 5},
+		}},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	adapter := &mockIPAdapter{mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api"}, "203.0.113.7"}
+	if got := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil).Type; got != ResultPaymentError {
+		t.Errorf("Expected no free requests without a ClientKey, got %s", got)
+	}
+}
+
+func TestFreeTierStoreErrorRequiresPayment(t *testing.T) {
+	server := newFreeTierServer(t, &FreeTierConfig{Requests: 5})
+	server.SetStore(&failingStore{MemoryStore: NewMemoryStore()})
+
+	if got := freeTierRequest(server, map[string]string{"X-API-Key": "alice"}); got != ResultPaymentError {
+		t.Errorf("Expected store failure to require payment, got %s", got)
+	}
+}
@@ -41,6 +41,12 @@ type Config struct {
 
 	// SettlementHandler called after successful settlement (optional)
 	SettlementHandler func(*gin.Context, *x402.SettleResponse)
+
+	// IdentityHeaders lists the X-402-* payer identity headers to inject (optional)
+	IdentityHeaders []string
+
+	// ServerSetup configures the HTTP server before initialization (optional)
+	ServerSetup func(*x402http.HTTPServer)
 }
 
 // SchemeConfig configures a payment scheme for a network.
@@ -124,6 +130,12 @@ func X402Payment(config Config) gin.HandlerFunc {
 	if config.SettlementHandler != nil {
 		opts = append(opts, WithSettlementHandler(config.SettlementHandler))
 	}
+	if config.IdentityHeaders != nil {
+		opts = append(opts, WithIdentityHeaders(config.IdentityHeaders...))
+	}
+	if config.ServerSetup != nil {
+		opts = append(opts, WithServerSetup(config.ServerSetup))
+	}
 
 	// Delegate to PaymentMiddlewareFromConfig (reuse all logic)
 	return PaymentMiddlewareFromConfig(config.Routes, opts...)
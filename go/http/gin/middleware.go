@@ -66,6 +66,43 @@ func (a *GinAdapter) GetUserAgent() string {
 	return a.ctx.GetHeader("User-Agent")
 }
 
+// GetClientIP gets the client IP, honoring the engine's trusted proxy settings
+func (a *GinAdapter) GetClientIP() string {
+	return a.ctx.ClientIP()
+}
+
+// GetBody reads the request body and restores it for downstream handlers
+func (a *GinAdapter) GetBody() ([]byte, error) {
+	if a.ctx.Request.Body == nil {
+		return nil, nil
+	}
+	body, replay, err := x402http.ReadBody(a.ctx.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	a.ctx.Request.Body = replay
+	return body, nil
+}
+
+// ============================================================================
+// Payer Identity
+// ============================================================================
+
+// PaymentIdentityKey is the Gin context key holding the *x402http.PaymentIdentity
+// of a verified request
+const PaymentIdentityKey = "x402.paymentIdentity"
+
+// GetPaymentIdentity returns who paid for the request, for handlers behind the
+// middleware. The transaction is filled in once the payment settles.
+func GetPaymentIdentity(c *gin.Context) (*x402http.PaymentIdentity, bool) {
+	value, ok := c.Get(PaymentIdentityKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := value.(*x402http.PaymentIdentity)
+	return identity, ok
+}
+
 // ============================================================================
 // Middleware Configuration
 // ============================================================================
@@ -95,6 +132,14 @@ type MiddlewareConfig struct {
 
 	// Context timeout for payment operations
 	Timeout time.Duration
+
+	// IdentityHeaders lists the X-402-* headers injected into verified requests
+	// (X-402-Tx is set on the response after settlement). Nil disables injection.
+	IdentityHeaders []string
+
+	// ServerSetup is called with the HTTP server before initialization, e.g. to
+	// enable the verify grace period or register monitor hooks
+	ServerSetup func(*x402http.HTTPServer)
 }
 
 // SchemeRegistration registers a scheme with the server
@@ -151,6 +196,24 @@ func WithSettlementHandler(handler func(*gin.Context, *x402.SettleResponse)) Mid
 	}
 }
 
+// WithIdentityHeaders enables payer identity headers for downstream handlers.
+// With no arguments, all of x402http.DefaultIdentityHeaders are injected.
+func WithIdentityHeaders(headers ...string) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		if len(headers) == 0 {
+			headers = x402http.DefaultIdentityHeaders
+		}
+		c.IdentityHeaders = headers
+	}
+}
+
+// WithServerSetup configures the underlying HTTP server before initialization
+func WithServerSetup(setup func(*x402http.HTTPServer)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.ServerSetup = setup
+	}
+}
+
 // WithTimeout sets the context timeout for payment operations
 func WithTimeout(timeout time.Duration) MiddlewareOption {
 	return func(c *MiddlewareConfig) {
@@ -180,6 +243,10 @@ func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceSe
 
 	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
 
+	if config.ServerSetup != nil {
+		config.ServerSetup(httpServer)
+	}
+
 	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
 	if config.SyncFacilitatorOnStart {
 		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
@@ -193,6 +260,25 @@ func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceSe
 	return createMiddlewareHandler(httpServer, config)
 }
 
+// Middleware creates Gin middleware for an HTTP server that is already
+// configured and initialized, e.g. one shared with other handlers. Verified
+// requests carry the payer (see GetPaymentIdentity) and are settled after the
+// handler chain completes.
+//
+//	server := x402http.Newx402HTTPResourceServer(routes, x402.WithFacilitatorClient(facilitator), ...)
+//	_ = server.Initialize(ctx)
+//	r.Use(ginmw.Middleware(server, &x402http.PaywallConfig{AppName: "My API"}))
+func Middleware(server *x402http.HTTPServer, paywallConfig *x402http.PaywallConfig, opts ...MiddlewareOption) gin.HandlerFunc {
+	config := &MiddlewareConfig{
+		PaywallConfig: paywallConfig,
+		Timeout:       30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return createMiddlewareHandler(server, config)
+}
+
 // PaymentMiddlewareFromConfig creates Gin middleware for x402 payment handling.
 // This creates the server internally from the provided options.
 func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...MiddlewareOption) gin.HandlerFunc {
@@ -218,6 +304,10 @@ func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...Middlewar
 
 	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
 
+	if config.ServerSetup != nil {
+		config.ServerSetup(httpServer)
+	}
+
 	// Register schemes
 	for _, scheme := range config.Schemes {
 		httpServer.Register(scheme.Network, scheme.Server)
@@ -243,6 +333,7 @@ func createMiddlewareHandler(server *x402http.HTTPServer, config *MiddlewareConf
 		adapter := NewGinAdapter(c)
 		reqCtx := x402http.HTTPRequestContext{
 			Adapter: adapter,
+			Host:    c.Request.Host,
 			Path:    c.Request.URL.Path,
 			Method:  c.Request.Method,
 		}
@@ -294,6 +385,9 @@ func handlePaymentError(c *gin.Context, response *x402http.HTTPResponseInstructi
 	// Send response body
 	if response.IsHTML {
 		c.Data(response.Status, "text/html; charset=utf-8", []byte(response.Body.(string)))
+	} else if data, ok := response.Body.([]byte); ok {
+		// Encoded body for a negotiated media type (e.g. application/x402+cbor)
+		c.Data(response.Status, response.Headers["Content-Type"], data)
 	} else {
 		c.JSON(response.Status, response.Body)
 	}
@@ -312,11 +406,23 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 	}
 	c.Writer = writer
 
+	// Expose the payer to the protected handler
+	identity := x402http.NewPaymentIdentity(result)
+	c.Request = c.Request.WithContext(x402http.ContextWithPaymentIdentity(c.Request.Context(), identity))
+	c.Set(PaymentIdentityKey, identity)
+	if config.IdentityHeaders != nil {
+		x402http.StripIdentityHeaders(c.Request.Header)
+		for key, value := range identity.Headers(config.IdentityHeaders) {
+			c.Request.Header.Set(key, value)
+		}
+	}
+
 	// Continue to protected handler
 	c.Next()
 
 	// Check if aborted
 	if c.IsAborted() {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
 		return
 	}
 
@@ -325,6 +431,9 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 
 	// Don't settle if response failed
 	if writer.statusCode >= 400 {
+		// Let the client retry with the same authorization
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+
 		// Write captured response
 		c.Writer.WriteHeader(writer.statusCode)
 		_, _ = c.Writer.Write(writer.body.Bytes())
@@ -338,17 +447,35 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 	fmt.Printf("   PaymentPayload: %+v\n", result.PaymentPayload)
 	fmt.Printf("   PaymentRequirements: %+v\n", result.PaymentRequirements)
 
+	// Per-item routes settle only for the items the handler returned
+	amount := x402http.ItemSettlementAmount(result, identity)
+	if amount == "0" {
+		server.ReleasePayment(ctx, *result.PaymentPayload)
+		c.Writer.WriteHeader(writer.statusCode)
+		_, _ = c.Writer.Write(writer.body.Bytes())
+		return
+	}
+
 	// Process settlement
-	settleResult := server.ProcessSettlement(
-		ctx,
+	settleResult := server.ProcessSettlementAmount(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
 		*result.PaymentPayload,
 		*result.PaymentRequirements,
+		amount,
 	)
 
 	fmt.Printf("🔍 [GIN SETTLEMENT DEBUG] Settlement completed\n")
 	fmt.Printf("   Success: %v\n", settleResult.Success)
 	fmt.Printf("   ErrorReason: %v\n", settleResult.ErrorReason)
 
+	// Requests let through under the verify grace period are served even if
+	// settlement fails; they were flagged for reconciliation
+	if !settleResult.Success && result.Reconcile != nil {
+		c.Writer.WriteHeader(writer.statusCode)
+		_, _ = c.Writer.Write(writer.body.Bytes())
+		return
+	}
+
 	// Check settlement success
 	if !settleResult.Success {
 		errorReason := settleResult.ErrorReason
@@ -371,6 +498,14 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 		c.Header(key, value)
 	}
 
+	// Record the transaction for outer layers (X-402-Tx, if allowlisted)
+	identity.SetSettlement(settleResult)
+	if config.IdentityHeaders != nil {
+		if tx, ok := identity.Headers(config.IdentityHeaders)[http.CanonicalHeaderKey(x402http.TransactionIdentityHeader)]; ok {
+			c.Header(x402http.TransactionIdentityHeader, tx)
+		}
+	}
+
 	// Call settlement handler if configured
 	if config.SettlementHandler != nil {
 		settleResponse := &x402.SettleResponse{
@@ -6,9 +6,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -98,24 +100,25 @@ func createTestRouter() *gin.Engine {
 	return router
 }
 
-// createPaymentHeader creates a base64-encoded payment header for testing
-//
-//nolint:unparam // payTo is always "0xtest" in current tests but keeping param for flexibility
-func createPaymentHeader(payTo string) string {
+// createPaymentHeader creates a base64-encoded payment header accepting the
+// first requirements router offers for an unpaid request
+func createPaymentHeader(t *testing.T, router *gin.Engine, method string, path string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, "http://example.com"+path, nil))
+	requiredJSON, err := base64.StdEncoding.DecodeString(w.Header().Get("PAYMENT-REQUIRED"))
+	if err != nil {
+		t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+	}
+	var required x402.PaymentRequired
+	if err := json.Unmarshal(requiredJSON, &required); err != nil || len(required.Accepts) == 0 {
+		t.Fatalf("Expected offered requirements, got %s (%v)", requiredJSON, err)
+	}
+
 	payload := x402.PaymentPayload{
 		X402Version: 2,
 		Payload:     map[string]interface{}{"sig": "test"},
-		Accepted: x402.PaymentRequirements{
-			Scheme:            "exact",
-			Network:           "eip155:1",
-			Asset:             "USDC",
-			Amount:            "1000000",
-			PayTo:             payTo,
-			MaxTimeoutSeconds: 300,
-			Extra: map[string]interface{}{
-				"resourceUrl": "http://example.com/api",
-			},
-		},
+		Accepted:    required.Accepts[0],
 	}
 
 	payloadJSON, _ := json.Marshal(payload)
@@ -255,6 +258,49 @@ func TestGinAdapter_GetUserAgent(t *testing.T) {
 	}
 }
 
+func TestGinAdapter_GetBody(t *testing.T) {
+	router := createTestRouter()
+	var adapterBody, handlerBody []byte
+
+	router.POST("/test", func(c *gin.Context) {
+		body, err := NewGinAdapter(c).GetBody()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		adapterBody = body
+		handlerBody, _ = c.GetRawData()
+	})
+
+	req := httptest.NewRequest("POST", "/test", bytes.NewBufferString(`{"prompt":"a cat"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if string(adapterBody) != `{"prompt":"a cat"}` {
+		t.Errorf("Expected adapter to read body, got '%s'", adapterBody)
+	}
+	if string(handlerBody) != string(adapterBody) {
+		t.Errorf("Expected body to remain readable by the handler, got '%s'", handlerBody)
+	}
+}
+
+func TestGinAdapter_GetClientIP(t *testing.T) {
+	router := createTestRouter()
+	var clientIP string
+
+	router.GET("/test", func(c *gin.Context) {
+		clientIP = NewGinAdapter(c).GetClientIP()
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if clientIP != "203.0.113.7" {
+		t.Errorf("Expected client IP '203.0.113.7', got '%s'", clientIP)
+	}
+}
+
 // ============================================================================
 // PaymentMiddleware Tests
 // ============================================================================
@@ -476,7 +522,7 @@ func TestPaymentMiddleware_SettlesAndReturnsResponseForVerifiedPayment(t *testin
 	})
 
 	req := httptest.NewRequest("POST", "/api", nil)
-	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
 	req.Host = "example.com"
 
 	w := httptest.NewRecorder()
@@ -546,7 +592,7 @@ func TestPaymentMiddleware_SkipsSettlementWhenHandlerReturns400OrHigher(t *testi
 	})
 
 	req := httptest.NewRequest("POST", "/api", nil)
-	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
 	req.Host = "example.com"
 
 	w := httptest.NewRecorder()
@@ -611,7 +657,7 @@ func TestPaymentMiddleware_Returns402WhenSettlementFails(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("POST", "/api", nil)
-	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
 	req.Host = "example.com"
 
 	w := httptest.NewRecorder()
@@ -634,6 +680,83 @@ func TestPaymentMiddleware_Returns402WhenSettlementFails(t *testing.T) {
 	}
 }
 
+func TestPaymentMiddleware_ServesGracedRequestWhenSettlementFails(t *testing.T) {
+	facilitatorDown := false
+	mockClient := &mockFacilitatorClient{
+		verifyFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			if facilitatorDown {
+				return nil, &x402http.FacilitatorUnavailableError{Operation: "verify", Err: errors.New("connection refused")}
+			}
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			if facilitatorDown {
+				return nil, errors.New("settle request failed: connection refused")
+			}
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds: []x402.SupportedKind{
+					{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+				},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+
+	routes := x402http.RoutesConfig{
+		"POST /api": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+
+	var reconciled []x402http.Reconciliation
+	router := createTestRouter()
+	router.Use(PaymentMiddlewareFromConfig(routes,
+		WithFacilitatorClient(mockClient),
+		WithScheme("eip155:1", &mockSchemeServer{scheme: "exact"}),
+		WithSyncFacilitatorOnStart(true),
+		WithTimeout(5*time.Second),
+		WithServerSetup(func(server *x402http.HTTPServer) {
+			server.EnableVerifyGracePeriod(x402http.VerifyGracePeriodConfig{Window: time.Minute, ClientKey: x402http.DefaultClientKey})
+			server.OnReconciliation(func(ctx context.Context, r x402http.Reconciliation) {
+				reconciled = append(reconciled, r)
+			})
+		}),
+	))
+
+	router.POST("/api", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": "protected-data"})
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api", nil)
+		req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
+		req.Header.Set("X-API-Key", "alice")
+		req.Host = "example.com"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(); w.Code != http.StatusOK {
+		t.Fatalf("Expected first paid request to succeed, got %d", w.Code)
+	}
+
+	facilitatorDown = true
+	w := request()
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected graced request to be served, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(reconciled) != 1 {
+		t.Errorf("Expected request flagged for reconciliation, got %d", len(reconciled))
+	}
+}
+
 func TestPaymentMiddleware_CustomErrorHandler(t *testing.T) {
 	customHandlerCalled := false
 
@@ -694,8 +817,9 @@ func TestPaymentMiddleware_CustomErrorHandler(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("POST", "/api", nil)
-	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
 	req.Host = "example.com"
+	customHandlerCalled = false
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -777,7 +901,7 @@ func TestPaymentMiddleware_CustomSettlementHandler(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("POST", "/api", nil)
-	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
 	req.Host = "example.com"
 
 	w := httptest.NewRecorder()
@@ -857,10 +981,125 @@ func TestPaymentMiddleware_WithTimeout(t *testing.T) {
 	}
 }
 
+func TestPaymentMiddleware_InjectsIdentityHeaders(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		verifyFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+
+	routes := x402http.RoutesConfig{
+		"POST /api": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+
+	router := createTestRouter()
+	router.Use(PaymentMiddlewareFromConfig(routes,
+		WithFacilitatorClient(mockClient),
+		WithScheme("eip155:1", &mockSchemeServer{scheme: "exact"}),
+		WithIdentityHeaders(x402http.PayerIdentityHeader, x402http.TransactionIdentityHeader),
+	))
+
+	var payerHeader, networkHeader string
+	var identity *x402http.PaymentIdentity
+	router.POST("/api", func(c *gin.Context) {
+		payerHeader = c.GetHeader(x402http.PayerIdentityHeader)
+		networkHeader = c.GetHeader(x402http.NetworkIdentityHeader)
+		identity, _ = x402http.PaymentIdentityFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"data": "protected-data"})
+	})
+
+	req := httptest.NewRequest("POST", "/api", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "POST", "/api"))
+	req.Header.Set(x402http.NetworkIdentityHeader, "spoofed")
+	req.Host = "example.com"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if payerHeader != "0xpayer" {
+		t.Errorf("Expected payer header '0xpayer', got '%s'", payerHeader)
+	}
+	if networkHeader != "" {
+		t.Errorf("Expected client-supplied network header to be stripped, got '%s'", networkHeader)
+	}
+	if identity == nil || identity.Network != "eip155:1" {
+		t.Errorf("Expected payment identity in request context, got %+v", identity)
+	}
+	if w.Header().Get(x402http.TransactionIdentityHeader) != "0xtx" {
+		t.Errorf("Expected X-402-Tx response header, got '%s'", w.Header().Get(x402http.TransactionIdentityHeader))
+	}
+}
+
 // ============================================================================
 // X402Payment (Builder Pattern) Tests
 // ============================================================================
 
+func TestMiddleware_UsesInitializedServerAndExposesPayer(t *testing.T) {
+	routes := x402http.RoutesConfig{
+		"GET /api": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+	server := x402http.Newx402HTTPResourceServer(routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	var payer string
+	router := createTestRouter()
+	router.Use(Middleware(server, &x402http.PaywallConfig{AppName: "Test App"}))
+	router.GET("/api", func(c *gin.Context) {
+		if identity, ok := GetPaymentIdentity(c); ok {
+			payer = identity.Payer
+		}
+		c.JSON(http.StatusOK, gin.H{"data": "protected-data"})
+	})
+
+	// Browsers get the paywall configured for the middleware
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPaymentRequired || !strings.Contains(w.Body.String(), "Test App") {
+		t.Errorf("Expected the configured paywall, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader(t, router, "GET", "/api"))
+	req.Host = "example.com"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Fatalf("Expected a settled response, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if payer != "0xmock" {
+		t.Errorf("Expected the handler to see payer 0xmock, got %q", payer)
+	}
+}
+
 func TestX402Payment_CreatesWorkingMiddleware(t *testing.T) {
 	mockClient := &mockFacilitatorClient{
 		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
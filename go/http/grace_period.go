@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Verify Grace Period
+// ============================================================================
+
+// VerifyGracePeriodConfig lets clients that paid recently through transient
+// facilitator failures. When verification fails because the facilitator could
+// not be reached or returned a server error (a FacilitatorUnavailableError), a
+// client whose payment verified within Window is let through and the request
+// is flagged for reconciliation. Payments the facilitator or a hook rejects
+// are never graced.
+type VerifyGracePeriodConfig struct {
+	// Window is how recently the client must have paid
+	Window time.Duration
+
+	// ClientKey identifies the client from a credential the server trusts, e.g.
+	// a validated API key or session. No client is graced without it.
+	// Unidentified clients are never graced.
+	ClientKey ClientKeyFunc
+}
+
+// Reconciliation describes a request let through under the grace period whose
+// payment was never verified. Settle or refund it once the facilitator recovers.
+type Reconciliation struct {
+	ClientKey           string
+	LastPaidAt          time.Time
+	VerifyError         string
+	PaymentPayload      *types.PaymentPayload
+	PaymentRequirements *types.PaymentRequirements
+}
+
+// ReconciliationHook receives requests flagged for reconciliation
+type ReconciliationHook func(context.Context, Reconciliation)
+
+// lastPaidKeyPrefix namespaces last-payment timestamps in the store
+const lastPaidKeyPrefix = "x402:last-paid:"
+
+// EnableVerifyGracePeriod enables the grace period for recently-paid clients.
+// Payment times are kept in the server's Store.
+func (s *x402HTTPResourceServer) EnableVerifyGracePeriod(config VerifyGracePeriodConfig) *x402HTTPResourceServer {
+	s.gracePeriod = &config
+	return s
+}
+
+// OnReconciliation registers a hook called for every request let through under the grace period
+func (s *x402HTTPResourceServer) OnReconciliation(hook ReconciliationHook) *x402HTTPResourceServer {
+	s.reconciliationHooks = append(s.reconciliationHooks, hook)
+	return s
+}
+
+// recordPayment remembers that the request's client just paid
+func (s *x402HTTPResourceServer) recordPayment(ctx context.Context, reqCtx HTTPRequestContext) {
+	if s.gracePeriod == nil || s.gracePeriod.ClientKey == nil || s.store == nil {
+		return
+	}
+	client := s.gracePeriod.ClientKey(ctx, reqCtx)
+	if client == "" {
+		return
+	}
+	_ = s.store.Set(ctx, lastPaidKeyPrefix+client, time.Now().UTC().Format(time.RFC3339Nano), s.gracePeriod.Window)
+}
+
+// graceVerifyFailure returns a reconciliation record when a failed verification
+// qualifies for the grace period, or nil when the failure must be enforced
+func (s *x402HTTPResourceServer) graceVerifyFailure(ctx context.Context, reqCtx HTTPRequestContext, payload *types.PaymentPayload, requirements *types.PaymentRequirements, verifyErr error) *Reconciliation {
+	if s.gracePeriod == nil || s.gracePeriod.ClientKey == nil || s.store == nil || !isTransientVerifyError(verifyErr) {
+		return nil
+	}
+	client := s.gracePeriod.ClientKey(ctx, reqCtx)
+	if client == "" {
+		return nil
+	}
+
+	value, ok, err := s.store.Get(ctx, lastPaidKeyPrefix+client)
+	if err != nil || !ok {
+		return nil
+	}
+	lastPaidAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || time.Since(lastPaidAt) > s.gracePeriod.Window {
+		return nil
+	}
+
+	reconciliation := &Reconciliation{
+		ClientKey:           client,
+		LastPaidAt:          lastPaidAt,
+		VerifyError:         verifyErr.Error(),
+		PaymentPayload:      payload,
+		PaymentRequirements: requirements,
+	}
+	for _, hook := range s.reconciliationHooks {
+		hook(ctx, *reconciliation)
+	}
+	return reconciliation
+}
+
+// isTransientVerifyError reports whether a verify error came from the facilitator
+// being unavailable rather than from the payment being rejected
+func isTransientVerifyError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var unavailable *FacilitatorUnavailableError
+	return errors.As(err, &unavailable)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestIsTransientVerifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", &FacilitatorUnavailableError{Operation: "verify", Err: errors.New("connection refused")}, true},
+		{"server error", &FacilitatorUnavailableError{Operation: "verify", StatusCode: 503, Err: errors.New("unavailable")}, true},
+		{"wrapped", fmt.Errorf("primary: %w", &FacilitatorUnavailableError{Operation: "verify", Err: errors.New("timeout")}), true},
+		{"malformed response", x402.NewVerifyError(x402.ErrInvalidResponse, "", "bad json"), false},
+		{"rejected payment", x402.NewVerifyError("insufficient_funds", "0xpayer", ""), false},
+		{"hook error", errors.New("before verify hook failed"), false},
+		{"canceled", context.Canceled, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientVerifyError(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVerifyGracePeriod(t *testing.T) {
+	var verifyErr error
+	facilitator := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			if verifyErr != nil {
+				return nil, verifyErr
+			}
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+	}
+	server := newMonitorTestServer(t, facilitator, false)
+	store := NewMemoryStore()
+	server.SetStore(store).EnableVerifyGracePeriod(VerifyGracePeriodConfig{Window: 5 * time.Minute, ClientKey: apiKeyClient})
+
+	var flagged []Reconciliation
+	server.OnReconciliation(func(ctx context.Context, r Reconciliation) {
+		flagged = append(flagged, r)
+	})
+
+	process := func(apiKey string) HTTPProcessResult {
+		adapter := &mockHTTPAdapter{
+			method:  "GET",
+			path:    "/api",
+			url:     "http://example.com/api",
+			headers: map[string]string{"X-API-Key": apiKey},
+		}
+		reqCtx := HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}
+		adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+		return server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	}
+
+	if result := process("alice"); result.Type != ResultPaymentVerified || result.Reconcile != nil {
+		t.Fatalf("Expected normal verified payment, got %+v", result)
+	}
+
+	verifyErr = &FacilitatorUnavailableError{Operation: "verify", StatusCode: 503, Err: errors.New("unavailable")}
+	result := process("alice")
+	if result.Type != ResultPaymentVerified || result.Reconcile == nil {
+		t.Fatalf("Expected recently-paid client to be graced, got %+v", result)
+	}
+	if len(flagged) != 1 || flagged[0].ClientKey != "key:alice" || flagged[0].PaymentPayload == nil {
+		t.Errorf("Expected reconciliation hook for alice, got %+v", flagged)
+	}
+
+	if result := process("bob"); result.Type != ResultPaymentError {
+		t.Errorf("Expected client without recent payment to be rejected, got %s", result.Type)
+	}
+
+	verifyErr = x402.NewVerifyError("insufficient_funds", "0xpayer", "")
+	if result := process("alice"); result.Type != ResultPaymentError {
+		t.Errorf("Expected rejected payment not to be graced, got %s", result.Type)
+	}
+
+	verifyErr = &FacilitatorUnavailableError{Operation: "verify", Err: errors.New("timeout")}
+	store.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	if result := process("alice"); result.Type != ResultPaymentError {
+		t.Errorf("Expected grace to end after the window, got %s", result.Type)
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// PAYMENT-REQUIRED Header Chunking
+// ============================================================================
+
+const (
+	// PaymentRequiredHeader carries the base64-encoded PaymentRequired response
+	PaymentRequiredHeader = "PAYMENT-REQUIRED"
+
+	// PaymentRequiredChunksHeader carries the number of PAYMENT-REQUIRED-N chunk headers
+	// when the encoded PaymentRequired is split across several headers
+	PaymentRequiredChunksHeader = "PAYMENT-REQUIRED-CHUNKS"
+
+	// DefaultPaymentRequiredHeaderLimit is the largest PAYMENT-REQUIRED header value
+	// sent before the server switches to chunked headers. 8KB is a common
+	// per-header limit for proxies and load balancers.
+	DefaultPaymentRequiredHeaderLimit = 8192
+
+	// maxPaymentRequiredChunks bounds reassembly so a malicious response cannot
+	// make the client look up an unbounded number of headers
+	maxPaymentRequiredChunks = 64
+)
+
+// SetPaymentRequiredHeaderLimit sets the maximum size of a single PAYMENT-REQUIRED
+// header value. Larger values are split across PAYMENT-REQUIRED-1..N headers,
+// with PAYMENT-REQUIRED-CHUNKS holding N. Clients using PaymentRoundTripper
+// reassemble them transparently.
+//
+// A limit of 0 restores DefaultPaymentRequiredHeaderLimit; a negative limit
+// disables chunking.
+func (s *x402HTTPResourceServer) SetPaymentRequiredHeaderLimit(limit int) *x402HTTPResourceServer {
+	s.paymentRequiredHeaderLimit = limit
+	return s
+}
+
+// paymentRequiredHeaders returns the headers carrying an encoded PaymentRequired,
+// chunking the value when it exceeds the configured limit
+func (s *x402HTTPResourceServer) paymentRequiredHeaders(encoded string) map[string]string {
+	limit := s.paymentRequiredHeaderLimit
+	if limit == 0 {
+		limit = DefaultPaymentRequiredHeaderLimit
+	}
+	return chunkHeader(s.HeaderNames().PaymentRequired, encoded, limit)
+}
+
+// chunkHeader splits an encoded header value into <name>-1..N headers, with
+// <name>-CHUNKS holding N
+func chunkHeader(name, encoded string, limit int) map[string]string {
+	if limit <= 0 || len(encoded) <= limit {
+		return map[string]string{name: encoded}
+	}
+
+	headers := make(map[string]string)
+	count := 0
+	for start := 0; start < len(encoded); start += limit {
+		end := start + limit
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		count++
+		headers[fmt.Sprintf("%s-%d", name, count)] = encoded[start:end]
+	}
+	headers[name+"-CHUNKS"] = strconv.Itoa(count)
+	return headers
+}
+
+// readPaymentRequiredHeader returns the encoded PaymentRequired from uppercase-normalized
+// headers, reassembling chunked headers. The boolean reports whether any
+// PAYMENT-REQUIRED header was present.
+func readPaymentRequiredHeader(normalizedHeaders map[string]string) (string, bool, error) {
+	return readChunkedHeader(normalizedHeaders, PaymentRequiredHeader)
+}
+
+// readChunkedHeader returns the value of the named header, reassembling
+// <name>-1..N chunk headers
+func readChunkedHeader(headers map[string]string, name string) (string, bool, error) {
+	if header, exists := headers[name]; exists {
+		return header, true, nil
+	}
+
+	chunksHeader := name + "-CHUNKS"
+	countHeader, exists := headers[chunksHeader]
+	if !exists {
+		return "", false, nil
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countHeader))
+	if err != nil || count < 1 || count > maxPaymentRequiredChunks {
+		return "", true, fmt.Errorf("invalid %s header: %q", chunksHeader, countHeader)
+	}
+
+	var b strings.Builder
+	for i := 1; i <= count; i++ {
+		chunk, exists := headers[fmt.Sprintf("%s-%d", name, i)]
+		if !exists {
+			return "", true, fmt.Errorf("missing %s-%d header (expected %d chunks)", name, i, count)
+		}
+		b.WriteString(chunk)
+	}
+	return b.String(), true, nil
+}
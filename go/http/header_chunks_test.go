@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// largePaymentRequired builds a PaymentRequired with many accepts entries
+func largePaymentRequired(n int) types.PaymentRequired {
+	accepts := make([]types.PaymentRequirements, n)
+	for i := range accepts {
+		accepts[i] = types.PaymentRequirements{
+			Scheme:  "mock",
+			Network: "test:1",
+			Asset:   fmt.Sprintf("TOKEN-%d", i),
+			Amount:  "1000",
+			PayTo:   "0xtest",
+		}
+	}
+	return types.PaymentRequired{X402Version: 2, Error: "Payment required", Accepts: accepts}
+}
+
+func TestChunkPaymentRequiredHeader(t *testing.T) {
+	if headers := chunkHeader(PaymentRequiredHeader, "abcdef", 10); len(headers) != 1 || headers[PaymentRequiredHeader] != "abcdef" {
+		t.Errorf("expected single header below limit, got %v", headers)
+	}
+	if headers := chunkHeader(PaymentRequiredHeader, "abcdef", -1); headers[PaymentRequiredHeader] != "abcdef" {
+		t.Errorf("expected chunking disabled for negative limit, got %v", headers)
+	}
+
+	headers := chunkHeader(PaymentRequiredHeader, "abcdefg", 3)
+	expected := map[string]string{
+		"PAYMENT-REQUIRED-1":      "abc",
+		"PAYMENT-REQUIRED-2":      "def",
+		"PAYMENT-REQUIRED-3":      "g",
+		"PAYMENT-REQUIRED-CHUNKS": "3",
+	}
+	if len(headers) != len(expected) {
+		t.Fatalf("expected %d headers, got %v", len(expected), headers)
+	}
+	for k, v := range expected {
+		if headers[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, headers[k])
+		}
+	}
+
+	reassembled, exists, err := readPaymentRequiredHeader(headers)
+	if err != nil || !exists || reassembled != "abcdefg" {
+		t.Errorf("expected reassembled value, got %q exists=%v err=%v", reassembled, exists, err)
+	}
+}
+
+func TestReadPaymentRequiredHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{"missing chunk", map[string]string{"PAYMENT-REQUIRED-CHUNKS": "2", "PAYMENT-REQUIRED-1": "abc"}},
+		{"invalid count", map[string]string{"PAYMENT-REQUIRED-CHUNKS": "two"}},
+		{"too many chunks", map[string]string{"PAYMENT-REQUIRED-CHUNKS": "1000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, exists, err := readPaymentRequiredHeader(tt.headers)
+			if err == nil || !exists {
+				t.Errorf("expected error, got exists=%v err=%v", exists, err)
+			}
+		})
+	}
+
+	if _, exists, err := readPaymentRequiredHeader(map[string]string{}); exists || err != nil {
+		t.Errorf("expected no header, got exists=%v err=%v", exists, err)
+	}
+}
+
+func TestCreateHTTPResponseChunksLargePaymentRequired(t *testing.T) {
+	server := Newx402HTTPResourceServer(nil).SetPaymentRequiredHeaderLimit(512)
+	required := largePaymentRequired(50)
+
+	response, err := server.createHTTPResponseV2(required, false, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, exists := response.Headers[PaymentRequiredHeader]; exists {
+		t.Fatal("expected PAYMENT-REQUIRED to be chunked")
+	}
+	if response.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected content type to be preserved, got %v", response.Headers)
+	}
+	for k, v := range response.Headers {
+		if strings.HasPrefix(k, PaymentRequiredHeader+"-") && len(v) > 512 {
+			t.Errorf("header %s exceeds limit: %d bytes", k, len(v))
+		}
+	}
+
+	decoded, err := Newx402HTTPClient(x402.Newx402Client()).GetPaymentRequiredResponse(response.Headers, nil)
+	if err != nil {
+		t.Fatalf("failed to reassemble: %v", err)
+	}
+	if len(decoded.Accepts) != 50 {
+		t.Errorf("expected 50 accepts, got %d", len(decoded.Accepts))
+	}
+
+	// Default limit leaves small responses untouched
+	response, _ = Newx402HTTPResourceServer(nil).createHTTPResponseV2(largePaymentRequired(1), false, nil, "", nil)
+	if _, exists := response.Headers[PaymentRequiredHeader]; !exists {
+		t.Error("expected single PAYMENT-REQUIRED header below default limit")
+	}
+}
+
+func TestPaymentRoundTripperReassemblesChunkedHeaders(t *testing.T) {
+	reqJSON, _ := json.Marshal(largePaymentRequired(40))
+	chunked := chunkHeader(PaymentRequiredHeader, base64.StdEncoding.EncodeToString(reqJSON), 256)
+
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.Header.Get("PAYMENT-SIGNATURE") == "" {
+			for k, v := range chunked {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client))
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || callCount != 2 {
+		t.Errorf("expected paid retry, got status %d after %d calls", resp.StatusCode, callCount)
+	}
+}
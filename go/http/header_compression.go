@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/coinbase/x402/go/http/headers"
+)
+
+// ============================================================================
+// Payment Header Compression
+// ============================================================================
+
+const (
+	// PaymentAcceptEncodingHeader lists the payment header encodings the sender
+	// can decode (e.g. "gzip, cbor-v1"). Clients send it on requests; servers
+	// with compression or CBOR enabled send it on 402 responses.
+	PaymentAcceptEncodingHeader = "PAYMENT-ACCEPT-ENCODING"
+
+	// PaymentEncodingHeader marks how the payment header in the same message
+	// (PAYMENT-REQUIRED or PAYMENT-SIGNATURE) is encoded
+	PaymentEncodingHeader = headers.PaymentEncoding
+
+	// PaymentEncodingGzip marks a gzip+base64 encoded payment header
+	PaymentEncodingGzip = headers.EncodingGzip
+
+	// PaymentEncodingCBOR marks a compact CBOR+base64 encoded payment header
+	// (see headers.EncodingCBOR). The value carries the profile version, so
+	// only peers sharing a version negotiate it.
+	PaymentEncodingCBOR = headers.EncodingCBOR
+
+	// maxDecompressedPaymentHeader bounds decompression to guard against gzip bombs
+	maxDecompressedPaymentHeader = headers.MaxDecompressedSize
+)
+
+// EnablePaymentHeaderCompression lets the server gzip PAYMENT-REQUIRED for clients
+// that send PAYMENT-ACCEPT-ENCODING: gzip, and advertises gzip support so clients
+// may compress PAYMENT-SIGNATURE. Compressed PAYMENT-SIGNATURE headers are
+// always accepted, whether or not compression is enabled.
+func (s *x402HTTPResourceServer) EnablePaymentHeaderCompression() *x402HTTPResourceServer {
+	s.compressPaymentHeaders = true
+	return s
+}
+
+// EnableCBORPaymentHeaders lets the server send PAYMENT-REQUIRED as compact CBOR
+// to clients that list cbor-v1 in PAYMENT-ACCEPT-ENCODING, and advertises CBOR
+// support so clients may send PAYMENT-SIGNATURE as CBOR. CBOR PAYMENT-SIGNATURE
+// headers are always accepted, whether or not this is enabled.
+func (s *x402HTTPResourceServer) EnableCBORPaymentHeaders() *x402HTTPResourceServer {
+	s.cborPaymentHeaders = true
+	return s
+}
+
+// paymentHeaderEncodings returns the encodings the server offers, in order
+func (s *x402HTTPResourceServer) paymentHeaderEncodings() []string {
+	var encodings []string
+	if s.compressPaymentHeaders {
+		encodings = append(encodings, PaymentEncodingGzip)
+	}
+	if s.cborPaymentHeaders {
+		encodings = append(encodings, PaymentEncodingCBOR)
+	}
+	return encodings
+}
+
+// applyPaymentHeaderCompression rewrites the PAYMENT-REQUIRED header(s) of a 402
+// response in the smallest encoding the server offers and the client accepts
+func (s *x402HTTPResourceServer) applyPaymentHeaderCompression(response *HTTPResponseInstructions, adapter HTTPAdapter) {
+	encodings := s.paymentHeaderEncodings()
+	if len(encodings) == 0 || response == nil || response.Headers == nil {
+		return
+	}
+
+	name := s.HeaderNames().PaymentRequired
+	encoded, exists, err := readChunkedHeader(response.Headers, name)
+	if err != nil || !exists {
+		return
+	}
+	response.Headers[PaymentAcceptEncodingHeader] = strings.Join(encodings, ", ")
+
+	if adapter == nil {
+		return
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return
+	}
+	smallest, encoding := smallestPaymentEncoding(raw, encoded, encodings, adapter.GetHeader(PaymentAcceptEncodingHeader))
+	if encoding == "" {
+		return
+	}
+
+	for k := range response.Headers {
+		if k == name || strings.HasPrefix(k, name+"-") {
+			delete(response.Headers, k)
+		}
+	}
+	for k, v := range s.paymentRequiredHeaders(smallest) {
+		response.Headers[k] = v
+	}
+	response.Headers[PaymentEncodingHeader] = encoding
+}
+
+// smallestPaymentEncoding encodes raw header JSON in each offered encoding the
+// peer accepts and returns the smallest result, or an empty encoding when none
+// beats the plain header
+func smallestPaymentEncoding(raw []byte, plain string, offered []string, accepted string) (string, string) {
+	smallest, smallestEncoding := plain, ""
+	for _, encoding := range offered {
+		if !acceptsPaymentEncoding(accepted, encoding) {
+			continue
+		}
+		candidate, err := headers.EncodeBytes(raw, encoding)
+		if err == nil && len(candidate) < len(smallest) {
+			smallest, smallestEncoding = candidate, encoding
+		}
+	}
+	return smallest, smallestEncoding
+}
+
+// acceptsPaymentEncoding reports whether a PAYMENT-ACCEPT-ENCODING value includes encoding
+func acceptsPaymentEncoding(value string, encoding string) bool {
+	for _, accepted := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(accepted), encoding) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodePaymentHeaderBytes base64-decodes a payment header to JSON according to
+// the message's PAYMENT-ENCODING header (gzip or cbor-v1)
+func decodePaymentHeaderBytes(header string, encoding string) ([]byte, error) {
+	return headers.LenientCodec.DecodeBytes(header, encoding)
+}
+
+// gzipBase64 compresses data and base64-encodes the result
+func gzipBase64(data []byte) (string, error) {
+	return headers.EncodeBytes(data, headers.EncodingGzip)
+}
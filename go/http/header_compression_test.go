@@ -0,0 +1,249 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestDecodePaymentHeaderBytes(t *testing.T) {
+	raw := []byte(`{"x402Version":2}`)
+
+	plain, err := decodePaymentHeaderBytes(base64.StdEncoding.EncodeToString(raw), "")
+	if err != nil || !bytes.Equal(plain, raw) {
+		t.Errorf("expected plain decode, got %q err=%v", plain, err)
+	}
+
+	compressed, err := gzipBase64(raw)
+	if err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	decoded, err := decodePaymentHeaderBytes(compressed, "GZIP")
+	if err != nil || !bytes.Equal(decoded, raw) {
+		t.Errorf("expected gzip decode, got %q err=%v", decoded, err)
+	}
+
+	if _, err := decodePaymentHeaderBytes(compressed, "br"); err == nil {
+		t.Error("expected unsupported encoding error")
+	}
+	if _, err := decodePaymentHeaderBytes(base64.StdEncoding.EncodeToString(raw), "gzip"); err == nil {
+		t.Error("expected invalid gzip error")
+	}
+}
+
+func TestDecodePaymentHeaderBytesRejectsGzipBomb(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write(make([]byte, maxDecompressedPaymentHeader+1))
+	_ = writer.Close()
+
+	if _, err := decodePaymentHeaderBytes(base64.StdEncoding.EncodeToString(buf.Bytes()), "gzip"); err == nil {
+		t.Error("expected oversized payload to be rejected")
+	}
+}
+
+func TestApplyPaymentHeaderCompression(t *testing.T) {
+	required := largePaymentRequired(30)
+	gzipAdapter := &mockHTTPAdapter{headers: map[string]string{PaymentAcceptEncodingHeader: "gzip"}}
+
+	// Disabled: response untouched
+	server := Newx402HTTPResourceServer(nil)
+	response, _ := server.createHTTPResponseV2(required, false, nil, "", nil)
+	server.applyPaymentHeaderCompression(response, gzipAdapter)
+	if _, exists := response.Headers[PaymentEncodingHeader]; exists {
+		t.Error("compression should be opt-in")
+	}
+
+	// Enabled but client did not advertise gzip: only advertise server support
+	server = Newx402HTTPResourceServer(nil).EnablePaymentHeaderCompression()
+	response, _ = server.createHTTPResponseV2(required, false, nil, "", nil)
+	server.applyPaymentHeaderCompression(response, &mockHTTPAdapter{})
+	if response.Headers[PaymentAcceptEncodingHeader] != PaymentEncodingGzip {
+		t.Error("expected server to advertise gzip support")
+	}
+	if _, exists := response.Headers[PaymentEncodingHeader]; exists {
+		t.Error("should not compress for clients without gzip support")
+	}
+
+	// Enabled and negotiated: compressed and decodable by the client
+	response, _ = server.createHTTPResponseV2(required, false, nil, "", nil)
+	uncompressedSize := len(response.Headers[PaymentRequiredHeader])
+	server.applyPaymentHeaderCompression(response, gzipAdapter)
+	if response.Headers[PaymentEncodingHeader] != PaymentEncodingGzip {
+		t.Fatal("expected compressed PAYMENT-REQUIRED")
+	}
+	if len(response.Headers[PaymentRequiredHeader]) >= uncompressedSize {
+		t.Errorf("expected compressed header to be smaller than %d bytes", uncompressedSize)
+	}
+
+	decoded, err := Newx402HTTPClient(x402.Newx402Client()).GetPaymentRequiredResponse(response.Headers, nil)
+	if err != nil {
+		t.Fatalf("failed to decode compressed header: %v", err)
+	}
+	if len(decoded.Accepts) != 30 {
+		t.Errorf("expected 30 accepts, got %d", len(decoded.Accepts))
+	}
+}
+
+func TestExtractPaymentV2Compressed(t *testing.T) {
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted:    types.PaymentRequirements{Scheme: "exact", Network: "eip155:1"},
+	}
+	payloadJSON, _ := json.Marshal(payload)
+	compressed, _ := gzipBase64(payloadJSON)
+
+	server := Newx402HTTPResourceServer(nil)
+	extracted, err := server.extractPaymentV2(&mockHTTPAdapter{headers: map[string]string{
+		"PAYMENT-SIGNATURE":   compressed,
+		PaymentEncodingHeader: "gzip",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if extracted == nil || extracted.Accepted.Network != "eip155:1" {
+		t.Errorf("expected decoded payload, got %+v", extracted)
+	}
+}
+
+func TestPaymentRoundTripperNegotiatesCompression(t *testing.T) {
+	required := largePaymentRequired(20)
+	required.Accepts[0].Extra = map[string]interface{}{"memo": strings.Repeat("x402 ", 100)}
+	reqJSON, _ := json.Marshal(required)
+	compressed, _ := gzipBase64(reqJSON)
+
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.Header.Get("PAYMENT-SIGNATURE") == "" {
+			if r.Header.Get(PaymentAcceptEncodingHeader) != PaymentEncodingGzip {
+				t.Error("expected client to advertise gzip support")
+			}
+			w.Header().Set(PaymentRequiredHeader, compressed)
+			w.Header().Set(PaymentEncodingHeader, PaymentEncodingGzip)
+			w.Header().Set(PaymentAcceptEncodingHeader, PaymentEncodingGzip)
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+
+		if r.Header.Get(PaymentEncodingHeader) != PaymentEncodingGzip {
+			t.Error("expected compressed PAYMENT-SIGNATURE")
+		}
+		payloadJSON, err := decodePaymentHeaderBytes(r.Header.Get("PAYMENT-SIGNATURE"), r.Header.Get(PaymentEncodingHeader))
+		if err != nil {
+			t.Errorf("failed to decode signature: %v", err)
+		}
+		var payload types.PaymentPayload
+		if err := json.Unmarshal(payloadJSON, &payload); err != nil || payload.Accepted.Asset != "TOKEN-0" {
+			t.Errorf("unexpected payload %s: %v", payloadJSON, err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client))
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || callCount != 2 {
+		t.Errorf("expected paid retry, got status %d after %d calls", resp.StatusCode, callCount)
+	}
+	if req.Header.Get(PaymentAcceptEncodingHeader) != "" {
+		t.Error("round tripper must not modify the caller's request")
+	}
+}
+
+func TestApplyPaymentHeaderCBOR(t *testing.T) {
+	required := largePaymentRequired(5)
+	server := Newx402HTTPResourceServer(nil).EnablePaymentHeaderCompression().EnableCBORPaymentHeaders()
+
+	// Client on a different CBOR profile version: only advertise
+	response, _ := server.createHTTPResponseV2(required, false, nil, "", nil)
+	server.applyPaymentHeaderCompression(response, &mockHTTPAdapter{headers: map[string]string{PaymentAcceptEncodingHeader: "cbor-v2"}})
+	if response.Headers[PaymentAcceptEncodingHeader] != "gzip, cbor-v1" {
+		t.Errorf("unexpected advertised encodings %q", response.Headers[PaymentAcceptEncodingHeader])
+	}
+	if _, exists := response.Headers[PaymentEncodingHeader]; exists {
+		t.Error("should not re-encode for clients without a shared encoding")
+	}
+
+	// Client accepting CBOR: re-encoded and decodable
+	response, _ = server.createHTTPResponseV2(required, false, nil, "", nil)
+	plainSize := len(response.Headers[PaymentRequiredHeader])
+	server.applyPaymentHeaderCompression(response, &mockHTTPAdapter{headers: map[string]string{PaymentAcceptEncodingHeader: "CBOR-v1"}})
+	if response.Headers[PaymentEncodingHeader] != PaymentEncodingCBOR {
+		t.Fatal("expected CBOR PAYMENT-REQUIRED")
+	}
+	if len(response.Headers[PaymentRequiredHeader]) >= plainSize {
+		t.Errorf("expected CBOR header to be smaller than %d bytes", plainSize)
+	}
+	decoded, err := Newx402HTTPClient(x402.Newx402Client()).GetPaymentRequiredResponse(response.Headers, nil)
+	if err != nil || len(decoded.Accepts) != 5 {
+		t.Fatalf("failed to decode CBOR header: %v", err)
+	}
+}
+
+func TestPaymentRoundTripperNegotiatesCBOR(t *testing.T) {
+	required := largePaymentRequired(1)
+	reqJSON, _ := json.Marshal(required)
+	encoded, _ := headers.EncodeBytes(reqJSON, PaymentEncodingCBOR)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PAYMENT-SIGNATURE") == "" {
+			if r.Header.Get(PaymentAcceptEncodingHeader) != "gzip, cbor-v1" {
+				t.Errorf("unexpected advertised encodings %q", r.Header.Get(PaymentAcceptEncodingHeader))
+			}
+			w.Header().Set(PaymentRequiredHeader, encoded)
+			w.Header().Set(PaymentEncodingHeader, PaymentEncodingCBOR)
+			w.Header().Set(PaymentAcceptEncodingHeader, PaymentEncodingCBOR)
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+
+		if r.Header.Get(PaymentEncodingHeader) != PaymentEncodingCBOR {
+			t.Error("expected CBOR PAYMENT-SIGNATURE")
+		}
+		payloadJSON, err := decodePaymentHeaderBytes(r.Header.Get("PAYMENT-SIGNATURE"), r.Header.Get(PaymentEncodingHeader))
+		if err != nil {
+			t.Errorf("failed to decode signature: %v", err)
+		}
+		var payload types.PaymentPayload
+		if err := json.Unmarshal(payloadJSON, &payload); err != nil || payload.Accepted.Asset != "TOKEN-0" {
+			t.Errorf("unexpected payload %s: %v", payloadJSON, err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client).EnableCBORPaymentHeaders())
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected paid retry, got status %d", resp.StatusCode)
+	}
+}
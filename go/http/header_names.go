@@ -0,0 +1,79 @@
+package http
+
+import "strings"
+
+// ============================================================================
+// Configurable Header Names
+// ============================================================================
+
+// HeaderNames names the headers carrying payments, challenges and settlements.
+// Empty fields keep the current names (PAYMENT-SIGNATURE, PAYMENT-REQUIRED
+// and PAYMENT-RESPONSE).
+type HeaderNames struct {
+	// PaymentSignature carries the client's payment payload
+	PaymentSignature string
+
+	// PaymentRequired carries the 402 challenge. Chunked challenges use
+	// <PaymentRequired>-1..N and <PaymentRequired>-CHUNKS.
+	PaymentRequired string
+
+	// PaymentResponse carries the settlement result
+	PaymentResponse string
+}
+
+var (
+	// DefaultHeaderNames are the current header names
+	DefaultHeaderNames = HeaderNames{
+		PaymentSignature: "PAYMENT-SIGNATURE",
+		PaymentRequired:  PaymentRequiredHeader,
+		PaymentResponse:  "PAYMENT-RESPONSE",
+	}
+
+	// LegacyHeaderNames are the names used by deployments predating
+	// PAYMENT-SIGNATURE: X-PAYMENT and X-PAYMENT-RESPONSE. Challenges keep
+	// PAYMENT-REQUIRED, which those deployments never sent.
+	LegacyHeaderNames = HeaderNames{
+		PaymentSignature: "X-PAYMENT",
+		PaymentResponse:  "X-PAYMENT-RESPONSE",
+	}
+)
+
+// withDefaults fills empty names with the current ones
+func (n HeaderNames) withDefaults() HeaderNames {
+	return HeaderNames{
+		PaymentSignature: orDefault(n.PaymentSignature, DefaultHeaderNames.PaymentSignature),
+		PaymentRequired:  orDefault(n.PaymentRequired, DefaultHeaderNames.PaymentRequired),
+		PaymentResponse:  orDefault(n.PaymentResponse, DefaultHeaderNames.PaymentResponse),
+	}
+}
+
+// SetHeaderNames renames the payment headers the server reads and writes,
+// for interop with older deployments. Payments are still accepted under
+// PAYMENT-SIGNATURE, so clients can migrate at their own pace.
+//
+// A facilitator client's profile can supply the names:
+//
+//	server.SetHeaderNames(facilitatorClient.HeaderNames())
+func (s *x402HTTPResourceServer) SetHeaderNames(names HeaderNames) *x402HTTPResourceServer {
+	s.headerNames = names.withDefaults()
+	return s
+}
+
+// HeaderNames returns the payment header names in use
+func (s *x402HTTPResourceServer) HeaderNames() HeaderNames {
+	return s.headerNames.withDefaults()
+}
+
+// paymentSignatureHeader returns the payment presented with the request,
+// under the configured name or else PAYMENT-SIGNATURE
+func (s *x402HTTPResourceServer) paymentSignatureHeader(adapter HTTPAdapter) string {
+	for _, name := range []string{s.HeaderNames().PaymentSignature, DefaultHeaderNames.PaymentSignature} {
+		if header := adapter.GetHeader(name); header != "" {
+			return header
+		}
+		if header := adapter.GetHeader(strings.ToLower(name)); header != "" {
+			return header
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestLegacyHeaderNames(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /data": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}}},
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).SetHeaderNames(LegacyHeaderNames)
+	_ = server.Initialize(context.Background())
+
+	request := func(headers map[string]string) HTTPProcessResult {
+		return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+			Adapter: &mockHTTPAdapter{method: "GET", path: "/data", url: "https://api.example.com/data", headers: headers},
+			Path:    "/data",
+			Method:  "GET",
+		}, nil)
+	}
+
+	unpaid := request(nil)
+	required, err := decodePaymentRequiredHeader(unpaid.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Expected challenges to keep PAYMENT-REQUIRED, got %v", unpaid.Response.Headers)
+	}
+	payloadJSON, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"sig": "test"}, Accepted: required.Accepts[0]})
+	encoded := base64.StdEncoding.EncodeToString(payloadJSON)
+
+	for _, name := range []string{"X-PAYMENT", "PAYMENT-SIGNATURE"} {
+		result := request(map[string]string{name: encoded})
+		if result.Type != ResultPaymentVerified {
+			t.Fatalf("Expected a payment under %s to verify, got %s (%+v)", name, result.Type, result.Response)
+		}
+		settlement := server.ProcessSettlement(context.Background(), *result.PaymentPayload, *result.PaymentRequirements)
+		if _, ok := settlement.Headers["X-PAYMENT-RESPONSE"]; !ok || len(settlement.Headers) != 1 {
+			t.Errorf("Expected the settlement under X-PAYMENT-RESPONSE, got %v", settlement.Headers)
+		}
+	}
+}
+
+func TestCustomPaymentRequiredHeaderName(t *testing.T) {
+	server := Newx402HTTPResourceServer(RoutesConfig{}).
+		SetHeaderNames(HeaderNames{PaymentRequired: "X-402-REQUIRED"}).
+		SetPaymentRequiredHeaderLimit(3)
+
+	headers := server.paymentRequiredHeaders("abcdefg")
+	if headers["X-402-REQUIRED-CHUNKS"] != "3" || headers["X-402-REQUIRED-1"] != "abc" {
+		t.Fatalf("Expected chunks under the custom name, got %v", headers)
+	}
+	if reassembled, exists, err := readChunkedHeader(headers, "X-402-REQUIRED"); err != nil || !exists || reassembled != "abcdefg" {
+		t.Errorf("Expected the chunks to reassemble, got %q exists=%v err=%v", reassembled, exists, err)
+	}
+
+	if names := server.HeaderNames(); names.PaymentSignature != "PAYMENT-SIGNATURE" || names.PaymentResponse != "PAYMENT-RESPONSE" {
+		t.Errorf("Expected unset names to keep the defaults, got %+v", names)
+	}
+}
+
+func TestFacilitatorProfileHeaderNames(t *testing.T) {
+	if names := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "https://facilitator.example"}).HeaderNames(); names != DefaultHeaderNames {
+		t.Errorf("Expected the default names, got %+v", names)
+	}
+
+	profile := WireProfile{Name: "legacy", Headers: LegacyHeaderNames}
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: "https://facilitator.example", Profile: profile})
+	names := client.HeaderNames()
+	if names.PaymentSignature != "X-PAYMENT" || names.PaymentResponse != "X-PAYMENT-RESPONSE" || names.PaymentRequired != "PAYMENT-REQUIRED" {
+		t.Errorf("Expected the profile's names, got %+v", names)
+	}
+}
@@ -0,0 +1,450 @@
+package headers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// ============================================================================
+// CBOR Encoding
+// ============================================================================
+
+// EncodingCBOR marks a payment header as compact CBOR (profile version 1)
+// instead of JSON. The profile is plain RFC 8949 CBOR over the JSON data model
+// with two size reductions:
+//
+//   - well-known x402 field names are map keys 1..n from a fixed dictionary
+//     (cborDictionaryV1) instead of text
+//   - lowercase "0x"-prefixed hex strings (signatures, nonces, hashes) are
+//     byte strings with tag 23
+//
+// Changing the dictionary requires a new profile version and encoding value,
+// so senders and receivers negotiate the versions they share through
+// PAYMENT-ACCEPT-ENCODING.
+const EncodingCBOR = "cbor-v1"
+
+// cborDictionaryV1 maps field names to integer keys (index + 1). It is part of
+// the wire format: entries must never be reordered or removed.
+var cborDictionaryV1 = []string{
+	"x402Version", "resource", "accepts", "accepted", "payload", "extensions", "error",
+	"scheme", "network", "amount", "asset", "payTo", "maxTimeoutSeconds", "extra",
+	"url", "description", "mimeType", "outputSchema",
+	"signature", "authorization", "from", "to", "value", "validAfter", "validBefore", "nonce",
+	"name", "version", "transaction",
+	"success", "errorReason", "errorMessage", "payer",
+	"permit2Authorization", "permitted", "token", "spender", "deadline", "witness",
+}
+
+var cborDictionaryKeys = func() map[string]uint64 {
+	keys := make(map[string]uint64, len(cborDictionaryV1))
+	for i, name := range cborDictionaryV1 {
+		keys[name] = uint64(i + 1)
+	}
+	return keys
+}()
+
+// maxCBORDepth bounds nesting when decoding untrusted input
+const maxCBORDepth = 32
+
+// CBOR major types, simple values, and tags
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTag      = 6
+	cborSimple   = 7
+
+	cborFalse = 20
+	cborTrue  = 21
+	cborNull  = 22
+
+	// cborTagHex marks a byte string that converts to lowercase "0x" hex
+	cborTagHex = 23
+)
+
+// EncodeCBOR converts JSON to plain RFC 8949 CBOR with text keys, e.g. for
+// application/x402+cbor response bodies. Map keys are in deterministic
+// (bytewise) order.
+func EncodeCBOR(jsonData []byte) ([]byte, error) {
+	return jsonToCBOR(jsonData, false)
+}
+
+// DecodeCBOR converts CBOR produced by EncodeCBOR or the EncodingCBOR profile
+// back to JSON
+func DecodeCBOR(data []byte) ([]byte, error) {
+	decoder := cborDecoder{data: data}
+	value, err := decoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CBOR: %w", err)
+	}
+	if decoder.pos != len(data) {
+		return nil, errors.New("invalid CBOR: trailing data")
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CBOR: %w", err)
+	}
+	return out, nil
+}
+
+// encodeCompactCBOR converts JSON to the EncodingCBOR profile
+func encodeCompactCBOR(jsonData []byte) ([]byte, error) {
+	return jsonToCBOR(jsonData, true)
+}
+
+func jsonToCBOR(jsonData []byte, compact bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	encoder := cborEncoder{compact: compact}
+	if err := encoder.encode(value); err != nil {
+		return nil, err
+	}
+	return encoder.buf.Bytes(), nil
+}
+
+// ----------------------------------------------------------------------------
+// Encoder
+// ----------------------------------------------------------------------------
+
+type cborEncoder struct {
+	buf     bytes.Buffer
+	compact bool
+}
+
+func (e *cborEncoder) encode(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.buf.WriteByte(cborSimple<<5 | cborNull)
+	case bool:
+		if v {
+			e.buf.WriteByte(cborSimple<<5 | cborTrue)
+		} else {
+			e.buf.WriteByte(cborSimple<<5 | cborFalse)
+		}
+	case string:
+		if raw, ok := e.hexBytes(v); ok {
+			writeCBORHead(&e.buf, cborTag, cborTagHex)
+			writeCBORHead(&e.buf, cborBytes, uint64(len(raw)))
+			e.buf.Write(raw)
+			return nil
+		}
+		writeCBORHead(&e.buf, cborText, uint64(len(v)))
+		e.buf.WriteString(v)
+	case json.Number:
+		return e.encodeNumber(v)
+	case []interface{}:
+		writeCBORHead(&e.buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		return e.encodeMap(v)
+	default:
+		return fmt.Errorf("unsupported CBOR value %T", value)
+	}
+	return nil
+}
+
+func (e *cborEncoder) encodeNumber(n json.Number) error {
+	if i, err := n.Int64(); err == nil {
+		if i >= 0 {
+			writeCBORHead(&e.buf, cborUnsigned, uint64(i))
+		} else {
+			writeCBORHead(&e.buf, cborNegative, uint64(-(i + 1)))
+		}
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		writeCBORHead(&e.buf, cborUnsigned, u)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("unsupported number %s: %w", n, err)
+	}
+	e.buf.WriteByte(cborSimple<<5 | 27)
+	_ = binary.Write(&e.buf, binary.BigEndian, math.Float64bits(f))
+	return nil
+}
+
+func (e *cborEncoder) encodeMap(m map[string]interface{}) error {
+	type entry struct {
+		key   []byte
+		value interface{}
+	}
+	entries := make([]entry, 0, len(m))
+	for name, value := range m {
+		var key bytes.Buffer
+		if id, ok := cborDictionaryKeys[name]; ok && e.compact {
+			writeCBORHead(&key, cborUnsigned, id)
+		} else {
+			writeCBORHead(&key, cborText, uint64(len(name)))
+			key.WriteString(name)
+		}
+		entries = append(entries, entry{key: key.Bytes(), value: value})
+	}
+	// Bytewise order of the encoded keys (RFC 8949 core deterministic encoding)
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+	writeCBORHead(&e.buf, cborMap, uint64(len(entries)))
+	for _, entry := range entries {
+		e.buf.Write(entry.key)
+		if err := e.encode(entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hexBytes returns the bytes of a lowercase, even-length "0x" hex string in compact mode
+func (e *cborEncoder) hexBytes(s string) ([]byte, bool) {
+	if !e.compact || len(s) < 4 || len(s)%2 != 0 || s[:2] != "0x" {
+		return nil, false
+	}
+	for _, c := range s[2:] {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, false
+		}
+	}
+	raw, err := hex.DecodeString(s[2:])
+	return raw, err == nil
+}
+
+// writeCBORHead writes a major type with its argument in the shortest form
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		_ = binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Decoder
+// ----------------------------------------------------------------------------
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads an initial byte and its argument. Indefinite lengths are not
+// part of the profile and are rejected.
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, 0, fmt.Errorf("unsupported additional info %d", info)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+	var n uint64
+	for _, b := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.pos += size
+	return major, info, n, nil
+}
+
+// take returns the next n bytes
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.New("unexpected end of data")
+	}
+	out := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return out, nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("nesting too deep")
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case cborNegative:
+		value := new(big.Int).SetUint64(n)
+		return json.Number(value.Neg(value.Add(value, big.NewInt(1))).String()), nil
+	case cborText:
+		text, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	case cborTag:
+		if n != cborTagHex {
+			return nil, fmt.Errorf("unsupported tag %d", n)
+		}
+		major, _, length, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != cborBytes {
+			return nil, errors.New("hex tag must wrap a byte string")
+		}
+		raw, err := d.take(length)
+		if err != nil {
+			return nil, err
+		}
+		return "0x" + hex.EncodeToString(raw), nil
+	case cborArray:
+		// Every item takes at least one byte, which bounds the allocation
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, errors.New("unexpected end of data")
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cborMap:
+		if n > uint64(len(d.data)-d.pos)/2 {
+			return nil, errors.New("unexpected end of data")
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.key()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := m[key]; exists {
+				return nil, fmt.Errorf("duplicate map key %q", key)
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case cborSimple:
+		return d.simple(info, n)
+	default:
+		return nil, fmt.Errorf("unsupported major type %d", major)
+	}
+}
+
+// key reads a map key: text, or a dictionary index
+func (d *cborDecoder) key() (string, error) {
+	major, _, n, err := d.head()
+	if err != nil {
+		return "", err
+	}
+	switch major {
+	case cborText:
+		text, err := d.take(n)
+		return string(text), err
+	case cborUnsigned:
+		if n == 0 || n > uint64(len(cborDictionaryV1)) {
+			return "", fmt.Errorf("unknown dictionary key %d", n)
+		}
+		return cborDictionaryV1[n-1], nil
+	default:
+		return "", fmt.Errorf("map keys must be text or dictionary indexes, got major type %d", major)
+	}
+}
+
+// simple decodes booleans, null, and floats
+func (d *cborDecoder) simple(info byte, n uint64) (interface{}, error) {
+	var f float64
+	switch info {
+	case cborFalse:
+		return false, nil
+	case cborTrue:
+		return true, nil
+	case cborNull:
+		return nil, nil
+	case 25:
+		f = float16ToFloat64(uint16(n))
+	case 26:
+		f = float64(math.Float32frombits(uint32(n)))
+	case 27:
+		f = math.Float64frombits(n)
+	default:
+		return nil, fmt.Errorf("unsupported simple value %d", info)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.New("NaN and infinity have no JSON representation")
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// float16ToFloat64 converts an IEEE 754 half-precision value
+func float16ToFloat64(h uint16) float64 {
+	exponent := int(h>>10) & 0x1f
+	mantissa := float64(h & 0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if h&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
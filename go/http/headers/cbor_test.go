@@ -0,0 +1,145 @@
+package headers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestEncodeCBOR(t *testing.T) {
+	// Vectors from RFC 8949 Appendix A
+	tests := []struct {
+		json string
+		hex  string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`1000000`, "1a000f4240"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`false`, "f4"},
+		{`true`, "f5"},
+		{`null`, "f6"},
+		{`"IETF"`, "6449455446"},
+		{`[1,[2,3]]`, "8201820203"},
+		{`{"b":[2,3],"a":1}`, "a26161016162820203"},
+		{`{"aa":1,"b":2}`, "a261620262616101"},
+	}
+	for _, tt := range tests {
+		got, err := EncodeCBOR([]byte(tt.json))
+		if err != nil {
+			t.Fatalf("EncodeCBOR(%s) failed: %v", tt.json, err)
+		}
+		if hex.EncodeToString(got) != tt.hex {
+			t.Errorf("EncodeCBOR(%s) = %x, want %s", tt.json, got, tt.hex)
+		}
+		decoded, err := DecodeCBOR(got)
+		if err != nil {
+			t.Fatalf("DecodeCBOR(%x) failed: %v", got, err)
+		}
+		assertSameJSON(t, decoded, []byte(tt.json))
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// Half and single precision floats from RFC 8949 Appendix A
+	for hexValue, want := range map[string]string{"f93c00": "1", "f9c400": "-4", "f90001": "5.960464477539063e-08", "fa47c35000": "100000"} {
+		data, _ := hex.DecodeString(hexValue)
+		decoded, err := DecodeCBOR(data)
+		if err != nil || string(decoded) != want {
+			t.Errorf("DecodeCBOR(%s) = %s, %v; want %s", hexValue, decoded, err, want)
+		}
+	}
+
+	for name, hexValue := range map[string]string{
+		"indefinite length": "9f01ff",
+		"truncated":         "6449",
+		"trailing data":     "0000",
+		"nan":               "f97e00",
+		"byte string":       "4101",
+		"unknown tag":       "c11a514b67b0",
+		"duplicate key":     "a2616101616102",
+		"unknown dict key":  "a1186400",
+		"huge array":        "9bffffffffffffffff",
+	} {
+		data, _ := hex.DecodeString(hexValue)
+		if _, err := DecodeCBOR(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	deep := make([]byte, maxCBORDepth+2)
+	for i := range deep {
+		deep[i] = 0x81
+	}
+	if _, err := DecodeCBOR(append(deep, 0x00)); err == nil {
+		t.Error("expected nesting depth error")
+	}
+}
+
+func TestCBOREncoding(t *testing.T) {
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted: types.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:8453",
+			Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			Amount:            "10000",
+			PayTo:             "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+			MaxTimeoutSeconds: 300,
+			Extra:             map[string]interface{}{"name": "USD Coin", "version": "2"},
+		},
+		Payload: map[string]interface{}{
+			"signature": "0x2d6a7588d6acca505cbf0d9a4a227e0c52c6c34008c8e8986a1283259764173608a2ce6496642e377d6da8dbbf5836e9bd15092f9ecab05ded3d6293af148b571c",
+			"authorization": map[string]interface{}{
+				"from":        "0x857b06519E91e3A54538791bDbb0E22373e36b66",
+				"to":          "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				"value":       "10000",
+				"validAfter":  "1740672089",
+				"validBefore": "1740672154",
+				"nonce":       "0xf3746613c2d920b5fdabc0856f2aeb2d4f88ee6037b8cc5d04a71a4462f13480",
+			},
+		},
+	}
+	raw, _ := json.Marshal(payload)
+
+	encoded, err := EncodeBytes(raw, "CBOR-V1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plain := base64.StdEncoding.EncodeToString(raw)
+	if len(encoded) > len(plain)*65/100 {
+		t.Errorf("expected CBOR to save at least 35%%: %d vs %d bytes", len(encoded), len(plain))
+	}
+
+	decoded, err := StrictCodec.DecodePaymentSignature(encoded, EncodingCBOR)
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Payload, payload.Payload) || decoded.Accepted.PayTo != payload.Accepted.PayTo {
+		t.Errorf("round trip mismatch: %+v", decoded)
+	}
+	if version, err := DetectVersion(encoded, EncodingCBOR); err != nil || version != 2 {
+		t.Errorf("DetectVersion = %d, %v", version, err)
+	}
+}
+
+func assertSameJSON(t *testing.T, got, want []byte) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	_ = json.Unmarshal(want, &wantValue)
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("JSON mismatch: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,319 @@
+// Package headers encodes and decodes the x402 HTTP headers (PAYMENT-REQUIRED,
+// PAYMENT-SIGNATURE, and PAYMENT-RESPONSE) without depending on the rest of
+// go/http, so custom clients, servers, and proxies produce and accept exactly
+// what the SDK does.
+//
+// Encoding is always canonical: JSON, then standard padded base64, optionally
+// gzip-compressed first when the message carries PAYMENT-ENCODING: gzip, or
+// converted to compact CBOR when it carries PAYMENT-ENCODING: cbor-v1.
+// Decoding comes in two modes:
+//
+//   - Strict accepts only canonical base64, rejects unknown JSON fields and
+//     trailing data, requires x402Version 2, and validates required fields.
+//   - Lenient also accepts unpadded and URL-safe base64 and surrounding
+//     whitespace, and ignores unknown fields. It does not validate content.
+//
+// The package-level Decode functions are lenient, matching the SDK's own
+// middleware; use Strict for conformance testing.
+package headers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// Header names
+const (
+	// PaymentRequired carries the V2 402 challenge (server to client)
+	PaymentRequired = "PAYMENT-REQUIRED"
+
+	// PaymentSignature carries the V2 payment payload (client to server)
+	PaymentSignature = "PAYMENT-SIGNATURE"
+
+	// PaymentResponse carries the settlement result (server to client)
+	PaymentResponse = "PAYMENT-RESPONSE"
+
+	// XPayment carries a V1 payment payload
+	XPayment = "X-PAYMENT"
+
+	// XPaymentResponse carries a V1 settlement result
+	XPaymentResponse = "X-PAYMENT-RESPONSE"
+
+	// PaymentEncoding marks the payment header in the same message as gzip-compressed
+	PaymentEncoding = "PAYMENT-ENCODING"
+
+	// EncodingGzip marks a gzip-compressed payment header
+	EncodingGzip = "gzip"
+)
+
+// MaxDecompressedSize bounds gzip decompression to guard against gzip bombs
+const MaxDecompressedSize = 1 << 20
+
+// Mode selects how strictly headers are decoded
+type Mode int
+
+const (
+	// Lenient tolerates non-canonical base64 and unknown fields
+	Lenient Mode = iota
+
+	// Strict accepts only canonical, complete V2 headers
+	Strict
+)
+
+// Codec decodes x402 headers in a given mode
+type Codec struct {
+	Mode Mode
+}
+
+var (
+	// LenientCodec decodes in Lenient mode
+	LenientCodec = Codec{Mode: Lenient}
+
+	// StrictCodec decodes in Strict mode
+	StrictCodec = Codec{Mode: Strict}
+)
+
+// ============================================================================
+// Encoding
+// ============================================================================
+
+// EncodePaymentRequired encodes a PAYMENT-REQUIRED header value
+func EncodePaymentRequired(required types.PaymentRequired) (string, error) {
+	return encodeJSON(required, "payment required")
+}
+
+// EncodePaymentSignature encodes a PAYMENT-SIGNATURE header value
+func EncodePaymentSignature(payload types.PaymentPayload) (string, error) {
+	return encodeJSON(payload, "payment payload")
+}
+
+// EncodePaymentResponse encodes a PAYMENT-RESPONSE header value
+func EncodePaymentResponse(response x402.SettleResponse) (string, error) {
+	return encodeJSON(response, "settle response")
+}
+
+// EncodeBytes base64-encodes raw header JSON, gzip-compressing it first when
+// encoding is EncodingGzip or converting it to CBOR when encoding is
+// EncodingCBOR. An empty encoding means plain JSON.
+func EncodeBytes(data []byte, encoding string) (string, error) {
+	switch normalizeEncoding(encoding) {
+	case "":
+		return base64.StdEncoding.EncodeToString(data), nil
+	case EncodingGzip:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return "", fmt.Errorf("failed to compress payment header: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return "", fmt.Errorf("failed to compress payment header: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	case EncodingCBOR:
+		encoded, err := encodeCompactCBOR(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode payment header as CBOR: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(encoded), nil
+	default:
+		return "", fmt.Errorf("unsupported payment encoding: %s", encoding)
+	}
+}
+
+func encodeJSON(value interface{}, name string) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// ============================================================================
+// Decoding
+// ============================================================================
+
+// DecodePaymentRequired decodes a PAYMENT-REQUIRED header value leniently
+func DecodePaymentRequired(header string, encoding string) (types.PaymentRequired, error) {
+	return LenientCodec.DecodePaymentRequired(header, encoding)
+}
+
+// DecodePaymentSignature decodes a PAYMENT-SIGNATURE header value leniently
+func DecodePaymentSignature(header string, encoding string) (types.PaymentPayload, error) {
+	return LenientCodec.DecodePaymentSignature(header, encoding)
+}
+
+// DecodePaymentResponse decodes a PAYMENT-RESPONSE header value leniently
+func DecodePaymentResponse(header string) (x402.SettleResponse, error) {
+	return LenientCodec.DecodePaymentResponse(header)
+}
+
+// DetectVersion returns the x402Version of a PAYMENT-REQUIRED, PAYMENT-SIGNATURE,
+// or X-PAYMENT header value
+func DetectVersion(header string, encoding string) (int, error) {
+	return LenientCodec.DetectVersion(header, encoding)
+}
+
+// DecodeBytes base64-decodes a header value to its JSON, decompressing it when
+// encoding (the message's PAYMENT-ENCODING header) is gzip and converting it
+// from CBOR when encoding is cbor-v1
+func (c Codec) DecodeBytes(header string, encoding string) ([]byte, error) {
+	data, err := c.decodeBase64(header)
+	if err != nil {
+		return nil, err
+	}
+
+	switch normalizeEncoding(encoding) {
+	case "":
+		return data, nil
+	case EncodingGzip:
+		return gunzipBounded(data)
+	case EncodingCBOR:
+		return DecodeCBOR(data)
+	default:
+		return nil, fmt.Errorf("unsupported payment encoding: %s", encoding)
+	}
+}
+
+// DetectVersion returns the x402Version of a header value
+func (c Codec) DetectVersion(header string, encoding string) (int, error) {
+	data, err := c.DecodeBytes(header, encoding)
+	if err != nil {
+		return 0, err
+	}
+	return types.DetectVersion(data)
+}
+
+// DecodePaymentRequired decodes a PAYMENT-REQUIRED header value
+func (c Codec) DecodePaymentRequired(header string, encoding string) (types.PaymentRequired, error) {
+	var required types.PaymentRequired
+	if err := c.decodeJSON(header, encoding, &required, "payment required"); err != nil {
+		return types.PaymentRequired{}, err
+	}
+	if c.Mode == Strict {
+		if required.X402Version != 2 {
+			return types.PaymentRequired{}, fmt.Errorf("unsupported x402 version: %d", required.X402Version)
+		}
+		if _, err := x402.NewPaymentRequired().WithOptions(required.Accepts...).Build(); err != nil {
+			return types.PaymentRequired{}, err
+		}
+		if required.Resource != nil && required.Resource.URL == "" {
+			return types.PaymentRequired{}, errors.New("resource must have a URL")
+		}
+	}
+	return required, nil
+}
+
+// DecodePaymentSignature decodes a PAYMENT-SIGNATURE header value
+func (c Codec) DecodePaymentSignature(header string, encoding string) (types.PaymentPayload, error) {
+	var payload types.PaymentPayload
+	if err := c.decodeJSON(header, encoding, &payload, "payment payload"); err != nil {
+		return types.PaymentPayload{}, err
+	}
+	if c.Mode == Strict {
+		switch {
+		case payload.X402Version != 2:
+			return types.PaymentPayload{}, fmt.Errorf("unsupported x402 version: %d", payload.X402Version)
+		case payload.Accepted.Scheme == "" || payload.Accepted.Network == "":
+			return types.PaymentPayload{}, errors.New("payment payload must include the accepted scheme and network")
+		case payload.Payload == nil:
+			return types.PaymentPayload{}, errors.New("payment payload must include a payload")
+		}
+	}
+	return payload, nil
+}
+
+// DecodePaymentResponse decodes a PAYMENT-RESPONSE header value. PAYMENT-RESPONSE
+// is never compressed.
+func (c Codec) DecodePaymentResponse(header string) (x402.SettleResponse, error) {
+	var response x402.SettleResponse
+	if err := c.decodeJSON(header, "", &response, "settle response"); err != nil {
+		return x402.SettleResponse{}, err
+	}
+	if c.Mode == Strict {
+		switch {
+		case response.Network == "":
+			return x402.SettleResponse{}, errors.New("settle response must include a network")
+		case response.Success && response.Transaction == "" && !response.Deferred:
+			return x402.SettleResponse{}, errors.New("successful settle response must include a transaction")
+		}
+	}
+	return response, nil
+}
+
+func (c Codec) decodeJSON(header string, encoding string, out interface{}, name string) error {
+	data, err := c.DecodeBytes(header, encoding)
+	if err != nil {
+		return err
+	}
+
+	if c.Mode != Strict {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid %s JSON: %w", name, err)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("invalid %s JSON: %w", name, err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid %s JSON: trailing data", name)
+	}
+	return nil
+}
+
+func (c Codec) decodeBase64(header string) ([]byte, error) {
+	if c.Mode == Strict {
+		data, err := base64.StdEncoding.Strict().DecodeString(header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 encoding: %w", err)
+		}
+		return data, nil
+	}
+
+	header = strings.TrimSpace(header)
+	var firstErr error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		data, err := encoding.DecodeString(header)
+		if err == nil {
+			return data, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, fmt.Errorf("invalid base64 encoding: %w", firstErr)
+}
+
+// gunzipBounded decompresses data, failing if the output exceeds MaxDecompressedSize
+func gunzipBounded(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip encoding: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip encoding: %w", err)
+	}
+	if len(decompressed) > MaxDecompressedSize {
+		return nil, fmt.Errorf("decompressed payment header exceeds %d bytes", MaxDecompressedSize)
+	}
+	return decompressed, nil
+}
+
+func normalizeEncoding(encoding string) string {
+	return strings.ToLower(strings.TrimSpace(encoding))
+}
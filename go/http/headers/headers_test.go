@@ -0,0 +1,108 @@
+package headers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func testRequired() types.PaymentRequired {
+	return types.PaymentRequired{
+		X402Version: 2,
+		Resource:    &types.ResourceInfo{URL: "https://api.example.com/weather"},
+		Accepts: []types.PaymentRequirements{{
+			Scheme:            "exact",
+			Network:           "eip155:8453",
+			Asset:             "0xusdc",
+			Amount:            "10000",
+			PayTo:             "0xabc",
+			MaxTimeoutSeconds: 300,
+		}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	required := testRequired()
+	encoded, err := EncodePaymentRequired(required)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := StrictCodec.DecodePaymentRequired(encoded, "")
+	if err != nil {
+		t.Fatalf("unexpected strict decode error: %v", err)
+	}
+	if decoded.Accepts[0].PayTo != "0xabc" || decoded.Resource.URL != required.Resource.URL {
+		t.Errorf("unexpected decoded value %+v", decoded)
+	}
+
+	payload := types.PaymentPayload{X402Version: 2, Accepted: required.Accepts[0], Payload: map[string]interface{}{"signature": "0xsig"}}
+	encoded, _ = EncodePaymentSignature(payload)
+	if decodedPayload, err := StrictCodec.DecodePaymentSignature(encoded, ""); err != nil || decodedPayload.Payload["signature"] != "0xsig" {
+		t.Errorf("unexpected payload %+v err=%v", decodedPayload, err)
+	}
+
+	response := x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:8453", Payer: "0xpayer"}
+	encoded, _ = EncodePaymentResponse(response)
+	if decodedResponse, err := StrictCodec.DecodePaymentResponse(encoded); err != nil || decodedResponse != response {
+		t.Errorf("unexpected response %+v err=%v", decodedResponse, err)
+	}
+}
+
+func TestGzipEncoding(t *testing.T) {
+	raw := []byte(`{"x402Version":2}`)
+	compressed, err := EncodeBytes(raw, EncodingGzip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version, err := DetectVersion(compressed, "GZIP"); err != nil || version != 2 {
+		t.Errorf("expected version 2, got %d err=%v", version, err)
+	}
+	if _, err := EncodeBytes(raw, "br"); err == nil {
+		t.Error("expected unsupported encoding error")
+	}
+}
+
+func TestLenientAndStrictModes(t *testing.T) {
+	json := `{"x402Version":2,"accepts":[{"scheme":"exact","network":"eip155:8453","asset":"0xusdc","amount":"1","payTo":"0xabc","maxTimeoutSeconds":60}],"future":true}`
+	urlSafeUnpadded := base64.RawURLEncoding.EncodeToString([]byte(json))
+	canonical := base64.StdEncoding.EncodeToString([]byte(json))
+
+	if _, err := LenientCodec.DecodePaymentRequired(" "+urlSafeUnpadded+" ", ""); err != nil {
+		t.Errorf("expected lenient decode to accept unpadded URL-safe base64, got %v", err)
+	}
+	if _, err := StrictCodec.DecodePaymentRequired(urlSafeUnpadded, ""); err == nil {
+		t.Error("expected strict decode to reject non-canonical base64")
+	}
+	if _, err := StrictCodec.DecodePaymentRequired(canonical, ""); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("expected strict decode to reject unknown fields, got %v", err)
+	}
+}
+
+func TestStrictValidation(t *testing.T) {
+	v1 := testRequired()
+	v1.X402Version = 1
+	noOptions := testRequired()
+	noOptions.Accepts = nil
+
+	for name, required := range map[string]types.PaymentRequired{"wrong version": v1, "no options": noOptions} {
+		encoded, _ := EncodePaymentRequired(required)
+		if _, err := StrictCodec.DecodePaymentRequired(encoded, ""); err == nil {
+			t.Errorf("%s: expected strict validation error", name)
+		}
+		if _, err := LenientCodec.DecodePaymentRequired(encoded, ""); err != nil {
+			t.Errorf("%s: expected lenient decode to succeed, got %v", name, err)
+		}
+	}
+
+	encoded, _ := EncodePaymentResponse(x402.SettleResponse{Success: true, Network: "eip155:8453"})
+	if _, err := StrictCodec.DecodePaymentResponse(encoded); err == nil {
+		t.Error("expected strict decode to require a transaction on success")
+	}
+	encoded, _ = EncodePaymentResponse(x402.SettleResponse{Success: true, Deferred: true, Network: "eip155:8453"})
+	if _, err := StrictCodec.DecodePaymentResponse(encoded); err != nil {
+		t.Errorf("expected a deferred response without a transaction to decode, got %v", err)
+	}
+}
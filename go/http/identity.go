@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Payer Identity Propagation
+// ============================================================================
+
+// Headers injected into verified requests for downstream handlers and services
+const (
+	PayerIdentityHeader       = "X-402-Payer"
+	NetworkIdentityHeader     = "X-402-Network"
+	AmountIdentityHeader      = "X-402-Amount"
+	TransactionIdentityHeader = "X-402-Tx"
+)
+
+// DefaultIdentityHeaders is the full set of identity headers
+var DefaultIdentityHeaders = []string{
+	PayerIdentityHeader,
+	NetworkIdentityHeader,
+	AmountIdentityHeader,
+	TransactionIdentityHeader,
+}
+
+// PaymentIdentity describes who paid for a request and how
+type PaymentIdentity struct {
+	Payer   string
+	Network string
+	Scheme  string
+	Asset   string
+	Amount  string
+
+	// Attribution is the metadata the route attached (see RouteConfig.Attribution)
+	Attribution map[string]string
+
+	// Transaction is empty until the payment has been settled
+	Transaction string
+
+	// itemCount is what the handler reported with ReportItemCount
+	itemCount *int
+}
+
+// NewPaymentIdentity builds the identity of a verified payment
+func NewPaymentIdentity(result HTTPProcessResult) *PaymentIdentity {
+	identity := &PaymentIdentity{Payer: result.Payer}
+	if result.PaymentRequirements != nil {
+		identity.Network = result.PaymentRequirements.Network
+		identity.Scheme = result.PaymentRequirements.Scheme
+		identity.Asset = result.PaymentRequirements.Asset
+		identity.Amount = result.PaymentRequirements.Amount
+		identity.Attribution = types.Attribution(result.PaymentRequirements.Extra)
+	}
+	return identity
+}
+
+// SetSettlement records the settlement transaction on the identity
+func (p *PaymentIdentity) SetSettlement(settle *ProcessSettleResult) {
+	if settle == nil || !settle.Success {
+		return
+	}
+	p.Transaction = settle.Transaction
+	if p.Payer == "" {
+		p.Payer = settle.Payer
+	}
+}
+
+// Headers returns the identity as headers, limited to the allowlist. Empty values are omitted.
+func (p *PaymentIdentity) Headers(allowlist []string) map[string]string {
+	values := map[string]string{
+		http.CanonicalHeaderKey(PayerIdentityHeader):       p.Payer,
+		http.CanonicalHeaderKey(NetworkIdentityHeader):     p.Network,
+		http.CanonicalHeaderKey(AmountIdentityHeader):      p.Amount,
+		http.CanonicalHeaderKey(TransactionIdentityHeader): p.Transaction,
+	}
+
+	headers := make(map[string]string)
+	for _, name := range allowlist {
+		key := http.CanonicalHeaderKey(name)
+		if value := values[key]; value != "" {
+			headers[key] = value
+		}
+	}
+	return headers
+}
+
+// StripIdentityHeaders removes client-supplied identity headers so downstream
+// services can trust the values injected by the middleware
+func StripIdentityHeaders(header http.Header) {
+	for _, name := range DefaultIdentityHeaders {
+		header.Del(name)
+	}
+}
+
+type paymentIdentityKey struct{}
+
+// ContextWithPaymentIdentity returns a context carrying the payment identity
+func ContextWithPaymentIdentity(ctx context.Context, identity *PaymentIdentity) context.Context {
+	return context.WithValue(ctx, paymentIdentityKey{}, identity)
+}
+
+// PaymentIdentityFromContext returns the payment identity stored by the middleware, if any
+func PaymentIdentityFromContext(ctx context.Context) (*PaymentIdentity, bool) {
+	identity, ok := ctx.Value(paymentIdentityKey{}).(*PaymentIdentity)
+	return identity, ok
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestPaymentIdentityHeaders(t *testing.T) {
+	identity := NewPaymentIdentity(HTTPProcessResult{
+		Payer: "0xpayer",
+		PaymentRequirements: &types.PaymentRequirements{
+			Scheme:  "exact",
+			Network: "eip155:8453",
+			Amount:  "10000",
+		},
+	})
+
+	headers := identity.Headers(DefaultIdentityHeaders)
+	if headers["X-402-Payer"] != "0xpayer" || headers["X-402-Network"] != "eip155:8453" || headers["X-402-Amount"] != "10000" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+	if _, ok := headers["X-402-Tx"]; ok {
+		t.Error("transaction header should be omitted before settlement")
+	}
+
+	identity.SetSettlement(&ProcessSettleResult{Success: true, Transaction: "0xtx"})
+	headers = identity.Headers([]string{"x-402-tx"})
+	if len(headers) != 1 || headers["X-402-Tx"] != "0xtx" {
+		t.Errorf("expected only allowlisted transaction header, got %v", headers)
+	}
+
+	identity.SetSettlement(&ProcessSettleResult{Success: false, Transaction: "0xfailed"})
+	if identity.Transaction != "0xtx" {
+		t.Error("failed settlement should not overwrite the transaction")
+	}
+}
+
+func TestStripIdentityHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-402-Payer", "spoofed")
+	header.Set("X-Other", "kept")
+
+	StripIdentityHeaders(header)
+
+	if header.Get("X-402-Payer") != "" || header.Get("X-Other") != "kept" {
+		t.Errorf("unexpected headers after strip: %v", header)
+	}
+}
+
+func TestPaymentIdentityContext(t *testing.T) {
+	if _, ok := PaymentIdentityFromContext(context.Background()); ok {
+		t.Error("expected no identity in empty context")
+	}
+
+	identity := &PaymentIdentity{Payer: "0xpayer"}
+	got, ok := PaymentIdentityFromContext(ContextWithPaymentIdentity(context.Background(), identity))
+	if !ok || got != identity {
+		t.Error("expected identity from context")
+	}
+}
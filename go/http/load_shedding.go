@@ -0,0 +1,229 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// Load Shedding
+// ============================================================================
+
+// Load shedding rejection reasons, reported to LoadSheddingConfig.OnReject
+const (
+	ShedReasonOverloaded = "overloaded"     // Concurrency limit reached and the queue is full
+	ShedReasonQueueWait  = "queue_timeout"  // Waited QueueTimeout without getting a slot
+	ShedReasonQuota      = "quota_exceeded" // The client used its quota for the window
+)
+
+// LoadSheddingConfig protects a facilitator's HTTP server, and the chain RPC
+// endpoints behind it, under burst load. Rejected requests get a 429 with a
+// Retry-After header.
+type LoadSheddingConfig struct {
+	// MaxConcurrent is the number of requests handled at once (0 = unlimited)
+	MaxConcurrent int
+
+	// MaxQueue is the number of requests that may wait for a free slot; more
+	// are rejected immediately (0 = none wait)
+	MaxQueue int
+
+	// QueueTimeout is how long a queued request waits for a slot (default: 5 seconds)
+	QueueTimeout time.Duration
+
+	// RetryAfter is sent with concurrency rejections (default: 1 second)
+	RetryAfter time.Duration
+
+	// Quota is the number of requests each client may make per QuotaWindow (0 = unlimited)
+	Quota int64
+
+	// QuotaFor overrides Quota per client identity, e.g. per API key plan (optional)
+	QuotaFor func(identity string) int64
+
+	// QuotaWindow is the quota period (default: 1 minute). Windows are aligned
+	// to multiples of the duration.
+	QuotaWindow time.Duration
+
+	// Store counts quota usage (default: in-memory). Share it between
+	// instances to enforce quotas across them.
+	Store Store
+
+	// Identity identifies the client for quotas (default: AuthIdentity).
+	// Requests without an identity are not subject to quotas.
+	Identity func(r *http.Request) string
+
+	// OnReject is called for every rejected request, e.g. to record metrics (optional)
+	OnReject func(r *http.Request, reason string)
+}
+
+// loadShedQuotaKeyPrefix namespaces quota counters in the store
+const loadShedQuotaKeyPrefix = "x402:load-shed:"
+
+// LoadShedder limits concurrency, queue depth, and per-client request rates
+// of an HTTP handler.
+//
+//	shedder := x402http.NewLoadShedder(x402http.LoadSheddingConfig{MaxConcurrent: 64, MaxQueue: 256, Quota: 600})
+//	http.ListenAndServe(":4022", shedder.Handler(mux))
+type LoadShedder struct {
+	config  LoadSheddingConfig
+	slots   chan struct{}
+	waiting int64
+	now     func() time.Time
+}
+
+// NewLoadShedder creates a load shedder, applying defaults
+func NewLoadShedder(config LoadSheddingConfig) *LoadShedder {
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = 5 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.QuotaWindow <= 0 {
+		config.QuotaWindow = time.Minute
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Identity == nil {
+		config.Identity = AuthIdentity
+	}
+
+	l := &LoadShedder{config: config, now: time.Now}
+	if config.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return l
+}
+
+// Handler wraps next with quota and concurrency limits
+func (l *LoadShedder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, ok := l.allowQuota(r); !ok {
+			l.reject(w, r, ShedReasonQuota, retryAfter)
+			return
+		}
+
+		if l.slots != nil {
+			reason, ok := l.acquire(r.Context())
+			if !ok {
+				if reason != "" {
+					l.reject(w, r, reason, l.config.RetryAfter)
+				}
+				return
+			}
+			defer func() { <-l.slots }()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Waiting returns the number of requests queued for a slot
+func (l *LoadShedder) Waiting() int {
+	return int(atomic.LoadInt64(&l.waiting))
+}
+
+// acquire takes a concurrency slot, queueing up to MaxQueue requests for at
+// most QueueTimeout. It returns an empty reason if the client went away.
+func (l *LoadShedder) acquire(ctx context.Context) (string, bool) {
+	select {
+	case l.slots <- struct{}{}:
+		return "", true
+	default:
+	}
+
+	if atomic.AddInt64(&l.waiting, 1) > int64(l.config.MaxQueue) {
+		atomic.AddInt64(&l.waiting, -1)
+		return ShedReasonOverloaded, false
+	}
+	defer atomic.AddInt64(&l.waiting, -1)
+
+	timer := time.NewTimer(l.config.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return "", true
+	case <-timer.C:
+		return ShedReasonQueueWait, false
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+// allowQuota counts the request against the client's quota, returning how long
+// until the window resets when it is exceeded. Store errors let requests through.
+func (l *LoadShedder) allowQuota(r *http.Request) (time.Duration, bool) {
+	identity := l.config.Identity(r)
+	if identity == "" {
+		return 0, true
+	}
+	quota := l.config.Quota
+	if l.config.QuotaFor != nil {
+		quota = l.config.QuotaFor(identity)
+	}
+	if quota <= 0 {
+		return 0, true
+	}
+
+	window := l.config.QuotaWindow
+	start := l.now().Truncate(window)
+	reset := start.Add(window).Sub(l.now())
+	key := loadShedQuotaKeyPrefix + identity + ":" + strconv.FormatInt(start.Unix(), 10)
+
+	used, err := l.config.Store.Increment(r.Context(), key, 1, reset)
+	if err != nil {
+		return 0, true
+	}
+	return reset, used <= quota
+}
+
+// reject reports a rejection and responds 429
+func (l *LoadShedder) reject(w http.ResponseWriter, r *http.Request, reason string, retryAfter time.Duration) {
+	if l.config.OnReject != nil {
+		l.config.OnReject(r, reason)
+	}
+	writeTooManyRequests(w, reason, retryAfter)
+}
+
+// writeTooManyRequests responds 429 with Retry-After in whole seconds
+func writeTooManyRequests(w http.ResponseWriter, reason string, retryAfter time.Duration) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": reason})
+}
+
+// AuthIdentity identifies a client by a hash of its Authorization or X-API-Key
+// header, falling back to its remote IP address
+func AuthIdentity(r *http.Request) string {
+	credential := strings.TrimSpace(r.Header.Get("Authorization"))
+	if credential == "" {
+		credential = strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	}
+	if credential != "" {
+		sum := sha256.Sum256([]byte(credential))
+		return "auth:" + hex.EncodeToString(sum[:8])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host == "" {
+		return ""
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadShedderConcurrencyAndQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var mu sync.Mutex
+	var rejected []string
+	shedder := NewLoadShedder(LoadSheddingConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Minute,
+		RetryAfter:    2 * time.Second,
+		OnReject: func(r *http.Request, reason string) {
+			mu.Lock()
+			rejected = append(rejected, reason)
+			mu.Unlock()
+		},
+	})
+	wrapped := shedder.Handler(handler)
+
+	// First request holds the only slot, the second waits in the queue
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, httptest.NewRequest("POST", "/settle", nil))
+			codes[i] = rec.Code
+		}(i)
+		if i == 0 {
+			<-started
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for shedder.Waiting() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A third request finds the queue full
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest("POST", "/settle", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	wg.Wait()
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK {
+		t.Errorf("Expected the running and queued requests to succeed, got %v", codes)
+	}
+	if len(rejected) != 1 || rejected[0] != ShedReasonOverloaded {
+		t.Errorf("Expected one %s rejection, got %v", ShedReasonOverloaded, rejected)
+	}
+}
+
+func TestLoadShedderQueueTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	shedder := NewLoadShedder(LoadSheddingConfig{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond})
+	wrapped := shedder.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	go wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/verify", nil))
+	<-started
+
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest("POST", "/verify", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after the queue timeout, got %d", rec.Code)
+	}
+}
+
+func TestLoadShedderQuotaPerIdentity(t *testing.T) {
+	shedder := NewLoadShedder(LoadSheddingConfig{
+		Quota:       2,
+		QuotaWindow: time.Minute,
+		QuotaFor: func(identity string) int64 {
+			if identity == AuthIdentity(withAuth("Bearer premium")) {
+				return 3
+			}
+			return 2
+		},
+	})
+	shedder.now = func() time.Time { return time.Unix(1700000010, 0) }
+	wrapped := shedder.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		wrapped.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(withAuth("Bearer basic")); rec.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within quota to pass, got %d", i+1, rec.Code)
+		}
+	}
+	rec := serve(withAuth("Bearer basic"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 over quota, got %d", rec.Code)
+	}
+	// The minute window started at 1699999980, so it resets in 30 seconds
+	if rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("Expected Retry-After 30, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Other identities have their own quota
+	for i := 0; i < 3; i++ {
+		if rec := serve(withAuth("Bearer premium")); rec.Code != http.StatusOK {
+			t.Fatalf("Expected premium request %d to pass, got %d", i+1, rec.Code)
+		}
+	}
+}
+
+func TestAuthIdentity(t *testing.T) {
+	if AuthIdentity(withAuth("Bearer a")) == AuthIdentity(withAuth("Bearer b")) {
+		t.Error("Expected different credentials to have different identities")
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.7:5000"
+	if got := AuthIdentity(req); got != "ip:203.0.113.7" {
+		t.Errorf("Expected the remote IP without credentials, got %q", got)
+	}
+}
+
+func withAuth(value string) *http.Request {
+	req := httptest.NewRequest("POST", "/settle", nil)
+	req.Header.Set("Authorization", value)
+	return req
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Monitor (Dry-Run) Mode
+// ============================================================================
+
+// MonitorEvent reports what payment enforcement would have done for a request
+// served in monitor mode
+type MonitorEvent struct {
+	Method string
+	Path   string
+
+	// Requirements are the payment options that would have been offered (what would have been charged)
+	Requirements []types.PaymentRequirements
+
+	// PaymentProvided is true when the request carried a payment header
+	PaymentProvided bool
+
+	// WouldVerify is true when the provided payment passed verification
+	WouldVerify bool
+
+	// Outcome is the result type enforcement would have produced
+	Outcome string
+
+	// Status is the HTTP status enforcement would have returned (0 when the request would pass)
+	Status int
+
+	// Error describes why the payment was missing, invalid, or failed verification
+	Error string
+}
+
+// MonitorHook receives monitor events, e.g. to log or record metrics
+type MonitorHook func(context.Context, MonitorEvent)
+
+// monitorTrace collects details from processHTTPRequest for a monitor event
+type monitorTrace struct {
+	requirements    []types.PaymentRequirements
+	paymentProvided bool
+	err             error
+}
+
+// SetMonitorMode enables or disables monitor mode for every route. In monitor
+// mode requests are always let through: payments are still evaluated (including
+// facilitator verification when a payment is sent) and reported to OnMonitor
+// hooks, but nothing is charged. Individual routes can opt in with RouteConfig.Monitor.
+func (s *x402HTTPResourceServer) SetMonitorMode(enabled bool) *x402HTTPResourceServer {
+	s.monitorMode = enabled
+	return s
+}
+
+// OnMonitor registers a hook called for every request served in monitor mode
+func (s *x402HTTPResourceServer) OnMonitor(hook MonitorHook) *x402HTTPResourceServer {
+	s.monitorHooks = append(s.monitorHooks, hook)
+	return s
+}
+
+// processMonitoredRequest evaluates a request as if enforced, reports the
+// outcome, and lets the request through without settlement
+func (s *x402HTTPResourceServer) processMonitoredRequest(ctx context.Context, reqCtx HTTPRequestContext, paywallConfig *PaywallConfig, routeConfig *RouteConfig) HTTPProcessResult {
+	trace := &monitorTrace{}
+	enforced := s.processHTTPRequest(ctx, reqCtx, paywallConfig, routeConfig, trace)
+
+	event := MonitorEvent{
+		Method:          reqCtx.Method,
+		Path:            reqCtx.Path,
+		Requirements:    trace.requirements,
+		PaymentProvided: trace.paymentProvided,
+		WouldVerify:     enforced.Type == ResultPaymentVerified,
+		Outcome:         enforced.Type,
+	}
+	if enforced.Response != nil {
+		event.Status = enforced.Response.Status
+	}
+	switch {
+	case trace.err != nil:
+		event.Error = trace.err.Error()
+	case event.Status == 402 && !trace.paymentProvided:
+		event.Error = "payment required"
+	case event.Status == 402:
+		event.Error = "payment did not match requirements"
+	}
+
+	for _, hook := range s.monitorHooks {
+		hook(ctx, event)
+	}
+
+	return HTTPProcessResult{
+		Type:    ResultNoPaymentRequired,
+		Payer:   enforced.Payer,
+		Monitor: &event,
+	}
+}
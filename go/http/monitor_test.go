@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func newMonitorTestServer(t *testing.T, facilitator *mockFacilitatorClient, routeMonitor bool) *x402HTTPResourceServer {
+	t.Helper()
+	routes := RoutesConfig{
+		"GET /api": {
+			Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+			Monitor: routeMonitor,
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(facilitator),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+	return server
+}
+
+func TestMonitorModeLetsUnpaidRequestsThrough(t *testing.T) {
+	server := newMonitorTestServer(t, &mockFacilitatorClient{}, false).SetMonitorMode(true)
+
+	var events []MonitorEvent
+	server.OnMonitor(func(ctx context.Context, event MonitorEvent) {
+		events = append(events, event)
+	})
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api"}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+
+	if result.Type != ResultNoPaymentRequired {
+		t.Fatalf("Expected request to pass through, got %s", result.Type)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 monitor event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Outcome != ResultPaymentError || event.Status != 402 || event.PaymentProvided || event.Error != "payment required" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if len(event.Requirements) != 1 || event.Requirements[0].Amount != "1000000" {
+		t.Errorf("Expected would-be charge in event, got %+v", event.Requirements)
+	}
+	if result.Monitor == nil || result.Monitor.Outcome != event.Outcome {
+		t.Error("Expected monitor event on result")
+	}
+}
+
+func TestMonitorModeReportsVerification(t *testing.T) {
+	tests := []struct {
+		name        string
+		verifyErr   error
+		wouldVerify bool
+	}{
+		{"valid payment", nil, true},
+		{"invalid payment", errors.New("insufficient funds"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settleCalled := false
+			facilitator := &mockFacilitatorClient{
+				verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+					if tt.verifyErr != nil {
+						return nil, tt.verifyErr
+					}
+					return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+				},
+				settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+					settleCalled = true
+					return &x402.SettleResponse{Success: true}, nil
+				},
+			}
+			server := newMonitorTestServer(t, facilitator, true)
+
+			var event MonitorEvent
+			server.OnMonitor(func(ctx context.Context, e MonitorEvent) { event = e })
+
+			adapter := &mockHTTPAdapter{
+				method: "GET",
+				path:   "/api",
+				url:    "http://example.com/api",
+			}
+			options := PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}
+			requirements, err := server.BuildPaymentRequirementsFromOptions(context.Background(), options, HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"})
+			if err != nil {
+				t.Fatalf("Failed to build requirements: %v", err)
+			}
+			adapter.headers = map[string]string{"PAYMENT-SIGNATURE": acceptingPaymentHeader(requirements[0])}
+			result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+
+			if result.Type != ResultNoPaymentRequired {
+				t.Fatalf("Expected request to pass through, got %s", result.Type)
+			}
+			if !event.PaymentProvided || event.WouldVerify != tt.wouldVerify {
+				t.Errorf("Unexpected event %+v", event)
+			}
+			if tt.verifyErr != nil && event.Error != tt.verifyErr.Error() {
+				t.Errorf("Expected verify error in event, got %q", event.Error)
+			}
+			if settleCalled {
+				t.Error("Monitor mode must never settle")
+			}
+		})
+	}
+}
+
+func TestMonitorModeDisabledEnforces(t *testing.T) {
+	server := newMonitorTestServer(t, &mockFacilitatorClient{}, false)
+	server.OnMonitor(func(ctx context.Context, e MonitorEvent) {
+		t.Error("Monitor hook should not run for enforced routes")
+	})
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api"}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+
+	if result.Type != ResultPaymentError || result.Monitor != nil {
+		t.Errorf("Expected enforced 402, got %+v", result)
+	}
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Address Book and Name Resolution
+// ============================================================================
+
+// NameResolver resolves a human-readable name (e.g. "merchant.eth" or
+// "merchant.sol") to an address on a network. See evm.NewENSResolver and
+// svm.NewSNSResolver.
+type NameResolver interface {
+	ResolveName(ctx context.Context, network x402.Network, name string) (string, error)
+}
+
+// NameResolverFunc adapts a function to NameResolver
+type NameResolverFunc func(ctx context.Context, network x402.Network, name string) (string, error)
+
+// ResolveName implements NameResolver
+func (f NameResolverFunc) ResolveName(ctx context.Context, network x402.Network, name string) (string, error) {
+	return f(ctx, network, name)
+}
+
+// IsResolvableName reports whether payTo is a name rather than an address.
+// EVM hex and Solana base58 addresses never contain a dot.
+func IsResolvableName(payTo string) bool {
+	return strings.Contains(payTo, ".")
+}
+
+// AddressBookConfig configures an AddressBook
+type AddressBookConfig struct {
+	// TTL is how long resolved names are cached (default: 1 hour)
+	TTL time.Duration
+}
+
+// AddressBook resolves payTo names to addresses, caching the results, and
+// remembers the name of every address it resolved so the paywall and
+// settlement results can display it. Static entries added with Add never
+// expire. It is safe for concurrent use.
+type AddressBook struct {
+	config AddressBookConfig
+
+	mu        sync.Mutex
+	resolvers []addressBookResolver
+	entries   map[string]addressBookEntry // keyed by network and lowercase name
+	names     map[string]string           // lowercase address -> name
+	now       func() time.Time
+}
+
+type addressBookResolver struct {
+	pattern  x402.Network
+	resolver NameResolver
+}
+
+type addressBookEntry struct {
+	address   string
+	expiresAt time.Time // zero means no expiry
+}
+
+// NewAddressBook creates an empty address book
+func NewAddressBook(config AddressBookConfig) *AddressBook {
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+	return &AddressBook{
+		config:  config,
+		entries: make(map[string]addressBookEntry),
+		names:   make(map[string]string),
+		now:     time.Now,
+	}
+}
+
+// RegisterResolver resolves names on networks matching pattern (e.g. "eip155:*").
+// Resolvers are tried in registration order.
+func (b *AddressBook) RegisterResolver(pattern x402.Network, resolver NameResolver) *AddressBook {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resolvers = append(b.resolvers, addressBookResolver{pattern: pattern, resolver: resolver})
+	return b
+}
+
+// Add records a static name for an address on networks matching pattern
+func (b *AddressBook) Add(pattern x402.Network, name string, address string) *AddressBook {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[addressBookKey(pattern, name)] = addressBookEntry{address: address}
+	b.names[strings.ToLower(address)] = name
+	return b
+}
+
+// Resolve returns the address for name on network. Static entries are
+// checked first, then cached resolutions, then registered resolvers.
+func (b *AddressBook) Resolve(ctx context.Context, network x402.Network, name string) (string, error) {
+	b.mu.Lock()
+	if address, ok := b.lookupLocked(network, name); ok {
+		b.mu.Unlock()
+		return address, nil
+	}
+	var resolver NameResolver
+	for _, r := range b.resolvers {
+		if x402.MatchesNetwork(r.pattern, network) {
+			resolver = r.resolver
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	if resolver == nil {
+		return "", fmt.Errorf("no name resolver for %s on %s", name, network)
+	}
+	address, err := resolver.ResolveName(ctx, network, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s on %s: %w", name, network, err)
+	}
+	if address == "" {
+		return "", fmt.Errorf("%s does not resolve to an address on %s", name, network)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[addressBookKey(network, name)] = addressBookEntry{address: address, expiresAt: b.now().Add(b.config.TTL)}
+	b.names[strings.ToLower(address)] = name
+	return address, nil
+}
+
+// NameOf returns the name an address was resolved from, for display
+func (b *AddressBook) NameOf(address string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name, ok := b.names[strings.ToLower(address)]
+	return name, ok
+}
+
+// lookupLocked finds a live entry for the exact network or a static entry for a matching pattern
+func (b *AddressBook) lookupLocked(network x402.Network, name string) (string, bool) {
+	if entry, ok := b.entries[addressBookKey(network, name)]; ok {
+		if entry.expiresAt.IsZero() || b.now().Before(entry.expiresAt) {
+			return entry.address, true
+		}
+		delete(b.entries, addressBookKey(network, name))
+	}
+	lowerName := strings.ToLower(name)
+	for key, entry := range b.entries {
+		pattern, entryName, _ := strings.Cut(key, "|")
+		if entry.expiresAt.IsZero() && entryName == lowerName && x402.MatchesNetwork(x402.Network(pattern), network) {
+			return entry.address, true
+		}
+	}
+	return "", false
+}
+
+func addressBookKey(network x402.Network, name string) string {
+	return string(network) + "|" + strings.ToLower(name)
+}
+
+// ============================================================================
+// Server Integration
+// ============================================================================
+
+// EnableNameResolution resolves payTo names (e.g. "merchant.eth") through the
+// address book. Static names are resolved by Initialize; with strict set,
+// Initialize fails when any name does not resolve, otherwise unresolved names
+// are retried per request. Names returned by DynamicPayToFunc are resolved
+// per request (cached by the address book).
+func (s *x402HTTPResourceServer) EnableNameResolution(book *AddressBook, strict bool) *x402HTTPResourceServer {
+	s.addressBook = book
+	s.strictNames = strict
+	return s
+}
+
+// resolveRouteNames resolves static payTo names in every compiled route
+func (s *x402HTTPResourceServer) resolveRouteNames(ctx context.Context) error {
+	if s.addressBook == nil {
+		return nil
+	}
+	for i := range s.compiledRoutes {
+		config := &s.compiledRoutes[i].Config
+		var accepts PaymentOptions
+		for j, option := range config.Accepts {
+			payTo, ok := option.PayTo.(string)
+			if !ok || !IsResolvableName(payTo) {
+				continue
+			}
+			address, err := s.addressBook.Resolve(ctx, option.Network, payTo)
+			if err != nil {
+				if s.strictNames {
+					return err
+				}
+				continue
+			}
+			// Copy before the first change so the caller's RoutesConfig is untouched
+			if accepts == nil {
+				accepts = append(PaymentOptions(nil), config.Accepts...)
+			}
+			accepts[j].PayTo = address
+		}
+		if accepts != nil {
+			config.Accepts = accepts
+		}
+	}
+	return nil
+}
+
+// resolvePayTo resolves a payTo name at request time; addresses pass through
+func (s *x402HTTPResourceServer) resolvePayTo(ctx context.Context, network x402.Network, payTo string) (string, error) {
+	if s.addressBook == nil || !IsResolvableName(payTo) {
+		return payTo, nil
+	}
+	return s.addressBook.Resolve(ctx, network, payTo)
+}
+
+// paywallConfigWithNames adds the names of resolved payTo addresses to the paywall config
+func (s *x402HTTPResourceServer) paywallConfigWithNames(config *PaywallConfig, requirements []types.PaymentRequirements) *PaywallConfig {
+	if s.addressBook == nil {
+		return config
+	}
+	names := make(map[string]string)
+	for _, requirement := range requirements {
+		if name, ok := s.addressBook.NameOf(requirement.PayTo); ok {
+			names[requirement.PayTo] = name
+		}
+	}
+	if len(names) == 0 {
+		return config
+	}
+
+	withNames := PaywallConfig{}
+	if config != nil {
+		withNames = *config
+	}
+	withNames.PayToNames = names
+	return &withNames
+}
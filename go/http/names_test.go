@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func countingResolver(addresses map[string]string, calls *int) NameResolver {
+	return NameResolverFunc(func(ctx context.Context, network x402.Network, name string) (string, error) {
+		*calls++
+		if address, ok := addresses[name]; ok {
+			return address, nil
+		}
+		return "", errors.New("not found")
+	})
+}
+
+func TestAddressBookResolveCaches(t *testing.T) {
+	calls := 0
+	book := NewAddressBook(AddressBookConfig{TTL: time.Minute})
+	book.RegisterResolver("eip155:*", countingResolver(map[string]string{"merchant.eth": "0xMerchant"}, &calls))
+
+	now := time.Now()
+	book.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		address, err := book.Resolve(context.Background(), "eip155:8453", "merchant.eth")
+		if err != nil || address != "0xMerchant" {
+			t.Fatalf("expected 0xMerchant, got %q (%v)", address, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected cached resolution, got %d calls", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	_, _ = book.Resolve(context.Background(), "eip155:8453", "merchant.eth")
+	if calls != 2 {
+		t.Errorf("expected expired entry to be resolved again, got %d calls", calls)
+	}
+
+	if name, ok := book.NameOf("0xmerchant"); !ok || name != "merchant.eth" {
+		t.Errorf("expected reverse lookup of merchant.eth, got %q", name)
+	}
+
+	if _, err := book.Resolve(context.Background(), "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", "merchant.sol"); err == nil {
+		t.Error("expected error without a resolver for the network")
+	}
+}
+
+func TestAddressBookStaticEntries(t *testing.T) {
+	book := NewAddressBook(AddressBookConfig{})
+	book.Add("eip155:*", "Treasury.eth", "0xTreasury")
+
+	address, err := book.Resolve(context.Background(), "eip155:1", "treasury.eth")
+	if err != nil || address != "0xTreasury" {
+		t.Fatalf("expected static entry, got %q (%v)", address, err)
+	}
+}
+
+func nameRoutes(payTo string) RoutesConfig {
+	return RoutesConfig{
+		"GET /content": {
+			Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: payTo, Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+}
+
+func TestNameResolutionAtInitialize(t *testing.T) {
+	calls := 0
+	book := NewAddressBook(AddressBookConfig{})
+	book.RegisterResolver("eip155:*", countingResolver(map[string]string{"merchant.eth": "0xMerchant"}, &calls))
+
+	routes := nameRoutes("merchant.eth")
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).EnableNameResolution(book, true)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payTo := server.compiledRoutes[0].Config.Accepts[0].PayTo; payTo != "0xMerchant" {
+		t.Errorf("expected payTo to be resolved, got %v", payTo)
+	}
+	if payTo := routes["GET /content"].Accepts[0].PayTo; payTo != "merchant.eth" {
+		t.Errorf("expected caller's routes to be untouched, got %v", payTo)
+	}
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/content", url: "http://example.com/content", accept: "text/html", agent: "Mozilla/5.0"}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/content", Method: "GET"}, nil)
+	if result.Response == nil || !strings.Contains(result.Response.Body.(string), `payToNames: {"0xMerchant":"merchant.eth"}`) {
+		t.Error("expected paywall to display the payTo name")
+	}
+}
+
+func TestNameResolutionStrict(t *testing.T) {
+	calls := 0
+	book := NewAddressBook(AddressBookConfig{})
+	book.RegisterResolver("eip155:*", countingResolver(nil, &calls))
+
+	strict := Newx402HTTPResourceServer(
+		nameRoutes("missing.eth"),
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).EnableNameResolution(book, true)
+	if err := strict.Initialize(context.Background()); err == nil {
+		t.Error("expected strict mode to fail startup")
+	}
+
+	lenient := Newx402HTTPResourceServer(
+		nameRoutes("missing.eth"),
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).EnableNameResolution(book, false)
+	if err := lenient.Initialize(context.Background()); err != nil {
+		t.Fatalf("expected lenient mode to start, got %v", err)
+	}
+
+	options := lenient.compiledRoutes[0].Config.Accepts
+	if _, err := lenient.BuildPaymentRequirementsFromOptions(context.Background(), options, HTTPRequestContext{}); err == nil {
+		t.Error("expected unresolved name to fail at request time")
+	}
+}
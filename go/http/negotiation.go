@@ -0,0 +1,175 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Content Negotiation
+// ============================================================================
+
+// Media types a 402 response can be rendered as
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeHTML     = "text/html"
+	MediaTypeX402CBOR = "application/x402+cbor"
+)
+
+// PaymentRequiredEncoder renders the payment requirements as a 402 response body
+type PaymentRequiredEncoder func(paymentRequired types.PaymentRequired) ([]byte, error)
+
+// RegisterMediaType offers an additional 402 body format to API clients that
+// ask for mediaType in their Accept header. Registering application/x402+cbor
+// replaces the built-in CBOR encoder.
+func (s *x402HTTPResourceServer) RegisterMediaType(mediaType string, encoder PaymentRequiredEncoder) *x402HTTPResourceServer {
+	mediaType = strings.ToLower(mediaType)
+	if s.mediaEncoders == nil {
+		s.mediaEncoders = make(map[string]PaymentRequiredEncoder)
+	}
+	if _, exists := s.mediaEncoders[mediaType]; !exists {
+		s.mediaTypes = append(s.mediaTypes, mediaType)
+	}
+	s.mediaEncoders[mediaType] = encoder
+	return s
+}
+
+// negotiateMediaType picks the 402 body format. Browsers (see
+// SetBrowserDetector) get the paywall when allowed; API clients get the best
+// match for their Accept header among JSON, CBOR, and registered media types,
+// with JSON preferred on ties and used when nothing else is acceptable.
+func (s *x402HTTPResourceServer) negotiateMediaType(reqCtx HTTPRequestContext, allowHTML bool) string {
+	if allowHTML && s.isWebBrowser(reqCtx) {
+		return MediaTypeHTML
+	}
+	if reqCtx.Adapter == nil {
+		return MediaTypeJSON
+	}
+	offers := []string{MediaTypeJSON, MediaTypeX402CBOR}
+	for _, mediaType := range s.mediaTypes {
+		if mediaType != MediaTypeJSON && mediaType != MediaTypeX402CBOR {
+			offers = append(offers, mediaType)
+		}
+	}
+	if mediaType, ok := NegotiateMediaType(reqCtx.Adapter.GetAcceptHeader(), offers); ok {
+		return mediaType
+	}
+	return MediaTypeJSON
+}
+
+// encoderFor returns the encoder for a negotiated non-JSON, non-HTML media type
+func (s *x402HTTPResourceServer) encoderFor(mediaType string) PaymentRequiredEncoder {
+	if encoder, ok := s.mediaEncoders[mediaType]; ok {
+		return encoder
+	}
+	if mediaType == MediaTypeX402CBOR {
+		return EncodePaymentRequiredCBOR
+	}
+	return nil
+}
+
+// paymentRequiredResponse creates the 402 response in the media type negotiated for the request
+func (s *x402HTTPResourceServer) paymentRequiredResponse(reqCtx HTTPRequestContext, paymentRequired types.PaymentRequired, allowHTML bool, paywallConfig *PaywallConfig, customHTML string, unpaidResponse *UnpaidResponse) (*HTTPResponseInstructions, error) {
+	mediaType := s.negotiateMediaType(reqCtx, allowHTML)
+	encoder := s.encoderFor(mediaType)
+	if encoder == nil {
+		return s.createHTTPResponseV2(paymentRequired, mediaType == MediaTypeHTML, paywallConfig, customHTML, unpaidResponse)
+	}
+
+	body, err := encoder(paymentRequired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payment required as %s: %w", mediaType, err)
+	}
+	response, err := s.createHTTPResponseV2(paymentRequired, false, paywallConfig, customHTML, nil)
+	if err != nil {
+		return nil, err
+	}
+	response.Headers["Content-Type"] = mediaType
+	response.Headers["Vary"] = "Accept"
+	response.Body = body
+	return response, nil
+}
+
+// NegotiateMediaType returns the offer the Accept header ranks highest, using
+// q-values and the most specific matching range (exact, type/*, then */*).
+// Ties go to the earlier offer. An empty Accept header accepts the first offer;
+// ok is false when no offer is acceptable.
+func NegotiateMediaType(accept string, offers []string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q := acceptQuality(ranges, strings.ToLower(offer))
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// acceptRange is one media range of an Accept header
+type acceptRange struct {
+	mediaType string // lowercase type/subtype, possibly with wildcards
+	q         float64
+}
+
+// parseAccept parses an Accept header, ignoring malformed ranges
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "*" {
+			mediaType = "*/*"
+		}
+		if !strings.Contains(mediaType, "/") {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the q-value of the most specific range matching offer
+func acceptQuality(ranges []acceptRange, offer string) float64 {
+	offerType, _, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		rangeType, rangeSubtype, _ := strings.Cut(r.mediaType, "/")
+		var s int
+		switch {
+		case r.mediaType == offer:
+			s = 2
+		case rangeType == offerType && rangeSubtype == "*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+	offers := []string{MediaTypeJSON, MediaTypeX402CBOR, "application/vnd.example+yaml"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"empty accepts first offer", "", MediaTypeJSON, true},
+		{"wildcard prefers first offer", "*/*", MediaTypeJSON, true},
+		{"exact match", "application/x402+cbor", MediaTypeX402CBOR, true},
+		{"q-values", "application/json;q=0.5, application/x402+cbor", MediaTypeX402CBOR, true},
+		{"specific range overrides wildcard", "*/*;q=0.9, application/json;q=0.1", MediaTypeX402CBOR, true},
+		{"type wildcard", "application/*", MediaTypeJSON, true},
+		{"case insensitive", "Application/VND.Example+YAML", "application/vnd.example+yaml", true},
+		{"excluded with q=0", "application/json;q=0, application/*", MediaTypeX402CBOR, true},
+		{"nothing acceptable", "text/plain", "", false},
+		{"malformed ranges ignored", "garbage, application/x402+cbor;q=oops, application/json", MediaTypeJSON, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NegotiateMediaType(tt.accept, offers)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("NegotiateMediaType(%q) = %q, %v; want %q, %v", tt.accept, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func newNegotiationTestServer() *x402HTTPResourceServer {
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{
+			"GET /api": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}},
+		},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		panic(err)
+	}
+	return server
+}
+
+func TestPaymentRequiredNegotiation(t *testing.T) {
+	server := newNegotiationTestServer().RegisterMediaType("application/vnd.example+text", func(required types.PaymentRequired) ([]byte, error) {
+		return []byte(required.Accepts[0].PayTo), nil
+	})
+
+	process := func(accept, agent string) *HTTPResponseInstructions {
+		adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", accept: accept, agent: agent}
+		result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+		if result.Type != ResultPaymentError || result.Response == nil || result.Response.Status != 402 {
+			t.Fatalf("Expected 402 response, got %+v", result)
+		}
+		return result.Response
+	}
+
+	response := process("application/json", "")
+	if response.Headers["Content-Type"] != MediaTypeJSON || response.Body != nil {
+		t.Errorf("Expected JSON response, got %+v", response)
+	}
+
+	response = process("text/html", "Mozilla/5.0")
+	if !response.IsHTML {
+		t.Errorf("Expected paywall for browser, got %+v", response)
+	}
+
+	response = process("application/x402+cbor", "")
+	if response.Headers["Content-Type"] != MediaTypeX402CBOR || response.Headers["PAYMENT-REQUIRED"] == "" {
+		t.Errorf("Expected CBOR response with PAYMENT-REQUIRED header, got %+v", response.Headers)
+	}
+	body, ok := response.Body.([]byte)
+	if !ok || !bytes.Contains(body, []byte("0xtest")) {
+		t.Errorf("Expected CBOR body with requirements, got %v", response.Body)
+	}
+
+	response = process("application/vnd.example+text, application/json;q=0.5", "")
+	if response.Headers["Content-Type"] != "application/vnd.example+text" || string(response.Body.([]byte)) != "0xtest" {
+		t.Errorf("Expected custom media type, got %+v", response)
+	}
+}
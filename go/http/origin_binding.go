@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/url"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Origin Binding
+// ============================================================================
+
+// EnableOriginBinding binds every payment to the origin of its resource URL.
+// Requirements carry the origin in extra (types.ResourceOriginExtraKey), so it
+// is part of what the client accepts; clients refuse requirements bound to a
+// different origin than the one they requested, the server rejects payments
+// accepted for another origin, and facilitators check that the accepted
+// requirements hash to the ones the server sent, detecting a payTo that was
+// substituted in transit.
+//
+// The binding is advisory: the origin is not covered by the payer's
+// signature, so a relaying party can rewrite it together with the accepted
+// requirements. It guards honest clients and catches mismatches, not replays
+// of a payment against another origin.
+func (s *x402HTTPResourceServer) EnableOriginBinding() *x402HTTPResourceServer {
+	s.bindOrigin = true
+	return s
+}
+
+// bindRequirementsToOrigin adds the origin to a copy of each requirement's extra
+func bindRequirementsToOrigin(requirements []types.PaymentRequirements, origin string) {
+	for i := range requirements {
+		// Copy so a shared Extra map from the route config is never mutated
+		extra := make(map[string]interface{}, len(requirements[i].Extra)+1)
+		for k, v := range requirements[i].Extra {
+			extra[k] = v
+		}
+		extra[types.ResourceOriginExtraKey] = origin
+		requirements[i].Extra = extra
+	}
+}
+
+// acceptedOriginMatches reports whether the payload's accepted requirements carry the expected origin
+func acceptedOriginMatches(acceptedExtra map[string]interface{}, expected string) bool {
+	origin, ok := types.ExtraValue[string](acceptedExtra, types.ResourceOriginExtraKey)
+	return ok && origin == expected
+}
+
+// filterRequirementsByOrigin drops requirements bound to an origin other than
+// the one of the requested URL; unbound requirements are kept
+func filterRequirementsByOrigin(requirements []types.PaymentRequirements, requested *url.URL) []types.PaymentRequirements {
+	if requested == nil {
+		return requirements
+	}
+	origin, err := types.ResourceOrigin(requested.String())
+	if err != nil {
+		return requirements
+	}
+
+	filtered := make([]types.PaymentRequirements, 0, len(requirements))
+	for _, requirement := range requirements {
+		bound, ok := types.ExtraValue[string](requirement.Extra, types.ResourceOriginExtraKey)
+		if ok && bound != origin {
+			continue
+		}
+		filtered = append(filtered, requirement)
+	}
+	return filtered
+}
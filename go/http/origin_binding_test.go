@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestOriginBindingServer(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /data": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}}},
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	).EnableOriginBinding()
+	_ = server.Initialize(context.Background())
+
+	request := func(headers map[string]string) HTTPProcessResult {
+		return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+			Adapter: &mockHTTPAdapter{method: "GET", path: "/data", url: "https://API.example.com/data", headers: headers},
+			Path:    "/data",
+			Method:  "GET",
+		}, nil)
+	}
+
+	unpaid := request(nil)
+	required, err := decodePaymentRequiredHeader(unpaid.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	accepted := required.Accepts[0]
+	if got := accepted.Extra[types.ResourceOriginExtraKey]; got != "https://api.example.com" {
+		t.Fatalf("Expected origin in extra, got %v", got)
+	}
+
+	pay := func(accepted types.PaymentRequirements) HTTPProcessResult {
+		payloadJSON, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"sig": "test"}, Accepted: accepted})
+		return request(map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadJSON)})
+	}
+
+	if result := pay(accepted); result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected verified payment, got %s (%+v)", result.Type, result.Response)
+	}
+
+	accepted.Extra = map[string]interface{}{types.ResourceOriginExtraKey: "https://phishing.example"}
+	result := pay(accepted)
+	if result.Type != ResultPaymentError || result.Response.Status != 402 {
+		t.Fatalf("Expected 402 for another origin, got %s (%+v)", result.Type, result.Response)
+	}
+	rejected, _ := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if rejected.Error != "Payment is bound to a different origin" {
+		t.Errorf("Unexpected error %q", rejected.Error)
+	}
+}
+
+func TestOriginBindingClientRefusesOtherOrigin(t *testing.T) {
+	required := largePaymentRequired(1)
+	required.Accepts[0].Extra = map[string]interface{}{types.ResourceOriginExtraKey: "https://api.example.com"}
+	encoded, _ := encodePaymentRequiredHeader(required)
+
+	paid := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PAYMENT-SIGNATURE") != "" {
+			paid = true
+		}
+		w.Header().Set(PaymentRequiredHeader, encoded)
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client))
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	resp, err := httpClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected the client to refuse requirements bound to another origin")
+	}
+	if !strings.Contains(err.Error(), "different origin") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if paid {
+		t.Error("Expected no payment to be sent")
+	}
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Pagination Pricing
+// ============================================================================
+
+// PaginationConfig prices a list route per page request or per returned item.
+//
+// Per page, every request is charged the route's price, however many items
+// the page holds. Per item, the route's price authorizes a full page of
+// MaxItems items (see PerItemPrice) and the handler reports how many it
+// returned with ReportItemCount; only that share is settled. Per-item routes
+// need a scheme that can settle less than it authorizes, such as upto.
+//
+//	price, _ := x402http.PerItemPrice("$0.001", 100)
+//	routes := x402http.RoutesConfig{
+//	    "GET /api/records": {
+//	        Accepts:    x402http.PaymentOptions{{Scheme: "upto", Network: "eip155:8453", PayTo: payTo, Price: price}},
+//	        Pagination: &x402http.PaginationConfig{PerItem: true, MaxItems: 100},
+//	    },
+//	}
+//
+//	func listRecords(w http.ResponseWriter, r *http.Request) {
+//	    records := db.Page(r.URL.Query().Get("cursor"), 100)
+//	    x402http.ReportItemCount(r.Context(), len(records))
+//	    json.NewEncoder(w).Encode(records)
+//	}
+type PaginationConfig struct {
+	// PerItem settles for the items reported by the handler instead of the
+	// authorized amount. A handler that reports nothing is charged in full.
+	PerItem bool `json:"perItem,omitempty"`
+
+	// MaxItems is the most items a page returns, which the route's price
+	// covers. Required for PerItem; counts above it are charged as MaxItems.
+	MaxItems int `json:"maxItems,omitempty"`
+}
+
+// validate checks the configuration
+func (c *PaginationConfig) validate() error {
+	if c.PerItem && c.MaxItems <= 0 {
+		return errors.New("per-item pagination needs MaxItems")
+	}
+	return nil
+}
+
+// PerItemPrice returns the price authorizing a page of maxItems items at
+// pricePerItem, for a per-item route's payment option. pricePerItem may be a
+// money string ("$0.001"), a number, or an x402.AssetAmount.
+func PerItemPrice(pricePerItem x402.Price, maxItems int) (x402.Price, error) {
+	if maxItems <= 0 {
+		return nil, fmt.Errorf("invalid page size %d", maxItems)
+	}
+	return scalePrice(pricePerItem, float64(maxItems))
+}
+
+// ReportItemCount records how many items a paid list request returned, for
+// per-item routes to settle. Call it from the handler with the request's
+// context before the response is complete; it does nothing for unpaid
+// requests.
+func ReportItemCount(ctx context.Context, count int) {
+	identity, ok := PaymentIdentityFromContext(ctx)
+	if !ok {
+		return
+	}
+	if count < 0 {
+		count = 0
+	}
+	identity.itemCount = &count
+}
+
+// ItemCount returns the item count the handler reported, if any
+func (p *PaymentIdentity) ItemCount() (int, bool) {
+	if p.itemCount == nil {
+		return 0, false
+	}
+	return *p.itemCount, true
+}
+
+// ItemSettlementAmount returns the atomic amount to settle for a verified
+// request with ProcessSettlementAmount: the authorized amount scaled by the
+// items reported on identity for per-item routes, rounded up. It is "" (the
+// full amount) for per-page routes and when no count was reported, and "0"
+// when the page was empty; release the payment instead of settling nothing.
+func ItemSettlementAmount(result HTTPProcessResult, identity *PaymentIdentity) string {
+	if result.Pagination == nil || !result.Pagination.PerItem || result.PaymentRequirements == nil || identity == nil {
+		return ""
+	}
+	count, ok := identity.ItemCount()
+	maxItems := result.Pagination.MaxItems
+	if !ok || maxItems <= 0 || count >= maxItems {
+		return ""
+	}
+	authorized, ok := new(big.Int).SetString(result.PaymentRequirements.Amount, 10)
+	if !ok {
+		return ""
+	}
+
+	amount := new(big.Int).Mul(authorized, big.NewInt(int64(count)))
+	amount, remainder := amount.QuoRem(amount, big.NewInt(int64(maxItems)), new(big.Int))
+	if remainder.Sign() > 0 {
+		amount.Add(amount, big.NewInt(1))
+	}
+	return amount.String()
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestPerItemPrice(t *testing.T) {
+	cases := []struct {
+		perItem x402.Price
+		want    x402.Price
+	}{
+		{"$0.001", "$0.100000"},
+		{"0.0005", "0.050000"},
+		{x402.AssetAmount{Asset: "USDC", Amount: "10"}, x402.AssetAmount{Asset: "USDC", Amount: "1000"}},
+	}
+	for _, c := range cases {
+		price, err := PerItemPrice(c.perItem, 100)
+		if err != nil || !reflect.DeepEqual(price, c.want) {
+			t.Errorf("PerItemPrice(%v, 100) = %v, %v; want %v", c.perItem, price, err, c.want)
+		}
+	}
+	if _, err := PerItemPrice("$0.001", 0); err == nil {
+		t.Error("Expected an error for an empty page")
+	}
+}
+
+func TestItemSettlementAmount(t *testing.T) {
+	result := HTTPProcessResult{
+		PaymentRequirements: &types.PaymentRequirements{Amount: "100000"},
+		Pagination:          &PaginationConfig{PerItem: true, MaxItems: 30},
+	}
+	report := func(count int) *PaymentIdentity {
+		identity := NewPaymentIdentity(result)
+		ReportItemCount(ContextWithPaymentIdentity(context.Background(), identity), count)
+		return identity
+	}
+
+	cases := map[int]string{
+		10: "33334", // rounded up
+		15: "50000",
+		0:  "0",
+		-1: "0",
+		30: "",
+		45: "",
+	}
+	for count, want := range cases {
+		if got := ItemSettlementAmount(result, report(count)); got != want {
+			t.Errorf("%d items: amount = %q, want %q", count, got, want)
+		}
+	}
+
+	// Without a count the full authorization is settled
+	if got := ItemSettlementAmount(result, NewPaymentIdentity(result)); got != "" {
+		t.Errorf("Expected the full amount without a count, got %q", got)
+	}
+
+	// Per-page routes always settle the full amount
+	perPage := result
+	perPage.Pagination = &PaginationConfig{MaxItems: 30}
+	if got := ItemSettlementAmount(perPage, report(10)); got != "" {
+		t.Errorf("Expected per-page routes to settle in full, got %q", got)
+	}
+
+	// Reporting outside a paid request is harmless
+	ReportItemCount(context.Background(), 5)
+}
+
+func TestProcessHTTPRequestCarriesPagination(t *testing.T) {
+	pagination := &PaginationConfig{PerItem: true, MaxItems: 100}
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{
+			"GET /records": {
+				Accepts:    PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+				Pagination: pagination,
+			},
+		},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	adapter := &mockHTTPAdapter{
+		method:  "GET",
+		path:    "/records",
+		url:     "http://example.com/records",
+		headers: map[string]string{},
+	}
+	reqCtx := HTTPRequestContext{Adapter: adapter, Path: "/records", Method: "GET"}
+	adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+	result := server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	if result.Type != ResultPaymentVerified || result.Pagination != pagination {
+		t.Fatalf("Expected a verified result with the route's pagination, got %+v", result)
+	}
+
+	identity := NewPaymentIdentity(result)
+	ReportItemCount(ContextWithPaymentIdentity(context.Background(), identity), 25)
+	if got := ItemSettlementAmount(result, identity); got != "250000" {
+		t.Errorf("Expected a quarter of the authorization, got %q", got)
+	}
+}
@@ -0,0 +1,305 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Paid Downloads
+// ============================================================================
+
+// DownloadTokenHeader carries a download token. Paid download responses set
+// it, and requests carrying it (or the DownloadTokenParam query parameter)
+// resume the download without paying again.
+const DownloadTokenHeader = "X-402-Download-Token"
+
+// DownloadTokenParam is the query parameter alternative to DownloadTokenHeader,
+// for download managers that cannot set headers
+const DownloadTokenParam = "x402-download-token"
+
+// PaidDownloadConfig configures PaidDownloads
+type PaidDownloadConfig struct {
+	// Files holds the downloadable files. Files should implement io.Seeker
+	// (os.DirFS and embed.FS files do); others are read into memory.
+	Files fs.FS
+
+	// Prefix is the URL path files are served under (e.g. "/downloads/")
+	Prefix string
+
+	// Price is charged once per file. The download token then covers every
+	// range of the file, so interrupted downloads resume for free.
+	Price x402.Price
+
+	// PricePerMB charges for the requested byte range instead, in USD per
+	// megabyte (10^6 bytes), rounded up to the nearest micro-dollar. The
+	// download token covers the range that was paid for.
+	PricePerMB float64
+
+	// Secret signs download tokens
+	Secret []byte
+
+	// TokenTTL is how long a download token is valid (default: 24 hours)
+	TokenTTL time.Duration
+}
+
+// PaidDownloads serves files behind x402 with Range request support.
+// Register Price as a route's DynamicPriceFunc, Bypass on the HTTP server,
+// and the PaidDownloads itself as the route's handler:
+//
+//	downloads, _ := x402http.NewPaidDownloads(x402http.PaidDownloadConfig{
+//	    Files: os.DirFS("./files"), Prefix: "/downloads/", Price: "$0.50", Secret: secret,
+//	})
+//	routes := x402http.RoutesConfig{"GET /downloads/*": {Accepts: x402http.PaymentOptions{
+//	    {Scheme: "exact", Network: "eip155:8453", PayTo: payTo, Price: x402http.DynamicPriceFunc(downloads.Price)},
+//	}}}
+//	server.AddBypass(downloads.Bypass)
+//	mux.Handle("/downloads/", downloads)
+type PaidDownloads struct {
+	config PaidDownloadConfig
+}
+
+// NewPaidDownloads creates a paid download handler
+func NewPaidDownloads(config PaidDownloadConfig) (*PaidDownloads, error) {
+	if config.Files == nil {
+		return nil, errors.New("paid downloads need Files")
+	}
+	if len(config.Secret) == 0 {
+		return nil, errors.New("paid downloads need a Secret to sign download tokens")
+	}
+	if config.Price == nil && config.PricePerMB <= 0 {
+		return nil, errors.New("paid downloads need a Price or PricePerMB")
+	}
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = 24 * time.Hour
+	}
+	config.Prefix = "/" + strings.Trim(config.Prefix, "/")
+	return &PaidDownloads{config: config}, nil
+}
+
+// Price prices a download request, as a DynamicPriceFunc: Price for the
+// whole file, or PricePerMB for the requested range
+func (d *PaidDownloads) Price(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+	name, ok := d.fileName(reqCtx.Path)
+	if !ok {
+		return nil, fmt.Errorf("no file at %s", reqCtx.Path)
+	}
+	info, err := fs.Stat(d.config.Files, name)
+	if err != nil || info.IsDir() {
+		return nil, fmt.Errorf("no file at %s", reqCtx.Path)
+	}
+	if d.config.PricePerMB <= 0 {
+		return d.config.Price, nil
+	}
+
+	rangeHeader := ""
+	if reqCtx.Adapter != nil {
+		rangeHeader = reqCtx.Adapter.GetHeader("Range")
+	}
+	start, end := requestedRange(rangeHeader, info.Size())
+	micros := int64(math.Ceil(float64(end-start+1) * d.config.PricePerMB))
+	if micros < 1 {
+		micros = 1
+	}
+	return fmt.Sprintf("$%d.%06d", micros/1_000_000, micros%1_000_000), nil
+}
+
+// Bypass lets requests with a valid download token covering the requested
+// range through without payment, as a BypassFunc
+func (d *PaidDownloads) Bypass(ctx context.Context, reqCtx HTTPRequestContext) bool {
+	if reqCtx.Adapter == nil {
+		return false
+	}
+	token := reqCtx.Adapter.GetHeader(DownloadTokenHeader)
+	if token == "" {
+		if u, err := url.Parse(reqCtx.Adapter.GetURL()); err == nil {
+			token = u.Query().Get(DownloadTokenParam)
+		}
+	}
+	if token == "" {
+		return false
+	}
+
+	name, ok := d.fileName(reqCtx.Path)
+	if !ok {
+		return false
+	}
+	info, err := fs.Stat(d.config.Files, name)
+	if err != nil {
+		return false
+	}
+	paidStart, paidEnd, ok := d.verifyToken(token, name, time.Now())
+	if !ok {
+		return false
+	}
+	start, end := requestedRange(reqCtx.Adapter.GetHeader("Range"), info.Size())
+	return start >= paidStart && end <= paidEnd
+}
+
+// ServeHTTP serves the file, honoring Range and conditional requests. Paid
+// responses carry a DownloadTokenHeader for resuming the download.
+func (d *PaidDownloads) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := d.fileName(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := d.config.Files.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, "failed to read file", http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	// Requests that get here with a payment were paid for
+	if r.Header.Get("PAYMENT-SIGNATURE") != "" || r.Header.Get("X-PAYMENT") != "" {
+		start, end := int64(0), info.Size()-1
+		if d.config.PricePerMB > 0 {
+			start, end = requestedRange(r.Header.Get("Range"), info.Size())
+		}
+		w.Header().Set(DownloadTokenHeader, d.signToken(name, start, end, time.Now().Add(d.config.TokenTTL)))
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+// fileName maps a request path under Prefix to a file name in Files
+func (d *PaidDownloads) fileName(requestPath string) (string, bool) {
+	requestPath = path.Clean("/" + normalizePath(requestPath))
+	prefix := strings.TrimSuffix(d.config.Prefix, "/") + "/"
+	name, ok := strings.CutPrefix(requestPath, prefix)
+	if !ok || !fs.ValidPath(name) || name == "." {
+		return "", false
+	}
+	return name, true
+}
+
+// signToken creates a download token for bytes start-end of a file, valid until expires
+func (d *PaidDownloads) signToken(name string, start, end int64, expires time.Time) string {
+	claims := strconv.FormatInt(expires.Unix(), 10) + "." + strconv.FormatInt(start, 10) + "-" + strconv.FormatInt(end, 10)
+	return claims + "." + d.tokenSignature(claims, name)
+}
+
+// verifyToken checks a download token for a file and returns the byte range it covers
+func (d *PaidDownloads) verifyToken(token string, name string, now time.Time) (int64, int64, bool) {
+	claims, signature, ok := cutLast(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(d.tokenSignature(claims, name))) {
+		return 0, 0, false
+	}
+	expires, byteRange, ok := strings.Cut(claims, ".")
+	if !ok {
+		return 0, 0, false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.After(time.Unix(unix, 0)) {
+		return 0, 0, false
+	}
+	startText, endText, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err1 := strconv.ParseInt(startText, 10, 64)
+	end, err2 := strconv.ParseInt(endText, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// tokenSignature is the hex HMAC-SHA256 of a token's claims and file name
+func (d *PaidDownloads) tokenSignature(claims, name string) string {
+	mac := hmac.New(sha256.New, d.config.Secret)
+	mac.Write([]byte(claims + "\n" + name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// requestedRange returns the inclusive byte span a Range header asks for:
+// from the lowest start to the highest end of its ranges. Missing or
+// unsatisfiable headers ask for the whole file.
+func requestedRange(header string, size int64) (int64, int64) {
+	whole := func() (int64, int64) { return 0, size - 1 }
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || size <= 0 {
+		return whole()
+	}
+
+	start, end := int64(-1), int64(-1)
+	for _, part := range strings.Split(spec, ",") {
+		first, last, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return whole()
+		}
+		var from, to int64
+		switch {
+		case first == "":
+			// Suffix range: the last n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n <= 0 {
+				return whole()
+			}
+			from, to = max(size-n, 0), size-1
+		default:
+			var err error
+			if from, err = strconv.ParseInt(first, 10, 64); err != nil || from >= size {
+				return whole()
+			}
+			to = size - 1
+			if last != "" {
+				if to, err = strconv.ParseInt(last, 10, 64); err != nil || to < from {
+					return whole()
+				}
+				to = min(to, size-1)
+			}
+		}
+		if start < 0 || from < start {
+			start = from
+		}
+		if to > end {
+			end = to
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testDownloads(t *testing.T, config PaidDownloadConfig) *PaidDownloads {
+	t.Helper()
+	config.Files = fstest.MapFS{"big.bin": {Data: []byte(strings.Repeat("x", 2_500_000)), ModTime: time.Unix(1700000000, 0)}}
+	config.Prefix = "/downloads/"
+	config.Secret = []byte("secret")
+	downloads, err := NewPaidDownloads(config)
+	if err != nil {
+		t.Fatalf("Failed to create paid downloads: %v", err)
+	}
+	return downloads
+}
+
+func downloadContext(path string, headers map[string]string) HTTPRequestContext {
+	return HTTPRequestContext{
+		Adapter: &mockHTTPAdapter{headers: headers, method: "GET", path: path, url: "http://example.com" + path},
+		Path:    path,
+		Method:  "GET",
+	}
+}
+
+func TestPaidDownloadsWholeFile(t *testing.T) {
+	ctx := context.Background()
+	downloads := testDownloads(t, PaidDownloadConfig{Price: "$0.50"})
+
+	if price, err := downloads.Price(ctx, downloadContext("/downloads/big.bin", map[string]string{"Range": "bytes=0-99"})); err != nil || price != "$0.50" {
+		t.Errorf("Expected the whole-file price for any range, got %v (%v)", price, err)
+	}
+	if _, err := downloads.Price(ctx, downloadContext("/downloads/missing.bin", nil)); err == nil {
+		t.Error("Expected an error pricing a missing file")
+	}
+	if _, err := downloads.Price(ctx, downloadContext("/downloads/../secret", nil)); err == nil {
+		t.Error("Expected an error pricing a path outside the prefix")
+	}
+
+	// A paid request gets a token covering the whole file
+	req := httptest.NewRequest(http.MethodGet, "/downloads/big.bin", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", "paid")
+	req.Header.Set("Range", "bytes=0-999")
+	rec := httptest.NewRecorder()
+	downloads.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.Len() != 1000 {
+		t.Fatalf("Expected 1000 bytes of partial content, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+	token := rec.Header().Get(DownloadTokenHeader)
+	if token == "" {
+		t.Fatal("Expected a download token on the paid response")
+	}
+
+	// The token resumes the download from anywhere, by header or query
+	if !downloads.Bypass(ctx, downloadContext("/downloads/big.bin", map[string]string{DownloadTokenHeader: token, "Range": "bytes=1000-"})) {
+		t.Error("Expected the token to cover the rest of the file")
+	}
+	queryCtx := downloadContext("/downloads/big.bin", nil)
+	queryCtx.Adapter.(*mockHTTPAdapter).url += "?" + DownloadTokenParam + "=" + token
+	if !downloads.Bypass(ctx, queryCtx) {
+		t.Error("Expected the token to work as a query parameter")
+	}
+	if downloads.Bypass(ctx, downloadContext("/downloads/big.bin", map[string]string{DownloadTokenHeader: token + "0"})) {
+		t.Error("Expected a tampered token to be rejected")
+	}
+	if downloads.Bypass(ctx, downloadContext("/downloads/big.bin", nil)) {
+		t.Error("Expected requests without a token to pay")
+	}
+
+	// Unpaid requests get no token
+	rec = httptest.NewRecorder()
+	downloads.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/downloads/big.bin", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(DownloadTokenHeader) != "" {
+		t.Errorf("Expected the file without a token, got %d %q", rec.Code, rec.Header().Get(DownloadTokenHeader))
+	}
+}
+
+func TestPaidDownloadsPerRange(t *testing.T) {
+	ctx := context.Background()
+	downloads := testDownloads(t, PaidDownloadConfig{PricePerMB: 0.01})
+
+	cases := []struct {
+		rangeHeader string
+		price       string
+	}{
+		{"", "$0.025000"},                          // 2.5 MB
+		{"bytes=0-999999", "$0.010000"},            // 1 MB
+		{"bytes=-500000", "$0.005000"},             // last 0.5 MB
+		{"bytes=0-0", "$0.000001"},                 // rounds up to a micro-dollar
+		{"bytes=0-9,2000000-2000009", "$0.020001"}, // spans from the first start to the last end
+		{"bytes=9999999-", "$0.025000"},            // unsatisfiable: whole file
+	}
+	for _, c := range cases {
+		price, err := downloads.Price(ctx, downloadContext("/downloads/big.bin", map[string]string{"Range": c.rangeHeader}))
+		if err != nil || price != c.price {
+			t.Errorf("Range %q: expected %s, got %v (%v)", c.rangeHeader, c.price, price, err)
+		}
+	}
+
+	// A token covers only the range that was paid for
+	token := downloads.signToken("big.bin", 0, 999_999, time.Now().Add(time.Hour))
+	if !downloads.Bypass(ctx, downloadContext("/downloads/big.bin", map[string]string{DownloadTokenHeader: token, "Range": "bytes=500000-999999"})) {
+		t.Error("Expected the token to cover a range it paid for")
+	}
+	if downloads.Bypass(ctx, downloadContext("/downloads/big.bin", map[string]string{DownloadTokenHeader: token, "Range": "bytes=500000-1000000"})) {
+		t.Error("Expected the token not to cover bytes beyond the paid range")
+	}
+
+	expired := downloads.signToken("big.bin", 0, 2_499_999, time.Now().Add(-time.Second))
+	if downloads.Bypass(ctx, downloadContext("/downloads/big.bin", map[string]string{DownloadTokenHeader: expired})) {
+		t.Error("Expected an expired token to be rejected")
+	}
+}
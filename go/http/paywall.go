@@ -0,0 +1,159 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/networks"
+)
+
+// ============================================================================
+// Paywall Rendering
+// ============================================================================
+
+// PaywallRenderOptions controls how RenderPaywallHTML produces its output
+type PaywallRenderOptions struct {
+	// Template replaces the built-in EVM/SVM template (optional).
+	// The configuration script is injected before the first </body> tag and
+	// theme styles (PaywallConfig.Theme) before the first </head> tag.
+	Template string
+
+	// Nonce is added to every <script> and <style> tag, including the injected
+	// configuration block, so the page is allowed by a nonce-based
+	// Content-Security-Policy (optional). See RenderPaywall.
+	Nonce string
+}
+
+// RenderPaywallHTML renders the paywall HTML for a PaymentRequired response.
+//
+// Output is deterministic: the same inputs always produce byte-identical HTML.
+// Struct fields are emitted in declaration order and map keys (e.g. Extra,
+// Extensions) are sorted, so the result is suitable for golden-file testing of
+// both the built-in and custom templates.
+//
+// Args:
+//
+//	paymentRequired: The payment required response to embed
+//	config: Optional paywall configuration
+//	opts: Rendering options (custom template, CSP nonce)
+//
+// Returns:
+//
+//	The complete paywall HTML document
+func RenderPaywallHTML(paymentRequired x402.PaymentRequired, config *PaywallConfig, opts PaywallRenderOptions) string {
+	return RenderPaywall(paymentRequired, config, opts).HTML
+}
+
+// renderPaywallConfigScript renders the <script> block that exposes window.x402 to the template
+func renderPaywallConfigScript(paymentRequired x402.PaymentRequired, config *PaywallConfig, nonce string) string {
+	// Calculate display amount (assuming USDC with 6 decimals)
+	displayAmount := getDisplayAmount(paymentRequired)
+
+	appName := ""
+	appLogo := ""
+	testnet := false
+	currentURL := ""
+	walletConnectProjectID := ""
+	walletDiscovery := true
+	payToNames := ""
+
+	if config != nil {
+		appName = config.AppName
+		appLogo = config.AppLogo
+		testnet = config.Testnet
+		currentURL = config.CurrentURL
+		walletConnectProjectID = config.WalletConnectProjectID
+		walletDiscovery = !config.DisableWalletDiscovery
+		if len(config.PayToNames) > 0 {
+			namesJSON, _ := json.Marshal(config.PayToNames)
+			payToNames = fmt.Sprintf(",\n\t\t\tpayToNames: %s", namesJSON)
+		}
+	}
+
+	// Use resource URL as currentUrl if not explicitly configured
+	if currentURL == "" && paymentRequired.Resource != nil {
+		currentURL = paymentRequired.Resource.URL
+	}
+
+	requirementsJSON, _ := json.Marshal(paymentRequired)
+
+	scriptTag := "<script>"
+	if nonce != "" {
+		scriptTag = fmt.Sprintf(`<script nonce="%s">`, html.EscapeString(nonce))
+	}
+
+	// Inject configuration into the template
+	return fmt.Sprintf(`%s
+		window.x402 = {
+			paymentRequired: %s,
+			appName: "%s",
+			appLogo: "%s",
+			amount: %.6f,
+			testnet: %t,
+			displayAmount: %.2f,
+			currentUrl: "%s",
+			walletConnectProjectId: "%s",
+			walletDiscovery: %t%s
+		};
+	</script>`,
+		scriptTag,
+		string(requirementsJSON),
+		html.EscapeString(appName),
+		html.EscapeString(appLogo),
+		displayAmount,
+		testnet,
+		displayAmount,
+		html.EscapeString(currentURL),
+		html.EscapeString(walletConnectProjectID),
+		walletDiscovery,
+		payToNames,
+	)
+}
+
+// paywallNetwork returns the network of the first payment option, which the paywall presents
+func paywallNetwork(paymentRequired x402.PaymentRequired) x402.Network {
+	if len(paymentRequired.Accepts) == 0 {
+		return ""
+	}
+	return x402.Network(paymentRequired.Accepts[0].Network)
+}
+
+// selectPaywallTemplate chooses the appropriate paywall template based on the network family
+// Returns the SVM template for Solana networks and the EVM template otherwise
+func selectPaywallTemplate(paymentRequired x402.PaymentRequired) string {
+	if paywallNetwork(paymentRequired).Family() == networks.FamilySVM {
+		return SVMPaywallTemplate
+	}
+	return EVMPaywallTemplate
+}
+
+// getDisplayAmount extracts display amount from payment requirements
+func getDisplayAmount(paymentRequired x402.PaymentRequired) float64 {
+	if len(paymentRequired.Accepts) > 0 {
+		firstReq := paymentRequired.Accepts[0]
+		// Check if amount field exists
+		if firstReq.Amount != "" {
+			// V2 format - parse amount
+			amount, err := strconv.ParseFloat(firstReq.Amount, 64)
+			if err == nil {
+				return amount / math.Pow10(displayDecimals(firstReq))
+			}
+		}
+	}
+	return 0.0
+}
+
+// displayDecimals returns the decimals of the requirement's asset: the
+// network's default asset when it matches, otherwise USDC's 6 decimals
+func displayDecimals(requirements x402.PaymentRequirements) int {
+	asset, ok := x402.Network(requirements.Network).DefaultAsset()
+	if ok && strings.EqualFold(asset.Address, requirements.Asset) {
+		return asset.Decimals
+	}
+	return 6
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+	"golang.org/x/net/html"
+)
+
+// ============================================================================
+// Content-Security-Policy Support
+// ============================================================================
+
+// ContentSecurityPolicyHeader is the response header carrying the paywall CSP
+const ContentSecurityPolicyHeader = "Content-Security-Policy"
+
+// RenderedPaywall is the paywall HTML together with the CSP needed to serve it
+type RenderedPaywall struct {
+	// HTML is the complete paywall document
+	HTML string
+
+	// Nonce is the nonce applied to every <script> and <style> element (empty if none was requested)
+	Nonce string
+
+	// ScriptHashes are CSP hash sources ('sha256-...') for every inline script,
+	// including the bundled wallet JS and the injected configuration block.
+	// They pin the exact bundled code, the inline equivalent of subresource integrity.
+	ScriptHashes []string
+
+	// StyleHashes are CSP hash sources for every inline <style> element
+	StyleHashes []string
+
+	// ContentSecurityPolicy is a ready-to-use Content-Security-Policy header value
+	ContentSecurityPolicy string
+}
+
+// RenderPaywall renders the paywall and computes the Content-Security-Policy
+// required to serve it under a strict policy.
+//
+// When opts.Nonce is set, the nonce is applied to every script and style element
+// in the template (not only the injected configuration block) and included in
+// the policy. Script hashes are always included, so the policy also works for
+// caches or CDNs that strip nonces.
+//
+// Example:
+//
+//	nonce, _ := x402http.GeneratePaywallNonce()
+//	paywall := x402http.RenderPaywall(paymentRequired, config, x402http.PaywallRenderOptions{Nonce: nonce})
+//	w.Header().Set(x402http.ContentSecurityPolicyHeader, paywall.ContentSecurityPolicy)
+//	w.Write([]byte(paywall.HTML))
+func RenderPaywall(paymentRequired x402.PaymentRequired, config *PaywallConfig, opts PaywallRenderOptions) RenderedPaywall {
+	template := opts.Template
+	if template == "" {
+		template = selectPaywallTemplate(paymentRequired)
+	}
+
+	var info *paywallTemplateInfo
+	var themeCSS string
+	if config != nil {
+		themeCSS = renderThemeCSS(config.Theme)
+		if substituted := applyTemplateVariables(template, config.TemplateVariables); substituted != template {
+			// Substituted templates vary per config, so they bypass the template cache
+			template = substituted
+			info = parsePaywallTemplate(template)
+		}
+	}
+	if info == nil {
+		info = analyzePaywallTemplate(template)
+	}
+
+	configScript := renderPaywallConfigScript(paymentRequired, config, opts.Nonce)
+
+	// Apply the nonce to the template's own script/style tags
+	if opts.Nonce != "" {
+		template = applyNonce(template, info.tagOffsets, opts.Nonce)
+	}
+
+	// Theme overrides go last in <head> so they win over the template's own styles
+	styleHashes := append([]string{}, info.styleHashes...)
+	if themeCSS != "" {
+		template = strings.Replace(template, "</head>", renderThemeStyle(themeCSS, opts.Nonce)+"</head>", 1)
+		styleHashes = append(styleHashes, cspHash(themeCSS))
+	}
+
+	scriptHashes := append([]string{}, info.scriptHashes...)
+	scriptHashes = append(scriptHashes, cspHash(paywallConfigScriptBody(configScript)))
+
+	return RenderedPaywall{
+		HTML:                  strings.Replace(template, "</body>", configScript+"</body>", 1),
+		Nonce:                 opts.Nonce,
+		ScriptHashes:          scriptHashes,
+		StyleHashes:           styleHashes,
+		ContentSecurityPolicy: BuildPaywallCSP(opts.Nonce, scriptHashes, styleHashes),
+	}
+}
+
+// GeneratePaywallNonce returns a random base64 nonce suitable for a CSP nonce-source.
+// A new nonce must be generated for every response.
+func GeneratePaywallNonce() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// BuildPaywallCSP builds a Content-Security-Policy value for the paywall.
+//
+// Scripts and styles are restricted to the given nonce and hashes. Network,
+// image, and frame sources are limited to HTTPS (and WSS for wallet relays),
+// since wallets and RPC endpoints vary per deployment.
+func BuildPaywallCSP(nonce string, scriptHashes []string, styleHashes []string) string {
+	scriptSrc := []string{"'self'"}
+	styleSrc := []string{"'self'"}
+	if nonce != "" {
+		scriptSrc = append(scriptSrc, fmt.Sprintf("'nonce-%s'", nonce))
+		styleSrc = append(styleSrc, fmt.Sprintf("'nonce-%s'", nonce))
+	}
+	scriptSrc = append(scriptSrc, scriptHashes...)
+	styleSrc = append(styleSrc, styleHashes...)
+
+	directives := []string{
+		"default-src 'self'",
+		"script-src " + strings.Join(scriptSrc, " "),
+		"style-src " + strings.Join(styleSrc, " "),
+		"img-src 'self' https: data:",
+		"font-src 'self' https: data:",
+		"connect-src 'self' https: wss:",
+		"frame-src 'self' https:",
+		"object-src 'none'",
+		"base-uri 'none'",
+	}
+	return strings.Join(directives, "; ")
+}
+
+// paywallTemplateInfo caches the parsed structure of a template
+type paywallTemplateInfo struct {
+	// tagOffsets are byte offsets just after "<script" / "<style" in each start tag
+	tagOffsets   []int
+	scriptHashes []string
+	styleHashes  []string
+}
+
+// paywallTemplateCache maps template contents to their parsed structure.
+// Templates are large and parsed once; the map holds only a handful of entries.
+var paywallTemplateCache sync.Map
+
+// analyzePaywallTemplate returns the structure of a template, parsing it on first use
+func analyzePaywallTemplate(template string) *paywallTemplateInfo {
+	if cached, ok := paywallTemplateCache.Load(template); ok {
+		return cached.(*paywallTemplateInfo)
+	}
+
+	actual, _ := paywallTemplateCache.LoadOrStore(template, parsePaywallTemplate(template))
+	return actual.(*paywallTemplateInfo)
+}
+
+// parsePaywallTemplate tokenizes a template to locate script/style elements and hash inline bodies
+func parsePaywallTemplate(template string) *paywallTemplateInfo {
+	info := &paywallTemplateInfo{}
+	tokenizer := html.NewTokenizer(strings.NewReader(template))
+	offset := 0
+	inElement := ""
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		raw := tokenizer.Raw()
+
+		switch tokenType {
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "script" || tag == "style" {
+				info.tagOffsets = append(info.tagOffsets, offset+1+len(tag))
+				inElement = tag
+			}
+		case html.TextToken:
+			switch inElement {
+			case "script":
+				info.scriptHashes = append(info.scriptHashes, cspHash(string(raw)))
+			case "style":
+				info.styleHashes = append(info.styleHashes, cspHash(string(raw)))
+			}
+		case html.EndTagToken:
+			inElement = ""
+		}
+
+		offset += len(raw)
+	}
+
+	info.scriptHashes = dedupeSorted(info.scriptHashes)
+	info.styleHashes = dedupeSorted(info.styleHashes)
+	return info
+}
+
+// applyNonce inserts a nonce attribute at each recorded tag offset
+func applyNonce(template string, offsets []int, nonce string) string {
+	attr := fmt.Sprintf(` nonce="%s"`, html.EscapeString(nonce))
+
+	var b strings.Builder
+	b.Grow(len(template) + len(offsets)*len(attr))
+	last := 0
+	for _, off := range offsets {
+		b.WriteString(template[last:off])
+		b.WriteString(attr)
+		last = off
+	}
+	b.WriteString(template[last:])
+	return b.String()
+}
+
+// paywallConfigScriptBody extracts the text between the injected <script> tags
+func paywallConfigScriptBody(script string) string {
+	start := strings.Index(script, ">") + 1
+	end := strings.LastIndex(script, "</script>")
+	return script[start:end]
+}
+
+// cspHash returns a CSP hash source for inline content
+func cspHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("'sha256-%s'", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// dedupeSorted sorts and removes duplicates so policies are stable
+func dedupeSorted(values []string) []string {
+	sort.Strings(values)
+	result := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+var updateGolden = flag.Bool("update", false, "update paywall golden files")
+
+// goldenTemplate is a minimal template so golden files stay readable
+const goldenTemplate = `<!DOCTYPE html><html><head><title>Payment Required</title></head><body><div id="root"></div></body></html>`
+
+func goldenPaymentRequired(network, asset, payTo string) x402.PaymentRequired {
+	return x402.PaymentRequired{
+		X402Version: 2,
+		Error:       "Payment required",
+		Resource: &types.ResourceInfo{
+			URL:         "https://api.example.com/premium",
+			Description: "Premium content",
+			MimeType:    "application/json",
+		},
+		Accepts: []types.PaymentRequirements{
+			{
+				Scheme:            "exact",
+				Network:           network,
+				Asset:             asset,
+				Amount:            "1500000",
+				PayTo:             payTo,
+				MaxTimeoutSeconds: 60,
+				Extra: map[string]interface{}{
+					"version": "2",
+					"name":    "USD Coin",
+				},
+			},
+		},
+		Extensions: map[string]interface{}{
+			"zeta":  true,
+			"alpha": map[string]interface{}{"b": 2, "a": 1},
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "paywall", name+".golden")
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("paywall output does not match %s (run with -update to accept)\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+func TestRenderPaywallHTMLGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		required x402.PaymentRequired
+		config   *PaywallConfig
+		opts     PaywallRenderOptions
+	}{
+		{
+			name:     "evm_default",
+			required: goldenPaymentRequired("eip155:84532", "0x036CbD53842c5426634e7929541eC2318f3dCF7e", "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"),
+			opts:     PaywallRenderOptions{Template: goldenTemplate},
+		},
+		{
+			name:     "evm_config",
+			required: goldenPaymentRequired("eip155:8453", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"),
+			config: &PaywallConfig{
+				AppName:    "Weather <API>",
+				AppLogo:    "https://example.com/logo.png",
+				CurrentURL: "https://example.com/weather",
+				Testnet:    true,
+
+				WalletConnectProjectID: "wc-project-123",
+				DisableWalletDiscovery: true,
+			},
+			opts: PaywallRenderOptions{Template: goldenTemplate},
+		},
+		{
+			name:     "svm_nonce",
+			required: goldenPaymentRequired("solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", "CKPKJWNdJEqa81x7CkZ14BVPiY6y16Sxs7owznqtWYp5"),
+			opts:     PaywallRenderOptions{Template: goldenTemplate, Nonce: "r4nd0m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderPaywallHTML(tt.required, tt.config, tt.opts)
+
+			// Rendering must be stable across calls (map iteration order must not leak)
+			for i := 0; i < 10; i++ {
+				if again := RenderPaywallHTML(tt.required, tt.config, tt.opts); again != got {
+					t.Fatal("paywall rendering is not deterministic")
+				}
+			}
+
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderPaywallHTMLBuiltInTemplates(t *testing.T) {
+	evmRequired := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	svmRequired := goldenPaymentRequired("solana:devnet", "mint", "payto")
+
+	evm := RenderPaywallHTML(evmRequired, nil, PaywallRenderOptions{})
+	script := renderPaywallConfigScript(evmRequired, nil, "")
+	if evm != strings.Replace(EVMPaywallTemplate, "</body>", script+"</body>", 1) {
+		t.Error("expected EVM template with injected config script")
+	}
+
+	svm := RenderPaywallHTML(svmRequired, nil, PaywallRenderOptions{Nonce: "abc"})
+	if !strings.Contains(svm, `<script nonce="abc">`) {
+		t.Error("expected nonce on injected script tag")
+	}
+	svm = RenderPaywallHTML(svmRequired, nil, PaywallRenderOptions{})
+	if !strings.HasPrefix(svm, SVMPaywallTemplate[:strings.Index(SVMPaywallTemplate, "</body>")]) {
+		t.Error("expected SVM template for solana network")
+	}
+}
+
+func TestGeneratePaywallHTMLMatchesPublicRenderer(t *testing.T) {
+	server := Newx402HTTPResourceServer(nil)
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	config := &PaywallConfig{AppName: "Test"}
+
+	if server.generatePaywallHTMLV2(required, config, "") != RenderPaywallHTML(required, config, PaywallRenderOptions{}) {
+		t.Error("server paywall output should match RenderPaywallHTML")
+	}
+	if server.generatePaywallHTMLV2(required, config, "<custom/>") != "<custom/>" {
+		t.Error("custom paywall HTML should be returned unchanged")
+	}
+}
+
+func TestRenderPaywallCSP(t *testing.T) {
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	template := `<html><head><style>body{color:red}</style><script type="module">console.log("</div>")</script></head><body><script src="/app.js"></script></body></html>`
+
+	paywall := RenderPaywall(required, nil, PaywallRenderOptions{Template: template, Nonce: "n0nce"})
+
+	if strings.Count(paywall.HTML, `nonce="n0nce"`) != 4 {
+		t.Fatalf("expected nonce on all script and style tags, got:\n%s", paywall.HTML)
+	}
+	if !strings.Contains(paywall.HTML, `<script nonce="n0nce" type="module">`) {
+		t.Error("expected nonce inserted before existing attributes")
+	}
+
+	// Inline module script, empty src script body is skipped, config script
+	if len(paywall.ScriptHashes) != 2 {
+		t.Fatalf("expected 2 script hashes, got %v", paywall.ScriptHashes)
+	}
+	if paywall.ScriptHashes[0] != cspHash(`console.log("</div>")`) {
+		t.Errorf("unexpected inline script hash %s", paywall.ScriptHashes[0])
+	}
+	if len(paywall.StyleHashes) != 1 || paywall.StyleHashes[0] != cspHash("body{color:red}") {
+		t.Errorf("unexpected style hashes %v", paywall.StyleHashes)
+	}
+
+	csp := paywall.ContentSecurityPolicy
+	for _, want := range []string{"script-src 'self' 'nonce-n0nce' 'sha256-", "style-src 'self' 'nonce-n0nce'", "object-src 'none'"} {
+		if !strings.Contains(csp, want) {
+			t.Errorf("expected CSP to contain %q, got %s", want, csp)
+		}
+	}
+}
+
+func TestRenderPaywallCSPBuiltInTemplate(t *testing.T) {
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	paywall := RenderPaywall(required, nil, PaywallRenderOptions{Nonce: "abc"})
+
+	// The bundled JS contains "<script>" inside string literals, so count
+	// against the tokenizer's view of real elements rather than substrings
+	info := analyzePaywallTemplate(EVMPaywallTemplate)
+	if len(info.tagOffsets) == 0 {
+		t.Fatal("expected script/style elements in the built-in template")
+	}
+	if got := strings.Count(paywall.HTML, `nonce="abc"`); got != len(info.tagOffsets)+1 {
+		t.Errorf("expected %d nonces, got %d", len(info.tagOffsets)+1, got)
+	}
+	if len(paywall.ScriptHashes) < 2 {
+		t.Errorf("expected hashes for bundled and config scripts, got %d", len(paywall.ScriptHashes))
+	}
+}
+
+func TestCreateHTTPResponseWithCSP(t *testing.T) {
+	server := Newx402HTTPResourceServer(nil)
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+
+	response, err := server.createHTTPResponseV2(required, true, &PaywallConfig{EnableCSP: true}, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	csp := response.Headers[ContentSecurityPolicyHeader]
+	start := strings.Index(csp, "'nonce-") + len("'nonce-")
+	nonce := csp[start : start+strings.Index(csp[start:], "'")]
+	if !strings.Contains(response.Body.(string), `<script nonce="`+nonce+`">`) {
+		t.Error("expected paywall HTML to use the nonce from the CSP header")
+	}
+
+	response, err = server.createHTTPResponseV2(required, true, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := response.Headers[ContentSecurityPolicyHeader]; ok {
+		t.Error("CSP header should only be set when enabled")
+	}
+}
+
+func TestSelectPaywallTemplateByFamily(t *testing.T) {
+	tests := []struct {
+		network string
+		want    string
+	}{
+		{"eip155:8453", EVMPaywallTemplate},
+		{"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", SVMPaywallTemplate},
+		{"solana-devnet", SVMPaywallTemplate},
+		{"", EVMPaywallTemplate},
+	}
+
+	for _, tt := range tests {
+		required := x402.PaymentRequired{Accepts: []x402.PaymentRequirements{{Network: tt.network}}}
+		if got := selectPaywallTemplate(required); got != tt.want {
+			t.Errorf("Unexpected paywall template for %q", tt.network)
+		}
+	}
+}
+
+func TestGetDisplayAmountUsesDefaultAssetDecimals(t *testing.T) {
+	required := x402.PaymentRequired{Accepts: []x402.PaymentRequirements{{
+		Network: "eip155:8453",
+		Asset:   "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913",
+		Amount:  "2500000",
+	}}}
+	if got := getDisplayAmount(required); got != 2.5 {
+		t.Errorf("Expected 2.5, got %f", got)
+	}
+}
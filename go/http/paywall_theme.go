@@ -0,0 +1,211 @@
+package http
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Paywall Theming
+// ============================================================================
+
+// PaywallThemeMode selects the color scheme of the built-in paywall
+type PaywallThemeMode string
+
+const (
+	// PaywallThemeLight always uses the light palette (default)
+	PaywallThemeLight PaywallThemeMode = "light"
+
+	// PaywallThemeDark always uses the dark palette
+	PaywallThemeDark PaywallThemeMode = "dark"
+
+	// PaywallThemeAuto follows the browser's prefers-color-scheme setting
+	PaywallThemeAuto PaywallThemeMode = "auto"
+)
+
+// PaywallColors overrides the CSS color variables used by the built-in templates.
+// Empty fields keep the template (or dark palette) default. Values are CSS colors
+// such as "#2563eb" or "rgb(37 99 235)".
+type PaywallColors struct {
+	Background             string `json:"background,omitempty"`
+	ContainerBackground    string `json:"containerBackground,omitempty"`
+	Text                   string `json:"text,omitempty"`
+	SecondaryText          string `json:"secondaryText,omitempty"`
+	DetailsBackground      string `json:"detailsBackground,omitempty"`
+	DetailsBackgroundHover string `json:"detailsBackgroundHover,omitempty"`
+	ButtonPrimary          string `json:"buttonPrimary,omitempty"`
+	ButtonPrimaryHover     string `json:"buttonPrimaryHover,omitempty"`
+	ButtonSecondary        string `json:"buttonSecondary,omitempty"`
+	ButtonSecondaryHover   string `json:"buttonSecondaryHover,omitempty"`
+	ButtonPositive         string `json:"buttonPositive,omitempty"`
+	ButtonPositiveHover    string `json:"buttonPositiveHover,omitempty"`
+	ButtonError            string `json:"buttonError,omitempty"`
+	ButtonErrorHover       string `json:"buttonErrorHover,omitempty"`
+}
+
+// PaywallTheme customizes the look of the built-in paywall without replacing its HTML
+type PaywallTheme struct {
+	// Mode selects light, dark, or automatic color scheme (default: light)
+	Mode PaywallThemeMode `json:"mode,omitempty"`
+
+	// Light overrides colors of the light palette
+	Light *PaywallColors `json:"light,omitempty"`
+
+	// Dark overrides colors of the dark palette (merged over DefaultDarkPaywallColors)
+	Dark *PaywallColors `json:"dark,omitempty"`
+
+	// FontFamily replaces the paywall font stack (e.g. "'IBM Plex Sans', sans-serif")
+	FontFamily string `json:"fontFamily,omitempty"`
+}
+
+// DefaultDarkPaywallColors is the dark palette used by PaywallThemeDark and PaywallThemeAuto
+var DefaultDarkPaywallColors = PaywallColors{
+	Background:             "#0b0f19",
+	ContainerBackground:    "#111827",
+	Text:                   "#f9fafb",
+	SecondaryText:          "#9ca3af",
+	DetailsBackground:      "#1f2937",
+	DetailsBackgroundHover: "#374151",
+	ButtonPrimary:          "#3b82f6",
+	ButtonPrimaryHover:     "#2563eb",
+	ButtonSecondary:        "#1f2937",
+	ButtonSecondaryHover:   "#374151",
+	ButtonPositive:         "#10b981",
+	ButtonPositiveHover:    "#059669",
+	ButtonError:            "#f87171",
+	ButtonErrorHover:       "#ef4444",
+}
+
+// cssVariables returns the template CSS custom properties for the non-empty colors
+func (c *PaywallColors) cssVariables() string {
+	if c == nil {
+		return ""
+	}
+
+	pairs := []struct{ name, value string }{
+		{"--background-color", c.Background},
+		{"--container-background-color", c.ContainerBackground},
+		{"--text-color", c.Text},
+		{"--secondary-text-color", c.SecondaryText},
+		{"--details-background-color", c.DetailsBackground},
+		{"--details-background-color-hover", c.DetailsBackgroundHover},
+		{"--button-primary-color", c.ButtonPrimary},
+		{"--button-primary-hover-color", c.ButtonPrimaryHover},
+		{"--button-secondary-color", c.ButtonSecondary},
+		{"--button-secondary-hover-color", c.ButtonSecondaryHover},
+		{"--button-positive-color", c.ButtonPositive},
+		{"--button-positive-hover-color", c.ButtonPositiveHover},
+		{"--button-error-color", c.ButtonError},
+		{"--button-error-hover-color", c.ButtonErrorHover},
+	}
+
+	var b strings.Builder
+	for _, p := range pairs {
+		if value := sanitizeCSSValue(p.value); value != "" {
+			fmt.Fprintf(&b, "%s:%s;", p.name, value)
+		}
+	}
+	return b.String()
+}
+
+// mergeColors returns base with every non-empty field of override applied
+func mergeColors(base PaywallColors, override *PaywallColors) *PaywallColors {
+	if override == nil {
+		return &base
+	}
+	merged := base
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	set(&merged.Background, override.Background)
+	set(&merged.ContainerBackground, override.ContainerBackground)
+	set(&merged.Text, override.Text)
+	set(&merged.SecondaryText, override.SecondaryText)
+	set(&merged.DetailsBackground, override.DetailsBackground)
+	set(&merged.DetailsBackgroundHover, override.DetailsBackgroundHover)
+	set(&merged.ButtonPrimary, override.ButtonPrimary)
+	set(&merged.ButtonPrimaryHover, override.ButtonPrimaryHover)
+	set(&merged.ButtonSecondary, override.ButtonSecondary)
+	set(&merged.ButtonSecondaryHover, override.ButtonSecondaryHover)
+	set(&merged.ButtonPositive, override.ButtonPositive)
+	set(&merged.ButtonPositiveHover, override.ButtonPositiveHover)
+	set(&merged.ButtonError, override.ButtonError)
+	set(&merged.ButtonErrorHover, override.ButtonErrorHover)
+	return &merged
+}
+
+// renderThemeCSS renders the stylesheet body for a theme (empty if it changes nothing)
+func renderThemeCSS(theme *PaywallTheme) string {
+	if theme == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	light := theme.Light.cssVariables()
+	dark := mergeColors(DefaultDarkPaywallColors, theme.Dark).cssVariables()
+
+	switch theme.Mode {
+	case PaywallThemeDark:
+		fmt.Fprintf(&b, ":root{color-scheme:dark;%s}", dark)
+	case PaywallThemeAuto:
+		fmt.Fprintf(&b, ":root{color-scheme:light dark;%s}", light)
+		fmt.Fprintf(&b, "@media (prefers-color-scheme: dark){:root{%s}}", dark)
+	default:
+		if light != "" {
+			fmt.Fprintf(&b, ":root{%s}", light)
+		}
+	}
+
+	if font := sanitizeCSSValue(theme.FontFamily); font != "" {
+		fmt.Fprintf(&b, "body{font-family:%s}", font)
+	}
+	return b.String()
+}
+
+// renderThemeStyle wraps the theme CSS in a <style> element
+func renderThemeStyle(css string, nonce string) string {
+	if css == "" {
+		return ""
+	}
+	if nonce != "" {
+		return fmt.Sprintf(`<style nonce="%s">%s</style>`, html.EscapeString(nonce), css)
+	}
+	return "<style>" + css + "</style>"
+}
+
+// sanitizeCSSValue drops values that could escape the declaration or the <style> element
+func sanitizeCSSValue(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "{};<>\\\n\r") {
+		return ""
+	}
+	return value
+}
+
+// ============================================================================
+// Template Variables
+// ============================================================================
+
+// applyTemplateVariables replaces {{x402:name}} placeholders in a template with
+// HTML-escaped values. Keys are applied in sorted order so output is deterministic.
+func applyTemplateVariables(template string, variables map[string]string) string {
+	if len(variables) == 0 {
+		return template
+	}
+
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	replacements := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		replacements = append(replacements, "{{x402:"+key+"}}", html.EscapeString(variables[key]))
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderThemeCSS(t *testing.T) {
+	tests := []struct {
+		name    string
+		theme   *PaywallTheme
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "nil theme",
+			theme: nil,
+		},
+		{
+			name: "light overrides",
+			theme: &PaywallTheme{
+				Light: &PaywallColors{ButtonPrimary: "#ff0000"},
+			},
+			want:    []string{":root{--button-primary-color:#ff0000;}"},
+			notWant: []string{"prefers-color-scheme", "color-scheme:dark"},
+		},
+		{
+			name: "dark merges defaults",
+			theme: &PaywallTheme{
+				Mode: PaywallThemeDark,
+				Dark: &PaywallColors{Background: "black"},
+			},
+			want: []string{"color-scheme:dark", "--background-color:black;", "--text-color:" + DefaultDarkPaywallColors.Text},
+		},
+		{
+			name:  "auto uses media query",
+			theme: &PaywallTheme{Mode: PaywallThemeAuto},
+			want:  []string{"@media (prefers-color-scheme: dark){:root{", "--text-color:" + DefaultDarkPaywallColors.Text},
+		},
+		{
+			name:  "font family",
+			theme: &PaywallTheme{FontFamily: "'IBM Plex Sans', sans-serif"},
+			want:  []string{"body{font-family:'IBM Plex Sans', sans-serif}"},
+		},
+		{
+			name: "unsafe values are dropped",
+			theme: &PaywallTheme{
+				Light:      &PaywallColors{Text: "red}</style><script>alert(1)</script>"},
+				FontFamily: "x;background:url(evil)",
+			},
+			notWant: []string{"alert", "evil"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			css := renderThemeCSS(tt.theme)
+			if len(tt.want) == 0 && len(tt.notWant) == 0 && css != "" {
+				t.Fatalf("expected no CSS, got %q", css)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(css, want) {
+					t.Errorf("expected CSS to contain %q, got %q", want, css)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(css, notWant) {
+					t.Errorf("expected CSS not to contain %q, got %q", notWant, css)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPaywallTheme(t *testing.T) {
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	config := &PaywallConfig{Theme: &PaywallTheme{Mode: PaywallThemeDark}}
+
+	paywall := RenderPaywall(required, config, PaywallRenderOptions{Template: goldenTemplate, Nonce: "n"})
+
+	styleStart := strings.Index(paywall.HTML, `<style nonce="n">:root{color-scheme:dark;`)
+	if styleStart == -1 {
+		t.Fatalf("expected theme style in head, got:\n%s", paywall.HTML)
+	}
+	if styleStart > strings.Index(paywall.HTML, "</head>") {
+		t.Error("theme style should be injected into <head>")
+	}
+	if len(paywall.StyleHashes) != 1 || paywall.StyleHashes[0] != cspHash(renderThemeCSS(config.Theme)) {
+		t.Errorf("expected theme style hash, got %v", paywall.StyleHashes)
+	}
+
+	// Built-in template styles come first so the theme overrides them
+	builtIn := RenderPaywallHTML(required, config, PaywallRenderOptions{})
+	if strings.Index(builtIn, "--button-primary-color: #2563eb") > strings.Index(builtIn, "color-scheme:dark") {
+		t.Error("theme style should follow the template styles")
+	}
+}
+
+func TestRenderPaywallTemplateVariables(t *testing.T) {
+	required := goldenPaymentRequired("eip155:84532", "0xasset", "0xpayto")
+	template := `<html><head><title>{{x402:title}}</title></head><body><h1>{{x402:title}}</h1><p>{{x402:missing}}</p><script>var a = 1</script></body></html>`
+	config := &PaywallConfig{TemplateVariables: map[string]string{"title": "Weather <Pro>"}}
+
+	paywall := RenderPaywall(required, config, PaywallRenderOptions{Template: template, Nonce: "n"})
+
+	if strings.Count(paywall.HTML, "Weather &lt;Pro&gt;") != 2 {
+		t.Errorf("expected escaped variable in title and heading, got:\n%s", paywall.HTML)
+	}
+	if !strings.Contains(paywall.HTML, "{{x402:missing}}") {
+		t.Error("unknown placeholders should be left untouched")
+	}
+	if !strings.Contains(paywall.HTML, `<script nonce="n">var a = 1</script>`) {
+		t.Error("expected nonce on template script after substitution")
+	}
+}
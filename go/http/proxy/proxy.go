@@ -0,0 +1,184 @@
+// Package proxy puts x402 payments in front of an existing upstream API.
+//
+// A Proxy is an http.Handler wrapping httputil.ReverseProxy with x402 route
+// configs: requests to paid routes are verified, forwarded to the upstream,
+// and settled once it answers successfully, so the upstream needs no changes:
+//
+//	p, err := proxy.New(ctx, proxy.Config{
+//	    Upstream:    "http://localhost:9000",
+//	    Routes:      x402http.RoutesConfig{"GET /api/**": {Accepts: accepts}},
+//	    Facilitator: facilitator,
+//	    Schemes:     []proxy.SchemeConfig{{Network: "eip155:*", Server: evm.NewExactEvmScheme()}},
+//	})
+//	http.ListenAndServe(":8080", p)
+//
+// Payment is settled with the same rules as the net/http middleware: an
+// upstream error (status >= 400, or 502 when it cannot be reached) releases
+// the payment instead of settling it.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	chimw "github.com/coinbase/x402/go/http/chi"
+)
+
+// Config configures a paid reverse proxy
+type Config struct {
+	// Upstream is the base URL requests are forwarded to (e.g. "http://localhost:9000")
+	Upstream string
+
+	// Routes maps HTTP patterns to payment requirements
+	Routes x402http.RoutesConfig
+
+	// Facilitator is a single facilitator client (most common case)
+	// Use this OR Facilitators (not both)
+	Facilitator x402.FacilitatorClient
+
+	// Facilitators is an array of facilitator clients (for fallback/redundancy)
+	// Use this OR Facilitator (not both)
+	Facilitators []x402.FacilitatorClient
+
+	// Schemes to register with the server
+	Schemes []SchemeConfig
+
+	// PaywallConfig for browser-based payment UI (optional)
+	PaywallConfig *x402http.PaywallConfig
+
+	// Timeout for payment operations
+	// Default: 30 seconds
+	Timeout time.Duration
+
+	// PaidRoutesOnly answers 404 to requests matching no paid route instead
+	// of forwarding them for free
+	PaidRoutesOnly bool
+
+	// IdentityHeaders lists the X-402-* payer identity headers forwarded to
+	// the upstream (optional)
+	IdentityHeaders []string
+
+	// Transport reaches the upstream (optional, defaults to http.DefaultTransport)
+	Transport http.RoundTripper
+
+	// ServerSetup configures the HTTP server before initialization (optional)
+	ServerSetup func(*x402http.HTTPServer)
+}
+
+// SchemeConfig configures a payment scheme for a network.
+type SchemeConfig struct {
+	Network x402.Network
+	Server  x402.SchemeNetworkServer
+}
+
+// Proxy is a payment-gating reverse proxy
+type Proxy struct {
+	server  *x402http.HTTPServer
+	reverse *httputil.ReverseProxy
+	handler http.Handler
+}
+
+// New builds and initializes the resource server for config's routes and
+// returns a proxy forwarding to config.Upstream
+func New(ctx context.Context, config Config) (*Proxy, error) {
+	upstream, err := url.Parse(config.Upstream)
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid upstream %q: must be an absolute URL", config.Upstream)
+	}
+
+	var opts []x402.ResourceServerOption
+	if config.Facilitator != nil {
+		opts = append(opts, x402.WithFacilitatorClient(config.Facilitator))
+	}
+	for _, facilitator := range config.Facilitators {
+		opts = append(opts, x402.WithFacilitatorClient(facilitator))
+	}
+	for _, scheme := range config.Schemes {
+		opts = append(opts, x402.WithSchemeServer(scheme.Network, scheme.Server))
+	}
+
+	server := x402http.Newx402HTTPResourceServer(config.Routes, opts...)
+	if config.ServerSetup != nil {
+		config.ServerSetup(server)
+	}
+	if err := server.Initialize(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize x402 server: %w", err)
+	}
+
+	return Wrap(upstream, server, config), nil
+}
+
+// Wrap returns a proxy forwarding to upstream for a server that is already
+// configured and initialized. config's Upstream, Routes, Facilitator(s),
+// Schemes and ServerSetup are ignored.
+func Wrap(upstream *url.URL, server *x402http.HTTPServer, config Config) *Proxy {
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	reverse := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+			stripPaymentHeaders(r.Out.Header, server.HeaderNames())
+		},
+		Transport: config.Transport,
+	}
+
+	opts := []chimw.MiddlewareOption{chimw.WithTimeout(config.Timeout)}
+	if config.IdentityHeaders != nil {
+		opts = append(opts, chimw.WithIdentityHeaders(config.IdentityHeaders...))
+	}
+	handler := chimw.Middleware(server, config.PaywallConfig, opts...)(reverse)
+	if config.PaidRoutesOnly {
+		handler = paidRoutesOnly(server, handler)
+	}
+
+	return &Proxy{server: server, reverse: reverse, handler: handler}
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+// ReverseProxy returns the underlying reverse proxy, e.g. to set
+// ModifyResponse or ErrorHandler. Rewrite should be left in place.
+func (p *Proxy) ReverseProxy() *httputil.ReverseProxy {
+	return p.reverse
+}
+
+// Server returns the resource server pricing the proxy's routes
+func (p *Proxy) Server() *x402http.HTTPServer {
+	return p.server
+}
+
+// paidRoutesOnly answers 404 to requests matching no paid route
+func paidRoutesOnly(server *x402http.HTTPServer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCtx := x402http.HTTPRequestContext{
+			Adapter: chimw.NewChiAdapter(r),
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}
+		if !server.RequiresPayment(reqCtx) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stripPaymentHeaders keeps payment payloads from reaching the upstream
+func stripPaymentHeaders(header http.Header, names x402http.HeaderNames) {
+	header.Del(names.PaymentSignature)
+	header.Del(x402http.DefaultHeaderNames.PaymentSignature)
+	header.Del(x402http.LegacyHeaderNames.PaymentSignature)
+}
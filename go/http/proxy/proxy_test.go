@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// newTestProxy proxies to upstream, charging $1 for GET /api/**
+func newTestProxy(t *testing.T, upstream string, paidRoutesOnly bool) *httptest.Server {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+
+	p, err := New(context.Background(), Config{
+		Upstream: upstream,
+		Routes: x402http.RoutesConfig{
+			"GET /api/**": {Accepts: x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}},
+		},
+		Facilitator:     cash.NewFacilitatorClient(facilitator),
+		Schemes:         []SchemeConfig{{Network: testNetwork, Server: cash.NewSchemeNetworkServer()}},
+		PaidRoutesOnly:  paidRoutesOnly,
+		IdentityHeaders: x402http.DefaultIdentityHeaders,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proxy: %v", err)
+	}
+	ts := httptest.NewServer(p)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestProxyChargesAndForwards(t *testing.T) {
+	var seen *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+		if r.URL.Path == "/api/broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Upstream", "yes")
+		_, _ = io.WriteString(w, r.URL.RequestURI())
+	}))
+	defer upstream.Close()
+	ts := newTestProxy(t, upstream.URL, false)
+
+	if resp, _ := get(t, &http.Client{}, ts.URL+"/api/quotes"); resp.StatusCode != http.StatusPaymentRequired || seen != nil {
+		t.Fatalf("Expected an unpaid request to be challenged without reaching the upstream, got %d", resp.StatusCode)
+	}
+
+	resp, body := get(t, payingClient(), ts.URL+"/api/quotes?day=today")
+	if resp.StatusCode != http.StatusOK || body != "/api/quotes?day=today" {
+		t.Fatalf("Expected the upstream's response, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" || resp.Header.Get("X-Upstream") != "yes" {
+		t.Errorf("Expected settlement and upstream headers, got %v", resp.Header)
+	}
+	upstreamURL, _ := url.Parse(upstream.URL)
+	if seen.Host != upstreamURL.Host || seen.Header.Get("X-Forwarded-Host") == "" {
+		t.Errorf("Expected the request rewritten for the upstream, got host %q", seen.Host)
+	}
+	if seen.Header.Get("PAYMENT-SIGNATURE") != "" {
+		t.Error("Expected the payment payload to be stripped before forwarding")
+	}
+	if payer := seen.Header.Get("X-402-Payer"); payer != "~Alice" {
+		t.Errorf("Expected the payer forwarded to the upstream, got %q", payer)
+	}
+
+	if resp, _ := get(t, payingClient(), ts.URL+"/api/broken"); resp.StatusCode != http.StatusInternalServerError || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected an upstream error not to settle, got %d", resp.StatusCode)
+	}
+
+	if resp, body := get(t, &http.Client{}, ts.URL+"/health"); resp.StatusCode != http.StatusOK || body != "/health" {
+		t.Errorf("Expected unpriced routes to be forwarded for free, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestProxyPaidRoutesOnly(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "upstream")
+	}))
+	defer upstream.Close()
+	ts := newTestProxy(t, upstream.URL, true)
+
+	if resp, _ := get(t, &http.Client{}, ts.URL+"/admin"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected unpriced routes to be hidden, got %d", resp.StatusCode)
+	}
+	if resp, body := get(t, payingClient(), ts.URL+"/api/x"); resp.StatusCode != http.StatusOK || body != "upstream" {
+		t.Errorf("Expected paid routes to be forwarded, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestProxyUnreachableUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+	ts := newTestProxy(t, upstream.URL, false)
+
+	if resp, _ := get(t, payingClient(), ts.URL+"/api/x"); resp.StatusCode != http.StatusBadGateway || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected an unreachable upstream to fail without settling, got %d", resp.StatusCode)
+	}
+
+	if _, err := New(context.Background(), Config{Upstream: "not a url"}); err == nil {
+		t.Error("Expected a relative upstream to be rejected")
+	}
+}
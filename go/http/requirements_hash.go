@@ -0,0 +1,33 @@
+package http
+
+import (
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Requirements Hash Commitment
+// ============================================================================
+
+// RequireRequirementsHash rejects payments whose payload does not carry a
+// requirementsHash. Payloads that carry one are always matched exactly: the
+// accepted requirements must be identical to one of those offered (compared
+// by canonical JSON hash), not merely share scheme, network, asset, amount
+// and payTo.
+func (s *x402HTTPResourceServer) RequireRequirementsHash() *x402HTTPResourceServer {
+	s.requireRequirementsHash = true
+	return s
+}
+
+// findMatchingRequirements matches exactly when the payload commits to its
+// accepted requirements, and by fields for older clients unless a commitment
+// is required
+func (s *x402HTTPResourceServer) findMatchingRequirements(core *x402.X402ResourceServer, offered []types.PaymentRequirements, payload types.PaymentPayload) *types.PaymentRequirements {
+	if payload.RequirementsHash != "" {
+		return core.FindExactRequirements(offered, payload)
+	}
+	if s.requireRequirementsHash {
+		return nil
+	}
+	return core.FindMatchingRequirements(offered, payload)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestRequirementsHashMatching(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds:      []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /data": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}}},
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	request := func(headers map[string]string) HTTPProcessResult {
+		return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+			Adapter: &mockHTTPAdapter{method: "GET", path: "/data", url: "https://api.example.com/data", headers: headers},
+			Path:    "/data",
+			Method:  "GET",
+		}, nil)
+	}
+	unpaid := request(nil)
+	required, err := decodePaymentRequiredHeader(unpaid.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	offered := required.Accepts[0]
+
+	pay := func(accepted types.PaymentRequirements, hash string) string {
+		payloadJSON, _ := json.Marshal(types.PaymentPayload{
+			X402Version:      2,
+			Payload:          map[string]interface{}{"sig": "test"},
+			Accepted:         accepted,
+			RequirementsHash: hash,
+		})
+		return request(map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadJSON)}).Type
+	}
+	offeredHash, _ := types.RequirementsHash(offered)
+
+	// Only the matched fields and the expiry are kept; field matching accepts
+	// it, exact matching does not
+	partial := types.PaymentRequirements{
+		Scheme: offered.Scheme, Network: offered.Network, Asset: offered.Asset, Amount: offered.Amount, PayTo: offered.PayTo,
+		ExpiresAt: offered.ExpiresAt,
+		Extra:     map[string]interface{}{types.ExpiryProofExtraKey: offered.Extra[types.ExpiryProofExtraKey]},
+	}
+	partialHash, _ := types.RequirementsHash(partial)
+
+	tests := []struct {
+		name     string
+		accepted types.PaymentRequirements
+		hash     string
+		want     string
+	}{
+		{"exact with hash", offered, offeredHash, ResultPaymentVerified},
+		{"partial without hash", partial, "", ResultPaymentVerified},
+		{"partial with hash", partial, partialHash, ResultPaymentError},
+		{"hash of other requirements", offered, partialHash, ResultPaymentError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pay(tt.accepted, tt.hash); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	server.RequireRequirementsHash()
+	if got := pay(partial, ""); got != ResultPaymentError {
+		t.Errorf("Expected payments without a hash to be rejected, got %s", got)
+	}
+	if got := pay(offered, offeredHash); got != ResultPaymentVerified {
+		t.Errorf("Expected committed payment to verify, got %s", got)
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"net"
+)
+
+// ============================================================================
+// Client Identification
+// ============================================================================
+
+// APIKeyHeader is the header API clients send their key in
+const APIKeyHeader = "X-API-Key"
+
+// HTTPClientIPAdapter is implemented by adapters that can resolve the client IP:
+// the connection's remote address, or the address a framework resolves with
+// its trusted proxy settings. Request headers are never trusted for it.
+type HTTPClientIPAdapter interface {
+	GetClientIP() string
+}
+
+// RemoteIP returns the host of a "host:port" remote address, such as
+// http.Request.RemoteAddr, for HTTPClientIPAdapter implementations
+func RemoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// ClientKeyFunc returns a stable identifier for the client making a request.
+// An empty key means the client could not be identified.
+type ClientKeyFunc func(ctx context.Context, reqCtx HTTPRequestContext) string
+
+// DefaultClientKey identifies clients by IP address ("ip:<addr>"): the request's
+// ClientInfo IP, resolved through trusted proxies, or else the adapter's
+// HTTPClientIPAdapter address. Headers a client can set, such as an API key
+// or X-Forwarded-For, are not trusted; identify clients by a credential with
+// a ClientKeyFunc that validates it.
+func DefaultClientKey(ctx context.Context, reqCtx HTTPRequestContext) string {
+	ip := clientIP(reqCtx.Adapter)
+	if info, ok := ClientInfoFromContext(ctx); ok {
+		ip = info.IP
+	}
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
+
+// ============================================================================
+// Percentage Rollout
+// ============================================================================
+
+// RolloutConfig enforces payment for a stable percentage of clients on a route.
+// Each client is hashed into a bucket, so the same client always gets the same
+// behavior for a given Percent and Salt.
+type RolloutConfig struct {
+	// Percent of clients required to pay, from 0 to 100
+	Percent float64 `json:"percent"`
+
+	// Salt varies the bucketing between experiments (optional)
+	Salt string `json:"salt,omitempty"`
+
+	// ClientKey identifies the client (default: DefaultClientKey).
+	// Unidentified clients are always required to pay.
+	ClientKey ClientKeyFunc `json:"-"`
+}
+
+// rolloutBuckets is the bucket resolution (0.01%)
+const rolloutBuckets = 10000
+
+// enforces reports whether payment is enforced for the request's client
+func (r *RolloutConfig) enforces(ctx context.Context, reqCtx HTTPRequestContext) bool {
+	if r.Percent >= 100 {
+		return true
+	}
+	if r.Percent <= 0 {
+		return false
+	}
+
+	clientKey := r.ClientKey
+	if clientKey == nil {
+		clientKey = DefaultClientKey
+	}
+	key := clientKey(ctx, reqCtx)
+	if key == "" {
+		return true
+	}
+	return rolloutBucket(r.Salt, key) < uint64(r.Percent*rolloutBuckets/100)
+}
+
+// rolloutBucket hashes a client key into [0, rolloutBuckets)
+func rolloutBucket(salt string, key string) uint64 {
+	sum := sha256.Sum256([]byte(salt + ":" + key))
+	return binary.BigEndian.Uint64(sum[:8]) % rolloutBuckets
+}
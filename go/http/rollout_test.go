@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+type mockIPAdapter struct {
+	mockHTTPAdapter
+	ip string
+}
+
+func (m *mockIPAdapter) GetClientIP() string {
+	return m.ip
+}
+
+// apiKeyClient identifies clients by their X-API-Key header, standing in for
+// a ClientKeyFunc that validates the key
+func apiKeyClient(ctx context.Context, reqCtx HTTPRequestContext) string {
+	if key := reqCtx.Adapter.GetHeader(APIKeyHeader); key != "" {
+		return "key:" + key
+	}
+	return ""
+}
+
+func TestDefaultClientKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter HTTPAdapter
+		want    string
+	}{
+		{"adapter ip", &mockIPAdapter{ip: "10.0.0.1"}, "ip:10.0.0.1"},
+		{
+			"api key ignored",
+			&mockIPAdapter{mockHTTPAdapter{headers: map[string]string{"X-API-Key": "abc"}}, "10.0.0.1"},
+			"ip:10.0.0.1",
+		},
+		{
+			"forwarded for ignored",
+			&mockHTTPAdapter{headers: map[string]string{"X-Forwarded-For": "203.0.113.5, 10.0.0.1"}},
+			"",
+		},
+		{"real ip ignored", &mockHTTPAdapter{headers: map[string]string{"X-Real-IP": "203.0.113.6"}}, ""},
+		{"unidentified", &mockHTTPAdapter{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultClientKey(context.Background(), HTTPRequestContext{Adapter: tt.adapter}); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRolloutEnforcesStablePercentage(t *testing.T) {
+	rollout := &RolloutConfig{Percent: 30, Salt: "exp-1"}
+
+	enforced := 0
+	for i := 0; i < 2000; i++ {
+		reqCtx := HTTPRequestContext{Adapter: &mockIPAdapter{ip: fmt.Sprintf("10.0.%d.%d", i/256, i%256)}}
+		first := rollout.enforces(context.Background(), reqCtx)
+		if first != rollout.enforces(context.Background(), reqCtx) {
+			t.Fatalf("Expected stable decision for client %d", i)
+		}
+		if first {
+			enforced++
+		}
+	}
+	if enforced < 500 || enforced > 700 {
+		t.Errorf("Expected roughly 30%% of 2000 clients enforced, got %d", enforced)
+	}
+
+	unidentified := HTTPRequestContext{Adapter: &mockHTTPAdapter{}}
+	if !rollout.enforces(context.Background(), unidentified) {
+		t.Error("Expected unidentified clients to pay")
+	}
+}
+
+func TestRolloutGatesPaymentPerRoute(t *testing.T) {
+	routes := RoutesConfig{
+		"GET /free": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Rollout: &RolloutConfig{Percent: 0},
+		},
+		"GET /paid": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Rollout: &RolloutConfig{Percent: 100},
+		},
+		"GET /custom": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Rollout: &RolloutConfig{
+				Percent: 50,
+				ClientKey: func(ctx context.Context, reqCtx HTTPRequestContext) string {
+					return reqCtx.Adapter.GetHeader("X-Tenant")
+				},
+			},
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	process := func(path string, headers map[string]string) string {
+		adapter := &mockHTTPAdapter{method: "GET", path: path, url: "http://example.com" + path, headers: headers}
+		return server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: path, Method: "GET"}, nil).Type
+	}
+
+	if got := process("/free", nil); got != ResultNoPaymentRequired {
+		t.Errorf("Expected 0%% rollout to let requests through, got %s", got)
+	}
+	if got := process("/paid", nil); got != ResultPaymentError {
+		t.Errorf("Expected 100%% rollout to require payment, got %s", got)
+	}
+
+	// Find one tenant on each side of the split and check it is honored
+	seen := map[bool]string{}
+	for i := 0; len(seen) < 2; i++ {
+		tenant := fmt.Sprintf("tenant-%d", i)
+		seen[rolloutBucket("", tenant) < rolloutBuckets/2] = tenant
+	}
+	if got := process("/custom", map[string]string{"X-Tenant": seen[true]}); got != ResultPaymentError {
+		t.Errorf("Expected %s to pay, got %s", seen[true], got)
+	}
+	if got := process("/custom", map[string]string{"X-Tenant": seen[false]}); got != ResultNoPaymentRequired {
+		t.Errorf("Expected %s to pass through, got %s", seen[false], got)
+	}
+}
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ============================================================================
+// Route Patterns
+// ============================================================================
+
+// neverMatch is used for routes whose pattern does not compile
+var neverMatch = regexp.MustCompile(`$.^`)
+
+// routeRank describes how specific a route pattern is (see RoutesConfig)
+type routeRank struct {
+	wildcards int
+	params    int
+	literals  int
+	verbs     int // 0 means any method
+}
+
+// parseRoutePattern parses a route pattern like "GET /api/*" into its method
+// and path regex. Invalid patterns match nothing.
+func parseRoutePattern(pattern string) (string, *regexp.Regexp) {
+	verb, regex, _, _ := compileRoutePattern(pattern)
+	return verb, regex
+}
+
+// compileRoutePattern parses a hostless route pattern into its method (or
+// "|"-separated method list, "*" for any), path regex, and rank
+func compileRoutePattern(pattern string) (string, *regexp.Regexp, routeRank, error) {
+	parts := strings.Fields(pattern)
+
+	var verb, path string
+	if len(parts) == 2 {
+		verb = normalizeVerbs(parts[0])
+		path = parts[1]
+	} else {
+		verb = "*"
+		path = pattern
+	}
+
+	regexPattern, rank, err := compileRoutePath(path)
+	if verb != "*" {
+		rank.verbs = len(strings.Split(verb, "|"))
+	}
+	if err != nil {
+		return verb, neverMatch, rank, fmt.Errorf("invalid route pattern %q: %w", pattern, err)
+	}
+
+	regex, err := regexp.Compile(regexPattern)
+	if err != nil {
+		return verb, neverMatch, rank, fmt.Errorf("invalid route pattern %q: %w", pattern, err)
+	}
+	return verb, regex, rank, nil
+}
+
+// compileRoutePath converts a path pattern to an anchored regex
+func compileRoutePath(path string) (string, routeRank, error) {
+	var rank routeRank
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\':
+			if i+1 == len(path) {
+				return "", rank, fmt.Errorf("trailing escape")
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			rank.literals++
+
+		case strings.HasPrefix(path[i:], "**"):
+			rank.wildcards++
+			writeOptionalSegment(&b, ".*")
+			i++
+
+		case path[i] == '*':
+			rank.wildcards++
+			b.WriteString(".*?")
+
+		case strings.HasPrefix(path[i:], "[["):
+			end := strings.Index(path[i:], "]]")
+			if end < 0 {
+				return "", rank, fmt.Errorf("unterminated optional segment")
+			}
+			rank.params++
+			writeOptionalSegment(&b, "[^/]+")
+			i += end + 1
+
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return "", rank, fmt.Errorf("unterminated parameter")
+			}
+			rank.params++
+			b.WriteString("[^/]+")
+			i += end
+
+		case path[i] == '{':
+			end := matchingBrace(path[i:])
+			if end < 0 {
+				return "", rank, fmt.Errorf("unterminated regular expression")
+			}
+			rank.params++
+			b.WriteString("(?:" + path[i+1:i+end] + ")")
+			i += end
+
+		default:
+			b.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			rank.literals++
+		}
+	}
+
+	b.WriteString("$")
+	return b.String(), rank, nil
+}
+
+// writeOptionalSegment writes a segment that may be absent. When it follows
+// a "/", the slash becomes part of the optional group so "/api/**" also
+// matches "/api".
+func writeOptionalSegment(b *strings.Builder, segment string) {
+	current := b.String()
+	if strings.HasSuffix(current, "/") {
+		b.Reset()
+		b.WriteString(strings.TrimSuffix(current, "/"))
+		b.WriteString("(?:/" + segment + ")?")
+		return
+	}
+	b.WriteString("(?:" + segment + ")?")
+}
+
+// matchingBrace returns the index of the brace closing the one at s[0], or -1
+func matchingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// normalizeVerbs uppercases a method or "|"-separated method list
+func normalizeVerbs(verb string) string {
+	verbs := strings.Split(strings.ToUpper(verb), "|")
+	for _, v := range verbs {
+		if v == "*" {
+			return "*"
+		}
+	}
+	return strings.Join(verbs, "|")
+}
+
+// matches reports whether the route accepts a request's host, normalized path, and uppercase method
+func (r CompiledRoute) matches(host, normalizedPath, method string) bool {
+	if r.HostRegex != nil && !r.HostRegex.MatchString(host) {
+		return false
+	}
+	return r.Regex.MatchString(normalizedPath) && verbMatches(r.Verb, method)
+}
+
+// verbMatches reports whether a route's method or method list accepts a request method
+func verbMatches(verb string, method string) bool {
+	if verb == "*" {
+		return true
+	}
+	for _, v := range strings.Split(verb, "|") {
+		if v == method {
+			return true
+		}
+	}
+	return false
+}
+
+// verbsOverlap reports whether two routes accept a common method
+func verbsOverlap(a, b string) bool {
+	if a == "*" || b == "*" {
+		return true
+	}
+	for _, v := range strings.Split(a, "|") {
+		if verbMatches(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// routePrecedes reports whether route a is tried before route b (see RoutesConfig)
+func routePrecedes(a, b CompiledRoute) bool {
+	aHost, bHost := a.HostRegex != nil, b.HostRegex != nil
+	if aHost != bHost {
+		return aHost
+	}
+	if a.rank.wildcards != b.rank.wildcards {
+		return a.rank.wildcards < b.rank.wildcards
+	}
+	if a.rank.params != b.rank.params {
+		return a.rank.params < b.rank.params
+	}
+	if a.rank.literals != b.rank.literals {
+		return a.rank.literals > b.rank.literals
+	}
+	if verbRank(a.rank.verbs) != verbRank(b.rank.verbs) {
+		return verbRank(a.rank.verbs) < verbRank(b.rank.verbs)
+	}
+	return a.Pattern < b.Pattern
+}
+
+// verbRank orders a single method before a method list before any method
+func verbRank(verbs int) int {
+	if verbs == 0 {
+		return 1 << 30
+	}
+	return verbs
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestRoutePatternSyntax(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/api/**", "/api", true},
+		{"/api/**", "/api/a/b/c", true},
+		{"/api/**", "/apix", false},
+		{"/api/*", "/api", false},
+		{"/items/[id]", "/items/1/detail", false},
+		{"/v/[[version]]/docs", "/v/docs", true},
+		{"/v/[[version]]/docs", "/v/2/docs", true},
+		{"/v/[[version]]/docs", "/v/2/3/docs", false},
+		{"/items/{[0-9]+}", "/items/42", true},
+		{"/items/{[0-9]+}", "/items/abc", false},
+		{"/items/{a{2}}", "/items/aa", true},
+		{`/files/report\*`, "/files/report*", true},
+		{`/files/report\*`, "/files/report-2024", false},
+		{"/files/report.pdf", "/files/reportxpdf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			_, regex := parseRoutePattern(tt.pattern)
+			if regex.MatchString(normalizePath(tt.path)) != tt.match {
+				t.Errorf("expected match=%v for %s", tt.match, tt.path)
+			}
+		})
+	}
+}
+
+func TestRoutePatternMethodLists(t *testing.T) {
+	verb, _ := parseRoutePattern("get|Post /api/*")
+	if verb != "GET|POST" {
+		t.Fatalf("expected normalized method list, got %s", verb)
+	}
+	if !verbMatches(verb, "GET") || !verbMatches(verb, "POST") || verbMatches(verb, "DELETE") {
+		t.Errorf("unexpected method matching for %s", verb)
+	}
+	if !verbsOverlap("GET|POST", "POST|PUT") || verbsOverlap("GET|POST", "PUT") {
+		t.Error("unexpected method list overlap")
+	}
+}
+
+func TestInvalidRoutePatternMatchesNothing(t *testing.T) {
+	server := Newx402HTTPResourceServer(RoutesConfig{
+		"GET /items/{[0-9+}": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}},
+	})
+	if server.RequiresPayment(HTTPRequestContext{Path: "/items/1", Method: "GET"}) {
+		t.Error("expected invalid pattern to match nothing")
+	}
+	if err := server.ValidateConfig(); err == nil {
+		t.Error("expected ValidateConfig to report the invalid pattern")
+	}
+}
+
+func TestRoutePrecedence(t *testing.T) {
+	route := func(description string) RouteConfig {
+		return RouteConfig{
+			Description: description,
+			Accepts:     PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+		}
+	}
+	server := Newx402HTTPResourceServer(RoutesConfig{
+		"/api/**":            route("catch-all"),
+		"GET /api/*":         route("wildcard"),
+		"GET /api/[id]":      route("param"),
+		"GET|POST /api/me":   route("method list"),
+		"GET /api/me":        route("exact"),
+		"GET /api/[id]/tags": route("param with literal"),
+	}, x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}))
+
+	tests := []struct {
+		method, path, expected string
+	}{
+		{"GET", "/api/me", "exact"},
+		{"POST", "/api/me", "method list"},
+		{"GET", "/api/42", "param"},
+		{"GET", "/api/42/tags", "param with literal"},
+		{"GET", "/api/42/other", "wildcard"},
+		{"DELETE", "/api/42", "catch-all"},
+		{"GET", "/api", "catch-all"},
+	}
+	for _, tt := range tests {
+		config := server.getRouteConfig("", tt.path, tt.method)
+		if config == nil || config.Description != tt.expected {
+			t.Errorf("%s %s: expected %q, got %+v", tt.method, tt.path, tt.expected, config)
+		}
+	}
+
+	if err := server.ValidateConfig(); err != nil {
+		t.Errorf("expected distinct patterns to validate, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/caip"
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Route Validation
+// ============================================================================
+
+// requirementsValidator is implemented by scheme servers that can check
+// requirements for their scheme, e.g. that payTo is a valid address
+type requirementsValidator interface {
+	ValidatePaymentRequirements(requirements types.PaymentRequirements) error
+}
+
+// NewValidatedx402HTTPResourceServer creates an HTTP resource server like
+// Newx402HTTPResourceServer, but rejects invalid route configuration with an
+// aggregated error (see ValidateConfig)
+func NewValidatedx402HTTPResourceServer(routes RoutesConfig, opts ...x402.ResourceServerOption) (*x402HTTPResourceServer, error) {
+	server := Newx402HTTPResourceServer(routes, opts...)
+	if err := server.ValidateConfig(); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// ValidateConfig checks the route configuration without contacting a
+// facilitator and returns every problem found, joined into one error:
+//
+//   - networks that are not valid CAIP-2 identifiers
+//   - schemes with no scheme server registered for the network
+//   - static prices that cannot be parsed or are zero
+//   - static payTo values that are missing or rejected by the scheme server
+//     (e.g. an invalid EVM address); names such as "merchant.eth" are left to
+//     name resolution
+//   - patterns that do not compile, or that match the same requests so which
+//     route applies would be arbitrary
+//   - per-item pagination without a page size
+//   - free tiers without a ClientKey
+//
+// Routes of tenants that are not registered yet are only checked for their
+// patterns and payTo presence; call ValidateConfig again after RegisterTenant.
+func (s *x402HTTPResourceServer) ValidateConfig() error {
+	routes := append([]CompiledRoute(nil), s.compiledRoutes...)
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Pattern < routes[j].Pattern })
+
+	var errs []error
+	for _, route := range routes {
+		if route.err != nil {
+			errs = append(errs, route.err)
+			continue
+		}
+		if route.Config.Pagination != nil {
+			if err := route.Config.Pagination.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("route %q: %w", route.Pattern, err))
+			}
+		}
+		if route.Config.FreeTier != nil && route.Config.FreeTier.ClientKey == nil {
+			errs = append(errs, fmt.Errorf("route %q: free tier requires a ClientKey", route.Pattern))
+		}
+		for i, option := range route.Config.Accepts {
+			if err := s.validateOption(route.Config.Tenant, option); err != nil {
+				errs = append(errs, fmt.Errorf("route %q option %d (%s on %s): %w", route.Pattern, i, option.Scheme, option.Network, err))
+			}
+		}
+	}
+
+	for _, excluded := range s.excludedRoutes {
+		if excluded.err != nil {
+			errs = append(errs, excluded.err)
+		}
+	}
+
+	for i := range routes {
+		for j := i + 1; j < len(routes); j++ {
+			if routesOverlap(routes[i], routes[j]) {
+				errs = append(errs, fmt.Errorf("routes %q and %q match the same requests", routes[i].Pattern, routes[j].Pattern))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateOption checks the static parts of one payment option
+func (s *x402HTTPResourceServer) validateOption(tenant string, option PaymentOption) error {
+	if option.Scheme == "" {
+		return errors.New("scheme is required")
+	}
+	if _, err := caip.ParseChainID(string(option.Network)); err != nil {
+		return fmt.Errorf("unknown network: %w", err)
+	}
+
+	payTo, staticPayTo := option.PayTo.(string)
+	if _, dynamic := option.PayTo.(DynamicPayToFunc); !dynamic && !staticPayTo {
+		return fmt.Errorf("payTo must be string or DynamicPayToFunc, got %T", option.PayTo)
+	}
+	if staticPayTo && payTo == "" {
+		return errors.New("payTo is required")
+	}
+
+	core, ok := s.X402ResourceServer, true
+	if tenant != "" {
+		core, ok = s.tenants[tenant]
+	}
+	if !ok {
+		return nil
+	}
+	schemeServer := core.SchemeServer(option.Network, option.Scheme)
+	if schemeServer == nil {
+		return fmt.Errorf("no scheme server for %s on %s", option.Scheme, option.Network)
+	}
+
+	if _, dynamic := option.Price.(DynamicPriceFunc); dynamic {
+		return nil
+	}
+	assetAmount, err := schemeServer.ParsePrice(option.Price, option.Network)
+	if err != nil {
+		return fmt.Errorf("invalid price %v: %w", option.Price, err)
+	}
+	if amount, ok := new(big.Int).SetString(assetAmount.Amount, 10); ok && amount.Sign() <= 0 {
+		return fmt.Errorf("price %v is zero", option.Price)
+	}
+
+	validator, ok := schemeServer.(requirementsValidator)
+	if !ok || !staticPayTo || IsResolvableName(payTo) {
+		return nil
+	}
+	return validator.ValidatePaymentRequirements(types.PaymentRequirements{
+		Scheme:  option.Scheme,
+		Network: string(option.Network),
+		Asset:   assetAmount.Asset,
+		Amount:  assetAmount.Amount,
+		PayTo:   payTo,
+	})
+}
+
+// routesOverlap reports whether two routes compile to the same host and path
+// pattern with an overlapping method and equal precedence, so only the pattern
+// text decides between them, e.g. "GET /items/[id]" and "GET /items/[slug]"
+func routesOverlap(a, b CompiledRoute) bool {
+	if a.err != nil || b.err != nil || a.Host != b.Host || a.Regex.String() != b.Regex.String() {
+		return false
+	}
+	return verbRank(a.rank.verbs) == verbRank(b.rank.verbs) && verbsOverlap(a.Verb, b.Verb)
+}
+
+// ValidateRoutes builds the payment requirements of every route option with a
+// static payTo and price, reporting invalid configuration (e.g. a payTo that
+// is not a valid address for its network, or a non-checksummed EVM address
+// when the scheme server requires checksums) at startup rather than on the
+// first request. Call it after Initialize.
+func (s *x402HTTPResourceServer) ValidateRoutes(ctx context.Context) error {
+	var errs []error
+	for _, route := range s.compiledRoutes {
+		core, err := s.resourceServerFor(route.Config.Tenant)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %q: %w", route.Pattern, err))
+			continue
+		}
+		routeCtx := x402.ContextWithFacilitator(ctx, route.Config.Facilitator)
+
+		for _, option := range route.Config.Accepts {
+			if _, ok := option.PayTo.(DynamicPayToFunc); ok {
+				continue
+			}
+			if _, ok := option.Price.(DynamicPriceFunc); ok {
+				continue
+			}
+			if _, err := s.buildPaymentRequirements(routeCtx, core, []PaymentOption{option}, HTTPRequestContext{}); err != nil {
+				errs = append(errs, fmt.Errorf("route %q: %w", route.Pattern, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
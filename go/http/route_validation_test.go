@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	scheme := &mockSchemeServer{scheme: "exact"}
+	routes := RoutesConfig{
+		"GET /ok": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+		},
+		"GET /dynamic": {
+			Accepts: PaymentOptions{{
+				Scheme:  "exact",
+				Network: "eip155:1",
+				Price:   "$1.00",
+				PayTo: DynamicPayToFunc(func(ctx context.Context, reqCtx HTTPRequestContext) (string, error) {
+					return "", errors.New("only resolvable per request")
+				}),
+			}},
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", scheme),
+	)
+	_ = server.Initialize(context.Background())
+
+	if err := server.ValidateRoutes(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /bad": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:8453"}}}},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", scheme),
+	)
+	_ = invalid.Initialize(context.Background())
+	if err := invalid.ValidateRoutes(context.Background()); err == nil {
+		t.Error("expected error for a route without a scheme server")
+	}
+}
+
+// validatingSchemeServer parses "$0" as a zero amount and requires 0x payTo addresses
+type validatingSchemeServer struct {
+	mockSchemeServer
+}
+
+func (v *validatingSchemeServer) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	switch price {
+	case "$0":
+		return x402.AssetAmount{Asset: "USDC", Amount: "0"}, nil
+	case "free-ish":
+		return x402.AssetAmount{}, errors.New("unparseable price")
+	}
+	return v.mockSchemeServer.ParsePrice(price, network)
+}
+
+func (v *validatingSchemeServer) ValidatePaymentRequirements(requirements types.PaymentRequirements) error {
+	if !strings.HasPrefix(requirements.PayTo, "0x") {
+		return fmt.Errorf("invalid payTo address: %s", requirements.PayTo)
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+	option := func(payTo interface{}, price x402.Price, network x402.Network) PaymentOptions {
+		return PaymentOptions{{Scheme: "exact", PayTo: payTo, Price: price, Network: network}}
+	}
+	routes := RoutesConfig{
+		"GET /ok":            {Accepts: option("0xtest", "$1.00", "eip155:1")},
+		"GET /named":         {Accepts: option("merchant.eth", "$1.00", "eip155:1")},
+		"GET /bad-payto":     {Accepts: option("nope", "$1.00", "eip155:1")},
+		"GET /missing-payto": {Accepts: option("", "$1.00", "eip155:1")},
+		"GET /zero":          {Accepts: option("0xtest", "$0", "eip155:1")},
+		"GET /unparseable":   {Accepts: option("0xtest", "free-ish", "eip155:1")},
+		"GET /no-scheme":     {Accepts: option("0xtest", "$1.00", "eip155:8453")},
+		"GET /bad-network":   {Accepts: option("0xtest", "$1.00", "base")},
+		"GET /items/[id]":    {Accepts: option("0xtest", "$1.00", "eip155:1")},
+		"GET /items/[slug]":  {Accepts: option("0xtest", "$1.00", "eip155:1")},
+		"GET /pages":         {Accepts: option("0xtest", "$1.00", "eip155:1"), Pagination: &PaginationConfig{PerItem: true}},
+		"GET /free":          {Accepts: option("0xtest", "$1.00", "eip155:1"), FreeTier: &FreeTierConfig{Requests: 5}},
+	}
+
+	_, err := NewValidatedx402HTTPResourceServer(routes, x402.WithSchemeServer("eip155:1", &validatingSchemeServer{mockSchemeServer{scheme: "exact"}}))
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+
+	message := err.Error()
+	for _, expected := range []string{
+		`route "GET /bad-payto"`,
+		`route "GET /missing-payto"`,
+		`route "GET /zero"`,
+		`route "GET /unparseable"`,
+		`route "GET /no-scheme"`,
+		`route "GET /bad-network"`,
+		`route "GET /pages": per-item pagination needs MaxItems`,
+		`route "GET /free": free tier requires a ClientKey`,
+		`routes "GET /items/[id]" and "GET /items/[slug]" match the same requests`,
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected error to mention %s, got:\n%s", expected, message)
+		}
+	}
+	for _, unexpected := range []string{`"GET /ok"`, `"GET /named"`} {
+		if strings.Contains(message, unexpected) {
+			t.Errorf("unexpected error for %s:\n%s", unexpected, message)
+		}
+	}
+}
+
+func TestNewValidatedServerAcceptsValidConfig(t *testing.T) {
+	routes := RoutesConfig{
+		"GET /api": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}},
+		"POST /api": {Accepts: PaymentOptions{{
+			Scheme:  "exact",
+			PayTo:   "0xtest",
+			Network: "eip155:1",
+			Price: DynamicPriceFunc(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+				return "$0", nil
+			}),
+		}}},
+	}
+	server, err := NewValidatedx402HTTPResourceServer(routes, x402.WithSchemeServer("eip155:1", &validatingSchemeServer{mockSchemeServer{scheme: "exact"}}))
+	if err != nil || server == nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
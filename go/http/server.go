@@ -2,16 +2,16 @@ package http
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"html"
+	"net"
 	"net/url"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 
 	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/extensions"
 	"github.com/coinbase/x402/go/types"
 )
 
@@ -40,6 +40,29 @@ type PaywallConfig struct {
 	AppLogo    string `json:"appLogo,omitempty"`
 	CurrentURL string `json:"currentUrl,omitempty"`
 	Testnet    bool   `json:"testnet,omitempty"`
+
+	// WalletConnectProjectID enables the WalletConnect v2 connector in the EVM
+	// paywall. Project IDs are issued by WalletConnect Cloud.
+	WalletConnectProjectID string `json:"walletConnectProjectId,omitempty"`
+
+	// DisableWalletDiscovery turns off EIP-6963 discovery of injected wallets,
+	// leaving only the default window.ethereum provider
+	DisableWalletDiscovery bool `json:"disableWalletDiscovery,omitempty"`
+
+	// Theme customizes colors, fonts, and light/dark mode of the built-in paywall
+	Theme *PaywallTheme `json:"theme,omitempty"`
+
+	// TemplateVariables replace {{x402:name}} placeholders in the paywall template.
+	// Values are HTML-escaped.
+	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+
+	// EnableCSP serves the built-in paywall with a per-response nonce and a
+	// matching Content-Security-Policy header
+	EnableCSP bool `json:"enableCsp,omitempty"`
+
+	// PayToNames maps payTo addresses to display names (e.g. "merchant.eth").
+	// Filled in from the address book when name resolution is enabled.
+	PayToNames map[string]string `json:"payToNames,omitempty"`
 }
 
 // DynamicPayToFunc is a function that resolves payTo address dynamically based on request context
@@ -84,6 +107,10 @@ type PaymentOption struct {
 	Network           x402.Network           `json:"network"`
 	MaxTimeoutSeconds int                    `json:"maxTimeoutSeconds,omitempty"`
 	Extra             map[string]interface{} `json:"extra,omitempty"`
+
+	// Facilitator names the registered facilitator client (by identifier) that
+	// verifies and settles this option, overriding RouteConfig.Facilitator
+	Facilitator string `json:"facilitator,omitempty"`
 }
 
 // PaymentOptions is a slice of PaymentOption for convenience
@@ -101,20 +128,89 @@ type RouteConfig struct {
 	CustomPaywallHTML string                 `json:"customPaywallHtml,omitempty"`
 	Extensions        map[string]interface{} `json:"extensions,omitempty"`
 
+	// OutputSchema is a JSON Schema for the paid response, included in the 402 resource info
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+
+	// OutputExample is an example paid response, included in the 402 resource info
+	OutputExample interface{} `json:"outputExample,omitempty"`
+
+	// BindRequestBody binds payments to a SHA-256 hash of the request body. The hash
+	// is added to requirements extra (RequestBodyHashExtraKey) with the server's
+	// proof of it (types.RequestBodyProofExtraKey), and payments are rejected before
+	// verification unless their accepted requirements carry the hash of the body
+	// received and the proof. The adapter must implement HTTPBodyAdapter; bodies
+	// over MaxRequestBodySize are rejected with a 413.
+	//
+	// The binding is advisory: the payer's signature does not cover the hash, and
+	// anyone can obtain a proof for a body by requesting the route with it, so a
+	// relaying party can move a payment to another body of the same price. It
+	// catches mismatches between the body priced and the body sent, not replays.
+	BindRequestBody bool `json:"bindRequestBody,omitempty"`
+
+	// Attribution attaches metadata such as an order ID to the route's payment
+	// requirements (see AttributionFunc). It reaches SVM payment memos,
+	// settlement records, and PaymentIdentity.
+	Attribution AttributionFunc `json:"-"`
+
+	// Monitor runs this route in dry-run mode: payments are evaluated and
+	// reported to OnMonitor hooks but never enforced or settled
+	Monitor bool `json:"monitor,omitempty"`
+
+	// Tenant routes payments for this route through a resource server registered
+	// with RegisterTenant (its own facilitator and credentials)
+	Tenant string `json:"tenant,omitempty"`
+
+	// Facilitator names the registered facilitator client (by identifier) that
+	// verifies and settles payments for this route instead of the server-wide
+	// choice by scheme and network
+	Facilitator string `json:"facilitator,omitempty"`
+
+	// Rollout enforces payment for only a percentage of clients (default: all clients pay)
+	Rollout *RolloutConfig `json:"rollout,omitempty"`
+
+	// FreeTier lets each client make a number of free requests per window before payment is required
+	FreeTier *FreeTierConfig `json:"freeTier,omitempty"`
+
+	// Pagination prices a list route per page request or per returned item
+	Pagination *PaginationConfig `json:"pagination,omitempty"`
+
 	// UnpaidResponseBody is an optional callback to generate a custom response for unpaid API requests.
 	// For browser requests (Accept: text/html), the paywall HTML takes precedence.
 	// If not provided, defaults to { ContentType: "application/json", Body: nil }.
 	UnpaidResponseBody UnpaidResponseBodyFunc `json:"-"`
 }
 
-// RoutesConfig maps route patterns to configurations
+// RoutesConfig maps route patterns to configurations.
+//
+// A pattern is an optional method or method list ("GET", "GET|POST"), an
+// optional host (see virtual hosts), and a path:
+//
+//	"GET /api/users"          exact path
+//	"GET /api/*"              "*" matches any characters, including "/"
+//	"GET /api/**"             "/**" matches the prefix itself and anything below it
+//	"GET /items/[id]"         "[id]" matches one path segment
+//	"GET /v/[[version]]/docs" "[[version]]" matches one optional segment
+//	"GET /items/{[0-9]+}"     "{...}" is a regular expression
+//	"GET /files/report\*"    "\" escapes the next character
+//
+// When several patterns match a request, the first in this order wins:
+// host-specific routes, then patterns with fewer wildcards ("*", "**"), then
+// fewer parameters ("[id]", "{...}"), then more literal characters, then
+// a single method before a method list before any method, and finally the
+// pattern text itself.
 type RoutesConfig map[string]RouteConfig
 
 // CompiledRoute is a parsed route ready for matching
 type CompiledRoute struct {
-	Verb   string
-	Regex  *regexp.Regexp
-	Config RouteConfig
+	Pattern   string // Route key as configured, e.g. "GET /api/*"
+	Verb      string
+	Host      string         // Host pattern, empty for routes served on every host
+	HostRegex *regexp.Regexp // Nil for routes served on every host
+	Regex     *regexp.Regexp
+	Config    RouteConfig
+
+	rank routeRank // Specificity used to order routes
+	err  error     // Set when the pattern is invalid; the route then matches nothing
 }
 
 // ============================================================================
@@ -124,6 +220,7 @@ type CompiledRoute struct {
 // HTTPRequestContext encapsulates an HTTP request
 type HTTPRequestContext struct {
 	Adapter       HTTPAdapter
+	Host          string // Request host, used by host-specific routes (default: Host header or URL host)
 	Path          string
 	Method        string
 	PaymentHeader string
@@ -143,6 +240,12 @@ type HTTPProcessResult struct {
 	Response            *HTTPResponseInstructions
 	PaymentPayload      *types.PaymentPayload      // V2 only
 	PaymentRequirements *types.PaymentRequirements // V2 only
+	Payer               string                     // Payer reported by verification
+	Monitor             *MonitorEvent              // Set when the route ran in monitor mode
+	Reconcile           *Reconciliation            // Set when let through under the verify grace period
+	Tenant              string                     // Tenant of the matched route; settle with ContextWithTenant
+	Facilitator         string                     // Facilitator named by the route or option; settle with x402.ContextWithFacilitator
+	Pagination          *PaginationConfig          // Pagination pricing of the matched route; settle with ItemSettlementAmount
 }
 
 // Result type constants
@@ -160,6 +263,8 @@ type ProcessSettleResult struct {
 	Transaction string
 	Network     x402.Network
 	Payer       string
+	ExplorerURL string // Block explorer link for the transaction, when the network has one
+	PayToName   string // Name the payTo address was resolved from, when name resolution is enabled
 }
 
 // ============================================================================
@@ -170,6 +275,76 @@ type ProcessSettleResult struct {
 type x402HTTPResourceServer struct {
 	*x402.X402ResourceServer
 	compiledRoutes []CompiledRoute
+
+	// headerNames renames the payment headers (see SetHeaderNames)
+	headerNames HeaderNames
+
+	// paymentRequiredHeaderLimit is the chunking threshold for PAYMENT-REQUIRED (see SetPaymentRequiredHeaderLimit)
+	paymentRequiredHeaderLimit int
+
+	// compressPaymentHeaders enables gzip negotiation for payment headers (see EnablePaymentHeaderCompression)
+	compressPaymentHeaders bool
+
+	// cborPaymentHeaders enables CBOR negotiation for payment headers (see EnableCBORPaymentHeaders)
+	cborPaymentHeaders bool
+
+	// monitorMode observes payments on every route without enforcing them (see SetMonitorMode)
+	monitorMode  bool
+	monitorHooks []MonitorHook
+
+	// store holds server-side state such as free-tier usage (see SetStore)
+	store Store
+
+	// gracePeriod lets recently-paid clients through facilitator outages (see EnableVerifyGracePeriod)
+	gracePeriod         *VerifyGracePeriodConfig
+	reconciliationHooks []ReconciliationHook
+
+	// tenants maps tenant names to their own resource servers (see RegisterTenant)
+	tenants map[string]*x402.X402ResourceServer
+
+	// excludedRoutes and bypasses exempt requests from payment (see ExcludeRoutes and AddBypass)
+	excludedRoutes []CompiledRoute
+	bypasses       []BypassFunc
+
+	// browserDetector chooses between the paywall and JSON (see SetBrowserDetector)
+	browserDetector BrowserDetector
+
+	// mediaEncoders render 402 bodies for registered media types (see RegisterMediaType)
+	mediaEncoders map[string]PaymentRequiredEncoder
+	mediaTypes    []string
+
+	// addressBook resolves payTo names (see EnableNameResolution)
+	addressBook *AddressBook
+	strictNames bool
+
+	// extensionRegistry enriches, advertises and validates extensions (see SetExtensionRegistry)
+	extensionRegistry *extensions.Registry
+
+	// bindOrigin binds payments to the resource origin (see EnableOriginBinding)
+	bindOrigin bool
+
+	// requireRequirementsHash rejects payloads without a requirementsHash (see RequireRequirementsHash)
+	requireRequirementsHash bool
+
+	// doubleSpendProtection serializes requests reusing an authorization (see EnableDoubleSpendProtection)
+	doubleSpendProtection bool
+
+	// streamFailurePolicy makes up for paid streams that fail midway, recorded in streamAudit (see OnStreamFailure)
+	streamFailurePolicy StreamFailurePolicy
+	streamAudit         StreamAuditStore
+
+	// geoIP locates clients for ClientInfo (see SetGeoIPProvider)
+	geoIP GeoIPProvider
+
+	// trustedProxies may set the client IP with forwarding headers (see SetTrustedProxies)
+	trustedProxies []*net.IPNet
+
+	// optionFilters withhold payment options per request (see AddPaymentOptionFilter)
+	optionFilters []PaymentOptionFilter
+
+	// sseSessions holds what metered SSE streams' payments have left (see MeterSSE)
+	sseMu       sync.Mutex
+	sseSessions map[string]*sseSession
 }
 
 // Newx402HTTPResourceServer creates a new HTTP resource server
@@ -182,6 +357,7 @@ func Wrappedx402HTTPResourceServer(routes RoutesConfig, resourceServer *x402.X40
 	server := &x402HTTPResourceServer{
 		X402ResourceServer: resourceServer,
 		compiledRoutes:     []CompiledRoute{},
+		store:              NewMemoryStore(),
 	}
 
 	// Handle both single route and multiple routes
@@ -192,14 +368,25 @@ func Wrappedx402HTTPResourceServer(routes RoutesConfig, resourceServer *x402.X40
 
 	// Compile routes
 	for pattern, config := range normalizedRoutes {
-		verb, regex := parseRoutePattern(pattern)
+		host, hostlessPattern := splitRouteHost(pattern)
+		verb, regex, rank, err := compileRoutePattern(hostlessPattern)
 		server.compiledRoutes = append(server.compiledRoutes, CompiledRoute{
-			Verb:   verb,
-			Regex:  regex,
-			Config: config,
+			Pattern:   pattern,
+			Verb:      verb,
+			Host:      host,
+			HostRegex: compileHostPattern(host),
+			Regex:     regex,
+			Config:    config,
+			rank:      rank,
+			err:       err,
 		})
 	}
 
+	// Order routes by precedence (see RoutesConfig)
+	sort.SliceStable(server.compiledRoutes, func(i, j int) bool {
+		return routePrecedes(server.compiledRoutes[i], server.compiledRoutes[j])
+	})
+
 	return server
 }
 
@@ -216,6 +403,11 @@ func Wrappedx402HTTPResourceServer(routes RoutesConfig, resourceServer *x402.X40
 //
 //	Array of payment requirements (one per option)
 func (s *x402HTTPResourceServer) BuildPaymentRequirementsFromOptions(ctx context.Context, options []PaymentOption, reqCtx HTTPRequestContext) ([]types.PaymentRequirements, error) {
+	return s.buildPaymentRequirements(ctx, s.X402ResourceServer, options, reqCtx)
+}
+
+// buildPaymentRequirements builds requirements through the given resource server (shared or tenant)
+func (s *x402HTTPResourceServer) buildPaymentRequirements(ctx context.Context, core *x402.X402ResourceServer, options []PaymentOption, reqCtx HTTPRequestContext) ([]types.PaymentRequirements, error) {
 	allRequirements := make([]types.PaymentRequirements, 0)
 
 	for _, option := range options {
@@ -234,6 +426,10 @@ func (s *x402HTTPResourceServer) BuildPaymentRequirementsFromOptions(ctx context
 		} else {
 			return nil, fmt.Errorf("payTo must be string or DynamicPayToFunc, got %T", option.PayTo)
 		}
+		resolvedPayTo, err := s.resolvePayTo(ctx, option.Network, resolvedPayTo)
+		if err != nil {
+			return nil, err
+		}
 
 		// Resolve Price (x402.Price or DynamicPriceFunc)
 		var resolvedPrice x402.Price
@@ -259,7 +455,7 @@ func (s *x402HTTPResourceServer) BuildPaymentRequirementsFromOptions(ctx context
 		}
 
 		// Use existing BuildPaymentRequirementsFromConfig for each option
-		requirements, err := s.BuildPaymentRequirementsFromConfig(ctx, resourceConfig)
+		requirements, err := core.BuildPaymentRequirementsFromConfig(x402.ContextWithFacilitator(ctx, option.Facilitator), resourceConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build requirements for option %s on %s: %w", option.Scheme, option.Network, err)
 		}
@@ -270,22 +466,70 @@ func (s *x402HTTPResourceServer) BuildPaymentRequirementsFromOptions(ctx context
 	return allRequirements, nil
 }
 
+// optionFacilitator returns the facilitator named by the payment option the
+// requirements were built from, matched by scheme and network
+func optionFacilitator(options []PaymentOption, requirements types.PaymentRequirements) string {
+	for _, option := range options {
+		if option.Facilitator != "" && option.Scheme == requirements.Scheme && string(option.Network) == requirements.Network {
+			return option.Facilitator
+		}
+	}
+	return ""
+}
+
 // ProcessHTTPRequest handles an HTTP request and returns processing result
 func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx HTTPRequestContext, paywallConfig *PaywallConfig) HTTPProcessResult {
 	// Find matching route
-	routeConfig := s.getRouteConfig(reqCtx.Path, reqCtx.Method)
+	routeConfig := s.getRouteConfig(requestHost(reqCtx), reqCtx.Path, reqCtx.Method)
 	if routeConfig == nil {
 		return HTTPProcessResult{Type: ResultNoPaymentRequired}
 	}
 
+	// Make the client's IP and location available to pricing, filters, and hooks
+	ctx = s.withClientInfo(ctx, reqCtx)
+
+	// Trusted callers (e.g. internal services) skip payment
+	if s.bypassed(ctx, reqCtx) {
+		return HTTPProcessResult{Type: ResultNoPaymentRequired}
+	}
+
+	// Clients outside a partial rollout are not asked to pay
+	if routeConfig.Rollout != nil && !routeConfig.Rollout.enforces(ctx, reqCtx) {
+		return HTTPProcessResult{Type: ResultNoPaymentRequired}
+	}
+
+	// Unpaid requests within the client's free quota are let through
+	if routeConfig.FreeTier != nil && s.allowsFree(ctx, reqCtx, routeConfig.FreeTier) {
+		return HTTPProcessResult{Type: ResultNoPaymentRequired}
+	}
+
+	if s.monitorMode || routeConfig.Monitor {
+		return s.processMonitoredRequest(ctx, reqCtx, paywallConfig, routeConfig)
+	}
+	return s.processHTTPRequest(ctx, reqCtx, paywallConfig, routeConfig, nil)
+}
+
+// processHTTPRequest enforces payment for a matched route. When trace is non-nil
+// it records the requirements and verification outcome for monitor mode.
+func (s *x402HTTPResourceServer) processHTTPRequest(ctx context.Context, reqCtx HTTPRequestContext, paywallConfig *PaywallConfig, routeConfig *RouteConfig, trace *monitorTrace) HTTPProcessResult {
 	// Get payment options from route config
 	paymentOptions := routeConfig.Accepts
 	if len(paymentOptions) == 0 {
 		return HTTPProcessResult{Type: ResultNoPaymentRequired}
 	}
 
+	// Withhold options the client may not use (e.g. networks unavailable in their region)
+	paymentOptions = s.filterPaymentOptions(ctx, reqCtx, paymentOptions)
+	if len(paymentOptions) == 0 {
+		return paymentUnavailableResponse()
+	}
+
 	// Check for payment header (V2 only)
 	typedPayload, err := s.extractPaymentV2(reqCtx.Adapter)
+	if trace != nil {
+		trace.paymentProvided = typedPayload != nil || err != nil
+		trace.err = err
+	}
 	if err != nil {
 		return HTTPProcessResult{
 			Type:     ResultPaymentError,
@@ -293,8 +537,29 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 		}
 	}
 
+	// Dynamic prices can honor the quote the payment was made against
+	if typedPayload != nil {
+		ctx = context.WithValue(ctx, paymentPayloadContextKey{}, typedPayload)
+	}
+
+	// Tenant routes use the tenant's own facilitator
+	core, err := s.resourceServerFor(routeConfig.Tenant)
+	if err != nil {
+		return HTTPProcessResult{
+			Type: ResultPaymentError,
+			Response: &HTTPResponseInstructions{
+				Status:  500,
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    map[string]string{"error": err.Error()},
+			},
+		}
+	}
+
+	// Routes may name a facilitator; options can override it
+	ctx = x402.ContextWithFacilitator(ctx, routeConfig.Facilitator)
+
 	// Build requirements from all payment options (resolves dynamic values inline)
-	requirements, err := s.BuildPaymentRequirementsFromOptions(ctx, paymentOptions, reqCtx)
+	requirements, err := s.buildPaymentRequirements(ctx, core, paymentOptions, reqCtx)
 	if err != nil {
 		return HTTPProcessResult{
 			Type: ResultPaymentError,
@@ -313,9 +578,11 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 	}
 
 	resourceInfo := &types.ResourceInfo{
-		URL:         resourceURL,
-		Description: routeConfig.Description,
-		MimeType:    routeConfig.MimeType,
+		URL:           resourceURL,
+		Description:   routeConfig.Description,
+		MimeType:      routeConfig.MimeType,
+		OutputSchema:  routeConfig.OutputSchema,
+		OutputExample: routeConfig.OutputExample,
 	}
 
 	for i := range requirements {
@@ -324,18 +591,67 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 		}
 	}
 
-	extensions := routeConfig.Extensions
-	// TODO: Add EnrichExtensions method if needed
-	// if extensions != nil && len(extensions) > 0 {
-	// 	extensions = s.EnrichExtensions(extensions, reqCtx)
-	// }
+	// Bind the payment to this exact request body
+	var bodyHash string
+	if routeConfig.BindRequestBody {
+		bodyHash, err = requestBodyHash(reqCtx.Adapter)
+		if err != nil {
+			return HTTPProcessResult{
+				Type:     ResultPaymentError,
+				Response: requestBodyError(err),
+			}
+		}
+		bindRequirementsToBody(core, requirements, bodyHash)
+	}
+
+	// Tie the payment to the business object it pays for
+	if routeConfig.Attribution != nil {
+		attribution, err := routeConfig.Attribution(ctx, reqCtx)
+		if err == nil {
+			err = types.ValidateAttribution(attribution)
+		}
+		if err != nil {
+			return HTTPProcessResult{
+				Type: ResultPaymentError,
+				Response: &HTTPResponseInstructions{
+					Status:  500,
+					Headers: map[string]string{"Content-Type": "application/json"},
+					Body:    map[string]string{"error": fmt.Sprintf("Failed to attribute payment: %v", err)},
+				},
+			}
+		}
+		attributeRequirements(requirements, attribution)
+	}
+
+	// Bind the payment to the origin of the resource
+	var origin string
+	if s.bindOrigin {
+		origin, err = types.ResourceOrigin(resourceInfo.URL)
+		if err != nil {
+			return HTTPProcessResult{
+				Type: ResultPaymentError,
+				Response: &HTTPResponseInstructions{
+					Status:  500,
+					Headers: map[string]string{"Content-Type": "application/json"},
+					Body:    map[string]string{"error": err.Error()},
+				},
+			}
+		}
+		bindRequirementsToOrigin(requirements, origin)
+	}
+
+	if trace != nil {
+		trace.requirements = requirements
+	}
+
+	routeExtensions := s.enrichExtensions(routeConfig.Extensions, reqCtx)
 
 	if typedPayload == nil {
-		paymentRequired := s.CreatePaymentRequiredResponse(
+		paymentRequired := core.CreatePaymentRequiredResponse(
 			requirements,
 			resourceInfo,
 			"Payment required",
-			extensions,
+			routeExtensions,
 		)
 
 		// Call the UnpaidResponseBody callback if provided
@@ -355,105 +671,154 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 			unpaidResponse = unpaidResp
 		}
 
-		response, err := s.createHTTPResponseV2(
+		return s.paymentRequiredResult(
+			reqCtx,
 			paymentRequired,
-			s.isWebBrowser(reqCtx.Adapter),
-			paywallConfig,
+			true,
+			s.paywallConfigWithNames(paywallConfig, requirements),
 			routeConfig.CustomPaywallHTML,
 			unpaidResponse,
 		)
-		if err != nil {
-			return HTTPProcessResult{
-				Type: ResultPaymentError,
-				Response: &HTTPResponseInstructions{
-					Status:  500,
-					Headers: map[string]string{"Content-Type": "application/json"},
-					Body:    map[string]string{"error": fmt.Sprintf("Failed to create payment response: %v", err)},
-				},
-			}
-		}
-		return HTTPProcessResult{
-			Type:     ResultPaymentError,
-			Response: response,
-		}
 	}
 
 	// Find matching requirements (type-safe)
-	matchingReqs := s.FindMatchingRequirements(requirements, *typedPayload)
+	matchingReqs := s.findMatchingRequirements(core, requirements, *typedPayload)
 	if matchingReqs == nil {
-		paymentRequired := s.CreatePaymentRequiredResponse(
-			requirements,
-			resourceInfo,
-			"No matching payment requirements",
-			extensions,
-		)
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, "No matching payment requirements", routeExtensions, paywallConfig)
+	}
 
-		response, err := s.createHTTPResponseV2(paymentRequired, false, paywallConfig, "", nil)
-		if err != nil {
-			return HTTPProcessResult{
-				Type: ResultPaymentError,
-				Response: &HTTPResponseInstructions{
-					Status:  500,
-					Headers: map[string]string{"Content-Type": "application/json"},
-					Body:    map[string]string{"error": fmt.Sprintf("Failed to create payment response: %v", err)},
-				},
-			}
-		}
-		return HTTPProcessResult{
-			Type:     ResultPaymentError,
-			Response: response,
-		}
+	// Reject payments signed for a different request body
+	if routeConfig.BindRequestBody && !acceptedBodyMatches(core, typedPayload.Accepted, bodyHash) {
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, "Payment is bound to a different request body", routeExtensions, paywallConfig)
 	}
 
-	// Verify payment (type-safe)
-	_, verifyErr := s.VerifyPayment(ctx, *typedPayload, *matchingReqs)
-	if verifyErr != nil {
-		err = verifyErr
-		errorMsg := err.Error()
+	// Reject payments accepted for a different origin
+	if s.bindOrigin && !acceptedOriginMatches(typedPayload.Accepted.Extra, origin) {
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, "Payment is bound to a different origin", routeExtensions, paywallConfig)
+	}
 
-		paymentRequired := s.CreatePaymentRequiredResponse(
-			requirements,
-			resourceInfo,
-			errorMsg,
-			extensions,
-		)
+	// Reject payments presenting invalid extensions
+	if err := s.validateExtensions(typedPayload.Extensions); err != nil {
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, fmt.Sprintf("Invalid payment extensions: %v", err), routeExtensions, paywallConfig)
+	}
 
-		response, err := s.createHTTPResponseV2(paymentRequired, false, paywallConfig, "", nil)
-		if err != nil {
+	// Reject authorizations already presented by another in-flight request.
+	// Monitor mode never settles, so it never claims.
+	if trace == nil && !s.claimAuthorization(ctx, *typedPayload, *matchingReqs) {
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, "Payment authorization is already in use", routeExtensions, paywallConfig)
+	}
+
+	// Verify payment (type-safe) through the facilitator named for the matched option, if any
+	ctx = x402.ContextWithFacilitator(ctx, optionFacilitator(paymentOptions, *matchingReqs))
+	facilitator := x402.FacilitatorFromContext(ctx)
+	verifyResponse, verifyErr := core.VerifyPayment(ctx, *typedPayload, *matchingReqs)
+	if verifyErr != nil {
+		// Let recently-paid clients through transient facilitator failures
+		if reconciliation := s.graceVerifyFailure(ctx, reqCtx, typedPayload, matchingReqs, verifyErr); reconciliation != nil {
 			return HTTPProcessResult{
-				Type: ResultPaymentError,
-				Response: &HTTPResponseInstructions{
-					Status:  500,
-					Headers: map[string]string{"Content-Type": "application/json"},
-					Body:    map[string]string{"error": fmt.Sprintf("Failed to create payment response: %v", err)},
-				},
+				Type:                ResultPaymentVerified,
+				PaymentPayload:      typedPayload,
+				PaymentRequirements: matchingReqs,
+				Tenant:              routeConfig.Tenant,
+				Facilitator:         facilitator,
+				Pagination:          routeConfig.Pagination,
+				Reconcile:           reconciliation,
 			}
 		}
-		return HTTPProcessResult{
-			Type:     ResultPaymentError,
-			Response: response,
+
+		if trace != nil {
+			trace.err = verifyErr
+		}
+		if trace == nil {
+			s.ReleasePayment(ctx, *typedPayload)
 		}
+		return s.paymentError(core, reqCtx, requirements, resourceInfo, verifyErr.Error(), routeExtensions, paywallConfig)
 	}
 
 	// Payment verified
-	return HTTPProcessResult{
+	s.recordPayment(ctx, reqCtx)
+	result := HTTPProcessResult{
 		Type:                ResultPaymentVerified,
 		PaymentPayload:      typedPayload,
 		PaymentRequirements: matchingReqs,
+		Tenant:              routeConfig.Tenant,
+		Facilitator:         facilitator,
+		Pagination:          routeConfig.Pagination,
+	}
+	if verifyResponse != nil {
+		result.Payer = verifyResponse.Payer
+	}
+	return result
+}
+
+// paymentError responds with a 402 offering requirements again, for a
+// payment rejected with msg
+func (s *x402HTTPResourceServer) paymentError(core *x402.X402ResourceServer, reqCtx HTTPRequestContext, requirements []types.PaymentRequirements, resourceInfo *types.ResourceInfo, msg string, extensions map[string]interface{}, paywallConfig *PaywallConfig) HTTPProcessResult {
+	paymentRequired := core.CreatePaymentRequiredResponse(requirements, resourceInfo, msg, extensions)
+	return s.paymentRequiredResult(reqCtx, paymentRequired, false, paywallConfig, "", nil)
+}
+
+// paymentRequiredResult builds the 402 result for paymentRequired, or a 500
+// when the response cannot be created (see paymentRequiredResponse)
+func (s *x402HTTPResourceServer) paymentRequiredResult(reqCtx HTTPRequestContext, paymentRequired types.PaymentRequired, allowHTML bool, paywallConfig *PaywallConfig, customHTML string, unpaidResponse *UnpaidResponse) HTTPProcessResult {
+	response, err := s.paymentRequiredResponse(reqCtx, paymentRequired, allowHTML, paywallConfig, customHTML, unpaidResponse)
+	if err != nil {
+		return HTTPProcessResult{
+			Type: ResultPaymentError,
+			Response: &HTTPResponseInstructions{
+				Status:  500,
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    map[string]string{"error": fmt.Sprintf("Failed to create payment response: %v", err)},
+			},
+		}
+	}
+	s.applyPaymentHeaderCompression(response, reqCtx.Adapter)
+	return HTTPProcessResult{
+		Type:     ResultPaymentError,
+		Response: response,
 	}
 }
 
 // RequiresPayment checks if a request requires payment based on route configuration
 func (s *x402HTTPResourceServer) RequiresPayment(reqCtx HTTPRequestContext) bool {
-	routeConfig := s.getRouteConfig(reqCtx.Path, reqCtx.Method)
+	routeConfig := s.getRouteConfig(requestHost(reqCtx), reqCtx.Path, reqCtx.Method)
 	return routeConfig != nil
 }
 
 // ProcessSettlement handles settlement after successful response
 func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) *ProcessSettleResult {
+	return s.processSettlement(ctx, payload, requirements, "")
+}
+
+// ProcessSettlementAmount handles settlement after successful response for
+// amountToSettle (atomic units, at most the requirements' amount) instead of
+// the authorized maximum, e.g. for what a metered request used. The
+// facilitator must support partial settlement unless it is the full amount.
+func (s *x402HTTPResourceServer) ProcessSettlementAmount(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) *ProcessSettleResult {
+	return s.processSettlement(ctx, payload, requirements, amountToSettle)
+}
+
+// processSettlement settles for amountToSettle, or the full amount when empty
+func (s *x402HTTPResourceServer) processSettlement(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) *ProcessSettleResult {
+	// Settle through the tenant's facilitator when the context names one
+	core, err := s.resourceServerFor(TenantFromContext(ctx))
+	if err != nil {
+		s.ReleasePayment(ctx, payload)
+		return &ProcessSettleResult{
+			Success:     false,
+			ErrorReason: err.Error(),
+		}
+	}
+
 	// Settle payment (type-safe, no marshal needed)
-	settleResult, err := s.SettlePayment(ctx, payload, requirements)
+	var settleResult *x402.SettleResponse
+	if amountToSettle == "" {
+		settleResult, err = core.SettlePayment(ctx, payload, requirements)
+	} else {
+		settleResult, err = core.SettlePaymentAmount(ctx, payload, requirements, amountToSettle)
+	}
 	if err != nil {
+		s.ReleasePayment(ctx, payload)
 		return &ProcessSettleResult{
 			Success:     false,
 			ErrorReason: err.Error(),
@@ -461,6 +826,7 @@ func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload
 	}
 
 	if !settleResult.Success {
+		s.ReleasePayment(ctx, payload)
 		return &ProcessSettleResult{
 			Success:     false,
 			ErrorReason: settleResult.ErrorReason,
@@ -475,13 +841,20 @@ func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload
 		}
 	}
 
-	return &ProcessSettleResult{
+	explorerURL, _ := settleResult.Network.ExplorerURL(settleResult.Transaction)
+
+	result := &ProcessSettleResult{
 		Success:     true,
 		Headers:     headers,
 		Transaction: settleResult.Transaction,
 		Network:     settleResult.Network,
 		Payer:       settleResult.Payer,
+		ExplorerURL: explorerURL,
 	}
+	if s.addressBook != nil {
+		result.PayToName, _ = s.addressBook.NameOf(requirements.PayTo)
+	}
+	return result
 }
 
 // ============================================================================
@@ -489,13 +862,16 @@ func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload
 // ============================================================================
 
 // getRouteConfig finds matching route configuration
-func (s *x402HTTPResourceServer) getRouteConfig(path, method string) *RouteConfig {
+func (s *x402HTTPResourceServer) getRouteConfig(host, path, method string) *RouteConfig {
 	normalizedPath := normalizePath(path)
 	upperMethod := strings.ToUpper(method)
 
+	if s.isExcluded(host, normalizedPath, upperMethod) {
+		return nil
+	}
+
 	for _, route := range s.compiledRoutes {
-		if route.Regex.MatchString(normalizedPath) &&
-			(route.Verb == "*" || route.Verb == upperMethod) {
+		if route.matches(host, normalizedPath, upperMethod) {
 			config := route.Config // Make a copy
 			return &config
 		}
@@ -504,20 +880,27 @@ func (s *x402HTTPResourceServer) getRouteConfig(path, method string) *RouteConfi
 	return nil
 }
 
+type paymentPayloadContextKey struct{}
+
+// PaymentPayloadFromContext returns the payment presented with the request
+// while its requirements are built and verified, e.g. so a DynamicPriceFunc
+// can price a paid request as it was quoted
+func PaymentPayloadFromContext(ctx context.Context) (*types.PaymentPayload, bool) {
+	payload, ok := ctx.Value(paymentPayloadContextKey{}).(*types.PaymentPayload)
+	return payload, ok
+}
+
 // extractPaymentV2 extracts V2 payment from headers (V2 only)
 func (s *x402HTTPResourceServer) extractPaymentV2(adapter HTTPAdapter) (*types.PaymentPayload, error) {
-	// Check v2 header
-	header := adapter.GetHeader("PAYMENT-SIGNATURE")
-	if header == "" {
-		header = adapter.GetHeader("payment-signature")
-	}
+	// Check v2 header (under the configured name, or PAYMENT-SIGNATURE)
+	header := s.paymentSignatureHeader(adapter)
 
 	if header == "" {
 		return nil, nil // No payment header
 	}
 
-	// Decode base64 header
-	jsonBytes, err := decodeBase64Header(header)
+	// Decode base64 header (gzip-compressed if PAYMENT-ENCODING says so)
+	jsonBytes, err := decodePaymentHeaderBytes(header, adapter.GetHeader(PaymentEncodingHeader))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode payment header: %w", err)
 	}
@@ -561,16 +944,12 @@ func (s *x402HTTPResourceServer) extractPayment(adapter HTTPAdapter) *x402.Payme
 	}
 }
 
-// decodeBase64Header decodes a base64 header to JSON bytes
-func decodeBase64Header(header string) ([]byte, error) {
-	return base64.StdEncoding.DecodeString(header)
-}
-
-// isWebBrowser checks if request is from a web browser
-func (s *x402HTTPResourceServer) isWebBrowser(adapter HTTPAdapter) bool {
-	accept := adapter.GetAcceptHeader()
-	userAgent := adapter.GetUserAgent()
-	return strings.Contains(accept, "text/html") && strings.Contains(userAgent, "Mozilla")
+// isWebBrowser checks if request is from a web browser (see SetBrowserDetector)
+func (s *x402HTTPResourceServer) isWebBrowser(reqCtx HTTPRequestContext) bool {
+	if s.browserDetector != nil {
+		return s.browserDetector.IsBrowser(reqCtx)
+	}
+	return DefaultBrowserDetector.IsBrowser(reqCtx)
 }
 
 // createHTTPResponseV2 creates response instructions for V2 PaymentRequired
@@ -584,6 +963,23 @@ func (s *x402HTTPResourceServer) isWebBrowser(adapter HTTPAdapter) bool {
 //	unpaidResponse: Optional custom response for API clients (ignored for browser requests)
 func (s *x402HTTPResourceServer) createHTTPResponseV2(paymentRequired types.PaymentRequired, isWebBrowser bool, paywallConfig *PaywallConfig, customHTML string, unpaidResponse *UnpaidResponse) (*HTTPResponseInstructions, error) {
 	if isWebBrowser {
+		if customHTML == "" && paywallConfig != nil && paywallConfig.EnableCSP {
+			nonce, err := GeneratePaywallNonce()
+			if err != nil {
+				return nil, err
+			}
+			paywall := RenderPaywall(paymentRequired, paywallConfig, PaywallRenderOptions{Nonce: nonce})
+			return &HTTPResponseInstructions{
+				Status: 402,
+				Headers: map[string]string{
+					"Content-Type":              "text/html",
+					ContentSecurityPolicyHeader: paywall.ContentSecurityPolicy,
+				},
+				Body:   paywall.HTML,
+				IsHTML: true,
+			}, nil
+		}
+
 		html := s.generatePaywallHTMLV2(paymentRequired, paywallConfig, customHTML)
 		return &HTTPResponseInstructions{
 			Status: 402,
@@ -609,13 +1005,13 @@ func (s *x402HTTPResourceServer) createHTTPResponseV2(paymentRequired types.Paym
 		return nil, fmt.Errorf("failed to encode payment required header: %w", err)
 	}
 
+	headers := s.paymentRequiredHeaders(encodedHeader)
+	headers["Content-Type"] = contentType
+
 	return &HTTPResponseInstructions{
-		Status: 402,
-		Headers: map[string]string{
-			"Content-Type":     contentType,
-			"PAYMENT-REQUIRED": encodedHeader,
-		},
-		Body: body,
+		Status:  402,
+		Headers: headers,
+		Body:    body,
 	}, nil
 }
 
@@ -640,7 +1036,7 @@ func (s *x402HTTPResourceServer) createSettlementHeaders(response *x402.SettleRe
 		return nil, fmt.Errorf("failed to encode payment response header: %w", err)
 	}
 	return map[string]string{
-		"PAYMENT-RESPONSE": encodedHeader,
+		s.HeaderNames().PaymentResponse: encodedHeader,
 	}, nil
 }
 
@@ -662,9 +1058,11 @@ func (s *x402HTTPResourceServer) generatePaywallHTMLV2(paymentRequired types.Pay
 	// Convert resource
 	if paymentRequired.Resource != nil {
 		genericRequired.Resource = &x402.ResourceInfo{
-			URL:         paymentRequired.Resource.URL,
-			Description: paymentRequired.Resource.Description,
-			MimeType:    paymentRequired.Resource.MimeType,
+			URL:           paymentRequired.Resource.URL,
+			Description:   paymentRequired.Resource.Description,
+			MimeType:      paymentRequired.Resource.MimeType,
+			OutputSchema:  paymentRequired.Resource.OutputSchema,
+			OutputExample: paymentRequired.Resource.OutputExample,
 		}
 	}
 
@@ -682,84 +1080,7 @@ func (s *x402HTTPResourceServer) generatePaywallHTML(paymentRequired x402.Paymen
 	if customHTML != "" {
 		return customHTML
 	}
-
-	// Calculate display amount (assuming USDC with 6 decimals)
-	displayAmount := s.getDisplayAmount(paymentRequired)
-
-	appName := ""
-	appLogo := ""
-	testnet := false
-	currentURL := ""
-
-	if config != nil {
-		appName = config.AppName
-		appLogo = config.AppLogo
-		testnet = config.Testnet
-		currentURL = config.CurrentURL
-	}
-
-	// Use resource URL as currentUrl if not explicitly configured
-	if currentURL == "" && paymentRequired.Resource != nil {
-		currentURL = paymentRequired.Resource.URL
-	}
-
-	requirementsJSON, _ := json.Marshal(paymentRequired)
-
-	// Inject configuration into the template
-	configScript := fmt.Sprintf(`<script>
-		window.x402 = {
-			paymentRequired: %s,
-			appName: "%s",
-			appLogo: "%s",
-			amount: %.6f,
-			testnet: %t,
-			displayAmount: %.2f,
-			currentUrl: "%s"
-		};
-	</script>`,
-		string(requirementsJSON),
-		html.EscapeString(appName),
-		html.EscapeString(appLogo),
-		displayAmount,
-		testnet,
-		displayAmount,
-		html.EscapeString(currentURL),
-	)
-
-	// Select template based on network
-	template := s.selectPaywallTemplate(paymentRequired)
-	return strings.Replace(template, "</body>", configScript+"</body>", 1)
-}
-
-// selectPaywallTemplate chooses the appropriate paywall template based on the network
-// Returns EVM template for eip155:* networks, SVM template for solana:* networks
-func (s *x402HTTPResourceServer) selectPaywallTemplate(paymentRequired x402.PaymentRequired) string {
-	if len(paymentRequired.Accepts) == 0 {
-		return EVMPaywallTemplate // Default to EVM
-	}
-
-	network := paymentRequired.Accepts[0].Network
-	if strings.HasPrefix(network, "solana:") {
-		return SVMPaywallTemplate
-	}
-	return EVMPaywallTemplate
-}
-
-// getDisplayAmount extracts display amount from payment requirements
-func (s *x402HTTPResourceServer) getDisplayAmount(paymentRequired x402.PaymentRequired) float64 {
-	if len(paymentRequired.Accepts) > 0 {
-		firstReq := paymentRequired.Accepts[0]
-		// Check if amount field exists
-		if firstReq.Amount != "" {
-			// V2 format - parse amount
-			amount, err := strconv.ParseFloat(firstReq.Amount, 64)
-			if err == nil {
-				// Assuming USDC with 6 decimals
-				return amount / 1000000
-			}
-		}
-	}
-	return 0.0
+	return RenderPaywallHTML(paymentRequired, config, PaywallRenderOptions{})
 }
 
 // ============================================================================
@@ -767,31 +1088,6 @@ func (s *x402HTTPResourceServer) getDisplayAmount(paymentRequired x402.PaymentRe
 // ============================================================================
 
 // parseRoutePattern parses a route pattern like "GET /api/*"
-func parseRoutePattern(pattern string) (string, *regexp.Regexp) {
-	parts := strings.Fields(pattern)
-
-	var verb, path string
-	if len(parts) == 2 {
-		verb = strings.ToUpper(parts[0])
-		path = parts[1]
-	} else {
-		verb = "*"
-		path = pattern
-	}
-
-	// Convert pattern to regex
-	regexPattern := "^" + regexp.QuoteMeta(path)
-	regexPattern = strings.ReplaceAll(regexPattern, `\*`, `.*?`)
-	// Handle parameters like [id]
-	paramRegex := regexp.MustCompile(`\\\[([^\]]+)\\\]`)
-	regexPattern = paramRegex.ReplaceAllString(regexPattern, `[^/]+`)
-	regexPattern += "$"
-
-	regex := regexp.MustCompile(regexPattern)
-
-	return verb, regex
-}
-
 // normalizePath normalizes a URL path for matching
 func normalizePath(path string) string {
 	// Remove query string and fragment
@@ -48,6 +48,42 @@ func (m *mockHTTPAdapter) GetUserAgent() string {
 	return m.agent
 }
 
+// paymentHeader returns a payment signature header accepting the first
+// requirements the server offers for reqCtx without payment
+func paymentHeader(t *testing.T, server *x402HTTPResourceServer, reqCtx HTTPRequestContext) string {
+	t.Helper()
+	return acceptingPaymentHeader(offeredRequirements(t, server, reqCtx))
+}
+
+// offeredRequirements returns the first requirements the server offers for
+// reqCtx without payment
+func offeredRequirements(t *testing.T, server *x402HTTPResourceServer, reqCtx HTTPRequestContext) types.PaymentRequirements {
+	t.Helper()
+	unpaid := *reqCtx.Adapter.(*mockHTTPAdapter)
+	unpaid.headers = nil
+	reqCtx.Adapter = &unpaid
+	result := server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	if result.Response == nil {
+		t.Fatalf("Expected a 402 for an unpaid request, got %s", result.Type)
+	}
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+	}
+	return required.Accepts[0]
+}
+
+// acceptingPaymentHeader returns a payment signature header accepting
+// requirements
+func acceptingPaymentHeader(requirements types.PaymentRequirements) string {
+	payloadJSON, _ := json.Marshal(types.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted:    requirements,
+	})
+	return base64.StdEncoding.EncodeToString(payloadJSON)
+}
+
 func TestNewx402HTTPResourceServer(t *testing.T) {
 	routes := RoutesConfig{
 		"GET /api": {
@@ -306,36 +342,12 @@ func TestProcessHTTPRequestWithPaymentVerified(t *testing.T) {
 	)
 	_ = server.Initialize(ctx)
 
-	// Create payment payload that matches the route requirements exactly
-	acceptedRequirements := x402.PaymentRequirements{
-		Scheme:            "exact",
-		Network:           "eip155:1",
-		Asset:             "USDC",
-		Amount:            "1000000",
-		PayTo:             "0xtest",
-		MaxTimeoutSeconds: 300,
-		Extra: map[string]interface{}{
-			"resourceUrl": "http://example.com/api",
-		},
-	}
-
-	paymentPayload := x402.PaymentPayload{
-		X402Version: 2,
-		Payload:     map[string]interface{}{"sig": "test"},
-		Accepted:    acceptedRequirements,
-	}
-
-	payloadJSON, _ := json.Marshal(paymentPayload)
-	encoded := base64.StdEncoding.EncodeToString(payloadJSON)
-
-	// Request with payment
+	// Request with a payment accepting the route's requirements
 	adapter := &mockHTTPAdapter{
-		method: "POST",
-		path:   "/api",
-		url:    "http://example.com/api",
-		headers: map[string]string{
-			"PAYMENT-SIGNATURE": encoded,
-		},
+		method:  "POST",
+		path:    "/api",
+		url:     "http://example.com/api",
+		headers: map[string]string{},
 	}
 
 	reqCtx := HTTPRequestContext{
@@ -343,6 +355,7 @@ func TestProcessHTTPRequestWithPaymentVerified(t *testing.T) {
 		Path:    "/api",
 		Method:  "POST",
 	}
+	adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
 
 	result := server.ProcessHTTPRequest(ctx, reqCtx, nil)
 
@@ -414,6 +427,9 @@ func TestProcessSettlement(t *testing.T) {
 	if result.Headers["PAYMENT-RESPONSE"] == "" {
 		t.Error("Expected PAYMENT-RESPONSE header")
 	}
+	if result.ExplorerURL != "https://basescan.org/tx/0xtx" {
+		t.Errorf("Expected Basescan link, got %q", result.ExplorerURL)
+	}
 }
 
 func TestParseRoutePattern(t *testing.T) {
@@ -496,7 +512,6 @@ func TestNormalizePath(t *testing.T) {
 }
 
 func TestGetDisplayAmount(t *testing.T) {
-	server := Newx402HTTPResourceServer(RoutesConfig{})
 
 	tests := []struct {
 		name     string
@@ -539,7 +554,7 @@ func TestGetDisplayAmount(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := server.getDisplayAmount(tt.required)
+			result := getDisplayAmount(tt.required)
 			if result != tt.expected {
 				t.Errorf("Expected %f, got %f", tt.expected, result)
 			}
@@ -604,3 +619,75 @@ func (m *mockFacilitatorClient) GetSupported(ctx context.Context) (x402.Supporte
 func (m *mockFacilitatorClient) Identifier() string {
 	return "mock"
 }
+
+func TestProcessHTTPRequestIncludesOutputSchema(t *testing.T) {
+	ctx := context.Background()
+
+	routes := RoutesConfig{
+		"GET /weather": {
+			Accepts: PaymentOptions{
+				{
+					Scheme:  "exact",
+					PayTo:   "0xtest",
+					Price:   "$0.01",
+					Network: "eip155:1",
+				},
+			},
+			Description: "Current weather",
+			MimeType:    "application/json",
+			OutputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"temperature": map[string]interface{}{"type": "number"},
+				},
+			},
+			OutputExample: map[string]interface{}{"temperature": 21.5},
+		},
+	}
+
+	mockClient := &mockFacilitatorClient{
+		supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds: []x402.SupportedKind{
+					{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+				},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(ctx)
+
+	adapter := &mockHTTPAdapter{
+		method: "GET",
+		path:   "/weather",
+		url:    "http://example.com/weather",
+		accept: "application/json",
+	}
+
+	result := server.ProcessHTTPRequest(ctx, HTTPRequestContext{Adapter: adapter, Path: "/weather", Method: "GET"}, nil)
+	if result.Response == nil || result.Response.Status != 402 {
+		t.Fatalf("Expected 402 response, got %+v", result.Response)
+	}
+
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	if required.Resource == nil {
+		t.Fatal("Expected resource info")
+	}
+	if required.Resource.OutputSchema["type"] != "object" {
+		t.Errorf("Expected output schema in resource info, got %v", required.Resource.OutputSchema)
+	}
+	example, ok := required.Resource.OutputExample.(map[string]interface{})
+	if !ok || example["temperature"] != 21.5 {
+		t.Errorf("Expected output example in resource info, got %v", required.Resource.OutputExample)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/networks"
+)
+
+// ============================================================================
+// Client-Side Settlement Verification
+// ============================================================================
+
+// SettlementConfirmer confirms a settlement transaction on-chain, e.g.
+// evm.NewReceiptConfirmer for EVM networks
+type SettlementConfirmer interface {
+	ConfirmSettlement(ctx context.Context, response x402.SettleResponse) error
+}
+
+// SettlementConfirmerFunc adapts a function to SettlementConfirmer
+type SettlementConfirmerFunc func(ctx context.Context, response x402.SettleResponse) error
+
+// ConfirmSettlement implements SettlementConfirmer
+func (f SettlementConfirmerFunc) ConfirmSettlement(ctx context.Context, response x402.SettleResponse) error {
+	return f(ctx, response)
+}
+
+// PaymentResponseCheck configures VerifyPaymentResponse
+type PaymentResponseCheck struct {
+	// Payer is our address; the response must name it as the payer (optional)
+	Payer string
+
+	// Network is the network we paid on (optional)
+	Network x402.Network
+
+	// Confirmer confirms the transaction on-chain (optional)
+	Confirmer SettlementConfirmer
+}
+
+var (
+	evmTransactionHash = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+	svmSignature       = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{64,88}$`)
+)
+
+// VerifyPaymentResponse checks that a settlement response reports a plausible
+// settlement for our payment: success, a transaction in the network's format,
+// and (when configured) our payer address and network. With a Confirmer, the
+// transaction is also confirmed on-chain. Use it to detect servers that claim
+// settlement without performing it.
+func VerifyPaymentResponse(ctx context.Context, response x402.SettleResponse, check PaymentResponseCheck) error {
+	if !response.Success {
+		reason := response.ErrorReason
+		if reason == "" {
+			reason = "unknown reason"
+		}
+		return fmt.Errorf("settlement was not successful: %s", reason)
+	}
+	if response.Network == "" {
+		return fmt.Errorf("settlement response has no network")
+	}
+	if check.Network != "" && response.Network != check.Network {
+		return fmt.Errorf("settlement network %s does not match %s", response.Network, check.Network)
+	}
+
+	family := response.Network.Family()
+	switch family {
+	case networks.FamilyEVM:
+		if !evmTransactionHash.MatchString(response.Transaction) {
+			return fmt.Errorf("invalid EVM transaction hash: %q", response.Transaction)
+		}
+	case networks.FamilySVM:
+		if !svmSignature.MatchString(response.Transaction) {
+			return fmt.Errorf("invalid Solana transaction signature: %q", response.Transaction)
+		}
+	default:
+		if response.Transaction == "" {
+			return fmt.Errorf("settlement response has no transaction")
+		}
+	}
+
+	if check.Payer != "" {
+		payerMatches := response.Payer == check.Payer
+		if family == networks.FamilyEVM {
+			// EVM addresses are case-insensitive (checksum casing varies)
+			payerMatches = strings.EqualFold(response.Payer, check.Payer)
+		}
+		if !payerMatches {
+			return fmt.Errorf("settlement payer %q does not match %q", response.Payer, check.Payer)
+		}
+	}
+
+	if check.Confirmer != nil {
+		if err := check.Confirmer.ConfirmSettlement(ctx, response); err != nil {
+			return fmt.Errorf("settlement not confirmed on-chain: %w", err)
+		}
+	}
+	return nil
+}
+
+// VerifyPaymentSettlement decodes the PAYMENT-RESPONSE (or X-PAYMENT-RESPONSE)
+// header and checks it with VerifyPaymentResponse
+func (c *x402HTTPClient) VerifyPaymentSettlement(ctx context.Context, headers map[string]string, check PaymentResponseCheck) (*x402.SettleResponse, error) {
+	response, err := c.GetPaymentSettleResponse(headers)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyPaymentResponse(ctx, *response, check); err != nil {
+		return response, err
+	}
+	return response, nil
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+const testEvmTx = "0x" + "ab12cd34ef56ab12cd34ef56ab12cd34ef56ab12cd34ef56ab12cd34ef56ab12"
+
+func TestVerifyPaymentResponse(t *testing.T) {
+	valid := x402.SettleResponse{Success: true, Transaction: testEvmTx, Network: "eip155:8453", Payer: "0xAbC"}
+
+	tests := []struct {
+		name     string
+		response x402.SettleResponse
+		check    PaymentResponseCheck
+		wantErr  string
+	}{
+		{"valid", valid, PaymentResponseCheck{Payer: "0xabc", Network: "eip155:8453"}, ""},
+		{"failed", x402.SettleResponse{Success: false, ErrorReason: "insufficient_funds"}, PaymentResponseCheck{}, "insufficient_funds"},
+		{"bad evm hash", x402.SettleResponse{Success: true, Transaction: "0x1234", Network: "eip155:8453"}, PaymentResponseCheck{}, "invalid EVM transaction hash"},
+		{"wrong payer", valid, PaymentResponseCheck{Payer: "0xdef"}, "does not match"},
+		{"wrong network", valid, PaymentResponseCheck{Network: "eip155:1"}, "does not match"},
+		{
+			"solana signature",
+			x402.SettleResponse{Success: true, Transaction: strings.Repeat("5", 88), Network: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", Payer: "Payer111"},
+			PaymentResponseCheck{Payer: "Payer111"},
+			"",
+		},
+		{
+			"solana payer is case-sensitive",
+			x402.SettleResponse{Success: true, Transaction: strings.Repeat("5", 88), Network: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", Payer: "Payer111"},
+			PaymentResponseCheck{Payer: "payer111"},
+			"does not match",
+		},
+		{"bad solana signature", x402.SettleResponse{Success: true, Transaction: "0OIl", Network: "solana:devnet"}, PaymentResponseCheck{}, "invalid Solana transaction signature"},
+		{"unknown network without tx", x402.SettleResponse{Success: true, Network: "cosmos:hub"}, PaymentResponseCheck{}, "no transaction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyPaymentResponse(context.Background(), tt.response, tt.check)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPaymentSettlementConfirmsOnChain(t *testing.T) {
+	client := Newx402HTTPClient(x402.Newx402Client())
+	header, _ := encodePaymentResponseHeader(x402.SettleResponse{Success: true, Transaction: testEvmTx, Network: "eip155:8453", Payer: "0xabc"})
+	headers := map[string]string{"Payment-Response": header}
+
+	var confirmed string
+	confirmer := SettlementConfirmerFunc(func(ctx context.Context, response x402.SettleResponse) error {
+		confirmed = response.Transaction
+		return nil
+	})
+	response, err := client.VerifyPaymentSettlement(context.Background(), headers, PaymentResponseCheck{Payer: "0xabc", Confirmer: confirmer})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != testEvmTx || confirmed != testEvmTx {
+		t.Errorf("Expected transaction to be confirmed, got response %+v confirmed %q", response, confirmed)
+	}
+
+	failing := SettlementConfirmerFunc(func(ctx context.Context, response x402.SettleResponse) error {
+		return errors.New("transaction not found")
+	})
+	if _, err := client.VerifyPaymentSettlement(context.Background(), headers, PaymentResponseCheck{Confirmer: failing}); err == nil || !strings.Contains(err.Error(), "not confirmed on-chain") {
+		t.Errorf("Expected on-chain confirmation failure, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Pending Settlement Status
+// ============================================================================
+
+// SettlementStatusSource reports the status of settlements that were pending
+// when settle returned. x402Facilitator implements it.
+type SettlementStatusSource interface {
+	SettlementStatus(network x402.Network, transaction string) (x402.SettlementStatus, bool)
+}
+
+// SettlementStatusHandler serves the status of pending settlements as JSON
+// for GET requests with network and transaction query parameters, e.g. at
+// the facilitator's /settle/status endpoint
+func SettlementStatusHandler(source SettlementStatusSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		network := r.URL.Query().Get("network")
+		transaction := r.URL.Query().Get("transaction")
+		if network == "" || transaction == "" {
+			http.Error(w, "network and transaction are required", http.StatusBadRequest)
+			return
+		}
+
+		status, ok := source.SettlementStatus(x402.Network(network), transaction)
+		if !ok {
+			http.Error(w, "unknown settlement", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// SettlementStatus fetches the status of a pending settlement from its
+// StatusURL, or from the facilitator's /settle/status endpoint when it has
+// none
+func (c *HTTPFacilitatorClient) SettlementStatus(ctx context.Context, response x402.SettleResponse) (*x402.SettlementStatus, error) {
+	endpoint := response.StatusURL
+	if endpoint == "" {
+		query := url.Values{"network": {string(response.Network)}, "transaction": {response.Transaction}}
+		endpoint = c.url + "/settle/status?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create settlement status request: %w", err)
+	}
+
+	// Add auth headers if available
+	if c.authProvider != nil {
+		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth headers: %w", err)
+		}
+		for k, v := range authHeaders.Settle {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("settlement status request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("facilitator settlement status failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var status x402.SettlementStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settlement status: %w", err)
+	}
+	return &status, nil
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+type statusSourceFunc func(network x402.Network, transaction string) (x402.SettlementStatus, bool)
+
+func (f statusSourceFunc) SettlementStatus(network x402.Network, transaction string) (x402.SettlementStatus, bool) {
+	return f(network, transaction)
+}
+
+func TestSettlementStatusHandler(t *testing.T) {
+	source := statusSourceFunc(func(network x402.Network, transaction string) (x402.SettlementStatus, bool) {
+		if network != "eip155:8453" || transaction != "0xabc" {
+			return x402.SettlementStatus{}, false
+		}
+		return x402.SettlementStatus{
+			Status:      x402.SettlementStatusConfirmed,
+			Transaction: transaction,
+			Network:     network,
+			Result:      &x402.SettleResponse{Success: true, Transaction: transaction, Network: network},
+		}, true
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/settle/status", SettlementStatusHandler(source))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+	ctx := context.Background()
+
+	// Without a status URL the facilitator's endpoint is used
+	status, err := client.SettlementStatus(ctx, x402.SettleResponse{Transaction: "0xabc", Network: "eip155:8453", Pending: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.Status != x402.SettlementStatusConfirmed || status.Result == nil || !status.Result.Success {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	if _, err := client.SettlementStatus(ctx, x402.SettleResponse{StatusURL: server.URL + "/settle/status?network=eip155:8453&transaction=0xdef"}); err == nil {
+		t.Error("Expected an error for an unknown settlement")
+	}
+
+	resp, err := http.Get(server.URL + "/settle/status?network=eip155:8453")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a transaction, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,449 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Server-Sent Events Metering
+// ============================================================================
+
+const (
+	// SSEPaymentRequiredEvent names the event a metered stream is closed with
+	// once its payment is used up. Its data is the encoded PaymentRequired, as
+	// in the PAYMENT-REQUIRED header.
+	SSEPaymentRequiredEvent = "payment-required"
+
+	// SSESessionHeader carries a metered stream's session. Paid stream
+	// responses set it, and reconnects carrying it (or the SSESessionParam
+	// query parameter) resume what the payment has left without paying again.
+	SSESessionHeader = "X-402-SSE-Session"
+
+	// SSESessionParam is the query parameter alternative to SSESessionHeader,
+	// for EventSource clients that cannot set headers
+	SSESessionParam = "x402-sse-session"
+
+	// sseSessionTTL is how long a session metered only by events is kept
+	sseSessionTTL = time.Hour
+)
+
+// ErrSSEPaymentRequired is returned by SSEStream.Send once the stream's
+// payment is used up and the stream has been closed
+var ErrSSEPaymentRequired = errors.New("x402: stream payment used up")
+
+// SSEMeteringConfig sets what one payment buys on a metered stream. When both
+// are set, the stream closes at whichever runs out first.
+type SSEMeteringConfig struct {
+	// Window is how long the stream may run after payment, across reconnects
+	Window time.Duration
+
+	// Events is how many events the stream may send, across reconnects
+	Events int
+}
+
+// SSEEvent is a server-sent event. Multi-line data is sent as several data lines.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// SSEHandlerFunc writes a metered stream's events. It should return once the
+// stream's context is done.
+type SSEHandlerFunc func(stream *SSEStream, r *http.Request)
+
+// sseSession is what a metered stream's payment has left
+type sseSession struct {
+	payer      string
+	path       string
+	eventsLeft int       // -1 when events are not metered
+	until      time.Time // zero when time is not metered
+	expires    time.Time
+}
+
+// exhausted reports whether nothing is left
+func (s *sseSession) exhausted(now time.Time) bool {
+	return s.eventsLeft == 0 || (!s.until.IsZero() && !now.Before(s.until))
+}
+
+// MeterSSE serves a Server-Sent Events stream whose payment buys a time window
+// or a number of events (config). The route is priced by the server's routes;
+// payments are settled before the stream starts. Once the payment is used up,
+// the stream is sent an SSEPaymentRequiredEvent carrying the route's challenge
+// and closed. Clients pay again by reconnecting with a new payment.
+//
+//	routes := x402http.RoutesConfig{"GET /ticks": {Accepts: options}}
+//	mux.Handle("/ticks", server.MeterSSE(x402http.SSEMeteringConfig{Window: time.Minute}, func(stream *x402http.SSEStream, r *http.Request) {
+//	    for tick := range ticks(stream.Context()) {
+//	        if stream.Send(x402http.SSEEvent{Data: tick}) != nil {
+//	            return
+//	        }
+//	    }
+//	}))
+//
+// Streams must not be served behind the payment middleware, which buffers
+// responses until settlement. Free routes stream unmetered.
+func (s *x402HTTPResourceServer) MeterSSE(config SSEMeteringConfig, handler SSEHandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if session, ok := s.resumeSSESession(sseSessionFromRequest(r), r.URL.Path); ok {
+			s.streamSSE(w, r, session, handler)
+			return
+		}
+
+		reqCtx := s.sseRequestContext(r, false)
+		if !s.RequiresPayment(reqCtx) {
+			s.streamSSE(w, r, nil, handler)
+			return
+		}
+
+		ctx := r.Context()
+		result := s.ProcessHTTPRequest(ctx, reqCtx, nil)
+		switch result.Type {
+		case ResultPaymentError:
+			writeResponseInstructions(w, result.Response)
+			return
+		case ResultNoPaymentRequired:
+			s.streamSSE(w, r, nil, handler)
+			return
+		}
+
+		settlement := s.ProcessSettlement(
+			x402.ContextWithFacilitator(ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+			*result.PaymentPayload,
+			*result.PaymentRequirements,
+		)
+		if !settlement.Success {
+			reason := settlement.ErrorReason
+			if reason == "" {
+				reason = "Settlement failed"
+			}
+			writeResponseInstructions(w, &HTTPResponseInstructions{
+				Status: http.StatusPaymentRequired,
+				Body:   map[string]string{"error": "Settlement failed", "details": reason},
+			})
+			return
+		}
+
+		id, session, err := s.startSSESession(config, settlement.Payer, r.URL.Path)
+		if err != nil {
+			http.Error(w, "failed to start stream session", http.StatusInternalServerError)
+			return
+		}
+		for key, value := range settlement.Headers {
+			w.Header().Set(key, value)
+		}
+		w.Header().Set(SSESessionHeader, id)
+		s.streamSSE(w, r, session, handler)
+	})
+}
+
+// SSEAllowance reports what a metered stream session has left: events (-1
+// when events are not metered) and the end of its window (zero when time is
+// not metered). It reports false for unknown or expired sessions.
+func (s *x402HTTPResourceServer) SSEAllowance(session string) (events int, until time.Time, ok bool) {
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	stored, ok := s.sseSessions[session]
+	if !ok || time.Now().After(stored.expires) {
+		return 0, time.Time{}, false
+	}
+	return stored.eventsLeft, stored.until, true
+}
+
+// startSSESession records a paid session, pruning expired ones
+func (s *x402HTTPResourceServer) startSSESession(config SSEMeteringConfig, payer, path string) (string, *sseSession, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(raw)
+
+	now := time.Now()
+	session := &sseSession{payer: payer, path: path, eventsLeft: -1, expires: now.Add(sseSessionTTL)}
+	if config.Events > 0 {
+		session.eventsLeft = config.Events
+	}
+	if config.Window > 0 {
+		session.until = now.Add(config.Window)
+		session.expires = session.until
+	}
+
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	if s.sseSessions == nil {
+		s.sseSessions = make(map[string]*sseSession)
+	}
+	for key, stored := range s.sseSessions {
+		if now.After(stored.expires) {
+			delete(s.sseSessions, key)
+		}
+	}
+	s.sseSessions[id] = session
+	return id, session, nil
+}
+
+// resumeSSESession returns a session for path that has something left
+func (s *x402HTTPResourceServer) resumeSSESession(id, path string) (*sseSession, bool) {
+	if id == "" {
+		return nil, false
+	}
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	session, ok := s.sseSessions[id]
+	if !ok || session.path != path || session.exhausted(time.Now()) {
+		return nil, false
+	}
+	return session, true
+}
+
+// reserveSSEEvent counts an event against the session. It reports whether
+// the event may be sent and whether it is the last one.
+func (s *x402HTTPResourceServer) reserveSSEEvent(session *sseSession) (bool, bool) {
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	now := time.Now()
+	if session.exhausted(now) {
+		return false, true
+	}
+	if session.eventsLeft > 0 {
+		session.eventsLeft--
+	}
+	return true, session.exhausted(now)
+}
+
+// streamSSE runs handler on the event stream, closing it when the session
+// (nil for unmetered streams) runs out
+func (s *x402HTTPResourceServer) streamSSE(w http.ResponseWriter, r *http.Request, session *sseSession, handler SSEHandlerFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+	_ = controller.Flush()
+
+	stream := &SSEStream{ctx: ctx, cancel: cancel, w: w, controller: controller, server: s, request: r, session: session}
+	if session != nil {
+		stream.payer = session.payer
+		if !session.until.IsZero() {
+			timer := time.AfterFunc(time.Until(session.until), func() { stream.exhaust("Stream window elapsed") })
+			defer timer.Stop()
+		}
+	}
+
+	handler(stream, r.WithContext(ctx))
+
+	stream.mu.Lock()
+	stream.closed = true
+	stream.mu.Unlock()
+}
+
+// SSEStream is a metered event stream. Send is safe for concurrent use.
+type SSEStream struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	server     *x402HTTPResourceServer
+	request    *http.Request
+	session    *sseSession
+	payer      string
+
+	mu        sync.Mutex
+	closed    bool
+	exhausted bool
+}
+
+// Context is done when the client disconnects or the payment is used up
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// Payer returns who paid for the stream ("" for free routes)
+func (s *SSEStream) Payer() string {
+	return s.payer
+}
+
+// Send writes an event. It returns ErrSSEPaymentRequired once the payment is
+// used up; the event that uses it up is sent, followed by the
+// SSEPaymentRequiredEvent.
+func (s *SSEStream) Send(event SSEEvent) error {
+	last := false
+	if s.session != nil {
+		var ok bool
+		if ok, last = s.server.reserveSSEEvent(s.session); !ok {
+			s.exhaust("Stream payment used up")
+			return ErrSSEPaymentRequired
+		}
+	}
+
+	s.mu.Lock()
+	if s.exhausted {
+		s.mu.Unlock()
+		return ErrSSEPaymentRequired
+	}
+	if s.closed || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return context.Canceled
+	}
+	err := s.write(event)
+	s.mu.Unlock()
+	if err != nil {
+		s.cancel()
+		return err
+	}
+
+	if last {
+		s.exhaust("Stream events used up")
+	}
+	return nil
+}
+
+// exhaust sends the payment-required event and closes the stream
+func (s *SSEStream) exhaust(reason string) {
+	challenge := s.server.sseChallenge(s.request, reason)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.exhausted {
+		return
+	}
+	s.exhausted = true
+	_ = s.write(SSEEvent{Event: SSEPaymentRequiredEvent, Data: challenge})
+	s.cancel()
+}
+
+// write writes and flushes an event; callers hold mu
+func (s *SSEStream) write(event SSEEvent) error {
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event.Event)
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	if _, err := io.WriteString(s.w, b.String()); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// sseChallenge returns the route's encoded PaymentRequired, with reason as its
+// error, for the payment-required event
+func (s *x402HTTPResourceServer) sseChallenge(r *http.Request, reason string) string {
+	result := s.ProcessHTTPRequest(context.WithoutCancel(r.Context()), s.sseRequestContext(r, true), nil)
+	if result.Type != ResultPaymentError || result.Response == nil {
+		return ""
+	}
+	encoded, exists, err := readChunkedHeader(result.Response.Headers, s.HeaderNames().PaymentRequired)
+	if err != nil || !exists {
+		return ""
+	}
+	required, err := decodePaymentRequiredHeader(encoded, result.Response.Headers[PaymentEncodingHeader])
+	if err != nil {
+		return encoded
+	}
+	required.Error = reason
+	if reencoded, err := encodePaymentRequiredHeader(required); err == nil {
+		return reencoded
+	}
+	return encoded
+}
+
+// sseSessionFromRequest returns the session a reconnect carries
+func sseSessionFromRequest(r *http.Request) string {
+	if session := r.Header.Get(SSESessionHeader); session != "" {
+		return session
+	}
+	return r.URL.Query().Get(SSESessionParam)
+}
+
+// sseRequestContext describes a stream request to the server, without its
+// payment when unpaid is set
+func (s *x402HTTPResourceServer) sseRequestContext(r *http.Request, unpaid bool) HTTPRequestContext {
+	return HTTPRequestContext{
+		Adapter: &sseRequestAdapter{r: r, unpaid: unpaid, signatureHeader: s.HeaderNames().PaymentSignature},
+		Host:    r.Host,
+		Path:    r.URL.Path,
+		Method:  r.Method,
+	}
+}
+
+// sseRequestAdapter adapts a stream request for the server
+type sseRequestAdapter struct {
+	r               *http.Request
+	unpaid          bool
+	signatureHeader string
+}
+
+func (a *sseRequestAdapter) GetHeader(name string) string {
+	if a.unpaid && (isPaymentHeader(name) || strings.EqualFold(name, a.signatureHeader)) {
+		return ""
+	}
+	return a.r.Header.Get(name)
+}
+
+func (a *sseRequestAdapter) GetMethod() string       { return a.r.Method }
+func (a *sseRequestAdapter) GetPath() string         { return a.r.URL.Path }
+func (a *sseRequestAdapter) GetAcceptHeader() string { return a.r.Header.Get("Accept") }
+func (a *sseRequestAdapter) GetUserAgent() string    { return a.r.UserAgent() }
+func (a *sseRequestAdapter) GetClientIP() string     { return RemoteIP(a.r.RemoteAddr) }
+
+func (a *sseRequestAdapter) GetURL() string {
+	scheme := "http"
+	if a.r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + a.r.Host + a.r.URL.Path
+}
+
+// isPaymentHeader reports whether name carries a payment: any signature or
+// X-PAYMENT header, or its encoding
+func isPaymentHeader(name string) bool {
+	switch strings.ToUpper(name) {
+	case DefaultHeaderNames.PaymentSignature, LegacyHeaderNames.PaymentSignature, strings.ToUpper(PaymentEncodingHeader):
+		return true
+	}
+	return false
+}
+
+// writeResponseInstructions writes a payment error response
+func writeResponseInstructions(w http.ResponseWriter, response *HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case nil:
+		w.WriteHeader(response.Status)
+	case string:
+		if response.IsHTML {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.WriteHeader(response.Status)
+		_, _ = io.WriteString(w, body)
+	case []byte:
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(body)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.Status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
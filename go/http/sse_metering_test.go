@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// newSSEServer serves handler metered by config at /ticks, charged $1
+func newSSEServer(t *testing.T, config SSEMeteringConfig, handler SSEHandlerFunc) *httptest.Server {
+	t.Helper()
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /ticks": {Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}}},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	ts := httptest.NewServer(server.MeterSSE(config, handler))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// openSSE requests the stream with the given headers
+func openSSE(t *testing.T, url string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url+"/ticks", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// paySSE pays the stream's challenge and opens it
+func paySSE(t *testing.T, url string) *http.Response {
+	t.Helper()
+	unpaid := openSSE(t, url, nil)
+	if unpaid.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("Expected 402 before payment, got %d", unpaid.StatusCode)
+	}
+	required, err := decodePaymentRequiredHeader(unpaid.Header.Get(PaymentRequiredHeader), "")
+	if err != nil {
+		t.Fatalf("Failed to decode challenge: %v", err)
+	}
+	payloadJSON, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"sig": "test"}, Accepted: required.Accepts[0]})
+	resp := openSSE(t, url, map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadJSON)})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected the paid stream, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("PAYMENT-RESPONSE") == "" || resp.Header.Get(SSESessionHeader) == "" {
+		t.Errorf("Expected settlement and session headers, got %v", resp.Header)
+	}
+	return resp
+}
+
+func TestMeterSSEEvents(t *testing.T) {
+	ts := newSSEServer(t, SSEMeteringConfig{Events: 2}, func(stream *SSEStream, r *http.Request) {
+		for i := 1; i <= 3; i++ {
+			if err := stream.Send(SSEEvent{Data: fmt.Sprintf("%s %d", stream.Payer(), i)}); err != nil {
+				return
+			}
+		}
+		<-stream.Context().Done()
+	})
+
+	resp := paySSE(t, ts.URL)
+	body, _ := io.ReadAll(resp.Body)
+	stream := string(body)
+	if !strings.Contains(stream, "data: 0xmock 1\n\n") || !strings.Contains(stream, "data: 0xmock 2\n\n") || strings.Contains(stream, "0xmock 3") {
+		t.Errorf("Expected exactly two paid events, got %q", stream)
+	}
+	if !strings.Contains(stream, "event: "+SSEPaymentRequiredEvent+"\ndata: ") {
+		t.Fatalf("Expected the stream to close with a payment-required event, got %q", stream)
+	}
+	data := strings.TrimSpace(stream[strings.Index(stream, "event: "+SSEPaymentRequiredEvent):])
+	data = strings.TrimPrefix(strings.SplitN(data, "\n", 2)[1], "data: ")
+	if required, err := decodePaymentRequiredHeader(data, ""); err != nil || len(required.Accepts) != 1 || required.Error != "Stream events used up" {
+		t.Errorf("Expected the route's challenge, got %+v, %v", required, err)
+	}
+
+	session := resp.Header.Get(SSESessionHeader)
+	if resumed := openSSE(t, ts.URL, map[string]string{SSESessionHeader: session}); resumed.StatusCode != http.StatusPaymentRequired {
+		t.Errorf("Expected a used-up session to need payment, got %d", resumed.StatusCode)
+	}
+}
+
+func TestMeterSSEWindow(t *testing.T) {
+	ts := newSSEServer(t, SSEMeteringConfig{Window: 200 * time.Millisecond}, func(stream *SSEStream, r *http.Request) {
+		_ = stream.Send(SSEEvent{ID: "1", Data: stream.Payer()})
+		if r.URL.Query().Get("hold") == "" {
+			return
+		}
+		<-stream.Context().Done()
+	})
+
+	resp := paySSE(t, ts.URL)
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "id: 1\ndata: 0xmock\n\n" {
+		t.Errorf("Unexpected stream %q", body)
+	}
+
+	// Reconnects within the window resume without paying, until it elapses
+	session := resp.Header.Get(SSESessionHeader)
+	started := time.Now()
+	resumed, err := (&http.Client{}).Get(ts.URL + "/ticks?hold=1&" + SSESessionParam + "=" + session)
+	if err != nil || resumed.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the session to resume, got %v, %v", resumed, err)
+	}
+	defer resumed.Body.Close()
+	body, _ = io.ReadAll(resumed.Body)
+	if !strings.Contains(string(body), "event: "+SSEPaymentRequiredEvent) {
+		t.Errorf("Expected the window to close the stream, got %q", body)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected the stream to close with the window, took %v", elapsed)
+	}
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Store
+// ============================================================================
+
+// Store persists server-side state such as free-tier usage. The default is an
+// in-process MemoryStore; use a shared implementation (e.g. backed by Redis)
+// when running several server instances. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value at key and whether it exists
+	Get(ctx context.Context, key string) (string, bool, error)
+
+	// Set stores a value at key. A zero ttl means the value never expires.
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+
+	// Increment atomically adds delta to the integer counter at key and returns
+	// the new value. A missing key starts at zero and expires after ttl (zero
+	// means never); incrementing an existing key keeps its expiry.
+	Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Delete removes the value at key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryStoreSweepInterval is how many writes pass between sweeps of expired entries
+const memoryStoreSweepInterval = 1024
+
+// MemoryStore is an in-process Store
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryStoreEntry
+	writes  int
+	now     func() time.Time
+}
+
+type memoryStoreEntry struct {
+	value     string
+	expiresAt time.Time // zero means no expiry
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryStoreEntry),
+		now:     time.Now,
+	}
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.liveEntryLocked(key)
+	return entry.value, ok, nil
+}
+
+// Set implements Store
+func (m *MemoryStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryStoreEntry{value: value, expiresAt: m.expiryLocked(ttl)}
+	m.afterWriteLocked()
+	return nil
+}
+
+// Increment implements Store
+func (m *MemoryStore) Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.liveEntryLocked(key)
+	var current int64
+	if ok {
+		parsed, err := strconv.ParseInt(entry.value, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		current = parsed
+	} else {
+		entry.expiresAt = m.expiryLocked(ttl)
+	}
+
+	current += delta
+	entry.value = strconv.FormatInt(current, 10)
+	m.entries[key] = entry
+	m.afterWriteLocked()
+	return current, nil
+}
+
+// Delete implements Store
+func (m *MemoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// liveEntryLocked returns the entry at key, dropping it if expired
+func (m *MemoryStore) liveEntryLocked(key string) (memoryStoreEntry, bool) {
+	entry, ok := m.entries[key]
+	if !ok {
+		return memoryStoreEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		delete(m.entries, key)
+		return memoryStoreEntry{}, false
+	}
+	return entry, true
+}
+
+func (m *MemoryStore) expiryLocked(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
+
+// afterWriteLocked periodically sweeps expired entries so the map stays bounded
+func (m *MemoryStore) afterWriteLocked() {
+	m.writes++
+	if m.writes < memoryStoreSweepInterval {
+		return
+	}
+	m.writes = 0
+
+	now := m.now()
+	for key, entry := range m.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// SetStore sets the store used for server-side state such as free-tier usage
+// (default: an in-process MemoryStore)
+func (s *x402HTTPResourceServer) SetStore(store Store) *x402HTTPResourceServer {
+	s.store = store
+	return s
+}
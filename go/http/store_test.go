@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreIncrement(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Increment(ctx, "counter", 1, time.Minute)
+		if err != nil || got != want {
+			t.Fatalf("Expected %d, got %d (err %v)", want, got, err)
+		}
+	}
+
+	// Incrementing keeps the original expiry
+	now = now.Add(61 * time.Second)
+	if got, _ := store.Increment(ctx, "counter", 1, time.Minute); got != 1 {
+		t.Errorf("Expected counter to restart after expiry, got %d", got)
+	}
+
+	_ = store.Set(ctx, "text", "hello", 0)
+	if _, err := store.Increment(ctx, "text", 1, 0); err == nil {
+		t.Error("Expected error incrementing a non-integer value")
+	}
+}
+
+func TestMemoryStoreGetSet(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, ok, _ := store.Get(ctx, "missing"); ok {
+		t.Error("Expected missing key")
+	}
+
+	_ = store.Set(ctx, "a", "1", time.Minute)
+	_ = store.Set(ctx, "b", "2", 0)
+	if value, ok, _ := store.Get(ctx, "a"); !ok || value != "1" {
+		t.Errorf("Expected a=1, got %q (exists %v)", value, ok)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok, _ := store.Get(ctx, "a"); ok {
+		t.Error("Expected a to expire")
+	}
+	if value, ok, _ := store.Get(ctx, "b"); !ok || value != "2" {
+		t.Error("Expected b to never expire")
+	}
+}
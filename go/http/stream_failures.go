@@ -0,0 +1,237 @@
+package http
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Streamed Response Failures
+// ============================================================================
+
+// Remedies a stream failure policy can apply
+const (
+	StreamRemedyRefund = "refund" // Funds returned to the payer
+	StreamRemedyCredit = "credit" // Credit issued for future requests
+	StreamRemedyNone   = "none"   // Nothing owed, e.g. the failure was the client's
+)
+
+// StreamFailure describes a paid response that failed after streaming part of
+// its body, e.g. because the upstream errored. Streamed responses are settled
+// before their body is sent, so the payment has already been made.
+type StreamFailure struct {
+	Payer         string
+	Requirements  types.PaymentRequirements
+	Transaction   string // Settlement transaction of the payment
+	BytesWritten  int64
+	ExpectedBytes int64 // Full size of the response, 0 when unknown
+	Err           error
+}
+
+// UndeliveredAmount returns the share of the payment for the bytes not
+// delivered, in atomic units. It is the full amount when nothing was written
+// or the expected size is unknown.
+func (f StreamFailure) UndeliveredAmount() string {
+	paid, ok := new(big.Int).SetString(f.Requirements.Amount, 10)
+	if !ok {
+		return "0"
+	}
+	if f.BytesWritten <= 0 || f.ExpectedBytes <= 0 {
+		return paid.String()
+	}
+	if f.BytesWritten >= f.ExpectedBytes {
+		return "0"
+	}
+	undelivered := new(big.Int).Mul(paid, big.NewInt(f.ExpectedBytes-f.BytesWritten))
+	return undelivered.Quo(undelivered, big.NewInt(f.ExpectedBytes)).String()
+}
+
+// StreamRemedy is what a stream failure policy did for the payer
+type StreamRemedy struct {
+	Action    string `json:"action"` // StreamRemedyRefund, StreamRemedyCredit, or StreamRemedyNone
+	Amount    string `json:"amount,omitempty"`
+	Reference string `json:"reference,omitempty"` // Refund transaction or credit ID
+}
+
+// StreamFailurePolicy decides how to make up for a failed stream and carries
+// it out, e.g. by sending a refund or issuing credit
+type StreamFailurePolicy func(ctx context.Context, failure StreamFailure) (*StreamRemedy, error)
+
+// ProratedRemedy returns a policy that refunds or credits (action) the
+// undelivered share of the payment with issue, which returns a reference such
+// as the refund transaction. Nothing is issued when nothing is owed.
+func ProratedRemedy(action string, issue func(ctx context.Context, failure StreamFailure, amount string) (string, error)) StreamFailurePolicy {
+	return func(ctx context.Context, failure StreamFailure) (*StreamRemedy, error) {
+		amount := failure.UndeliveredAmount()
+		if amount == "0" {
+			return &StreamRemedy{Action: StreamRemedyNone}, nil
+		}
+		reference, err := issue(ctx, failure, amount)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamRemedy{Action: action, Amount: amount, Reference: reference}, nil
+	}
+}
+
+// StreamAuditRecord records a stream failure and its remedy
+type StreamAuditRecord struct {
+	Payer         string        `json:"payer,omitempty"`
+	Network       string        `json:"network"`
+	Asset         string        `json:"asset"`
+	PayTo         string        `json:"payTo"`
+	Paid          string        `json:"paid"` // Atomic units
+	Transaction   string        `json:"transaction,omitempty"`
+	BytesWritten  int64         `json:"bytesWritten"`
+	ExpectedBytes int64         `json:"expectedBytes,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Remedy        *StreamRemedy `json:"remedy,omitempty"`
+	RemedyError   string        `json:"remedyError,omitempty"` // Why the policy failed
+	FailedAt      time.Time     `json:"failedAt"`
+}
+
+// StreamAuditStore persists stream failure records, e.g. to an audit database
+type StreamAuditStore interface {
+	Append(ctx context.Context, record StreamAuditRecord) error
+}
+
+// MemoryStreamAuditStore keeps stream failure records in memory
+type MemoryStreamAuditStore struct {
+	mu      sync.Mutex
+	records []StreamAuditRecord
+}
+
+// NewMemoryStreamAuditStore creates an empty in-memory audit store
+func NewMemoryStreamAuditStore() *MemoryStreamAuditStore {
+	return &MemoryStreamAuditStore{}
+}
+
+// Append implements StreamAuditStore
+func (s *MemoryStreamAuditStore) Append(ctx context.Context, record StreamAuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns all records, oldest first
+func (s *MemoryStreamAuditStore) Records() []StreamAuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StreamAuditRecord(nil), s.records...)
+}
+
+// OnStreamFailure sets the policy applied to paid streams that fail midway,
+// recording every failure and remedy in audit (default: in-memory store)
+func (s *x402HTTPResourceServer) OnStreamFailure(policy StreamFailurePolicy, audit StreamAuditStore) *x402HTTPResourceServer {
+	if audit == nil {
+		audit = NewMemoryStreamAuditStore()
+	}
+	s.streamFailurePolicy = policy
+	s.streamAudit = audit
+	return s
+}
+
+// HandleStreamFailure applies the stream failure policy and records the
+// outcome in the audit store. It returns the remedy, or nil when no policy
+// is set.
+func (s *x402HTTPResourceServer) HandleStreamFailure(ctx context.Context, failure StreamFailure) (*StreamRemedy, error) {
+	if s.streamFailurePolicy == nil {
+		return nil, nil
+	}
+
+	record := StreamAuditRecord{
+		Payer:         failure.Payer,
+		Network:       failure.Requirements.Network,
+		Asset:         failure.Requirements.Asset,
+		PayTo:         failure.Requirements.PayTo,
+		Paid:          failure.Requirements.Amount,
+		Transaction:   failure.Transaction,
+		BytesWritten:  failure.BytesWritten,
+		ExpectedBytes: failure.ExpectedBytes,
+		FailedAt:      time.Now(),
+	}
+	if failure.Err != nil {
+		record.Error = failure.Err.Error()
+	}
+
+	remedy, err := s.streamFailurePolicy(ctx, failure)
+	record.Remedy = remedy
+	if err != nil {
+		record.RemedyError = err.Error()
+	}
+	if auditErr := s.streamAudit.Append(ctx, record); auditErr != nil && err == nil {
+		err = auditErr
+	}
+	return remedy, err
+}
+
+// PaidStream writes the body of a settled, streamed response, counting the
+// bytes delivered so a failure midway can be made up for.
+//
+//	stream := server.NewPaidStream(w, *result.PaymentRequirements, settlement, size)
+//	if _, err := io.Copy(stream, upstream); err != nil {
+//		stream.Fail(ctx, err)
+//	}
+type PaidStream struct {
+	w        http.ResponseWriter
+	server   *x402HTTPResourceServer
+	failure  StreamFailure
+	mu       sync.Mutex
+	reported bool
+}
+
+// NewPaidStream wraps w for a response to a settled payment. expectedBytes is
+// the full size of the response (0 when unknown).
+func (s *x402HTTPResourceServer) NewPaidStream(w http.ResponseWriter, requirements types.PaymentRequirements, settlement *ProcessSettleResult, expectedBytes int64) *PaidStream {
+	failure := StreamFailure{Requirements: requirements, ExpectedBytes: expectedBytes}
+	if settlement != nil {
+		failure.Payer = settlement.Payer
+		failure.Transaction = settlement.Transaction
+	}
+	return &PaidStream{w: w, server: s, failure: failure}
+}
+
+// Write implements io.Writer, counting bytes delivered
+func (p *PaidStream) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.mu.Lock()
+	p.failure.BytesWritten += int64(n)
+	p.mu.Unlock()
+	return n, err
+}
+
+// Flush sends buffered data to the client, if the writer supports it
+func (p *PaidStream) Flush() {
+	if flusher, ok := p.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// BytesWritten returns the number of body bytes delivered
+func (p *PaidStream) BytesWritten() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.failure.BytesWritten
+}
+
+// Fail reports that the stream failed with err, applying the server's stream
+// failure policy. Only the first call has an effect.
+func (p *PaidStream) Fail(ctx context.Context, err error) (*StreamRemedy, error) {
+	p.mu.Lock()
+	if p.reported {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	p.reported = true
+	failure := p.failure
+	failure.Err = err
+	p.mu.Unlock()
+
+	return p.server.HandleStreamFailure(ctx, failure)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestStreamFailureUndeliveredAmount(t *testing.T) {
+	requirements := types.PaymentRequirements{Amount: "1000"}
+	tests := []struct {
+		name             string
+		written, expects int64
+		want             string
+	}{
+		{"nothing delivered", 0, 400, "1000"},
+		{"quarter delivered", 100, 400, "750"},
+		{"fully delivered", 400, 400, "0"},
+		{"unknown size", 100, 0, "1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := StreamFailure{Requirements: requirements, BytesWritten: tt.written, ExpectedBytes: tt.expects}
+			if got := failure.UndeliveredAmount(); got != tt.want {
+				t.Errorf("UndeliveredAmount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaidStreamFailure(t *testing.T) {
+	ctx := context.Background()
+	audit := NewMemoryStreamAuditStore()
+	var refunded string
+	server := Newx402HTTPResourceServer(RoutesConfig{}).OnStreamFailure(
+		ProratedRemedy(StreamRemedyRefund, func(ctx context.Context, failure StreamFailure, amount string) (string, error) {
+			refunded = amount
+			return "0xrefund", nil
+		}),
+		audit,
+	)
+
+	requirements := types.PaymentRequirements{Network: "eip155:8453", Asset: "USDC", PayTo: "0xmerchant", Amount: "1000"}
+	rec := httptest.NewRecorder()
+	stream := server.NewPaidStream(rec, requirements, &ProcessSettleResult{Success: true, Payer: "0xpayer", Transaction: "0xpaid"}, 400)
+
+	upstream := io.MultiReader(strings.NewReader(strings.Repeat("x", 100)), &failingReader{err: errors.New("upstream reset")})
+	_, err := io.Copy(stream, upstream)
+	if err == nil {
+		t.Fatal("Expected the upstream to fail")
+	}
+	remedy, err := stream.Fail(ctx, err)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remedy.Action != StreamRemedyRefund || remedy.Amount != "750" || remedy.Reference != "0xrefund" || refunded != "750" {
+		t.Errorf("Expected a 750 refund, got %+v", remedy)
+	}
+	if again, _ := stream.Fail(ctx, err); again != nil {
+		t.Error("Expected a second failure report to be ignored")
+	}
+
+	records := audit.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Payer != "0xpayer" || record.Transaction != "0xpaid" || record.BytesWritten != 100 || record.Error != "upstream reset" || record.Remedy == nil {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+}
+
+func TestStreamFailureRemedyError(t *testing.T) {
+	audit := NewMemoryStreamAuditStore()
+	server := Newx402HTTPResourceServer(RoutesConfig{}).OnStreamFailure(
+		ProratedRemedy(StreamRemedyCredit, func(ctx context.Context, failure StreamFailure, amount string) (string, error) {
+			return "", errors.New("ledger unavailable")
+		}),
+		audit,
+	)
+
+	_, err := server.HandleStreamFailure(context.Background(), StreamFailure{Requirements: types.PaymentRequirements{Amount: "10"}})
+	if err == nil {
+		t.Fatal("Expected the remedy error")
+	}
+	if records := audit.Records(); len(records) != 1 || records[0].RemedyError != "ledger unavailable" {
+		t.Errorf("Expected the failed remedy to be audited, got %+v", records)
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
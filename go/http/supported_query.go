@@ -0,0 +1,73 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// /supported Query Parameters
+// ============================================================================
+
+// Query parameters accepted by GET /supported
+const (
+	SupportedParamScheme  = "scheme"
+	SupportedParamNetwork = "network"
+	SupportedParamVersion = "x402Version"
+	SupportedParamLimit   = "limit"
+	SupportedParamCursor  = "cursor"
+)
+
+// EncodeSupportedQuery encodes a query as /supported query parameters,
+// omitting unset fields
+func EncodeSupportedQuery(query x402.SupportedQuery) url.Values {
+	values := url.Values{}
+	if query.Scheme != "" {
+		values.Set(SupportedParamScheme, query.Scheme)
+	}
+	if query.Network != "" {
+		values.Set(SupportedParamNetwork, string(query.Network))
+	}
+	if query.X402Version != 0 {
+		values.Set(SupportedParamVersion, strconv.Itoa(query.X402Version))
+	}
+	if query.Limit != 0 {
+		values.Set(SupportedParamLimit, strconv.Itoa(query.Limit))
+	}
+	if query.Cursor != "" {
+		values.Set(SupportedParamCursor, query.Cursor)
+	}
+	return values
+}
+
+// ParseSupportedQuery parses /supported query parameters, for facilitators
+// serving the endpoint:
+//
+//	query, err := x402http.ParseSupportedQuery(r.URL.Query())
+//	if err != nil { /* 400 */ }
+//	supported, err := facilitator.QuerySupported(query)
+func ParseSupportedQuery(values url.Values) (x402.SupportedQuery, error) {
+	query := x402.SupportedQuery{
+		Scheme:  values.Get(SupportedParamScheme),
+		Network: x402.Network(values.Get(SupportedParamNetwork)),
+		Cursor:  values.Get(SupportedParamCursor),
+	}
+	if raw := values.Get(SupportedParamVersion); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil || version < 0 {
+			return x402.SupportedQuery{}, fmt.Errorf("invalid %s: %q", SupportedParamVersion, raw)
+		}
+		query.X402Version = version
+	}
+	if raw := values.Get(SupportedParamLimit); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return x402.SupportedQuery{}, fmt.Errorf("invalid %s: %q", SupportedParamLimit, raw)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
@@ -0,0 +1,298 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Surge Pricing
+// ============================================================================
+
+// SurgeSchedule raises prices during a recurring time of day
+type SurgeSchedule struct {
+	// Days the schedule applies on (default: every day)
+	Days []time.Weekday
+
+	// Start and End are offsets from midnight in the pricer's Location. An End
+	// before Start wraps past midnight (e.g. 22h to 2h).
+	Start time.Duration
+	End   time.Duration
+
+	// Multiplier scales the base price while the schedule applies (e.g. 1.5)
+	Multiplier float64
+}
+
+// SurgeTier raises prices at or above a utilization
+type SurgeTier struct {
+	// Utilization is the load, from 0 to 1, at which the tier starts
+	Utilization float64
+
+	// Multiplier scales the base price at this load
+	Multiplier float64
+}
+
+// LoadFunc reports current utilization, from 0 (idle) to 1 (saturated)
+type LoadFunc func(ctx context.Context) (float64, error)
+
+// SurgePricerConfig configures a SurgePricer
+type SurgePricerConfig struct {
+	// Schedules raise prices at times of day; the highest applying multiplier wins
+	Schedules []SurgeSchedule
+
+	// Location interprets schedule times (default: UTC)
+	Location *time.Location
+
+	// Load samples utilization once per Interval, raising prices by the
+	// highest tier reached. Load errors apply no load surge.
+	Load  LoadFunc
+	Tiers []SurgeTier
+
+	// MaxMultiplier caps the combined schedule and load multiplier (0 = no cap)
+	MaxMultiplier float64
+
+	// Interval is how long a multiplier holds before it is recomputed
+	// (default: one minute)
+	Interval time.Duration
+
+	// QuoteTTL is how long a quoted multiplier is remembered to price payments
+	// made against it (default: 10 minutes). Keep it at least as long as the
+	// routes' MaxTimeoutSeconds.
+	QuoteTTL time.Duration
+
+	// Store remembers quoted multipliers (default: in-memory). Share it across
+	// server instances so a payment quoted by one is priced the same by another.
+	Store Store
+}
+
+// SurgePricer scales route prices by time of day and load. Multipliers hold
+// for an Interval and are remembered, so a payment made against a 402 is
+// verified at the price it was quoted even if the surge has since changed:
+// the quote time comes from the accepted requirements' expiresAt.
+//
+//	surge, _ := x402http.NewSurgePricer(x402http.SurgePricerConfig{
+//	    Schedules: []x402http.SurgeSchedule{{Start: 17 * time.Hour, End: 21 * time.Hour, Multiplier: 1.5}},
+//	    Load:      func(ctx context.Context) (float64, error) { return queue.Utilization(), nil },
+//	    Tiers:     []x402http.SurgeTier{{Utilization: 0.8, Multiplier: 2}},
+//	})
+//	option := x402http.PaymentOption{Scheme: "exact", Network: "eip155:8453", PayTo: payTo, Price: surge.Price("$0.01")}
+type SurgePricer struct {
+	config SurgePricerConfig
+	now    func() time.Time
+}
+
+// surgeKeyPrefix namespaces quoted multipliers in the store
+const surgeKeyPrefix = "x402:surge:"
+
+// NewSurgePricer creates a surge pricer
+func NewSurgePricer(config SurgePricerConfig) (*SurgePricer, error) {
+	if len(config.Schedules) == 0 && config.Load == nil {
+		return nil, errors.New("surge pricing needs schedules or a load signal")
+	}
+	if config.Load != nil && len(config.Tiers) == 0 {
+		return nil, errors.New("surge pricing with a load signal needs tiers")
+	}
+	for _, schedule := range config.Schedules {
+		if schedule.Multiplier <= 0 || schedule.Start < 0 || schedule.End < 0 || schedule.Start >= 24*time.Hour || schedule.End > 24*time.Hour {
+			return nil, fmt.Errorf("invalid surge schedule %+v", schedule)
+		}
+	}
+	for _, tier := range config.Tiers {
+		if tier.Multiplier <= 0 {
+			return nil, fmt.Errorf("invalid surge tier %+v", tier)
+		}
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.QuoteTTL <= 0 {
+		config.QuoteTTL = 10 * time.Minute
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	return &SurgePricer{config: config, now: time.Now}, nil
+}
+
+// Price returns a DynamicPriceFunc scaling base by the current multiplier, or
+// by the multiplier quoted when the request carries a payment. base may be a
+// money string ("$0.01", "0.01"), a number, or an x402.AssetAmount.
+func (p *SurgePricer) Price(base x402.Price) DynamicPriceFunc {
+	return func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+		multiplier, err := p.Multiplier(ctx, p.quotedAt(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return scalePrice(base, multiplier)
+	}
+}
+
+// Multiplier returns the multiplier in effect at a time, computing and
+// remembering it when the time is in the current interval
+func (p *SurgePricer) Multiplier(ctx context.Context, at time.Time) (float64, error) {
+	window := at.Truncate(p.config.Interval)
+	key := surgeKeyPrefix + strconv.FormatInt(window.Unix(), 10)
+	raw, ok, err := p.config.Store.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load surge multiplier: %w", err)
+	}
+	if ok {
+		if multiplier, err := strconv.ParseFloat(raw, 64); err == nil {
+			return multiplier, nil
+		}
+	}
+
+	multiplier := p.scheduleMultiplier(window) * p.loadMultiplier(ctx)
+	if p.config.MaxMultiplier > 0 && multiplier > p.config.MaxMultiplier {
+		multiplier = p.config.MaxMultiplier
+	}
+	// Round so the remembered value prices exactly as computed
+	multiplier, _ = strconv.ParseFloat(strconv.FormatFloat(multiplier, 'f', 4, 64), 64)
+
+	// Only the current interval is remembered; an unremembered past quote
+	// (e.g. after a restart without a shared store) is priced afresh
+	if window.Equal(p.now().Truncate(p.config.Interval)) {
+		if err := p.config.Store.Set(ctx, key, strconv.FormatFloat(multiplier, 'f', 4, 64), p.config.QuoteTTL); err != nil {
+			return 0, fmt.Errorf("failed to store surge multiplier: %w", err)
+		}
+	}
+	return multiplier, nil
+}
+
+// quotedAt returns when the request's payment was quoted, or now. Quotes in
+// the future or older than QuoteTTL are priced now.
+func (p *SurgePricer) quotedAt(ctx context.Context) time.Time {
+	now := p.now()
+	payload, ok := PaymentPayloadFromContext(ctx)
+	if !ok || payload.Accepted.ExpiresAt == 0 {
+		return now
+	}
+	quoted := time.Unix(payload.Accepted.ExpiresAt-int64(payload.Accepted.MaxTimeoutSeconds), 0)
+	if quoted.After(now) || now.Sub(quoted) > p.config.QuoteTTL {
+		return now
+	}
+	return quoted
+}
+
+// scheduleMultiplier returns the highest multiplier of the schedules applying at t
+func (p *SurgePricer) scheduleMultiplier(t time.Time) float64 {
+	local := t.In(p.config.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.config.Location)
+	offset := local.Sub(midnight)
+	yesterday := local.AddDate(0, 0, -1).Weekday()
+
+	multiplier := 1.0
+	for _, schedule := range p.config.Schedules {
+		var applies bool
+		if schedule.Start <= schedule.End {
+			applies = offset >= schedule.Start && offset < schedule.End && scheduledOn(schedule.Days, local.Weekday())
+		} else {
+			// Wraps midnight: the evening belongs to today, the early hours to yesterday
+			applies = (offset >= schedule.Start && scheduledOn(schedule.Days, local.Weekday())) ||
+				(offset < schedule.End && scheduledOn(schedule.Days, yesterday))
+		}
+		if applies && schedule.Multiplier > multiplier {
+			multiplier = schedule.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// loadMultiplier returns the multiplier of the highest tier the current load reaches
+func (p *SurgePricer) loadMultiplier(ctx context.Context) float64 {
+	if p.config.Load == nil {
+		return 1
+	}
+	load, err := p.config.Load(ctx)
+	if err != nil {
+		return 1
+	}
+	multiplier, reached := 1.0, -1.0
+	for _, tier := range p.config.Tiers {
+		if load >= tier.Utilization && tier.Utilization > reached {
+			multiplier, reached = tier.Multiplier, tier.Utilization
+		}
+	}
+	return multiplier
+}
+
+func scheduledOn(days []time.Weekday, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// scalePrice multiplies a price, rounding money up to the micro-unit and
+// atomic amounts up to the unit
+func scalePrice(base x402.Price, multiplier float64) (x402.Price, error) {
+	factor, ok := new(big.Rat).SetString(strconv.FormatFloat(multiplier, 'f', 4, 64))
+	if !ok || factor.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid surge multiplier %v", multiplier)
+	}
+
+	switch price := base.(type) {
+	case x402.AssetAmount:
+		amount, ok := new(big.Rat).SetString(price.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid asset amount %q", price.Amount)
+		}
+		price.Amount = ceilRat(amount.Mul(amount, factor), 0)
+		return price, nil
+	case string:
+		number := strings.TrimSpace(price)
+		prefix, suffix := "", ""
+		if rest, ok := strings.CutPrefix(number, "$"); ok {
+			prefix, number = "$", rest
+		}
+		if value, unit, ok := strings.Cut(number, " "); ok {
+			number, suffix = value, " "+unit
+		}
+		amount, ok := new(big.Rat).SetString(number)
+		if !ok {
+			return nil, fmt.Errorf("invalid price %q", price)
+		}
+		return prefix + ceilRat(amount.Mul(amount, factor), 6) + suffix, nil
+	case float64:
+		amount, ok := new(big.Rat).SetString(strconv.FormatFloat(price, 'f', -1, 64))
+		if !ok {
+			return nil, fmt.Errorf("invalid price %v", price)
+		}
+		scaled, _ := strconv.ParseFloat(ceilRat(amount.Mul(amount, factor), 6), 64)
+		return scaled, nil
+	case int:
+		scaled, _ := strconv.ParseFloat(ceilRat(new(big.Rat).Mul(big.NewRat(int64(price), 1), factor), 6), 64)
+		return scaled, nil
+	}
+	return nil, fmt.Errorf("surge pricing does not support %T prices", base)
+}
+
+// ceilRat formats r rounded up to the given decimals
+func ceilRat(r *big.Rat, decimals int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+	units, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	if decimals == 0 {
+		return units.String()
+	}
+	whole, fraction := new(big.Int).QuoRem(units, scale, new(big.Int))
+	return fmt.Sprintf("%s.%0*d", whole, decimals, fraction)
+}
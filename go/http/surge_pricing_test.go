@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestSurgePricerSchedules(t *testing.T) {
+	pricer, err := NewSurgePricer(SurgePricerConfig{
+		Schedules: []SurgeSchedule{
+			{Start: 17 * time.Hour, End: 21 * time.Hour, Multiplier: 1.5},
+			{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 2 * time.Hour, Multiplier: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create surge pricer: %v", err)
+	}
+
+	cases := []struct {
+		at   time.Time
+		want float64
+	}{
+		{time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), 1},   // Wednesday noon
+		{time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), 1.5}, // Wednesday evening
+		{time.Date(2026, 10, 14, 21, 0, 0, 0, time.UTC), 1},   // end is exclusive
+		{time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC), 1},   // late Wednesday
+		{time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), 2},   // late Friday
+		{time.Date(2026, 10, 17, 1, 30, 0, 0, time.UTC), 2},   // Friday night, past midnight
+		{time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), 1},
+	}
+	for _, c := range cases {
+		if got := pricer.scheduleMultiplier(c.at); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.at, c.want, got)
+		}
+	}
+
+	if _, err := NewSurgePricer(SurgePricerConfig{Schedules: []SurgeSchedule{{Start: 25 * time.Hour, Multiplier: 2}}}); err == nil {
+		t.Error("Expected an error for a schedule past midnight")
+	}
+	if _, err := NewSurgePricer(SurgePricerConfig{Load: func(ctx context.Context) (float64, error) { return 0, nil }}); err == nil {
+		t.Error("Expected an error for a load signal without tiers")
+	}
+}
+
+func TestSurgePricerHonorsQuotedPrice(t *testing.T) {
+	load := 0.9
+	now := time.Date(2026, 10, 14, 12, 0, 10, 0, time.UTC)
+	pricer, err := NewSurgePricer(SurgePricerConfig{
+		Load:          func(ctx context.Context) (float64, error) { return load, nil },
+		Tiers:         []SurgeTier{{Utilization: 0.5, Multiplier: 1.5}, {Utilization: 0.8, Multiplier: 3}},
+		MaxMultiplier: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create surge pricer: %v", err)
+	}
+	pricer.now = func() time.Time { return now }
+	price := pricer.Price("$0.01")
+	priceAt := func(ctx context.Context) x402.Price {
+		p, err := price(ctx, HTTPRequestContext{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return p
+	}
+
+	// Quoted under load, capped at 2x
+	if p := priceAt(context.Background()); p != "$0.020000" {
+		t.Fatalf("Expected the capped surge price, got %v", p)
+	}
+
+	// Load drops within the interval: the multiplier holds
+	load = 0.1
+	now = now.Add(30 * time.Second)
+	if p := priceAt(context.Background()); p != "$0.020000" {
+		t.Errorf("Expected the multiplier to hold for the interval, got %v", p)
+	}
+
+	// In the next interval new quotes are cheaper, but a payment against the
+	// earlier quote is still priced as quoted
+	now = now.Add(time.Minute)
+	if p := priceAt(context.Background()); p != "$0.010000" {
+		t.Errorf("Expected the surge to end, got %v", p)
+	}
+	quoted := time.Date(2026, 10, 14, 12, 0, 10, 0, time.UTC)
+	paid := &types.PaymentPayload{Accepted: types.PaymentRequirements{MaxTimeoutSeconds: 60, ExpiresAt: quoted.Add(time.Minute).Unix()}}
+	if p := priceAt(context.WithValue(context.Background(), paymentPayloadContextKey{}, paid)); p != "$0.020000" {
+		t.Errorf("Expected the payment priced as quoted, got %v", p)
+	}
+
+	// Quotes older than QuoteTTL are priced now
+	now = now.Add(time.Hour)
+	if p := priceAt(context.WithValue(context.Background(), paymentPayloadContextKey{}, paid)); p != "$0.010000" {
+		t.Errorf("Expected a stale quote priced now, got %v", p)
+	}
+}
+
+func TestScalePrice(t *testing.T) {
+	cases := []struct {
+		base x402.Price
+		want x402.Price
+	}{
+		{"$0.01", "$0.011000"},
+		{"0.333333", "0.366667"}, // rounds up
+		{"0.5 USD", "0.550000 USD"},
+		{0.01, 0.011},
+		{2, 2.2},
+		{x402.AssetAmount{Asset: "0xusdc", Amount: "1000001"}, x402.AssetAmount{Asset: "0xusdc", Amount: "1100002"}},
+	}
+	for _, c := range cases {
+		got, err := scalePrice(c.base, 1.1)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("scalePrice(%v, 1.1) = %v (%v), want %v", c.base, got, err, c.want)
+		}
+	}
+	if _, err := scalePrice("free", 1.1); err == nil {
+		t.Error("Expected an error for an unparseable price")
+	}
+}
+
+func TestPaymentPayloadReachesDynamicPrice(t *testing.T) {
+	var seen *types.PaymentPayload
+	server := Newx402HTTPResourceServer(
+		RoutesConfig{"GET /api": {Accepts: PaymentOptions{{
+			Scheme: "exact", PayTo: "0xtest", Network: "eip155:1",
+			Price: DynamicPriceFunc(func(ctx context.Context, reqCtx HTTPRequestContext) (x402.Price, error) {
+				seen, _ = PaymentPayloadFromContext(ctx)
+				return "$1.00", nil
+			}),
+		}}}},
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", headers: map[string]string{}}
+	reqCtx := HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}
+	adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+	server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+	if seen == nil || seen.Accepted.PayTo != "0xtest" {
+		t.Errorf("Expected the presented payment in the price context, got %+v", seen)
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ============================================================================
+// Tenants
+// ============================================================================
+
+// RegisterTenant serves routes with RouteConfig.Tenant set to name through the
+// tenant's own resource server. Configure that server with the tenant's
+// facilitator client (and its credentials) and schemes: requirements, verify,
+// and settle for the tenant's routes then never touch another tenant's
+// facilitator, and hooks registered on it only see the tenant's payments.
+// Combine with host-specific routes and per-route PayTo so each tenant is paid
+// to its own addresses.
+//
+//	acme := x402.Newx402ResourceServer(
+//		x402.WithFacilitatorClient(x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: acmeURL, AuthProvider: acmeAuth})),
+//		x402.WithSchemeServer("eip155:8453", evm.NewExactEvmScheme()),
+//	)
+//	server.RegisterTenant("acme", acme)
+func (s *x402HTTPResourceServer) RegisterTenant(name string, server *x402.X402ResourceServer) *x402HTTPResourceServer {
+	if s.tenants == nil {
+		s.tenants = make(map[string]*x402.X402ResourceServer)
+	}
+	s.tenants[name] = server
+	return s
+}
+
+// Initialize initializes the shared resource server and every tenant's
+// resource server with their facilitators, and resolves payTo names when
+// name resolution is enabled
+func (s *x402HTTPResourceServer) Initialize(ctx context.Context) error {
+	if err := s.X402ResourceServer.Initialize(ctx); err != nil {
+		return err
+	}
+	if err := s.resolveRouteNames(ctx); err != nil {
+		return err
+	}
+	for name, tenant := range s.tenants {
+		if err := tenant.Initialize(ctx); err != nil {
+			return fmt.Errorf("failed to initialize tenant %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resourceServerFor returns the resource server handling a tenant's payments.
+// Unregistered tenants are an error rather than falling back to the shared
+// facilitator, so payments are never routed to another tenant.
+func (s *x402HTTPResourceServer) resourceServerFor(tenant string) (*x402.X402ResourceServer, error) {
+	if tenant == "" {
+		return s.X402ResourceServer, nil
+	}
+	server, ok := s.tenants[tenant]
+	if !ok {
+		return nil, fmt.Errorf("tenant %q is not registered", tenant)
+	}
+	return server, nil
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a context that routes ProcessSettlement through the
+// tenant's resource server. Middleware passes HTTPProcessResult.Tenant here.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	if tenant == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored by ContextWithTenant, if any
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// countingFacilitator records which facilitator handled each call
+func countingFacilitator(name string, calls *[]string) *mockFacilitatorClient {
+	return &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			*calls = append(*calls, name+":verify")
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			*calls = append(*calls, name+":settle")
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+	}
+}
+
+func TestTenantUsesOwnFacilitator(t *testing.T) {
+	var calls []string
+	routes := RoutesConfig{
+		"GET acme.com/api": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Tenant:  "acme",
+		},
+		"GET /api": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(countingFacilitator("shared", &calls)),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+
+	acme := x402.Newx402ResourceServer(
+		x402.WithFacilitatorClient(countingFacilitator("acme", &calls)),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	var acmeSettlements int
+	acme.OnAfterSettle(func(ctx x402.SettleResultContext) error {
+		acmeSettlements++
+		return nil
+	})
+	server.RegisterTenant("acme", acme)
+
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	pay := func(host string) {
+		adapter := &mockHTTPAdapter{
+			method:  "GET",
+			path:    "/api",
+			url:     "http://" + host + "/api",
+			headers: map[string]string{},
+		}
+		reqCtx := HTTPRequestContext{Adapter: adapter, Host: host, Path: "/api", Method: "GET"}
+		adapter.headers["PAYMENT-SIGNATURE"] = paymentHeader(t, server, reqCtx)
+		result := server.ProcessHTTPRequest(context.Background(), reqCtx, nil)
+		if result.Type != ResultPaymentVerified {
+			t.Fatalf("Expected verified payment for %s, got %s", host, result.Type)
+		}
+		settle := server.ProcessSettlement(ContextWithTenant(context.Background(), result.Tenant), *result.PaymentPayload, *result.PaymentRequirements)
+		if !settle.Success {
+			t.Fatalf("Expected settlement for %s, got %s", host, settle.ErrorReason)
+		}
+	}
+
+	pay("acme.com")
+	pay("other.com")
+
+	want := []string{"acme:verify", "acme:settle", "shared:verify", "shared:settle"}
+	if len(calls) != len(want) {
+		t.Fatalf("Expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Expected calls %v, got %v", want, calls)
+			break
+		}
+	}
+	if acmeSettlements != 1 {
+		t.Errorf("Expected tenant hooks to see only the tenant's settlement, got %d", acmeSettlements)
+	}
+}
+
+func TestUnregisteredTenantFailsClosed(t *testing.T) {
+	var calls []string
+	routes := RoutesConfig{
+		"GET /api": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+			Tenant:  "missing",
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(countingFacilitator("shared", &calls)),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	adapter := &mockHTTPAdapter{
+		method:  "GET",
+		path:    "/api",
+		url:     "http://example.com/api",
+		headers: map[string]string{"PAYMENT-SIGNATURE": acceptingPaymentHeader(x402.PaymentRequirements{Scheme: "exact", Network: "eip155:1"})},
+	}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+	if result.Response == nil || result.Response.Status != 500 {
+		t.Errorf("Expected 500 for unregistered tenant, got %+v", result)
+	}
+
+	settle := server.ProcessSettlement(ContextWithTenant(context.Background(), "missing"), x402.PaymentPayload{}, x402.PaymentRequirements{})
+	if settle.Success {
+		t.Error("Expected settlement for unregistered tenant to fail")
+	}
+	if len(calls) != 0 {
+		t.Errorf("Expected shared facilitator to be untouched, got %v", calls)
+	}
+}
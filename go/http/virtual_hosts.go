@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ============================================================================
+// Virtual Hosts
+// ============================================================================
+
+// Route patterns may name a host before the path so one server can front many
+// tenants' APIs, each with its own payTo and prices:
+//
+//	"GET api.acme.com/weather"    matches only requests for api.acme.com
+//	"GET *.globex.com/weather"    matches any single-label subdomain of globex.com
+//	"GET /weather"                matches every host
+//
+// Host matching ignores case and port. Routes with a host take precedence
+// over routes without one.
+
+// splitRouteHost separates a host from a route pattern, returning the host
+// (empty when the pattern applies to every host) and the pattern without it
+func splitRouteHost(pattern string) (string, string) {
+	parts := strings.Fields(pattern)
+	if len(parts) == 0 {
+		return "", pattern
+	}
+
+	path := parts[len(parts)-1]
+	slash := strings.Index(path, "/")
+	if slash <= 0 {
+		return "", pattern
+	}
+	host := path[:slash]
+	parts[len(parts)-1] = path[slash:]
+	return strings.ToLower(host), strings.Join(parts, " ")
+}
+
+// compileHostPattern converts a host pattern to a regex where "*" matches one DNS label
+func compileHostPattern(host string) *regexp.Regexp {
+	if host == "" {
+		return nil
+	}
+	regexPattern := "^" + regexp.QuoteMeta(host) + "$"
+	regexPattern = strings.ReplaceAll(regexPattern, `\*`, `[^.]+`)
+	return regexp.MustCompile(regexPattern)
+}
+
+// requestHost returns the lowercase host of a request without its port
+func requestHost(reqCtx HTTPRequestContext) string {
+	host := reqCtx.Host
+	if host == "" && reqCtx.Adapter != nil {
+		host = reqCtx.Adapter.GetHeader("Host")
+		if host == "" {
+			if parsed, err := url.Parse(reqCtx.Adapter.GetURL()); err == nil {
+				host = parsed.Host
+			}
+		}
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
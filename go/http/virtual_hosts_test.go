@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestSplitRouteHost(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		wantHost    string
+		wantPattern string
+	}{
+		{"GET /api/*", "", "GET /api/*"},
+		{"/api", "", "/api"},
+		{"*", "", "*"},
+		{"GET API.Acme.com/api/*", "api.acme.com", "GET /api/*"},
+		{"*.globex.com/weather", "*.globex.com", "/weather"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			host, pattern := splitRouteHost(tt.pattern)
+			if host != tt.wantHost || pattern != tt.wantPattern {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tt.wantHost, tt.wantPattern, host, pattern)
+			}
+		})
+	}
+}
+
+func TestRequestHost(t *testing.T) {
+	tests := []struct {
+		name   string
+		reqCtx HTTPRequestContext
+		want   string
+	}{
+		{"explicit host with port", HTTPRequestContext{Host: "API.acme.com:8443"}, "api.acme.com"},
+		{"host header", HTTPRequestContext{Adapter: &mockHTTPAdapter{headers: map[string]string{"Host": "acme.com"}}}, "acme.com"},
+		{"url host", HTTPRequestContext{Adapter: &mockHTTPAdapter{url: "https://globex.com:443/api"}}, "globex.com"},
+		{"ipv6", HTTPRequestContext{Host: "[::1]:8080"}, "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestHost(tt.reqCtx); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVirtualHostRouting(t *testing.T) {
+	routes := RoutesConfig{
+		"GET api.acme.com/weather": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xacme", Price: "$1.00", Network: "eip155:1"}},
+		},
+		"GET *.globex.com/weather": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xglobex", Price: "$2.00", Network: "eip155:1"}},
+		},
+		"GET /weather": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xdefault", Price: "$3.00", Network: "eip155:1"}},
+		},
+		"GET initech.com/reports": {
+			Accepts: PaymentOptions{{Scheme: "exact", PayTo: "0xinitech", Price: "$4.00", Network: "eip155:1"}},
+		},
+	}
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	_ = server.Initialize(context.Background())
+
+	payTo := func(host, path string) string {
+		adapter := &mockHTTPAdapter{method: "GET", path: path, url: "http://" + host + path}
+		result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{Adapter: adapter, Host: host, Path: path, Method: "GET"}, nil)
+		if result.Type == ResultNoPaymentRequired {
+			return ""
+		}
+		required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"], "")
+		if err != nil {
+			t.Fatalf("Failed to decode header: %v", err)
+		}
+		return required.Accepts[0].PayTo
+	}
+
+	tests := []struct {
+		host, path, want string
+	}{
+		{"api.acme.com", "/weather", "0xacme"},
+		{"API.ACME.COM:443", "/weather", "0xacme"},
+		{"eu.globex.com", "/weather", "0xglobex"},
+		{"a.b.globex.com", "/weather", "0xdefault"},
+		{"unknown.com", "/weather", "0xdefault"},
+		{"initech.com", "/reports", "0xinitech"},
+		{"acme.com", "/reports", ""},
+	}
+	for _, tt := range tests {
+		if got := payTo(tt.host, tt.path); got != tt.want {
+			t.Errorf("%s%s: expected payTo %q, got %q", tt.host, tt.path, tt.want, got)
+		}
+	}
+}
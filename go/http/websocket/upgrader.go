@@ -0,0 +1,409 @@
+// Package websocket charges for WebSocket connections with x402.
+//
+// The upgrade request is priced by the server's routes like any other
+// request: without a valid payment it gets a 402 before the connection is
+// upgraded, and a paid upgrade is settled before the 101 response, which
+// carries PAYMENT-RESPONSE. Long-lived connections can be charged again
+// periodically with in-band control messages: text messages holding a JSON
+// object whose type starts with "x402.".
+//
+//	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+//	    "GET /quotes": {Accepts: accepts, Description: "Live quotes, $0.01 per minute"},
+//	}, x402.WithFacilitatorClient(facilitator))
+//	_ = server.Initialize(ctx)
+//
+//	upgrader := websocket.NewUpgrader(server, gorilla.Upgrader{}).SetRepayment(time.Minute, 30*time.Second)
+//	http.HandleFunc("/quotes", func(w http.ResponseWriter, r *http.Request) {
+//	    conn, err := upgrader.Upgrade(w, r)
+//	    if err != nil {
+//	        return // the response was written
+//	    }
+//	    defer conn.Close()
+//	    ...
+//	})
+//
+// Serve paid WebSocket routes with the Upgrader rather than behind an HTTP
+// middleware, which buffers responses and cannot upgrade.
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+)
+
+// Control message types
+const (
+	// MessagePaymentRequired asks the client to pay for the next period
+	// (server to client, with paymentRequired)
+	MessagePaymentRequired = "x402.payment-required"
+
+	// MessagePayment pays for the next period (client to server, with payment)
+	MessagePayment = "x402.payment"
+
+	// MessagePaymentResponse reports a re-payment's settlement (server to
+	// client, with paymentResponse)
+	MessagePaymentResponse = "x402.payment-response"
+)
+
+// ControlMessage is an x402 control message. Its fields hold the same base64
+// values as the PAYMENT-REQUIRED, PAYMENT-SIGNATURE and PAYMENT-RESPONSE headers.
+type ControlMessage struct {
+	Type            string `json:"type"`
+	PaymentRequired string `json:"paymentRequired,omitempty"`
+	Payment         string `json:"payment,omitempty"`
+	PaymentResponse string `json:"paymentResponse,omitempty"`
+}
+
+// DefaultRepaymentTimeout is how long a client has to answer a re-payment
+// challenge when SetRepayment is given no timeout
+const DefaultRepaymentTimeout = 30 * time.Second
+
+// ErrPaymentRequired is returned by Upgrade when the request was answered
+// with a payment error instead of being upgraded
+var ErrPaymentRequired = errors.New("x402: payment required")
+
+// Upgrader upgrades WebSocket connections once they are paid for
+type Upgrader struct {
+	server   *x402http.HTTPServer
+	upgrader websocket.Upgrader
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewUpgrader creates an upgrader for an initialized HTTP server. upgrader
+// configures the WebSocket handshake (buffer sizes, origin check, ...).
+func NewUpgrader(server *x402http.HTTPServer, upgrader websocket.Upgrader) *Upgrader {
+	return &Upgrader{server: server, upgrader: upgrader}
+}
+
+// SetRepayment charges paid connections again every interval: the client is
+// sent a MessagePaymentRequired challenge and has timeout to answer it with
+// a MessagePayment, or the connection is closed with ClosePolicyViolation.
+// A zero interval (the default) charges once per connection.
+func (u *Upgrader) SetRepayment(interval, timeout time.Duration) *Upgrader {
+	if timeout <= 0 {
+		timeout = DefaultRepaymentTimeout
+	}
+	u.interval = interval
+	u.timeout = timeout
+	return u
+}
+
+// Upgrade charges for the connection and upgrades it. Requests to free routes
+// are upgraded as is. When the payment is missing, invalid or fails to
+// settle, the error response is written and ErrPaymentRequired returned.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	reqCtx := requestContext(r, nil, "")
+	if !u.server.RequiresPayment(reqCtx) {
+		ws, err := u.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Conn{ws: ws, done: make(chan struct{})}, nil
+	}
+
+	ctx := r.Context()
+	result := u.server.ProcessHTTPRequest(ctx, reqCtx, nil)
+	switch result.Type {
+	case x402http.ResultPaymentError:
+		writeInstructions(w, result.Response)
+		return nil, ErrPaymentRequired
+	case x402http.ResultNoPaymentRequired:
+		ws, err := u.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Conn{ws: ws, done: make(chan struct{})}, nil
+	}
+
+	settlement := u.server.ProcessSettlement(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+	)
+	if !settlement.Success {
+		reason := settlement.ErrorReason
+		if reason == "" {
+			reason = "Settlement failed"
+		}
+		writeJSON(w, http.StatusPaymentRequired, map[string]string{"error": "Settlement failed", "details": reason})
+		return nil, ErrPaymentRequired
+	}
+
+	responseHeader := http.Header{}
+	for key, value := range settlement.Headers {
+		responseHeader.Set(key, value)
+	}
+	ws, err := u.upgrader.Upgrade(w, r, responseHeader)
+	if err != nil {
+		// The payment was settled; the handshake failure is the client's
+		return nil, err
+	}
+
+	conn := &Conn{
+		ws:       ws,
+		done:     make(chan struct{}),
+		payer:    settlement.Payer,
+		upgrader: u,
+		request:  r,
+		ctx:      context.WithoutCancel(ctx),
+	}
+	if u.interval > 0 {
+		go conn.meter()
+	}
+	return conn, nil
+}
+
+// Conn is a WebSocket connection, paid for when its route is priced. Read
+// with ReadMessage: it answers re-payment messages itself and returns only
+// the application's messages. Writes are safe for concurrent use with the
+// challenges the connection sends.
+type Conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+
+	payer    string
+	upgrader *Upgrader
+	request  *http.Request
+	ctx      context.Context
+
+	mu   sync.Mutex
+	paid chan struct{} // open while a re-payment challenge is outstanding
+}
+
+// Payer returns the address that paid for the connection ("" on free routes)
+func (c *Conn) Payer() string {
+	return c.payer
+}
+
+// ReadMessage reads the next application message, handling x402 control
+// messages in between
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	for {
+		messageType, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return messageType, data, err
+		}
+		if messageType == websocket.TextMessage && c.upgrader != nil {
+			var message ControlMessage
+			if json.Unmarshal(data, &message) == nil && message.Type == MessagePayment {
+				c.handlePayment(message.Payment)
+				continue
+			}
+		}
+		return messageType, data, nil
+	}
+}
+
+// WriteMessage writes a message
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(messageType, data)
+}
+
+// Close closes the connection and stops re-payment challenges
+func (c *Conn) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return c.ws.Close()
+}
+
+// UnderlyingConn returns the gorilla connection, e.g. to set deadlines or
+// limits. Reading from it directly skips re-payment messages.
+func (c *Conn) UnderlyingConn() *websocket.Conn {
+	return c.ws
+}
+
+// meter challenges the client every interval and closes the connection when
+// a challenge goes unpaid
+func (c *Conn) meter() {
+	timer := time.NewTimer(c.upgrader.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-timer.C:
+		}
+
+		c.mu.Lock()
+		paid := make(chan struct{})
+		c.paid = paid
+		c.mu.Unlock()
+		if err := c.challenge("Payment required"); err != nil {
+			_ = c.Close()
+			return
+		}
+
+		select {
+		case <-c.done:
+			return
+		case <-paid:
+			timer.Reset(c.upgrader.interval)
+		case <-time.After(c.upgrader.timeout):
+			deadline := time.Now().Add(time.Second)
+			_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "payment required"), deadline)
+			_ = c.Close()
+			return
+		}
+	}
+}
+
+// challenge sends the route's payment challenge
+func (c *Conn) challenge(message string) error {
+	none := ""
+	result := c.upgrader.server.ProcessHTTPRequest(c.ctx, requestContext(c.request, &none, c.upgrader.server.HeaderNames().PaymentSignature), nil)
+	if result.Type != x402http.ResultPaymentError {
+		return errors.New("x402: no payment challenge for the route")
+	}
+	header := result.Response.Headers[c.upgrader.server.HeaderNames().PaymentRequired]
+	if required, err := headers.DecodePaymentRequired(header, result.Response.Headers[x402http.PaymentEncodingHeader]); err == nil && message != "" {
+		required.Error = message
+		if encoded, err := headers.EncodePaymentRequired(required); err == nil {
+			header = encoded
+		}
+	}
+	return c.writeControl(ControlMessage{Type: MessagePaymentRequired, PaymentRequired: header})
+}
+
+// handlePayment verifies and settles a re-payment. Payments are only
+// accepted while a challenge is outstanding; a failed one is challenged again.
+func (c *Conn) handlePayment(payment string) {
+	c.mu.Lock()
+	paid := c.paid
+	c.mu.Unlock()
+	if paid == nil || payment == "" {
+		return
+	}
+
+	server := c.upgrader.server
+	result := server.ProcessHTTPRequest(c.ctx, requestContext(c.request, &payment, server.HeaderNames().PaymentSignature), nil)
+	if result.Type != x402http.ResultPaymentVerified {
+		message := "Invalid payment"
+		if result.Response != nil {
+			if required, err := headers.DecodePaymentRequired(result.Response.Headers[server.HeaderNames().PaymentRequired], result.Response.Headers[x402http.PaymentEncodingHeader]); err == nil && required.Error != "" {
+				message = required.Error
+			}
+		}
+		_ = c.challenge(message)
+		return
+	}
+
+	settlement := server.ProcessSettlement(
+		x402.ContextWithFacilitator(x402http.ContextWithTenant(c.ctx, result.Tenant), result.Facilitator),
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+	)
+	if !settlement.Success {
+		reason := settlement.ErrorReason
+		if reason == "" {
+			reason = "Settlement failed"
+		}
+		_ = c.challenge(reason)
+		return
+	}
+
+	c.mu.Lock()
+	if c.paid == paid {
+		c.paid = nil
+		close(paid)
+	}
+	c.mu.Unlock()
+	_ = c.writeControl(ControlMessage{Type: MessagePaymentResponse, PaymentResponse: settlement.Headers[server.HeaderNames().PaymentResponse]})
+}
+
+// writeControl sends a control message
+func (c *Conn) writeControl(message ControlMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(websocket.TextMessage, data)
+}
+
+// requestContext describes the upgrade request to the HTTP server, with the
+// upgrade's payment replaced by payment when it is set ("" for none).
+// signatureHeader is the server's payment header name.
+func requestContext(r *http.Request, payment *string, signatureHeader string) x402http.HTTPRequestContext {
+	return x402http.HTTPRequestContext{
+		Adapter: &requestAdapter{r: r, payment: payment, signatureHeader: signatureHeader},
+		Host:    r.Host,
+		Path:    r.URL.Path,
+		Method:  r.Method,
+	}
+}
+
+// requestAdapter adapts the upgrade request for the x402 HTTP server
+type requestAdapter struct {
+	r               *http.Request
+	payment         *string
+	signatureHeader string
+}
+
+func (a *requestAdapter) GetHeader(name string) string {
+	if a.payment != nil {
+		switch http.CanonicalHeaderKey(name) {
+		case http.CanonicalHeaderKey(headers.PaymentSignature), http.CanonicalHeaderKey(a.signatureHeader):
+			return *a.payment
+		case http.CanonicalHeaderKey(headers.XPayment), http.CanonicalHeaderKey(headers.PaymentEncoding):
+			return ""
+		}
+	}
+	return a.r.Header.Get(name)
+}
+
+func (a *requestAdapter) GetMethod() string       { return a.r.Method }
+func (a *requestAdapter) GetPath() string         { return a.r.URL.Path }
+func (a *requestAdapter) GetAcceptHeader() string { return a.r.Header.Get("Accept") }
+func (a *requestAdapter) GetUserAgent() string    { return a.r.UserAgent() }
+func (a *requestAdapter) GetClientIP() string     { return x402http.RemoteIP(a.r.RemoteAddr) }
+
+func (a *requestAdapter) GetURL() string {
+	scheme := "ws"
+	if a.r.TLS != nil {
+		scheme = "wss"
+	}
+	return scheme + "://" + a.r.Host + a.r.URL.Path
+}
+
+// writeInstructions writes a payment error response
+func writeInstructions(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case nil:
+		w.WriteHeader(response.Status)
+	case string:
+		if response.IsHTML {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.WriteHeader(response.Status)
+		_, _ = io.WriteString(w, body)
+	case []byte:
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(body)
+	default:
+		writeJSON(w, response.Status, body)
+	}
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
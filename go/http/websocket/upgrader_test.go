@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// newTestEndpoint serves an echo WebSocket at /quotes, charged $1 per connection
+// and again every interval
+func newTestEndpoint(t *testing.T, interval, timeout time.Duration) string {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+		"GET /quotes": {Accepts: x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$1"}}},
+	}, x402.WithFacilitatorClient(cash.NewFacilitatorClient(facilitator)), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	upgrader := NewUpgrader(server, websocket.Upgrader{}).SetRepayment(interval, timeout)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(conn.Payer()+": "+string(data))); err != nil {
+				return
+			}
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/quotes", handler)
+	mux.HandleFunc("/free", handler)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+// pay answers an encoded challenge with a payment from ~Alice, encoded as a header
+func pay(t *testing.T, challenge string) string {
+	t.Helper()
+	required, err := headers.DecodePaymentRequired(challenge, "")
+	if err != nil || len(required.Accepts) != 1 {
+		t.Fatalf("Expected a payment challenge, got %+v, %v", required, err)
+	}
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	payload, err := client.CreatePaymentPayload(context.Background(), required.Accepts[0], required.Resource, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+	signature, err := headers.EncodePaymentSignature(payload)
+	if err != nil {
+		t.Fatalf("Failed to encode payment: %v", err)
+	}
+	return signature
+}
+
+// dialPaid opens a paid connection to /quotes
+func dialPaid(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	_, resp, err := websocket.DefaultDialer.Dial(url+"/quotes", nil)
+	if !errors.Is(err, websocket.ErrBadHandshake) || resp.StatusCode != http.StatusPaymentRequired {
+		t.Fatalf("Expected a 402 before the upgrade, got %v", err)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial(url+"/quotes", http.Header{headers.PaymentSignature: {pay(t, resp.Header.Get(headers.PaymentRequired))}})
+	if err != nil {
+		t.Fatalf("Expected the paid upgrade to succeed, got %v", err)
+	}
+	if settled, err := headers.DecodePaymentResponse(resp.Header.Get(headers.PaymentResponse)); err != nil || !settled.Success {
+		t.Errorf("Expected the upgrade response to carry the settlement, got %+v, %v", settled, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readControl(t *testing.T, conn *websocket.Conn) ControlMessage {
+	t.Helper()
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Unexpected read error: %v", err)
+	}
+	var message ControlMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("Expected a control message, got %q", data)
+	}
+	return message
+}
+
+func TestUpgraderChargesBeforeUpgrade(t *testing.T) {
+	url := newTestEndpoint(t, 0, 0)
+
+	free, _, err := websocket.DefaultDialer.Dial(url+"/free", nil)
+	if err != nil {
+		t.Fatalf("Expected a free route to upgrade, got %v", err)
+	}
+	defer free.Close()
+
+	conn := dialPaid(t, url)
+	_ = conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "~Alice: hello" {
+		t.Errorf("Expected the handler to see the payer, got %q, %v", data, err)
+	}
+}
+
+func TestUpgraderChargesPeriodically(t *testing.T) {
+	url := newTestEndpoint(t, 50*time.Millisecond, 5*time.Second)
+	conn := dialPaid(t, url)
+
+	challenge := readControl(t, conn)
+	if challenge.Type != MessagePaymentRequired {
+		t.Fatalf("Expected a re-payment challenge, got %+v", challenge)
+	}
+
+	// A bad payment is challenged again
+	bad, _ := json.Marshal(ControlMessage{Type: MessagePayment, Payment: "not-a-payment"})
+	_ = conn.WriteMessage(websocket.TextMessage, bad)
+	if retry := readControl(t, conn); retry.Type != MessagePaymentRequired {
+		t.Fatalf("Expected a new challenge after a bad payment, got %+v", retry)
+	}
+
+	payment, _ := json.Marshal(ControlMessage{Type: MessagePayment, Payment: pay(t, challenge.PaymentRequired)})
+	_ = conn.WriteMessage(websocket.TextMessage, payment)
+	response := readControl(t, conn)
+	if settled, err := headers.DecodePaymentResponse(response.PaymentResponse); response.Type != MessagePaymentResponse || err != nil || !settled.Success {
+		t.Fatalf("Expected the re-payment to settle, got %+v, %v", response, err)
+	}
+}
+
+func TestUpgraderClosesUnpaidConnections(t *testing.T) {
+	url := newTestEndpoint(t, 20*time.Millisecond, 50*time.Millisecond)
+	conn := dialPaid(t, url)
+
+	if challenge := readControl(t, conn); challenge.Type != MessagePaymentRequired {
+		t.Fatalf("Expected a re-payment challenge, got %+v", challenge)
+	}
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("Expected the connection to close for non-payment, got %v", err)
+	}
+}
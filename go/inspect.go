@@ -0,0 +1,142 @@
+package x402
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// PaymentInspection is a payment payload's details, normalized across
+// versions and mechanisms for logs, admin UIs and tooling. Nothing in it is
+// verified: it is what the payload claims.
+type PaymentInspection struct {
+	X402Version int    `json:"x402Version"`
+	Scheme      string `json:"scheme"`
+	Network     string `json:"network"`
+
+	// Payer is the account paying, when the payload names it
+	Payer string `json:"payer,omitempty"`
+
+	// Amount is the authorized amount in the asset's smallest unit, or the
+	// accepted requirements' amount when the payload does not state one
+	Amount string `json:"amount,omitempty"`
+
+	// Asset is the token address (or mint) paid with
+	Asset string `json:"asset,omitempty"`
+
+	// PayTo is the recipient of the accepted requirements (V2 only)
+	PayTo string `json:"payTo,omitempty"`
+
+	// Expiry is when the authorization stops being valid, when it has a deadline
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// PayloadInspector fills in an inspection from a mechanism's payload. It
+// reports whether it recognized the payload.
+type PayloadInspector func(payload map[string]interface{}, inspection *PaymentInspection) bool
+
+var (
+	payloadInspectorsMu sync.RWMutex
+	payloadInspectors   []PayloadInspector
+)
+
+// RegisterPayloadInspector adds an inspector for payloads InspectPayment
+// cannot read on its own, such as the signed Solana transactions of SVM
+// payments (registered by the svm package). EVM authorizations are built in.
+func RegisterPayloadInspector(inspector PayloadInspector) {
+	payloadInspectorsMu.Lock()
+	defer payloadInspectorsMu.Unlock()
+	payloadInspectors = append(payloadInspectors, inspector)
+}
+
+// InspectPayment decodes a V1 or V2 payment payload (JSON, as carried
+// base64-encoded in PAYMENT-SIGNATURE or X-PAYMENT) into a PaymentInspection,
+// without verifying it. Payloads of unknown mechanisms only get the fields of
+// the envelope.
+func InspectPayment(payloadBytes []byte) (*PaymentInspection, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &PaymentInspection{X402Version: version}
+	var payload map[string]interface{}
+	switch version {
+	case 1:
+		var v1 types.PaymentPayloadV1
+		if err := json.Unmarshal(payloadBytes, &v1); err != nil {
+			return nil, fmt.Errorf("invalid v1 payment payload: %w", err)
+		}
+		inspection.Scheme = v1.Scheme
+		inspection.Network = v1.Network
+		payload = v1.Payload
+	case 2:
+		var v2 types.PaymentPayload
+		if err := json.Unmarshal(payloadBytes, &v2); err != nil {
+			return nil, fmt.Errorf("invalid v2 payment payload: %w", err)
+		}
+		inspection.Scheme = v2.Accepted.Scheme
+		inspection.Network = v2.Accepted.Network
+		inspection.Amount = v2.Accepted.Amount
+		inspection.Asset = v2.Accepted.Asset
+		inspection.PayTo = v2.Accepted.PayTo
+		payload = v2.Payload
+	default:
+		return nil, fmt.Errorf("unsupported x402 version: %d", version)
+	}
+
+	if inspectAuthorization(payload, inspection) {
+		return inspection, nil
+	}
+	payloadInspectorsMu.RLock()
+	defer payloadInspectorsMu.RUnlock()
+	for _, inspector := range payloadInspectors {
+		if inspector(payload, inspection) {
+			break
+		}
+	}
+	return inspection, nil
+}
+
+// inspectAuthorization reads EVM authorizations: EIP-3009 (authorization)
+// and Permit2 (permit2Authorization)
+func inspectAuthorization(payload map[string]interface{}, inspection *PaymentInspection) bool {
+	if authorization, ok := payload["authorization"].(map[string]interface{}); ok {
+		inspection.Payer, _ = authorization["from"].(string)
+		if value, _ := authorization["value"].(string); value != "" {
+			inspection.Amount = value
+		}
+		validBefore, _ := authorization["validBefore"].(string)
+		inspection.Expiry = unixTime(validBefore)
+		return true
+	}
+	if authorization, ok := payload["permit2Authorization"].(map[string]interface{}); ok {
+		inspection.Payer, _ = authorization["from"].(string)
+		if permitted, ok := authorization["permitted"].(map[string]interface{}); ok {
+			if amount, _ := permitted["amount"].(string); amount != "" {
+				inspection.Amount = amount
+			}
+			if token, _ := permitted["token"].(string); token != "" {
+				inspection.Asset = token
+			}
+		}
+		deadline, _ := authorization["deadline"].(string)
+		inspection.Expiry = unixTime(deadline)
+		return true
+	}
+	return false
+}
+
+// unixTime parses a decimal Unix timestamp, or returns nil
+func unixTime(value string) *time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
@@ -0,0 +1,60 @@
+package x402
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInspectPayment(t *testing.T) {
+	expiry := time.Unix(1767225600, 0).UTC()
+	tests := []struct {
+		name    string
+		payload string
+		want    PaymentInspection
+	}{
+		{
+			name: "v2 EIP-3009 authorization",
+			payload: `{"x402Version":2,"accepted":{"scheme":"exact","network":"eip155:8453","asset":"0xusdc","amount":"10000","payTo":"0xbob"},
+				"payload":{"signature":"0x","authorization":{"from":"0xalice","to":"0xbob","value":"10000","validAfter":"0","validBefore":"1767225600","nonce":"0x01"}}}`,
+			want: PaymentInspection{X402Version: 2, Scheme: "exact", Network: "eip155:8453", Payer: "0xalice", Amount: "10000", Asset: "0xusdc", PayTo: "0xbob", Expiry: &expiry},
+		},
+		{
+			name: "v2 Permit2 authorization",
+			payload: `{"x402Version":2,"accepted":{"scheme":"exact","network":"eip155:8453","asset":"0xtoken","amount":"5","payTo":"0xbob"},
+				"payload":{"permit2Authorization":{"from":"0xalice","permitted":{"token":"0xtoken","amount":"5"},"deadline":"1767225600"}}}`,
+			want: PaymentInspection{X402Version: 2, Scheme: "exact", Network: "eip155:8453", Payer: "0xalice", Amount: "5", Asset: "0xtoken", PayTo: "0xbob", Expiry: &expiry},
+		},
+		{
+			name:    "v1 authorization",
+			payload: `{"x402Version":1,"scheme":"exact","network":"base-sepolia","payload":{"authorization":{"from":"0xalice","value":"20000","validBefore":"1767225600"}}}`,
+			want:    PaymentInspection{X402Version: 1, Scheme: "exact", Network: "base-sepolia", Payer: "0xalice", Amount: "20000", Expiry: &expiry},
+		},
+		{
+			name:    "unknown mechanism",
+			payload: `{"x402Version":2,"accepted":{"scheme":"cash","network":"x402:cash","amount":"1","payTo":"Bob"},"payload":{"signature":"~Alice"}}`,
+			want:    PaymentInspection{X402Version: 2, Scheme: "cash", Network: "x402:cash", Amount: "1", PayTo: "Bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InspectPayment([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("InspectPayment() error = %v", err)
+			}
+			if (got.Expiry == nil) != (tt.want.Expiry == nil) || (got.Expiry != nil && !got.Expiry.Equal(*tt.want.Expiry)) {
+				t.Errorf("InspectPayment() expiry = %v, want %v", got.Expiry, tt.want.Expiry)
+			}
+			got.Expiry, tt.want.Expiry = nil, nil
+			if *got != tt.want {
+				t.Errorf("InspectPayment() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{`not json`, `{"x402Version":0}`, `{"x402Version":3}`} {
+		if _, err := InspectPayment([]byte(invalid)); err == nil {
+			t.Errorf("InspectPayment(%s) expected an error", invalid)
+		}
+	}
+}
@@ -147,6 +147,22 @@ type SchemeNetworkFacilitator interface {
 	Settle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error)
 }
 
+// SchemeNetworkSimulator is optionally implemented by SchemeNetworkFacilitator
+// mechanisms that can simulate settlement. Simulate runs all verification and
+// a full settlement simulation, returning the projected cost without
+// broadcasting anything.
+type SchemeNetworkSimulator interface {
+	Simulate(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SimulateResponse, error)
+}
+
+// SchemeNetworkPartialSettler is optionally implemented by
+// SchemeNetworkFacilitator mechanisms whose payments authorize a maximum
+// amount, such as usage-based schemes. SettlePartial settles amountToSettle
+// (atomic units, at most the requirements' amount) instead of the maximum.
+type SchemeNetworkPartialSettler interface {
+	SettlePartial(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error)
+}
+
 // ============================================================================
 // FacilitatorClient Interfaces (Network Boundary - uses bytes)
 // ============================================================================
@@ -164,3 +180,22 @@ type FacilitatorClient interface {
 	// GetSupported returns supported payment kinds in flat array format with x402Version in each element (backward compatible)
 	GetSupported(ctx context.Context) (SupportedResponse, error)
 }
+
+// SimulatingFacilitatorClient is implemented by facilitator clients that can
+// simulate settlement (V2 only), e.g. for cost-aware facilitator routing
+type SimulatingFacilitatorClient interface {
+	Simulate(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SimulateResponse, error)
+}
+
+// PartialSettlingFacilitatorClient is implemented by facilitator clients that
+// can settle less than the authorized amount (V2 only), e.g. for usage-based
+// schemes. amountToSettle is in atomic units.
+type PartialSettlingFacilitatorClient interface {
+	SettlePartial(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*SettleResponse, error)
+}
+
+// FeeQuotingFacilitatorClient is implemented by facilitator clients that can
+// quote the facilitator's current fee for a payment before it is made
+type FeeQuotingFacilitatorClient interface {
+	QuoteFee(ctx context.Context, request FeeQuoteRequest) (*FeeQuote, error)
+}
@@ -0,0 +1,215 @@
+// Package keyexchange sells conventional API keys for x402 payments, so an
+// existing key-based API can be monetized without changing it. Behind a paid
+// route, Handler asks an Issuer (typically a call into the API's own key
+// backend) for a key, settles the payment, and returns the key to the client,
+// which then calls the API as any other customer would.
+//
+//	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+//	    "POST /keys": {Accepts: x402http.PaymentOptions{{Scheme: "exact", Network: "eip155:8453", PayTo: payTo, Price: "$5"}}},
+//	}, x402.WithFacilitatorClient(facilitator), ...)
+//	http.Handle("/keys", keyexchange.Handler(server, keyexchange.Config{
+//	    Issuer: keyexchange.IssuerFunc(func(ctx context.Context, p keyexchange.Purchase) (*keyexchange.Key, error) {
+//	        return backend.CreateKey(ctx, p.Payer, 10_000) // 10k calls for $5
+//	    }),
+//	}))
+package keyexchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// Purchase describes the payment a key is being issued for
+type Purchase struct {
+	// Payer is the address that paid, as reported by verification
+	Payer string
+
+	// Scheme, Network, Asset, Amount, and PayTo are the requirements paid
+	Scheme  string
+	Network x402.Network
+	Asset   string
+	Amount  string
+	PayTo   string
+
+	// Request is the paid request, e.g. to read a plan from its query
+	Request *http.Request
+}
+
+// Key is an issued API key
+type Key struct {
+	// Key is the secret the client presents to the API
+	Key string `json:"key"`
+
+	// ExpiresAt is when the key stops working, if it expires
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Metadata is returned to the client as-is (e.g. the key's ID, plan, or
+	// call quota)
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Issuer provisions keys in the API's key backend
+type Issuer interface {
+	// Issue creates a key for a verified payment. It is called before the
+	// payment settles; the key is only returned once it has.
+	Issue(ctx context.Context, purchase Purchase) (*Key, error)
+}
+
+// IssuerFunc adapts a function to an Issuer
+type IssuerFunc func(ctx context.Context, purchase Purchase) (*Key, error)
+
+// Issue implements Issuer
+func (f IssuerFunc) Issue(ctx context.Context, purchase Purchase) (*Key, error) {
+	return f(ctx, purchase)
+}
+
+// Config configures a Handler
+type Config struct {
+	// Issuer provisions the keys
+	Issuer Issuer
+
+	// Revoke disables a key whose payment failed to settle. The key is never
+	// returned to the client either way, but without Revoke it is left
+	// unused in the backend.
+	Revoke func(ctx context.Context, key *Key) error
+}
+
+// Response is the JSON body Handler returns
+type Response struct {
+	Key
+
+	// Transaction and Network identify the settled payment
+	Transaction string       `json:"transaction"`
+	Network     x402.Network `json:"network"`
+}
+
+// Handler returns an http.Handler that sells keys on server's paid routes.
+// Unpaid requests get the 402 response. Each verified payment gets one key:
+// the Issuer is called first, so a payment is never taken without a key to
+// show for it, and the key is released only after settlement succeeds (a
+// failed settlement revokes it and responds 402). Requests to routes that
+// require no payment are refused with 404, so keys are never given away.
+func Handler(server *x402http.HTTPServer, config Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		result := server.ProcessHTTPRequest(ctx, x402http.HTTPRequestContext{
+			Adapter: &requestAdapter{r: r},
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}, nil)
+		switch result.Type {
+		case x402http.ResultPaymentError:
+			writeInstructions(w, result.Response)
+			return
+		case x402http.ResultNoPaymentRequired:
+			http.NotFound(w, r)
+			return
+		}
+
+		requirements := result.PaymentRequirements
+		key, err := config.Issuer.Issue(ctx, Purchase{
+			Payer:   result.Payer,
+			Scheme:  requirements.Scheme,
+			Network: x402.Network(requirements.Network),
+			Asset:   requirements.Asset,
+			Amount:  requirements.Amount,
+			PayTo:   requirements.PayTo,
+			Request: r,
+		})
+		if err == nil && (key == nil || key.Key == "") {
+			err = errors.New("issuer returned no key")
+		}
+		if err != nil {
+			server.ReleasePayment(ctx, *result.PaymentPayload)
+			writeError(w, http.StatusBadGateway, "key_issuance_failed", err.Error())
+			return
+		}
+
+		settleCtx := x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator)
+		settlement := server.ProcessSettlement(settleCtx, *result.PaymentPayload, *requirements)
+		if !settlement.Success {
+			if config.Revoke != nil {
+				_ = config.Revoke(context.WithoutCancel(ctx), key)
+			}
+			reason := settlement.ErrorReason
+			if reason == "" {
+				reason = "Settlement failed"
+			}
+			writeError(w, http.StatusPaymentRequired, "settlement_failed", reason)
+			return
+		}
+
+		for name, value := range settlement.Headers {
+			w.Header().Set(name, value)
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Response{Key: *key, Transaction: settlement.Transaction, Network: settlement.Network})
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, reason, details string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": reason, "details": details})
+}
+
+// writeInstructions writes a payment error response
+func writeInstructions(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case []byte:
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(body)
+	case string:
+		w.WriteHeader(response.Status)
+		_, _ = io.WriteString(w, body)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.Status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+// requestAdapter adapts a net/http request for the x402 HTTP server
+type requestAdapter struct {
+	r *http.Request
+}
+
+func (a *requestAdapter) GetHeader(name string) string { return a.r.Header.Get(name) }
+func (a *requestAdapter) GetMethod() string            { return a.r.Method }
+func (a *requestAdapter) GetPath() string              { return a.r.URL.Path }
+func (a *requestAdapter) GetAcceptHeader() string      { return a.r.Header.Get("Accept") }
+func (a *requestAdapter) GetUserAgent() string         { return a.r.UserAgent() }
+func (a *requestAdapter) GetClientIP() string          { return x402http.RemoteIP(a.r.RemoteAddr) }
+
+func (a *requestAdapter) GetURL() string {
+	scheme := "http"
+	if a.r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + a.r.Host + a.r.URL.Path
+}
+
+// GetBody reads the request body and restores it, for routes that bind payments to it
+func (a *requestAdapter) GetBody() ([]byte, error) {
+	if a.r.Body == nil {
+		return nil, nil
+	}
+	body, replay, err := x402http.ReadBody(a.r.Body)
+	if err != nil {
+		return nil, err
+	}
+	a.r.Body = replay
+	return body, nil
+}
@@ -0,0 +1,151 @@
+package keyexchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+)
+
+const testNetwork x402.Network = "x402:cash"
+
+// failingSettlement verifies payments but never settles them
+type failingSettlement struct {
+	*cash.FacilitatorClient
+}
+
+func (f failingSettlement) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	return nil, x402.NewSettleError("insufficient_funds", "", testNetwork, "", "insufficient funds")
+}
+
+// fakeBackend issues numbered keys and records revocations
+type fakeBackend struct {
+	issued  []Purchase
+	revoked []string
+	err     error
+}
+
+func (b *fakeBackend) Issue(ctx context.Context, purchase Purchase) (*Key, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	b.issued = append(b.issued, purchase)
+	return &Key{Key: "sk_live_" + purchase.Payer, Metadata: map[string]interface{}{"calls": 10000}}, nil
+}
+
+func (b *fakeBackend) revoke(ctx context.Context, key *Key) error {
+	b.revoked = append(b.revoked, key.Key)
+	return nil
+}
+
+func newTestExchange(t *testing.T, settles bool) (*httptest.Server, *fakeBackend) {
+	t.Helper()
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{testNetwork}, cash.NewSchemeNetworkFacilitator())
+	var client x402.FacilitatorClient = cash.NewFacilitatorClient(facilitator)
+	if !settles {
+		client = failingSettlement{cash.NewFacilitatorClient(facilitator)}
+	}
+
+	server := x402http.Newx402HTTPResourceServer(x402http.RoutesConfig{
+		"POST /keys": {Accepts: x402http.PaymentOptions{{Scheme: "cash", Network: testNetwork, PayTo: "Bob", Price: "$5"}}},
+	}, x402.WithFacilitatorClient(client), x402.WithSchemeServer(testNetwork, cash.NewSchemeNetworkServer()))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	backend := &fakeBackend{}
+	return httptest.NewServer(Handler(server, Config{Issuer: backend, Revoke: backend.revoke})), backend
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register(testNetwork, cash.NewSchemeNetworkClient("Alice"))
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+func TestHandlerIssuesKeyAfterSettlement(t *testing.T) {
+	exchange, backend := newTestExchange(t, true)
+	defer exchange.Close()
+
+	resp, err := http.Post(exchange.URL+"/keys", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPaymentRequired || len(backend.issued) != 0 {
+		t.Fatalf("Expected an unpaid 402 with no key issued, got %d with %d issued", resp.StatusCode, len(backend.issued))
+	}
+
+	resp, err = payingClient().Post(exchange.URL+"/keys", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected a key, got %d (%v)", resp.StatusCode, err)
+	}
+	if response.Key.Key != "sk_live_~Alice" || response.Metadata["calls"] != float64(10000) || response.Network != testNetwork || response.Transaction == "" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if settlement, err := headers.DecodePaymentResponse(resp.Header.Get("PAYMENT-RESPONSE")); err != nil || settlement.Transaction != response.Transaction {
+		t.Errorf("Expected the settlement header, got %+v (%v)", settlement, err)
+	}
+	if resp.Header.Get("Cache-Control") != "no-store" {
+		t.Error("Expected keys not to be cached")
+	}
+	if len(backend.issued) != 1 || backend.issued[0].Payer != "~Alice" || backend.issued[0].PayTo != "Bob" || backend.issued[0].Request == nil {
+		t.Errorf("Unexpected purchase %+v", backend.issued)
+	}
+}
+
+func TestHandlerRevokesKeyWhenSettlementFails(t *testing.T) {
+	exchange, backend := newTestExchange(t, false)
+	defer exchange.Close()
+
+	resp, err := payingClient().Post(exchange.URL+"/keys", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusPaymentRequired || body["error"] != "settlement_failed" {
+		t.Fatalf("Expected a settlement failure, got %d %v", resp.StatusCode, body)
+	}
+	if len(backend.revoked) != 1 || backend.revoked[0] != "sk_live_~Alice" {
+		t.Errorf("Expected the issued key to be revoked, got %v", backend.revoked)
+	}
+}
+
+func TestHandlerDoesNotSettleWhenIssuingFails(t *testing.T) {
+	exchange, backend := newTestExchange(t, true)
+	defer exchange.Close()
+	backend.err = errors.New("backend unavailable")
+
+	resp, err := payingClient().Post(exchange.URL+"/keys", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || resp.Header.Get("PAYMENT-RESPONSE") != "" {
+		t.Errorf("Expected an unsettled 502, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(exchange.URL+"/free", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected routes without payment to give no keys, got %d", resp.StatusCode)
+	}
+}
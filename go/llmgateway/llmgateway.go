@@ -0,0 +1,555 @@
+// Package llmgateway is a reference x402 gateway for OpenAI-compatible chat
+// completions, billed per token. Each request authorizes the most it could
+// cost (its prompt plus max_tokens of output) under a usage-based scheme such
+// as upto; the gateway proxies it upstream, counts the tokens actually used
+// (from the usage the upstream reports, streamed or not), and settles only
+// what they cost.
+//
+//	gateway, _ := llmgateway.New(llmgateway.Config{
+//	    Upstream: "https://api.openai.com/v1",
+//	    APIKey:   os.Getenv("OPENAI_API_KEY"),
+//	    Models: map[string]llmgateway.ModelPricing{
+//	        "gpt-4o-mini": {Input: "$0.15", Output: "$0.60", MaxTokens: 4096},
+//	    },
+//	    Accepts: x402http.PaymentOptions{{Scheme: "upto", Network: "eip155:8453", PayTo: payTo}},
+//	})
+//	server := x402http.Newx402HTTPResourceServer(gateway.Routes(), x402.WithFacilitatorClient(facilitator), ...)
+//	http.Handle(llmgateway.ChatCompletionsPath, gateway.Handler(server))
+package llmgateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// ChatCompletionsPath is the OpenAI-compatible path the gateway serves
+const ChatCompletionsPath = "/v1/chat/completions"
+
+// SettlementErrorTrailer reports a failed settlement after a streamed
+// response, whose PAYMENT-RESPONSE is sent as a trailer
+const SettlementErrorTrailer = "X-402-Settlement-Error"
+
+// DefaultMaxTokens caps completions for models without a MaxTokens
+const DefaultMaxTokens = 4096
+
+// maxRequestBytes bounds request bodies
+const maxRequestBytes = 10 << 20
+
+// ModelPricing prices a model's tokens
+type ModelPricing struct {
+	// Input and Output are USD prices per million tokens (e.g. "$0.15")
+	Input  string
+	Output string
+
+	// MaxTokens caps completion tokens per request and is the default when a
+	// request sets none (default: DefaultMaxTokens). The authorized maximum
+	// is priced from it, so lower caps mean smaller authorizations.
+	MaxTokens int
+}
+
+// Config configures a Gateway
+type Config struct {
+	// Upstream is the OpenAI-compatible API base URL (e.g. "https://api.openai.com/v1")
+	Upstream string
+
+	// APIKey authenticates to the upstream
+	APIKey string
+
+	// Models prices each model the gateway serves; other models are refused
+	Models map[string]ModelPricing
+
+	// Accepts lists how requests can be paid. Price is set by the gateway;
+	// Scheme defaults to "upto".
+	Accepts x402http.PaymentOptions
+
+	// Client calls the upstream (default: a client with a 10 minute timeout)
+	Client *http.Client
+}
+
+// Gateway proxies chat completions and bills them per token
+type Gateway struct {
+	config Config
+	prices map[string]modelPrices
+}
+
+// modelPrices are a model's parsed prices in USD per token
+type modelPrices struct {
+	input     *big.Rat
+	output    *big.Rat
+	maxTokens int
+}
+
+// New creates a gateway
+func New(config Config) (*Gateway, error) {
+	if config.Upstream == "" {
+		return nil, errors.New("llm gateway needs an upstream")
+	}
+	if len(config.Models) == 0 {
+		return nil, errors.New("llm gateway needs model pricing")
+	}
+	if len(config.Accepts) == 0 {
+		return nil, errors.New("llm gateway needs at least one payment option")
+	}
+	config.Upstream = strings.TrimSuffix(config.Upstream, "/")
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Minute}
+	}
+
+	perToken := big.NewRat(1, 1_000_000)
+	prices := make(map[string]modelPrices, len(config.Models))
+	for model, pricing := range config.Models {
+		input, err := parseUSD(pricing.Input)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: invalid input price: %w", model, err)
+		}
+		output, err := parseUSD(pricing.Output)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: invalid output price: %w", model, err)
+		}
+		maxTokens := pricing.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = DefaultMaxTokens
+		}
+		prices[model] = modelPrices{input: input.Mul(input, perToken), output: output.Mul(output, perToken), maxTokens: maxTokens}
+	}
+	return &Gateway{config: config, prices: prices}, nil
+}
+
+// Routes returns the route configuration for ChatCompletionsPath, pricing
+// each request at the most it could cost and binding the payment to the
+// request body
+func (g *Gateway) Routes() x402http.RoutesConfig {
+	accepts := make(x402http.PaymentOptions, len(g.config.Accepts))
+	for i, option := range g.config.Accepts {
+		if option.Scheme == "" {
+			option.Scheme = "upto"
+		}
+		option.Price = x402http.DynamicPriceFunc(g.price)
+		accepts[i] = option
+	}
+	return x402http.RoutesConfig{
+		"POST " + ChatCompletionsPath: {
+			Accepts:         accepts,
+			Description:     "Chat completion, billed per token",
+			MimeType:        "application/json",
+			BindRequestBody: true,
+		},
+	}
+}
+
+// price is the route's DynamicPriceFunc
+func (g *Gateway) price(ctx context.Context, reqCtx x402http.HTTPRequestContext) (x402.Price, error) {
+	adapter, ok := reqCtx.Adapter.(x402http.HTTPBodyAdapter)
+	if !ok {
+		return nil, errors.New("pricing needs the request body")
+	}
+	body, err := adapter.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req, prices, err := g.parse(body)
+	if err != nil {
+		return nil, err
+	}
+	return formatUSD(g.maxCost(req, prices)), nil
+}
+
+// chatRequest is the part of a chat completion request the gateway reads
+type chatRequest struct {
+	Model               string          `json:"model"`
+	Messages            json.RawMessage `json:"messages"`
+	Stream              bool            `json:"stream"`
+	MaxTokens           int             `json:"max_tokens"`
+	MaxCompletionTokens int             `json:"max_completion_tokens"`
+}
+
+// usage is the token usage an upstream reports
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// parse reads a request and its model's prices, applying the model's token cap
+func (g *Gateway) parse(body []byte) (chatRequest, modelPrices, error) {
+	var req chatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return req, modelPrices{}, fmt.Errorf("invalid chat completion request: %w", err)
+	}
+	prices, ok := g.prices[req.Model]
+	if !ok {
+		return req, modelPrices{}, fmt.Errorf("model %q is not available", req.Model)
+	}
+	if req.MaxCompletionTokens > 0 {
+		req.MaxTokens = req.MaxCompletionTokens
+	}
+	if req.MaxTokens <= 0 || req.MaxTokens > prices.maxTokens {
+		req.MaxTokens = prices.maxTokens
+	}
+	return req, prices, nil
+}
+
+// estimatePromptTokens overestimates a prompt's tokens from its size.
+// Tokens average about four bytes of English; three leaves headroom.
+func estimatePromptTokens(messages json.RawMessage) int {
+	return (len(messages) + 2) / 3
+}
+
+// maxCost is the most a request can cost, in USD
+func (g *Gateway) maxCost(req chatRequest, prices modelPrices) *big.Rat {
+	return cost(prices, usage{PromptTokens: estimatePromptTokens(req.Messages), CompletionTokens: req.MaxTokens})
+}
+
+// cost prices token usage in USD
+func cost(prices modelPrices, used usage) *big.Rat {
+	input := new(big.Rat).Mul(prices.input, big.NewRat(int64(used.PromptTokens), 1))
+	output := new(big.Rat).Mul(prices.output, big.NewRat(int64(used.CompletionTokens), 1))
+	return input.Add(input, output)
+}
+
+// Handler serves ChatCompletionsPath through server, which must have been
+// created with Routes and initialized
+func (g *Gateway) Handler(server *x402http.HTTPServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "use POST")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+		adapter := &requestAdapter{r: r}
+		body, err := adapter.GetBody()
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", "request body too large")
+			return
+		}
+		req, prices, err := g.parse(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		result := server.ProcessHTTPRequest(ctx, x402http.HTTPRequestContext{
+			Adapter: adapter,
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}, nil)
+		switch result.Type {
+		case x402http.ResultPaymentError:
+			writeInstructions(w, result.Response)
+			return
+		case x402http.ResultNoPaymentRequired:
+			g.proxy(w, r, body, req, nil)
+			return
+		}
+
+		settle := func(used usage) (*x402http.ProcessSettleResult, bool) {
+			settleCtx := x402.ContextWithFacilitator(x402http.ContextWithTenant(ctx, result.Tenant), result.Facilitator)
+			amount := settlementAmount(result.PaymentRequirements.Amount, cost(prices, used), g.maxCost(req, prices))
+			if amount == "0" {
+				server.ReleasePayment(settleCtx, *result.PaymentPayload)
+				return nil, true
+			}
+			settlement := server.ProcessSettlementAmount(settleCtx, *result.PaymentPayload, *result.PaymentRequirements, amount)
+			return settlement, settlement.Success
+		}
+		if !g.proxy(w, r, body, req, settle) {
+			server.ReleasePayment(ctx, *result.PaymentPayload)
+		}
+	})
+}
+
+// settleFunc settles a request's usage
+type settleFunc func(used usage) (*x402http.ProcessSettleResult, bool)
+
+// proxy forwards a request upstream and relays the response, settling its
+// usage with settle (nil for free requests). It reports whether the upstream
+// served the request.
+func (g *Gateway) proxy(w http.ResponseWriter, r *http.Request, body []byte, req chatRequest, settle settleFunc) bool {
+	upstreamBody, err := upstreamRequest(body, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return false
+	}
+	upstreamReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, g.config.Upstream+"/chat/completions", bytes.NewReader(upstreamBody))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "api_error", err.Error())
+		return false
+	}
+	upstreamReq.Header.Set("Content-Type", "application/json")
+	if g.config.APIKey != "" {
+		upstreamReq.Header.Set("Authorization", "Bearer "+g.config.APIKey)
+	}
+
+	resp, err := g.config.Client.Do(upstreamReq)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "api_error", "upstream unavailable")
+		return false
+	}
+	defer resp.Body.Close()
+
+	// Upstream errors are relayed unbilled
+	if resp.StatusCode >= 400 {
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+		return false
+	}
+
+	if req.Stream {
+		g.relayStream(w, resp, req, settle)
+		return true
+	}
+	g.relay(w, resp, req, settle)
+	return true
+}
+
+// relay relays a complete response, settling before it is sent
+func (g *Gateway) relay(w http.ResponseWriter, resp *http.Response, req chatRequest, settle settleFunc) {
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "api_error", "upstream response interrupted")
+		return
+	}
+	if settle != nil {
+		var completion struct {
+			Usage   *usage `json:"usage"`
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		_ = json.Unmarshal(responseBody, &completion)
+		used := usage{}
+		if completion.Usage != nil {
+			used = *completion.Usage
+		} else {
+			var content strings.Builder
+			for _, choice := range completion.Choices {
+				content.WriteString(choice.Message.Content)
+			}
+			used = estimateUsage(req, content.String())
+		}
+
+		settlement, ok := settle(used)
+		if !ok {
+			writeError(w, http.StatusPaymentRequired, "payment_error", "settlement failed: "+settlement.ErrorReason)
+			return
+		}
+		if settlement != nil {
+			for key, value := range settlement.Headers {
+				w.Header().Set(key, value)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(responseBody)
+}
+
+// relayStream relays a server-sent event stream as it arrives, counting the
+// tokens, and settles once it ends. The settlement is sent as trailers.
+func (g *Gateway) relayStream(w http.ResponseWriter, resp *http.Response, req chatRequest, settle settleFunc) {
+	if settle != nil {
+		w.Header().Set("Trailer", "PAYMENT-RESPONSE, "+SettlementErrorTrailer)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(resp.StatusCode)
+	flusher, _ := w.(http.Flusher)
+
+	var reported *usage
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if data, ok := strings.CutPrefix(line, "data:"); ok && strings.TrimSpace(data) != "[DONE]" {
+			var chunk struct {
+				Usage   *usage `json:"usage"`
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+			}
+			if json.Unmarshal([]byte(data), &chunk) == nil {
+				if chunk.Usage != nil {
+					reported = chunk.Usage
+				}
+				for _, choice := range chunk.Choices {
+					content.WriteString(choice.Delta.Content)
+				}
+			}
+		}
+		_, _ = io.WriteString(w, line+"\n")
+		if line == "" && flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if settle == nil {
+		return
+	}
+	used := estimateUsage(req, content.String())
+	if reported != nil {
+		used = *reported
+	}
+	settlement, ok := settle(used)
+	switch {
+	case !ok:
+		w.Header().Set(SettlementErrorTrailer, settlement.ErrorReason)
+	case settlement != nil:
+		for key, value := range settlement.Headers {
+			w.Header().Set(key, value)
+		}
+	}
+}
+
+// estimateUsage estimates usage when the upstream reports none
+func estimateUsage(req chatRequest, completion string) usage {
+	return usage{
+		PromptTokens:     estimatePromptTokens(req.Messages),
+		CompletionTokens: min((len(completion)+3)/4, req.MaxTokens),
+	}
+}
+
+// upstreamRequest rewrites a request for the upstream: completions are capped
+// at the authorized tokens, and streams are asked to report usage
+func upstreamRequest(body []byte, req chatRequest) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["max_completion_tokens"]; ok {
+		fields["max_completion_tokens"] = req.MaxTokens
+	} else {
+		fields["max_tokens"] = req.MaxTokens
+	}
+	if req.Stream {
+		options, _ := fields["stream_options"].(map[string]interface{})
+		if options == nil {
+			options = map[string]interface{}{}
+		}
+		options["include_usage"] = true
+		fields["stream_options"] = options
+	}
+	return json.Marshal(fields)
+}
+
+// settlementAmount scales the authorized amount (atomic units) by the share
+// of the maximum cost that was used, rounding up and capping at the amount
+func settlementAmount(authorized string, used, maxCost *big.Rat) string {
+	amount, ok := new(big.Int).SetString(authorized, 10)
+	if !ok || used.Sign() <= 0 || maxCost.Sign() <= 0 {
+		return "0"
+	}
+	if used.Cmp(maxCost) >= 0 {
+		return amount.String()
+	}
+	share := new(big.Rat).Mul(new(big.Rat).SetInt(amount), used)
+	share.Quo(share, maxCost)
+	settled := new(big.Int).Quo(share.Num(), share.Denom())
+	if new(big.Rat).SetInt(settled).Cmp(share) < 0 {
+		settled.Add(settled, big.NewInt(1))
+	}
+	return settled.String()
+}
+
+// parseUSD parses a USD price like "$0.15"
+func parseUSD(price string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimPrefix(strings.TrimSpace(price), "$"))
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid USD price %q", price)
+	}
+	return value, nil
+}
+
+// formatUSD formats a cost as a USD price, rounded up to the micro-dollar
+func formatUSD(cost *big.Rat) string {
+	micros := new(big.Rat).Mul(cost, big.NewRat(1_000_000, 1))
+	whole := new(big.Int).Quo(micros.Num(), micros.Denom())
+	if new(big.Rat).SetInt(whole).Cmp(micros) < 0 {
+		whole.Add(whole, big.NewInt(1))
+	}
+	if whole.Sign() == 0 {
+		whole.SetInt64(1)
+	}
+	dollars, rest := new(big.Int).QuoRem(whole, big.NewInt(1_000_000), new(big.Int))
+	return fmt.Sprintf("$%s.%06d", dollars, rest.Int64())
+}
+
+// writeError writes an OpenAI-style error
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"type": errorType, "message": message},
+	})
+}
+
+// writeInstructions writes a payment error response
+func writeInstructions(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	switch body := response.Body.(type) {
+	case []byte:
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(body)
+	case string:
+		w.WriteHeader(response.Status)
+		_, _ = io.WriteString(w, body)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.Status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+// requestAdapter adapts a net/http request for the x402 HTTP server
+type requestAdapter struct {
+	r *http.Request
+}
+
+func (a *requestAdapter) GetHeader(name string) string { return a.r.Header.Get(name) }
+func (a *requestAdapter) GetMethod() string            { return a.r.Method }
+func (a *requestAdapter) GetPath() string              { return a.r.URL.Path }
+func (a *requestAdapter) GetAcceptHeader() string      { return a.r.Header.Get("Accept") }
+func (a *requestAdapter) GetUserAgent() string         { return a.r.UserAgent() }
+func (a *requestAdapter) GetClientIP() string          { return x402http.RemoteIP(a.r.RemoteAddr) }
+
+func (a *requestAdapter) GetURL() string {
+	scheme := "http"
+	if a.r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + a.r.Host + a.r.URL.Path
+}
+
+// GetBody reads the request body and restores it
+func (a *requestAdapter) GetBody() ([]byte, error) {
+	if a.r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(a.r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = a.r.Body.Close()
+	a.r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,229 @@
+package llmgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/http/headers"
+	"github.com/coinbase/x402/go/types"
+)
+
+const testNetwork x402.Network = "eip155:8453"
+
+// uptoServer prices USD in six-decimal atomic units
+type uptoServer struct{}
+
+func (uptoServer) Scheme() string { return "upto" }
+
+func (uptoServer) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	usd, err := parseUSD(price.(string))
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
+	atomic := new(big.Rat).Mul(usd, big.NewRat(1_000_000, 1))
+	return x402.AssetAmount{Asset: "0xusdc", Amount: new(big.Int).Quo(atomic.Num(), atomic.Denom()).String()}, nil
+}
+
+func (uptoServer) EnhancePaymentRequirements(ctx context.Context, requirements types.PaymentRequirements, kind types.SupportedKind, extensions []string) (types.PaymentRequirements, error) {
+	return requirements, nil
+}
+
+// uptoClient signs nothing; the facilitator accepts any payload
+type uptoClient struct{}
+
+func (uptoClient) Scheme() string { return "upto" }
+
+func (uptoClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	return types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"payer": "0xpayer"}}, nil
+}
+
+// meteredFacilitator records the amounts it settles
+type meteredFacilitator struct {
+	mu      sync.Mutex
+	settled []string
+}
+
+func (f *meteredFacilitator) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (f *meteredFacilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	var requirements types.PaymentRequirements
+	_ = json.Unmarshal(requirementsBytes, &requirements)
+	return f.SettlePartial(ctx, payloadBytes, requirementsBytes, requirements.Amount)
+}
+
+func (f *meteredFacilitator) SettlePartial(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*x402.SettleResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settled = append(f.settled, amountToSettle)
+	return &x402.SettleResponse{Success: true, Transaction: "0xsettled" + amountToSettle, Network: testNetwork, Payer: "0xpayer"}, nil
+}
+
+func (f *meteredFacilitator) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return x402.SupportedResponse{Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "upto", Network: string(testNetwork)}}}, nil
+}
+
+// fakeUpstream answers chat completions, streamed or not, reporting usage
+func fakeUpstream(t *testing.T, received *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("Unexpected upstream request %s (%s)", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(received)
+		if (*received)["stream"] == true {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":100}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Hello"}}],"usage":{"prompt_tokens":10,"completion_tokens":100}}`)
+	}))
+}
+
+func newTestGateway(t *testing.T, upstream string) (*httptest.Server, *meteredFacilitator) {
+	t.Helper()
+	gateway, err := New(Config{
+		Upstream: upstream + "/v1",
+		APIKey:   "sk-test",
+		Models:   map[string]ModelPricing{"mini": {Input: "$1", Output: "$10", MaxTokens: 1000}},
+		Accepts:  x402http.PaymentOptions{{Network: testNetwork, PayTo: "0xrecipient"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+	facilitator := &meteredFacilitator{}
+	server := x402http.Newx402HTTPResourceServer(gateway.Routes(), x402.WithFacilitatorClient(facilitator), x402.WithSchemeServer(testNetwork, uptoServer{}))
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(ChatCompletionsPath, gateway.Handler(server))
+	return httptest.NewServer(mux), facilitator
+}
+
+func payingClient() *http.Client {
+	client := x402.Newx402Client()
+	client.Register("eip155:*", uptoClient{})
+	return x402http.WrapHTTPClientWithPayment(&http.Client{}, x402http.Newx402HTTPClient(client))
+}
+
+// chatBody has 30 bytes of messages, estimated as 10 prompt tokens
+func chatBody(stream bool) string {
+	return fmt.Sprintf(`{"model":"mini","messages":[{"role":"user","content":""}],"max_tokens":5000,"stream":%t}`, stream)
+}
+
+func TestGatewayBillsUsedTokens(t *testing.T) {
+	var received map[string]interface{}
+	upstream := fakeUpstream(t, &received)
+	defer upstream.Close()
+	gateway, facilitator := newTestGateway(t, upstream.URL)
+	defer gateway.Close()
+
+	// Unpaid requests are quoted the most they could cost: 10 prompt tokens
+	// at $1/M plus the capped 1000 output tokens at $10/M = $0.01001
+	resp, err := http.Post(gateway.URL+ChatCompletionsPath, "application/json", strings.NewReader(chatBody(false)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	required, err := headers.DecodePaymentRequired(resp.Header.Get("PAYMENT-REQUIRED"), "")
+	if resp.StatusCode != http.StatusPaymentRequired || err != nil || required.Accepts[0].Amount != "10010" || required.Accepts[0].Scheme != "upto" {
+		t.Fatalf("Expected a 402 for 10010 under upto, got %d %+v (%v)", resp.StatusCode, required.Accepts, err)
+	}
+
+	// Paid: 100 output tokens cost $0.00101, so 1010 of the 10010 authorized settle
+	resp, err = payingClient().Post(gateway.URL+ChatCompletionsPath, "application/json", strings.NewReader(chatBody(false)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte("Hello")) {
+		t.Fatalf("Expected the completion, got %d %s", resp.StatusCode, body)
+	}
+	if settlement, err := headers.DecodePaymentResponse(resp.Header.Get("PAYMENT-RESPONSE")); err != nil || settlement.Transaction != "0xsettled1010" {
+		t.Errorf("Expected a settlement for 1010, got %+v (%v)", settlement, err)
+	}
+	if received["max_tokens"] != float64(1000) {
+		t.Errorf("Expected the upstream to be capped at 1000 tokens, got %v", received["max_tokens"])
+	}
+	if len(facilitator.settled) != 1 || facilitator.settled[0] != "1010" {
+		t.Errorf("Expected 1010 settled, got %v", facilitator.settled)
+	}
+}
+
+func TestGatewayStreamsAndSettlesInTrailers(t *testing.T) {
+	var received map[string]interface{}
+	upstream := fakeUpstream(t, &received)
+	defer upstream.Close()
+	gateway, facilitator := newTestGateway(t, upstream.URL)
+	defer gateway.Close()
+
+	resp, err := payingClient().Post(gateway.URL+ChatCompletionsPath, "application/json", strings.NewReader(chatBody(true)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"content":"lo"`)) || !bytes.Contains(body, []byte("[DONE]")) {
+		t.Fatalf("Expected the relayed stream, got %d %s", resp.StatusCode, body)
+	}
+	if options, _ := received["stream_options"].(map[string]interface{}); options["include_usage"] != true {
+		t.Errorf("Expected the upstream to be asked for usage, got %v", received["stream_options"])
+	}
+	if settlement, err := headers.DecodePaymentResponse(resp.Trailer.Get("PAYMENT-RESPONSE")); err != nil || settlement.Transaction != "0xsettled1010" {
+		t.Errorf("Expected the settlement in the trailer, got %+v (%v)", settlement, err)
+	}
+	if len(facilitator.settled) != 1 || facilitator.settled[0] != "1010" {
+		t.Errorf("Expected 1010 settled, got %v", facilitator.settled)
+	}
+}
+
+func TestGatewayRejectsUnknownModels(t *testing.T) {
+	gateway, facilitator := newTestGateway(t, "http://127.0.0.1:1")
+	defer gateway.Close()
+
+	resp, err := payingClient().Post(gateway.URL+ChatCompletionsPath, "application/json", strings.NewReader(`{"model":"gpt-9","messages":[]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || len(facilitator.settled) != 0 {
+		t.Errorf("Expected an unbilled 400, got %d with %v settled", resp.StatusCode, facilitator.settled)
+	}
+}
+
+func TestSettlementAmount(t *testing.T) {
+	cases := []struct {
+		used, max *big.Rat
+		want      string
+	}{
+		{big.NewRat(1, 4), big.NewRat(1, 1), "250"},
+		{big.NewRat(1, 3), big.NewRat(1, 1), "334"}, // rounds up
+		{big.NewRat(2, 1), big.NewRat(1, 1), "1000"},
+		{big.NewRat(0, 1), big.NewRat(1, 1), "0"},
+	}
+	for _, c := range cases {
+		if got := settlementAmount("1000", c.used, c.max); got != c.want {
+			t.Errorf("settlementAmount(1000, %s, %s) = %s, want %s", c.used, c.max, got, c.want)
+		}
+	}
+	if got := formatUSD(big.NewRat(1, 3)); got != "$0.333334" {
+		t.Errorf("Expected prices rounded up to the micro-dollar, got %s", got)
+	}
+}
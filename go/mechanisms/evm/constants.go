@@ -19,6 +19,13 @@ const (
 	// Permit2 function names
 	FunctionSettle = "settle"
 
+	// Multicall3 function names
+	FunctionAggregate3 = "aggregate3"
+
+	// Token restriction function names (USDC-style pausable and blacklistable tokens)
+	FunctionPaused        = "paused"
+	FunctionIsBlacklisted = "isBlacklisted"
+
 	// Transaction status
 	TxStatusSuccess = 1
 	TxStatusFailed  = 0
@@ -49,6 +56,10 @@ const (
 	// Vanity address: 0x4020...0002 for easy recognition.
 	X402UptoPermit2ProxyAddress = "0x4020633461b2895a48930Ff97eE8fCdE8E520002"
 
+	// Multicall3Address is the canonical Multicall3 contract, deployed at the
+	// same address on most EVM chains. Used to batch settlements.
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
 	// Permit2DeadlineBuffer is the time buffer (in seconds) added when checking
 	// deadline expiration to account for block propagation time.
 	Permit2DeadlineBuffer = 6
@@ -210,6 +221,74 @@ var (
 		}
 	]`)
 
+	// ERC20TransferABI for plain token transfers (e.g. sweeping to cold storage)
+	ERC20TransferABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "to", "type": "address"},
+				{"name": "amount", "type": "uint256"}
+			],
+			"name": "transfer",
+			"outputs": [{"name": "", "type": "bool"}],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// PausedABI for checking whether a pausable token is paused
+	PausedABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "paused",
+			"outputs": [{"name": "", "type": "bool"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// IsBlacklistedABI for checking whether an account is blacklisted (USDC)
+	IsBlacklistedABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "account", "type": "address"}
+			],
+			"name": "isBlacklisted",
+			"outputs": [{"name": "", "type": "bool"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// Multicall3Aggregate3ABI for batching calls through Multicall3
+	Multicall3Aggregate3ABI = []byte(`[
+		{
+			"type": "function",
+			"name": "aggregate3",
+			"inputs": [
+				{
+					"name": "calls",
+					"type": "tuple[]",
+					"components": [
+						{"name": "target", "type": "address"},
+						{"name": "allowFailure", "type": "bool"},
+						{"name": "callData", "type": "bytes"}
+					]
+				}
+			],
+			"outputs": [
+				{
+					"name": "returnData",
+					"type": "tuple[]",
+					"components": [
+						{"name": "success", "type": "bool"},
+						{"name": "returnData", "type": "bytes"}
+					]
+				}
+			],
+			"stateMutability": "payable"
+		}
+	]`)
+
 	// X402ExactPermit2ProxySettleABI for calling settle on x402ExactPermit2Proxy
 	X402ExactPermit2ProxySettleABI = []byte(`[
 		{
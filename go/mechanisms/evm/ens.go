@@ -0,0 +1,118 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress is the ENS registry, deployed at the same address on
+// Ethereum mainnet and its testnets
+const ENSRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+
+// ensRegistryABI is the minimal ABI for the registry's resolver lookup
+const ensRegistryABI = `[{
+	"inputs": [{"type": "bytes32", "name": "node"}],
+	"name": "resolver",
+	"outputs": [{"type": "address", "name": ""}],
+	"stateMutability": "view",
+	"type": "function"
+}]`
+
+// ensResolverABI is the minimal ABI for a resolver's address lookup
+const ensResolverABI = `[{
+	"inputs": [{"type": "bytes32", "name": "node"}],
+	"name": "addr",
+	"outputs": [{"type": "address", "name": ""}],
+	"stateMutability": "view",
+	"type": "function"
+}]`
+
+// ContractReader reads data from smart contracts. FacilitatorEvmSigner satisfies it.
+type ContractReader interface {
+	ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error)
+}
+
+// ENSResolver resolves ENS names (e.g. "merchant.eth") to addresses.
+// ENS lives on Ethereum mainnet, so the reader should be connected to
+// mainnet; the resolved address is used on whichever EVM network the
+// payTo is configured for. It satisfies x402http.NameResolver.
+type ENSResolver struct {
+	reader   ContractReader
+	registry string
+}
+
+// NewENSResolver creates a resolver reading the ENS registry through reader
+func NewENSResolver(reader ContractReader) *ENSResolver {
+	return &ENSResolver{reader: reader, registry: ENSRegistryAddress}
+}
+
+// ResolveName returns the address an ENS name points to
+func (r *ENSResolver) ResolveName(ctx context.Context, network x402.Network, name string) (string, error) {
+	node := ENSNamehash(name)
+
+	resolver, err := r.readAddress(ctx, r.registry, ensRegistryABI, "resolver", node)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up resolver for %s: %w", name, err)
+	}
+	if resolver == (common.Address{}) {
+		return "", fmt.Errorf("%s has no resolver", name)
+	}
+
+	address, err := r.readAddress(ctx, resolver.Hex(), ensResolverABI, "addr", node)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve address for %s: %w", name, err)
+	}
+	if address == (common.Address{}) {
+		return "", fmt.Errorf("%s has no address", name)
+	}
+	return address.Hex(), nil
+}
+
+// readAddress calls a view function returning a single address
+func (r *ENSResolver) readAddress(ctx context.Context, contract string, abi string, functionName string, node [32]byte) (common.Address, error) {
+	result, err := r.reader.ReadContract(ctx, contract, []byte(abi), functionName, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	// ReadContract returns interface{}, so handle the types implementations return
+	switch value := result.(type) {
+	case common.Address:
+		return value, nil
+	case string:
+		if !IsValidAddress(value) {
+			return common.Address{}, fmt.Errorf("invalid address returned from %s: %s", functionName, value)
+		}
+		return common.HexToAddress(value), nil
+	case []byte:
+		if len(value) != common.AddressLength {
+			return common.Address{}, fmt.Errorf("invalid address returned from %s: %d bytes", functionName, len(value))
+		}
+		return common.BytesToAddress(value), nil
+	default:
+		return common.Address{}, errors.New("invalid return type from " + functionName + ": expected address")
+	}
+}
+
+// ENSNamehash computes the ENS namehash of a name (EIP-137). Names are
+// lowercased; full ENSIP-15 normalization is left to the caller.
+func ENSNamehash(name string) [32]byte {
+	var node [32]byte
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		copy(node[:], crypto.Keccak256(node[:], labelHash))
+	}
+	return node
+}
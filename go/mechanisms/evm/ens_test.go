@@ -0,0 +1,53 @@
+package evm
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type mockContractReader struct {
+	results map[string]interface{} // keyed by contract address and function
+}
+
+func (m *mockContractReader) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	return m.results[address+"."+functionName], nil
+}
+
+func TestENSNamehash(t *testing.T) {
+	tests := map[string]string{
+		"":            "0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":         "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"vitalik.eth": "ee6c4522aab0003e8d14cd40a6af439055fd2577951148c14b6cea9a53475835",
+		"Vitalik.ETH": "ee6c4522aab0003e8d14cd40a6af439055fd2577951148c14b6cea9a53475835",
+	}
+	for name, expected := range tests {
+		node := ENSNamehash(name)
+		if hex.EncodeToString(node[:]) != expected {
+			t.Errorf("namehash(%q) = %x, expected %s", name, node, expected)
+		}
+	}
+}
+
+func TestENSResolver(t *testing.T) {
+	resolverAddress := common.HexToAddress("0x4976fb03C32e5B8cfe2b6cCB31c09Ba78EBaBa41")
+	reader := &mockContractReader{results: map[string]interface{}{
+		ENSRegistryAddress + ".resolver": resolverAddress,
+		resolverAddress.Hex() + ".addr":  "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+	}}
+
+	address, err := NewENSResolver(reader).ResolveName(context.Background(), "eip155:8453", "vitalik.eth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if address != "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045" {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	reader.results[ENSRegistryAddress+".resolver"] = common.Address{}
+	if _, err := NewENSResolver(reader).ResolveName(context.Background(), "eip155:1", "unregistered.eth"); err == nil {
+		t.Error("expected error for a name without a resolver")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"time"
 
 	"github.com/coinbase/x402/go/mechanisms/evm"
 	"github.com/coinbase/x402/go/types"
@@ -13,6 +12,7 @@ import (
 // ExactEvmScheme implements the SchemeNetworkClient interface for EVM exact payments (V2)
 type ExactEvmScheme struct {
 	signer evm.ClientEvmSigner
+	window evm.ValidityWindowConfig
 }
 
 // NewExactEvmScheme creates a new ExactEvmScheme
@@ -27,6 +27,14 @@ func (c *ExactEvmScheme) Scheme() string {
 	return evm.SchemeExact
 }
 
+// SetValidityWindow sets the validAfter buffer and validity duration of
+// EIP-3009 authorizations (default 30 seconds and one hour). The window never
+// outlasts the requirements' MaxTimeoutSeconds.
+func (c *ExactEvmScheme) SetValidityWindow(config evm.ValidityWindowConfig) *ExactEvmScheme {
+	c.window = config
+	return c
+}
+
 // CreatePaymentPayload creates a V2 payment payload for the exact scheme.
 // Routes to EIP-3009 or Permit2 based on requirements.Extra["assetTransferMethod"].
 // Defaults to EIP-3009 for backward compatibility.
@@ -34,16 +42,14 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	ctx context.Context,
 	requirements types.PaymentRequirements,
 ) (types.PaymentPayload, error) {
-	// Check asset transfer method
-	assetTransferMethod := evm.AssetTransferMethodEIP3009 // default
-	if requirements.Extra != nil {
-		if method, ok := requirements.Extra["assetTransferMethod"].(string); ok {
-			assetTransferMethod = evm.AssetTransferMethod(method)
-		}
+	// Check asset transfer method (default: EIP-3009)
+	var transfer evm.ExtraAssetTransfer
+	if err := types.UnmarshalExtra(requirements.Extra, &transfer); err != nil {
+		return types.PaymentPayload{}, err
 	}
 
 	// Route based on method
-	if assetTransferMethod == evm.AssetTransferMethodPermit2 {
+	if transfer.AssetTransferMethod == evm.AssetTransferMethodPermit2 {
 		return CreatePermit2Payload(ctx, c.signer, requirements)
 	}
 
@@ -82,20 +88,15 @@ func (c *ExactEvmScheme) createEIP3009Payload(
 		return types.PaymentPayload{}, err
 	}
 
-	// V2 specific: No buffer on validAfter (can use immediately)
-	validAfter, validBefore := evm.CreateValidityWindow(time.Hour)
+	// Backdate validAfter for clock skew; validBefore ends with the server's timeout
+	validAfter, validBefore := c.window.Create(requirements.MaxTimeoutSeconds)
 
-	// Extract extra fields for EIP-3009
-	tokenName := assetInfo.Name
-	tokenVersion := assetInfo.Version
-	if requirements.Extra != nil {
-		if name, ok := requirements.Extra["name"].(string); ok {
-			tokenName = name
-		}
-		if ver, ok := requirements.Extra["version"].(string); ok {
-			tokenVersion = ver
-		}
+	// Extract extra fields for EIP-3009, defaulting to the asset's domain
+	domain := evm.ExtraEIP712{Name: assetInfo.Name, Version: assetInfo.Version}
+	if err := types.UnmarshalExtra(requirements.Extra, &domain); err != nil {
+		return types.PaymentPayload{}, err
 	}
+	tokenName, tokenVersion := domain.Name, domain.Version
 
 	// Create authorization
 	authorization := evm.ExactEIP3009Authorization{
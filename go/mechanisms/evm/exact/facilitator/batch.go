@@ -0,0 +1,224 @@
+package facilitator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/types"
+)
+
+// call3 is one call in a Multicall3 aggregate3 batch
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// BatchKey implements x402.SchemeNetworkBatchSettler. EIP-3009 payments share
+// a batch with other payments from the same payer in the same asset, and must
+// settle before their authorization's validBefore. Permit2 payments and
+// undeployed smart wallets settle alone.
+func (f *ExactEvmScheme) BatchKey(payload types.PaymentPayload, requirements types.PaymentRequirements) (string, time.Time, bool) {
+	if evm.IsPermit2Payload(payload.Payload) {
+		return "", time.Time{}, false
+	}
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
+	if err != nil || evm.IsERC6492Signature(signatureBytes) {
+		return "", time.Time{}, false
+	}
+	validBefore, ok := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
+	if !ok || !validBefore.IsInt64() {
+		return "", time.Time{}, false
+	}
+
+	key := strings.ToLower(requirements.Asset) + ":" + strings.ToLower(evmPayload.Authorization.From)
+	return key, time.Unix(validBefore.Int64(), 0), true
+}
+
+// SettleBatch settles EIP-3009 payments from one payer in a single Multicall3
+// (or ExactEvmSchemeConfig.BatchContract) transaction, implementing
+// x402.SchemeNetworkBatchSettler. Payments that fail verification are
+// rejected individually; the rest succeed or fail together, and each
+// response reports its share of the transaction's cost.
+func (f *ExactEvmScheme) SettleBatch(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) []x402.BatchSettlement {
+	results := make([]x402.BatchSettlement, len(payloads))
+	var calls []call3
+	var members []int
+	var payer string
+	total := new(big.Int)
+	var balance *big.Int
+	seen := make(map[string]bool)
+
+	for i := range payloads {
+		call, evmPayload, err := f.batchCall(ctx, payloads[i], requirements[i])
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		network := x402.Network(requirements[i].Network)
+		nonce := strings.ToLower(evmPayload.Authorization.Nonce)
+		if seen[nonce] {
+			results[i].Err = x402.NewSettleError(ErrNonceAlreadyUsed, evmPayload.Authorization.From, network, "", "nonce repeated in batch")
+			continue
+		}
+
+		// Each payment was checked against the balance alone; check the sum
+		// too, since one short payment would revert the whole batch
+		if balance == nil {
+			if balance, err = f.signer.GetBalance(ctx, evmPayload.Authorization.From, call.Target.Hex()); err != nil {
+				results[i].Err = x402.NewSettleError(ErrFailedToGetBalance, evmPayload.Authorization.From, network, "", err.Error())
+				balance = nil
+				continue
+			}
+		}
+		value, _ := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
+		if new(big.Int).Add(total, value).Cmp(balance) > 0 {
+			results[i].Err = x402.NewSettleError(ErrInsufficientBalance, evmPayload.Authorization.From, network, "", fmt.Sprintf("insufficient balance for batch: %s < %s", balance, new(big.Int).Add(total, value)))
+			continue
+		}
+		seen[nonce] = true
+		total.Add(total, value)
+		payer = evmPayload.Authorization.From
+		calls = append(calls, call)
+		members = append(members, i)
+	}
+	if len(members) == 0 {
+		return results
+	}
+
+	first := members[0]
+	network := x402.Network(payloads[first].Accepted.Network)
+	signer := f.withJournal(f.settlementSigner(network), network, payloads[first].Payload, requirements[first].Asset, total.String(), batchAttribution(requirements, members))
+	var response *x402.SettleResponse
+	var err error
+	if f.config.ReceiptTimeout <= 0 {
+		response, err = f.sendBatch(ctx, signer, network, payer, calls)
+	} else {
+		timed := &receiptTimeoutSigner{FacilitatorEvmSigner: signer, timeout: f.config.ReceiptTimeout}
+		response, err = f.sendBatch(ctx, timed, network, payer, calls)
+		response, err = timed.pendingResult(network, response, err)
+	}
+
+	for _, i := range members {
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		shared := *response
+		shared.Cost = shareCost(response.Cost, len(members))
+		results[i].Response = &shared
+	}
+	return results
+}
+
+// shareCost splits a batch transaction's cost evenly across its payments
+func shareCost(cost *x402.SettlementCost, payments int) *x402.SettlementCost {
+	if cost == nil {
+		return nil
+	}
+	share := &x402.SettlementCost{GasUsed: cost.GasUsed / uint64(payments), GasPrice: cost.GasPrice}
+	if fee, ok := new(big.Int).SetString(cost.Fee, 10); ok {
+		share.Fee = fee.Div(fee, big.NewInt(int64(payments))).String()
+	}
+	return share
+}
+
+// batchCall verifies an EIP-3009 payment and encodes its transferWithAuthorization
+// call for the batch
+func (f *ExactEvmScheme) batchCall(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (call3, *evm.ExactEIP3009Payload, error) {
+	network := x402.Network(payload.Accepted.Network)
+
+	verifyResp, err := f.verifyEIP3009(ctx, payload, requirements)
+	if err != nil {
+		ve := &x402.VerifyError{}
+		if errors.As(err, &ve) {
+			return call3{}, nil, x402.NewSettleError(ve.InvalidReason, ve.Payer, network, "", ve.InvalidMessage)
+		}
+		return call3{}, nil, x402.NewSettleError(ErrVerificationFailed, "", network, "", err.Error())
+	}
+
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return call3{}, nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", err.Error())
+	}
+	assetInfo, err := evm.GetAssetInfo(string(requirements.Network), requirements.Asset)
+	if err != nil {
+		return call3{}, nil, x402.NewSettleError(ErrFailedToGetAssetInfo, verifyResp.Payer, network, "", err.Error())
+	}
+	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
+	if err != nil {
+		return call3{}, nil, x402.NewSettleError(ErrInvalidSignatureFormat, verifyResp.Payer, network, "", err.Error())
+	}
+
+	contractABI, args, err := transferWithAuthorizationCall(evmPayload.Authorization, signatureBytes)
+	if err == nil {
+		var parsed abi.ABI
+		if parsed, err = abi.JSON(bytes.NewReader(contractABI)); err == nil {
+			var callData []byte
+			if callData, err = parsed.Pack(evm.FunctionTransferWithAuthorization, args...); err == nil {
+				return call3{Target: common.HexToAddress(assetInfo.Address), CallData: callData}, evmPayload, nil
+			}
+		}
+	}
+	return call3{}, nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", err.Error())
+}
+
+// sendBatch sends the aggregated calls in one transaction and waits for it
+func (f *ExactEvmScheme) sendBatch(ctx context.Context, signer evm.FacilitatorEvmSigner, network x402.Network, payer string, calls []call3) (*x402.SettleResponse, error) {
+	contract := f.config.BatchContract
+	if contract == "" {
+		contract = evm.Multicall3Address
+	}
+
+	txHash, err := signer.WriteContract(ctx, contract, evm.Multicall3Aggregate3ABI, evm.FunctionAggregate3, calls)
+	if err != nil {
+		reason := ErrFailedToExecuteTransfer
+		if preflightReason, ok := gasPreflightReason(err); ok {
+			reason = preflightReason
+		}
+		return nil, x402.NewSettleError(reason, payer, network, "", err.Error())
+	}
+
+	receipt, err := signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrFailedToGetReceipt, payer, network, txHash, err.Error())
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return nil, x402.NewSettleError(ErrTransactionFailed, payer, network, txHash, fmt.Sprintf("batch of %d payments reverted", len(calls)))
+	}
+
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: txHash,
+		Network:     network,
+		Payer:       payer,
+		Cost:        receipt.Cost(),
+	}, nil
+}
+
+// batchAttribution returns the attribution shared by every settled member of a
+// batch, or nil when they differ, since one journaled transaction covers them all
+func batchAttribution(requirements []types.PaymentRequirements, members []int) map[string]string {
+	shared := types.Attribution(requirements[members[0]].Extra)
+	for _, i := range members[1:] {
+		if !reflect.DeepEqual(types.Attribution(requirements[i].Extra), shared) {
+			return nil
+		}
+	}
+	return shared
+}
@@ -10,6 +10,7 @@ const (
 	ErrFailedToGetNetworkConfig  = "invalid_exact_evm_failed_to_get_network_config"
 	ErrFailedToGetAssetInfo      = "invalid_exact_evm_failed_to_get_asset_info"
 	ErrRecipientMismatch         = "invalid_exact_evm_recipient_mismatch"
+	ErrInvalidAddress            = "invalid_exact_evm_payload_address"
 	ErrInvalidAuthorizationValue = "invalid_exact_evm_authorization_value"
 	ErrInvalidRequiredAmount     = "invalid_exact_evm_required_amount"
 	ErrInsufficientAmount        = "invalid_exact_evm_insufficient_amount"
@@ -20,6 +21,12 @@ const (
 	ErrInvalidSignatureFormat    = "invalid_exact_evm_signature_format"
 	ErrFailedToVerifySignature   = "invalid_exact_evm_failed_to_verify_signature"
 	ErrInvalidSignature          = "invalid_exact_evm_signature"
+	ErrInvalidRequirementsExtra  = "invalid_exact_evm_requirements_extra"
+
+	// Token restriction verify errors (shared by EIP-3009 and Permit2)
+	ErrTokenPaused          = "invalid_exact_evm_token_paused"
+	ErrPayerBlacklisted     = "invalid_exact_evm_payer_blacklisted"
+	ErrRecipientBlacklisted = "invalid_exact_evm_recipient_blacklisted"
 
 	// EIP-3009 Settle errors
 	ErrVerificationFailed      = "invalid_exact_evm_verification_failed"
@@ -28,6 +35,8 @@ const (
 	ErrFailedToExecuteTransfer = "invalid_exact_evm_failed_to_execute_transfer"
 	ErrFailedToGetReceipt      = "invalid_exact_evm_failed_to_get_receipt"
 	ErrTransactionFailed       = "invalid_exact_evm_transaction_failed"
+	ErrGasEstimationFailed     = "invalid_exact_evm_gas_estimation_failed"
+	ErrGasLimitExceeded        = "invalid_exact_evm_gas_limit_exceeded"
 
 	// Smart wallet errors (shared by EIP-3009 and Permit2)
 	ErrUndeployedSmartWallet       = "invalid_exact_evm_payload_undeployed_smart_wallet"
@@ -0,0 +1,111 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// GasEstimateEvent reports a settlement gas estimate, e.g. to record metrics
+type GasEstimateEvent struct {
+	Network  x402.Network
+	Contract string
+	Function string
+
+	// Gas is the estimated gas (0 when estimation failed)
+	Gas uint64
+
+	// MaxGas is the configured cap (0 when uncapped)
+	MaxGas uint64
+
+	// Err is a *GasPreflightError when the transaction was not sent
+	Err error
+}
+
+// GasEstimateHook receives settlement gas estimates
+type GasEstimateHook func(context.Context, GasEstimateEvent)
+
+// GasPreflightError is returned when a settlement transaction is not sent
+// because gas estimation failed (typically a revert) or exceeded the cap.
+// Reason is ErrGasEstimationFailed or ErrGasLimitExceeded.
+type GasPreflightError struct {
+	Reason string
+	Gas    uint64
+	MaxGas uint64
+	Err    error
+}
+
+func (e *GasPreflightError) Error() string {
+	if e.Reason == ErrGasLimitExceeded {
+		return fmt.Sprintf("%s: estimated gas %d exceeds cap %d", e.Reason, e.Gas, e.MaxGas)
+	}
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *GasPreflightError) Unwrap() error {
+	return e.Err
+}
+
+// gasCheckedSigner estimates the gas of every contract write before sending it
+type gasCheckedSigner struct {
+	evm.FacilitatorEvmSigner
+	network x402.Network
+	maxGas  uint64
+	hook    GasEstimateHook
+}
+
+// settlementSigner returns the signer used to send settlement transactions,
+// wrapped with the gas preflight when ExactEvmSchemeConfig.EstimateGas is set
+func (f *ExactEvmScheme) settlementSigner(network x402.Network) evm.FacilitatorEvmSigner {
+	if !f.config.EstimateGas {
+		return f.signer
+	}
+	return &gasCheckedSigner{
+		FacilitatorEvmSigner: f.signer,
+		network:              network,
+		maxGas:               f.config.MaxSettlementGas,
+		hook:                 f.config.OnGasEstimate,
+	}
+}
+
+// WriteContract sends the transaction only if its gas estimate succeeds and fits the cap
+func (s *gasCheckedSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	gas, err := estimateSettlementGas(ctx, s.FacilitatorEvmSigner, s.maxGas, address, abi, functionName, args...)
+	if s.hook != nil {
+		s.hook(ctx, GasEstimateEvent{Network: s.network, Contract: address, Function: functionName, Gas: gas, MaxGas: s.maxGas, Err: err})
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.FacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+// estimateSettlementGas estimates a contract write with the signer's
+// evm.GasEstimator, returning a *GasPreflightError if estimation fails or the
+// estimate exceeds maxGas (0 means no cap)
+func estimateSettlementGas(ctx context.Context, signer evm.FacilitatorEvmSigner, maxGas uint64, address string, abi []byte, functionName string, args ...interface{}) (uint64, error) {
+	estimator, ok := signer.(evm.GasEstimator)
+	if !ok {
+		return 0, &GasPreflightError{Reason: ErrGasEstimationFailed, MaxGas: maxGas, Err: errors.New("signer does not implement evm.GasEstimator")}
+	}
+	gas, err := estimator.EstimateContractGas(ctx, address, abi, functionName, args...)
+	if err != nil {
+		return 0, &GasPreflightError{Reason: ErrGasEstimationFailed, MaxGas: maxGas, Err: err}
+	}
+	if maxGas > 0 && gas > maxGas {
+		return gas, &GasPreflightError{Reason: ErrGasLimitExceeded, Gas: gas, MaxGas: maxGas}
+	}
+	return gas, nil
+}
+
+// gasPreflightReason returns the error code of a gas preflight failure
+func gasPreflightReason(err error) (string, bool) {
+	var preflightErr *GasPreflightError
+	if errors.As(err, &preflightErr) {
+		return preflightErr.Reason, true
+	}
+	return "", false
+}
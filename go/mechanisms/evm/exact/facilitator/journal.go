@@ -0,0 +1,96 @@
+package facilitator
+
+import (
+	"context"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// journalingSigner records settlement transactions in a journal as soon as
+// they are sent, and resolves them once their receipt is read
+type journalingSigner struct {
+	evm.FacilitatorEvmSigner
+	journal    x402.SettlementJournal
+	settlement x402.SubmittedSettlement
+}
+
+// WriteContract sends the transaction and journals it before returning.
+// Journal errors do not fail settlement, since the transaction is already sent.
+func (s *journalingSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	txHash, err := s.FacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+	if err != nil {
+		return txHash, err
+	}
+	settlement := s.settlement
+	settlement.Transaction = txHash
+	settlement.SubmittedAt = time.Now()
+	_ = s.journal.Submitted(ctx, settlement)
+	return txHash, nil
+}
+
+// WaitForTransactionReceipt resolves the journaled transaction once it is mined
+func (s *journalingSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	receipt, err := s.FacilitatorEvmSigner.WaitForTransactionReceipt(ctx, txHash)
+	if err == nil && receipt != nil {
+		_ = s.journal.Resolved(ctx, s.settlement.Network, txHash)
+	}
+	return receipt, err
+}
+
+// withJournal wraps signer to journal settlement transactions, if a journal
+// is configured
+func (f *ExactEvmScheme) withJournal(signer evm.FacilitatorEvmSigner, network x402.Network, payload map[string]interface{}, asset, amount string, attribution map[string]string) evm.FacilitatorEvmSigner {
+	if f.config.Journal == nil {
+		return signer
+	}
+	return &journalingSigner{
+		FacilitatorEvmSigner: signer,
+		journal:              f.config.Journal,
+		settlement: x402.SubmittedSettlement{
+			Scheme:      evm.SchemeExact,
+			Network:     network,
+			Payer:       payloadPayer(payload),
+			Asset:       asset,
+			Amount:      amount,
+			Attribution: attribution,
+		},
+	}
+}
+
+// payloadPayer returns the payer of an EIP-3009 or Permit2 payload
+func payloadPayer(payload map[string]interface{}) string {
+	for _, field := range []string{"authorization", "permit2Authorization"} {
+		if authorization, ok := payload[field].(map[string]interface{}); ok {
+			if from, ok := authorization["from"].(string); ok {
+				return from
+			}
+		}
+	}
+	return ""
+}
+
+// RecoverSettlement waits for the receipt of a journaled or pending
+// settlement transaction, implementing x402.SettlementRecoverer
+func (f *ExactEvmScheme) RecoverSettlement(ctx context.Context, settlement x402.SubmittedSettlement) (*x402.SettleResponse, error) {
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, settlement.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &x402.SettleResponse{
+		Success:     receipt.Status == evm.TxStatusSuccess,
+		Transaction: settlement.Transaction,
+		Network:     settlement.Network,
+		Payer:       settlement.Payer,
+		Cost:        receipt.Cost(),
+	}
+	if !response.Success {
+		response.ErrorReason = ErrTransactionFailed
+	}
+	if f.config.Journal != nil {
+		_ = f.config.Journal.Resolved(ctx, settlement.Network, settlement.Transaction)
+	}
+	return response, nil
+}
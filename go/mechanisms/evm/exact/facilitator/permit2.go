@@ -46,7 +46,10 @@ func VerifyPermit2(
 		return nil, x402.NewVerifyError(ErrPermit2InvalidSpender, payer, "invalid spender")
 	}
 
-	// Verify witness.to matches payTo
+	// Verify witness.to is a valid address and matches payTo
+	if err := evm.ValidateAddress(permit2Payload.Permit2Authorization.Witness.To, false); err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidAddress, payer, err.Error())
+	}
 	if !strings.EqualFold(permit2Payload.Permit2Authorization.Witness.To, requirements.PayTo) {
 		return nil, x402.NewVerifyError(ErrPermit2RecipientMismatch, payer, "recipient mismatch")
 	}
@@ -232,6 +235,7 @@ func SettlePermit2(
 		Transaction: txHash,
 		Network:     network,
 		Payer:       verifyResp.Payer,
+		Cost:        receipt.Cost(),
 	}, nil
 }
 
@@ -258,6 +262,9 @@ func verifyPermit2Signature(
 
 // parsePermit2Error extracts meaningful error codes from contract reverts.
 func parsePermit2Error(err error) string {
+	if reason, ok := gasPreflightReason(err); ok {
+		return reason
+	}
 	msg := err.Error()
 	switch {
 	case strings.Contains(msg, "AmountExceedsPermitted"):
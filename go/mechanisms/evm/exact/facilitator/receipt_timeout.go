@@ -0,0 +1,58 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// receiptTimeoutSigner stops waiting for the receipt of a settlement
+// transaction after a timeout, remembering the transaction so settlement can
+// report it as pending
+type receiptTimeoutSigner struct {
+	evm.FacilitatorEvmSigner
+	timeout time.Duration
+	sent    string // Last settlement transaction
+	pending string // Settlement transaction whose receipt timed out
+}
+
+// WriteContract sends a settlement transaction, remembering its hash
+func (s *receiptTimeoutSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	txHash, err := s.FacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+	if err == nil {
+		s.sent = txHash
+	}
+	return txHash, err
+}
+
+// WaitForTransactionReceipt waits at most the timeout for settlement
+// transactions; other transactions, such as wallet deployments, are waited
+// for as usual
+func (s *receiptTimeoutSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	if txHash != s.sent {
+		return s.FacilitatorEvmSigner.WaitForTransactionReceipt(ctx, txHash)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	receipt, err := s.FacilitatorEvmSigner.WaitForTransactionReceipt(waitCtx, txHash)
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		s.pending = txHash
+	}
+	return receipt, err
+}
+
+// pendingResult turns a settlement that failed because its receipt timed out
+// into a pending response for the sent transaction
+func (s *receiptTimeoutSigner) pendingResult(network x402.Network, response *x402.SettleResponse, err error) (*x402.SettleResponse, error) {
+	if err == nil || s.pending == "" {
+		return response, err
+	}
+	pending := &x402.SettleResponse{Success: true, Pending: true, Transaction: s.pending, Network: network}
+	if se := (&x402.SettleError{}); errors.As(err, &se) {
+		pending.Payer = se.Payer
+	}
+	return pending, nil
+}
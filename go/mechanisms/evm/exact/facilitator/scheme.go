@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -19,6 +20,39 @@ type ExactEvmSchemeConfig struct {
 	// DeployERC4337WithEIP6492 enables automatic deployment of ERC-4337 smart wallets
 	// via EIP-6492 when encountering undeployed contract signatures during settlement
 	DeployERC4337WithEIP6492 bool
+
+	// CheckTokenRestrictions calls the token's paused() and isBlacklisted(address)
+	// views for the payer and payee during verification, rejecting payments
+	// whose settlement would revert. Adds up to three RPC reads per payment.
+	CheckTokenRestrictions bool
+
+	// EstimateGas runs eth_estimateGas before sending settlement transactions
+	// and fails settlement with ErrGasEstimationFailed instead of broadcasting
+	// a transaction that would revert. The signer must implement evm.GasEstimator.
+	EstimateGas bool
+
+	// MaxSettlementGas fails settlement with ErrGasLimitExceeded when the
+	// estimate is higher (0 means no cap). Only applies with EstimateGas.
+	MaxSettlementGas uint64
+
+	// OnGasEstimate is called with every settlement gas estimate
+	OnGasEstimate GasEstimateHook
+
+	// ReceiptTimeout bounds the wait for a settlement transaction's receipt
+	// (0 = wait as long as the context allows). Settlement then returns a
+	// pending response with the transaction hash, and x402Facilitator keeps
+	// waiting for the outcome in the background.
+	ReceiptTimeout time.Duration
+
+	// BatchContract is the Multicall3-compatible contract used to settle
+	// batches when x402Facilitator.EnableSettlementBatching is on (empty =
+	// evm.Multicall3Address)
+	BatchContract string
+
+	// Journal records settlement transactions as soon as they are sent, so
+	// x402Facilitator.RecoverSettlements can resume waiting for them after a
+	// crash (nil = not journaled)
+	Journal x402.SettlementJournal
 }
 
 // ExactEvmScheme implements the SchemeNetworkFacilitator interface for EVM exact payments (V2)
@@ -82,7 +116,14 @@ func (f *ExactEvmScheme) Verify(
 		if err != nil {
 			return nil, x402.NewVerifyError(ErrInvalidPayload, "", fmt.Sprintf("failed to parse Permit2 payload: %s", err.Error()))
 		}
-		return VerifyPermit2(ctx, f.signer, payload, requirements, permit2Payload)
+		resp, err := VerifyPermit2(ctx, f.signer, payload, requirements, permit2Payload)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.checkTokenRestrictions(ctx, evm.NormalizeAddress(requirements.Asset), resp.Payer, requirements.PayTo); err != nil {
+			return nil, err
+		}
+		return resp, nil
 	}
 
 	// Default to EIP-3009 verification
@@ -129,6 +170,13 @@ func (f *ExactEvmScheme) verifyEIP3009(
 		return nil, x402.NewVerifyError(ErrFailedToGetAssetInfo, "", err.Error())
 	}
 
+	// Validate authorization addresses, including mixed-case EIP-55 checksums
+	for _, address := range []string{evmPayload.Authorization.From, evmPayload.Authorization.To} {
+		if err := evm.ValidateAddress(address, false); err != nil {
+			return nil, x402.NewVerifyError(ErrInvalidAddress, "", err.Error())
+		}
+	}
+
 	// Validate authorization matches requirements
 	if !strings.EqualFold(evmPayload.Authorization.To, requirements.PayTo) {
 		return nil, x402.NewVerifyError(ErrRecipientMismatch, "", fmt.Sprintf("recipient mismatch: %s != %s", evmPayload.Authorization.To, requirements.PayTo))
@@ -168,17 +216,12 @@ func (f *ExactEvmScheme) verifyEIP3009(
 		return nil, x402.NewVerifyError(ErrInsufficientBalance, evmPayload.Authorization.From, fmt.Sprintf("insufficient balance: %s < %s", balance.String(), authValue.String()))
 	}
 
-	// Extract token info from requirements
-	tokenName := assetInfo.Name
-	tokenVersion := assetInfo.Version
-	if requirements.Extra != nil {
-		if name, ok := requirements.Extra["name"].(string); ok {
-			tokenName = name
-		}
-		if version, ok := requirements.Extra["version"].(string); ok {
-			tokenVersion = version
-		}
+	// Extract token info from requirements, defaulting to the asset's domain
+	domain := evm.ExtraEIP712{Name: assetInfo.Name, Version: assetInfo.Version}
+	if err := types.UnmarshalExtra(requirements.Extra, &domain); err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidRequirementsExtra, evmPayload.Authorization.From, err.Error())
 	}
+	tokenName, tokenVersion := domain.Name, domain.Version
 
 	// Verify signature
 	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
@@ -203,6 +246,11 @@ func (f *ExactEvmScheme) verifyEIP3009(
 		return nil, x402.NewVerifyError(ErrInvalidSignature, evmPayload.Authorization.From, fmt.Sprintf("invalid signature: %s", evmPayload.Signature))
 	}
 
+	// Optional preflight for tokens that would revert the transfer
+	if err := f.checkTokenRestrictions(ctx, assetInfo.Address, evmPayload.Authorization.From, evmPayload.Authorization.To); err != nil {
+		return nil, err
+	}
+
 	return &x402.VerifyResponse{
 		IsValid: true,
 		Payer:   evmPayload.Authorization.From,
@@ -216,23 +264,51 @@ func (f *ExactEvmScheme) Settle(
 	payload types.PaymentPayload,
 	requirements types.PaymentRequirements,
 ) (*x402.SettleResponse, error) {
+	network := x402.Network(payload.Accepted.Network)
+	signer := f.withJournal(f.settlementSigner(network), network, payload.Payload, requirements.Asset, requirements.Amount, types.Attribution(requirements.Extra))
+	if f.config.ReceiptTimeout <= 0 {
+		return f.settle(ctx, signer, payload, requirements)
+	}
+
+	timed := &receiptTimeoutSigner{FacilitatorEvmSigner: signer, timeout: f.config.ReceiptTimeout}
+	response, err := f.settle(ctx, timed, payload, requirements)
+	return timed.pendingResult(network, response, err)
+}
+
+// settle routes to EIP-3009 or Permit2 settlement, sending transactions with signer
+func (f *ExactEvmScheme) settle(
+	ctx context.Context,
+	signer evm.FacilitatorEvmSigner,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.SettleResponse, error) {
+	network := x402.Network(payload.Accepted.Network)
+
 	// Check if this is a Permit2 payload and route accordingly
 	if evm.IsPermit2Payload(payload.Payload) {
 		permit2Payload, err := evm.Permit2PayloadFromMap(payload.Payload)
 		if err != nil {
-			network := x402.Network(payload.Accepted.Network)
 			return nil, x402.NewSettleError(ErrInvalidPayload, "", network, "", fmt.Sprintf("failed to parse Permit2 payload: %s", err.Error()))
 		}
-		return SettlePermit2(ctx, f.signer, payload, requirements, permit2Payload)
+		payer := permit2Payload.Permit2Authorization.From
+		if err := f.checkTokenRestrictions(ctx, evm.NormalizeAddress(requirements.Asset), payer, requirements.PayTo); err != nil {
+			ve := &x402.VerifyError{}
+			if errors.As(err, &ve) {
+				return nil, x402.NewSettleError(ve.InvalidReason, ve.Payer, network, "", ve.InvalidMessage)
+			}
+			return nil, x402.NewSettleError(ErrVerificationFailed, payer, network, "", err.Error())
+		}
+		return SettlePermit2(ctx, signer, payload, requirements, permit2Payload)
 	}
 
 	// Default to EIP-3009 settlement
-	return f.settleEIP3009(ctx, payload, requirements)
+	return f.settleEIP3009(ctx, signer, payload, requirements)
 }
 
-// settleEIP3009 settles an EIP-3009 payment on-chain.
+// settleEIP3009 settles an EIP-3009 payment on-chain, sending transactions with signer.
 func (f *ExactEvmScheme) settleEIP3009(
 	ctx context.Context,
+	signer evm.FacilitatorEvmSigner,
 	payload types.PaymentPayload,
 	requirements types.PaymentRequirements,
 ) (*x402.SettleResponse, error) {
@@ -286,7 +362,7 @@ func (f *ExactEvmScheme) settleEIP3009(
 			// Wallet not deployed
 			if f.config.DeployERC4337WithEIP6492 {
 				// Deploy wallet
-				err := f.deploySmartWallet(ctx, sigData)
+				err := f.deploySmartWallet(ctx, signer, sigData)
 				if err != nil {
 					return nil, x402.NewSettleError(ErrSmartWalletDeploymentFailed, verifyResp.Payer, network, "", err.Error())
 				}
@@ -300,75 +376,21 @@ func (f *ExactEvmScheme) settleEIP3009(
 	// Use inner signature for settlement
 	signatureBytes = sigData.InnerSignature
 
-	// Parse values (validated during verify, but check again for safety)
-	value, ok := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
-	if !ok {
-		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", "invalid authorization value")
-	}
-	validAfter, ok := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10)
-	if !ok {
-		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", "invalid validAfter")
-	}
-	validBefore, ok := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
-	if !ok {
-		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", "invalid validBefore")
-	}
-	nonceBytes, err := evm.HexToBytes(evmPayload.Authorization.Nonce)
+	abi, args, err := transferWithAuthorizationCall(evmPayload.Authorization, signatureBytes)
 	if err != nil {
-		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", "invalid nonce format")
-	}
-
-	// Determine signature type: ECDSA (65 bytes) or smart wallet (longer)
-	isECDSA := len(signatureBytes) == 65
-
-	var txHash string
-	if isECDSA {
-		// For EOA wallets, use v,r,s overload
-		r := signatureBytes[0:32]
-		s := signatureBytes[32:64]
-		v := signatureBytes[64]
-		if v == 0 || v == 1 {
-			v += 27
-		}
-
-		txHash, err = f.signer.WriteContract(
-			ctx,
-			assetInfo.Address,
-			evm.TransferWithAuthorizationVRSABI,
-			evm.FunctionTransferWithAuthorization,
-			common.HexToAddress(evmPayload.Authorization.From),
-			common.HexToAddress(evmPayload.Authorization.To),
-			value,
-			validAfter,
-			validBefore,
-			[32]byte(nonceBytes),
-			v,
-			[32]byte(r),
-			[32]byte(s),
-		)
-	} else {
-		// For smart wallets, use bytes signature overload
-		txHash, err = f.signer.WriteContract(
-			ctx,
-			assetInfo.Address,
-			evm.TransferWithAuthorizationBytesABI,
-			evm.FunctionTransferWithAuthorization,
-			common.HexToAddress(evmPayload.Authorization.From),
-			common.HexToAddress(evmPayload.Authorization.To),
-			value,
-			validAfter,
-			validBefore,
-			[32]byte(nonceBytes),
-			signatureBytes,
-		)
+		return nil, x402.NewSettleError(ErrInvalidPayload, verifyResp.Payer, network, "", err.Error())
 	}
-
+	txHash, err := signer.WriteContract(ctx, assetInfo.Address, abi, evm.FunctionTransferWithAuthorization, args...)
 	if err != nil {
-		return nil, x402.NewSettleError(ErrFailedToExecuteTransfer, verifyResp.Payer, network, "", err.Error())
+		reason := ErrFailedToExecuteTransfer
+		if preflightReason, ok := gasPreflightReason(err); ok {
+			reason = preflightReason
+		}
+		return nil, x402.NewSettleError(reason, verifyResp.Payer, network, "", err.Error())
 	}
 
 	// Wait for transaction confirmation
-	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	receipt, err := signer.WaitForTransactionReceipt(ctx, txHash)
 	if err != nil {
 		return nil, x402.NewSettleError(ErrFailedToGetReceipt, verifyResp.Payer, network, txHash, err.Error())
 	}
@@ -382,6 +404,51 @@ func (f *ExactEvmScheme) settleEIP3009(
 		Transaction: txHash,
 		Network:     network,
 		Payer:       verifyResp.Payer,
+		Cost:        receipt.Cost(),
+	}, nil
+}
+
+// transferWithAuthorizationCall returns the ABI and arguments of the EIP-3009
+// transferWithAuthorization call for an authorization and its (inner) signature
+func transferWithAuthorizationCall(authorization evm.ExactEIP3009Authorization, signatureBytes []byte) ([]byte, []interface{}, error) {
+	// Parse values (validated during verify, but check again for safety)
+	value, ok := new(big.Int).SetString(authorization.Value, 10)
+	if !ok {
+		return nil, nil, errors.New("invalid authorization value")
+	}
+	validAfter, ok := new(big.Int).SetString(authorization.ValidAfter, 10)
+	if !ok {
+		return nil, nil, errors.New("invalid validAfter")
+	}
+	validBefore, ok := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok {
+		return nil, nil, errors.New("invalid validBefore")
+	}
+	nonceBytes, err := evm.HexToBytes(authorization.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, nil, errors.New("invalid nonce format")
+	}
+
+	from := common.HexToAddress(authorization.From)
+	to := common.HexToAddress(authorization.To)
+
+	// Determine signature type: ECDSA (65 bytes) or smart wallet (longer)
+	if len(signatureBytes) == 65 {
+		// For EOA wallets, use v,r,s overload
+		r := signatureBytes[0:32]
+		s := signatureBytes[32:64]
+		v := signatureBytes[64]
+		if v == 0 || v == 1 {
+			v += 27
+		}
+		return evm.TransferWithAuthorizationVRSABI, []interface{}{
+			from, to, value, validAfter, validBefore, [32]byte(nonceBytes), v, [32]byte(r), [32]byte(s),
+		}, nil
+	}
+
+	// For smart wallets, use bytes signature overload
+	return evm.TransferWithAuthorizationBytesABI, []interface{}{
+		from, to, value, validAfter, validBefore, [32]byte(nonceBytes), signatureBytes,
 	}, nil
 }
 
@@ -393,6 +460,7 @@ func (f *ExactEvmScheme) settleEIP3009(
 // Args:
 //
 //	ctx: Context for cancellation
+//	signer: Signer sending the deployment transaction
 //	sigData: Parsed ERC-6492 signature containing factory address and calldata
 //
 // Returns:
@@ -400,12 +468,13 @@ func (f *ExactEvmScheme) settleEIP3009(
 //	error if deployment fails
 func (f *ExactEvmScheme) deploySmartWallet(
 	ctx context.Context,
+	signer evm.FacilitatorEvmSigner,
 	sigData *evm.ERC6492SignatureData,
 ) error {
 	factoryAddr := common.BytesToAddress(sigData.Factory[:])
 
 	// Send the factory calldata directly - it already contains the encoded function call
-	txHash, err := f.signer.SendTransaction(
+	txHash, err := signer.SendTransaction(
 		ctx,
 		factoryAddr.Hex(),
 		sigData.FactoryCalldata,
@@ -415,7 +484,7 @@ func (f *ExactEvmScheme) deploySmartWallet(
 	}
 
 	// Wait for deployment transaction
-	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	receipt, err := signer.WaitForTransactionReceipt(ctx, txHash)
 	if err != nil {
 		return fmt.Errorf("failed to wait for deployment: %w", err)
 	}
@@ -0,0 +1,78 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/types"
+)
+
+// simulatedTxHash stands in for transactions a simulation did not send
+const simulatedTxHash = "0x0000000000000000000000000000000000000000000000000000000000000000"
+
+// Simulate verifies a payment and runs its settlement with every transaction
+// gas-estimated (eth_estimateGas) instead of sent, returning the projected
+// gas and, when the signer implements evm.GasPriceReader, the fee.
+// The signer must implement evm.GasEstimator. MaxSettlementGas applies as in
+// settlement; payments that would deploy a smart wallet cannot be simulated.
+func (f *ExactEvmScheme) Simulate(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.SimulateResponse, error) {
+	simulator := &simulatingSigner{FacilitatorEvmSigner: f.signer, maxGas: f.config.MaxSettlementGas}
+
+	settled, err := f.settle(ctx, simulator, payload, requirements)
+	if err != nil {
+		se := &x402.SettleError{}
+		if errors.As(err, &se) {
+			return nil, x402.NewVerifyError(se.ErrorReason, se.Payer, se.ErrorMessage)
+		}
+		return nil, err
+	}
+
+	response := &x402.SimulateResponse{
+		IsValid: true,
+		Payer:   settled.Payer,
+		Network: x402.Network(requirements.Network),
+		GasUsed: simulator.gasUsed,
+	}
+	if reader, ok := f.signer.(evm.GasPriceReader); ok {
+		if price, err := reader.SuggestGasPrice(ctx); err == nil && price != nil {
+			response.GasPrice = price.String()
+			response.Fee = new(big.Int).Mul(price, new(big.Int).SetUint64(simulator.gasUsed)).String()
+		}
+	}
+	return response, nil
+}
+
+// simulatingSigner estimates the gas of settlement transactions instead of
+// sending them, reporting them as mined
+type simulatingSigner struct {
+	evm.FacilitatorEvmSigner
+	maxGas  uint64
+	gasUsed uint64
+}
+
+func (s *simulatingSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	gas, err := estimateSettlementGas(ctx, s.FacilitatorEvmSigner, s.maxGas, address, abi, functionName, args...)
+	if err != nil {
+		return "", err
+	}
+	s.gasUsed += gas
+	return simulatedTxHash, nil
+}
+
+func (s *simulatingSigner) SendTransaction(ctx context.Context, to string, data []byte) (string, error) {
+	return "", errors.New("smart wallet deployment cannot be simulated")
+}
+
+func (s *simulatingSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	if txHash == simulatedTxHash {
+		return &evm.TransactionReceipt{Status: evm.TxStatusSuccess, TxHash: txHash}, nil
+	}
+	return s.FacilitatorEvmSigner.WaitForTransactionReceipt(ctx, txHash)
+}
@@ -0,0 +1,47 @@
+package facilitator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// checkTokenRestrictions fails fast on transfers a token contract is certain
+// to revert: a paused token, or a blacklisted payer or payee (USDC-style
+// paused() and isBlacklisted(address)). Tokens that do not implement these
+// views, and RPC failures, are treated as unrestricted, since the preflight
+// only saves gas and settlement reports the revert anyway.
+// Only runs when ExactEvmSchemeConfig.CheckTokenRestrictions is enabled.
+func (f *ExactEvmScheme) checkTokenRestrictions(ctx context.Context, tokenAddress, payer, payee string) error {
+	if !f.config.CheckTokenRestrictions {
+		return nil
+	}
+
+	if paused, ok := f.readBool(ctx, tokenAddress, evm.PausedABI, evm.FunctionPaused); ok && paused {
+		return x402.NewVerifyError(ErrTokenPaused, payer, fmt.Sprintf("token %s is paused", tokenAddress))
+	}
+
+	if blacklisted, ok := f.readBool(ctx, tokenAddress, evm.IsBlacklistedABI, evm.FunctionIsBlacklisted, common.HexToAddress(payer)); ok && blacklisted {
+		return x402.NewVerifyError(ErrPayerBlacklisted, payer, fmt.Sprintf("payer %s is blacklisted by token %s", payer, tokenAddress))
+	}
+	if blacklisted, ok := f.readBool(ctx, tokenAddress, evm.IsBlacklistedABI, evm.FunctionIsBlacklisted, common.HexToAddress(payee)); ok && blacklisted {
+		return x402.NewVerifyError(ErrRecipientBlacklisted, payer, fmt.Sprintf("recipient %s is blacklisted by token %s", payee, tokenAddress))
+	}
+
+	return nil
+}
+
+// readBool calls a bool view function, reporting false for ok if the call
+// fails or returns another type
+func (f *ExactEvmScheme) readBool(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (bool, bool) {
+	result, err := f.signer.ReadContract(ctx, address, abi, functionName, args...)
+	if err != nil {
+		return false, false
+	}
+	value, ok := result.(bool)
+	return value, ok
+}
@@ -15,7 +15,8 @@ import (
 
 // ExactEvmScheme implements the SchemeNetworkServer interface for EVM exact payments (V2)
 type ExactEvmScheme struct {
-	moneyParsers []x402.MoneyParser
+	moneyParsers    []x402.MoneyParser
+	requireChecksum bool
 }
 
 // NewExactEvmScheme creates a new ExactEvmScheme
@@ -62,6 +63,18 @@ func (s *ExactEvmScheme) RegisterMoneyParser(parser x402.MoneyParser) *ExactEvmS
 	return s
 }
 
+// RequireChecksummedAddresses rejects payTo addresses that are not in their
+// EIP-55 checksummed form, catching merchant addresses copied without their
+// checksum. By default lowercase addresses are accepted and checksummed.
+//
+// Returns:
+//
+//	The server instance for chaining
+func (s *ExactEvmScheme) RequireChecksummedAddresses() *ExactEvmScheme {
+	s.requireChecksum = true
+	return s
+}
+
 // ParsePrice parses a price string and converts it to an asset amount (V2)
 // If price is already an AssetAmount, returns it directly.
 // If price is Money (string | number), parses to decimal and tries custom parsers.
@@ -215,6 +228,14 @@ func (s *ExactEvmScheme) EnhancePaymentRequirements(
 ) (types.PaymentRequirements, error) {
 	networkStr := string(requirements.Network)
 
+	// Format payTo with its EIP-55 checksum, rejecting mistyped checksums
+	if requirements.PayTo != "" {
+		if err := evm.ValidateAddress(requirements.PayTo, s.requireChecksum); err != nil {
+			return requirements, fmt.Errorf(ErrInvalidPayToAddress+": %w", err)
+		}
+		requirements.PayTo, _ = evm.ChecksumAddress(requirements.PayTo)
+	}
+
 	// Get asset info - if no asset specified, GetAssetInfo will try to use the default
 	var assetInfo *evm.AssetInfo
 	var err error
@@ -231,6 +252,9 @@ func (s *ExactEvmScheme) EnhancePaymentRequirements(
 		}
 		requirements.Asset = assetInfo.Address
 	}
+	if checksummed, err := evm.ChecksumAddress(requirements.Asset); err == nil {
+		requirements.Asset = checksummed
+	}
 
 	// Ensure amount is in the correct format (smallest unit)
 	if requirements.Amount != "" && strings.Contains(requirements.Amount, ".") {
@@ -242,19 +266,13 @@ func (s *ExactEvmScheme) EnhancePaymentRequirements(
 		requirements.Amount = amount.String()
 	}
 
-	// Add EIP-3009 specific fields to Extra if not present
-	if requirements.Extra == nil {
-		requirements.Extra = make(map[string]interface{})
-	}
-
 	// Add token name and version for EIP-712 signing
 	// ONLY add if not already present (client may have specified exact values)
-	if _, ok := requirements.Extra["name"]; !ok {
-		requirements.Extra["name"] = assetInfo.Name
-	}
-	if _, ok := requirements.Extra["version"]; !ok {
-		requirements.Extra["version"] = assetInfo.Version
+	extra, err := types.MergeExtra(requirements.Extra, evm.ExtraEIP712{Name: assetInfo.Name, Version: assetInfo.Version}, false)
+	if err != nil {
+		return requirements, err
 	}
+	requirements.Extra = extra
 
 	// Copy extensions from supportedKind if provided
 	if supportedKind.Extra != nil {
@@ -294,9 +312,9 @@ func (s *ExactEvmScheme) GetDisplayAmount(amount string, network string, asset s
 func (s *ExactEvmScheme) ValidatePaymentRequirements(requirements x402.PaymentRequirements) error {
 	networkStr := string(requirements.Network)
 
-	// Check PayTo is a valid address
-	if !evm.IsValidAddress(requirements.PayTo) {
-		return fmt.Errorf(ErrInvalidPayToAddress+": %s", requirements.PayTo)
+	// Check PayTo is a valid address with a valid checksum
+	if err := evm.ValidateAddress(requirements.PayTo, s.requireChecksum); err != nil {
+		return fmt.Errorf(ErrInvalidPayToAddress+": %w", err)
 	}
 
 	// Check amount is valid
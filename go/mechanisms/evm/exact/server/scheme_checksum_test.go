@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+const merchantAddress = "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+
+func TestEnhancePaymentRequirementsChecksumsAddresses(t *testing.T) {
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Asset:   strings.ToLower(baseMainnetUSDC),
+		Amount:  "1000",
+		PayTo:   strings.ToLower(merchantAddress),
+	}
+
+	enhanced, err := NewExactEvmScheme().EnhancePaymentRequirements(context.Background(), requirements, types.SupportedKind{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enhanced.PayTo != merchantAddress || enhanced.Asset != baseMainnetUSDC {
+		t.Errorf("expected checksummed addresses, got payTo %s asset %s", enhanced.PayTo, enhanced.Asset)
+	}
+
+	requirements.PayTo = "0xD8dA6BF26964aF9D7eEd9e03E53415D37aA96045" // mistyped checksum
+	if _, err := NewExactEvmScheme().EnhancePaymentRequirements(context.Background(), requirements, types.SupportedKind{}, nil); err == nil {
+		t.Error("expected error for an invalid checksum")
+	}
+}
+
+func TestRequireChecksummedAddresses(t *testing.T) {
+	scheme := NewExactEvmScheme().RequireChecksummedAddresses()
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Amount:  "1000",
+		PayTo:   strings.ToLower(merchantAddress),
+	}
+
+	if _, err := scheme.EnhancePaymentRequirements(context.Background(), requirements, types.SupportedKind{}, nil); err == nil {
+		t.Error("expected lowercase payTo to be rejected")
+	}
+
+	requirements.PayTo = merchantAddress
+	if _, err := scheme.EnhancePaymentRequirements(context.Background(), requirements, types.SupportedKind{}, nil); err != nil {
+		t.Errorf("unexpected error for a checksummed payTo: %v", err)
+	}
+}
@@ -12,6 +12,7 @@ const (
 	ErrInvalidExtraField               = "invalid_exact_evm_extra_field"
 	ErrMissingEip712Domain             = "invalid_exact_evm_missing_eip712_domain"
 	ErrRecipientMismatch               = "invalid_exact_evm_payload_recipient_mismatch"
+	ErrInvalidAddress                  = "invalid_exact_evm_payload_address"
 	ErrInvalidAuthorizationValue       = "invalid_exact_evm_payload_authorization_value"
 	ErrInvalidRequiredAmount           = "invalid_exact_evm_required_amount"
 	ErrAuthorizationValueInsufficient  = "invalid_exact_evm_payload_authorization_value_insufficient"
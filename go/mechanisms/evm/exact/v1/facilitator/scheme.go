@@ -131,6 +131,13 @@ func (f *ExactEvmSchemeV1) Verify(
 		return nil, x402.NewVerifyError(ErrMissingEip712Domain, evmPayload.Authorization.From, "missing EIP-712 domain parameters")
 	}
 
+	// Validate authorization addresses, including mixed-case EIP-55 checksums
+	for _, address := range []string{evmPayload.Authorization.From, evmPayload.Authorization.To} {
+		if err := evm.ValidateAddress(address, false); err != nil {
+			return nil, x402.NewVerifyError(ErrInvalidAddress, evmPayload.Authorization.From, err.Error())
+		}
+	}
+
 	// Validate authorization matches requirements
 	if !strings.EqualFold(evmPayload.Authorization.To, requirements.PayTo) {
 		return nil, x402.NewVerifyError(ErrRecipientMismatch, evmPayload.Authorization.From, fmt.Sprintf("recipient mismatch: %s != %s", evmPayload.Authorization.To, requirements.PayTo))
@@ -346,6 +353,7 @@ func (f *ExactEvmSchemeV1) Settle(
 		Transaction: txHash,
 		Network:     network,
 		Payer:       verifyResp.Payer,
+		Cost:        receipt.Cost(),
 	}, nil
 }
 
@@ -0,0 +1,44 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// TransactionReceiptReader fetches transaction receipts, waiting for the
+// transaction to be mined
+type TransactionReceiptReader interface {
+	WaitForTransactionReceipt(ctx context.Context, txHash string) (*TransactionReceipt, error)
+}
+
+// ReceiptConfirmer confirms EVM settlements by checking the transaction receipt.
+// It satisfies x402http.SettlementConfirmer for client-side settlement checks.
+type ReceiptConfirmer struct {
+	reader TransactionReceiptReader
+}
+
+// NewReceiptConfirmer creates a confirmer reading receipts from reader
+func NewReceiptConfirmer(reader TransactionReceiptReader) *ReceiptConfirmer {
+	return &ReceiptConfirmer{reader: reader}
+}
+
+// ConfirmSettlement checks that the settlement transaction was mined and succeeded
+func (c *ReceiptConfirmer) ConfirmSettlement(ctx context.Context, response x402.SettleResponse) error {
+	receipt, err := c.reader.WaitForTransactionReceipt(ctx, response.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to get receipt for %s: %w", response.Transaction, err)
+	}
+	if receipt == nil {
+		return fmt.Errorf("no receipt for %s", response.Transaction)
+	}
+	if receipt.TxHash != "" && !strings.EqualFold(receipt.TxHash, response.Transaction) {
+		return fmt.Errorf("receipt is for %s, not %s", receipt.TxHash, response.Transaction)
+	}
+	if receipt.Status != TxStatusSuccess {
+		return fmt.Errorf("transaction %s failed", response.Transaction)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+type receiptReaderFunc func(ctx context.Context, txHash string) (*TransactionReceipt, error)
+
+func (f receiptReaderFunc) WaitForTransactionReceipt(ctx context.Context, txHash string) (*TransactionReceipt, error) {
+	return f(ctx, txHash)
+}
+
+func TestReceiptConfirmer(t *testing.T) {
+	response := x402.SettleResponse{Success: true, Transaction: "0xABC", Network: "eip155:8453"}
+
+	tests := []struct {
+		name    string
+		receipt *TransactionReceipt
+		err     error
+		wantErr bool
+	}{
+		{"success", &TransactionReceipt{Status: TxStatusSuccess, TxHash: "0xabc"}, nil, false},
+		{"reverted", &TransactionReceipt{Status: TxStatusFailed, TxHash: "0xabc"}, nil, true},
+		{"different transaction", &TransactionReceipt{Status: TxStatusSuccess, TxHash: "0xdef"}, nil, true},
+		{"not found", nil, errors.New("not found"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirmer := NewReceiptConfirmer(receiptReaderFunc(func(ctx context.Context, txHash string) (*TransactionReceipt, error) {
+				return tt.receipt, tt.err
+			}))
+			err := confirmer.ConfirmSettlement(context.Background(), response)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
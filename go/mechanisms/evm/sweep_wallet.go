@@ -0,0 +1,60 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SweepSigner is the subset of FacilitatorEvmSigner needed to sweep token
+// balances from the signer's address
+type SweepSigner interface {
+	GetBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error)
+	WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error)
+	WaitForTransactionReceipt(ctx context.Context, txHash string) (*TransactionReceipt, error)
+}
+
+// SweepWallet moves ERC-20 balances out of a hot payTo address controlled by
+// a signer. It satisfies sweep.Wallet.
+type SweepWallet struct {
+	signer  SweepSigner
+	address string
+}
+
+// NewSweepWallet creates a sweep wallet for an address the signer controls
+func NewSweepWallet(signer SweepSigner, address string) *SweepWallet {
+	return &SweepWallet{signer: signer, address: NormalizeAddress(address)}
+}
+
+// Address returns the hot address
+func (w *SweepWallet) Address() string {
+	return w.address
+}
+
+// Balance returns the hot address's token balance
+func (w *SweepWallet) Balance(ctx context.Context, asset string) (*big.Int, error) {
+	return w.signer.GetBalance(ctx, w.address, asset)
+}
+
+// Transfer sends tokens to an address and waits for the transaction to succeed
+func (w *SweepWallet) Transfer(ctx context.Context, asset string, to string, amount *big.Int) (string, error) {
+	if !IsValidAddress(to) {
+		return "", fmt.Errorf("invalid destination address: %s", to)
+	}
+
+	txHash, err := w.signer.WriteContract(ctx, asset, ERC20TransferABI, "transfer", common.HexToAddress(to), amount)
+	if err != nil {
+		return "", err
+	}
+
+	receipt, err := w.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return txHash, fmt.Errorf("failed to get receipt: %w", err)
+	}
+	if receipt.Status != TxStatusSuccess {
+		return txHash, fmt.Errorf("transaction %s failed", txHash)
+	}
+	return txHash, nil
+}
@@ -0,0 +1,61 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/x402/go/sweep"
+)
+
+var _ sweep.Wallet = (*SweepWallet)(nil)
+
+// fakeSweepSigner records the transfer call
+type fakeSweepSigner struct {
+	balance  *big.Int
+	status   uint64
+	function string
+	args     []interface{}
+}
+
+func (f *fakeSweepSigner) GetBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	return f.balance, nil
+}
+
+func (f *fakeSweepSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	f.function = functionName
+	f.args = args
+	return "0xtx", nil
+}
+
+func (f *fakeSweepSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*TransactionReceipt, error) {
+	return &TransactionReceipt{Status: f.status, TxHash: txHash}, nil
+}
+
+func TestSweepWalletTransfer(t *testing.T) {
+	signer := &fakeSweepSigner{balance: big.NewInt(42), status: TxStatusSuccess}
+	wallet := NewSweepWallet(signer, "0xAbC0000000000000000000000000000000000001")
+
+	if wallet.Address() != "0xabc0000000000000000000000000000000000001" {
+		t.Errorf("Expected normalized address, got %s", wallet.Address())
+	}
+	if balance, _ := wallet.Balance(context.Background(), "0xusdc"); balance.Int64() != 42 {
+		t.Errorf("Expected balance 42, got %s", balance)
+	}
+
+	tx, err := wallet.Transfer(context.Background(), "0xusdc", "0x0000000000000000000000000000000000000002", big.NewInt(40))
+	if err != nil || tx != "0xtx" {
+		t.Fatalf("Expected transfer, got %q (%v)", tx, err)
+	}
+	if signer.function != "transfer" || len(signer.args) != 2 {
+		t.Errorf("Expected transfer(to, amount), got %s%v", signer.function, signer.args)
+	}
+
+	signer.status = TxStatusFailed
+	if _, err := wallet.Transfer(context.Background(), "0xusdc", "0x0000000000000000000000000000000000000002", big.NewInt(40)); err == nil {
+		t.Error("Expected error for reverted transfer")
+	}
+	if _, err := wallet.Transfer(context.Background(), "0xusdc", "not-an-address", big.NewInt(40)); err == nil {
+		t.Error("Expected error for invalid destination")
+	}
+}
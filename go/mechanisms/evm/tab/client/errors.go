@@ -0,0 +1,9 @@
+package client
+
+// Client error constants for the tab EVM scheme (V2)
+const (
+	ErrInvalidAmount       = "invalid_tab_evm_client_amount"
+	ErrMissingTabContract  = "invalid_tab_evm_client_missing_tab_contract"
+	ErrFailedToSignDeposit = "invalid_tab_evm_client_failed_to_sign_deposit"
+	ErrFailedToSignVoucher = "invalid_tab_evm_client_failed_to_sign_voucher"
+)
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/mechanisms/evm/tab"
+	"github.com/coinbase/x402/go/types"
+)
+
+// Defaults for tabs opened by the client
+const (
+	// DefaultTabDuration is how long a tab stays open before its deposit can
+	// be reclaimed
+	DefaultTabDuration = 24 * time.Hour
+
+	// tabExpiryMargin stops using a tab this long before it expires, leaving
+	// the facilitator time to close it
+	tabExpiryMargin = 15 * time.Minute
+)
+
+// openTab is a tab the client has opened with a payee
+type openTab struct {
+	channelID string
+	deposit   *big.Int
+	spent     *big.Int // Running total of the last voucher
+	expiry    time.Time
+}
+
+// TabEvmScheme implements the SchemeNetworkClient interface for EVM tab
+// payments (V2). It opens a tab with each payee on the first payment and
+// signs a voucher for the running total on every later one.
+type TabEvmScheme struct {
+	signer   evm.ClientEvmSigner
+	duration time.Duration
+
+	mu   sync.Mutex
+	tabs map[string]*openTab
+}
+
+// NewTabEvmScheme creates a new TabEvmScheme
+func NewTabEvmScheme(signer evm.ClientEvmSigner) *TabEvmScheme {
+	return &TabEvmScheme{
+		signer:   signer,
+		duration: DefaultTabDuration,
+		tabs:     make(map[string]*openTab),
+	}
+}
+
+// Scheme returns the scheme identifier
+func (c *TabEvmScheme) Scheme() string {
+	return tab.SchemeTab
+}
+
+// SetTabDuration sets how long new tabs stay open (default DefaultTabDuration)
+func (c *TabEvmScheme) SetTabDuration(duration time.Duration) *TabEvmScheme {
+	c.duration = duration
+	return c
+}
+
+// Forget drops the client's tab for the requirements' payee, so the next
+// payment opens a new one (e.g. after the server reports the tab closed)
+func (c *TabEvmScheme) Forget(requirements types.PaymentRequirements) {
+	var extra tab.ExtraTab
+	_ = types.UnmarshalExtra(requirements.Extra, &extra)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tabs, tabKey(requirements, extra.Contract))
+}
+
+// CreatePaymentPayload creates a V2 payment payload for the tab scheme: a
+// voucher for the tab's new running total, opening a tab first if there is
+// none with room for the payment
+func (c *TabEvmScheme) CreatePaymentPayload(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+) (types.PaymentPayload, error) {
+	var extra tab.ExtraTab
+	if err := types.UnmarshalExtra(requirements.Extra, &extra); err != nil {
+		return types.PaymentPayload{}, err
+	}
+	if !evm.IsValidAddress(extra.Contract) {
+		return types.PaymentPayload{}, fmt.Errorf(ErrMissingTabContract+": %q", extra.Contract)
+	}
+	chainID, err := evm.GetEvmChainId(string(requirements.Network))
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+	price, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok || price.Sign() <= 0 {
+		return types.PaymentPayload{}, fmt.Errorf(ErrInvalidAmount+": %s", requirements.Amount)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := tabKey(requirements, extra.Contract)
+	current := c.tabs[key]
+	var open *tab.Open
+	if current == nil || new(big.Int).Add(current.spent, price).Cmp(current.deposit) > 0 || time.Until(current.expiry) < tabExpiryMargin {
+		current, open, err = c.openTab(ctx, requirements, extra, chainID, price)
+		if err != nil {
+			return types.PaymentPayload{}, err
+		}
+	}
+
+	voucher := tab.Voucher{
+		ChannelID: current.channelID,
+		Amount:    new(big.Int).Add(current.spent, price).String(),
+	}
+	message, err := tab.VoucherMessage(voucher)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+	signature, err := c.signer.SignTypedData(ctx, tab.VoucherDomain(chainID, extra.Contract), tab.VoucherTypes, "Voucher", message)
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToSignVoucher+": %w", err)
+	}
+
+	// Only commit the new total (and tab) once the voucher is signed
+	current.spent.Add(current.spent, price)
+	c.tabs[key] = current
+
+	payload := &tab.Payload{Voucher: voucher, Signature: evm.BytesToHex(signature), Open: open}
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     payload.ToMap(),
+	}, nil
+}
+
+// openTab signs the deposit authorization for a new tab
+func (c *TabEvmScheme) openTab(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	extra tab.ExtraTab,
+	chainID *big.Int,
+	price *big.Int,
+) (*openTab, *tab.Open, error) {
+	assetInfo, err := evm.GetAssetInfo(string(requirements.Network), requirements.Asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	domain := evm.ExtraEIP712{Name: assetInfo.Name, Version: assetInfo.Version}
+	if err := types.UnmarshalExtra(requirements.Extra, &domain); err != nil {
+		return nil, nil, err
+	}
+
+	// Deposit what the server asks for, and at least this payment
+	deposit := new(big.Int).Set(price)
+	if requested, ok := new(big.Int).SetString(extra.Deposit, 10); ok && requested.Cmp(deposit) > 0 {
+		deposit = requested
+	}
+
+	salt, err := evm.CreateNonce()
+	if err != nil {
+		return nil, nil, err
+	}
+	expiry := time.Now().Add(c.duration).Truncate(time.Second)
+	expiryStr := fmt.Sprint(expiry.Unix())
+	channelID, err := tab.ChannelID(assetInfo.Address, requirements.PayTo, expiryStr, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validAfter, validBefore := evm.CreateValidityWindow(time.Hour)
+	authorization := evm.ExactEIP3009Authorization{
+		From:        c.signer.Address(),
+		To:          extra.Contract,
+		Value:       deposit.String(),
+		ValidAfter:  validAfter.String(),
+		ValidBefore: validBefore.String(),
+		Nonce:       channelID,
+	}
+	message, err := tab.AuthorizationMessage(authorization)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokenDomain := evm.TypedDataDomain{Name: domain.Name, Version: domain.Version, ChainID: chainID, VerifyingContract: assetInfo.Address}
+	signature, err := c.signer.SignTypedData(ctx, tokenDomain, tab.ReceiveWithAuthorizationTypes, "ReceiveWithAuthorization", message)
+	if err != nil {
+		return nil, nil, fmt.Errorf(ErrFailedToSignDeposit+": %w", err)
+	}
+
+	opened := &openTab{channelID: channelID, deposit: deposit, spent: new(big.Int), expiry: expiry}
+	open := &tab.Open{Expiry: expiryStr, Salt: salt, Authorization: authorization, Signature: evm.BytesToHex(signature)}
+	return opened, open, nil
+}
+
+// tabKey identifies the tab used for a payee, asset, and tab contract
+func tabKey(requirements types.PaymentRequirements, contract string) string {
+	return strings.ToLower(strings.Join([]string{requirements.Network, requirements.Asset, requirements.PayTo, contract}, "|"))
+}
@@ -0,0 +1,37 @@
+package facilitator
+
+// Facilitator error constants for the tab EVM scheme
+const (
+	// Verify errors
+	ErrInvalidScheme            = "invalid_tab_evm_scheme"
+	ErrNetworkMismatch          = "invalid_tab_evm_network_mismatch"
+	ErrInvalidPayload           = "invalid_tab_evm_payload"
+	ErrInvalidRequirementsExtra = "invalid_tab_evm_requirements_extra"
+	ErrFailedToGetAssetInfo     = "invalid_tab_evm_failed_to_get_asset_info"
+	ErrInvalidChannelID         = "invalid_tab_evm_channel_id"
+	ErrTabAlreadyOpen           = "invalid_tab_evm_tab_already_open"
+	ErrUnknownTab               = "invalid_tab_evm_unknown_tab"
+	ErrTabClosed                = "invalid_tab_evm_tab_closed"
+	ErrTabExpiring              = "invalid_tab_evm_tab_expiring"
+	ErrTabMismatch              = "invalid_tab_evm_tab_mismatch"
+	ErrVoucherTooLow            = "invalid_tab_evm_voucher_too_low"
+	ErrVoucherExceedsDeposit    = "invalid_tab_evm_voucher_exceeds_deposit"
+	ErrFailedToCheckNonce       = "invalid_tab_evm_failed_to_check_nonce"
+	ErrNonceAlreadyUsed         = "invalid_tab_evm_nonce_already_used"
+	ErrFailedToGetBalance       = "invalid_tab_evm_failed_to_get_balance"
+	ErrInsufficientBalance      = "invalid_tab_evm_insufficient_balance"
+	ErrValidBeforeExpired       = "invalid_tab_evm_authorization_valid_before"
+	ErrValidAfterInFuture       = "invalid_tab_evm_authorization_valid_after"
+	ErrInvalidSignatureFormat   = "invalid_tab_evm_signature_format"
+	ErrFailedToVerifySignature  = "invalid_tab_evm_failed_to_verify_signature"
+	ErrInvalidDepositSignature  = "invalid_tab_evm_deposit_signature"
+	ErrInvalidVoucherSignature  = "invalid_tab_evm_voucher_signature"
+	ErrFailedToLoadTab          = "invalid_tab_evm_failed_to_load_tab"
+
+	// Settle errors
+	ErrFailedToOpenTab    = "invalid_tab_evm_failed_to_open_tab"
+	ErrFailedToCloseTab   = "invalid_tab_evm_failed_to_close_tab"
+	ErrFailedToGetReceipt = "invalid_tab_evm_failed_to_get_receipt"
+	ErrTransactionFailed  = "invalid_tab_evm_transaction_failed"
+	ErrFailedToSaveTab    = "invalid_tab_evm_failed_to_save_tab"
+)
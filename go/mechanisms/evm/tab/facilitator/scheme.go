@@ -0,0 +1,507 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/mechanisms/evm/tab"
+	"github.com/coinbase/x402/go/types"
+)
+
+// DefaultCloseMargin is how long before a tab expires its vouchers stop being
+// accepted, leaving time to close it
+const DefaultCloseMargin = 10 * time.Minute
+
+// blockTimeBuffer is how long (in seconds) a deposit authorization must stay
+// valid for the open transaction to land in a block
+const blockTimeBuffer = 6
+
+// TabEvmSchemeConfig holds configuration for the TabEvmScheme facilitator
+type TabEvmSchemeConfig struct {
+	// Store keeps tabs and their latest vouchers (nil = in-memory)
+	Store TabStore
+
+	// CloseMargin refuses vouchers this long before a tab expires
+	// (0 = DefaultCloseMargin)
+	CloseMargin time.Duration
+}
+
+// TabEvmScheme implements the SchemeNetworkFacilitator interface for EVM tab
+// payments (V2). The first payment opens the tab on-chain; later payments
+// only record the owner's latest voucher, which Close settles.
+type TabEvmScheme struct {
+	signer evm.FacilitatorEvmSigner
+	config TabEvmSchemeConfig
+
+	locksMu sync.Mutex
+	locks   map[string]*sync.Mutex
+}
+
+// ClosedTab is the outcome of closing one tab
+type ClosedTab struct {
+	Tab      *Tab
+	Response *x402.SettleResponse
+	Err      error
+}
+
+// NewTabEvmScheme creates a new TabEvmScheme
+func NewTabEvmScheme(signer evm.FacilitatorEvmSigner, config *TabEvmSchemeConfig) *TabEvmScheme {
+	cfg := TabEvmSchemeConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryTabStore()
+	}
+	if cfg.CloseMargin <= 0 {
+		cfg.CloseMargin = DefaultCloseMargin
+	}
+	return &TabEvmScheme{
+		signer: signer,
+		config: cfg,
+		locks:  make(map[string]*sync.Mutex),
+	}
+}
+
+// Scheme returns the scheme identifier
+func (f *TabEvmScheme) Scheme() string {
+	return tab.SchemeTab
+}
+
+// CaipFamily returns the CAIP family pattern this facilitator supports
+func (f *TabEvmScheme) CaipFamily() string {
+	return "eip155:*"
+}
+
+// GetExtra returns mechanism-specific extra data for the supported kinds endpoint
+func (f *TabEvmScheme) GetExtra(_ x402.Network) map[string]interface{} {
+	return nil
+}
+
+// GetSigners returns signer addresses used by this facilitator
+func (f *TabEvmScheme) GetSigners(_ x402.Network) []string {
+	return f.signer.GetAddresses()
+}
+
+// Verify checks a voucher against its tab: signed by the tab's owner, at
+// least the price above the previous voucher, and within the deposit. A
+// payload that opens a tab also has its deposit authorization checked.
+func (f *TabEvmScheme) Verify(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.VerifyResponse, error) {
+	updated, _, err := f.check(ctx, payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: updated.Owner}, nil
+}
+
+// Settle records the voucher, first opening the tab on-chain if the payload
+// carries its deposit. The response's transaction is the tab's open
+// transaction; the payee is paid when the tab is closed.
+func (f *TabEvmScheme) Settle(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.SettleResponse, error) {
+	network := x402.Network(requirements.Network)
+	tabPayload, err := tab.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrInvalidPayload, "", network, "", err.Error())
+	}
+
+	// Vouchers for one tab are checked and recorded one at a time
+	unlock := f.lock(tabPayload.Voucher.ChannelID)
+	defer unlock()
+
+	updated, open, err := f.check(ctx, payload, requirements)
+	if err != nil {
+		ve := &x402.VerifyError{}
+		if errors.As(err, &ve) {
+			return nil, x402.NewSettleError(ve.InvalidReason, ve.Payer, network, "", ve.InvalidMessage)
+		}
+		return nil, x402.NewSettleError(ErrInvalidPayload, "", network, "", err.Error())
+	}
+
+	if open != nil {
+		txHash, err := f.openTab(ctx, updated, open)
+		if err != nil {
+			return nil, err
+		}
+		updated.OpenTransaction = txHash
+	}
+
+	updated.UpdatedAt = time.Now()
+	if err := f.config.Store.Put(ctx, updated); err != nil {
+		return nil, x402.NewSettleError(ErrFailedToSaveTab, updated.Owner, network, updated.OpenTransaction, err.Error())
+	}
+
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: updated.OpenTransaction,
+		Network:     network,
+		Payer:       updated.Owner,
+	}, nil
+}
+
+// Close closes a tab on-chain with its latest voucher, paying the payee the
+// voucher's amount and refunding the rest of the deposit to the owner.
+// Closing a closed tab returns its close transaction.
+func (f *TabEvmScheme) Close(ctx context.Context, channelID string) (*x402.SettleResponse, error) {
+	unlock := f.lock(channelID)
+	defer unlock()
+
+	t, err := f.config.Store.Get(ctx, channelID)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrFailedToLoadTab, "", "", "", err.Error())
+	}
+	if t == nil {
+		return nil, x402.NewSettleError(ErrUnknownTab, "", "", "", fmt.Sprintf("unknown tab %s", channelID))
+	}
+	network := x402.Network(t.Network)
+	if t.Closed() {
+		return &x402.SettleResponse{Success: true, Transaction: t.CloseTransaction, Network: network, Payer: t.Owner}, nil
+	}
+
+	channelIDBytes, _ := evm.HexToBytes(t.ChannelID)
+	amount, _ := new(big.Int).SetString(t.Amount, 10)
+	signature, err := evm.HexToBytes(t.Signature)
+	if err != nil || amount == nil || len(channelIDBytes) != 32 {
+		return nil, x402.NewSettleError(ErrInvalidPayload, t.Owner, network, "", "stored voucher is malformed")
+	}
+
+	txHash, err := f.signer.WriteContract(ctx, t.Contract, tab.TabCloseABI, tab.FunctionClose, [32]byte(channelIDBytes), amount, signature)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrFailedToCloseTab, t.Owner, network, "", err.Error())
+	}
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, x402.NewSettleError(ErrFailedToGetReceipt, t.Owner, network, txHash, err.Error())
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return nil, x402.NewSettleError(ErrTransactionFailed, t.Owner, network, txHash, "")
+	}
+
+	t.CloseTransaction = txHash
+	t.UpdatedAt = time.Now()
+	if err := f.config.Store.Put(ctx, t); err != nil {
+		return nil, x402.NewSettleError(ErrFailedToSaveTab, t.Owner, network, txHash, err.Error())
+	}
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: txHash,
+		Network:     network,
+		Payer:       t.Owner,
+		Cost:        receipt.Cost(),
+	}, nil
+}
+
+// CloseExpiring closes the open tabs that expire within the given duration.
+// Run it periodically with at least the close margin, so every tab is closed
+// before its owner can reclaim the deposit.
+func (f *TabEvmScheme) CloseExpiring(ctx context.Context, within time.Duration) ([]ClosedTab, error) {
+	tabs, err := f.config.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var closed []ClosedTab
+	deadline := time.Now().Add(within)
+	for _, t := range tabs {
+		if t.Closed() || t.Expiry.After(deadline) {
+			continue
+		}
+		response, err := f.Close(ctx, t.ChannelID)
+		closed = append(closed, ClosedTab{Tab: t, Response: response, Err: err})
+	}
+	return closed, nil
+}
+
+// Tabs returns the tabs the facilitator knows, soonest expiry first
+func (f *TabEvmScheme) Tabs(ctx context.Context) ([]*Tab, error) {
+	return f.config.Store.List(ctx)
+}
+
+// check validates a payment and returns its tab with the voucher applied,
+// and the opening to submit if the payment opens the tab
+func (f *TabEvmScheme) check(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*Tab, *tab.Open, error) {
+	if payload.Accepted.Scheme != tab.SchemeTab {
+		return nil, nil, x402.NewVerifyError(ErrInvalidScheme, "", fmt.Sprintf("invalid scheme: %s", payload.Accepted.Scheme))
+	}
+	if payload.Accepted.Network != requirements.Network {
+		return nil, nil, x402.NewVerifyError(ErrNetworkMismatch, "", fmt.Sprintf("network mismatch: %s != %s", payload.Accepted.Network, requirements.Network))
+	}
+
+	var extra tab.ExtraTab
+	if err := types.UnmarshalExtra(requirements.Extra, &extra); err != nil || !evm.IsValidAddress(extra.Contract) {
+		return nil, nil, x402.NewVerifyError(ErrInvalidRequirementsExtra, "", "requirements have no valid tabContract")
+	}
+	tabPayload, err := tab.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return nil, nil, x402.NewVerifyError(ErrInvalidPayload, "", err.Error())
+	}
+	chainID, err := evm.GetEvmChainId(requirements.Network)
+	if err != nil {
+		return nil, nil, x402.NewVerifyError(ErrNetworkMismatch, "", err.Error())
+	}
+	price, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok || price.Sign() <= 0 {
+		return nil, nil, x402.NewVerifyError(ErrInvalidPayload, "", fmt.Sprintf("invalid required amount: %s", requirements.Amount))
+	}
+	amount, ok := new(big.Int).SetString(tabPayload.Voucher.Amount, 10)
+	if !ok {
+		return nil, nil, x402.NewVerifyError(ErrInvalidPayload, "", fmt.Sprintf("invalid voucher amount: %s", tabPayload.Voucher.Amount))
+	}
+
+	var current *Tab
+	if tabPayload.Open != nil {
+		current, err = f.checkOpen(ctx, tabPayload, requirements, extra, chainID)
+	} else {
+		current, err = f.checkOpenTab(ctx, tabPayload, requirements, extra)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The voucher must pay for this request on top of the previous one
+	previous, _ := new(big.Int).SetString(current.Amount, 10)
+	if minimum := new(big.Int).Add(previous, price); amount.Cmp(minimum) < 0 {
+		return nil, nil, x402.NewVerifyError(ErrVoucherTooLow, current.Owner, fmt.Sprintf("voucher %s is below %s", amount, minimum))
+	}
+	deposit, _ := new(big.Int).SetString(current.Deposit, 10)
+	if amount.Cmp(deposit) > 0 {
+		return nil, nil, x402.NewVerifyError(ErrVoucherExceedsDeposit, current.Owner, fmt.Sprintf("voucher %s exceeds deposit %s", amount, deposit))
+	}
+
+	hash, err := tab.HashVoucher(tabPayload.Voucher, chainID, current.Contract)
+	if err != nil {
+		return nil, nil, x402.NewVerifyError(ErrInvalidPayload, current.Owner, err.Error())
+	}
+	if err := f.verifySignature(ctx, current.Owner, hash, tabPayload.Signature, ErrInvalidVoucherSignature); err != nil {
+		return nil, nil, err
+	}
+
+	current.Amount = amount.String()
+	current.Signature = tabPayload.Signature
+	return current, tabPayload.Open, nil
+}
+
+// checkOpen validates a payment that opens a tab and returns the new tab
+func (f *TabEvmScheme) checkOpen(
+	ctx context.Context,
+	tabPayload *tab.Payload,
+	requirements types.PaymentRequirements,
+	extra tab.ExtraTab,
+	chainID *big.Int,
+) (*Tab, error) {
+	open := tabPayload.Open
+	owner := open.Authorization.From
+
+	existing, err := f.config.Store.Get(ctx, tabPayload.Voucher.ChannelID)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrFailedToLoadTab, owner, err.Error())
+	}
+	if existing != nil {
+		return nil, x402.NewVerifyError(ErrTabAlreadyOpen, owner, fmt.Sprintf("tab %s is already open", tabPayload.Voucher.ChannelID))
+	}
+
+	// The channel id binds the deposit to this payee and expiry
+	channelID, err := tab.ChannelID(requirements.Asset, requirements.PayTo, open.Expiry, open.Salt)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, err.Error())
+	}
+	if !strings.EqualFold(channelID, tabPayload.Voucher.ChannelID) || !strings.EqualFold(channelID, open.Authorization.Nonce) {
+		return nil, x402.NewVerifyError(ErrInvalidChannelID, owner, fmt.Sprintf("expected channel id %s", channelID))
+	}
+	if !strings.EqualFold(open.Authorization.To, extra.Contract) {
+		return nil, x402.NewVerifyError(ErrTabMismatch, owner, fmt.Sprintf("deposit goes to %s, not the tab contract %s", open.Authorization.To, extra.Contract))
+	}
+	expirySeconds, ok := new(big.Int).SetString(open.Expiry, 10)
+	if !ok || !expirySeconds.IsInt64() {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, fmt.Sprintf("invalid expiry: %s", open.Expiry))
+	}
+	expiry := time.Unix(expirySeconds.Int64(), 0)
+	if time.Until(expiry) <= f.config.CloseMargin {
+		return nil, x402.NewVerifyError(ErrTabExpiring, owner, fmt.Sprintf("tab expires at %s", expiry.UTC().Format(time.RFC3339)))
+	}
+	deposit, ok := new(big.Int).SetString(open.Authorization.Value, 10)
+	if !ok || deposit.Sign() <= 0 {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, fmt.Sprintf("invalid deposit: %s", open.Authorization.Value))
+	}
+
+	// The deposit must be submittable now, or the open transaction reverts
+	now := time.Now().Unix()
+	validBefore, ok := new(big.Int).SetString(open.Authorization.ValidBefore, 10)
+	if !ok {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, fmt.Sprintf("invalid validBefore: %s", open.Authorization.ValidBefore))
+	}
+	if validBefore.Cmp(big.NewInt(now+blockTimeBuffer)) < 0 {
+		return nil, x402.NewVerifyError(ErrValidBeforeExpired, owner, fmt.Sprintf("valid before expired: %s < %d", validBefore, now+blockTimeBuffer))
+	}
+	validAfter, ok := new(big.Int).SetString(open.Authorization.ValidAfter, 10)
+	if !ok {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, fmt.Sprintf("invalid validAfter: %s", open.Authorization.ValidAfter))
+	}
+	if validAfter.Cmp(big.NewInt(now)) > 0 {
+		return nil, x402.NewVerifyError(ErrValidAfterInFuture, owner, fmt.Sprintf("valid after in future: %s > %d", validAfter, now))
+	}
+
+	assetInfo, err := evm.GetAssetInfo(requirements.Network, requirements.Asset)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrFailedToGetAssetInfo, owner, err.Error())
+	}
+	domain := evm.ExtraEIP712{Name: assetInfo.Name, Version: assetInfo.Version}
+	if err := types.UnmarshalExtra(requirements.Extra, &domain); err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidRequirementsExtra, owner, err.Error())
+	}
+	hash, err := tab.HashDepositAuthorization(open.Authorization, chainID, assetInfo.Address, domain.Name, domain.Version)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidPayload, owner, err.Error())
+	}
+	if err := f.verifySignature(ctx, owner, hash, open.Signature, ErrInvalidDepositSignature); err != nil {
+		return nil, err
+	}
+
+	nonce, _ := evm.HexToBytes(channelID)
+	used, err := f.signer.ReadContract(ctx, assetInfo.Address, evm.AuthorizationStateABI, evm.FunctionAuthorizationState, common.HexToAddress(owner), [32]byte(nonce))
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrFailedToCheckNonce, owner, err.Error())
+	}
+	if isUsed, ok := used.(bool); !ok || isUsed {
+		return nil, x402.NewVerifyError(ErrNonceAlreadyUsed, owner, fmt.Sprintf("deposit authorization %s already used", channelID))
+	}
+	balance, err := f.signer.GetBalance(ctx, owner, assetInfo.Address)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrFailedToGetBalance, owner, err.Error())
+	}
+	if balance.Cmp(deposit) < 0 {
+		return nil, x402.NewVerifyError(ErrInsufficientBalance, owner, fmt.Sprintf("insufficient balance: %s < %s", balance, deposit))
+	}
+
+	return &Tab{
+		ChannelID: channelID,
+		Network:   requirements.Network,
+		Contract:  extra.Contract,
+		Token:     assetInfo.Address,
+		Owner:     owner,
+		Payee:     requirements.PayTo,
+		Deposit:   deposit.String(),
+		Expiry:    expiry,
+		Amount:    "0",
+	}, nil
+}
+
+// checkOpenTab loads the open tab a voucher is for and checks it matches the
+// requirements
+func (f *TabEvmScheme) checkOpenTab(
+	ctx context.Context,
+	tabPayload *tab.Payload,
+	requirements types.PaymentRequirements,
+	extra tab.ExtraTab,
+) (*Tab, error) {
+	current, err := f.config.Store.Get(ctx, tabPayload.Voucher.ChannelID)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrFailedToLoadTab, "", err.Error())
+	}
+	if current == nil {
+		return nil, x402.NewVerifyError(ErrUnknownTab, "", fmt.Sprintf("unknown tab %s", tabPayload.Voucher.ChannelID))
+	}
+	if current.Closed() {
+		return nil, x402.NewVerifyError(ErrTabClosed, current.Owner, fmt.Sprintf("tab %s is closed", current.ChannelID))
+	}
+	if current.Network != requirements.Network ||
+		!strings.EqualFold(current.Payee, requirements.PayTo) ||
+		!strings.EqualFold(current.Token, requirements.Asset) ||
+		!strings.EqualFold(current.Contract, extra.Contract) {
+		return nil, x402.NewVerifyError(ErrTabMismatch, current.Owner, "tab does not match the payment requirements")
+	}
+	if time.Until(current.Expiry) <= f.config.CloseMargin {
+		return nil, x402.NewVerifyError(ErrTabExpiring, current.Owner, fmt.Sprintf("tab expires at %s", current.Expiry.UTC().Format(time.RFC3339)))
+	}
+	return current, nil
+}
+
+// verifySignature checks that owner signed hash (EOA or deployed smart wallet)
+func (f *TabEvmScheme) verifySignature(ctx context.Context, owner string, hash []byte, signature string, invalidReason string) error {
+	signatureBytes, err := evm.HexToBytes(signature)
+	if err != nil {
+		return x402.NewVerifyError(ErrInvalidSignatureFormat, owner, err.Error())
+	}
+	valid, _, err := evm.VerifyUniversalSignature(ctx, f.signer, owner, [32]byte(hash), signatureBytes, false)
+	if err != nil {
+		return x402.NewVerifyError(ErrFailedToVerifySignature, owner, err.Error())
+	}
+	if !valid {
+		return x402.NewVerifyError(invalidReason, owner, fmt.Sprintf("invalid signature: %s", signature))
+	}
+	return nil
+}
+
+// openTab submits the tab's deposit on-chain and waits for it
+func (f *TabEvmScheme) openTab(ctx context.Context, t *Tab, open *tab.Open) (string, error) {
+	network := x402.Network(t.Network)
+	deposit, _ := new(big.Int).SetString(t.Deposit, 10)
+	expiry, _ := new(big.Int).SetString(open.Expiry, 10)
+	validAfter, okAfter := new(big.Int).SetString(open.Authorization.ValidAfter, 10)
+	validBefore, okBefore := new(big.Int).SetString(open.Authorization.ValidBefore, 10)
+	salt, saltErr := evm.HexToBytes(open.Salt)
+	signature, sigErr := evm.HexToBytes(open.Signature)
+	if !okAfter || !okBefore || saltErr != nil || sigErr != nil {
+		return "", x402.NewSettleError(ErrInvalidPayload, t.Owner, network, "", "malformed deposit authorization")
+	}
+
+	txHash, err := f.signer.WriteContract(
+		ctx,
+		t.Contract,
+		tab.TabOpenABI,
+		tab.FunctionOpen,
+		common.HexToAddress(t.Token),
+		common.HexToAddress(t.Owner),
+		common.HexToAddress(t.Payee),
+		deposit,
+		expiry,
+		validAfter,
+		validBefore,
+		[32]byte(salt),
+		signature,
+	)
+	if err != nil {
+		return "", x402.NewSettleError(ErrFailedToOpenTab, t.Owner, network, "", err.Error())
+	}
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return "", x402.NewSettleError(ErrFailedToGetReceipt, t.Owner, network, txHash, err.Error())
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return "", x402.NewSettleError(ErrTransactionFailed, t.Owner, network, txHash, "")
+	}
+	return txHash, nil
+}
+
+// lock serializes work on one tab, returning the unlock function
+func (f *TabEvmScheme) lock(channelID string) func() {
+	key := strings.ToLower(channelID)
+	f.locksMu.Lock()
+	mu, ok := f.locks[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		f.locks[key] = mu
+	}
+	f.locksMu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
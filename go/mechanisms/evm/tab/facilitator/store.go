@@ -0,0 +1,95 @@
+package facilitator
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tab is the facilitator's record of an open or closed tab
+type Tab struct {
+	ChannelID string    `json:"channelId"`
+	Network   string    `json:"network"`
+	Contract  string    `json:"contract"`
+	Token     string    `json:"token"`
+	Owner     string    `json:"owner"`
+	Payee     string    `json:"payee"`
+	Deposit   string    `json:"deposit"`
+	Expiry    time.Time `json:"expiry"`
+
+	// Amount is the running total of the highest voucher, and Signature the
+	// owner's signature of it
+	Amount    string `json:"amount"`
+	Signature string `json:"signature"`
+
+	OpenTransaction  string    `json:"openTransaction"`
+	CloseTransaction string    `json:"closeTransaction,omitempty"` // Set once closed
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Closed reports whether the tab was closed on-chain
+func (t *Tab) Closed() bool {
+	return t.CloseTransaction != ""
+}
+
+// TabStore persists tabs and their latest vouchers. The last voucher is what
+// the payee is paid when the tab closes, so losing it loses the payments
+// since the tab opened; use durable storage in production.
+// Implementations must be safe for concurrent use.
+type TabStore interface {
+	// Get returns the tab, or nil if it is unknown
+	Get(ctx context.Context, channelID string) (*Tab, error)
+
+	// Put saves the tab
+	Put(ctx context.Context, tab *Tab) error
+
+	// List returns all tabs, soonest expiry first
+	List(ctx context.Context) ([]*Tab, error)
+}
+
+// MemoryTabStore is an in-process TabStore, lost on restart
+type MemoryTabStore struct {
+	mu   sync.Mutex
+	tabs map[string]Tab
+}
+
+// NewMemoryTabStore creates an empty in-process store
+func NewMemoryTabStore() *MemoryTabStore {
+	return &MemoryTabStore{tabs: make(map[string]Tab)}
+}
+
+// Get implements TabStore
+func (s *MemoryTabStore) Get(ctx context.Context, channelID string) (*Tab, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tab, ok := s.tabs[strings.ToLower(channelID)]
+	if !ok {
+		return nil, nil
+	}
+	return &tab, nil
+}
+
+// Put implements TabStore
+func (s *MemoryTabStore) Put(ctx context.Context, tab *Tab) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tabs[strings.ToLower(tab.ChannelID)] = *tab
+	return nil
+}
+
+// List implements TabStore
+func (s *MemoryTabStore) List(ctx context.Context) ([]*Tab, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tabs := make([]*Tab, 0, len(s.tabs))
+	for _, tab := range s.tabs {
+		tab := tab
+		tabs = append(tabs, &tab)
+	}
+	sort.Slice(tabs, func(a, b int) bool {
+		return tabs[a].Expiry.Before(tabs[b].Expiry)
+	})
+	return tabs, nil
+}
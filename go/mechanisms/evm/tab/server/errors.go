@@ -0,0 +1,7 @@
+package server
+
+// Server error constants for the tab EVM scheme (V2)
+const (
+	ErrInvalidTabContract = "invalid_tab_evm_server_invalid_tab_contract"
+	ErrInvalidDeposit     = "invalid_tab_evm_server_invalid_deposit"
+)
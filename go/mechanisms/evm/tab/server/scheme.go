@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	exactserver "github.com/coinbase/x402/go/mechanisms/evm/exact/server"
+	"github.com/coinbase/x402/go/mechanisms/evm/tab"
+	"github.com/coinbase/x402/go/types"
+)
+
+// DefaultDepositMultiple is how many payments a tab's deposit covers when
+// TabEvmSchemeConfig.Deposit is not set
+const DefaultDepositMultiple = 100
+
+// TabEvmSchemeConfig holds configuration for the TabEvmScheme server
+type TabEvmSchemeConfig struct {
+	// Contract is the tab contract clients deposit into (required)
+	Contract string
+
+	// Deposit is the deposit clients are asked to open a tab with, in atomic
+	// units (empty = DefaultDepositMultiple times the price)
+	Deposit string
+}
+
+// TabEvmScheme implements the SchemeNetworkServer interface for EVM tab
+// payments (V2). Prices are parsed like the exact scheme's; requirements
+// also carry the tab contract and deposit.
+type TabEvmScheme struct {
+	*exactserver.ExactEvmScheme
+	config TabEvmSchemeConfig
+}
+
+// NewTabEvmScheme creates a new TabEvmScheme
+func NewTabEvmScheme(config TabEvmSchemeConfig) *TabEvmScheme {
+	return &TabEvmScheme{
+		ExactEvmScheme: exactserver.NewExactEvmScheme(),
+		config:         config,
+	}
+}
+
+// Scheme returns the scheme identifier
+func (s *TabEvmScheme) Scheme() string {
+	return tab.SchemeTab
+}
+
+// EnhancePaymentRequirements adds the token's EIP-712 domain, the tab
+// contract, and the deposit to V2 payment requirements
+func (s *TabEvmScheme) EnhancePaymentRequirements(
+	ctx context.Context,
+	requirements types.PaymentRequirements,
+	supportedKind types.SupportedKind,
+	extensionKeys []string,
+) (types.PaymentRequirements, error) {
+	if !evm.IsValidAddress(s.config.Contract) {
+		return requirements, fmt.Errorf(ErrInvalidTabContract+": %q", s.config.Contract)
+	}
+	requirements, err := s.ExactEvmScheme.EnhancePaymentRequirements(ctx, requirements, supportedKind, extensionKeys)
+	if err != nil {
+		return requirements, err
+	}
+
+	deposit := s.config.Deposit
+	if deposit == "" {
+		price, ok := new(big.Int).SetString(requirements.Amount, 10)
+		if !ok {
+			return requirements, fmt.Errorf(ErrInvalidDeposit+": cannot derive from amount %q", requirements.Amount)
+		}
+		deposit = price.Mul(price, big.NewInt(DefaultDepositMultiple)).String()
+	} else if amount, ok := new(big.Int).SetString(deposit, 10); !ok || amount.Sign() <= 0 {
+		return requirements, fmt.Errorf(ErrInvalidDeposit+": %s", deposit)
+	}
+
+	contract, _ := evm.ChecksumAddress(s.config.Contract)
+	extra, err := types.MergeExtra(requirements.Extra, tab.ExtraTab{Contract: contract, Deposit: deposit}, true)
+	if err != nil {
+		return requirements, err
+	}
+	requirements.Extra = extra
+	return requirements, nil
+}
@@ -0,0 +1,258 @@
+// Package tab holds the types shared by the client, server, and facilitator
+// sides of the EVM tab scheme.
+//
+// A tab is a payment channel. The client deposits funds into a tab contract
+// once, then pays for each request with an off-chain voucher for the running
+// total. The facilitator closes the tab with the last voucher, paying the
+// payee and refunding the rest, so any number of requests costs two on-chain
+// transactions.
+package tab
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+const (
+	// Scheme identifier
+	SchemeTab = "tab"
+
+	// Tab contract function names
+	FunctionOpen  = "open"
+	FunctionClose = "close"
+
+	// EIP-712 domain of the tab contract, used for vouchers
+	VoucherDomainName    = "x402 Tab"
+	VoucherDomainVersion = "1"
+)
+
+// Tab contract ABIs. The reference contract is contracts/evm/src/x402Tab.sol;
+// see specs/schemes/tab/scheme_tab_evm.md.
+var (
+	// TabOpenABI for opening a tab. The contract pulls the deposit with the
+	// owner's EIP-3009 receiveWithAuthorization, whose nonce must be
+	// ChannelID(token, payee, expiry, salt), so the payee and expiry cannot be
+	// changed by whoever submits it.
+	TabOpenABI = []byte(`[
+		{
+			"type": "function",
+			"name": "open",
+			"inputs": [
+				{"name": "token", "type": "address"},
+				{"name": "owner", "type": "address"},
+				{"name": "payee", "type": "address"},
+				{"name": "deposit", "type": "uint256"},
+				{"name": "expiry", "type": "uint256"},
+				{"name": "validAfter", "type": "uint256"},
+				{"name": "validBefore", "type": "uint256"},
+				{"name": "salt", "type": "bytes32"},
+				{"name": "signature", "type": "bytes"}
+			],
+			"outputs": [],
+			"stateMutability": "nonpayable"
+		}
+	]`)
+
+	// TabCloseABI for closing a tab with the owner's voucher. Only the payee
+	// or the account that opened the tab may close it. The contract pays the
+	// voucher amount to the payee and refunds the rest to the owner; after
+	// expiry, the owner can reclaim a tab that was never closed.
+	TabCloseABI = []byte(`[
+		{
+			"type": "function",
+			"name": "close",
+			"inputs": [
+				{"name": "channelId", "type": "bytes32"},
+				{"name": "amount", "type": "uint256"},
+				{"name": "signature", "type": "bytes"}
+			],
+			"outputs": [],
+			"stateMutability": "nonpayable"
+		}
+	]`)
+)
+
+// ExtraTab configures the tab in requirements.Extra
+type ExtraTab struct {
+	Contract string `json:"tabContract"`       // Tab contract address
+	Deposit  string `json:"deposit,omitempty"` // Deposit to open a tab with, in atomic units
+}
+
+// Voucher authorizes the payee to collect Amount, the running total of the
+// tab in atomic units, when the tab is closed
+type Voucher struct {
+	ChannelID string `json:"channelId"` // bytes32 hex
+	Amount    string `json:"amount"`
+}
+
+// Open carries what the facilitator needs to open a tab on-chain. The
+// authorization transfers the deposit to the tab contract, with
+// ChannelID(token, payee, expiry, salt) as its nonce.
+type Open struct {
+	Expiry        string                        `json:"expiry"` // Unix timestamp
+	Salt          string                        `json:"salt"`   // bytes32 hex
+	Authorization evm.ExactEIP3009Authorization `json:"authorization"`
+	Signature     string                        `json:"signature"`
+}
+
+// Payload is the tab payment payload: a signed voucher, plus the tab
+// opening on the first payment
+type Payload struct {
+	Voucher   Voucher `json:"voucher"`
+	Signature string  `json:"signature"` // Owner's EIP-712 signature of the voucher
+	Open      *Open   `json:"open,omitempty"`
+}
+
+// ToMap converts the payload to a map for JSON marshaling
+func (p *Payload) ToMap() map[string]interface{} {
+	data, _ := json.Marshal(p)
+	var result map[string]interface{}
+	_ = json.Unmarshal(data, &result)
+	return result
+}
+
+// PayloadFromMap creates a Payload from a map
+func PayloadFromMap(data map[string]interface{}) (*Payload, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var payload Payload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("invalid tab payload: %w", err)
+	}
+	if payload.Voucher.ChannelID == "" || payload.Voucher.Amount == "" {
+		return nil, fmt.Errorf("invalid tab payload: missing voucher")
+	}
+	return &payload, nil
+}
+
+// ChannelID derives a tab's id, which is also the nonce of its deposit
+// authorization: keccak256(abi.encode(token, payee, expiry, salt))
+func ChannelID(token, payee, expiry, salt string) (string, error) {
+	expiryInt, ok := new(big.Int).SetString(expiry, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid expiry: %s", expiry)
+	}
+	saltBytes, err := evm.HexToBytes(salt)
+	if err != nil || len(saltBytes) != 32 {
+		return "", fmt.Errorf("invalid salt: %s", salt)
+	}
+
+	encoded := make([]byte, 0, 128)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(token).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(payee).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(expiryInt.Bytes(), 32)...)
+	encoded = append(encoded, saltBytes...)
+	return evm.BytesToHex(crypto.Keccak256(encoded)), nil
+}
+
+// VoucherTypes are the EIP-712 types of a voucher
+var VoucherTypes = map[string][]evm.TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"Voucher": {
+		{Name: "channelId", Type: "bytes32"},
+		{Name: "amount", Type: "uint256"},
+	},
+}
+
+// VoucherDomain returns the EIP-712 domain of vouchers for a tab contract
+func VoucherDomain(chainID *big.Int, contract string) evm.TypedDataDomain {
+	return evm.TypedDataDomain{
+		Name:              VoucherDomainName,
+		Version:           VoucherDomainVersion,
+		ChainID:           chainID,
+		VerifyingContract: contract,
+	}
+}
+
+// VoucherMessage returns the EIP-712 message of a voucher
+func VoucherMessage(voucher Voucher) (map[string]interface{}, error) {
+	channelID, err := evm.HexToBytes(voucher.ChannelID)
+	if err != nil || len(channelID) != 32 {
+		return nil, fmt.Errorf("invalid channel id: %s", voucher.ChannelID)
+	}
+	amount, ok := new(big.Int).SetString(voucher.Amount, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid voucher amount: %s", voucher.Amount)
+	}
+	return map[string]interface{}{"channelId": channelID, "amount": amount}, nil
+}
+
+// HashVoucher hashes a voucher for signing or verification
+func HashVoucher(voucher Voucher, chainID *big.Int, contract string) ([]byte, error) {
+	message, err := VoucherMessage(voucher)
+	if err != nil {
+		return nil, err
+	}
+	return evm.HashTypedData(VoucherDomain(chainID, contract), VoucherTypes, "Voucher", message)
+}
+
+// ReceiveWithAuthorizationTypes are the EIP-712 types of the deposit
+// authorization (EIP-3009 receiveWithAuthorization)
+var ReceiveWithAuthorizationTypes = map[string][]evm.TypedDataField{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"ReceiveWithAuthorization": {
+		{Name: "from", Type: "address"},
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "validAfter", Type: "uint256"},
+		{Name: "validBefore", Type: "uint256"},
+		{Name: "nonce", Type: "bytes32"},
+	},
+}
+
+// AuthorizationMessage returns the EIP-712 message of a deposit authorization
+func AuthorizationMessage(authorization evm.ExactEIP3009Authorization) (map[string]interface{}, error) {
+	value, ok := new(big.Int).SetString(authorization.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid authorization value: %s", authorization.Value)
+	}
+	validAfter, ok := new(big.Int).SetString(authorization.ValidAfter, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid validAfter: %s", authorization.ValidAfter)
+	}
+	validBefore, ok := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid validBefore: %s", authorization.ValidBefore)
+	}
+	nonce, err := evm.HexToBytes(authorization.Nonce)
+	if err != nil || len(nonce) != 32 {
+		return nil, fmt.Errorf("invalid nonce: %s", authorization.Nonce)
+	}
+	return map[string]interface{}{
+		"from":        common.HexToAddress(authorization.From).Hex(),
+		"to":          common.HexToAddress(authorization.To).Hex(),
+		"value":       value,
+		"validAfter":  validAfter,
+		"validBefore": validBefore,
+		"nonce":       nonce,
+	}, nil
+}
+
+// HashDepositAuthorization hashes a deposit authorization for signing or
+// verification against the token's EIP-712 domain
+func HashDepositAuthorization(authorization evm.ExactEIP3009Authorization, chainID *big.Int, token, tokenName, tokenVersion string) ([]byte, error) {
+	message, err := AuthorizationMessage(authorization)
+	if err != nil {
+		return nil, err
+	}
+	domain := evm.TypedDataDomain{Name: tokenName, Version: tokenVersion, ChainID: chainID, VerifyingContract: token}
+	return evm.HashTypedData(domain, ReceiveWithAuthorizationTypes, "ReceiveWithAuthorization", message)
+}
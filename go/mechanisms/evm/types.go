@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+
+	x402 "github.com/coinbase/x402/go"
 )
 
 // ExactEIP3009Authorization represents the EIP-3009 TransferWithAuthorization data
@@ -42,6 +44,19 @@ const (
 	AssetTransferMethodPermit2 AssetTransferMethod = "permit2"
 )
 
+// ExtraEIP712 is the EIP-712 domain of the token, carried in requirements.Extra
+// for EIP-3009 signing. Empty fields fall back to the asset's defaults.
+type ExtraEIP712 struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ExtraAssetTransfer selects the transfer method in requirements.Extra
+// (default: AssetTransferMethodEIP3009)
+type ExtraAssetTransfer struct {
+	AssetTransferMethod AssetTransferMethod `json:"assetTransferMethod,omitempty"`
+}
+
 // Permit2TokenPermissions represents the permitted token and amount for Permit2.
 // This is part of the PermitWitnessTransferFrom message structure that gets signed.
 type Permit2TokenPermissions struct {
@@ -235,6 +250,19 @@ type FacilitatorEvmSigner interface {
 	GetCode(ctx context.Context, address string) ([]byte, error)
 }
 
+// GasEstimator is optionally implemented by facilitator signers that can
+// estimate a contract call's gas before sending it (eth_estimateGas from the
+// address WriteContract would use). Estimation fails if the call would revert.
+type GasEstimator interface {
+	EstimateContractGas(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (uint64, error)
+}
+
+// GasPriceReader is optionally implemented by facilitator signers that can
+// suggest the current gas price in wei (eth_gasPrice), used to project fees
+type GasPriceReader interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
 // TypedDataDomain represents the EIP-712 domain separator
 type TypedDataDomain struct {
 	Name              string   `json:"name"`
@@ -254,6 +282,24 @@ type TransactionReceipt struct {
 	Status      uint64 `json:"status"`
 	BlockNumber uint64 `json:"blockNumber"`
 	TxHash      string `json:"transactionHash"`
+
+	// GasUsed and EffectiveGasPrice report the transaction's cost (optional)
+	GasUsed           uint64   `json:"gasUsed,omitempty"`
+	EffectiveGasPrice *big.Int `json:"effectiveGasPrice,omitempty"`
+}
+
+// Cost returns the settlement cost recorded in the receipt, or nil if the
+// signer did not report gas used
+func (r *TransactionReceipt) Cost() *x402.SettlementCost {
+	if r == nil || r.GasUsed == 0 {
+		return nil
+	}
+	cost := &x402.SettlementCost{GasUsed: r.GasUsed}
+	if r.EffectiveGasPrice != nil {
+		cost.GasPrice = r.EffectiveGasPrice.String()
+		cost.Fee = new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed)).String()
+	}
+	return cost
 }
 
 // AssetInfo contains information about an ERC20 token
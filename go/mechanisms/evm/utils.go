@@ -7,31 +7,33 @@ import (
 	"math/big"
 	"strings"
 	"time"
-)
 
-// GetEvmChainId returns the chain ID for a given network
-func GetEvmChainId(network string) (*big.Int, error) {
-	networkStr := network
+	"github.com/coinbase/x402/go/caip"
+	"github.com/ethereum/go-ethereum/common"
+)
 
-	// Normalize network name
-	switch networkStr {
+// normalizeNetworkName maps legacy network names onto their CAIP-2 identifiers
+func normalizeNetworkName(network string) string {
+	switch network {
 	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
+		return "eip155:8453"
 	case "base-sepolia":
-		networkStr = "eip155:84532"
+		return "eip155:84532"
 	}
+	return network
+}
+
+// GetEvmChainId returns the chain ID for a given network
+func GetEvmChainId(network string) (*big.Int, error) {
+	networkStr := normalizeNetworkName(network)
 
 	if config, ok := NetworkConfigs[networkStr]; ok {
 		return config.ChainID, nil
 	}
 
 	// Try to parse from CAIP-2 format (eip155:chainId)
-	if strings.HasPrefix(networkStr, "eip155:") {
-		chainIdStr := strings.TrimPrefix(networkStr, "eip155:")
-		chainId, ok := new(big.Int).SetString(chainIdStr, 10)
-		if ok {
-			return chainId, nil
-		}
+	if chain, err := caip.ParseChainID(networkStr); err == nil && chain.IsEVM() {
+		return chain.EVMChainID()
 	}
 
 	return nil, fmt.Errorf("unsupported network: %s", network)
@@ -68,7 +70,9 @@ func MaxUint256() *big.Int {
 	return max
 }
 
-// NormalizeAddress ensures an Ethereum address is in the correct format
+// NormalizeAddress lowercases an Ethereum address with a 0x prefix, for use as
+// a comparison or lookup key. Use ChecksumAddress to format addresses for display
+// or for requirements sent to clients.
 func NormalizeAddress(address string) string {
 	// Remove 0x prefix if present
 	addr := strings.TrimPrefix(strings.ToLower(address), "0x")
@@ -78,6 +82,8 @@ func NormalizeAddress(address string) string {
 }
 
 // IsValidAddress checks if a string is a valid Ethereum address
+// (40 hex characters, optionally 0x-prefixed). It does not check the EIP-55
+// checksum; use ValidateAddress for that.
 func IsValidAddress(address string) bool {
 	// Remove 0x prefix if present
 	addr := strings.TrimPrefix(address, "0x")
@@ -92,6 +98,42 @@ func IsValidAddress(address string) bool {
 	return err == nil
 }
 
+// IsChecksummedAddress reports whether an address is in its EIP-55 checksummed form
+func IsChecksummedAddress(address string) bool {
+	return IsValidAddress(address) && address == common.HexToAddress(address).Hex()
+}
+
+// ValidateAddress checks an address and its EIP-55 checksum. Mixed-case
+// addresses must carry a valid checksum, so a mistyped checksummed address is
+// rejected. All-lowercase and all-uppercase addresses carry no checksum and
+// are accepted unless requireChecksum is set.
+func ValidateAddress(address string, requireChecksum bool) error {
+	if !IsValidAddress(address) {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+	addr := strings.TrimPrefix(address, "0x")
+	if addr == strings.TrimPrefix(common.HexToAddress(addr).Hex(), "0x") {
+		return nil
+	}
+
+	unchecksummed := addr == strings.ToLower(addr) || addr == strings.ToUpper(addr)
+	if !unchecksummed {
+		return fmt.Errorf("invalid EIP-55 checksum: %s", address)
+	}
+	if requireChecksum {
+		return fmt.Errorf("address is not checksummed: %s (expected %s)", address, common.HexToAddress(address).Hex())
+	}
+	return nil
+}
+
+// ChecksumAddress validates an address and formats it in its EIP-55 checksummed form
+func ChecksumAddress(address string) (string, error) {
+	if err := ValidateAddress(address, false); err != nil {
+		return "", err
+	}
+	return common.HexToAddress(address).Hex(), nil
+}
+
 // ParseAmount converts a decimal string amount to wei based on token decimals
 func ParseAmount(amount string, decimals int) (*big.Int, error) {
 	// Parse the decimal amount
@@ -167,15 +209,7 @@ func FormatAmount(amount *big.Int, decimals int) string {
 //   - NetworkConfig with chain ID (and default asset if configured)
 //   - Error if the network format is invalid
 func GetNetworkConfig(network string) (*NetworkConfig, error) {
-	networkStr := network
-
-	// Normalize network name
-	switch networkStr {
-	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
-	case "base-sepolia":
-		networkStr = "eip155:84532"
-	}
+	networkStr := normalizeNetworkName(network)
 
 	// Check if we have a pre-configured network with default asset
 	if config, ok := NetworkConfigs[networkStr]; ok {
@@ -183,10 +217,8 @@ func GetNetworkConfig(network string) (*NetworkConfig, error) {
 	}
 
 	// For any valid EIP-155 network, dynamically create a config with just the chain ID
-	if strings.HasPrefix(networkStr, "eip155:") {
-		chainIdStr := strings.TrimPrefix(networkStr, "eip155:")
-		chainId, ok := new(big.Int).SetString(chainIdStr, 10)
-		if ok {
+	if chain, err := caip.ParseChainID(networkStr); err == nil && chain.IsEVM() {
+		if chainId, err := chain.EVMChainID(); err == nil {
 			return &NetworkConfig{
 				ChainID: chainId,
 				// No DefaultAsset - callers using TokenAsset don't need it
@@ -209,6 +241,15 @@ func GetNetworkConfig(network string) (*NetworkConfig, error) {
 //   - AssetInfo for the requested asset
 //   - Error if default asset is requested but not configured for this network
 func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, error) {
+	// Unwrap CAIP-19 asset IDs (eip155:8453/erc20:0x...) to the token address
+	if asset, err := caip.ParseAssetID(assetSymbolOrAddress); err == nil {
+		address, err := erc20AssetAddress(network, asset)
+		if err != nil {
+			return nil, err
+		}
+		assetSymbolOrAddress = address
+	}
+
 	// Check if it's an explicit address - works for ANY network
 	if IsValidAddress(assetSymbolOrAddress) {
 		normalizedAddr := NormalizeAddress(assetSymbolOrAddress)
@@ -244,12 +285,70 @@ func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, erro
 	return &config.DefaultAsset, nil
 }
 
+// erc20AssetAddress returns the token address of a CAIP-19 asset on the given network
+func erc20AssetAddress(network string, asset caip.AssetID) (string, error) {
+	if asset.AssetNamespace != caip.AssetNamespaceERC20 {
+		return "", fmt.Errorf("unsupported asset namespace %q: expected %s", asset.AssetNamespace, caip.AssetNamespaceERC20)
+	}
+	chainId, err := GetEvmChainId(network)
+	if err != nil {
+		return "", err
+	}
+	assetChainId, err := asset.ChainID.EVMChainID()
+	if err != nil {
+		return "", err
+	}
+	if assetChainId.Cmp(chainId) != 0 {
+		return "", fmt.Errorf("asset %s is not on network %s", asset, network)
+	}
+	return asset.AssetReference, nil
+}
+
 // CreateValidityWindow creates valid after/before timestamps
 func CreateValidityWindow(duration time.Duration) (validAfter, validBefore *big.Int) {
+	return ValidityWindowConfig{ValidFor: duration}.Create(0)
+}
+
+// Defaults for the validity window of client authorizations
+const (
+	// DefaultValidAfterBuffer backdates validAfter to account for clock skew
+	// and block time
+	DefaultValidAfterBuffer = 30 * time.Second
+
+	// DefaultValidFor is how long an authorization stays valid
+	DefaultValidFor = time.Hour
+)
+
+// ValidityWindowConfig sets the validAfter/validBefore bounds a client signs
+// into a transfer authorization. Zero fields use the defaults.
+type ValidityWindowConfig struct {
+	// ValidAfterBuffer is subtracted from now for validAfter
+	// (default DefaultValidAfterBuffer)
+	ValidAfterBuffer time.Duration
+
+	// ValidFor is added to now for validBefore (default DefaultValidFor)
+	ValidFor time.Duration
+}
+
+// Create returns the validAfter/validBefore timestamps. A positive
+// maxTimeoutSeconds (the requirements' MaxTimeoutSeconds) caps the window,
+// since the server won't settle the payment any later.
+func (c ValidityWindowConfig) Create(maxTimeoutSeconds int) (validAfter, validBefore *big.Int) {
+	buffer := c.ValidAfterBuffer
+	if buffer <= 0 {
+		buffer = DefaultValidAfterBuffer
+	}
+	validFor := c.ValidFor
+	if validFor <= 0 {
+		validFor = DefaultValidFor
+	}
+	if maxTimeout := time.Duration(maxTimeoutSeconds) * time.Second; maxTimeout > 0 && maxTimeout < validFor {
+		validFor = maxTimeout
+	}
+
 	now := time.Now().Unix()
-	// Add 30 second buffer to account for clock skew and block time
-	validAfter = big.NewInt(now - 30)
-	validBefore = big.NewInt(now + int64(duration.Seconds()))
+	validAfter = big.NewInt(now - int64(buffer.Seconds()))
+	validBefore = big.NewInt(now + int64(validFor.Seconds()))
 	return validAfter, validBefore
 }
 
@@ -0,0 +1,113 @@
+package evm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetEvmChainId(t *testing.T) {
+	tests := []struct {
+		network string
+		want    int64
+		wantErr bool
+	}{
+		{"base", 8453, false},
+		{"base-sepolia", 84532, false},
+		{"eip155:8453", 8453, false},
+		{"eip155:999999", 999999, false},
+		{"eip155:base", 0, true},
+		{"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			chainId, err := GetEvmChainId(tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEvmChainId(%q) error = %v, wantErr %v", tt.network, err, tt.wantErr)
+			}
+			if err == nil && chainId.Int64() != tt.want {
+				t.Errorf("expected %d, got %s", tt.want, chainId)
+			}
+		})
+	}
+}
+
+func TestGetAssetInfoAcceptsCAIP19(t *testing.T) {
+	usdc := NetworkConfigs["eip155:8453"].DefaultAsset.Address
+
+	info, err := GetAssetInfo("eip155:8453", "eip155:8453/erc20:"+usdc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Address != usdc {
+		t.Errorf("expected default asset %s, got %s", usdc, info.Address)
+	}
+
+	if _, err := GetAssetInfo("eip155:84532", "eip155:8453/erc20:"+usdc); err == nil {
+		t.Error("expected error for asset on a different chain")
+	}
+	if _, err := GetAssetInfo("eip155:8453", "eip155:8453/slip44:60"); err == nil {
+		t.Error("expected error for non-erc20 asset")
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	const checksummed = "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+
+	tests := []struct {
+		name            string
+		address         string
+		requireChecksum bool
+		wantErr         bool
+	}{
+		{"checksummed", checksummed, true, false},
+		{"checksummed without prefix", checksummed[2:], true, false},
+		{"lowercase", strings.ToLower(checksummed), false, false},
+		{"lowercase when checksum required", strings.ToLower(checksummed), true, true},
+		{"bad checksum", "0xD8dA6BF26964aF9D7eEd9e03E53415D37aA96045", false, true},
+		{"too short", "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA960", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAddress(tt.address, tt.requireChecksum)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAddress(%q, %t) error = %v, wantErr %v", tt.address, tt.requireChecksum, err, tt.wantErr)
+			}
+		})
+	}
+
+	formatted, err := ChecksumAddress(strings.ToLower(checksummed))
+	if err != nil || formatted != checksummed {
+		t.Errorf("ChecksumAddress() = %q (%v), expected %s", formatted, err, checksummed)
+	}
+	if !IsChecksummedAddress(checksummed) || IsChecksummedAddress(strings.ToLower(checksummed)) {
+		t.Error("IsChecksummedAddress did not distinguish checksummed from lowercase")
+	}
+}
+
+func TestValidityWindowConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         ValidityWindowConfig
+		maxTimeout     int
+		buffer, length int64
+	}{
+		{"defaults", ValidityWindowConfig{}, 0, 30, 3600},
+		{"capped by the server timeout", ValidityWindowConfig{}, 60, 30, 60},
+		{"configured", ValidityWindowConfig{ValidAfterBuffer: 10 * time.Second, ValidFor: 2 * time.Minute}, 0, 10, 120},
+		{"shorter than the server timeout", ValidityWindowConfig{ValidFor: time.Minute}, 300, 30, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validAfter, validBefore := tt.config.Create(tt.maxTimeout)
+			if got := validBefore.Int64() - validAfter.Int64(); got != tt.buffer+tt.length {
+				t.Errorf("expected a %ds window, got %ds", tt.buffer+tt.length, got)
+			}
+			if skew := time.Now().Unix() - validAfter.Int64() - tt.buffer; skew < 0 || skew > 1 {
+				t.Errorf("expected validAfter %ds before now, got %ds", tt.buffer, tt.buffer+skew)
+			}
+		})
+	}
+}
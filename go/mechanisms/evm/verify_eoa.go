@@ -28,8 +28,33 @@ func VerifyEOASignature(
 	signature []byte,
 	expectedAddress common.Address,
 ) (bool, error) {
+	recoveredAddress, err := RecoverSigner(hash, signature)
+	if err != nil {
+		return false, err
+	}
+
+	// Compare the recovered address with the expected address
+	return recoveredAddress == expectedAddress, nil
+}
+
+// RecoverSigner recovers the address that produced an ECDSA signature over a hash
+//
+// Servers can use it to show who signed a payload without a verify round trip;
+// it does not check that the signature authorizes anything. Both Ethereum
+// (27/28) and raw (0/1) v values are accepted.
+//
+// Args:
+//
+//	hash: The 32-byte message hash that was signed
+//	signature: The 65-byte ECDSA signature (r: 32 bytes, s: 32 bytes, v: 1 byte)
+//
+// Returns:
+//
+//	The signer's address
+//	error if the signature is malformed or recovery fails
+func RecoverSigner(hash []byte, signature []byte) (common.Address, error) {
 	if len(signature) != 65 {
-		return false, errors.New("invalid EOA signature length: expected 65 bytes")
+		return common.Address{}, errors.New("invalid EOA signature length: expected 65 bytes")
 	}
 
 	// Create a copy to avoid modifying the original signature
@@ -46,12 +71,9 @@ func VerifyEOASignature(
 	// Recover the public key from the signature
 	pubKey, err := crypto.SigToPub(hash, sig)
 	if err != nil {
-		return false, err
+		return common.Address{}, err
 	}
 
 	// Derive the Ethereum address from the recovered public key
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
-
-	// Compare the recovered address with the expected address
-	return recoveredAddress == expectedAddress, nil
+	return crypto.PubkeyToAddress(*pubKey), nil
 }
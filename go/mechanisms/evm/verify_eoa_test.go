@@ -141,3 +141,27 @@ func TestVerifyEOASignature_VValueAdjustment(t *testing.T) {
 		}
 	})
 }
+
+// TestRecoverSigner tests recovering the signer address of a signature
+func TestRecoverSigner(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	testHash := crypto.Keccak256([]byte("test"))
+	sig, _ := crypto.Sign(testHash, privateKey)
+
+	got, err := RecoverSigner(testHash, sig)
+	if err != nil || got != address {
+		t.Errorf("RecoverSigner() = %v, %v, want %v", got, err, address)
+	}
+
+	// The caller's signature is not modified by the v adjustment
+	sig[64] += 27
+	got, err = RecoverSigner(testHash, sig)
+	if err != nil || got != address || sig[64] < 27 {
+		t.Errorf("RecoverSigner() with v=27/28 = %v, %v, want %v", got, err, address)
+	}
+
+	if _, err := RecoverSigner(testHash, sig[:64]); err == nil {
+		t.Error("RecoverSigner() should reject a 64-byte signature")
+	}
+}
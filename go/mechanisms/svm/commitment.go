@@ -0,0 +1,40 @@
+package svm
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// commitmentKey is the context key for a facilitator's commitment level
+type commitmentKey struct{}
+
+// ContextWithCommitment returns a context asking facilitator signers to
+// simulate, send, and confirm transactions at a commitment level
+func ContextWithCommitment(ctx context.Context, commitment rpc.CommitmentType) context.Context {
+	return context.WithValue(ctx, commitmentKey{}, commitment)
+}
+
+// CommitmentFromContext returns the commitment level stored by
+// ContextWithCommitment, or DefaultCommitment. FacilitatorSvmSigner
+// implementations use it for their RPC calls.
+func CommitmentFromContext(ctx context.Context) rpc.CommitmentType {
+	if commitment, ok := ctx.Value(commitmentKey{}).(rpc.CommitmentType); ok && commitment != "" {
+		return commitment
+	}
+	return DefaultCommitment
+}
+
+// CommitmentReached reports whether a transaction's confirmation status
+// satisfies a commitment level
+func CommitmentReached(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	switch status {
+	case rpc.ConfirmationStatusFinalized:
+		return true
+	case rpc.ConfirmationStatusConfirmed:
+		return commitment != rpc.CommitmentFinalized
+	case rpc.ConfirmationStatusProcessed:
+		return commitment == rpc.CommitmentProcessed
+	}
+	return false
+}
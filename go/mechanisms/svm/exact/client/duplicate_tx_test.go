@@ -486,3 +486,43 @@ func TestMemoInstructionHasNoSigners(t *testing.T) {
 	// Empty accounts is critical - signers break facilitator verification
 	assert.Empty(t, memoIx.Accounts, "memo must have no accounts")
 }
+
+// TestMemoCarriesAttribution verifies the server's attribution is written to
+// the memo next to the nonce, so the payment can be tied to an order on-chain
+func TestMemoCarriesAttribution(t *testing.T) {
+	server := httptest.NewServer(mockSolanaRPCHandler(t, func() string {
+		return fixedBlockhash
+	}))
+	defer server.Close()
+
+	signer := &mockClientSigner{keypair: solana.NewWallet().PrivateKey}
+	client := NewExactSvmScheme(signer, &svm.ClientConfig{RPCURL: server.URL})
+
+	requirements := types.PaymentRequirements{
+		Scheme:            "exact",
+		Network:           "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+		Asset:             "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		Amount:            "100000",
+		PayTo:             solana.NewWallet().PublicKey().String(),
+		MaxTimeoutSeconds: 3600,
+		Extra: map[string]interface{}{
+			"feePayer":                solana.NewWallet().PublicKey().String(),
+			types.AttributionExtraKey: map[string]interface{}{"orderId": "ord_123"},
+		},
+	}
+
+	payload, err := client.CreatePaymentPayload(context.Background(), requirements)
+	require.NoError(t, err)
+
+	decoded, err := svm.DecodeTransaction(payload.Payload["transaction"].(string))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(decoded.Message.Instructions), 4)
+
+	var memo struct {
+		Attribution map[string]string `json:"attribution"`
+		Nonce       string            `json:"nonce"`
+	}
+	require.NoError(t, json.Unmarshal(decoded.Message.Instructions[3].Data, &memo))
+	assert.Equal(t, "ord_123", memo.Attribution["orderId"])
+	assert.Len(t, memo.Nonce, 32, "memo should keep the uniqueness nonce")
+}
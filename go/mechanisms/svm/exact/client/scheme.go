@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -111,10 +112,11 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 	}
 
 	// Get fee payer from requirements.extra
-	feePayerAddr, ok := requirements.Extra["feePayer"].(string)
-	if !ok {
+	var extra svm.ExtraFeePayer
+	if err := types.UnmarshalExtra(requirements.Extra, &extra); err != nil || extra.FeePayer == "" {
 		return types.PaymentPayload{}, errors.New(ErrFeePayerRequired)
 	}
+	feePayerAddr := extra.FeePayer
 
 	feePayer, err := solana.PublicKeyFromBase58(feePayerAddr)
 	if err != nil {
@@ -163,15 +165,19 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToBuildTransferIx+": %w", err)
 	}
 
-	// Memo with random nonce for transaction uniqueness (empty accounts - SPL Memo doesn't require signers)
+	// Memo with random nonce for transaction uniqueness, and the server's attribution if any (empty accounts - SPL Memo doesn't require signers)
 	memoBytes := make([]byte, 16)
 	if _, err := rand.Read(memoBytes); err != nil {
 		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToBuildMemoIx+": %w", err)
 	}
+	memoData, err := paymentMemo(hex.EncodeToString(memoBytes), types.Attribution(requirements.Extra))
+	if err != nil {
+		return types.PaymentPayload{}, fmt.Errorf(ErrFailedToBuildMemoIx+": %w", err)
+	}
 	memoIx := solana.NewInstruction(
 		solana.MustPublicKeyFromBase58(svm.MemoProgramAddress),
 		solana.AccountMetaSlice{},
-		[]byte(hex.EncodeToString(memoBytes)),
+		memoData,
 	)
 
 	// Create final transaction
@@ -214,3 +220,19 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 		Payload:     svmPayload.ToMap(),
 	}, nil
 }
+
+// paymentMemo returns the memo data: the nonce alone, or with attribution the
+// JSON object {"attribution": {...}, "nonce": "<hex>"} so the payment can be
+// tied to a business object from the chain alone
+func paymentMemo(nonce string, attribution map[string]string) ([]byte, error) {
+	if len(attribution) == 0 {
+		return []byte(nonce), nil
+	}
+	if err := types.ValidateAttribution(attribution); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Attribution map[string]string `json:"attribution"`
+		Nonce       string            `json:"nonce"`
+	}{attribution, nonce})
+}
@@ -0,0 +1,102 @@
+package facilitator
+
+import (
+	"context"
+	"testing"
+
+	solana "github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/x402/go/mechanisms/svm"
+	"github.com/coinbase/x402/go/types"
+)
+
+// recordingSigner signs nothing and records the commitment of each simulation
+type recordingSigner struct {
+	feePayer    solana.PublicKey
+	simulations []rpc.CommitmentType
+}
+
+func (s *recordingSigner) GetAddresses(ctx context.Context, network string) []solana.PublicKey {
+	return []solana.PublicKey{s.feePayer}
+}
+
+func (s *recordingSigner) SignTransaction(ctx context.Context, tx *solana.Transaction, feePayer solana.PublicKey, network string) error {
+	return nil
+}
+
+func (s *recordingSigner) SimulateTransaction(ctx context.Context, tx *solana.Transaction, network string) error {
+	s.simulations = append(s.simulations, svm.CommitmentFromContext(ctx))
+	return nil
+}
+
+func (s *recordingSigner) SendTransaction(ctx context.Context, tx *solana.Transaction, network string) (solana.Signature, error) {
+	return solana.Signature{}, nil
+}
+
+func (s *recordingSigner) ConfirmTransaction(ctx context.Context, signature solana.Signature, network string) error {
+	return nil
+}
+
+func (f instructionFixture) payment(t *testing.T) (types.PaymentPayload, types.PaymentRequirements) {
+	t.Helper()
+	tx := f.build(t, f.feePayer, append(f.computeInstructions(t), f.transferInstruction(t, f.client), memoInstruction())...)
+	encoded, err := svm.EncodeTransaction(tx)
+	require.NoError(t, err)
+	requirements := types.PaymentRequirements{
+		Scheme:  f.requirements.Scheme,
+		Network: f.requirements.Network,
+		Asset:   f.requirements.Asset,
+		Amount:  f.requirements.Amount,
+		PayTo:   f.requirements.PayTo,
+		Extra:   map[string]interface{}{"feePayer": f.feePayer.String()},
+	}
+	return types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"transaction": encoded}}, requirements
+}
+
+func TestVerifySimulationConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		config *ExactSvmSchemeConfig
+		want   []rpc.CommitmentType
+	}{
+		{"defaults to confirmed", nil, []rpc.CommitmentType{rpc.CommitmentConfirmed}},
+		{"uses the configured commitment", &ExactSvmSchemeConfig{Commitment: rpc.CommitmentFinalized}, []rpc.CommitmentType{rpc.CommitmentFinalized}},
+		{"skips simulation", &ExactSvmSchemeConfig{SkipSimulation: true}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := newInstructionFixture()
+			signer := &recordingSigner{feePayer: f.feePayer}
+			payload, requirements := f.payment(t)
+
+			response, err := NewExactSvmScheme(signer, c.config).Verify(context.Background(), payload, requirements)
+			require.NoError(t, err)
+			assert.True(t, response.IsValid)
+			assert.Equal(t, c.want, signer.simulations)
+		})
+	}
+}
+
+func TestMaxComputeUnitPrice(t *testing.T) {
+	f := newInstructionFixture()
+	withPrice := func(microLamports uint64) []solana.Instruction {
+		instructions := f.computeInstructions(t)
+		price, err := computebudget.NewSetComputeUnitPriceInstructionBuilder().SetMicroLamports(microLamports).ValidateAndBuild()
+		require.NoError(t, err)
+		instructions[1] = price
+		return append(instructions, f.transferInstruction(t, f.client))
+	}
+	verify := func(config *ExactSvmSchemeConfig, instructions []solana.Instruction) error {
+		tx := f.build(t, f.feePayer, instructions...)
+		return NewExactSvmScheme(nil, config).verifyInstructions(tx, f.requirements, []string{f.feePayer.String()})
+	}
+
+	assert.NoError(t, verify(nil, withPrice(svm.MaxComputeUnitPriceMicrolamports)))
+	assert.EqualError(t, verify(nil, withPrice(svm.MaxComputeUnitPriceMicrolamports+1)), ErrComputePriceInstructionTooHigh)
+	assert.EqualError(t, verify(&ExactSvmSchemeConfig{MaxComputeUnitPrice: 1000}, withPrice(1001)), ErrComputePriceInstructionTooHigh)
+	assert.NoError(t, verify(&ExactSvmSchemeConfig{MaxComputeUnitPrice: 10_000_000}, withPrice(svm.MaxComputeUnitPriceMicrolamports+1)))
+}
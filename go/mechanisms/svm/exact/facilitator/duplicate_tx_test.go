@@ -8,12 +8,9 @@ import (
 )
 
 func TestFacilitatorInstructionConstraints(t *testing.T) {
-	t.Run("allows 3-6 instructions", func(t *testing.T) {
-		minInstructions := 3
-		maxInstructions := 6
-
+	t.Run("allows 3-7 instructions", func(t *testing.T) {
 		assert.Equal(t, 3, minInstructions)
-		assert.Equal(t, 6, maxInstructions)
+		assert.Equal(t, 7, maxInstructions)
 	})
 
 	t.Run("optional instructions may be Lighthouse or Memo", func(t *testing.T) {
@@ -13,6 +13,10 @@ const (
 	ErrUnknownFourthInstruction       = "invalid_exact_solana_payload_unknown_fourth_instruction"
 	ErrUnknownFifthInstruction        = "invalid_exact_solana_payload_unknown_fifth_instruction"
 	ErrUnknownSixthInstruction        = "invalid_exact_solana_payload_unknown_sixth_instruction"
+	ErrUnknownProgram                 = "invalid_exact_solana_payload_unknown_program"
+	ErrCreateATAInstruction           = "invalid_exact_solana_payload_transaction_instructions_create_ata_instruction"
+	ErrFeePayerInInstructionAccounts  = "invalid_exact_solana_payload_transaction_fee_payer_in_instruction_accounts"
+	ErrAddressTableLookups            = "invalid_exact_solana_payload_transaction_address_table_lookups"
 	ErrComputeLimitInstruction        = "invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction"
 	ErrComputePriceInstruction        = "invalid_exact_solana_payload_transaction_instructions_compute_price_instruction"
 	ErrComputePriceInstructionTooHigh = "invalid_exact_solana_payload_transaction_instructions_compute_price_instruction_too_high"
@@ -0,0 +1,196 @@
+package facilitator
+
+import (
+	"errors"
+	"fmt"
+
+	solana "github.com/gagliardetto/solana-go"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/svm"
+)
+
+// Instruction layout accepted from clients, in order:
+//
+//  1. SetComputeUnitLimit
+//  2. SetComputeUnitPrice
+//  3. CreateAssociatedTokenAccount or its idempotent variant for the
+//     recipient's token account (optional)
+//  4. TransferChecked
+//  5. up to three Lighthouse (wallet protection) or Memo (uniqueness)
+//     instructions (optional)
+//
+// Programs outside this allowlist are rejected, and no instruction other than
+// the compute budget ones may reference an account of the facilitator's
+// signers, so a transaction can neither spend from nor fund accounts with the
+// fee payer.
+const (
+	minInstructions = 3
+	maxInstructions = 7
+)
+
+// verifyProgramIndexes checks that every instruction's program is a static
+// account key; programs cannot be loaded from address lookup tables
+func verifyProgramIndexes(tx *solana.Transaction) error {
+	for i, inst := range tx.Message.Instructions {
+		if int(inst.ProgramIDIndex) >= len(tx.Message.AccountKeys) {
+			return fmt.Errorf("instruction %d references program index %d outside the account keys", i, inst.ProgramIDIndex)
+		}
+	}
+	return nil
+}
+
+// verifyInstructions checks the instruction layout against the allowlist.
+// Program indexes must have been checked with verifyProgramIndexes.
+func (f *ExactSvmScheme) verifyInstructions(tx *solana.Transaction, requirements x402.PaymentRequirements, signerAddresses []string) error {
+	instructions := tx.Message.Instructions
+	if len(instructions) < minInstructions || len(instructions) > maxInstructions {
+		return errors.New(ErrTransactionInstructionsLength)
+	}
+
+	if err := f.verifyComputeLimitInstruction(tx, instructions[0]); err != nil {
+		return err
+	}
+	if err := f.verifyComputePriceInstruction(tx, instructions[1]); err != nil {
+		return err
+	}
+
+	transferIndex := 2
+	if tx.Message.AccountKeys[instructions[2].ProgramIDIndex].Equals(solana.SPLAssociatedTokenAccountProgramID) {
+		if err := verifyCreateATAInstruction(tx, instructions[2], requirements, signerAddresses); err != nil {
+			return err
+		}
+		transferIndex = 3
+	}
+	if transferIndex >= len(instructions) {
+		return errors.New(ErrNoTransferInstruction)
+	}
+	if err := f.verifyTransferInstruction(tx, instructions[transferIndex], requirements, signerAddresses); err != nil {
+		return err
+	}
+	if err := verifyNoSignerAccounts(tx, instructions[transferIndex], signerAddresses); err != nil {
+		return err
+	}
+
+	lighthouse := solana.MustPublicKeyFromBase58(svm.LighthouseProgramAddress)
+	memo := solana.MustPublicKeyFromBase58(svm.MemoProgramAddress)
+	optional := instructions[transferIndex+1:]
+	if len(optional) > 3 {
+		return errors.New(ErrTransactionInstructionsLength)
+	}
+	for i, inst := range optional {
+		progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
+		if !progID.Equals(lighthouse) && !progID.Equals(memo) {
+			return errors.New(unknownInstructionReason(transferIndex + 1 + i))
+		}
+		if err := verifyNoSignerAccounts(tx, inst, signerAddresses); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyFeePayerIsolation checks that the facilitator's signers can only pay
+// fees: the fee payer is the first account and no other account key is a
+// facilitator signer, so no instruction, nor any program it invokes, can use
+// their signatures or balances. Accounts loaded from address lookup tables
+// are not visible to these checks and are rejected.
+func verifyFeePayerIsolation(tx *solana.Transaction, feePayer solana.PublicKey, signerAddresses []string) error {
+	if len(tx.Message.AddressTableLookups) > 0 {
+		return errors.New(ErrAddressTableLookups)
+	}
+	if len(tx.Message.AccountKeys) == 0 || !tx.Message.AccountKeys[0].Equals(feePayer) {
+		return errors.New(ErrFeePayerMismatch)
+	}
+	for _, key := range tx.Message.AccountKeys[1:] {
+		for _, signerAddr := range signerAddresses {
+			if key.String() == signerAddr {
+				return errors.New(ErrFeePayerInInstructionAccounts)
+			}
+		}
+	}
+	return nil
+}
+
+// verifySourceNotSignerATA rejects transfers out of a token account owned by a
+// facilitator signer, which a delegate could otherwise spend
+func verifySourceNotSignerATA(source, mint solana.PublicKey, signerAddresses []string) error {
+	for _, signerAddr := range signerAddresses {
+		signer, err := solana.PublicKeyFromBase58(signerAddr)
+		if err != nil {
+			continue
+		}
+		for _, programID := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+			signerATA, _, err := solana.FindProgramAddress([][]byte{signer[:], programID[:], mint[:]}, solana.SPLAssociatedTokenAccountProgramID)
+			if err == nil && source.Equals(signerATA) {
+				return errors.New(ErrFeePayerTransferringFunds)
+			}
+		}
+	}
+	return nil
+}
+
+// unknownInstructionReason names the position of an unexpected instruction
+func unknownInstructionReason(index int) string {
+	switch index {
+	case 3:
+		return ErrUnknownFourthInstruction
+	case 4:
+		return ErrUnknownFifthInstruction
+	default:
+		return ErrUnknownSixthInstruction
+	}
+}
+
+// verifyCreateATAInstruction checks that an associated token account
+// instruction only creates the recipient's token account, funded by someone
+// other than the facilitator
+func verifyCreateATAInstruction(tx *solana.Transaction, inst solana.CompiledInstruction, requirements x402.PaymentRequirements, signerAddresses []string) error {
+	// Create has no data; CreateIdempotent is [1]
+	if len(inst.Data) > 1 || (len(inst.Data) == 1 && inst.Data[0] > 1) {
+		return errors.New(ErrCreateATAInstruction)
+	}
+
+	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
+	if err != nil || len(accounts) < 6 {
+		return errors.New(ErrCreateATAInstruction)
+	}
+	// [funder, associated token account, wallet, mint, system program, token program]
+	funder, ata, wallet, mint := accounts[0].PublicKey, accounts[1].PublicKey, accounts[2].PublicKey, accounts[3].PublicKey
+	systemProgram, tokenProgram := accounts[4].PublicKey, accounts[5].PublicKey
+
+	for _, signerAddr := range signerAddresses {
+		if funder.String() == signerAddr {
+			return errors.New(ErrFeePayerInInstructionAccounts)
+		}
+	}
+
+	if wallet.String() != requirements.PayTo || mint.String() != requirements.Asset {
+		return errors.New(ErrCreateATAInstruction)
+	}
+	if !systemProgram.Equals(solana.SystemProgramID) || (!tokenProgram.Equals(solana.TokenProgramID) && !tokenProgram.Equals(solana.Token2022ProgramID)) {
+		return errors.New(ErrCreateATAInstruction)
+	}
+	expectedATA, _, err := solana.FindAssociatedTokenAddress(wallet, mint)
+	if err != nil || !ata.Equals(expectedATA) {
+		return errors.New(ErrCreateATAInstruction)
+	}
+	return nil
+}
+
+// verifyNoSignerAccounts rejects instructions that reference the facilitator's signers
+func verifyNoSignerAccounts(tx *solana.Transaction, inst solana.CompiledInstruction, signerAddresses []string) error {
+	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
+	if err != nil {
+		return errors.New(ErrFeePayerInInstructionAccounts)
+	}
+	for _, account := range accounts {
+		for _, signerAddr := range signerAddresses {
+			if account.PublicKey.String() == signerAddr {
+				return errors.New(ErrFeePayerInInstructionAccounts)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package facilitator
+
+import (
+	"testing"
+
+	solana "github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/svm"
+)
+
+type instructionFixture struct {
+	feePayer     solana.PublicKey
+	client       solana.PublicKey
+	payTo        solana.PublicKey
+	mint         solana.PublicKey
+	requirements x402.PaymentRequirements
+}
+
+func newInstructionFixture() instructionFixture {
+	f := instructionFixture{
+		feePayer: solana.NewWallet().PublicKey(),
+		client:   solana.NewWallet().PublicKey(),
+		payTo:    solana.NewWallet().PublicKey(),
+		mint:     solana.NewWallet().PublicKey(),
+	}
+	f.requirements = x402.PaymentRequirements{
+		Scheme:  svm.SchemeExact,
+		Network: svm.SolanaDevnetCAIP2,
+		Asset:   f.mint.String(),
+		Amount:  "1000",
+		PayTo:   f.payTo.String(),
+	}
+	return f
+}
+
+func (f instructionFixture) computeInstructions(t *testing.T) []solana.Instruction {
+	t.Helper()
+	limit, err := computebudget.NewSetComputeUnitLimitInstructionBuilder().SetUnits(svm.DefaultComputeUnitLimit).ValidateAndBuild()
+	require.NoError(t, err)
+	price, err := computebudget.NewSetComputeUnitPriceInstructionBuilder().SetMicroLamports(svm.DefaultComputeUnitPriceMicrolamports).ValidateAndBuild()
+	require.NoError(t, err)
+	return []solana.Instruction{limit, price}
+}
+
+func (f instructionFixture) transferInstruction(t *testing.T, owner solana.PublicKey) solana.Instruction {
+	t.Helper()
+	source, _, err := solana.FindAssociatedTokenAddress(owner, f.mint)
+	require.NoError(t, err)
+	destination, _, err := solana.FindAssociatedTokenAddress(f.payTo, f.mint)
+	require.NoError(t, err)
+	transfer, err := token.NewTransferCheckedInstructionBuilder().
+		SetAmount(1000).
+		SetDecimals(6).
+		SetSourceAccount(source).
+		SetMintAccount(f.mint).
+		SetDestinationAccount(destination).
+		SetOwnerAccount(owner).
+		ValidateAndBuild()
+	require.NoError(t, err)
+	return transfer
+}
+
+func (f instructionFixture) createATAInstruction(t *testing.T, funder, wallet solana.PublicKey) solana.Instruction {
+	t.Helper()
+	create, err := associatedtokenaccount.NewCreateInstruction(funder, wallet, f.mint).ValidateAndBuild()
+	require.NoError(t, err)
+	return create
+}
+
+func memoInstruction() solana.Instruction {
+	return solana.NewInstruction(solana.MustPublicKeyFromBase58(svm.MemoProgramAddress), solana.AccountMetaSlice{}, []byte("nonce"))
+}
+
+func (f instructionFixture) build(t *testing.T, feePayer solana.PublicKey, instructions ...solana.Instruction) *solana.Transaction {
+	t.Helper()
+	builder := solana.NewTransactionBuilder().SetFeePayer(feePayer).SetRecentBlockHash(solana.Hash{})
+	for _, inst := range instructions {
+		builder.AddInstruction(inst)
+	}
+	tx, err := builder.Build()
+	require.NoError(t, err)
+	return tx
+}
+
+func (f instructionFixture) verify(t *testing.T, instructions ...solana.Instruction) error {
+	t.Helper()
+	tx := f.build(t, f.feePayer, instructions...)
+	scheme := NewExactSvmScheme(nil, nil)
+	require.NoError(t, verifyProgramIndexes(tx))
+	return scheme.verifyInstructions(tx, f.requirements, []string{f.feePayer.String()})
+}
+
+func TestVerifyInstructions(t *testing.T) {
+	t.Run("accepts compute budget, transfer and memo", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t), f.transferInstruction(t, f.client), memoInstruction())
+		assert.NoError(t, f.verify(t, instructions...))
+	})
+
+	t.Run("accepts a client-funded recipient ATA creation", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t),
+			f.createATAInstruction(t, f.client, f.payTo),
+			f.transferInstruction(t, f.client),
+			memoInstruction(),
+		)
+		assert.NoError(t, f.verify(t, instructions...))
+	})
+
+	t.Run("rejects ATA creation funded by the fee payer", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t),
+			f.createATAInstruction(t, f.feePayer, f.payTo),
+			f.transferInstruction(t, f.client),
+		)
+		assert.EqualError(t, f.verify(t, instructions...), ErrFeePayerInInstructionAccounts)
+	})
+
+	t.Run("rejects ATA creation for another wallet", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t),
+			f.createATAInstruction(t, f.client, solana.NewWallet().PublicKey()),
+			f.transferInstruction(t, f.client),
+		)
+		assert.EqualError(t, f.verify(t, instructions...), ErrCreateATAInstruction)
+	})
+
+	t.Run("rejects ATA creation after the transfer", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t),
+			f.transferInstruction(t, f.client),
+			f.createATAInstruction(t, f.client, f.payTo),
+		)
+		assert.EqualError(t, f.verify(t, instructions...), ErrUnknownFourthInstruction)
+	})
+
+	t.Run("rejects a system transfer from the fee payer", func(t *testing.T) {
+		f := newInstructionFixture()
+		drain := system.NewTransferInstruction(1_000_000, f.feePayer, solana.NewWallet().PublicKey()).Build()
+		instructions := append(f.computeInstructions(t), f.transferInstruction(t, f.client), memoInstruction(), drain)
+		assert.EqualError(t, f.verify(t, instructions...), ErrUnknownFifthInstruction)
+	})
+
+	t.Run("rejects a memo that references the fee payer", func(t *testing.T) {
+		f := newInstructionFixture()
+		memo := solana.NewInstruction(
+			solana.MustPublicKeyFromBase58(svm.MemoProgramAddress),
+			solana.AccountMetaSlice{solana.Meta(f.feePayer).SIGNER()},
+			[]byte("nonce"),
+		)
+		instructions := append(f.computeInstructions(t), f.transferInstruction(t, f.client), memo)
+		assert.EqualError(t, f.verify(t, instructions...), ErrFeePayerInInstructionAccounts)
+	})
+
+	t.Run("rejects a transfer authorized by the fee payer", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t), f.transferInstruction(t, f.feePayer))
+		assert.EqualError(t, f.verify(t, instructions...), ErrFeePayerTransferringFunds)
+	})
+
+	t.Run("rejects too many instructions", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t),
+			f.createATAInstruction(t, f.client, f.payTo),
+			f.transferInstruction(t, f.client),
+			memoInstruction(), memoInstruction(), memoInstruction(), memoInstruction(),
+		)
+		assert.EqualError(t, f.verify(t, instructions...), ErrTransactionInstructionsLength)
+	})
+
+	t.Run("rejects a missing transfer", func(t *testing.T) {
+		f := newInstructionFixture()
+		instructions := append(f.computeInstructions(t), memoInstruction())
+		assert.EqualError(t, f.verify(t, instructions...), ErrNoTransferInstruction)
+	})
+}
+
+func TestVerifyFeePayerIsolation(t *testing.T) {
+	t.Run("accepts a transaction that only uses the fee payer for fees", func(t *testing.T) {
+		f := newInstructionFixture()
+		tx := f.build(t, f.feePayer, append(f.computeInstructions(t), f.transferInstruction(t, f.client), memoInstruction())...)
+		assert.NoError(t, verifyFeePayerIsolation(tx, f.feePayer, []string{f.feePayer.String()}))
+	})
+
+	t.Run("rejects a different fee payer", func(t *testing.T) {
+		f := newInstructionFixture()
+		tx := f.build(t, f.client, append(f.computeInstructions(t), f.transferInstruction(t, f.client))...)
+		assert.EqualError(t, verifyFeePayerIsolation(tx, f.feePayer, []string{f.feePayer.String()}), ErrFeePayerMismatch)
+	})
+
+	t.Run("rejects another facilitator signer in the account keys", func(t *testing.T) {
+		f := newInstructionFixture()
+		other := solana.NewWallet().PublicKey()
+		drain := system.NewTransferInstruction(1_000_000, other, f.payTo).Build()
+		tx := f.build(t, f.feePayer, append(f.computeInstructions(t), f.transferInstruction(t, f.client), drain)...)
+		err := verifyFeePayerIsolation(tx, f.feePayer, []string{f.feePayer.String(), other.String()})
+		assert.EqualError(t, err, ErrFeePayerInInstructionAccounts)
+	})
+
+	t.Run("rejects address lookup tables", func(t *testing.T) {
+		f := newInstructionFixture()
+		tx := f.build(t, f.feePayer, append(f.computeInstructions(t), f.transferInstruction(t, f.client))...)
+		tx.Message.SetVersion(solana.MessageVersionV0)
+		tx.Message.SetAddressTableLookups([]solana.MessageAddressTableLookup{{
+			AccountKey:      solana.NewWallet().PublicKey(),
+			WritableIndexes: []uint8{0},
+		}})
+		assert.EqualError(t, verifyFeePayerIsolation(tx, f.feePayer, []string{f.feePayer.String()}), ErrAddressTableLookups)
+	})
+
+	t.Run("rejects a transfer out of the fee payer's token account", func(t *testing.T) {
+		f := newInstructionFixture()
+		source, _, err := solana.FindAssociatedTokenAddress(f.feePayer, f.mint)
+		require.NoError(t, err)
+		destination, _, err := solana.FindAssociatedTokenAddress(f.payTo, f.mint)
+		require.NoError(t, err)
+		delegated, err := token.NewTransferCheckedInstructionBuilder().
+			SetAmount(1000).
+			SetDecimals(6).
+			SetSourceAccount(source).
+			SetMintAccount(f.mint).
+			SetDestinationAccount(destination).
+			SetOwnerAccount(f.client).
+			ValidateAndBuild()
+		require.NoError(t, err)
+
+		err = f.verify(t, append(f.computeInstructions(t), delegated)...)
+		assert.EqualError(t, err, ErrFeePayerTransferringFunds)
+	})
+}
+
+func TestProjectedFee(t *testing.T) {
+	f := newInstructionFixture()
+	tx := f.build(t, f.feePayer, append(f.computeInstructions(t), f.transferInstruction(t, f.client))...)
+
+	units, microLamports, err := computeBudget(tx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(svm.DefaultComputeUnitLimit), units)
+	assert.Equal(t, uint64(svm.DefaultComputeUnitPriceMicrolamports), microLamports)
+
+	// Two signatures (fee payer and client) plus a rounded-up priority fee
+	assert.Equal(t, "10001", projectedFee(tx, 1000, 1).String())
+	assert.Equal(t, "10000", projectedFee(tx, 0, 0).String())
+	assert.Equal(t, "15000", projectedFee(tx, 5_000_000, 1000).String())
+}
@@ -0,0 +1,83 @@
+package facilitator
+
+import (
+	"context"
+	"time"
+
+	solana "github.com/gagliardetto/solana-go"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/svm"
+)
+
+// settlementExpiry is how long after submission an unconfirmed settlement is
+// considered failed. Transactions expire with their blockhash after about 150
+// slots (roughly a minute), so they can no longer land after this.
+const settlementExpiry = 2 * time.Minute
+
+// SetJournal sets ExactSvmSchemeConfig.Journal
+func (f *ExactSvmScheme) SetJournal(journal x402.SettlementJournal) *ExactSvmScheme {
+	f.config.Journal = journal
+	return f
+}
+
+// journalSubmitted records a sent settlement transaction. Journal errors do
+// not fail settlement, since the transaction is already sent.
+func (f *ExactSvmScheme) journalSubmitted(ctx context.Context, signature solana.Signature, network x402.Network, payer, asset, amount string, attribution map[string]string) {
+	if f.config.Journal == nil {
+		return
+	}
+	_ = f.config.Journal.Submitted(ctx, x402.SubmittedSettlement{
+		Transaction: signature.String(),
+		Scheme:      svm.SchemeExact,
+		Network:     network,
+		Payer:       payer,
+		Asset:       asset,
+		Amount:      amount,
+		SubmittedAt: time.Now(),
+		Attribution: attribution,
+	})
+}
+
+// journalResolved removes a confirmed settlement transaction from the journal
+func (f *ExactSvmScheme) journalResolved(ctx context.Context, signature solana.Signature, network x402.Network) {
+	if f.config.Journal == nil {
+		return
+	}
+	_ = f.config.Journal.Resolved(ctx, network, signature.String())
+}
+
+// RecoverSettlement waits for a journaled or pending settlement transaction
+// to confirm, implementing x402.SettlementRecoverer. Transactions still
+// unconfirmed after their blockhash expired are reported as failed.
+func (f *ExactSvmScheme) RecoverSettlement(ctx context.Context, settlement x402.SubmittedSettlement) (*x402.SettleResponse, error) {
+	signature, err := solana.SignatureFromBase58(settlement.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = svm.ContextWithCommitment(ctx, f.config.Commitment)
+	if err := f.signer.ConfirmTransaction(ctx, signature, string(settlement.Network)); err != nil {
+		if time.Since(settlement.SubmittedAt) < settlementExpiry {
+			return nil, err
+		}
+		f.journalResolved(ctx, signature, settlement.Network)
+		return &x402.SettleResponse{
+			Success:      false,
+			ErrorReason:  ErrTransactionConfirmationFailed,
+			ErrorMessage: err.Error(),
+			Transaction:  settlement.Transaction,
+			Network:      settlement.Network,
+			Payer:        settlement.Payer,
+		}, nil
+	}
+
+	f.journalResolved(ctx, signature, settlement.Network)
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: settlement.Transaction,
+		Network:     settlement.Network,
+		Payer:       settlement.Payer,
+		Cost:        svm.SettlementCost(ctx, f.signer, signature, string(settlement.Network)),
+	}, nil
+}
@@ -6,28 +6,86 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
+	"time"
 
 	solana "github.com/gagliardetto/solana-go"
 	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
 	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
 
 	x402 "github.com/coinbase/x402/go"
 	"github.com/coinbase/x402/go/mechanisms/svm"
 	"github.com/coinbase/x402/go/types"
 )
 
+// ExactSvmSchemeConfig holds configuration for the ExactSvmScheme facilitator
+type ExactSvmSchemeConfig struct {
+	// Commitment is the level at which the signer simulates, sends, and
+	// confirms transactions, passed to it with svm.ContextWithCommitment
+	// (empty = svm.DefaultCommitment)
+	Commitment rpc.CommitmentType
+
+	// SkipSimulation verifies payments without simulating the signed
+	// transaction. Verification is faster and needs no RPC call, but a
+	// transfer that would fail (e.g. for insufficient balance) is only
+	// detected when settlement fails to confirm.
+	SkipSimulation bool
+
+	// ConfirmTimeout bounds the wait for a settlement transaction to confirm
+	// (0 = wait as long as the context allows). Settlement then returns a
+	// pending response with the transaction signature, and x402Facilitator
+	// keeps waiting for the outcome in the background.
+	ConfirmTimeout time.Duration
+
+	// MaxComputeUnitPrice is the highest compute unit price, in
+	// microlamports, a payment transaction may set since the fee payer pays
+	// it (0 = svm.MaxComputeUnitPriceMicrolamports)
+	MaxComputeUnitPrice uint64
+
+	// Journal records settlement transactions as soon as they are sent, so
+	// x402Facilitator.RecoverSettlements can resume waiting for them after a
+	// crash (nil = not journaled)
+	Journal x402.SettlementJournal
+}
+
 // ExactSvmScheme implements the SchemeNetworkFacilitator interface for SVM (Solana) exact payments (V2)
 type ExactSvmScheme struct {
 	signer svm.FacilitatorSvmSigner
+	config ExactSvmSchemeConfig
 }
 
 // NewExactSvmScheme creates a new ExactSvmScheme
-func NewExactSvmScheme(signer svm.FacilitatorSvmSigner) *ExactSvmScheme {
+// Args:
+//
+//	signer: The SVM signer for facilitator operations
+//	config: Optional configuration (nil uses defaults)
+//
+// Returns:
+//
+//	Configured ExactSvmScheme instance
+func NewExactSvmScheme(signer svm.FacilitatorSvmSigner, config *ExactSvmSchemeConfig) *ExactSvmScheme {
+	cfg := ExactSvmSchemeConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Commitment == "" {
+		cfg.Commitment = svm.DefaultCommitment
+	}
+	if cfg.MaxComputeUnitPrice == 0 {
+		cfg.MaxComputeUnitPrice = svm.MaxComputeUnitPriceMicrolamports
+	}
 	return &ExactSvmScheme{
 		signer: signer,
+		config: cfg,
 	}
 }
 
+// SetConfirmationTimeout sets ExactSvmSchemeConfig.ConfirmTimeout
+func (f *ExactSvmScheme) SetConfirmationTimeout(timeout time.Duration) *ExactSvmScheme {
+	f.config.ConfirmTimeout = timeout
+	return f
+}
+
 // Scheme returns the scheme identifier
 func (f *ExactSvmScheme) Scheme() string {
 	return svm.SchemeExact
@@ -81,14 +139,14 @@ func (f *ExactSvmScheme) Verify(
 		return nil, x402.NewVerifyError(ErrNetworkMismatch, "", fmt.Sprintf("network mismatch: %s != %s", payload.Accepted.Network, requirements.Network))
 	}
 
-	if requirements.Extra == nil || requirements.Extra["feePayer"] == nil {
-		return nil, x402.NewVerifyError(ErrMissingFeePayer, "", "missing feePayer")
-	}
-
-	feePayerStr, ok := requirements.Extra["feePayer"].(string)
-	if !ok {
+	var extra svm.ExtraFeePayer
+	if err := types.UnmarshalExtra(requirements.Extra, &extra); err != nil {
 		return nil, x402.NewVerifyError(ErrMissingFeePayer, "", fmt.Sprintf("invalid feePayer: %v", requirements.Extra["feePayer"]))
 	}
+	if extra.FeePayer == "" {
+		return nil, x402.NewVerifyError(ErrMissingFeePayer, "", "missing feePayer")
+	}
+	feePayerStr := extra.FeePayer
 
 	// Verify that the requested feePayer is managed by this facilitator
 	signerAddresses := f.signer.GetAddresses(ctx, string(network))
@@ -120,31 +178,27 @@ func (f *ExactSvmScheme) Verify(
 		return nil, x402.NewVerifyError(ErrTransactionCouldNotBeDecoded, "", err.Error())
 	}
 
-	// Allow 3-6 instructions:
-	// - 3 instructions: ComputeLimit + ComputePrice + TransferChecked
-	// - 4 instructions: ComputeLimit + ComputePrice + TransferChecked + Lighthouse or Memo
-	// - 5 instructions: ComputeLimit + ComputePrice + TransferChecked + Lighthouse + Lighthouse or Memo
-	// - 6 instructions: ComputeLimit + ComputePrice + TransferChecked + Lighthouse + Lighthouse + Memo
-	// See: https://github.com/coinbase/x402/issues/828
-	numInstructions := len(tx.Message.Instructions)
-	if numInstructions < 3 || numInstructions > 6 {
-		return nil, x402.NewVerifyError(ErrTransactionInstructionsLength, "", fmt.Sprintf("transaction instructions length mismatch: %d < 3 or %d > 6", numInstructions, numInstructions))
+	// Step 3: Verify Instructions
+	// Allowed, in order: ComputeLimit + ComputePrice + optional CreateATA for the
+	// recipient + TransferChecked + up to three Lighthouse or Memo instructions.
+	// See instructions.go and https://github.com/coinbase/x402/issues/828
+	if err := verifyProgramIndexes(tx); err != nil {
+		return nil, x402.NewVerifyError(ErrUnknownProgram, "", err.Error())
 	}
 
-	// Step 3: Verify Compute Budget Instructions
-	if err := f.verifyComputeLimitInstruction(tx, tx.Message.Instructions[0]); err != nil {
-		return nil, x402.NewVerifyError(err.Error(), "", err.Error())
+	// feePayer already validated in Step 1
+	feePayer, err := solana.PublicKeyFromBase58(feePayerStr)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidFeePayer, "", err.Error())
 	}
 
-	if err := f.verifyComputePriceInstruction(tx, tx.Message.Instructions[1]); err != nil {
+	// Defense in depth: the fee payer may only pay fees
+	if err := verifyFeePayerIsolation(tx, feePayer, signerAddressStrs); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), "", err.Error())
 	}
 
-	// Extract payer from transaction
-	payer, err := svm.GetTokenPayerFromTransaction(tx)
-	if err != nil {
-		return nil, x402.NewVerifyError(ErrNoTransferInstruction, payer, err.Error())
-	}
+	// Extract payer from transaction; a missing transfer is reported by verifyInstructions
+	payer, _ := svm.GetTokenPayerFromTransaction(tx)
 
 	// V2: payload.Accepted.Network is already validated by scheme lookup
 	// Network matching is implicit - facilitator was selected based on requirements.Network
@@ -159,55 +213,23 @@ func (f *ExactSvmScheme) Verify(
 		Extra:   requirements.Extra,
 	}
 
-	// Step 4: Verify Transfer Instruction
-	if err := f.verifyTransferInstruction(tx, tx.Message.Instructions[2], reqStruct, signerAddressStrs); err != nil {
+	if err := f.verifyInstructions(tx, reqStruct, signerAddressStrs); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), payer, err.Error())
 	}
 
-	// Step 5: Verify optional instructions (if present)
-	// Allowed optional programs: Lighthouse (wallet protection) and Memo (uniqueness)
-	if numInstructions >= 4 {
-		lighthousePubkey := solana.MustPublicKeyFromBase58(svm.LighthouseProgramAddress)
-		memoPubkey := solana.MustPublicKeyFromBase58(svm.MemoProgramAddress)
-		optionalInstructions := tx.Message.Instructions[3:]
-		invalidReasons := []string{
-			ErrUnknownFourthInstruction,
-			ErrUnknownFifthInstruction,
-			ErrUnknownSixthInstruction,
-		}
-
-		for i, instruction := range optionalInstructions {
-			progID := tx.Message.AccountKeys[instruction.ProgramIDIndex]
-			if progID.Equals(lighthousePubkey) || progID.Equals(memoPubkey) {
-				continue
-			}
-
-			reason := ErrUnknownSixthInstruction
-			if i < len(invalidReasons) {
-				reason = invalidReasons[i]
-			}
-
-			return nil, x402.NewVerifyError(reason, payer, fmt.Sprintf("unknown optional instruction: %s", progID.String()))
-		}
-	}
-
-	// Step 6: Sign and Simulate Transaction
+	// Step 4: Sign and Simulate Transaction
 	// CRITICAL: Simulation proves transaction will succeed (catches insufficient balance, invalid accounts, etc)
 
-	// feePayer already validated in Step 1
-	feePayer, err := solana.PublicKeyFromBase58(feePayerStr)
-	if err != nil {
-		return nil, x402.NewVerifyError(ErrInvalidFeePayer, payer, err.Error())
-	}
-
 	// Sign transaction with the feePayer's signer
 	if err := f.signer.SignTransaction(ctx, tx, feePayer, string(requirements.Network)); err != nil {
 		return nil, x402.NewVerifyError(ErrTransactionSigningFailed, payer, err.Error())
 	}
 
 	// Simulate transaction to verify it would succeed
-	if err := f.signer.SimulateTransaction(ctx, tx, string(requirements.Network)); err != nil {
-		return nil, x402.NewVerifyError(ErrTransactionSimulationFailed, payer, err.Error())
+	if !f.config.SkipSimulation {
+		if err := f.signer.SimulateTransaction(svm.ContextWithCommitment(ctx, f.config.Commitment), tx, string(requirements.Network)); err != nil {
+			return nil, x402.NewVerifyError(ErrTransactionSimulationFailed, payer, err.Error())
+		}
 	}
 
 	return &x402.VerifyResponse{
@@ -249,10 +271,11 @@ func (f *ExactSvmScheme) Settle(
 	}
 
 	// Extract and validate feePayer from requirements matches transaction
-	feePayerStr, ok := requirements.Extra["feePayer"].(string)
-	if !ok {
+	var extra svm.ExtraFeePayer
+	if err := types.UnmarshalExtra(requirements.Extra, &extra); err != nil || extra.FeePayer == "" {
 		return nil, x402.NewSettleError(ErrMissingFeePayer, verifyResp.Payer, network, "", "")
 	}
+	feePayerStr := extra.FeePayer
 
 	expectedFeePayer, err := solana.PublicKeyFromBase58(feePayerStr)
 	if err != nil {
@@ -272,21 +295,40 @@ func (f *ExactSvmScheme) Settle(
 	}
 
 	// Send transaction to network
+	ctx = svm.ContextWithCommitment(ctx, f.config.Commitment)
 	signature, err := f.signer.SendTransaction(ctx, tx, string(requirements.Network))
 	if err != nil {
 		return nil, x402.NewSettleError(ErrTransactionFailed, verifyResp.Payer, network, "", err.Error())
 	}
 
-	// Wait for confirmation
-	if err := f.signer.ConfirmTransaction(ctx, signature, string(requirements.Network)); err != nil {
+	f.journalSubmitted(ctx, signature, network, verifyResp.Payer, requirements.Asset, requirements.Amount, types.Attribution(requirements.Extra))
+
+	// Wait for confirmation, at most the confirmation timeout
+	confirmCtx, cancel := ctx, context.CancelFunc(func() {})
+	if f.config.ConfirmTimeout > 0 {
+		confirmCtx, cancel = context.WithTimeout(ctx, f.config.ConfirmTimeout)
+	}
+	defer cancel()
+	if err := f.signer.ConfirmTransaction(confirmCtx, signature, string(requirements.Network)); err != nil {
+		if ctx.Err() == nil && errors.Is(confirmCtx.Err(), context.DeadlineExceeded) {
+			return &x402.SettleResponse{
+				Success:     true,
+				Pending:     true,
+				Transaction: signature.String(),
+				Network:     network,
+				Payer:       verifyResp.Payer,
+			}, nil
+		}
 		return nil, x402.NewSettleError(ErrTransactionConfirmationFailed, verifyResp.Payer, network, signature.String(), err.Error())
 	}
+	f.journalResolved(ctx, signature, network)
 
 	return &x402.SettleResponse{
 		Success:     true,
 		Transaction: signature.String(),
 		Network:     network,
 		Payer:       verifyResp.Payer,
+		Cost:        svm.SettlementCost(ctx, f.signer, signature, string(requirements.Network)),
 	}, nil
 }
 
@@ -343,8 +385,8 @@ func (f *ExactSvmScheme) verifyComputePriceInstruction(tx *solana.Transaction, i
 
 	// Check if it's SetComputeUnitPrice and validate the price
 	if priceInst, ok := decoded.Impl.(*computebudget.SetComputeUnitPrice); ok {
-		// Check if price exceeds maximum (5 lamports per compute unit = 5,000,000 microlamports)
-		if priceInst.MicroLamports > uint64(svm.MaxComputeUnitPriceMicrolamports) {
+		// Check if price exceeds the configured maximum
+		if priceInst.MicroLamports > f.config.MaxComputeUnitPrice {
 			return errors.New(ErrComputePriceInstructionTooHigh)
 		}
 	} else {
@@ -418,6 +460,10 @@ func (f *ExactSvmScheme) verifyTransferInstruction(
 		return errors.New(ErrRecipientMismatch)
 	}
 
+	if err := verifySourceNotSignerATA(accounts[0].PublicKey, mintPubkey, signerAddresses); err != nil {
+		return err
+	}
+
 	destATA := transferChecked.GetDestinationAccount().PublicKey
 	if destATA.String() != expectedDestATA.String() {
 		return errors.New(ErrRecipientMismatch)
@@ -0,0 +1,92 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	solana "github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/svm"
+	"github.com/coinbase/x402/go/types"
+)
+
+// Simulate verifies a payment, which already signs and simulates the
+// transaction, and projects the fee the fee payer would pay: the base fee
+// per signature plus the priority fee of the requested compute unit limit
+// at the requested price. GasUsed is the compute unit limit.
+func (f *ExactSvmScheme) Simulate(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.SimulateResponse, error) {
+	verified, err := f.Verify(ctx, payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify decoded and checked the same transaction
+	solanaPayload, err := svm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrInvalidPayloadTransaction, verified.Payer, err.Error())
+	}
+	tx, err := svm.DecodeTransaction(solanaPayload.Transaction)
+	if err != nil {
+		return nil, x402.NewVerifyError(ErrTransactionCouldNotBeDecoded, verified.Payer, err.Error())
+	}
+
+	units, microLamports, err := computeBudget(tx)
+	if err != nil {
+		return nil, x402.NewVerifyError(err.Error(), verified.Payer, err.Error())
+	}
+
+	return &x402.SimulateResponse{
+		IsValid:  true,
+		Payer:    verified.Payer,
+		Network:  x402.Network(requirements.Network),
+		GasUsed:  uint64(units),
+		GasPrice: new(big.Rat).SetFrac64(int64(microLamports), 1_000_000).FloatString(6),
+		Fee:      projectedFee(tx, units, microLamports).String(),
+	}, nil
+}
+
+// computeBudget returns the compute unit limit and price (microlamports per
+// unit) of a verified transaction
+func computeBudget(tx *solana.Transaction) (uint32, uint64, error) {
+	var units uint32
+	var microLamports uint64
+	for i, reason := range []string{ErrComputeLimitInstruction, ErrComputePriceInstruction} {
+		inst := tx.Message.Instructions[i]
+		accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
+		if err != nil {
+			return 0, 0, errors.New(reason)
+		}
+		decoded, err := computebudget.DecodeInstruction(accounts, inst.Data)
+		if err != nil {
+			return 0, 0, errors.New(reason)
+		}
+		switch budget := decoded.Impl.(type) {
+		case *computebudget.SetComputeUnitLimit:
+			units = budget.Units
+		case *computebudget.SetComputeUnitPrice:
+			microLamports = budget.MicroLamports
+		default:
+			return 0, 0, errors.New(reason)
+		}
+	}
+	return units, microLamports, nil
+}
+
+// projectedFee is the base fee per signature plus the priority fee, in lamports
+func projectedFee(tx *solana.Transaction, units uint32, microLamports uint64) *big.Int {
+	signatures := int64(tx.Message.Header.NumRequiredSignatures)
+	fee := big.NewInt(signatures * svm.LamportsPerSignature)
+
+	// Priority fee = ceil(units * microLamports / 1,000,000)
+	priority := new(big.Int).Mul(big.NewInt(int64(units)), new(big.Int).SetUint64(microLamports))
+	priority.Add(priority, big.NewInt(999_999))
+	priority.Div(priority, big.NewInt(1_000_000))
+	return fee.Add(fee, priority)
+}
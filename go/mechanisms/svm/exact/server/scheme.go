@@ -234,10 +234,12 @@ func (s *ExactSvmScheme) EnhancePaymentRequirements(
 
 	// Add feePayer from supportedKind.extra to payment requirements
 	// The facilitator provides its address as the fee payer for transaction fees
-	if supportedKind.Extra != nil {
-		if feePayer, ok := supportedKind.Extra["feePayer"]; ok {
-			requirements.Extra["feePayer"] = feePayer
+	if feePayer, ok := types.ExtraValue[string](supportedKind.Extra, "feePayer"); ok {
+		extra, err := types.MergeExtra(requirements.Extra, svm.ExtraFeePayer{FeePayer: feePayer}, true)
+		if err != nil {
+			return requirements, err
 		}
+		requirements.Extra = extra
 	}
 
 	// Copy extensions from supportedKind if provided
@@ -292,6 +292,7 @@ func (f *ExactSvmSchemeV1) Settle(
 		Transaction: signature.String(),
 		Network:     network,
 		Payer:       verifyResp.Payer,
+		Cost:        svm.SettlementCost(ctx, f.signer, signature, string(requirements.Network)),
 	}, nil
 }
 
@@ -0,0 +1,29 @@
+package svm
+
+import (
+	"strconv"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func init() {
+	x402.RegisterPayloadInspector(InspectPayload)
+}
+
+// InspectPayload fills in a payment inspection from the TransferChecked
+// instruction of an SVM payload's transaction. It is registered with
+// x402.InspectPayment when this package is imported.
+func InspectPayload(payload map[string]interface{}, inspection *x402.PaymentInspection) bool {
+	transaction, ok := payload["transaction"].(string)
+	if !ok {
+		return false
+	}
+	transfer, err := GetTokenTransferFromPayload(transaction)
+	if err != nil {
+		return false
+	}
+	inspection.Payer = transfer.Payer
+	inspection.Amount = strconv.FormatUint(transfer.Amount, 10)
+	inspection.Asset = transfer.Mint
+	return true
+}
@@ -0,0 +1,103 @@
+package svm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	solana "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var (
+	// NameServiceProgramID is the SPL Name Service program that owns SNS name accounts
+	NameServiceProgramID = solana.MustPublicKeyFromBase58("namesLPneVptA9Z5rqUDD9tMTWEJwofgaYwp8cawRkX")
+
+	// SolTLDAuthority is the parent name account of every .sol domain
+	SolTLDAuthority = solana.MustPublicKeyFromBase58("58PwtjSDuFHuUkYjH9BYnnQKHfwo9reZhC2zMJv9JPkx")
+)
+
+// snsHashPrefix is prepended to names before hashing them into account seeds
+const snsHashPrefix = "SPL Name Service"
+
+// snsHeaderSize is the name account header: parent, owner, and class keys
+const snsHeaderSize = 96
+
+// AccountDataReader fetches the raw data of a Solana account
+type AccountDataReader interface {
+	GetAccountData(ctx context.Context, account solana.PublicKey) ([]byte, error)
+}
+
+// RPCAccountDataReader reads account data over Solana JSON-RPC
+type RPCAccountDataReader struct {
+	client *rpc.Client
+}
+
+// NewRPCAccountDataReader creates an account reader using an RPC client
+func NewRPCAccountDataReader(client *rpc.Client) *RPCAccountDataReader {
+	return &RPCAccountDataReader{client: client}
+}
+
+// GetAccountData implements AccountDataReader
+func (r *RPCAccountDataReader) GetAccountData(ctx context.Context, account solana.PublicKey) ([]byte, error) {
+	info, err := r.client.GetAccountInfo(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return info.Value.Data.GetBinary(), nil
+}
+
+// SNSResolver resolves Solana Name Service .sol domains (e.g. "merchant.sol")
+// to the domain owner's address. It satisfies x402http.NameResolver.
+type SNSResolver struct {
+	reader AccountDataReader
+}
+
+// NewSNSResolver creates a resolver reading name accounts through reader
+func NewSNSResolver(reader AccountDataReader) *SNSResolver {
+	return &SNSResolver{reader: reader}
+}
+
+// ResolveName returns the owner of a .sol domain
+func (r *SNSResolver) ResolveName(ctx context.Context, network x402.Network, name string) (string, error) {
+	account, err := SNSDomainAccount(name)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := r.reader.GetAccountData(ctx, account)
+	if err != nil {
+		return "", fmt.Errorf("failed to read name account for %s: %w", name, err)
+	}
+	if len(data) < snsHeaderSize {
+		return "", fmt.Errorf("invalid name account for %s", name)
+	}
+
+	owner := solana.PublicKeyFromBytes(data[32:64])
+	if owner.IsZero() {
+		return "", fmt.Errorf("%s has no owner", name)
+	}
+	return owner.String(), nil
+}
+
+// SNSDomainAccount derives the name account of a .sol domain.
+// Only second-level domains (e.g. "merchant.sol") are supported.
+func SNSDomainAccount(name string) (solana.PublicKey, error) {
+	label, ok := strings.CutSuffix(strings.ToLower(name), ".sol")
+	if !ok || label == "" || strings.Contains(label, ".") {
+		return solana.PublicKey{}, fmt.Errorf("unsupported SNS name: %s", name)
+	}
+
+	hashed := sha256.Sum256([]byte(snsHashPrefix + label))
+	var class solana.PublicKey
+	account, _, err := solana.FindProgramAddress(
+		[][]byte{hashed[:], class[:], SolTLDAuthority[:]},
+		NameServiceProgramID,
+	)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("failed to derive name account for %s: %w", name, err)
+	}
+	return account, nil
+}
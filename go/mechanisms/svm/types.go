@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	solana "github.com/gagliardetto/solana-go"
+
+	x402 "github.com/coinbase/x402/go"
 )
 
 // ExactSvmPayload represents a SVM (Solana) payment payload
@@ -19,6 +22,12 @@ type ExactSvmPayloadV1 = ExactSvmPayload
 // ExactSvmPayloadV2 - alias for v2 (currently identical, reserved for future)
 type ExactSvmPayloadV2 = ExactSvmPayload
 
+// ExtraFeePayer is the facilitator address that pays transaction fees, carried
+// in requirements.Extra and in the facilitator's supported kinds
+type ExtraFeePayer struct {
+	FeePayer string `json:"feePayer,omitempty"`
+}
+
 // ClientSvmSigner defines client-side operations
 type ClientSvmSigner interface {
 	// Address returns the signer's Solana address (base58)
@@ -54,6 +63,29 @@ type FacilitatorSvmSigner interface {
 	ConfirmTransaction(ctx context.Context, signature solana.Signature, network string) error
 }
 
+// TransactionFeeReader is optionally implemented by facilitator signers that
+// can read a confirmed transaction's metadata, so settlements report what they
+// cost the fee payer
+type TransactionFeeReader interface {
+	// GetTransactionFee returns the fee in lamports and the compute units
+	// consumed by a confirmed transaction
+	GetTransactionFee(ctx context.Context, signature solana.Signature, network string) (fee uint64, computeUnits uint64, err error)
+}
+
+// SettlementCost reads the cost of a confirmed settlement transaction when the
+// signer implements TransactionFeeReader, returning nil otherwise or on failure
+func SettlementCost(ctx context.Context, signer FacilitatorSvmSigner, signature solana.Signature, network string) *x402.SettlementCost {
+	reader, ok := signer.(TransactionFeeReader)
+	if !ok {
+		return nil
+	}
+	fee, computeUnits, err := reader.GetTransactionFee(ctx, signature, network)
+	if err != nil {
+		return nil
+	}
+	return &x402.SettlementCost{GasUsed: computeUnits, Fee: strconv.FormatUint(fee, 10)}
+}
+
 // AssetInfo contains information about a SPL token
 type AssetInfo struct {
 	Address  string // Mint address
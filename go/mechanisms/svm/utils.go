@@ -11,6 +11,8 @@ import (
 	bin "github.com/gagliardetto/binary"
 	solana "github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/token"
+
+	"github.com/coinbase/x402/go/caip"
 )
 
 var (
@@ -22,8 +24,12 @@ var (
 func NormalizeNetwork(network string) (string, error) {
 	// If it's already CAIP-2 format (contains ":"), validate it's supported
 	if strings.Contains(network, ":") {
-		if _, ok := NetworkConfigs[network]; ok {
-			return network, nil
+		chain, err := caip.ParseChainID(network)
+		if err != nil || !chain.IsSolana() {
+			return "", fmt.Errorf("unsupported Solana network: %s", network)
+		}
+		if _, ok := NetworkConfigs[chain.String()]; ok {
+			return chain.String(), nil
 		}
 		return "", fmt.Errorf("unsupported Solana network: %s", network)
 	}
@@ -60,6 +66,17 @@ func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, erro
 		return nil, err
 	}
 
+	// Unwrap CAIP-19 asset IDs (solana:<genesis>/token:<mint>) to the mint address
+	if asset, err := caip.ParseAssetID(assetSymbolOrAddress); err == nil {
+		if asset.AssetNamespace != caip.AssetNamespaceToken {
+			return nil, fmt.Errorf("unsupported asset namespace %q: expected %s", asset.AssetNamespace, caip.AssetNamespaceToken)
+		}
+		if caip2Network, _ := NormalizeNetwork(network); asset.ChainID.String() != caip2Network {
+			return nil, fmt.Errorf("asset %s is not on network %s", asset, network)
+		}
+		assetSymbolOrAddress = asset.AssetReference
+	}
+
 	// Check if it's a valid Solana address (mint address)
 	if ValidateSolanaAddress(assetSymbolOrAddress) {
 		// Check if it matches the default asset
@@ -171,15 +188,30 @@ func DecodeTransaction(base64Tx string) (*solana.Transaction, error) {
 	return tx, nil
 }
 
-// GetTokenPayerFromTransaction extracts the token payer (owner) address from a transaction
-// This looks for the TransferChecked instruction and returns the owner/authority address
-func GetTokenPayerFromTransaction(tx *solana.Transaction) (string, error) {
+// TokenTransfer describes the TransferChecked instruction of a payment transaction
+type TokenTransfer struct {
+	Payer       string // Owner/authority of the source token account
+	Source      string // Source token account
+	Mint        string // Token mint
+	Destination string // Destination token account
+	Amount      uint64 // Amount in the token's smallest unit
+	Decimals    uint8  // Decimals declared by the instruction
+}
+
+// GetTokenTransferFromTransaction extracts the token transfer from a transaction
+// This looks for the first TransferChecked instruction, so servers can show the
+// payer, amount, mint and destination of a payload without a verify round trip.
+// It does not check signatures or balances.
+func GetTokenTransferFromTransaction(tx *solana.Transaction) (*TokenTransfer, error) {
 	if tx == nil || tx.Message.Instructions == nil {
-		return "", fmt.Errorf("invalid transaction: nil transaction or instructions")
+		return nil, fmt.Errorf("invalid transaction: nil transaction or instructions")
 	}
 
 	// Iterate through instructions to find TransferChecked
 	for _, inst := range tx.Message.Instructions {
+		if int(inst.ProgramIDIndex) >= len(tx.Message.AccountKeys) {
+			continue
+		}
 		programID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 		// Check if this is a token program instruction
@@ -196,16 +228,49 @@ func GetTokenPayerFromTransaction(tx *solana.Transaction) (string, error) {
 			}
 
 			// Check if it's a TransferChecked instruction
-			if _, ok := decoded.Impl.(*token.TransferChecked); ok {
-				// The owner/authority is the 4th account (index 3)
-				if len(accounts) >= 4 {
-					return accounts[3].PublicKey.String(), nil
-				}
+			// Accounts: [source, mint, destination, owner, ...]
+			transfer, ok := decoded.Impl.(*token.TransferChecked)
+			if !ok || len(accounts) < 4 {
+				continue
+			}
+
+			result := &TokenTransfer{
+				Payer:       accounts[3].PublicKey.String(),
+				Source:      accounts[0].PublicKey.String(),
+				Mint:        accounts[1].PublicKey.String(),
+				Destination: accounts[2].PublicKey.String(),
 			}
+			if transfer.Amount != nil {
+				result.Amount = *transfer.Amount
+			}
+			if transfer.Decimals != nil {
+				result.Decimals = *transfer.Decimals
+			}
+			return result, nil
 		}
 	}
 
-	return "", fmt.Errorf("no TransferChecked instruction found in transaction")
+	return nil, fmt.Errorf("no TransferChecked instruction found in transaction")
+}
+
+// GetTokenPayerFromTransaction extracts the token payer (owner) address from a transaction
+// This looks for the TransferChecked instruction and returns the owner/authority address
+func GetTokenPayerFromTransaction(tx *solana.Transaction) (string, error) {
+	transfer, err := GetTokenTransferFromTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	return transfer.Payer, nil
+}
+
+// GetTokenTransferFromPayload decodes a base64 payment transaction and extracts
+// its token transfer
+func GetTokenTransferFromPayload(base64Tx string) (*TokenTransfer, error) {
+	tx, err := DecodeTransaction(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	return GetTokenTransferFromTransaction(tx)
 }
 
 // EncodeTransaction encodes a Solana transaction to base64
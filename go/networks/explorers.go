@@ -0,0 +1,93 @@
+// Package networks provides per-network metadata shared across the SDK: the
+// network family, testnet status, native and default asset decimals, and
+// block explorer links.
+package networks
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// Block Explorers
+// ============================================================================
+
+// TxPlaceholder is replaced by the transaction hash in Explorer.TxURL
+const TxPlaceholder = "{tx}"
+
+// Explorer describes a block explorer for a network
+type Explorer struct {
+	// Name is the explorer's display name (e.g. "Basescan")
+	Name string
+
+	// TxURL is the transaction URL template, containing TxPlaceholder
+	// (e.g. "https://basescan.org/tx/{tx}")
+	TxURL string
+}
+
+var (
+	explorersMu sync.RWMutex
+	explorers   = map[string]Explorer{
+		// EVM (CAIP-2)
+		"eip155:1":        {Name: "Etherscan", TxURL: "https://etherscan.io/tx/{tx}"},
+		"eip155:11155111": {Name: "Etherscan", TxURL: "https://sepolia.etherscan.io/tx/{tx}"},
+		"eip155:8453":     {Name: "Basescan", TxURL: "https://basescan.org/tx/{tx}"},
+		"eip155:84532":    {Name: "Basescan", TxURL: "https://sepolia.basescan.org/tx/{tx}"},
+		"eip155:10":       {Name: "Optimism Explorer", TxURL: "https://optimistic.etherscan.io/tx/{tx}"},
+		"eip155:42161":    {Name: "Arbiscan", TxURL: "https://arbiscan.io/tx/{tx}"},
+		"eip155:137":      {Name: "Polygonscan", TxURL: "https://polygonscan.com/tx/{tx}"},
+		"eip155:43114":    {Name: "Snowtrace", TxURL: "https://snowtrace.io/tx/{tx}"},
+
+		// Solana (CAIP-2)
+		"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp": {Name: "Solscan", TxURL: "https://solscan.io/tx/{tx}"},
+		"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1": {Name: "Solscan", TxURL: "https://solscan.io/tx/{tx}?cluster=devnet"},
+		"solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z": {Name: "Solscan", TxURL: "https://solscan.io/tx/{tx}?cluster=testnet"},
+	}
+
+	// v1NetworkAliases maps legacy V1 network names to CAIP-2 identifiers
+	v1NetworkAliases = map[string]string{
+		"ethereum":       "eip155:1",
+		"sepolia":        "eip155:11155111",
+		"base":           "eip155:8453",
+		"base-sepolia":   "eip155:84532",
+		"optimism":       "eip155:10",
+		"arbitrum":       "eip155:42161",
+		"polygon":        "eip155:137",
+		"avalanche":      "eip155:43114",
+		"solana":         "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+		"solana-devnet":  "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+		"solana-testnet": "solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z",
+	}
+)
+
+// RegisterExplorer sets the block explorer for a network, replacing any
+// existing entry. Networks are CAIP-2 identifiers (e.g. "eip155:8453").
+func RegisterExplorer(network string, explorer Explorer) {
+	explorersMu.Lock()
+	defer explorersMu.Unlock()
+	explorers[network] = explorer
+}
+
+// ExplorerFor returns the block explorer for a network. Legacy V1 network
+// names (e.g. "base-sepolia") are accepted.
+func ExplorerFor(network string) (Explorer, bool) {
+	explorersMu.RLock()
+	defer explorersMu.RUnlock()
+
+	explorer, ok := explorers[Canonical(network)]
+	return explorer, ok
+}
+
+// TransactionURL returns the block explorer URL for a transaction, or false
+// when the network has no registered explorer or the hash is empty
+func TransactionURL(network string, txHash string) (string, bool) {
+	if txHash == "" {
+		return "", false
+	}
+	explorer, ok := ExplorerFor(network)
+	if !ok || !strings.Contains(explorer.TxURL, TxPlaceholder) {
+		return "", false
+	}
+	return strings.ReplaceAll(explorer.TxURL, TxPlaceholder, url.PathEscape(txHash)), true
+}
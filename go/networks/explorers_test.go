@@ -0,0 +1,46 @@
+package networks
+
+import "testing"
+
+func TestTransactionURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		tx      string
+		want    string
+		wantOK  bool
+	}{
+		{"base", "eip155:8453", "0xabc", "https://basescan.org/tx/0xabc", true},
+		{"v1 alias", "base-sepolia", "0xabc", "https://sepolia.basescan.org/tx/0xabc", true},
+		{"solana devnet", "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "5sig", "https://solscan.io/tx/5sig?cluster=devnet", true},
+		{"escapes hash", "eip155:1", "a/b", "https://etherscan.io/tx/a%2Fb", true},
+		{"unknown network", "eip155:999999", "0xabc", "", false},
+		{"empty hash", "eip155:8453", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TransactionURL(tt.network, tt.tx)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestRegisterExplorer(t *testing.T) {
+	RegisterExplorer("eip155:999999", Explorer{Name: "Custom", TxURL: "https://explorer.example/tx/{tx}"})
+	t.Cleanup(func() {
+		explorersMu.Lock()
+		delete(explorers, "eip155:999999")
+		explorersMu.Unlock()
+	})
+
+	explorer, ok := ExplorerFor("eip155:999999")
+	if !ok || explorer.Name != "Custom" {
+		t.Fatalf("Expected registered explorer, got %+v", explorer)
+	}
+	if got, _ := TransactionURL("eip155:999999", "0x1"); got != "https://explorer.example/tx/0x1" {
+		t.Errorf("Unexpected URL %q", got)
+	}
+}
@@ -0,0 +1,147 @@
+package networks
+
+import (
+	"sync"
+
+	"github.com/coinbase/x402/go/caip"
+)
+
+// ============================================================================
+// Network Registry
+// ============================================================================
+
+// Family groups networks that share an address format, signing scheme, and
+// tooling (wallets, paywall templates)
+type Family string
+
+const (
+	// FamilyUnknown is returned for networks outside a supported CAIP-2 namespace
+	FamilyUnknown Family = ""
+
+	// FamilyEVM covers eip155 networks
+	FamilyEVM Family = "evm"
+
+	// FamilySVM covers solana networks
+	FamilySVM Family = "svm"
+)
+
+// Native coin decimals per family (ETH wei, SOL lamports)
+const (
+	EVMNativeDecimals = 18
+	SVMNativeDecimals = 9
+)
+
+// Asset describes a token used for payments
+type Asset struct {
+	Address  string
+	Symbol   string
+	Decimals int
+}
+
+// Info describes a known network
+type Info struct {
+	// Name is the display name (e.g. "Base Sepolia")
+	Name string
+
+	// Testnet is true for test networks
+	Testnet bool
+
+	// DefaultAsset is the stablecoin prices are quoted in, if any
+	DefaultAsset *Asset
+}
+
+var (
+	usdcBase          = &Asset{Address: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", Symbol: "USDC", Decimals: 6}
+	usdcBaseSepolia   = &Asset{Address: "0x036CbD53842c5426634e7929541eC2318f3dCF7e", Symbol: "USDC", Decimals: 6}
+	usdcSolana        = &Asset{Address: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Symbol: "USDC", Decimals: 6}
+	usdcSolanaDevnet  = &Asset{Address: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", Symbol: "USDC", Decimals: 6}
+	usdcSolanaTestnet = &Asset{Address: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", Symbol: "USDC", Decimals: 6}
+
+	registryMu sync.RWMutex
+	registry   = map[string]Info{
+		// EVM (CAIP-2)
+		"eip155:1":        {Name: "Ethereum"},
+		"eip155:11155111": {Name: "Sepolia", Testnet: true},
+		"eip155:8453":     {Name: "Base", DefaultAsset: usdcBase},
+		"eip155:84532":    {Name: "Base Sepolia", Testnet: true, DefaultAsset: usdcBaseSepolia},
+		"eip155:10":       {Name: "Optimism"},
+		"eip155:42161":    {Name: "Arbitrum One"},
+		"eip155:137":      {Name: "Polygon"},
+		"eip155:43114":    {Name: "Avalanche C-Chain"},
+
+		// Solana (CAIP-2)
+		"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp": {Name: "Solana", DefaultAsset: usdcSolana},
+		"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1": {Name: "Solana Devnet", Testnet: true, DefaultAsset: usdcSolanaDevnet},
+		"solana:4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z": {Name: "Solana Testnet", Testnet: true, DefaultAsset: usdcSolanaTestnet},
+	}
+)
+
+// RegisterNetwork sets the metadata for a network, replacing any existing
+// entry. Networks are CAIP-2 identifiers (e.g. "eip155:8453").
+func RegisterNetwork(network string, info Info) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[network] = info
+}
+
+// Lookup returns the metadata for a known network. Legacy V1 network names
+// (e.g. "base-sepolia") are accepted.
+func Lookup(network string) (Info, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	info, ok := registry[Canonical(network)]
+	return info, ok
+}
+
+// Canonical returns the CAIP-2 identifier for a legacy V1 network name, or
+// the network unchanged
+func Canonical(network string) string {
+	if caip2, ok := v1NetworkAliases[network]; ok {
+		return caip2
+	}
+	return network
+}
+
+// FamilyOf returns the family of any network in a supported CAIP-2
+// namespace, registered or not
+func FamilyOf(network string) Family {
+	chain, err := caip.ParseChainID(Canonical(network))
+	if err != nil {
+		return FamilyUnknown
+	}
+	switch {
+	case chain.IsEVM():
+		return FamilyEVM
+	case chain.IsSolana():
+		return FamilySVM
+	}
+	return FamilyUnknown
+}
+
+// IsTestnet reports whether the network is a known test network
+func IsTestnet(network string) bool {
+	info, ok := Lookup(network)
+	return ok && info.Testnet
+}
+
+// NativeDecimals returns the decimals of the network's native coin, or 0 for
+// unknown families
+func NativeDecimals(network string) int {
+	switch FamilyOf(network) {
+	case FamilyEVM:
+		return EVMNativeDecimals
+	case FamilySVM:
+		return SVMNativeDecimals
+	}
+	return 0
+}
+
+// DefaultAsset returns the network's default payment asset, if one is known
+func DefaultAsset(network string) (Asset, bool) {
+	info, ok := Lookup(network)
+	if !ok || info.DefaultAsset == nil {
+		return Asset{}, false
+	}
+	return *info.DefaultAsset, true
+}
@@ -0,0 +1,60 @@
+package networks
+
+import "testing"
+
+func TestNetworkCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  string
+		family   Family
+		testnet  bool
+		decimals int
+	}{
+		{"base", "eip155:8453", FamilyEVM, false, 18},
+		{"base sepolia", "eip155:84532", FamilyEVM, true, 18},
+		{"v1 alias", "base-sepolia", FamilyEVM, true, 18},
+		{"unregistered evm chain", "eip155:999999", FamilyEVM, false, 18},
+		{"solana devnet", "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", FamilySVM, true, 9},
+		{"unsupported namespace", "cosmos:cosmoshub-4", FamilyUnknown, false, 0},
+		{"malformed", "not-a-network", FamilyUnknown, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FamilyOf(tt.network); got != tt.family {
+				t.Errorf("Expected family %q, got %q", tt.family, got)
+			}
+			if got := IsTestnet(tt.network); got != tt.testnet {
+				t.Errorf("Expected testnet %v, got %v", tt.testnet, got)
+			}
+			if got := NativeDecimals(tt.network); got != tt.decimals {
+				t.Errorf("Expected %d native decimals, got %d", tt.decimals, got)
+			}
+		})
+	}
+}
+
+func TestDefaultAsset(t *testing.T) {
+	asset, ok := DefaultAsset("base")
+	if !ok || asset.Address != "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913" || asset.Decimals != 6 {
+		t.Errorf("Expected Base USDC, got %+v (%v)", asset, ok)
+	}
+
+	if _, ok := DefaultAsset("eip155:1"); ok {
+		t.Error("Expected no default asset for a network without one")
+	}
+
+	RegisterNetwork("eip155:999999", Info{Name: "Custom", Testnet: true, DefaultAsset: &Asset{Address: "0xabc", Symbol: "TEST", Decimals: 2}})
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "eip155:999999")
+		registryMu.Unlock()
+	}()
+
+	if !IsTestnet("eip155:999999") {
+		t.Error("Expected registered network to be a testnet")
+	}
+	if asset, ok := DefaultAsset("eip155:999999"); !ok || asset.Decimals != 2 {
+		t.Errorf("Expected registered default asset, got %+v (%v)", asset, ok)
+	}
+}
@@ -0,0 +1,58 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Partial Settlement
+// ============================================================================
+
+// SettlePartial settles a V2 payment for amountToSettle (atomic units, at
+// most the requirements' amount) instead of the authorized maximum, e.g. for
+// what a metered request actually used. The mechanism must implement
+// SchemeNetworkPartialSettler unless amountToSettle is the full amount.
+func (f *x402Facilitator) SettlePartial(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*SettleResponse, error) {
+	if amountToSettle == "" {
+		return nil, NewSettleError(ErrInvalidAmountToSettle, "", "", "", "amount to settle is required")
+	}
+	return f.settle(ctx, payloadBytes, requirementsBytes, amountToSettle)
+}
+
+// SettlePaymentAmount settles a V2 payment for amountToSettle (atomic units,
+// at most the requirements' amount) instead of the authorized maximum. The
+// facilitator must implement PartialSettlingFacilitatorClient unless
+// amountToSettle is the full amount.
+func (s *x402ResourceServer) SettlePaymentAmount(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error) {
+	if err := checkAmountToSettle(amountToSettle, requirements.Amount); err != nil {
+		return nil, NewSettleError(ErrInvalidAmountToSettle, "", Network(requirements.Network), "", err.Error())
+	}
+	if amountToSettle == requirements.Amount {
+		amountToSettle = ""
+	}
+	return s.settlePayment(ctx, payload, requirements, amountToSettle)
+}
+
+// checkAmountToSettle checks that amountToSettle is empty or a positive
+// amount no greater than maxAmount
+func checkAmountToSettle(amountToSettle, maxAmount string) error {
+	if amountToSettle == "" {
+		return nil
+	}
+	amount, ok := new(big.Int).SetString(amountToSettle, 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("invalid amount to settle %q", amountToSettle)
+	}
+	max, ok := new(big.Int).SetString(maxAmount, 10)
+	if !ok {
+		return fmt.Errorf("invalid requirements amount %q", maxAmount)
+	}
+	if amount.Cmp(max) > 0 {
+		return fmt.Errorf("amount to settle %s exceeds the authorized %s", amountToSettle, maxAmount)
+	}
+	return nil
+}
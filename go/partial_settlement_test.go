@@ -0,0 +1,116 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+type mockPartialSettler struct {
+	mockSchemeNetworkFacilitator
+	settled string
+}
+
+func (m *mockPartialSettler) SettlePartial(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error) {
+	m.settled = amountToSettle
+	return &SettleResponse{Success: true, Transaction: "0xpartial", Network: Network(requirements.Network)}, nil
+}
+
+func partialSettlementBytes() ([]byte, []byte) {
+	requirements := types.PaymentRequirements{Scheme: "upto", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+func TestFacilitatorSettlePartial(t *testing.T) {
+	ctx := context.Background()
+	payloadBytes, requirementsBytes := partialSettlementBytes()
+
+	settler := &mockPartialSettler{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "upto"}}
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, settler)
+
+	response, err := facilitator.SettlePartial(ctx, payloadBytes, requirementsBytes, "250000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xpartial" || settler.settled != "250000" {
+		t.Errorf("Expected a partial settlement of 250000, got %s (%+v)", settler.settled, response)
+	}
+
+	// The full amount settles normally
+	response, err = facilitator.SettlePartial(ctx, payloadBytes, requirementsBytes, "1000000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xmocktx" {
+		t.Errorf("Expected a full settlement, got %s", response.Transaction)
+	}
+
+	for _, amount := range []string{"1000001", "0", "-5", "abc"} {
+		_, err := facilitator.SettlePartial(ctx, payloadBytes, requirementsBytes, amount)
+		var se *SettleError
+		if !errors.As(err, &se) || se.ErrorReason != ErrInvalidAmountToSettle {
+			t.Errorf("Amount %s: expected %s, got %v", amount, ErrInvalidAmountToSettle, err)
+		}
+	}
+}
+
+func TestFacilitatorSettlePartialNotSupported(t *testing.T) {
+	payloadBytes, requirementsBytes := partialSettlementBytes()
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "upto"})
+
+	_, err := facilitator.SettlePartial(context.Background(), payloadBytes, requirementsBytes, "250000")
+	var se *SettleError
+	if !errors.As(err, &se) || se.ErrorReason != ErrPartialSettlementNotSupported {
+		t.Errorf("Expected %s, got %v", ErrPartialSettlementNotSupported, err)
+	}
+}
+
+type mockPartialFacilitatorClient struct {
+	mockFacilitatorClient
+	settled string
+}
+
+func (m *mockPartialFacilitatorClient) SettlePartial(ctx context.Context, payloadBytes []byte, requirementsBytes []byte, amountToSettle string) (*SettleResponse, error) {
+	m.settled = amountToSettle
+	return &SettleResponse{Success: true, Transaction: "0xpartial", Network: "eip155:1"}, nil
+}
+
+func TestServerSettlePaymentAmount(t *testing.T) {
+	ctx := context.Background()
+	requirements := types.PaymentRequirements{Scheme: "upto", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+
+	client := &mockPartialFacilitatorClient{mockFacilitatorClient: mockFacilitatorClient{
+		kinds: []SupportedKind{{X402Version: 2, Scheme: "upto", Network: "eip155:1"}},
+	}}
+	var hookAmount string
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(client),
+		WithBeforeSettleHook(func(ctx SettleContext) (*BeforeHookResult, error) {
+			hookAmount = ctx.AmountToSettle
+			return nil, nil
+		}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	response, err := server.SettlePaymentAmount(ctx, payload, requirements, "400")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xpartial" || client.settled != "400" || hookAmount != "400" {
+		t.Errorf("Expected a partial settlement of 400, got %s (hook saw %q)", client.settled, hookAmount)
+	}
+
+	if _, err := server.SettlePaymentAmount(ctx, payload, requirements, "2000000"); err == nil {
+		t.Error("Expected an error settling more than authorized")
+	}
+}
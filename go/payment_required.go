@@ -0,0 +1,115 @@
+package x402
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// PaymentRequiredBuilder constructs a V2 PaymentRequired challenge independently
+// of any transport, so queues, gRPC, MCP, and other non-HTTP integrations can
+// issue the same 402 challenges as go/http:
+//
+//	required, err := x402.NewPaymentRequired().
+//		WithOption(requirements).
+//		WithResource(types.ResourceInfo{URL: "mcp://tools/search", Description: "Web search"}).
+//		Build()
+//
+// Requirements can come from X402ResourceServer.BuildPaymentRequirementsFromConfig
+// or be written by hand.
+type PaymentRequiredBuilder struct {
+	required types.PaymentRequired
+}
+
+// NewPaymentRequired starts a V2 PaymentRequired challenge
+func NewPaymentRequired() *PaymentRequiredBuilder {
+	return &PaymentRequiredBuilder{
+		required: types.PaymentRequired{
+			X402Version: 2,
+			Accepts:     []types.PaymentRequirements{},
+		},
+	}
+}
+
+// WithOption adds a payment option the client may choose
+func (b *PaymentRequiredBuilder) WithOption(requirements types.PaymentRequirements) *PaymentRequiredBuilder {
+	b.required.Accepts = append(b.required.Accepts, requirements)
+	return b
+}
+
+// WithOptions adds several payment options
+func (b *PaymentRequiredBuilder) WithOptions(requirements ...types.PaymentRequirements) *PaymentRequiredBuilder {
+	b.required.Accepts = append(b.required.Accepts, requirements...)
+	return b
+}
+
+// WithResource describes the resource being paid for
+func (b *PaymentRequiredBuilder) WithResource(resource types.ResourceInfo) *PaymentRequiredBuilder {
+	b.required.Resource = &resource
+	return b
+}
+
+// WithError sets the human-readable reason payment is required
+func (b *PaymentRequiredBuilder) WithError(message string) *PaymentRequiredBuilder {
+	b.required.Error = message
+	return b
+}
+
+// WithExtension adds a protocol extension entry
+func (b *PaymentRequiredBuilder) WithExtension(key string, value interface{}) *PaymentRequiredBuilder {
+	if b.required.Extensions == nil {
+		b.required.Extensions = make(map[string]interface{})
+	}
+	b.required.Extensions[key] = value
+	return b
+}
+
+// Build validates and returns the challenge. At least one option is required,
+// every option must name its scheme, network, asset, amount, and payTo, and a
+// resource, when set, must have a URL.
+func (b *PaymentRequiredBuilder) Build() (types.PaymentRequired, error) {
+	if len(b.required.Accepts) == 0 {
+		return types.PaymentRequired{}, errors.New("payment required must include at least one payment option")
+	}
+	for i, option := range b.required.Accepts {
+		if err := validatePaymentOption(option); err != nil {
+			return types.PaymentRequired{}, fmt.Errorf("invalid payment option %d: %w", i, err)
+		}
+	}
+	if b.required.Resource != nil && b.required.Resource.URL == "" {
+		return types.PaymentRequired{}, errors.New("resource must have a URL")
+	}
+
+	// Copy so the builder can keep being used without aliasing the result
+	required := b.required
+	required.Accepts = append([]types.PaymentRequirements(nil), b.required.Accepts...)
+	if b.required.Resource != nil {
+		resource := *b.required.Resource
+		required.Resource = &resource
+	}
+	if b.required.Extensions != nil {
+		required.Extensions = make(map[string]interface{}, len(b.required.Extensions))
+		for k, v := range b.required.Extensions {
+			required.Extensions[k] = v
+		}
+	}
+	return required, nil
+}
+
+// validatePaymentOption checks the fields every V2 payment option must carry
+func validatePaymentOption(option types.PaymentRequirements) error {
+	switch {
+	case option.Scheme == "":
+		return errors.New("scheme is required")
+	case option.Network == "":
+		return errors.New("network is required")
+	case option.Asset == "":
+		return errors.New("asset is required")
+	case option.Amount == "":
+		return errors.New("amount is required")
+	case option.PayTo == "":
+		return errors.New("payTo is required")
+	}
+	return nil
+}
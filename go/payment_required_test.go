@@ -0,0 +1,71 @@
+package x402
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func testRequirements() types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:            "exact",
+		Network:           "eip155:8453",
+		Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:            "10000",
+		PayTo:             "0xabc",
+		MaxTimeoutSeconds: 300,
+	}
+}
+
+func TestPaymentRequiredBuilder(t *testing.T) {
+	builder := NewPaymentRequired().
+		WithOption(testRequirements()).
+		WithResource(types.ResourceInfo{URL: "mcp://tools/search", Description: "Web search"}).
+		WithError("Payment required").
+		WithExtension("bazaar", map[string]interface{}{"discoverable": true})
+
+	required, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if required.X402Version != 2 || len(required.Accepts) != 1 || required.Error != "Payment required" {
+		t.Fatalf("unexpected payment required %+v", required)
+	}
+	if required.Resource == nil || required.Resource.URL != "mcp://tools/search" {
+		t.Fatalf("expected resource, got %+v", required.Resource)
+	}
+	if _, ok := required.Extensions["bazaar"]; !ok {
+		t.Fatalf("expected bazaar extension, got %v", required.Extensions)
+	}
+
+	// Further use of the builder must not change a built challenge
+	builder.WithOption(testRequirements()).WithExtension("other", true)
+	if len(required.Accepts) != 1 || len(required.Extensions) != 1 {
+		t.Fatalf("expected built challenge to be independent of the builder, got %+v", required)
+	}
+}
+
+func TestPaymentRequiredBuilderValidation(t *testing.T) {
+	missingPayTo := testRequirements()
+	missingPayTo.PayTo = ""
+
+	tests := []struct {
+		name    string
+		builder *PaymentRequiredBuilder
+		wantErr string
+	}{
+		{"no options", NewPaymentRequired(), "at least one payment option"},
+		{"missing payTo", NewPaymentRequired().WithOptions(testRequirements(), missingPayTo), "invalid payment option 1: payTo is required"},
+		{"resource without url", NewPaymentRequired().WithOption(testRequirements()).WithResource(types.ResourceInfo{}), "resource must have a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package x402pb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ============================================================================
+// JSON Types to Protobuf
+// ============================================================================
+
+// FromResourceInfo converts resource info to its protobuf message
+func FromResourceInfo(info *types.ResourceInfo) (*ResourceInfo, error) {
+	if info == nil {
+		return nil, nil
+	}
+	outputSchema, err := toStruct(info.OutputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	var outputExample *structpb.Value
+	if info.OutputExample != nil {
+		if outputExample, err = toValue(info.OutputExample); err != nil {
+			return nil, fmt.Errorf("invalid output example: %w", err)
+		}
+	}
+	return &ResourceInfo{
+		Url:           info.URL,
+		Description:   info.Description,
+		MimeType:      info.MimeType,
+		OutputSchema:  outputSchema,
+		OutputExample: outputExample,
+	}, nil
+}
+
+// FromPaymentRequirements converts payment requirements to their protobuf message
+func FromPaymentRequirements(requirements types.PaymentRequirements) (*PaymentRequirements, error) {
+	extra, err := toStruct(requirements.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extra: %w", err)
+	}
+	return &PaymentRequirements{
+		Scheme:            requirements.Scheme,
+		Network:           requirements.Network,
+		Asset:             requirements.Asset,
+		Amount:            requirements.Amount,
+		PayTo:             requirements.PayTo,
+		MaxTimeoutSeconds: int64(requirements.MaxTimeoutSeconds),
+		Extra:             extra,
+		ExpiresAt:         requirements.ExpiresAt,
+	}, nil
+}
+
+// FromPaymentRequired converts a 402 challenge to its protobuf message
+func FromPaymentRequired(required types.PaymentRequired) (*PaymentRequired, error) {
+	resource, err := FromResourceInfo(required.Resource)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := toStruct(required.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extensions: %w", err)
+	}
+	accepts := make([]*PaymentRequirements, 0, len(required.Accepts))
+	for _, requirements := range required.Accepts {
+		converted, err := FromPaymentRequirements(requirements)
+		if err != nil {
+			return nil, err
+		}
+		accepts = append(accepts, converted)
+	}
+	return &PaymentRequired{
+		X402Version: int32(required.X402Version),
+		Error:       required.Error,
+		Resource:    resource,
+		Accepts:     accepts,
+		Extensions:  extensions,
+	}, nil
+}
+
+// FromPaymentPayload converts a payment payload to its protobuf message
+func FromPaymentPayload(payload types.PaymentPayload) (*PaymentPayload, error) {
+	signed, err := toStruct(payload.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	accepted, err := FromPaymentRequirements(payload.Accepted)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := FromResourceInfo(payload.Resource)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := toStruct(payload.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extensions: %w", err)
+	}
+	return &PaymentPayload{
+		X402Version:      int32(payload.X402Version),
+		Payload:          signed,
+		Accepted:         accepted,
+		Resource:         resource,
+		Extensions:       extensions,
+		RequirementsHash: payload.RequirementsHash,
+	}, nil
+}
+
+// NewVerifyRequest creates a verify request for a payment and the requirements it pays
+func NewVerifyRequest(payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyRequest, error) {
+	convertedPayload, convertedRequirements, err := fromPaymentPair(payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyRequest{
+		X402Version:         int32(payload.X402Version),
+		PaymentPayload:      convertedPayload,
+		PaymentRequirements: convertedRequirements,
+	}, nil
+}
+
+// NewSettleRequest creates a settle request for a payment and the requirements it pays
+func NewSettleRequest(payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleRequest, error) {
+	convertedPayload, convertedRequirements, err := fromPaymentPair(payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+	return &SettleRequest{
+		X402Version:         int32(payload.X402Version),
+		PaymentPayload:      convertedPayload,
+		PaymentRequirements: convertedRequirements,
+	}, nil
+}
+
+// FromVerifyResponse converts a verification result to its protobuf message
+func FromVerifyResponse(response x402.VerifyResponse) *VerifyResponse {
+	return &VerifyResponse{
+		IsValid:        response.IsValid,
+		InvalidReason:  response.InvalidReason,
+		InvalidMessage: response.InvalidMessage,
+		Payer:          response.Payer,
+	}
+}
+
+// FromSettleResponse converts a settlement result to its protobuf message
+func FromSettleResponse(response x402.SettleResponse) *SettleResponse {
+	return &SettleResponse{
+		Success:      response.Success,
+		ErrorReason:  response.ErrorReason,
+		ErrorMessage: response.ErrorMessage,
+		Payer:        response.Payer,
+		Transaction:  response.Transaction,
+		Network:      string(response.Network),
+	}
+}
+
+// ============================================================================
+// Protobuf to JSON Types
+// ============================================================================
+
+// ToTypes converts the message to resource info (nil for a nil message)
+func (x *ResourceInfo) ToTypes() *types.ResourceInfo {
+	if x == nil {
+		return nil
+	}
+	info := &types.ResourceInfo{
+		URL:          x.GetUrl(),
+		Description:  x.GetDescription(),
+		MimeType:     x.GetMimeType(),
+		OutputSchema: fromStruct(x.GetOutputSchema()),
+	}
+	if x.GetOutputExample() != nil {
+		info.OutputExample = x.GetOutputExample().AsInterface()
+	}
+	return info
+}
+
+// ToTypes converts the message to payment requirements
+func (x *PaymentRequirements) ToTypes() types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:            x.GetScheme(),
+		Network:           x.GetNetwork(),
+		Asset:             x.GetAsset(),
+		Amount:            x.GetAmount(),
+		PayTo:             x.GetPayTo(),
+		MaxTimeoutSeconds: int(x.GetMaxTimeoutSeconds()),
+		Extra:             fromStruct(x.GetExtra()),
+		ExpiresAt:         x.GetExpiresAt(),
+	}
+}
+
+// ToTypes converts the message to a 402 challenge
+func (x *PaymentRequired) ToTypes() types.PaymentRequired {
+	accepts := make([]types.PaymentRequirements, 0, len(x.GetAccepts()))
+	for _, requirements := range x.GetAccepts() {
+		accepts = append(accepts, requirements.ToTypes())
+	}
+	return types.PaymentRequired{
+		X402Version: int(x.GetX402Version()),
+		Error:       x.GetError(),
+		Resource:    x.GetResource().ToTypes(),
+		Accepts:     accepts,
+		Extensions:  fromStruct(x.GetExtensions()),
+	}
+}
+
+// ToTypes converts the message to a payment payload
+func (x *PaymentPayload) ToTypes() types.PaymentPayload {
+	return types.PaymentPayload{
+		X402Version:      int(x.GetX402Version()),
+		Payload:          fromStruct(x.GetPayload()),
+		Accepted:         x.GetAccepted().ToTypes(),
+		Resource:         x.GetResource().ToTypes(),
+		Extensions:       fromStruct(x.GetExtensions()),
+		RequirementsHash: x.GetRequirementsHash(),
+	}
+}
+
+// ToX402 converts the message to a verification result
+func (x *VerifyResponse) ToX402() x402.VerifyResponse {
+	return x402.VerifyResponse{
+		IsValid:        x.GetIsValid(),
+		InvalidReason:  x.GetInvalidReason(),
+		InvalidMessage: x.GetInvalidMessage(),
+		Payer:          x.GetPayer(),
+	}
+}
+
+// ToX402 converts the message to a settlement result
+func (x *SettleResponse) ToX402() x402.SettleResponse {
+	return x402.SettleResponse{
+		Success:      x.GetSuccess(),
+		ErrorReason:  x.GetErrorReason(),
+		ErrorMessage: x.GetErrorMessage(),
+		Payer:        x.GetPayer(),
+		Transaction:  x.GetTransaction(),
+		Network:      x402.Network(x.GetNetwork()),
+	}
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+func fromPaymentPair(payload types.PaymentPayload, requirements types.PaymentRequirements) (*PaymentPayload, *PaymentRequirements, error) {
+	convertedPayload, err := FromPaymentPayload(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	convertedRequirements, err := FromPaymentRequirements(requirements)
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertedPayload, convertedRequirements, nil
+}
+
+// toStruct converts a JSON object to a Struct (nil for an empty object). Values
+// go through encoding/json first so typed Go values (e.g. []string or structs
+// in Extra) convert the same way they serialize.
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	var normalized map[string]interface{}
+	if err := normalizeJSON(m, &normalized); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(normalized)
+}
+
+// toValue converts any JSON value to a Value
+func toValue(v interface{}) (*structpb.Value, error) {
+	var normalized interface{}
+	if err := normalizeJSON(v, &normalized); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(normalized)
+}
+
+func normalizeJSON(v interface{}, out interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// fromStruct converts a Struct to a JSON object (nil for a nil Struct)
+func fromStruct(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
@@ -0,0 +1,124 @@
+package x402pb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func testRequirements() types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:            "exact",
+		Network:           "eip155:8453",
+		Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:            "10000",
+		PayTo:             "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		MaxTimeoutSeconds: 300,
+		ExpiresAt:         1700000300,
+		Extra:             map[string]interface{}{"name": "USD Coin", "version": "2"},
+	}
+}
+
+func TestPaymentRequiredRoundTrip(t *testing.T) {
+	required := types.PaymentRequired{
+		X402Version: 2,
+		Error:       "Payment required",
+		Resource: &types.ResourceInfo{
+			URL:           "https://api.example.com/weather",
+			MimeType:      "application/json",
+			OutputSchema:  map[string]interface{}{"type": "object"},
+			OutputExample: map[string]interface{}{"temperature": 21.5},
+		},
+		Accepts:    []types.PaymentRequirements{testRequirements()},
+		Extensions: map[string]interface{}{"bazaar": map[string]interface{}{"discoverable": true}},
+	}
+
+	message, err := FromPaymentRequired(required)
+	if err != nil {
+		t.Fatalf("FromPaymentRequired failed: %v", err)
+	}
+	wire, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded PaymentRequired
+	if err := proto.Unmarshal(wire, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	assertSameJSON(t, decoded.ToTypes(), required)
+}
+
+func TestPaymentPayloadRoundTrip(t *testing.T) {
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    testRequirements(),
+		Payload: map[string]interface{}{
+			"signature": "0x2d6a7588",
+			"authorization": map[string]interface{}{
+				"from":  "0x857b06519E91e3A54538791bDbb0E22373e36b66",
+				"value": "10000",
+			},
+		},
+	}
+
+	request, err := NewVerifyRequest(payload, payload.Accepted)
+	if err != nil {
+		t.Fatalf("NewVerifyRequest failed: %v", err)
+	}
+	if request.GetX402Version() != 2 || request.GetPaymentRequirements().GetPayTo() != payload.Accepted.PayTo {
+		t.Errorf("unexpected request %v", request)
+	}
+	assertSameJSON(t, request.GetPaymentPayload().ToTypes(), payload)
+}
+
+func TestTypedExtraValues(t *testing.T) {
+	requirements := testRequirements()
+	requirements.Extra = map[string]interface{}{"signers": []string{"a", "b"}}
+
+	message, err := FromPaymentRequirements(requirements)
+	if err != nil {
+		t.Fatalf("FromPaymentRequirements failed: %v", err)
+	}
+	signers := message.GetExtra().GetFields()["signers"].GetListValue().GetValues()
+	if len(signers) != 2 || signers[1].GetStringValue() != "b" {
+		t.Errorf("unexpected signers %v", signers)
+	}
+
+	if _, err := FromPaymentRequirements(types.PaymentRequirements{Extra: map[string]interface{}{"bad": make(chan int)}}); err == nil {
+		t.Error("expected error for a value with no JSON encoding")
+	}
+}
+
+func TestResponseConversions(t *testing.T) {
+	verify := x402.VerifyResponse{IsValid: false, InvalidReason: "insufficient_funds", InvalidMessage: "balance too low", Payer: "0xpayer"}
+	if got := FromVerifyResponse(verify).ToX402(); got != verify {
+		t.Errorf("verify round trip = %+v", got)
+	}
+
+	settle := x402.SettleResponse{Success: true, Payer: "0xpayer", Transaction: "0xtx", Network: "eip155:8453"}
+	if got := FromSettleResponse(settle).ToX402(); got != settle {
+		t.Errorf("settle round trip = %+v", got)
+	}
+
+	var empty *ResourceInfo
+	if empty.ToTypes() != nil {
+		t.Error("nil resource should convert to nil")
+	}
+}
+
+func assertSameJSON(t *testing.T, got, want interface{}) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	var gotValue, wantValue interface{}
+	_ = json.Unmarshal(gotJSON, &gotValue)
+	_ = json.Unmarshal(wantJSON, &wantValue)
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("JSON mismatch:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+}
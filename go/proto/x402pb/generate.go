@@ -0,0 +1,12 @@
+// Package x402pb holds protocol buffer types for the x402 v2 wire format,
+// generated from proto/x402/v2/x402.proto, and converters to and from the
+// JSON types in the types and x402 packages.
+//
+// The messages carry the same data as the JSON encoding, so a gRPC facilitator
+// or a client in another language can exchange payments with the HTTP SDK
+// without loss. Free-form objects (extra, payload, extensions) are
+// google.protobuf.Struct values, whose numbers are doubles: keep large
+// integers in those objects as strings, as the schemes already do.
+package x402pb
+
+//go:generate protoc -I .. --go_out=../.. --go_opt=module=github.com/coinbase/x402/go ../x402/v2/x402.proto
@@ -0,0 +1,792 @@
+// Protocol buffer definitions for the x402 v2 wire format.
+//
+// Messages mirror the JSON types in github.com/coinbase/x402/go/types field for
+// field. Free-form JSON objects (extra, payload, extensions) are
+// google.protobuf.Struct values. Amounts stay decimal strings in the asset's
+// smallest unit so no precision is lost.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: x402/v2/x402.proto
+
+package x402pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ResourceInfo describes the resource being paid for.
+type ResourceInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Url         string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	MimeType    string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// JSON Schema of the response returned after payment.
+	OutputSchema *structpb.Struct `protobuf:"bytes,4,opt,name=output_schema,json=outputSchema,proto3" json:"output_schema,omitempty"`
+	// Example of the response returned after payment.
+	OutputExample *structpb.Value `protobuf:"bytes,5,opt,name=output_example,json=outputExample,proto3" json:"output_example,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceInfo) Reset() {
+	*x = ResourceInfo{}
+	mi := &file_x402_v2_x402_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceInfo) ProtoMessage() {}
+
+func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceInfo.ProtoReflect.Descriptor instead.
+func (*ResourceInfo) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResourceInfo) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResourceInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ResourceInfo) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *ResourceInfo) GetOutputSchema() *structpb.Struct {
+	if x != nil {
+		return x.OutputSchema
+	}
+	return nil
+}
+
+func (x *ResourceInfo) GetOutputExample() *structpb.Value {
+	if x != nil {
+		return x.OutputExample
+	}
+	return nil
+}
+
+// PaymentRequirements is one way a client can pay for a resource.
+type PaymentRequirements struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Scheme string                 `protobuf:"bytes,1,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	// CAIP-2 network identifier, e.g. "eip155:8453".
+	Network string `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	Asset   string `protobuf:"bytes,3,opt,name=asset,proto3" json:"asset,omitempty"`
+	// Amount in the asset's smallest unit, as a decimal string.
+	Amount            string `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	PayTo             string `protobuf:"bytes,5,opt,name=pay_to,json=payTo,proto3" json:"pay_to,omitempty"`
+	MaxTimeoutSeconds int64  `protobuf:"varint,6,opt,name=max_timeout_seconds,json=maxTimeoutSeconds,proto3" json:"max_timeout_seconds,omitempty"`
+	// Scheme-specific fields.
+	Extra *structpb.Struct `protobuf:"bytes,7,opt,name=extra,proto3" json:"extra,omitempty"`
+	// Unix time in seconds after which the requirements must not be paid; 0 means no expiry.
+	ExpiresAt     int64 `protobuf:"varint,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentRequirements) Reset() {
+	*x = PaymentRequirements{}
+	mi := &file_x402_v2_x402_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentRequirements) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentRequirements) ProtoMessage() {}
+
+func (x *PaymentRequirements) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentRequirements.ProtoReflect.Descriptor instead.
+func (*PaymentRequirements) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PaymentRequirements) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *PaymentRequirements) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *PaymentRequirements) GetAsset() string {
+	if x != nil {
+		return x.Asset
+	}
+	return ""
+}
+
+func (x *PaymentRequirements) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PaymentRequirements) GetPayTo() string {
+	if x != nil {
+		return x.PayTo
+	}
+	return ""
+}
+
+func (x *PaymentRequirements) GetMaxTimeoutSeconds() int64 {
+	if x != nil {
+		return x.MaxTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *PaymentRequirements) GetExtra() *structpb.Struct {
+	if x != nil {
+		return x.Extra
+	}
+	return nil
+}
+
+func (x *PaymentRequirements) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// PaymentRequired is the 402 challenge (the PAYMENT-REQUIRED header).
+type PaymentRequired struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X402Version   int32                  `protobuf:"varint,1,opt,name=x402_version,json=x402Version,proto3" json:"x402_version,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Resource      *ResourceInfo          `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Accepts       []*PaymentRequirements `protobuf:"bytes,4,rep,name=accepts,proto3" json:"accepts,omitempty"`
+	Extensions    *structpb.Struct       `protobuf:"bytes,5,opt,name=extensions,proto3" json:"extensions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentRequired) Reset() {
+	*x = PaymentRequired{}
+	mi := &file_x402_v2_x402_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentRequired) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentRequired) ProtoMessage() {}
+
+func (x *PaymentRequired) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentRequired.ProtoReflect.Descriptor instead.
+func (*PaymentRequired) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PaymentRequired) GetX402Version() int32 {
+	if x != nil {
+		return x.X402Version
+	}
+	return 0
+}
+
+func (x *PaymentRequired) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PaymentRequired) GetResource() *ResourceInfo {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *PaymentRequired) GetAccepts() []*PaymentRequirements {
+	if x != nil {
+		return x.Accepts
+	}
+	return nil
+}
+
+func (x *PaymentRequired) GetExtensions() *structpb.Struct {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+// PaymentPayload is a signed payment (the PAYMENT-SIGNATURE header).
+type PaymentPayload struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	X402Version int32                  `protobuf:"varint,1,opt,name=x402_version,json=x402Version,proto3" json:"x402_version,omitempty"`
+	// Scheme-specific signed payload.
+	Payload *structpb.Struct `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The requirements the client chose to pay.
+	Accepted   *PaymentRequirements `protobuf:"bytes,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Resource   *ResourceInfo        `protobuf:"bytes,4,opt,name=resource,proto3" json:"resource,omitempty"`
+	Extensions *structpb.Struct     `protobuf:"bytes,5,opt,name=extensions,proto3" json:"extensions,omitempty"`
+	// Canonical JSON digest of accepted, "sha256:<hex>".
+	RequirementsHash string `protobuf:"bytes,6,opt,name=requirements_hash,json=requirementsHash,proto3" json:"requirements_hash,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PaymentPayload) Reset() {
+	*x = PaymentPayload{}
+	mi := &file_x402_v2_x402_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentPayload) ProtoMessage() {}
+
+func (x *PaymentPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentPayload.ProtoReflect.Descriptor instead.
+func (*PaymentPayload) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PaymentPayload) GetX402Version() int32 {
+	if x != nil {
+		return x.X402Version
+	}
+	return 0
+}
+
+func (x *PaymentPayload) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PaymentPayload) GetAccepted() *PaymentRequirements {
+	if x != nil {
+		return x.Accepted
+	}
+	return nil
+}
+
+func (x *PaymentPayload) GetResource() *ResourceInfo {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *PaymentPayload) GetExtensions() *structpb.Struct {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *PaymentPayload) GetRequirementsHash() string {
+	if x != nil {
+		return x.RequirementsHash
+	}
+	return ""
+}
+
+// VerifyRequest asks a facilitator to verify a payment.
+type VerifyRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	X402Version         int32                  `protobuf:"varint,1,opt,name=x402_version,json=x402Version,proto3" json:"x402_version,omitempty"`
+	PaymentPayload      *PaymentPayload        `protobuf:"bytes,2,opt,name=payment_payload,json=paymentPayload,proto3" json:"payment_payload,omitempty"`
+	PaymentRequirements *PaymentRequirements   `protobuf:"bytes,3,opt,name=payment_requirements,json=paymentRequirements,proto3" json:"payment_requirements,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_x402_v2_x402_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyRequest) GetX402Version() int32 {
+	if x != nil {
+		return x.X402Version
+	}
+	return 0
+}
+
+func (x *VerifyRequest) GetPaymentPayload() *PaymentPayload {
+	if x != nil {
+		return x.PaymentPayload
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetPaymentRequirements() *PaymentRequirements {
+	if x != nil {
+		return x.PaymentRequirements
+	}
+	return nil
+}
+
+// VerifyResponse is the result of payment verification.
+type VerifyResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IsValid        bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	InvalidReason  string                 `protobuf:"bytes,2,opt,name=invalid_reason,json=invalidReason,proto3" json:"invalid_reason,omitempty"`
+	InvalidMessage string                 `protobuf:"bytes,3,opt,name=invalid_message,json=invalidMessage,proto3" json:"invalid_message,omitempty"`
+	Payer          string                 `protobuf:"bytes,4,opt,name=payer,proto3" json:"payer,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_x402_v2_x402_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VerifyResponse) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetInvalidReason() string {
+	if x != nil {
+		return x.InvalidReason
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetInvalidMessage() string {
+	if x != nil {
+		return x.InvalidMessage
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetPayer() string {
+	if x != nil {
+		return x.Payer
+	}
+	return ""
+}
+
+// SettleRequest asks a facilitator to settle a verified payment.
+type SettleRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	X402Version         int32                  `protobuf:"varint,1,opt,name=x402_version,json=x402Version,proto3" json:"x402_version,omitempty"`
+	PaymentPayload      *PaymentPayload        `protobuf:"bytes,2,opt,name=payment_payload,json=paymentPayload,proto3" json:"payment_payload,omitempty"`
+	PaymentRequirements *PaymentRequirements   `protobuf:"bytes,3,opt,name=payment_requirements,json=paymentRequirements,proto3" json:"payment_requirements,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SettleRequest) Reset() {
+	*x = SettleRequest{}
+	mi := &file_x402_v2_x402_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettleRequest) ProtoMessage() {}
+
+func (x *SettleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettleRequest.ProtoReflect.Descriptor instead.
+func (*SettleRequest) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SettleRequest) GetX402Version() int32 {
+	if x != nil {
+		return x.X402Version
+	}
+	return 0
+}
+
+func (x *SettleRequest) GetPaymentPayload() *PaymentPayload {
+	if x != nil {
+		return x.PaymentPayload
+	}
+	return nil
+}
+
+func (x *SettleRequest) GetPaymentRequirements() *PaymentRequirements {
+	if x != nil {
+		return x.PaymentRequirements
+	}
+	return nil
+}
+
+// SettleResponse is the result of payment settlement.
+type SettleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorReason   string                 `protobuf:"bytes,2,opt,name=error_reason,json=errorReason,proto3" json:"error_reason,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Payer         string                 `protobuf:"bytes,4,opt,name=payer,proto3" json:"payer,omitempty"`
+	Transaction   string                 `protobuf:"bytes,5,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Network       string                 `protobuf:"bytes,6,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SettleResponse) Reset() {
+	*x = SettleResponse{}
+	mi := &file_x402_v2_x402_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettleResponse) ProtoMessage() {}
+
+func (x *SettleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_x402_v2_x402_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettleResponse.ProtoReflect.Descriptor instead.
+func (*SettleResponse) Descriptor() ([]byte, []int) {
+	return file_x402_v2_x402_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SettleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SettleResponse) GetErrorReason() string {
+	if x != nil {
+		return x.ErrorReason
+	}
+	return ""
+}
+
+func (x *SettleResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *SettleResponse) GetPayer() string {
+	if x != nil {
+		return x.Payer
+	}
+	return ""
+}
+
+func (x *SettleResponse) GetTransaction() string {
+	if x != nil {
+		return x.Transaction
+	}
+	return ""
+}
+
+func (x *SettleResponse) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+var File_x402_v2_x402_proto protoreflect.FileDescriptor
+
+const file_x402_v2_x402_proto_rawDesc = "" +
+	"\n" +
+	"\x12x402/v2/x402.proto\x12\ax402.v2\x1a\x1cgoogle/protobuf/struct.proto\"\xdc\x01\n" +
+	"\fResourceInfo\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12<\n" +
+	"\routput_schema\x18\x04 \x01(\v2\x17.google.protobuf.StructR\foutputSchema\x12=\n" +
+	"\x0eoutput_example\x18\x05 \x01(\v2\x16.google.protobuf.ValueR\routputExample\"\x8a\x02\n" +
+	"\x13PaymentRequirements\x12\x16\n" +
+	"\x06scheme\x18\x01 \x01(\tR\x06scheme\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\x12\x14\n" +
+	"\x05asset\x18\x03 \x01(\tR\x05asset\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12\x15\n" +
+	"\x06pay_to\x18\x05 \x01(\tR\x05payTo\x12.\n" +
+	"\x13max_timeout_seconds\x18\x06 \x01(\x03R\x11maxTimeoutSeconds\x12-\n" +
+	"\x05extra\x18\a \x01(\v2\x17.google.protobuf.StructR\x05extra\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\x03R\texpiresAt\"\xee\x01\n" +
+	"\x0fPaymentRequired\x12!\n" +
+	"\fx402_version\x18\x01 \x01(\x05R\vx402Version\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x121\n" +
+	"\bresource\x18\x03 \x01(\v2\x15.x402.v2.ResourceInfoR\bresource\x126\n" +
+	"\aaccepts\x18\x04 \x03(\v2\x1c.x402.v2.PaymentRequirementsR\aaccepts\x127\n" +
+	"\n" +
+	"extensions\x18\x05 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"extensions\"\xb9\x02\n" +
+	"\x0ePaymentPayload\x12!\n" +
+	"\fx402_version\x18\x01 \x01(\x05R\vx402Version\x121\n" +
+	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\x128\n" +
+	"\baccepted\x18\x03 \x01(\v2\x1c.x402.v2.PaymentRequirementsR\baccepted\x121\n" +
+	"\bresource\x18\x04 \x01(\v2\x15.x402.v2.ResourceInfoR\bresource\x127\n" +
+	"\n" +
+	"extensions\x18\x05 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"extensions\x12+\n" +
+	"\x11requirements_hash\x18\x06 \x01(\tR\x10requirementsHash\"\xc5\x01\n" +
+	"\rVerifyRequest\x12!\n" +
+	"\fx402_version\x18\x01 \x01(\x05R\vx402Version\x12@\n" +
+	"\x0fpayment_payload\x18\x02 \x01(\v2\x17.x402.v2.PaymentPayloadR\x0epaymentPayload\x12O\n" +
+	"\x14payment_requirements\x18\x03 \x01(\v2\x1c.x402.v2.PaymentRequirementsR\x13paymentRequirements\"\x91\x01\n" +
+	"\x0eVerifyResponse\x12\x19\n" +
+	"\bis_valid\x18\x01 \x01(\bR\aisValid\x12%\n" +
+	"\x0einvalid_reason\x18\x02 \x01(\tR\rinvalidReason\x12'\n" +
+	"\x0finvalid_message\x18\x03 \x01(\tR\x0einvalidMessage\x12\x14\n" +
+	"\x05payer\x18\x04 \x01(\tR\x05payer\"\xc5\x01\n" +
+	"\rSettleRequest\x12!\n" +
+	"\fx402_version\x18\x01 \x01(\x05R\vx402Version\x12@\n" +
+	"\x0fpayment_payload\x18\x02 \x01(\v2\x17.x402.v2.PaymentPayloadR\x0epaymentPayload\x12O\n" +
+	"\x14payment_requirements\x18\x03 \x01(\v2\x1c.x402.v2.PaymentRequirementsR\x13paymentRequirements\"\xc4\x01\n" +
+	"\x0eSettleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
+	"\ferror_reason\x18\x02 \x01(\tR\verrorReason\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12\x14\n" +
+	"\x05payer\x18\x04 \x01(\tR\x05payer\x12 \n" +
+	"\vtransaction\x18\x05 \x01(\tR\vtransaction\x12\x18\n" +
+	"\anetwork\x18\x06 \x01(\tR\anetwork2\x83\x01\n" +
+	"\vFacilitator\x129\n" +
+	"\x06Verify\x12\x16.x402.v2.VerifyRequest\x1a\x17.x402.v2.VerifyResponse\x129\n" +
+	"\x06Settle\x12\x16.x402.v2.SettleRequest\x1a\x17.x402.v2.SettleResponseB*Z(github.com/coinbase/x402/go/proto/x402pbb\x06proto3"
+
+var (
+	file_x402_v2_x402_proto_rawDescOnce sync.Once
+	file_x402_v2_x402_proto_rawDescData []byte
+)
+
+func file_x402_v2_x402_proto_rawDescGZIP() []byte {
+	file_x402_v2_x402_proto_rawDescOnce.Do(func() {
+		file_x402_v2_x402_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_x402_v2_x402_proto_rawDesc), len(file_x402_v2_x402_proto_rawDesc)))
+	})
+	return file_x402_v2_x402_proto_rawDescData
+}
+
+var file_x402_v2_x402_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_x402_v2_x402_proto_goTypes = []any{
+	(*ResourceInfo)(nil),        // 0: x402.v2.ResourceInfo
+	(*PaymentRequirements)(nil), // 1: x402.v2.PaymentRequirements
+	(*PaymentRequired)(nil),     // 2: x402.v2.PaymentRequired
+	(*PaymentPayload)(nil),      // 3: x402.v2.PaymentPayload
+	(*VerifyRequest)(nil),       // 4: x402.v2.VerifyRequest
+	(*VerifyResponse)(nil),      // 5: x402.v2.VerifyResponse
+	(*SettleRequest)(nil),       // 6: x402.v2.SettleRequest
+	(*SettleResponse)(nil),      // 7: x402.v2.SettleResponse
+	(*structpb.Struct)(nil),     // 8: google.protobuf.Struct
+	(*structpb.Value)(nil),      // 9: google.protobuf.Value
+}
+var file_x402_v2_x402_proto_depIdxs = []int32{
+	8,  // 0: x402.v2.ResourceInfo.output_schema:type_name -> google.protobuf.Struct
+	9,  // 1: x402.v2.ResourceInfo.output_example:type_name -> google.protobuf.Value
+	8,  // 2: x402.v2.PaymentRequirements.extra:type_name -> google.protobuf.Struct
+	0,  // 3: x402.v2.PaymentRequired.resource:type_name -> x402.v2.ResourceInfo
+	1,  // 4: x402.v2.PaymentRequired.accepts:type_name -> x402.v2.PaymentRequirements
+	8,  // 5: x402.v2.PaymentRequired.extensions:type_name -> google.protobuf.Struct
+	8,  // 6: x402.v2.PaymentPayload.payload:type_name -> google.protobuf.Struct
+	1,  // 7: x402.v2.PaymentPayload.accepted:type_name -> x402.v2.PaymentRequirements
+	0,  // 8: x402.v2.PaymentPayload.resource:type_name -> x402.v2.ResourceInfo
+	8,  // 9: x402.v2.PaymentPayload.extensions:type_name -> google.protobuf.Struct
+	3,  // 10: x402.v2.VerifyRequest.payment_payload:type_name -> x402.v2.PaymentPayload
+	1,  // 11: x402.v2.VerifyRequest.payment_requirements:type_name -> x402.v2.PaymentRequirements
+	3,  // 12: x402.v2.SettleRequest.payment_payload:type_name -> x402.v2.PaymentPayload
+	1,  // 13: x402.v2.SettleRequest.payment_requirements:type_name -> x402.v2.PaymentRequirements
+	4,  // 14: x402.v2.Facilitator.Verify:input_type -> x402.v2.VerifyRequest
+	6,  // 15: x402.v2.Facilitator.Settle:input_type -> x402.v2.SettleRequest
+	5,  // 16: x402.v2.Facilitator.Verify:output_type -> x402.v2.VerifyResponse
+	7,  // 17: x402.v2.Facilitator.Settle:output_type -> x402.v2.SettleResponse
+	16, // [16:18] is the sub-list for method output_type
+	14, // [14:16] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_x402_v2_x402_proto_init() }
+func file_x402_v2_x402_proto_init() {
+	if File_x402_v2_x402_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_x402_v2_x402_proto_rawDesc), len(file_x402_v2_x402_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_x402_v2_x402_proto_goTypes,
+		DependencyIndexes: file_x402_v2_x402_proto_depIdxs,
+		MessageInfos:      file_x402_v2_x402_proto_msgTypes,
+	}.Build()
+	File_x402_v2_x402_proto = out.File
+	file_x402_v2_x402_proto_goTypes = nil
+	file_x402_v2_x402_proto_depIdxs = nil
+}
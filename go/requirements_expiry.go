@@ -0,0 +1,117 @@
+package x402
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Requirements Expiry
+// ============================================================================
+
+// WithRequirementsExpiry stamps issued payment requirements with expiresAt,
+// maxTimeoutSeconds after they were built, and rejects payments against
+// expired requirements. It is off by default, since clients that do not know
+// the field drop it when echoing the accepted requirements.
+func WithRequirementsExpiry() ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.requirementsExpiry = true
+	}
+}
+
+// WithRequirementsExpiryKey sets the key authenticating the expiry of issued
+// payment requirements and what they are bound to (see RequirementsProof).
+// Servers generate a random key by default; set a shared key when several
+// instances serve the same routes, so a challenge issued by one can be paid
+// at another.
+func WithRequirementsExpiryKey(key []byte) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.expiryKey = append([]byte(nil), key...)
+	}
+}
+
+// newExpiryKey returns a random expiry key
+func newExpiryKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("x402: failed to generate requirements expiry key: %v", err))
+	}
+	return key
+}
+
+// expiryProof authenticates requirements' ExpiresAt together with the
+// payment terms it was issued for, so it cannot be moved to other terms
+func (s *x402ResourceServer) expiryProof(requirements types.PaymentRequirements) string {
+	return s.RequirementsProof(requirements, "")
+}
+
+// RequirementsProof authenticates the payment terms and ExpiresAt of issued
+// requirements together with binding, a value the server bound them to such
+// as a request body hash. Only servers holding the expiry key can produce it,
+// so a proof checked against the server's own binding shows the requirements
+// were issued for it.
+func (s *x402ResourceServer) RequirementsProof(requirements types.PaymentRequirements, binding string) string {
+	mac := hmac.New(sha256.New, s.expiryKey)
+	mac.Write([]byte(strings.Join([]string{
+		requirements.Scheme,
+		requirements.Network,
+		requirements.Asset,
+		requirements.Amount,
+		requirements.PayTo,
+		strconv.FormatInt(requirements.ExpiresAt, 10),
+		binding,
+	}, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// withExpiryProof returns requirements with the proof of their ExpiresAt in
+// extra (see types.ExpiryProofExtraKey)
+func (s *x402ResourceServer) withExpiryProof(requirements types.PaymentRequirements) types.PaymentRequirements {
+	if requirements.ExpiresAt == 0 {
+		return requirements
+	}
+	extra := make(map[string]interface{}, len(requirements.Extra)+1)
+	for key, value := range requirements.Extra {
+		extra[key] = value
+	}
+	extra[types.ExpiryProofExtraKey] = s.expiryProof(requirements)
+	requirements.Extra = extra
+	return requirements
+}
+
+// checkRequirementsExpiry rejects a payment against expired requirements.
+// When the server issued the offered requirements with an expiry, the
+// accepted requirements must carry one too; when it also proved the expiry,
+// the accepted expiry must carry this server's proof, so it was neither
+// dropped nor extended by the client.
+func (s *x402ResourceServer) checkRequirementsExpiry(accepted types.PaymentRequirements, offered types.PaymentRequirements) error {
+	if err := checkExpiryPresent(accepted, offered); err != nil {
+		return err
+	}
+	if _, proved := offered.Extra[types.ExpiryProofExtraKey]; proved {
+		proof, _ := types.ExtraValue[string](accepted.Extra, types.ExpiryProofExtraKey)
+		if !hmac.Equal([]byte(proof), []byte(s.expiryProof(accepted))) {
+			return NewVerifyError(ErrRequirementsExpired, "", "payment requirements expiry was not issued by this server")
+		}
+	}
+	if accepted.Expired(s.now()) {
+		return NewVerifyError(ErrRequirementsExpired, "", fmt.Sprintf("payment requirements expired at %d", accepted.ExpiresAt))
+	}
+	return nil
+}
+
+// checkExpiryPresent rejects accepted requirements that dropped the expiry of
+// the offered requirements
+func checkExpiryPresent(accepted types.PaymentRequirements, offered types.PaymentRequirements) error {
+	if offered.ExpiresAt != 0 && accepted.ExpiresAt == 0 {
+		return NewVerifyError(ErrRequirementsExpired, "", "payment requirements expiry is missing")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package revenue records the facilitator fee portion of settlements made
+// with fee-on-top (see x402.WithFacilitatorFee) and reports the amounts owed
+// to each facilitator for reconciliation.
+//
+//	ledger := revenue.NewLedger()
+//	server.OnAfterSettle(ledger.AfterSettle)
+//	...
+//	_ = ledger.WriteCSV(os.Stdout)
+package revenue
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// Payout is the facilitator fee portion of one settlement
+type Payout struct {
+	Facilitator string    `json:"facilitator"`
+	Network     string    `json:"network"`
+	Asset       string    `json:"asset"`
+	PayTo       string    `json:"payTo"`
+	Payer       string    `json:"payer,omitempty"`
+	Transaction string    `json:"transaction"`
+	Gross       string    `json:"gross"` // Total paid in atomic units
+	Fee         string    `json:"fee"`   // Facilitator fee in atomic units
+	SettledAt   time.Time `json:"settledAt"`
+}
+
+// Owed is the total fee owed to a facilitator for one asset on one network
+type Owed struct {
+	Facilitator string `json:"facilitator"`
+	Network     string `json:"network"`
+	Asset       string `json:"asset"`
+	Amount      string `json:"amount"` // Atomic units
+	Settlements int    `json:"settlements"`
+}
+
+// Ledger records facilitator payouts. It is safe for concurrent use.
+type Ledger struct {
+	mu      sync.Mutex
+	payouts []Payout
+	now     func() time.Time
+}
+
+// NewLedger creates an empty ledger
+func NewLedger() *Ledger {
+	return &Ledger{now: time.Now}
+}
+
+// AfterSettle is an x402.AfterSettleHook recording the facilitator fee of
+// successful settlements. Settlements without a fee are ignored.
+func (l *Ledger) AfterSettle(ctx x402.SettleResultContext) error {
+	if ctx.Result == nil || !ctx.Result.Success {
+		return nil
+	}
+	fee, ok := x402.FacilitatorFeeFromRequirements(ctx.Requirements)
+	if !ok {
+		return nil
+	}
+
+	network := string(ctx.Result.Network)
+	if network == "" {
+		network = ctx.Requirements.GetNetwork()
+	}
+	l.Record(Payout{
+		Facilitator: fee.Facilitator,
+		Network:     network,
+		Asset:       ctx.Requirements.GetAsset(),
+		PayTo:       ctx.Requirements.GetPayTo(),
+		Payer:       ctx.Result.Payer,
+		Transaction: ctx.Result.Transaction,
+		Gross:       ctx.Requirements.GetAmount(),
+		Fee:         fee.Amount,
+	})
+	return nil
+}
+
+// Record appends a payout, stamping SettledAt if unset
+func (l *Ledger) Record(payout Payout) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if payout.SettledAt.IsZero() {
+		payout.SettledAt = l.now()
+	}
+	l.payouts = append(l.payouts, payout)
+}
+
+// Payouts returns recorded payouts, oldest first
+func (l *Ledger) Payouts() []Payout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Payout(nil), l.payouts...)
+}
+
+// Owed totals fees per facilitator, network, and asset, sorted in that order
+func (l *Ledger) Owed() []Owed {
+	type key struct{ facilitator, network, asset string }
+	totals := make(map[key]*big.Int)
+	counts := make(map[key]int)
+
+	for _, payout := range l.Payouts() {
+		fee, ok := new(big.Int).SetString(payout.Fee, 10)
+		if !ok {
+			continue
+		}
+		k := key{payout.Facilitator, payout.Network, payout.Asset}
+		if totals[k] == nil {
+			totals[k] = new(big.Int)
+		}
+		totals[k].Add(totals[k], fee)
+		counts[k]++
+	}
+
+	owed := make([]Owed, 0, len(totals))
+	for k, total := range totals {
+		owed = append(owed, Owed{
+			Facilitator: k.facilitator,
+			Network:     k.network,
+			Asset:       k.asset,
+			Amount:      total.String(),
+			Settlements: counts[k],
+		})
+	}
+	sort.Slice(owed, func(i, j int) bool {
+		if owed[i].Facilitator != owed[j].Facilitator {
+			return owed[i].Facilitator < owed[j].Facilitator
+		}
+		if owed[i].Network != owed[j].Network {
+			return owed[i].Network < owed[j].Network
+		}
+		return owed[i].Asset < owed[j].Asset
+	})
+	return owed
+}
+
+// WriteCSV exports the amounts owed as CSV with a header row
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"facilitator", "network", "asset", "amount", "settlements"}); err != nil {
+		return err
+	}
+	for _, owed := range l.Owed() {
+		row := []string{owed.Facilitator, owed.Network, owed.Asset, owed.Amount, strconv.Itoa(owed.Settlements)}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,83 @@
+package revenue
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func settleContext(requirements types.PaymentRequirements, result *x402.SettleResponse) x402.SettleResultContext {
+	return x402.SettleResultContext{
+		SettleContext: x402.SettleContext{
+			Ctx:          context.Background(),
+			Requirements: requirements,
+		},
+		Result: result,
+	}
+}
+
+func feeRequirements(facilitator, amount, fee string) types.PaymentRequirements {
+	return types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Asset:   "0xusdc",
+		Amount:  amount,
+		PayTo:   "0xmerchant",
+		Extra: map[string]interface{}{
+			x402.FacilitatorFeeExtraKey: map[string]interface{}{
+				"facilitator": facilitator,
+				"amount":      fee,
+				"price":       "1000000",
+			},
+		},
+	}
+}
+
+func TestLedgerRecordsFeePortion(t *testing.T) {
+	ledger := NewLedger()
+	result := &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:8453", Payer: "0xpayer"}
+
+	_ = ledger.AfterSettle(settleContext(feeRequirements("a", "1005000", "5000"), result))
+	_ = ledger.AfterSettle(settleContext(feeRequirements("a", "1005000", "5000"), result))
+	_ = ledger.AfterSettle(settleContext(feeRequirements("b", "1010000", "10000"), result))
+
+	// No fee and failed settlements are not recorded
+	_ = ledger.AfterSettle(settleContext(types.PaymentRequirements{Amount: "1000000"}, result))
+	_ = ledger.AfterSettle(settleContext(feeRequirements("a", "1005000", "5000"), &x402.SettleResponse{Success: false}))
+
+	payouts := ledger.Payouts()
+	if len(payouts) != 3 {
+		t.Fatalf("expected 3 payouts, got %d", len(payouts))
+	}
+	if payouts[0].Gross != "1005000" || payouts[0].Fee != "5000" || payouts[0].Transaction != "0xtx" || payouts[0].SettledAt.IsZero() {
+		t.Errorf("unexpected payout %+v", payouts[0])
+	}
+
+	owed := ledger.Owed()
+	if len(owed) != 2 {
+		t.Fatalf("expected 2 owed entries, got %+v", owed)
+	}
+	if owed[0].Facilitator != "a" || owed[0].Amount != "10000" || owed[0].Settlements != 2 {
+		t.Errorf("unexpected owed entry %+v", owed[0])
+	}
+	if owed[1].Facilitator != "b" || owed[1].Amount != "10000" || owed[1].Settlements != 1 {
+		t.Errorf("unexpected owed entry %+v", owed[1])
+	}
+}
+
+func TestLedgerWriteCSV(t *testing.T) {
+	ledger := NewLedger()
+	ledger.Record(Payout{Facilitator: "a", Network: "eip155:8453", Asset: "0xusdc", Fee: "5000"})
+
+	var buf bytes.Buffer
+	if err := ledger.WriteCSV(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "facilitator,network,asset,amount,settlements\na,eip155:8453,0xusdc,5000,1\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -25,6 +25,16 @@ type x402ResourceServer struct {
 	registeredExtensions map[string]types.ResourceServerExtension
 	supportedCache       *SupportedCache
 
+	// Fee-on-top by facilitator identifier
+	facilitatorFees map[string]FacilitatorFee
+	feeQuotes       feeQuoteCache
+
+	// Deferred settlement of small payments, by scheme, network, asset, and payer
+	deferral   *DeferredSettlementConfig
+	accrualMu  sync.Mutex
+	accruals   map[string]*accrual
+	deferredWG sync.WaitGroup
+
 	// Lifecycle hooks
 	beforeVerifyHooks    []BeforeVerifyHook
 	afterVerifyHooks     []AfterVerifyHook
@@ -32,6 +42,13 @@ type x402ResourceServer struct {
 	beforeSettleHooks    []BeforeSettleHook
 	afterSettleHooks     []AfterSettleHook
 	onSettleFailureHooks []OnSettleFailureHook
+
+	now func() time.Time
+
+	// requirementsExpiry stamps issued requirements with ExpiresAt (see WithRequirementsExpiry)
+	requirementsExpiry bool
+	// expiryKey authenticates the ExpiresAt of issued requirements
+	expiryKey []byte
 }
 
 // SupportedCache caches facilitator capabilities
@@ -106,6 +123,8 @@ func Newx402ResourceServer(opts ...ResourceServerOption) *x402ResourceServer {
 			expiry: make(map[string]time.Time),
 			ttl:    5 * time.Minute,
 		},
+		now:       time.Now,
+		expiryKey: newExpiryKey(),
 	}
 
 	for _, opt := range opts {
@@ -127,26 +146,37 @@ func (s *x402ResourceServer) Initialize(ctx context.Context) error {
 			return fmt.Errorf("failed to get supported from facilitator: %w", err)
 		}
 
-		// Populate facilitatorClients map from kinds (now flat array with version in each element)
-		for _, kind := range supported.Kinds {
-			network := Network(kind.Network)
-			scheme := kind.Scheme
+		s.applySupportedLocked(client, supported)
+	}
 
-			if s.facilitatorClients[network] == nil {
-				s.facilitatorClients[network] = make(map[string]FacilitatorClient)
-			}
+	return nil
+}
 
-			// Only set if not already present (precedence to earlier clients)
-			if s.facilitatorClients[network][scheme] == nil {
-				s.facilitatorClients[network][scheme] = client
-			}
+// applySupportedLocked routes the client's kinds to it and caches its supported
+// response. Callers must hold s.mu.
+func (s *x402ResourceServer) applySupportedLocked(client FacilitatorClient, supported SupportedResponse) {
+	// Populate facilitatorClients map from kinds (now flat array with version in each element)
+	for _, kind := range supported.Kinds {
+		network := Network(kind.Network)
+		scheme := kind.Scheme
+
+		if s.facilitatorClients[network] == nil {
+			s.facilitatorClients[network] = make(map[string]FacilitatorClient)
 		}
 
-		// Cache the supported response
-		s.supportedCache.Set(fmt.Sprintf("facilitator_%p", client), supported)
+		// Only set if not already present (precedence to earlier clients)
+		if s.facilitatorClients[network][scheme] == nil {
+			s.facilitatorClients[network][scheme] = client
+		}
 	}
 
-	return nil
+	// Cache the supported response
+	s.supportedCache.Set(supportedCacheKey(client), supported)
+}
+
+// supportedCacheKey identifies a facilitator client in the supported cache
+func supportedCacheKey(client FacilitatorClient) string {
+	return fmt.Sprintf("facilitator_%p", client)
 }
 
 // Register registers a payment mechanism (V2, default)
@@ -162,6 +192,13 @@ func (s *x402ResourceServer) Register(network Network, schemeServer SchemeNetwor
 	return s
 }
 
+// SchemeServer returns the scheme server registered for a scheme on a network, or nil
+func (s *x402ResourceServer) SchemeServer(network Network, scheme string) SchemeNetworkServer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.schemes[network][scheme]
+}
+
 func (s *x402ResourceServer) RegisterExtension(extension types.ResourceServerExtension) *x402ResourceServer {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -264,6 +301,9 @@ func (s *x402ResourceServer) BuildPaymentRequirements(
 		MaxTimeoutSeconds: maxTimeout,
 		Extra:             assetAmount.Extra,
 	}
+	if s.requirementsExpiry {
+		requirements.ExpiresAt = s.now().Add(time.Duration(maxTimeout) * time.Second).Unix()
+	}
 
 	// Enhance with scheme-specific details
 	enhanced, err := schemeServer.EnhancePaymentRequirements(ctx, requirements, supportedKind, extensions)
@@ -271,7 +311,7 @@ func (s *x402ResourceServer) BuildPaymentRequirements(
 		return types.PaymentRequirements{}, err
 	}
 
-	return enhanced, nil
+	return s.withExpiryProof(enhanced), nil
 }
 
 // FindMatchingRequirements finds requirements that match a payment payload
@@ -288,8 +328,33 @@ func (s *x402ResourceServer) FindMatchingRequirements(available []types.PaymentR
 	return nil
 }
 
+// FindExactRequirements finds the offered requirements that the payload's
+// accepted requirements are identical to, compared by types.RequirementsHash
+// rather than a few fields. A payload carrying a requirementsHash must commit
+// to its accepted requirements, otherwise nothing matches.
+func (s *x402ResourceServer) FindExactRequirements(available []types.PaymentRequirements, payload types.PaymentPayload) *types.PaymentRequirements {
+	accepted, err := types.RequirementsHash(payload.Accepted)
+	if err != nil {
+		return nil
+	}
+	if payload.RequirementsHash != "" && payload.RequirementsHash != accepted {
+		return nil
+	}
+	for _, req := range available {
+		if hash, err := types.RequirementsHash(req); err == nil && hash == accepted {
+			return &req
+		}
+	}
+	return nil
+}
+
 // VerifyPayment verifies a V2 payment
 func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+	// Reject payments created against a stale challenge
+	if err := s.checkRequirementsExpiry(payload.Accepted, requirements); err != nil {
+		return nil, err
+	}
+
 	// Marshal to bytes early for hooks (escape hatch for extensions)
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -324,9 +389,12 @@ func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.Pa
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
 
-	facilitator := s.facilitatorClients[network][scheme]
+	facilitator, selectErr := s.selectFacilitatorClient(ctx, network, scheme)
 	s.mu.RUnlock()
 
+	if selectErr != nil {
+		return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", selectErr.Error())
+	}
 	if facilitator == nil {
 		return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for %s on %s", scheme, network))
 	}
@@ -355,8 +423,63 @@ func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.Pa
 	return verifyResult, nil
 }
 
+// SimulatePayment asks the payment's facilitator to verify it and simulate
+// settlement, returning the projected settlement cost without settling, e.g.
+// to route payments to the cheapest facilitator. The facilitator client must
+// implement SimulatingFacilitatorClient. Verify hooks are not run.
+func (s *x402ResourceServer) SimulatePayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SimulateResponse, error) {
+	if err := s.checkRequirementsExpiry(payload.Accepted, requirements); err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, NewVerifyError(ErrFailedToMarshalPayload, "", err.Error())
+	}
+	requirementsBytes, err := json.Marshal(requirements)
+	if err != nil {
+		return nil, NewVerifyError(ErrFailedToMarshalRequirements, "", err.Error())
+	}
+
+	s.mu.RLock()
+	scheme := requirements.Scheme
+	network := Network(requirements.Network)
+
+	facilitator, selectErr := s.selectFacilitatorClient(ctx, network, scheme)
+	s.mu.RUnlock()
+
+	if selectErr != nil {
+		return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", selectErr.Error())
+	}
+	if facilitator == nil {
+		return nil, NewVerifyError(ErrNoFacilitatorForNetwork, "", fmt.Sprintf("no facilitator for %s on %s", scheme, network))
+	}
+
+	simulator, ok := facilitator.(SimulatingFacilitatorClient)
+	if !ok {
+		return nil, NewVerifyError(ErrSimulationNotSupported, "", fmt.Sprintf("facilitator for %s on %s does not support simulation", scheme, network))
+	}
+	return simulator.Simulate(ctx, payloadBytes, requirementsBytes)
+}
+
 // SettlePayment settles a V2 payment
 func (s *x402ResourceServer) SettlePayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+	return s.settlePayment(ctx, payload, requirements, "")
+}
+
+// settlePayment settles a V2 payment for amountToSettle, or the full amount
+// when empty
+func (s *x402ResourceServer) settlePayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error) {
+	if amountToSettle == "" && s.deferral != nil && s.deferSettlement(ctx, payload, requirements) {
+		payer, _ := authorizationFields(payload.Payload)
+		return &SettleResponse{Success: true, Deferred: true, Network: Network(requirements.Network), Payer: payer}, nil
+	}
+	return s.settleNow(ctx, payload, requirements, amountToSettle)
+}
+
+// settleNow settles a V2 payment through the facilitator, running the settle
+// hooks
+func (s *x402ResourceServer) settleNow(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, amountToSettle string) (*SettleResponse, error) {
 	// Marshal to bytes early for hooks (escape hatch for extensions)
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -375,6 +498,7 @@ func (s *x402ResourceServer) SettlePayment(ctx context.Context, payload types.Pa
 		Requirements:      requirements,
 		PayloadBytes:      payloadBytes,
 		RequirementsBytes: requirementsBytes,
+		AmountToSettle:    amountToSettle,
 	}
 
 	for _, hook := range s.beforeSettleHooks {
@@ -391,15 +515,28 @@ func (s *x402ResourceServer) SettlePayment(ctx context.Context, payload types.Pa
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
 
-	facilitator := s.facilitatorClients[network][scheme]
+	facilitator, selectErr := s.selectFacilitatorClient(ctx, network, scheme)
 	s.mu.RUnlock()
 
+	if selectErr != nil {
+		return nil, NewSettleError("no_facilitator", "", network, "", selectErr.Error())
+	}
 	if facilitator == nil {
 		return nil, NewSettleError("no_facilitator", "", network, "", fmt.Sprintf("no facilitator for %s on %s", scheme, network))
 	}
 
 	// Use already marshaled bytes for network call
-	settleResult, settleErr := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	var settleResult *SettleResponse
+	var settleErr error
+	if amountToSettle == "" {
+		settleResult, settleErr = facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	} else {
+		partial, ok := facilitator.(PartialSettlingFacilitatorClient)
+		if !ok {
+			return nil, NewSettleError(ErrPartialSettlementNotSupported, "", network, "", fmt.Sprintf("facilitator for %s on %s does not support partial settlement", scheme, network))
+		}
+		settleResult, settleErr = partial.SettlePartial(ctx, payloadBytes, requirementsBytes, amountToSettle)
+	}
 
 	// Handle failure
 	if settleErr != nil {
@@ -466,13 +603,31 @@ func (s *x402ResourceServer) BuildPaymentRequirementsFromConfig(ctx context.Cont
 	var supportedKind types.SupportedKind
 	foundKind := false
 
-	// Check each cached facilitator response for matching supported kind
+	// Check each cached facilitator response for matching supported kind.
+	// Facilitators may advertise wildcard families (e.g. "eip155:*"); an exact
+	// network match is preferred over a wildcard one.
+	// A facilitator named by the context supplies its own kinds (e.g. its fee payer).
+	var wildcardKind *SupportedKind
+	cacheKey := ""
+	if identifier := FacilitatorFromContext(ctx); identifier != "" {
+		client, err := s.namedFacilitatorClient(identifier)
+		if err != nil {
+			return nil, err
+		}
+		cacheKey = supportedCacheKey(client)
+	}
 	s.supportedCache.mu.RLock()
-	for _, cachedResponse := range s.supportedCache.data {
+	for key, cachedResponse := range s.supportedCache.data {
+		if cacheKey != "" && key != cacheKey {
+			continue
+		}
 		// Iterate through flat kinds array (version is in each element)
-		for _, kind := range cachedResponse.Kinds {
+		for i, kind := range cachedResponse.Kinds {
 			// Match on scheme and network (only check V2 kinds)
-			if kind.X402Version == 2 && kind.Scheme == config.Scheme && string(kind.Network) == string(config.Network) {
+			if kind.X402Version != 2 || kind.Scheme != config.Scheme {
+				continue
+			}
+			if string(kind.Network) == string(config.Network) {
 				supportedKind = types.SupportedKind{
 					X402Version: kind.X402Version,
 					Scheme:      kind.Scheme,
@@ -482,6 +637,9 @@ func (s *x402ResourceServer) BuildPaymentRequirementsFromConfig(ctx context.Cont
 				foundKind = true
 				break
 			}
+			if wildcardKind == nil && IsWildcardNetwork(Network(kind.Network)) && MatchesNetwork(Network(kind.Network), config.Network) {
+				wildcardKind = &cachedResponse.Kinds[i]
+			}
 		}
 		if foundKind {
 			break
@@ -489,6 +647,16 @@ func (s *x402ResourceServer) BuildPaymentRequirementsFromConfig(ctx context.Cont
 	}
 	s.supportedCache.mu.RUnlock()
 
+	if !foundKind && wildcardKind != nil {
+		supportedKind = types.SupportedKind{
+			X402Version: wildcardKind.X402Version,
+			Scheme:      wildcardKind.Scheme,
+			Network:     string(config.Network),
+			Extra:       wildcardKind.Extra,
+		}
+		foundKind = true
+	}
+
 	// If no cached kind found, create a basic one (fallback for cases without facilitator)
 	if !foundKind {
 		supportedKind = types.SupportedKind{
@@ -503,7 +671,28 @@ func (s *x402ResourceServer) BuildPaymentRequirementsFromConfig(ctx context.Cont
 		return nil, err
 	}
 
+	if fee, client, ok := s.facilitatorFeeFor(ctx, config.Network, config.Scheme); ok {
+		if err := s.chargeFacilitatorFee(ctx, &requirement, fee, client); err != nil {
+			return nil, err
+		}
+	}
+
 	return []types.PaymentRequirements{requirement}, nil
 }
 
+// facilitatorClientFor returns the facilitator client for a scheme on a network,
+// falling back to a client that advertised a wildcard family (e.g. "eip155:*").
+// Callers must hold s.mu.
+func (s *x402ResourceServer) facilitatorClientFor(network Network, scheme string) FacilitatorClient {
+	if client := s.facilitatorClients[network][scheme]; client != nil {
+		return client
+	}
+	for pattern, clients := range s.facilitatorClients {
+		if IsWildcardNetwork(pattern) && MatchesNetwork(pattern, network) && clients[scheme] != nil {
+			return clients[scheme]
+		}
+	}
+	return nil
+}
+
 // Helper functions use the generic findSchemesByNetwork from utils.go
@@ -40,6 +40,7 @@ type SettleContext struct {
 	Requirements      PaymentRequirementsView
 	PayloadBytes      []byte // Raw bytes for extensions needing full data
 	RequirementsBytes []byte // Raw bytes for extensions needing full data
+	AmountToSettle    string // Amount settled when less than the requirements' amount (empty = full)
 }
 
 // SettleResultContext contains settle operation result and context
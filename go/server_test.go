@@ -594,3 +594,236 @@ func TestSupportedCache(t *testing.T) {
 	}
 }
 */
+
+func TestServerUsesWildcardFacilitator(t *testing.T) {
+	ctx := context.Background()
+	mockClient := &mockFacilitatorClient{
+		kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:*"}},
+	}
+	server := Newx402ResourceServer(WithFacilitatorClient(mockClient))
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:8453", Asset: "USDC", Amount: "1", PayTo: "0xrecipient"}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+
+	if _, err := server.VerifyPayment(ctx, payload, requirements); err != nil {
+		t.Fatalf("Expected wildcard facilitator to verify, got %v", err)
+	}
+
+	requirements.Scheme = "upto"
+	payload.Accepted = requirements
+	if _, err := server.VerifyPayment(ctx, payload, requirements); err == nil {
+		t.Error("Expected error for a scheme the wildcard facilitator does not support")
+	}
+}
+
+func TestServerRequirementsExpiry(t *testing.T) {
+	ctx := context.Background()
+	issuedAt := time.Unix(1700000000, 0)
+
+	verifyCalled := false
+	mockClient := &mockFacilitatorClient{
+		kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*VerifyResponse, error) {
+			verifyCalled = true
+			return &VerifyResponse{IsValid: true}, nil
+		},
+	}
+	newServer := func(opts ...ResourceServerOption) *x402ResourceServer {
+		opts = append(opts,
+			WithFacilitatorClient(mockClient),
+			WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+		)
+		server := Newx402ResourceServer(opts...)
+		server.now = func() time.Time { return issuedAt }
+		if err := server.Initialize(ctx); err != nil {
+			t.Fatalf("Failed to initialize server: %v", err)
+		}
+		return server
+	}
+	build := func(server *x402ResourceServer) types.PaymentRequirements {
+		requirements, err := server.BuildPaymentRequirements(ctx, ResourceConfig{
+			Scheme:            "exact",
+			PayTo:             "0xrecipient",
+			Price:             "$1.00",
+			Network:           "eip155:1",
+			MaxTimeoutSeconds: 600,
+		}, types.SupportedKind{Scheme: "exact", Network: "eip155:1"}, []string{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return requirements
+	}
+
+	// Expiry is opt-in, so clients that drop expiresAt keep working by default
+	unexpiring := newServer()
+	offered := build(unexpiring)
+	if offered.ExpiresAt != 0 {
+		t.Fatalf("Expected no expiresAt by default, got %d", offered.ExpiresAt)
+	}
+	unexpiring.now = func() time.Time { return issuedAt.Add(24 * time.Hour) }
+	if _, err := unexpiring.VerifyPayment(ctx, types.PaymentPayload{X402Version: 2, Accepted: offered, Payload: map[string]interface{}{}}, offered); err != nil {
+		t.Fatalf("Expected payment without expiry to verify, got %v", err)
+	}
+
+	server := newServer(WithRequirementsExpiry())
+	requirements := build(server)
+	if requirements.ExpiresAt != issuedAt.Unix()+600 {
+		t.Fatalf("Expected expiresAt %d, got %d", issuedAt.Unix()+600, requirements.ExpiresAt)
+	}
+
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+
+	server.now = func() time.Time { return issuedAt.Add(600 * time.Second) }
+	if _, err := server.VerifyPayment(ctx, payload, requirements); err != nil {
+		t.Fatalf("Expected payment at the expiry second to verify, got %v", err)
+	}
+
+	verifyCalled = false
+	server.now = func() time.Time { return issuedAt.Add(601 * time.Second) }
+	_, err := server.VerifyPayment(ctx, payload, requirements)
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsExpired {
+		t.Fatalf("Expected %s, got %v", ErrRequirementsExpired, err)
+	}
+	if verifyCalled {
+		t.Error("Expected expired payment to be rejected before reaching the facilitator")
+	}
+}
+
+func TestServerRequirementsExpiryProof(t *testing.T) {
+	ctx := context.Background()
+	issuedAt := time.Unix(1700000000, 0)
+	newServer := func(opts ...ResourceServerOption) *x402ResourceServer {
+		opts = append(opts,
+			WithRequirementsExpiry(),
+			WithFacilitatorClient(&mockFacilitatorClient{kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}}),
+			WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+		)
+		server := Newx402ResourceServer(opts...)
+		server.now = func() time.Time { return issuedAt }
+		if err := server.Initialize(ctx); err != nil {
+			t.Fatalf("Failed to initialize server: %v", err)
+		}
+		return server
+	}
+	build := func(server *x402ResourceServer) types.PaymentRequirements {
+		requirements, err := server.BuildPaymentRequirements(ctx, ResourceConfig{
+			Scheme:            "exact",
+			PayTo:             "0xrecipient",
+			Price:             "$1.00",
+			Network:           "eip155:1",
+			MaxTimeoutSeconds: 600,
+		}, types.SupportedKind{Scheme: "exact", Network: "eip155:1"}, []string{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return requirements
+	}
+
+	server := newServer()
+	issued := build(server)
+	if _, ok := issued.Extra[types.ExpiryProofExtraKey]; !ok {
+		t.Fatalf("Expected an expiry proof in extra, got %v", issued.Extra)
+	}
+
+	// The server regenerates its requirements when the payment arrives
+	server.now = func() time.Time { return issuedAt.Add(300 * time.Second) }
+	offered := build(server)
+
+	withExpiry := func(expiresAt int64, proof interface{}) types.PaymentRequirements {
+		accepted := issued
+		accepted.ExpiresAt = expiresAt
+		accepted.Extra = map[string]interface{}{}
+		for key, value := range issued.Extra {
+			accepted.Extra[key] = value
+		}
+		if proof == nil {
+			delete(accepted.Extra, types.ExpiryProofExtraKey)
+		} else {
+			accepted.Extra[types.ExpiryProofExtraKey] = proof
+		}
+		return accepted
+	}
+
+	tests := []struct {
+		name     string
+		accepted types.PaymentRequirements
+		valid    bool
+	}{
+		{"issued", issued, true},
+		{"expiry stripped", withExpiry(0, nil), false},
+		{"expiry extended", withExpiry(issued.ExpiresAt+3600, issued.Extra[types.ExpiryProofExtraKey]), false},
+		{"proof stripped", withExpiry(issued.ExpiresAt, nil), false},
+		{"proof forged", withExpiry(issued.ExpiresAt+3600, "00"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := types.PaymentPayload{X402Version: 2, Accepted: tt.accepted, Payload: map[string]interface{}{}}
+			_, err := server.VerifyPayment(ctx, payload, offered)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("Expected issued requirements to verify, got %v", err)
+				}
+				return
+			}
+			var verifyErr *VerifyError
+			if !errors.As(err, &verifyErr) || verifyErr.InvalidReason != ErrRequirementsExpired {
+				t.Errorf("Expected %s, got %v", ErrRequirementsExpired, err)
+			}
+			if _, err := server.SimulatePayment(ctx, payload, offered); err == nil {
+				t.Error("Expected simulation to reject the expiry as well")
+			}
+		})
+	}
+
+	// Another instance only accepts the expiry when it shares the key
+	payload := types.PaymentPayload{X402Version: 2, Accepted: issued, Payload: map[string]interface{}{}}
+	if _, err := newServer().VerifyPayment(ctx, payload, offered); err == nil {
+		t.Error("Expected an instance with another key to reject the expiry")
+	}
+	key := []byte("shared-expiry-key")
+	shared := newServer(WithRequirementsExpiryKey(key))
+	payload.Accepted = build(shared)
+	if _, err := newServer(WithRequirementsExpiryKey(key)).VerifyPayment(ctx, payload, offered); err != nil {
+		t.Errorf("Expected an instance sharing the key to verify, got %v", err)
+	}
+}
+
+func TestServerFindExactRequirements(t *testing.T) {
+	server := Newx402ResourceServer()
+	offered := types.PaymentRequirements{
+		Scheme:            "exact",
+		Network:           "eip155:1",
+		Asset:             "USDC",
+		Amount:            "1000000",
+		PayTo:             "0xrecipient",
+		MaxTimeoutSeconds: 60,
+		Extra:             map[string]interface{}{"name": "USD Coin"},
+		ExpiresAt:         2000000000,
+	}
+	available := []types.PaymentRequirements{offered}
+
+	// A client echoes the requirements it decoded, with a different expiry than the regenerated ones
+	accepted := offered
+	accepted.ExpiresAt = 1999999000
+	accepted.Extra = map[string]interface{}{"name": "USD Coin"}
+	hash, _ := types.RequirementsHash(accepted)
+
+	if matched := server.FindExactRequirements(available, types.PaymentPayload{Accepted: accepted, RequirementsHash: hash}); matched == nil {
+		t.Fatal("Expected identical requirements to match")
+	}
+	if matched := server.FindExactRequirements(available, types.PaymentPayload{Accepted: accepted, RequirementsHash: "sha256:00"}); matched != nil {
+		t.Error("Expected a wrong commitment not to match")
+	}
+
+	accepted.Extra = map[string]interface{}{"name": "Fake Coin"}
+	if matched := server.FindExactRequirements(available, types.PaymentPayload{Accepted: accepted}); matched != nil {
+		t.Error("Expected changed extra not to match")
+	}
+	if matched := server.FindMatchingRequirements(available, types.PaymentPayload{Accepted: accepted}); matched == nil {
+		t.Error("Expected field matching to ignore extra")
+	}
+}
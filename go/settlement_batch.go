@@ -0,0 +1,169 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Settlement Batching
+// ============================================================================
+
+// SchemeNetworkBatchSettler is optionally implemented by SchemeNetworkFacilitator
+// mechanisms that can settle several payments in one transaction (e.g. through
+// a multicall contract), sharing its fee across them
+type SchemeNetworkBatchSettler interface {
+	// BatchKey groups payments that may share a transaction (e.g. same payer
+	// and asset) and returns the latest time the payment can be settled.
+	// ok is false for payments that must be settled alone.
+	BatchKey(payload types.PaymentPayload, requirements types.PaymentRequirements) (key string, settleBy time.Time, ok bool)
+
+	// SettleBatch verifies and settles the payments in one transaction,
+	// returning one result per payment, in order
+	SettleBatch(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) []BatchSettlement
+}
+
+// BatchSettlement is the outcome of one payment in a batch
+type BatchSettlement struct {
+	Response *SettleResponse
+	Err      error
+}
+
+// SettlementBatchConfig controls how payments are aggregated
+type SettlementBatchConfig struct {
+	// MaxSize settles a batch as soon as it holds this many payments (default 20)
+	MaxSize int
+
+	// MaxWait is how long the first payment in a batch waits for others (default 2s)
+	MaxWait time.Duration
+
+	// Margin settles a batch this long before its earliest payment expires
+	// (default 30s). Payments closer to expiry settle alone.
+	Margin time.Duration
+}
+
+// Default settlement batching limits
+const (
+	defaultBatchMaxSize = 20
+	defaultBatchMaxWait = 2 * time.Second
+	defaultBatchMargin  = 30 * time.Second
+)
+
+// settlementBatch is a group of payments waiting to be settled together
+type settlementBatch struct {
+	facilitator SchemeNetworkFacilitator
+	settler     SchemeNetworkBatchSettler
+	entries     []batchEntry
+	flushAt     time.Time
+	timer       *time.Timer
+}
+
+type batchEntry struct {
+	payload      types.PaymentPayload
+	requirements types.PaymentRequirements
+	done         chan BatchSettlement
+}
+
+// EnableSettlementBatching aggregates V2 payments into shared settlement
+// transactions for mechanisms implementing SchemeNetworkBatchSettler. Settle
+// holds each payment until its batch is full, MaxWait has passed, or its
+// earliest payment is about to expire, so micro-payments split one
+// transaction fee instead of paying one each.
+func (f *x402Facilitator) EnableSettlementBatching(config SettlementBatchConfig) *x402Facilitator {
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaultBatchMaxSize
+	}
+	if config.MaxWait <= 0 {
+		config.MaxWait = defaultBatchMaxWait
+	}
+	if config.Margin <= 0 {
+		config.Margin = defaultBatchMargin
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batchConfig = &config
+	return f
+}
+
+// settleBatched adds a payment to its batch and waits for the batch to settle
+func (f *x402Facilitator) settleBatched(ctx context.Context, config SettlementBatchConfig, facilitator SchemeNetworkFacilitator, settler SchemeNetworkBatchSettler, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+	key, settleBy, ok := settler.BatchKey(payload, requirements)
+	flushBy := settleBy.Add(-config.Margin)
+	if !ok || !time.Now().Before(flushBy) {
+		return facilitator.Settle(ctx, payload, requirements)
+	}
+	key = fmt.Sprintf("%s:%s:%s", requirements.Scheme, requirements.Network, key)
+	done := make(chan BatchSettlement, 1)
+
+	f.batchMu.Lock()
+	if f.batches == nil {
+		f.batches = make(map[string]*settlementBatch)
+	}
+	batch := f.batches[key]
+	if batch == nil {
+		batch = &settlementBatch{facilitator: facilitator, settler: settler, flushAt: time.Now().Add(config.MaxWait)}
+		if flushBy.Before(batch.flushAt) {
+			batch.flushAt = flushBy
+		}
+		batch.timer = time.AfterFunc(time.Until(batch.flushAt), func() { f.flushBatch(key, batch) })
+		f.batches[key] = batch
+	} else if flushBy.Before(batch.flushAt) {
+		batch.flushAt = flushBy
+		batch.timer.Reset(time.Until(flushBy))
+	}
+	batch.entries = append(batch.entries, batchEntry{payload: payload, requirements: requirements, done: done})
+	full := len(batch.entries) >= config.MaxSize
+	f.batchMu.Unlock()
+
+	if full {
+		go f.flushBatch(key, batch)
+	}
+
+	// The batch still settles if ctx ends first
+	select {
+	case result := <-done:
+		return result.Response, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flushBatch settles a batch, unless it was already flushed
+func (f *x402Facilitator) flushBatch(key string, batch *settlementBatch) {
+	f.batchMu.Lock()
+	if f.batches[key] != batch {
+		f.batchMu.Unlock()
+		return
+	}
+	delete(f.batches, key)
+	batch.timer.Stop()
+	entries := batch.entries
+	f.batchMu.Unlock()
+
+	ctx := context.Background()
+	if len(entries) == 1 {
+		response, err := batch.facilitator.Settle(ctx, entries[0].payload, entries[0].requirements)
+		entries[0].done <- BatchSettlement{Response: response, Err: err}
+		return
+	}
+
+	payloads := make([]types.PaymentPayload, len(entries))
+	requirements := make([]types.PaymentRequirements, len(entries))
+	for i, entry := range entries {
+		payloads[i] = entry.payload
+		requirements[i] = entry.requirements
+	}
+	results := batch.settler.SettleBatch(ctx, payloads, requirements)
+	for i, entry := range entries {
+		if i >= len(results) {
+			network := Network(entry.requirements.Network)
+			entry.done <- BatchSettlement{Err: NewSettleError(ErrInvalidResponse, "", network, "", "batch settlement returned too few results")}
+			continue
+		}
+		entry.done <- results[i]
+	}
+}
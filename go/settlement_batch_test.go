@@ -0,0 +1,101 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+type mockBatchSettler struct {
+	mockSchemeNetworkFacilitator
+	settleBy time.Time
+	mu       sync.Mutex
+	batches  [][]types.PaymentPayload
+}
+
+func (m *mockBatchSettler) BatchKey(payload types.PaymentPayload, requirements types.PaymentRequirements) (string, time.Time, bool) {
+	return "payer", m.settleBy, true
+}
+
+func (m *mockBatchSettler) SettleBatch(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) []BatchSettlement {
+	m.mu.Lock()
+	m.batches = append(m.batches, payloads)
+	m.mu.Unlock()
+	results := make([]BatchSettlement, len(payloads))
+	for i := range results {
+		results[i].Response = &SettleResponse{Success: true, Transaction: "0xbatch", Network: "eip155:1"}
+	}
+	return results
+}
+
+func batchTestSettlement() ([]byte, []byte) {
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "100", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+func TestSettlementBatchingAggregatesPayments(t *testing.T) {
+	settler := &mockBatchSettler{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}, settleBy: time.Now().Add(time.Hour)}
+	facilitator := Newx402Facilitator().EnableSettlementBatching(SettlementBatchConfig{MaxSize: 3, MaxWait: time.Minute})
+	facilitator.Register([]Network{"eip155:1"}, settler)
+
+	payloadBytes, requirementsBytes := batchTestSettlement()
+	var wg sync.WaitGroup
+	results := make([]*SettleResponse, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = facilitator.Settle(context.Background(), payloadBytes, requirementsBytes)
+		}(i)
+	}
+	wg.Wait()
+
+	if len(settler.batches) != 1 || len(settler.batches[0]) != 3 {
+		t.Fatalf("Expected one batch of 3 payments, got %d batches", len(settler.batches))
+	}
+	for i, response := range results {
+		if response == nil || response.Transaction != "0xbatch" {
+			t.Errorf("Payment %d: expected the batch transaction, got %+v", i, response)
+		}
+	}
+}
+
+func TestSettlementBatchingFlushesAfterMaxWait(t *testing.T) {
+	settler := &mockBatchSettler{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}, settleBy: time.Now().Add(time.Hour)}
+	facilitator := Newx402Facilitator().EnableSettlementBatching(SettlementBatchConfig{MaxSize: 10, MaxWait: 10 * time.Millisecond})
+	facilitator.Register([]Network{"eip155:1"}, settler)
+
+	// A lone payment settles on its own once MaxWait passes
+	payloadBytes, requirementsBytes := batchTestSettlement()
+	response, err := facilitator.Settle(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xmocktx" || len(settler.batches) != 0 {
+		t.Errorf("Expected a regular settlement, got %s", response.Transaction)
+	}
+}
+
+func TestSettlementBatchingSkipsExpiringPayments(t *testing.T) {
+	settler := &mockBatchSettler{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}, settleBy: time.Now().Add(10 * time.Second)}
+	facilitator := Newx402Facilitator().EnableSettlementBatching(SettlementBatchConfig{MaxWait: time.Minute})
+	facilitator.Register([]Network{"eip155:1"}, settler)
+
+	// Within the default 30s margin, so it cannot wait for a batch
+	payloadBytes, requirementsBytes := batchTestSettlement()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xmocktx" {
+		t.Errorf("Expected an immediate settlement, got %s", response.Transaction)
+	}
+}
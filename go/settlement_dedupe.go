@@ -0,0 +1,219 @@
+package x402
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Settlement Deduplication
+// ============================================================================
+
+// defaultSettlementDedupeTTL is how long settlements are remembered by default
+const defaultSettlementDedupeTTL = 24 * time.Hour
+
+// SettlementCache remembers settlement results by authorization. Use a shared
+// implementation when running several facilitator instances. Implementations
+// must be safe for concurrent use.
+type SettlementCache interface {
+	// Get returns the settlement stored at key and whether it exists
+	Get(ctx context.Context, key string) (*SettleResponse, bool, error)
+
+	// Put stores a settlement at key for ttl
+	Put(ctx context.Context, key string, response *SettleResponse, ttl time.Duration) error
+}
+
+// settlementCacheSweepInterval is how many writes pass between sweeps of expired entries
+const settlementCacheSweepInterval = 1024
+
+// MemorySettlementCache is an in-process SettlementCache
+type MemorySettlementCache struct {
+	mu      sync.Mutex
+	entries map[string]settlementCacheEntry
+	writes  int
+	now     func() time.Time
+}
+
+type settlementCacheEntry struct {
+	response  SettleResponse
+	expiresAt time.Time
+}
+
+// NewMemorySettlementCache creates an empty in-process settlement cache
+func NewMemorySettlementCache() *MemorySettlementCache {
+	return &MemorySettlementCache{
+		entries: make(map[string]settlementCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get implements SettlementCache
+func (c *MemorySettlementCache) Get(ctx context.Context, key string) (*SettleResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	response := entry.response
+	return &response, true, nil
+}
+
+// Put implements SettlementCache
+func (c *MemorySettlementCache) Put(ctx context.Context, key string, response *SettleResponse, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = settlementCacheEntry{response: *response, expiresAt: c.now().Add(ttl)}
+	c.afterWriteLocked()
+	return nil
+}
+
+// afterWriteLocked periodically sweeps expired entries so the map stays bounded
+func (c *MemorySettlementCache) afterWriteLocked() {
+	c.writes++
+	if c.writes < settlementCacheSweepInterval {
+		return
+	}
+	c.writes = 0
+
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// EnableSettlementDedupe remembers successful settlements for ttl (default:
+// 24 hours) in cache (default: in-process), so a retried settle request
+// returns the original transaction instead of reverting on-chain. A
+// settlement is only returned for an identical request: the same payload,
+// signature included, requirements and amount. Concurrent requests for the
+// same authorization (network, asset, payer, nonce) wait for the first to
+// finish; a different payment reusing its nonce is settled on its own and
+// fails on-chain. Only authorizations with a payer and nonce (EVM EIP-3009
+// and Permit2) are deduplicated. Hooks do not run for deduplicated requests.
+func (f *x402Facilitator) EnableSettlementDedupe(cache SettlementCache, ttl time.Duration) *x402Facilitator {
+	if cache == nil {
+		cache = NewMemorySettlementCache()
+	}
+	if ttl <= 0 {
+		ttl = defaultSettlementDedupeTTL
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settlementCache = cache
+	f.settlementTTL = ttl
+	f.settling = make(map[string]chan struct{})
+	return f
+}
+
+// settlementKey identifies the authorization in a payload by network, asset,
+// payer, and nonce, or returns "" when it has none
+func settlementKey(network, asset string, payload map[string]interface{}) string {
+	from, nonce := authorizationFields(payload)
+	if from == "" || nonce == "" {
+		return ""
+	}
+	return strings.ToLower(strings.Join([]string{network, asset, from, nonce}, ":"))
+}
+
+// settlementCacheKey extends an authorization's settlementKey with a digest
+// of the full payload, requirements and amount, so a remembered settlement is
+// never returned for a different payment that reuses the nonce. It returns ""
+// when key is empty.
+func settlementCacheKey(key string, payload interface{}, requirements interface{}, amountToSettle string) string {
+	if key == "" {
+		return ""
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	requirementsJSON, err := json.Marshal(requirements)
+	if err != nil {
+		return ""
+	}
+	digest := sha256.New()
+	for _, part := range [][]byte{payloadJSON, requirementsJSON, []byte(amountToSettle)} {
+		digest.Write(part)
+		digest.Write([]byte{0})
+	}
+	return key + ":" + hex.EncodeToString(digest.Sum(nil))
+}
+
+// authorizationFields returns the payer and nonce of the authorization in a
+// payload (EVM EIP-3009 and Permit2), or empty strings when it has none
+func authorizationFields(payload map[string]interface{}) (from string, nonce string) {
+	for _, field := range []string{"authorization", "permit2Authorization"} {
+		authorization, ok := payload[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		from, _ = authorization["from"].(string)
+		nonce, _ = authorization["nonce"].(string)
+		if from != "" && nonce != "" {
+			return from, nonce
+		}
+	}
+	return "", ""
+}
+
+// beginSettlement returns the settlement remembered at cacheKey, or claims
+// the authorization key for a new settlement, waiting while another request
+// settles it. Call the returned function when the new settlement is done.
+func (f *x402Facilitator) beginSettlement(ctx context.Context, key string, cacheKey string) (*SettleResponse, func(), error) {
+	f.mu.RLock()
+	cache := f.settlementCache
+	f.mu.RUnlock()
+	if cache == nil || key == "" || cacheKey == "" {
+		return nil, func() {}, nil
+	}
+
+	for {
+		f.settlingMu.Lock()
+		done, busy := f.settling[key]
+		if !busy {
+			if cached, ok, err := cache.Get(ctx, cacheKey); err == nil && ok {
+				f.settlingMu.Unlock()
+				return cached, nil, nil
+			}
+			done = make(chan struct{})
+			f.settling[key] = done
+			f.settlingMu.Unlock()
+			return nil, func() {
+				f.settlingMu.Lock()
+				delete(f.settling, key)
+				f.settlingMu.Unlock()
+				close(done)
+			}, nil
+		}
+		f.settlingMu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// rememberSettlement stores a successful settlement at cacheKey for deduplication
+func (f *x402Facilitator) rememberSettlement(ctx context.Context, cacheKey string, response *SettleResponse) {
+	f.mu.RLock()
+	cache, ttl := f.settlementCache, f.settlementTTL
+	f.mu.RUnlock()
+	if cache == nil || cacheKey == "" || response == nil || !response.Success {
+		return
+	}
+	_ = cache.Put(ctx, cacheKey, response, ttl)
+}
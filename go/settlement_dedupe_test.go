@@ -0,0 +1,238 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func dedupeTestSettlement(nonce string) ([]byte, []byte) {
+	return dedupeTestPayment(nonce, "1000000", "0xrecipient", "0xsig")
+}
+
+func dedupeTestPayment(nonce string, amount string, payTo string, signature string) ([]byte, []byte) {
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "0xUSDC",
+		Amount:  amount,
+		PayTo:   payTo,
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload: map[string]interface{}{
+			"signature": signature,
+			"authorization": map[string]interface{}{
+				"from":  "0xPayer",
+				"nonce": nonce,
+			},
+		},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+func TestSettlementDedupeReturnsOriginalTransaction(t *testing.T) {
+	ctx := context.Background()
+	var settles int32
+	facilitator := Newx402Facilitator().EnableSettlementDedupe(nil, 0)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			if atomic.AddInt32(&settles, 1) > 1 {
+				return nil, errors.New("authorization already used")
+			}
+			return &SettleResponse{Success: true, Transaction: "0xoriginal", Network: "eip155:1"}, nil
+		},
+	})
+
+	payloadBytes, requirementsBytes := dedupeTestSettlement("0x01")
+	for i := 0; i < 3; i++ {
+		response, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Attempt %d: unexpected error: %v", i, err)
+		}
+		if response.Transaction != "0xoriginal" {
+			t.Errorf("Attempt %d: expected original transaction, got %s", i, response.Transaction)
+		}
+	}
+	if settles != 1 {
+		t.Errorf("Expected one on-chain settlement, got %d", settles)
+	}
+
+	// A different nonce is a different authorization
+	payloadBytes, requirementsBytes = dedupeTestSettlement("0x02")
+	if _, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Error("Expected a new nonce to reach the mechanism")
+	}
+}
+
+func TestSettlementDedupeConcurrentRetries(t *testing.T) {
+	ctx := context.Background()
+	var settles int32
+	release := make(chan struct{})
+	facilitator := Newx402Facilitator().EnableSettlementDedupe(nil, time.Minute)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			atomic.AddInt32(&settles, 1)
+			<-release
+			return &SettleResponse{Success: true, Transaction: "0xoriginal", Network: "eip155:1"}, nil
+		},
+	})
+
+	payloadBytes, requirementsBytes := dedupeTestSettlement("0x01")
+	var wg sync.WaitGroup
+	results := make([]*SettleResponse, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if settles != 1 {
+		t.Errorf("Expected one on-chain settlement, got %d", settles)
+	}
+	for i, response := range results {
+		if response == nil || response.Transaction != "0xoriginal" {
+			t.Errorf("Request %d: expected original transaction, got %+v", i, response)
+		}
+	}
+}
+
+func TestSettlementDedupeRejectsNonceReuse(t *testing.T) {
+	ctx := context.Background()
+	var settles int32
+	release := make(chan struct{})
+	facilitator := Newx402Facilitator().EnableSettlementDedupe(nil, time.Minute)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			if atomic.AddInt32(&settles, 1) > 1 {
+				return nil, errors.New("authorization already used")
+			}
+			<-release
+			return &SettleResponse{Success: true, Transaction: "0xcheap", Network: "eip155:1"}, nil
+		},
+	})
+
+	// Two payments signed with one nonce, sent at once: a cheap one and an
+	// expensive one to another payee
+	cheapPayload, cheapRequirements := dedupeTestPayment("0x01", "1000", "0xrecipient", "0xsig1")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = facilitator.Settle(ctx, cheapPayload, cheapRequirements)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	expensive := make(chan error, 1)
+	go func() {
+		payloadBytes, requirementsBytes := dedupeTestPayment("0x01", "100000000", "0xother", "0xsig2")
+		response, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+		if err == nil && response.Transaction == "0xcheap" {
+			err = errors.New("got the cheap payment's settlement")
+		} else if err == nil {
+			err = errors.New("settled")
+		} else {
+			err = nil
+		}
+		expensive <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if err := <-expensive; err != nil {
+		t.Errorf("Expected the expensive payment to be settled on its own and fail: %v", err)
+	}
+	if settles != 2 {
+		t.Errorf("Expected both payments to reach the mechanism, got %d settlements", settles)
+	}
+
+	// Only the exact payment that settled gets the remembered result
+	if response, err := facilitator.Settle(ctx, cheapPayload, cheapRequirements); err != nil || response.Transaction != "0xcheap" {
+		t.Errorf("Expected the settled payment's retry to be deduplicated, got %+v %v", response, err)
+	}
+	tampered, _ := dedupeTestPayment("0x01", "1000", "0xrecipient", "0xsig3")
+	if _, err := facilitator.Settle(ctx, tampered, cheapRequirements); err == nil {
+		t.Error("Expected a payload with another signature not to be deduplicated")
+	}
+}
+
+func TestSettlementDedupeRetriesFailures(t *testing.T) {
+	ctx := context.Background()
+	var settles int32
+	facilitator := Newx402Facilitator().EnableSettlementDedupe(nil, 0)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			if atomic.AddInt32(&settles, 1) == 1 {
+				return nil, errors.New("rpc unavailable")
+			}
+			return &SettleResponse{Success: true, Transaction: "0xretried", Network: "eip155:1"}, nil
+		},
+	})
+
+	payloadBytes, requirementsBytes := dedupeTestSettlement("0x01")
+	if _, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected the first settlement to fail")
+	}
+	response, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Transaction != "0xretried" {
+		t.Errorf("Expected failed settlements not to be remembered, got %s", response.Transaction)
+	}
+}
+
+func TestMemorySettlementCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemorySettlementCache()
+	cache.now = func() time.Time { return now }
+
+	_ = cache.Put(ctx, "key", &SettleResponse{Success: true, Transaction: "0xtx"}, time.Minute)
+	if _, ok, _ := cache.Get(ctx, "key"); !ok {
+		t.Fatal("Expected cached settlement")
+	}
+	now = now.Add(time.Minute)
+	if _, ok, _ := cache.Get(ctx, "key"); ok {
+		t.Error("Expected settlement to expire")
+	}
+}
+
+func TestMemorySettlementCacheSweepsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemorySettlementCache()
+	cache.now = func() time.Time { return now }
+
+	_ = cache.Put(ctx, "expired", &SettleResponse{Success: true}, time.Minute)
+	now = now.Add(time.Minute)
+	for i := 1; i < settlementCacheSweepInterval; i++ {
+		_ = cache.Put(ctx, fmt.Sprintf("key-%d", i), &SettleResponse{Success: true}, time.Minute)
+	}
+	if _, ok := cache.entries["expired"]; ok {
+		t.Error("Expected expired entries to be swept")
+	}
+	if len(cache.entries) != settlementCacheSweepInterval-1 {
+		t.Errorf("Expected %d live entries, got %d", settlementCacheSweepInterval-1, len(cache.entries))
+	}
+}
@@ -0,0 +1,240 @@
+package x402
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Deferred Settlement
+// ============================================================================
+
+// DeferredSettlementConfig defers settlement of payments too small to be
+// worth a transaction of their own
+type DeferredSettlementConfig struct {
+	// Threshold is the break-even amount, in atomic units of the asset, at
+	// which settling is worth its gas cost. Payments below it are deferred,
+	// and a payer's deferred payments settle once they add up to it.
+	Threshold string
+
+	// AssetThresholds overrides Threshold per asset address (case-insensitive),
+	// for assets with different decimals or value
+	AssetThresholds map[string]string
+
+	// MaxAge settles a payer's deferred payments once the oldest has waited
+	// this long (default 10 minutes)
+	MaxAge time.Duration
+
+	// Margin settles deferred payments this long before the earliest one's
+	// authorization, assumed valid for the requirements' MaxTimeoutSeconds,
+	// expires (default 30s)
+	Margin time.Duration
+}
+
+// Default deferred settlement limits
+const (
+	defaultDeferralMaxAge = 10 * time.Minute
+	defaultDeferralMargin = 30 * time.Second
+)
+
+// AccruedLiability is what one payer owes in deferred payments of one asset
+type AccruedLiability struct {
+	Scheme   string    `json:"scheme"`
+	Network  string    `json:"network"`
+	Asset    string    `json:"asset"`
+	Payer    string    `json:"payer"`
+	Amount   string    `json:"amount"` // Total of the deferred payments, in atomic units
+	Payments int       `json:"payments"`
+	Since    time.Time `json:"since"`    // When the oldest payment was deferred
+	SettleBy time.Time `json:"settleBy"` // When the payments will be settled at the latest
+}
+
+// accrual is a payer's deferred payments in one asset
+type accrual struct {
+	liability AccruedLiability
+	total     *big.Int
+	payments  []deferredPayment
+	timer     *time.Timer
+}
+
+type deferredPayment struct {
+	ctx          context.Context
+	payload      types.PaymentPayload
+	requirements types.PaymentRequirements
+}
+
+// WithDeferredSettlement defers settlement of payments below the break-even
+// threshold. SettlePayment returns a Deferred response for them and settles
+// a payer's deferred payments together, in the background, once they reach
+// the threshold or MaxAge. Settle hooks run when a payment is settled.
+// Payments without a payer in the payload (EVM authorizations have one)
+// settle immediately.
+func WithDeferredSettlement(config DeferredSettlementConfig) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		if config.MaxAge <= 0 {
+			config.MaxAge = defaultDeferralMaxAge
+		}
+		if config.Margin <= 0 {
+			config.Margin = defaultDeferralMargin
+		}
+		s.deferral = &config
+	}
+}
+
+// AccruedLiabilities returns the deferred payments not yet settled, by payer
+// and asset, soonest to settle first
+func (s *x402ResourceServer) AccruedLiabilities() []AccruedLiability {
+	s.accrualMu.Lock()
+	defer s.accrualMu.Unlock()
+
+	liabilities := make([]AccruedLiability, 0, len(s.accruals))
+	for _, a := range s.accruals {
+		liability := a.liability
+		liability.Amount = a.total.String()
+		liability.Payments = len(a.payments)
+		liabilities = append(liabilities, liability)
+	}
+	sort.Slice(liabilities, func(i, j int) bool {
+		return liabilities[i].SettleBy.Before(liabilities[j].SettleBy)
+	})
+	return liabilities
+}
+
+// SettleAccrued settles all deferred payments now, e.g. before shutdown, and
+// waits for deferred settlements in flight to finish
+func (s *x402ResourceServer) SettleAccrued(ctx context.Context) error {
+	s.accrualMu.Lock()
+	accruals := s.accruals
+	s.accruals = nil
+	s.accrualMu.Unlock()
+
+	for _, a := range accruals {
+		a.timer.Stop()
+		s.settleDeferred(a.payments)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.deferredWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deferSettlement adds a payment below the threshold to its payer's accrual
+// and reports whether it was deferred. A payment that brings the accrual to
+// the threshold is not deferred; the accrued payments settle with it.
+func (s *x402ResourceServer) deferSettlement(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) bool {
+	config := s.deferral
+	threshold, ok := deferralThreshold(config, requirements.Asset)
+	if !ok {
+		return false
+	}
+	amount, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok || amount.Sign() <= 0 || amount.Cmp(threshold) >= 0 {
+		return false
+	}
+	payer, _ := authorizationFields(payload.Payload)
+	if payer == "" {
+		return false
+	}
+	now := s.now()
+	settleBy := now.Add(time.Duration(requirements.MaxTimeoutSeconds)*time.Second - config.Margin)
+	if !settleBy.After(now) {
+		return false
+	}
+
+	key := strings.ToLower(strings.Join([]string{requirements.Scheme, requirements.Network, requirements.Asset, payer}, ":"))
+	s.accrualMu.Lock()
+	if s.accruals == nil {
+		s.accruals = make(map[string]*accrual)
+	}
+	a := s.accruals[key]
+	if a == nil {
+		a = &accrual{
+			liability: AccruedLiability{
+				Scheme:   requirements.Scheme,
+				Network:  requirements.Network,
+				Asset:    requirements.Asset,
+				Payer:    payer,
+				Since:    now,
+				SettleBy: now.Add(config.MaxAge),
+			},
+			total: new(big.Int),
+		}
+	}
+
+	if total := new(big.Int).Add(a.total, amount); total.Cmp(threshold) >= 0 {
+		if s.accruals[key] == a {
+			delete(s.accruals, key)
+			a.timer.Stop()
+		}
+		s.accrualMu.Unlock()
+		s.settleDeferred(a.payments)
+		return false
+	}
+
+	a.total.Add(a.total, amount)
+	a.payments = append(a.payments, deferredPayment{ctx: context.WithoutCancel(ctx), payload: payload, requirements: requirements})
+	if settleBy.Before(a.liability.SettleBy) {
+		a.liability.SettleBy = settleBy
+	}
+	if a.timer == nil {
+		a.timer = time.AfterFunc(a.liability.SettleBy.Sub(now), func() { s.flushAccrual(key, a) })
+		s.accruals[key] = a
+	} else {
+		a.timer.Reset(a.liability.SettleBy.Sub(now))
+	}
+	s.accrualMu.Unlock()
+	return true
+}
+
+// flushAccrual settles an accrual, unless it was already settled
+func (s *x402ResourceServer) flushAccrual(key string, a *accrual) {
+	s.accrualMu.Lock()
+	if s.accruals[key] != a {
+		s.accrualMu.Unlock()
+		return
+	}
+	delete(s.accruals, key)
+	s.accrualMu.Unlock()
+	s.settleDeferred(a.payments)
+}
+
+// settleDeferred settles deferred payments concurrently in the background,
+// so a facilitator batching settlements can aggregate them
+func (s *x402ResourceServer) settleDeferred(payments []deferredPayment) {
+	for _, p := range payments {
+		s.deferredWG.Add(1)
+		go func(p deferredPayment) {
+			defer s.deferredWG.Done()
+			_, _ = s.settleNow(p.ctx, p.payload, p.requirements, "")
+		}(p)
+	}
+}
+
+// deferralThreshold returns the break-even threshold for an asset
+func deferralThreshold(config *DeferredSettlementConfig, asset string) (*big.Int, bool) {
+	threshold := config.Threshold
+	for a, t := range config.AssetThresholds {
+		if strings.EqualFold(a, asset) {
+			threshold = t
+			break
+		}
+	}
+	value, ok := new(big.Int).SetString(threshold, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, false
+	}
+	return value, true
+}
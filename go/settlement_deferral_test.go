@@ -0,0 +1,151 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func deferralPayment(payer, nonce, amount string) (types.PaymentPayload, types.PaymentRequirements) {
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:8453", Asset: "0xusdc", Amount: amount, PayTo: "0xrecipient", MaxTimeoutSeconds: 3600}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{
+		"authorization": map[string]interface{}{"from": payer, "nonce": nonce},
+	}}
+	return payload, requirements
+}
+
+// settleRecorder is a facilitator client recording the nonces it settles
+type settleRecorder struct {
+	mockFacilitatorClient
+	mu      sync.Mutex
+	settled []string
+}
+
+func newSettleRecorder() *settleRecorder {
+	r := &settleRecorder{}
+	r.settle = func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+		var payload types.PaymentPayload
+		_ = json.Unmarshal(payloadBytes, &payload)
+		_, nonce := authorizationFields(payload.Payload)
+		r.mu.Lock()
+		r.settled = append(r.settled, nonce)
+		r.mu.Unlock()
+		return &SettleResponse{Success: true, Transaction: "0x" + nonce, Network: "eip155:8453"}, nil
+	}
+	return r
+}
+
+func (r *settleRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.settled)
+}
+
+func TestDeferredSettlementAccruesUntilThreshold(t *testing.T) {
+	ctx := context.Background()
+	client := newSettleRecorder()
+	var hooked int
+	var hookMu sync.Mutex
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(client),
+		WithDeferredSettlement(DeferredSettlementConfig{Threshold: "1000"}),
+		WithAfterSettleHook(func(ctx SettleResultContext) error {
+			hookMu.Lock()
+			hooked++
+			hookMu.Unlock()
+			return nil
+		}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	for _, nonce := range []string{"a", "b"} {
+		payload, requirements := deferralPayment("0xPayer", nonce, "400")
+		response, err := server.SettlePayment(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !response.Success || !response.Deferred || response.Transaction != "" || response.Payer != "0xPayer" {
+			t.Fatalf("Expected a deferred response, got %+v", response)
+		}
+	}
+	if client.count() != 0 {
+		t.Fatalf("Expected nothing settled yet, got %v", client.settled)
+	}
+
+	liabilities := server.AccruedLiabilities()
+	if len(liabilities) != 1 || liabilities[0].Amount != "800" || liabilities[0].Payments != 2 || liabilities[0].Payer != "0xPayer" {
+		t.Fatalf("Expected 800 accrued from 2 payments, got %+v", liabilities)
+	}
+
+	// Another payer accrues separately; large payments are never deferred
+	other, requirements := deferralPayment("0xOther", "c", "400")
+	if response, _ := server.SettlePayment(ctx, other, requirements); !response.Deferred {
+		t.Error("Expected the other payer's payment to be deferred")
+	}
+	large, requirements := deferralPayment("0xPayer", "d", "5000")
+	if response, _ := server.SettlePayment(ctx, large, requirements); response.Deferred || response.Transaction != "0xd" {
+		t.Errorf("Expected a large payment to settle immediately, got %+v", response)
+	}
+
+	// Crossing the threshold settles the payer's accrued payments with it
+	last, requirements := deferralPayment("0xPayer", "e", "400")
+	response, err := server.SettlePayment(ctx, last, requirements)
+	if err != nil || response.Transaction != "0xe" {
+		t.Fatalf("Expected the payment crossing the threshold to settle, got %+v (%v)", response, err)
+	}
+	if err := server.SettleAccrued(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.count() != 5 {
+		t.Errorf("Expected all 5 payments settled, got %v", client.settled)
+	}
+	if len(server.AccruedLiabilities()) != 0 {
+		t.Errorf("Expected no liabilities left, got %+v", server.AccruedLiabilities())
+	}
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	if hooked != 5 {
+		t.Errorf("Expected settle hooks for each settled payment, got %d", hooked)
+	}
+}
+
+func TestDeferredSettlementMaxAge(t *testing.T) {
+	ctx := context.Background()
+	client := newSettleRecorder()
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(client),
+		WithDeferredSettlement(DeferredSettlementConfig{
+			Threshold:       "1000000",
+			AssetThresholds: map[string]string{"0xUSDC": "1000"},
+			MaxAge:          20 * time.Millisecond,
+		}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	payload, requirements := deferralPayment("0xPayer", "a", "999")
+	if response, _ := server.SettlePayment(ctx, payload, requirements); !response.Deferred {
+		t.Fatalf("Expected the payment to be deferred, got %+v", response)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if client.count() != 1 {
+		t.Fatal("Expected the payment to settle after MaxAge")
+	}
+
+	// A payment without a payer cannot be accrued
+	unattributed := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+	if response, _ := server.SettlePayment(ctx, unattributed, requirements); response.Deferred {
+		t.Error("Expected a payment without a payer to settle immediately")
+	}
+}
@@ -0,0 +1,242 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Settlement Journal
+// ============================================================================
+
+// SubmittedSettlement is a settlement transaction that was broadcast but
+// whose outcome is not yet known
+type SubmittedSettlement struct {
+	Transaction string    `json:"transaction"`
+	Scheme      string    `json:"scheme"`
+	Network     Network   `json:"network"`
+	Payer       string    `json:"payer,omitempty"`
+	Asset       string    `json:"asset,omitempty"`
+	Amount      string    `json:"amount,omitempty"` // Atomic units
+	SubmittedAt time.Time `json:"submittedAt"`
+
+	// Attribution is the metadata the server attached to the requirements
+	// (types.AttributionExtraKey), e.g. an order ID
+	Attribution map[string]string `json:"attribution,omitempty"`
+}
+
+// SettlementJournal persists settlement transactions as soon as they are
+// broadcast, so a facilitator that restarts before they confirm can find out
+// how they ended. Implementations must be safe for concurrent use.
+type SettlementJournal interface {
+	// Submitted records a broadcast transaction
+	Submitted(ctx context.Context, settlement SubmittedSettlement) error
+
+	// Resolved removes a transaction whose outcome is known
+	Resolved(ctx context.Context, network Network, transaction string) error
+
+	// Pending returns the transactions not yet resolved, oldest first
+	Pending(ctx context.Context) ([]SubmittedSettlement, error)
+}
+
+// SettlementRecoverer is optionally implemented by SchemeNetworkFacilitator
+// mechanisms that journal their settlements. RecoverSettlement waits for a
+// submitted transaction, returning its final outcome (a SettleResponse with
+// Success false if it failed), or an error while the outcome is unknown.
+type SettlementRecoverer interface {
+	RecoverSettlement(ctx context.Context, settlement SubmittedSettlement) (*SettleResponse, error)
+}
+
+// RecoveredSettlement is the outcome of recovering a submitted settlement
+type RecoveredSettlement struct {
+	Settlement SubmittedSettlement
+	Response   *SettleResponse // Final outcome, nil when Err is set
+	Err        error           // Why the outcome is still unknown
+}
+
+// RecoverSettlements resumes waiting for the settlements in journal that were
+// submitted but not confirmed, e.g. after a crash, resolving those whose
+// outcome is now known. Call it on startup, after registering mechanisms;
+// settlements still unknown stay in the journal for the next call.
+func (f *x402Facilitator) RecoverSettlements(ctx context.Context, journal SettlementJournal) ([]RecoveredSettlement, error) {
+	pending, err := journal.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RecoveredSettlement, 0, len(pending))
+	for _, settlement := range pending {
+		result := RecoveredSettlement{Settlement: settlement}
+
+		f.mu.RLock()
+		data := findSchemeData(f.schemes, settlement.Scheme, settlement.Network)
+		f.mu.RUnlock()
+
+		var recoverer SettlementRecoverer
+		if data != nil {
+			recoverer, _ = data.facilitator.(SettlementRecoverer)
+		}
+		if recoverer == nil {
+			result.Err = fmt.Errorf("no facilitator can recover scheme %s on network %s", settlement.Scheme, settlement.Network)
+			results = append(results, result)
+			continue
+		}
+
+		result.Response, result.Err = recoverer.RecoverSettlement(ctx, settlement)
+		if result.Err == nil {
+			if err := journal.Resolved(ctx, settlement.Network, settlement.Transaction); err != nil {
+				result.Err = err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// MemorySettlementJournal is an in-process SettlementJournal. It does not
+// survive restarts; use FileSettlementJournal or a database-backed journal
+// for crash recovery.
+type MemorySettlementJournal struct {
+	mu      sync.Mutex
+	entries map[string]SubmittedSettlement
+}
+
+// NewMemorySettlementJournal creates an empty in-process journal
+func NewMemorySettlementJournal() *MemorySettlementJournal {
+	return &MemorySettlementJournal{entries: make(map[string]SubmittedSettlement)}
+}
+
+// Submitted implements SettlementJournal
+func (j *MemorySettlementJournal) Submitted(ctx context.Context, settlement SubmittedSettlement) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries[journalKey(settlement.Network, settlement.Transaction)] = settlement
+	return nil
+}
+
+// Resolved implements SettlementJournal
+func (j *MemorySettlementJournal) Resolved(ctx context.Context, network Network, transaction string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.entries, journalKey(network, transaction))
+	return nil
+}
+
+// Pending implements SettlementJournal
+func (j *MemorySettlementJournal) Pending(ctx context.Context) ([]SubmittedSettlement, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return sortedSettlements(j.entries), nil
+}
+
+// FileSettlementJournal is a SettlementJournal kept in a JSON file, rewritten
+// atomically on every change. It suits a single facilitator instance.
+type FileSettlementJournal struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]SubmittedSettlement
+}
+
+// NewFileSettlementJournal opens the journal at path, loading the pending
+// settlements it holds, or creates it
+func NewFileSettlementJournal(path string) (*FileSettlementJournal, error) {
+	j := &FileSettlementJournal{path: path, entries: make(map[string]SubmittedSettlement)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settlement journal: %w", err)
+	}
+	var pending []SubmittedSettlement
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse settlement journal: %w", err)
+	}
+	for _, settlement := range pending {
+		j.entries[journalKey(settlement.Network, settlement.Transaction)] = settlement
+	}
+	return j, nil
+}
+
+// Submitted implements SettlementJournal
+func (j *FileSettlementJournal) Submitted(ctx context.Context, settlement SubmittedSettlement) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries[journalKey(settlement.Network, settlement.Transaction)] = settlement
+	return j.save()
+}
+
+// Resolved implements SettlementJournal
+func (j *FileSettlementJournal) Resolved(ctx context.Context, network Network, transaction string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	key := journalKey(network, transaction)
+	if _, ok := j.entries[key]; !ok {
+		return nil
+	}
+	delete(j.entries, key)
+	return j.save()
+}
+
+// Pending implements SettlementJournal
+func (j *FileSettlementJournal) Pending(ctx context.Context) ([]SubmittedSettlement, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return sortedSettlements(j.entries), nil
+}
+
+// save writes the journal to a temporary file and renames it over the old
+// one, so a crash never leaves a partial file; the caller holds the lock
+func (j *FileSettlementJournal) save() error {
+	data, err := json.Marshal(sortedSettlements(j.entries))
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write settlement journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write settlement journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write settlement journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write settlement journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to write settlement journal: %w", err)
+	}
+	return nil
+}
+
+func journalKey(network Network, transaction string) string {
+	return string(network) + ":" + transaction
+}
+
+// sortedSettlements returns entries oldest first
+func sortedSettlements(entries map[string]SubmittedSettlement) []SubmittedSettlement {
+	settlements := make([]SubmittedSettlement, 0, len(entries))
+	for _, settlement := range entries {
+		settlements = append(settlements, settlement)
+	}
+	sort.Slice(settlements, func(a, b int) bool {
+		if !settlements[a].SubmittedAt.Equal(settlements[b].SubmittedAt) {
+			return settlements[a].SubmittedAt.Before(settlements[b].SubmittedAt)
+		}
+		return settlements[a].Transaction < settlements[b].Transaction
+	})
+	return settlements
+}
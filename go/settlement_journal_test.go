@@ -0,0 +1,81 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type mockRecoveringFacilitator struct {
+	mockSchemeNetworkFacilitator
+	recover func(ctx context.Context, settlement SubmittedSettlement) (*SettleResponse, error)
+}
+
+func (m *mockRecoveringFacilitator) RecoverSettlement(ctx context.Context, settlement SubmittedSettlement) (*SettleResponse, error) {
+	return m.recover(ctx, settlement)
+}
+
+func TestRecoverSettlements(t *testing.T) {
+	ctx := context.Background()
+	journal := NewMemorySettlementJournal()
+	now := time.Now()
+	_ = journal.Submitted(ctx, SubmittedSettlement{Transaction: "0xconfirmed", Scheme: "exact", Network: "eip155:1", SubmittedAt: now})
+	_ = journal.Submitted(ctx, SubmittedSettlement{Transaction: "0xpending", Scheme: "exact", Network: "eip155:1", SubmittedAt: now.Add(time.Second)})
+	_ = journal.Submitted(ctx, SubmittedSettlement{Transaction: "0xunknown", Scheme: "exact", Network: "eip155:2", SubmittedAt: now.Add(2 * time.Second)})
+
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockRecoveringFacilitator{
+		mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"},
+		recover: func(ctx context.Context, settlement SubmittedSettlement) (*SettleResponse, error) {
+			if settlement.Transaction == "0xpending" {
+				return nil, errors.New("not yet mined")
+			}
+			return &SettleResponse{Success: true, Transaction: settlement.Transaction, Network: settlement.Network}, nil
+		},
+	})
+
+	recovered, err := facilitator.RecoverSettlements(ctx, journal)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recovered) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(recovered))
+	}
+	if recovered[0].Err != nil || !recovered[0].Response.Success {
+		t.Errorf("Expected the first settlement to be recovered, got %+v", recovered[0])
+	}
+	if recovered[1].Err == nil || recovered[2].Err == nil {
+		t.Errorf("Expected unknown outcomes to be reported, got %+v", recovered[1:])
+	}
+
+	pending, _ := journal.Pending(ctx)
+	if len(pending) != 2 || pending[0].Transaction != "0xpending" || pending[1].Transaction != "0xunknown" {
+		t.Errorf("Expected unresolved settlements to stay journaled, got %+v", pending)
+	}
+}
+
+func TestFileSettlementJournalSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "settlements.json")
+
+	journal, err := NewFileSettlementJournal(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = journal.Submitted(ctx, SubmittedSettlement{Transaction: "0xa", Scheme: "exact", Network: "eip155:1", Payer: "0xpayer", SubmittedAt: time.Now()})
+	_ = journal.Submitted(ctx, SubmittedSettlement{Transaction: "0xb", Scheme: "exact", Network: "eip155:1", SubmittedAt: time.Now()})
+	if err := journal.Resolved(ctx, "eip155:1", "0xb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileSettlementJournal(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pending, _ := reopened.Pending(ctx)
+	if len(pending) != 1 || pending[0].Transaction != "0xa" || pending[0].Payer != "0xpayer" {
+		t.Errorf("Expected the unresolved settlement after reopening, got %+v", pending)
+	}
+}
@@ -0,0 +1,174 @@
+package x402
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// ============================================================================
+// Pending Settlement Status
+// ============================================================================
+
+// Settlement statuses reported by SettlementStatus
+const (
+	SettlementStatusPending   = "pending"
+	SettlementStatusConfirmed = "confirmed"
+	SettlementStatusFailed    = "failed"
+)
+
+// pendingSettlementTimeout bounds how long a pending settlement is waited for
+// in the background
+const pendingSettlementTimeout = time.Hour
+
+// settlementStatusRetention is how long final statuses stay available
+const settlementStatusRetention = 24 * time.Hour
+
+// pendingSettlementRetryDelay is the wait between attempts to learn the
+// outcome of a pending settlement
+var pendingSettlementRetryDelay = 5 * time.Second
+
+// SettlementStatus is the state of a settlement that was pending when Settle
+// returned
+type SettlementStatus struct {
+	Status      string          `json:"status"` // SettlementStatusPending, SettlementStatusConfirmed, or SettlementStatusFailed
+	Transaction string          `json:"transaction"`
+	Network     Network         `json:"network"`
+	Payer       string          `json:"payer,omitempty"`
+	Result      *SettleResponse `json:"result,omitempty"` // Final outcome, once known
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// SetSettlementStatusURL sets the endpoint serving SettlementStatus (e.g.
+// "https://facilitator.example/settle/status"). Pending settle responses
+// link to it with network and transaction query parameters.
+func (f *x402Facilitator) SetSettlementStatusURL(statusURL string) *x402Facilitator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statusURL = statusURL
+	return f
+}
+
+// OnSettlementFinalized registers a hook called when a pending settlement
+// confirms or fails
+func (f *x402Facilitator) OnSettlementFinalized(hook FacilitatorSettlementFinalizedHook) *x402Facilitator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settlementFinalizedHooks = append(f.settlementFinalizedHooks, hook)
+	return f
+}
+
+// SettlementStatus returns the status of a settlement that was pending when
+// Settle returned, and false for unknown transactions
+func (f *x402Facilitator) SettlementStatus(network Network, transaction string) (SettlementStatus, bool) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+	status, ok := f.statuses[journalKey(network, transaction)]
+	if !ok {
+		return SettlementStatus{}, false
+	}
+	return *status, true
+}
+
+// trackPendingSettlement links a pending response to its status and keeps
+// waiting for the outcome in the background with the mechanism's
+// SettlementRecoverer
+func (f *x402Facilitator) trackPendingSettlement(requirements PaymentRequirementsView, response *SettleResponse) {
+	if response == nil || !response.Pending {
+		return
+	}
+
+	f.mu.RLock()
+	statusURL := f.statusURL
+	var facilitator interface{}
+	if data := findSchemeData(f.schemes, requirements.GetScheme(), Network(requirements.GetNetwork())); data != nil {
+		facilitator = data.facilitator
+	}
+	f.mu.RUnlock()
+	if statusURL != "" {
+		query := url.Values{"network": {string(response.Network)}, "transaction": {response.Transaction}}
+		response.StatusURL = statusURL + "?" + query.Encode()
+	}
+
+	now := time.Now()
+	f.statusMu.Lock()
+	if f.statuses == nil {
+		f.statuses = make(map[string]*SettlementStatus)
+	}
+	for key, status := range f.statuses {
+		if status.Status != SettlementStatusPending && now.Sub(status.UpdatedAt) > settlementStatusRetention {
+			delete(f.statuses, key)
+		}
+	}
+	f.statuses[journalKey(response.Network, response.Transaction)] = &SettlementStatus{
+		Status:      SettlementStatusPending,
+		Transaction: response.Transaction,
+		Network:     response.Network,
+		Payer:       response.Payer,
+		UpdatedAt:   now,
+	}
+	f.statusMu.Unlock()
+
+	recoverer, ok := facilitator.(SettlementRecoverer)
+	if !ok {
+		return
+	}
+	settlement := SubmittedSettlement{
+		Transaction: response.Transaction,
+		Scheme:      requirements.GetScheme(),
+		Network:     response.Network,
+		Payer:       response.Payer,
+		Asset:       requirements.GetAsset(),
+		Amount:      requirements.GetAmount(),
+		SubmittedAt: now,
+		Attribution: types.Attribution(requirements.GetExtra()),
+	}
+	go f.awaitSettlement(recoverer, settlement)
+}
+
+// awaitSettlement waits for the outcome of a pending settlement, retrying
+// while it is unknown, and finalizes it
+func (f *x402Facilitator) awaitSettlement(recoverer SettlementRecoverer, settlement SubmittedSettlement) {
+	ctx, cancel := context.WithTimeout(context.Background(), pendingSettlementTimeout)
+	defer cancel()
+
+	for {
+		result, err := recoverer.RecoverSettlement(ctx, settlement)
+		if err == nil && result != nil {
+			f.finalizeSettlement(ctx, settlement, result)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pendingSettlementRetryDelay):
+		}
+	}
+}
+
+// finalizeSettlement records the outcome of a pending settlement and runs
+// the finalized hooks
+func (f *x402Facilitator) finalizeSettlement(ctx context.Context, settlement SubmittedSettlement, result *SettleResponse) {
+	state := SettlementStatusConfirmed
+	if !result.Success {
+		state = SettlementStatusFailed
+	}
+
+	f.statusMu.Lock()
+	if status, ok := f.statuses[journalKey(settlement.Network, settlement.Transaction)]; ok {
+		status.Status = state
+		status.Result = result
+		status.UpdatedAt = time.Now()
+	}
+	f.statusMu.Unlock()
+
+	f.mu.RLock()
+	hooks := f.settlementFinalizedHooks
+	f.mu.RUnlock()
+	hookCtx := FacilitatorSettlementFinalizedContext{Ctx: ctx, Settlement: settlement, Result: result}
+	for _, hook := range hooks {
+		_ = hook(hookCtx) // Log errors but don't fail
+	}
+}
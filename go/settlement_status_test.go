@@ -0,0 +1,77 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestPendingSettlementIsTrackedUntilConfirmed(t *testing.T) {
+	defer func(delay time.Duration) { pendingSettlementRetryDelay = delay }(pendingSettlementRetryDelay)
+	pendingSettlementRetryDelay = time.Millisecond
+
+	ctx := context.Background()
+	var attempts int32
+	finalized := make(chan FacilitatorSettlementFinalizedContext, 1)
+
+	facilitator := Newx402Facilitator().SetSettlementStatusURL("https://facilitator.example/settle/status")
+	facilitator.Register([]Network{"eip155:1"}, &mockRecoveringFacilitator{
+		mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{
+			scheme: "exact",
+			settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+				return &SettleResponse{Success: true, Pending: true, Transaction: "0xslow", Network: "eip155:1", Payer: "0xpayer"}, nil
+			},
+		},
+		recover: func(ctx context.Context, settlement SubmittedSettlement) (*SettleResponse, error) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				return nil, errors.New("not yet mined")
+			}
+			return &SettleResponse{Success: true, Transaction: settlement.Transaction, Network: settlement.Network, Payer: settlement.Payer}, nil
+		},
+	})
+	facilitator.OnSettlementFinalized(func(ctx FacilitatorSettlementFinalizedContext) error {
+		finalized <- ctx
+		return nil
+	})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient",
+		Extra: map[string]interface{}{types.AttributionExtraKey: map[string]interface{}{"orderId": "ord_123"}}}
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"signature": "0x"}})
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	response, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !response.Pending {
+		t.Fatal("Expected a pending response")
+	}
+	if response.StatusURL != "https://facilitator.example/settle/status?network=eip155%3A1&transaction=0xslow" {
+		t.Errorf("Unexpected status URL %s", response.StatusURL)
+	}
+
+	select {
+	case ctx := <-finalized:
+		if !ctx.Result.Success || ctx.Settlement.Transaction != "0xslow" || ctx.Settlement.Amount != "1000000" || ctx.Settlement.Attribution["orderId"] != "ord_123" {
+			t.Errorf("Unexpected finalized settlement %+v, %+v", ctx.Settlement, ctx.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the settlement to be finalized")
+	}
+
+	status, ok := facilitator.SettlementStatus("eip155:1", "0xslow")
+	if !ok {
+		t.Fatal("Expected a settlement status")
+	}
+	if status.Status != SettlementStatusConfirmed || status.Result == nil || status.Payer != "0xpayer" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if _, ok := facilitator.SettlementStatus("eip155:1", "0xother"); ok {
+		t.Error("Expected no status for an unknown transaction")
+	}
+}
@@ -0,0 +1,298 @@
+// Package signedurl sells access to objects in S3, GCS, or R2 without
+// proxying their bytes. Behind a paid route, Handler answers each paid request
+// with a short-lived signed URL for the object (or a redirect to it), and the
+// client downloads straight from the bucket.
+//
+// S3Signer presigns URLs with AWS Signature Version 4, which S3, Cloudflare R2,
+// and Google Cloud Storage (with HMAC keys) all accept. Other signers, such as
+// the official cloud SDKs, can implement Signer.
+package signedurl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long signed URLs are valid unless configured otherwise
+const DefaultTTL = 5 * time.Minute
+
+// maxTTL is the longest validity SigV4 presigned URLs allow
+const maxTTL = 7 * 24 * time.Hour
+
+// Signer mints signed URLs for objects
+type Signer interface {
+	// SignURL returns a URL granting GET access to the object at key for ttl
+	SignURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ============================================================================
+// S3-Compatible Signer
+// ============================================================================
+
+// S3Config configures an S3Signer
+type S3Config struct {
+	// Bucket holds the objects
+	Bucket string
+
+	// Region is the bucket's region (e.g. "us-east-1"; "auto" for R2 and GCS)
+	Region string
+
+	// AccessKeyID and SecretAccessKey are the signing credentials. For GCS,
+	// use an HMAC key.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Endpoint is the service URL (default: https://s3.<region>.amazonaws.com)
+	Endpoint string
+
+	// PathStyle addresses objects as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint host>/<key>
+	PathStyle bool
+
+	// ContentDisposition, when set, overrides the object's Content-Disposition
+	// in the response (e.g. `attachment; filename="report.pdf"`)
+	ContentDisposition string
+
+	// now returns the signing time (for tests)
+	now func() time.Time
+}
+
+// R2Config returns the S3Config for a Cloudflare R2 bucket
+func R2Config(accountID, bucket, accessKeyID, secretAccessKey string) S3Config {
+	return S3Config{
+		Bucket:          bucket,
+		Region:          "auto",
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Endpoint:        "https://" + accountID + ".r2.cloudflarestorage.com",
+		PathStyle:       true,
+	}
+}
+
+// GCSConfig returns the S3Config for a Google Cloud Storage bucket, signing
+// with a GCS HMAC key
+func GCSConfig(bucket, hmacAccessID, hmacSecret string) S3Config {
+	return S3Config{
+		Bucket:          bucket,
+		Region:          "auto",
+		AccessKeyID:     hmacAccessID,
+		SecretAccessKey: hmacSecret,
+		Endpoint:        "https://storage.googleapis.com",
+		PathStyle:       true,
+	}
+}
+
+// S3Signer presigns GET URLs with AWS Signature Version 4
+type S3Signer struct {
+	config   S3Config
+	endpoint *url.URL
+}
+
+// NewS3Signer creates a signer for an S3-compatible bucket
+func NewS3Signer(config S3Config) (*S3Signer, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, errors.New("signed URLs need a bucket and region")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("signed URLs need an access key")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", config.Endpoint)
+	}
+	if config.now == nil {
+		config.now = time.Now
+	}
+	return &S3Signer{config: config, endpoint: endpoint}, nil
+}
+
+// SignURL implements Signer
+func (s *S3Signer) SignURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", errors.New("empty object key")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > maxTTL {
+		return "", fmt.Errorf("signed URLs are valid for at most %s", maxTTL)
+	}
+
+	host := s.endpoint.Host
+	objectPath := "/" + key
+	if s.config.PathStyle {
+		objectPath = "/" + s.config.Bucket + objectPath
+	} else {
+		host = s.config.Bucket + "." + host
+	}
+	canonicalURI := uriEncode(strings.TrimSuffix(s.endpoint.Path, "/")+objectPath, false)
+
+	now := s.config.now().UTC()
+	date := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.config.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.FormatInt(int64(ttl/time.Second), 10),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if s.config.ContentDisposition != "" {
+		query["response-content-disposition"] = s.config.ContentDisposition
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		canonicalURI,
+		canonicalQuery,
+		"host:" + host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s.endpoint.Scheme + "://" + host + canonicalURI + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+// canonicalQueryString encodes query parameters sorted by name
+func canonicalQueryString(query map[string]string) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = uriEncode(name, true) + "=" + uriEncode(query[name], true)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, as SigV4
+// requires, leaving "/" alone in paths
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// ============================================================================
+// Handler
+// ============================================================================
+
+// HandlerConfig configures a Handler
+type HandlerConfig struct {
+	// Signer mints the URLs
+	Signer Signer
+
+	// Prefix is stripped from the request path to get the object key
+	// (e.g. "/assets/" serves /assets/video.mp4 as video.mp4)
+	Prefix string
+
+	// Key maps a request to an object key instead of Prefix, e.g. to look up
+	// a product's object. Returning an error responds 404.
+	Key func(r *http.Request) (string, error)
+
+	// TTL is how long signed URLs are valid (default: DefaultTTL). Keep it
+	// short: anyone holding the URL can fetch the object until it expires.
+	TTL time.Duration
+
+	// Redirect responds with a 302 to the signed URL instead of returning it as JSON
+	Redirect bool
+}
+
+// Response is the JSON body Handler returns when not redirecting
+type Response struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Handler returns an http.Handler that answers each request with a signed URL
+// for its object. Put it behind a paid route: it signs for every request it
+// receives. Middleware that buffers the response until settlement (such as
+// the Gin middleware) withholds the URL when settlement fails.
+func Handler(config HandlerConfig) http.Handler {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	prefix := "/" + strings.Trim(config.Prefix, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var key string
+		if config.Key != nil {
+			var err error
+			if key, err = config.Key(r); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+		} else {
+			cleaned := path.Clean("/" + r.URL.Path)
+			var ok bool
+			if key, ok = strings.CutPrefix(cleaned, strings.TrimSuffix(prefix, "/")+"/"); !ok || key == "" {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		expiresAt := time.Now().Add(config.TTL).UTC().Truncate(time.Second)
+		signed, err := config.Signer.SignURL(r.Context(), key, config.TTL)
+		if err != nil {
+			http.Error(w, "failed to sign URL", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if config.Redirect {
+			http.Redirect(w, r, signed, http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Response{URL: signed, ExpiresAt: expiresAt})
+	})
+}
@@ -0,0 +1,109 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ============================================================================
+// Supported Kinds Filtering and Pagination
+// ============================================================================
+
+// SupportedQuery filters and paginates a facilitator's supported kinds.
+// The zero value matches everything in a single page.
+type SupportedQuery struct {
+	// Scheme limits kinds to one scheme (e.g. "exact")
+	Scheme string
+
+	// Network limits kinds to a network or a family wildcard (e.g. "eip155:*").
+	// Kinds advertised as wildcards match any network in their family.
+	Network Network
+
+	// X402Version limits kinds to one protocol version (0 for all)
+	X402Version int
+
+	// Limit is the page size (0 for no pagination)
+	Limit int
+
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+}
+
+// SupportedQueryClient is implemented by facilitator clients that can filter
+// and paginate supported kinds on the facilitator
+type SupportedQueryClient interface {
+	QuerySupported(ctx context.Context, query SupportedQuery) (SupportedResponse, error)
+}
+
+// QuerySupported returns the supported kinds matching the query
+func (f *x402Facilitator) QuerySupported(query SupportedQuery) (SupportedResponse, error) {
+	return FilterSupported(f.GetSupported(), query)
+}
+
+// FilterSupported applies a query to a supported response. Kinds are sorted
+// by version, scheme, and network so pages are stable across requests. Signers
+// are limited to the families of the matching kinds; extensions are unchanged.
+func FilterSupported(response SupportedResponse, query SupportedQuery) (SupportedResponse, error) {
+	if query.Limit < 0 {
+		return SupportedResponse{}, fmt.Errorf("invalid limit: %d", query.Limit)
+	}
+	offset := 0
+	if query.Cursor != "" {
+		parsed, err := strconv.Atoi(query.Cursor)
+		if err != nil || parsed < 0 {
+			return SupportedResponse{}, fmt.Errorf("invalid cursor: %q", query.Cursor)
+		}
+		offset = parsed
+	}
+
+	kinds := make([]SupportedKind, 0, len(response.Kinds))
+	for _, kind := range response.Kinds {
+		if query.Scheme != "" && kind.Scheme != query.Scheme {
+			continue
+		}
+		if query.X402Version != 0 && kind.X402Version != query.X402Version {
+			continue
+		}
+		if query.Network != "" && !Network(kind.Network).Match(query.Network) {
+			continue
+		}
+		kinds = append(kinds, kind)
+	}
+	sort.SliceStable(kinds, func(i, j int) bool {
+		if kinds[i].X402Version != kinds[j].X402Version {
+			return kinds[i].X402Version < kinds[j].X402Version
+		}
+		if kinds[i].Scheme != kinds[j].Scheme {
+			return kinds[i].Scheme < kinds[j].Scheme
+		}
+		return kinds[i].Network < kinds[j].Network
+	})
+
+	signers := make(map[string][]string)
+	for family, addresses := range response.Signers {
+		for _, kind := range kinds {
+			if Network(kind.Network).Match(Network(family)) {
+				signers[family] = addresses
+				break
+			}
+		}
+	}
+
+	filtered := SupportedResponse{
+		Kinds:      kinds,
+		Extensions: response.Extensions,
+		Signers:    signers,
+	}
+
+	if offset > len(kinds) {
+		offset = len(kinds)
+	}
+	filtered.Kinds = kinds[offset:]
+	if query.Limit > 0 && len(filtered.Kinds) > query.Limit {
+		filtered.Kinds = filtered.Kinds[:query.Limit]
+		filtered.NextCursor = strconv.Itoa(offset + query.Limit)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,103 @@
+package x402
+
+import "testing"
+
+func supportedFixture() SupportedResponse {
+	return SupportedResponse{
+		Kinds: []SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"},
+			{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 1, Scheme: "exact", Network: "base"},
+			{X402Version: 2, Scheme: "upto", Network: "eip155:*"},
+			{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+		},
+		Extensions: []string{"bazaar"},
+		Signers: map[string][]string{
+			"eip155:*": {"0xsigner"},
+			"solana:*": {"SoLsigner"},
+		},
+	}
+}
+
+func TestFilterSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    SupportedQuery
+		networks []string
+		signers  []string
+	}{
+		{"everything sorted", SupportedQuery{}, []string{"base", "eip155:1", "eip155:8453", "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "eip155:*"}, []string{"eip155:*", "solana:*"}},
+		{"by scheme", SupportedQuery{Scheme: "upto"}, []string{"eip155:*"}, []string{"eip155:*"}},
+		{"by version", SupportedQuery{X402Version: 1}, []string{"base"}, []string{}},
+		{"by family", SupportedQuery{Network: "solana:*"}, []string{"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"}, []string{"solana:*"}},
+		{"exact network includes wildcard kinds", SupportedQuery{Network: "eip155:10"}, []string{"eip155:*"}, []string{"eip155:*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := FilterSupported(supportedFixture(), tt.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(filtered.Kinds) != len(tt.networks) {
+				t.Fatalf("Expected %d kinds, got %+v", len(tt.networks), filtered.Kinds)
+			}
+			for i, network := range tt.networks {
+				if filtered.Kinds[i].Network != network {
+					t.Errorf("Expected kind %d on %s, got %s", i, network, filtered.Kinds[i].Network)
+				}
+			}
+			if len(filtered.Signers) != len(tt.signers) {
+				t.Errorf("Expected signers for %v, got %v", tt.signers, filtered.Signers)
+			}
+			if len(filtered.Extensions) != 1 {
+				t.Errorf("Expected extensions to be kept, got %v", filtered.Extensions)
+			}
+		})
+	}
+}
+
+func TestFilterSupportedPagination(t *testing.T) {
+	query := SupportedQuery{X402Version: 2, Limit: 2}
+	var networks []string
+	pages := 0
+
+	for {
+		page, err := FilterSupported(supportedFixture(), query)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pages++
+		for _, kind := range page.Kinds {
+			networks = append(networks, kind.Network)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if pages != 2 || len(networks) != 4 {
+		t.Fatalf("Expected 4 kinds over 2 pages, got %v over %d pages", networks, pages)
+	}
+
+	for _, cursor := range []string{"abc", "-1"} {
+		if _, err := FilterSupported(supportedFixture(), SupportedQuery{Cursor: cursor}); err == nil {
+			t.Errorf("Expected error for cursor %q", cursor)
+		}
+	}
+}
+
+func TestFacilitatorQuerySupported(t *testing.T) {
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1", "eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+	facilitator.RegisterV1([]Network{"base"}, &mockSchemeNetworkFacilitatorV1{scheme: "exact"})
+
+	supported, err := facilitator.QuerySupported(SupportedQuery{X402Version: 2, Limit: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(supported.Kinds) != 1 || supported.Kinds[0].Network != "eip155:1" || supported.NextCursor != "1" {
+		t.Errorf("Unexpected first page %+v", supported)
+	}
+}
@@ -0,0 +1,258 @@
+package x402
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Supported Kinds Watcher
+// ============================================================================
+
+// SupportedChange describes how a facilitator's supported response changed
+// between two refreshes
+type SupportedChange struct {
+	Previous SupportedResponse
+	Current  SupportedResponse
+
+	AddedKinds   []SupportedKind
+	RemovedKinds []SupportedKind
+
+	// ChangedKinds are kinds (same version, scheme, and network) whose extra
+	// changed, e.g. a rotated SVM fee payer. They hold the current values.
+	ChangedKinds []SupportedKind
+
+	// AddedSigners and RemovedSigners are keyed by CAIP family
+	AddedSigners   map[string][]string
+	RemovedSigners map[string][]string
+
+	AddedExtensions   []string
+	RemovedExtensions []string
+}
+
+// Empty reports whether nothing changed
+func (c SupportedChange) Empty() bool {
+	return len(c.AddedKinds) == 0 && len(c.RemovedKinds) == 0 && len(c.ChangedKinds) == 0 &&
+		len(c.AddedSigners) == 0 && len(c.RemovedSigners) == 0 &&
+		len(c.AddedExtensions) == 0 && len(c.RemovedExtensions) == 0
+}
+
+// SupportedChangeHandler is called when a watched facilitator's supported response changes
+type SupportedChangeHandler func(ctx context.Context, change SupportedChange)
+
+// DiffSupported compares two supported responses. Kinds are identified by
+// version, scheme, and network; ordering of kinds, signers, and extensions is ignored.
+func DiffSupported(previous, current SupportedResponse) SupportedChange {
+	change := SupportedChange{
+		Previous:       previous,
+		Current:        current,
+		AddedSigners:   make(map[string][]string),
+		RemovedSigners: make(map[string][]string),
+	}
+
+	previousKinds := make(map[supportedKindKey]SupportedKind, len(previous.Kinds))
+	for _, kind := range previous.Kinds {
+		previousKinds[keyOfKind(kind)] = kind
+	}
+	currentKinds := make(map[supportedKindKey]bool, len(current.Kinds))
+	for _, kind := range current.Kinds {
+		key := keyOfKind(kind)
+		currentKinds[key] = true
+		old, existed := previousKinds[key]
+		switch {
+		case !existed:
+			change.AddedKinds = append(change.AddedKinds, kind)
+		case !extraEqual(old.Extra, kind.Extra):
+			change.ChangedKinds = append(change.ChangedKinds, kind)
+		}
+	}
+	for _, kind := range previous.Kinds {
+		if !currentKinds[keyOfKind(kind)] {
+			change.RemovedKinds = append(change.RemovedKinds, kind)
+		}
+	}
+
+	families := make(map[string]bool)
+	for family := range previous.Signers {
+		families[family] = true
+	}
+	for family := range current.Signers {
+		families[family] = true
+	}
+	for family := range families {
+		if added := stringsMissing(current.Signers[family], previous.Signers[family]); len(added) > 0 {
+			change.AddedSigners[family] = added
+		}
+		if removed := stringsMissing(previous.Signers[family], current.Signers[family]); len(removed) > 0 {
+			change.RemovedSigners[family] = removed
+		}
+	}
+
+	change.AddedExtensions = stringsMissing(current.Extensions, previous.Extensions)
+	change.RemovedExtensions = stringsMissing(previous.Extensions, current.Extensions)
+	return change
+}
+
+// SupportedWatcherConfig configures a SupportedWatcher
+type SupportedWatcherConfig struct {
+	// Interval between refreshes (default: 5 minutes)
+	Interval time.Duration
+
+	// OnError is called when a refresh fails (optional). The previous
+	// response is kept, so a failed refresh never reports kinds as removed.
+	OnError func(ctx context.Context, err error)
+}
+
+// SupportedWatcher periodically refreshes a facilitator's supported kinds and
+// notifies handlers when kinds, signers, or extensions change
+type SupportedWatcher struct {
+	client FacilitatorClient
+	config SupportedWatcherConfig
+
+	mu       sync.Mutex
+	current  *SupportedResponse
+	handlers []SupportedChangeHandler
+}
+
+// NewSupportedWatcher creates a watcher for a facilitator client. The first
+// refresh sets the baseline without notifying handlers, unless one is set with SetBaseline.
+func NewSupportedWatcher(client FacilitatorClient, config SupportedWatcherConfig) *SupportedWatcher {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	return &SupportedWatcher{client: client, config: config}
+}
+
+// OnChange registers a handler called with every non-empty change
+func (w *SupportedWatcher) OnChange(handler SupportedChangeHandler) *SupportedWatcher {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+	return w
+}
+
+// SetBaseline sets the response later refreshes are compared against
+func (w *SupportedWatcher) SetBaseline(supported SupportedResponse) *SupportedWatcher {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.current = &supported
+	return w
+}
+
+// Current returns the latest supported response, if one has been fetched
+func (w *SupportedWatcher) Current() (SupportedResponse, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.current == nil {
+		return SupportedResponse{}, false
+	}
+	return *w.current, true
+}
+
+// Refresh fetches the supported response now and notifies handlers if it changed
+func (w *SupportedWatcher) Refresh(ctx context.Context) (SupportedChange, error) {
+	supported, err := w.client.GetSupported(ctx)
+	if err != nil {
+		return SupportedChange{}, err
+	}
+
+	w.mu.Lock()
+	previous := w.current
+	w.current = &supported
+	handlers := append([]SupportedChangeHandler(nil), w.handlers...)
+	w.mu.Unlock()
+
+	if previous == nil {
+		return SupportedChange{Current: supported}, nil
+	}
+	change := DiffSupported(*previous, supported)
+	if !change.Empty() {
+		for _, handler := range handlers {
+			handler(ctx, change)
+		}
+	}
+	return change, nil
+}
+
+// Run refreshes on every interval until the context is cancelled, then
+// returns the context's error
+func (w *SupportedWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := w.Refresh(ctx); err != nil && w.config.OnError != nil && ctx.Err() == nil {
+				w.config.OnError(ctx, err)
+			}
+		}
+	}
+}
+
+// WatchSupported starts a watcher for each facilitator client that keeps the
+// server's supported kinds current, so requirements built afterwards pick up
+// changes such as a rotated fee payer. The handler (optional) is called for
+// each change. Watchers stop when the context is cancelled. Call after Initialize.
+func (s *x402ResourceServer) WatchSupported(ctx context.Context, config SupportedWatcherConfig, handler SupportedChangeHandler) {
+	for _, client := range s.tempFacilitatorClients {
+		watcher := NewSupportedWatcher(client, config)
+
+		s.supportedCache.mu.RLock()
+		baseline, ok := s.supportedCache.data[supportedCacheKey(client)]
+		s.supportedCache.mu.RUnlock()
+		if ok {
+			watcher.SetBaseline(baseline)
+		}
+
+		watcher.OnChange(func(ctx context.Context, change SupportedChange) {
+			s.mu.Lock()
+			s.applySupportedLocked(client, change.Current)
+			s.mu.Unlock()
+			if handler != nil {
+				handler(ctx, change)
+			}
+		})
+		go func() { _ = watcher.Run(ctx) }()
+	}
+}
+
+// supportedKindKey identifies a kind across refreshes
+type supportedKindKey struct {
+	version int
+	scheme  string
+	network string
+}
+
+func keyOfKind(kind SupportedKind) supportedKindKey {
+	return supportedKindKey{version: kind.X402Version, scheme: kind.Scheme, network: kind.Network}
+}
+
+// extraEqual compares kind extras, treating nil and empty as equal
+func extraEqual(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// stringsMissing returns the values of a not present in b, sorted
+func stringsMissing(a, b []string) []string {
+	present := make(map[string]bool, len(b))
+	for _, value := range b {
+		present[value] = true
+	}
+	var missing []string
+	for _, value := range a {
+		if !present[value] {
+			missing = append(missing, value)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,168 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSolanaNetwork = "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"
+
+func TestDiffSupported(t *testing.T) {
+	previous := SupportedResponse{
+		Kinds: []SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "eip155:8453"},
+			{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+			{X402Version: 2, Scheme: "exact", Network: testSolanaNetwork, Extra: map[string]interface{}{"feePayer": "OldPayer"}},
+		},
+		Extensions: []string{"bazaar"},
+		Signers:    map[string][]string{"solana:*": {"OldPayer"}, "eip155:*": {"0xsigner"}},
+	}
+	current := SupportedResponse{
+		Kinds: []SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: testSolanaNetwork, Extra: map[string]interface{}{"feePayer": "NewPayer"}},
+			{X402Version: 2, Scheme: "exact", Network: "eip155:8453", Extra: map[string]interface{}{}},
+			{X402Version: 2, Scheme: "upto", Network: "eip155:8453"},
+		},
+		Extensions: []string{"bazaar", "sign-in-with-x"},
+		Signers:    map[string][]string{"solana:*": {"NewPayer"}, "eip155:*": {"0xsigner"}},
+	}
+
+	change := DiffSupported(previous, current)
+
+	if len(change.AddedKinds) != 1 || change.AddedKinds[0].Scheme != "upto" {
+		t.Errorf("Expected upto kind added, got %+v", change.AddedKinds)
+	}
+	if len(change.RemovedKinds) != 1 || change.RemovedKinds[0].Network != "eip155:1" {
+		t.Errorf("Expected eip155:1 kind removed, got %+v", change.RemovedKinds)
+	}
+	if len(change.ChangedKinds) != 1 || change.ChangedKinds[0].Extra["feePayer"] != "NewPayer" {
+		t.Errorf("Expected rotated fee payer, got %+v", change.ChangedKinds)
+	}
+	if got := change.AddedSigners["solana:*"]; len(got) != 1 || got[0] != "NewPayer" {
+		t.Errorf("Expected NewPayer signer added, got %v", change.AddedSigners)
+	}
+	if got := change.RemovedSigners["solana:*"]; len(got) != 1 || got[0] != "OldPayer" {
+		t.Errorf("Expected OldPayer signer removed, got %v", change.RemovedSigners)
+	}
+	if len(change.AddedSigners) != 1 || len(change.RemovedSigners) != 1 {
+		t.Errorf("Expected unchanged EVM signers to be omitted, got %v / %v", change.AddedSigners, change.RemovedSigners)
+	}
+	if len(change.AddedExtensions) != 1 || change.AddedExtensions[0] != "sign-in-with-x" || len(change.RemovedExtensions) != 0 {
+		t.Errorf("Unexpected extension changes %v / %v", change.AddedExtensions, change.RemovedExtensions)
+	}
+
+	if !DiffSupported(current, current).Empty() {
+		t.Error("Expected identical responses to produce an empty change")
+	}
+}
+
+func TestSupportedWatcherRefresh(t *testing.T) {
+	ctx := context.Background()
+	client := &mockFacilitatorClient{kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}}}
+
+	var changes []SupportedChange
+	watcher := NewSupportedWatcher(client, SupportedWatcherConfig{}).
+		OnChange(func(ctx context.Context, change SupportedChange) {
+			changes = append(changes, change)
+		})
+
+	// First refresh sets the baseline
+	if _, err := watcher.Refresh(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := watcher.Refresh(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no notifications without changes, got %d", len(changes))
+	}
+
+	client.kinds = append(client.kinds, SupportedKind{X402Version: 2, Scheme: "exact", Network: "eip155:1"})
+	if _, err := watcher.Refresh(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 1 || len(changes[0].AddedKinds) != 1 {
+		t.Fatalf("Expected one notification with an added kind, got %+v", changes)
+	}
+}
+
+// failingSupportedClient fails GetSupported
+type failingSupportedClient struct {
+	mockFacilitatorClient
+}
+
+func (f *failingSupportedClient) GetSupported(ctx context.Context) (SupportedResponse, error) {
+	return SupportedResponse{}, errors.New("facilitator unavailable")
+}
+
+func TestSupportedWatcherKeepsBaselineOnError(t *testing.T) {
+	baseline := SupportedResponse{Kinds: []SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}}}
+	watcher := NewSupportedWatcher(&failingSupportedClient{}, SupportedWatcherConfig{}).SetBaseline(baseline)
+
+	if _, err := watcher.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected refresh error")
+	}
+	if current, ok := watcher.Current(); !ok || len(current.Kinds) != 1 {
+		t.Errorf("Expected baseline to be kept, got %+v", current)
+	}
+}
+
+func TestServerWatchSupportedUpdatesCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	feePayer := "OldPayer"
+	supported := func() []SupportedKind {
+		mu.Lock()
+		defer mu.Unlock()
+		return []SupportedKind{{X402Version: 2, Scheme: "exact", Network: testSolanaNetwork, Extra: map[string]interface{}{"feePayer": feePayer}}}
+	}
+
+	server := Newx402ResourceServer(WithFacilitatorClient(&dynamicSupportedClient{&mockFacilitatorClient{}, supported}))
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	changed := make(chan SupportedChange, 1)
+	server.WatchSupported(ctx, SupportedWatcherConfig{Interval: 5 * time.Millisecond}, func(ctx context.Context, change SupportedChange) {
+		select {
+		case changed <- change:
+		default:
+		}
+	})
+
+	mu.Lock()
+	feePayer = "NewPayer"
+	mu.Unlock()
+
+	select {
+	case change := <-changed:
+		if len(change.ChangedKinds) != 1 {
+			t.Fatalf("Expected changed kind, got %+v", change)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for change notification")
+	}
+
+	server.supportedCache.mu.RLock()
+	defer server.supportedCache.mu.RUnlock()
+	for _, cached := range server.supportedCache.data {
+		if cached.Kinds[0].Extra["feePayer"] != "NewPayer" {
+			t.Errorf("Expected cached fee payer to be rotated, got %v", cached.Kinds[0].Extra)
+		}
+	}
+}
+
+// dynamicSupportedClient returns kinds computed on each call
+type dynamicSupportedClient struct {
+	*mockFacilitatorClient
+	kinds func() []SupportedKind
+}
+
+func (d *dynamicSupportedClient) GetSupported(ctx context.Context) (SupportedResponse, error) {
+	return SupportedResponse{Kinds: d.kinds(), Extensions: []string{}, Signers: map[string][]string{}}, nil
+}
@@ -0,0 +1,238 @@
+// Package sweep moves accumulated payments from hot payTo addresses to cold
+// storage. Each target watches one asset on one network; when the hot balance
+// exceeds the threshold, everything above the reserve is transferred to the
+// cold address and an audit record is written.
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// Record statuses
+const (
+	StatusSwept   = "swept"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Wallet controls a hot address on one network
+type Wallet interface {
+	// Address returns the hot address being swept
+	Address() string
+
+	// Balance returns the address's balance of the asset in atomic units
+	Balance(ctx context.Context, asset string) (*big.Int, error)
+
+	// Transfer sends an amount of the asset to an address and returns the transaction
+	Transfer(ctx context.Context, asset string, to string, amount *big.Int) (string, error)
+}
+
+// Target configures sweeping of one asset on one network
+type Target struct {
+	Network x402.Network
+	Asset   string
+	Wallet  Wallet
+
+	// ColdAddress receives swept funds
+	ColdAddress string
+
+	// Threshold is the hot balance (atomic units) that triggers a sweep (default: any positive balance)
+	Threshold *big.Int
+
+	// Reserve is left in the hot address, e.g. for refunds (default: 0)
+	Reserve *big.Int
+
+	// Interval between sweeps when running on a schedule (default: 1 hour)
+	Interval time.Duration
+}
+
+// Record is an audit entry for one sweep attempt
+type Record struct {
+	Network     x402.Network `json:"network"`
+	Asset       string       `json:"asset"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Balance     string       `json:"balance,omitempty"`
+	Amount      string       `json:"amount,omitempty"`
+	Transaction string       `json:"transaction,omitempty"`
+	Status      string       `json:"status"`
+	Error       string       `json:"error,omitempty"`
+	At          time.Time    `json:"at"`
+}
+
+// AuditLog stores sweep records
+type AuditLog interface {
+	Append(ctx context.Context, record Record) error
+}
+
+// MemoryAuditLog keeps sweep records in memory
+type MemoryAuditLog struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewMemoryAuditLog creates an empty in-memory audit log
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+// Append implements AuditLog
+func (l *MemoryAuditLog) Append(ctx context.Context, record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+	return nil
+}
+
+// Records returns all records, oldest first
+func (l *MemoryAuditLog) Records() []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Record(nil), l.records...)
+}
+
+// Config configures a Sweeper
+type Config struct {
+	Targets []Target
+
+	// Audit receives a record for every sweep attempt (default: in-memory log)
+	Audit AuditLog
+
+	// RecordSkipped also audits sweeps skipped for being under the threshold
+	RecordSkipped bool
+}
+
+// Sweeper runs sweeps for its targets
+type Sweeper struct {
+	targets       []Target
+	audit         AuditLog
+	recordSkipped bool
+	now           func() time.Time
+}
+
+// NewSweeper validates the targets and creates a sweeper
+func NewSweeper(config Config) (*Sweeper, error) {
+	if len(config.Targets) == 0 {
+		return nil, errors.New("sweep: at least one target is required")
+	}
+	targets := make([]Target, len(config.Targets))
+	for i, target := range config.Targets {
+		if target.Wallet == nil {
+			return nil, fmt.Errorf("sweep: target %d (%s) has no wallet", i, target.Network)
+		}
+		if target.Network == "" || target.Asset == "" || target.ColdAddress == "" {
+			return nil, fmt.Errorf("sweep: target %d requires network, asset, and cold address", i)
+		}
+		if strings.EqualFold(target.ColdAddress, target.Wallet.Address()) {
+			return nil, fmt.Errorf("sweep: target %d cold address is the hot address", i)
+		}
+		if target.Interval <= 0 {
+			target.Interval = time.Hour
+		}
+		targets[i] = target
+	}
+
+	audit := config.Audit
+	if audit == nil {
+		audit = NewMemoryAuditLog()
+	}
+
+	return &Sweeper{
+		targets:       targets,
+		audit:         audit,
+		recordSkipped: config.RecordSkipped,
+		now:           time.Now,
+	}, nil
+}
+
+// SweepAll sweeps every target once and returns the records
+func (s *Sweeper) SweepAll(ctx context.Context) []Record {
+	records := make([]Record, 0, len(s.targets))
+	for _, target := range s.targets {
+		records = append(records, s.sweep(ctx, target))
+	}
+	return records
+}
+
+// Run sweeps each target on its own interval until the context is cancelled,
+// then returns the context's error
+func (s *Sweeper) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, target := range s.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(target.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					s.sweep(ctx, target)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// sweep transfers the target's balance above the reserve when it exceeds the threshold
+func (s *Sweeper) sweep(ctx context.Context, target Target) Record {
+	record := Record{
+		Network: target.Network,
+		Asset:   target.Asset,
+		From:    target.Wallet.Address(),
+		To:      target.ColdAddress,
+		At:      s.now(),
+	}
+
+	balance, err := target.Wallet.Balance(ctx, target.Asset)
+	if err != nil {
+		record.Status = StatusFailed
+		record.Error = fmt.Sprintf("failed to read balance: %v", err)
+		return s.write(ctx, record)
+	}
+	record.Balance = balance.String()
+
+	amount := new(big.Int).Set(balance)
+	if target.Reserve != nil {
+		amount.Sub(amount, target.Reserve)
+	}
+	if amount.Sign() <= 0 || (target.Threshold != nil && balance.Cmp(target.Threshold) < 0) {
+		record.Status = StatusSkipped
+		if s.recordSkipped {
+			return s.write(ctx, record)
+		}
+		return record
+	}
+	record.Amount = amount.String()
+
+	tx, err := target.Wallet.Transfer(ctx, target.Asset, target.ColdAddress, amount)
+	record.Transaction = tx
+	if err != nil {
+		record.Status = StatusFailed
+		record.Error = fmt.Sprintf("transfer failed: %v", err)
+	} else {
+		record.Status = StatusSwept
+	}
+	return s.write(ctx, record)
+}
+
+// write appends a record to the audit log. Audit failures are reported on
+// the returned record so they are not lost silently.
+func (s *Sweeper) write(ctx context.Context, record Record) Record {
+	if err := s.audit.Append(ctx, record); err != nil && record.Error == "" {
+		record.Error = fmt.Sprintf("audit failed: %v", err)
+	}
+	return record
+}
@@ -0,0 +1,149 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWallet holds balances in memory
+type fakeWallet struct {
+	mu          sync.Mutex
+	address     string
+	balance     *big.Int
+	transferErr error
+	transfers   []*big.Int
+}
+
+func (w *fakeWallet) Address() string {
+	return w.address
+}
+
+func (w *fakeWallet) Balance(ctx context.Context, asset string) (*big.Int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return new(big.Int).Set(w.balance), nil
+}
+
+func (w *fakeWallet) Transfer(ctx context.Context, asset string, to string, amount *big.Int) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.transferErr != nil {
+		return "", w.transferErr
+	}
+	w.balance.Sub(w.balance, amount)
+	w.transfers = append(w.transfers, amount)
+	return "0xtx", nil
+}
+
+func newTarget(wallet Wallet) Target {
+	return Target{
+		Network:     "eip155:8453",
+		Asset:       "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Wallet:      wallet,
+		ColdAddress: "0xcold",
+		Threshold:   big.NewInt(1_000_000),
+		Reserve:     big.NewInt(100_000),
+	}
+}
+
+func TestSweepAboveThreshold(t *testing.T) {
+	wallet := &fakeWallet{address: "0xhot", balance: big.NewInt(5_000_000)}
+	audit := NewMemoryAuditLog()
+	sweeper, err := NewSweeper(Config{Targets: []Target{newTarget(wallet)}, Audit: audit})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	records := sweeper.SweepAll(context.Background())
+	if len(records) != 1 || records[0].Status != StatusSwept || records[0].Amount != "4900000" || records[0].Transaction != "0xtx" {
+		t.Fatalf("Unexpected records %+v", records)
+	}
+	if wallet.balance.Int64() != 100_000 {
+		t.Errorf("Expected reserve to remain, got %s", wallet.balance)
+	}
+	if logged := audit.Records(); len(logged) != 1 || logged[0].To != "0xcold" || logged[0].From != "0xhot" {
+		t.Errorf("Expected audit record of the sweep, got %+v", logged)
+	}
+}
+
+func TestSweepBelowThreshold(t *testing.T) {
+	wallet := &fakeWallet{address: "0xhot", balance: big.NewInt(500_000)}
+	audit := NewMemoryAuditLog()
+	sweeper, _ := NewSweeper(Config{Targets: []Target{newTarget(wallet)}, Audit: audit})
+
+	if records := sweeper.SweepAll(context.Background()); records[0].Status != StatusSkipped {
+		t.Fatalf("Expected skip, got %+v", records[0])
+	}
+	if len(wallet.transfers) != 0 || len(audit.Records()) != 0 {
+		t.Errorf("Expected no transfer or audit record, got %v / %+v", wallet.transfers, audit.Records())
+	}
+
+	sweeper, _ = NewSweeper(Config{Targets: []Target{newTarget(wallet)}, Audit: audit, RecordSkipped: true})
+	sweeper.SweepAll(context.Background())
+	if logged := audit.Records(); len(logged) != 1 || logged[0].Status != StatusSkipped {
+		t.Errorf("Expected skipped sweep to be audited, got %+v", logged)
+	}
+}
+
+func TestSweepTransferFailureIsAudited(t *testing.T) {
+	wallet := &fakeWallet{address: "0xhot", balance: big.NewInt(5_000_000), transferErr: errors.New("nonce too low")}
+	audit := NewMemoryAuditLog()
+	sweeper, _ := NewSweeper(Config{Targets: []Target{newTarget(wallet)}, Audit: audit})
+
+	sweeper.SweepAll(context.Background())
+	logged := audit.Records()
+	if len(logged) != 1 || logged[0].Status != StatusFailed || logged[0].Error != "transfer failed: nonce too low" {
+		t.Errorf("Expected failed sweep to be audited, got %+v", logged)
+	}
+}
+
+func TestNewSweeperValidatesTargets(t *testing.T) {
+	wallet := &fakeWallet{address: "0xHOT", balance: big.NewInt(0)}
+
+	tests := []struct {
+		name   string
+		target Target
+	}{
+		{"no wallet", Target{Network: "eip155:8453", Asset: "0xusdc", ColdAddress: "0xcold"}},
+		{"no cold address", Target{Network: "eip155:8453", Asset: "0xusdc", Wallet: wallet}},
+		{"cold is hot", Target{Network: "eip155:8453", Asset: "0xusdc", Wallet: wallet, ColdAddress: "0xhot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSweeper(Config{Targets: []Target{tt.target}}); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	if _, err := NewSweeper(Config{}); err == nil {
+		t.Error("Expected error without targets")
+	}
+}
+
+func TestSweeperRunOnSchedule(t *testing.T) {
+	wallet := &fakeWallet{address: "0xhot", balance: big.NewInt(5_000_000)}
+	target := newTarget(wallet)
+	target.Interval = 5 * time.Millisecond
+	audit := NewMemoryAuditLog()
+	sweeper, _ := NewSweeper(Config{Targets: []Target{target}, Audit: audit})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- sweeper.Run(ctx) }()
+
+	for len(audit.Records()) == 0 && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if logged := audit.Records(); len(logged) == 0 || logged[0].Status != StatusSwept {
+		t.Fatalf("Expected a scheduled sweep, got %+v", logged)
+	}
+}
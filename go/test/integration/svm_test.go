@@ -274,7 +274,7 @@ func TestSVMIntegrationV2(t *testing.T) {
 
 		// Setup facilitator with SVM v2 scheme
 		facilitator := x402.Newx402Facilitator()
-		svmFacilitator := svmfacilitator.NewExactSvmScheme(facilitatorSigner)
+		svmFacilitator := svmfacilitator.NewExactSvmScheme(facilitatorSigner, nil)
 		// Register for Solana Devnet
 		facilitator.Register([]x402.Network{svm.SolanaDevnetCAIP2}, svmFacilitator)
 
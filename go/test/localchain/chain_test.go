@@ -0,0 +1,171 @@
+//go:build localchain
+
+// Package localchain_test runs the full client → server → facilitator →
+// settlement loop against local chains: anvil for EVM and
+// solana-test-validator for SVM, each started in a container.
+//
+// The tests are opt-in behind the localchain build tag:
+//
+//	go test -tags localchain ./test/localchain/...
+//
+// Set LOCALCHAIN_EVM_RPC or LOCALCHAIN_SVM_RPC to use an already running
+// node instead of starting a container.
+package localchain_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	chimw "github.com/coinbase/x402/go/http/chi"
+	"github.com/coinbase/x402/go/x402test"
+)
+
+const (
+	defaultAnvilImage  = "ghcr.io/foundry-rs/foundry:latest"
+	defaultSolanaImage = "solanalabs/solana:v1.18.26"
+
+	// startupTimeout bounds how long a node may take to answer RPC
+	startupTimeout = 90 * time.Second
+)
+
+// nodeRPC returns the RPC URL of a local node, starting a container for it
+// unless envVar names an existing one
+func nodeRPC(t *testing.T, envVar string, image string, imageEnvVar string, port string, entrypoint string, args ...string) string {
+	t.Helper()
+	if url := os.Getenv(envVar); url != "" {
+		return url
+	}
+	if override := os.Getenv(imageEnvVar); override != "" {
+		image = override
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skipf("Skipping: docker not found and %s not set", envVar)
+	}
+
+	runArgs := append([]string{"run", "-d", "--rm", "-p", "127.0.0.1::" + port, "--entrypoint", entrypoint, image}, args...)
+	out, err := exec.Command("docker", runArgs...).Output()
+	if err != nil {
+		t.Fatalf("Failed to start %s: %v", image, commandError(err))
+	}
+	id := strings.TrimSpace(string(out))
+	t.Cleanup(func() { _ = exec.Command("docker", "rm", "-f", id).Run() })
+
+	out, err = exec.Command("docker", "port", id, port).Output()
+	if err != nil {
+		t.Fatalf("Failed to read the published port of %s: %v", image, commandError(err))
+	}
+	// docker port prints one line per binding, e.g. "127.0.0.1:49153"
+	address := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	return "http://" + address
+}
+
+// commandError includes a failed command's stderr
+func commandError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// waitForRPC polls a JSON-RPC endpoint with method until it answers
+func waitForRPC(t *testing.T, url string, method string) {
+	t.Helper()
+	deadline := time.Now().Add(startupTimeout)
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[]}`)
+	for {
+		resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Node at %s did not answer %s within %v: %v", url, method, startupTimeout, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// localFacilitatorClient adapts an in-process facilitator to x402.FacilitatorClient
+type localFacilitatorClient struct {
+	facilitator *x402.X402Facilitator
+}
+
+func (l *localFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	return l.facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+}
+
+func (l *localFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	return l.facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+func (l *localFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return l.facilitator.GetSupported(), nil
+}
+
+// paidLoop serves GET /paid behind the x402 middleware, with the facilitator
+// reached over HTTP, and fetches it once with a paying client. It returns
+// the settlement the client received.
+func paidLoop(
+	t *testing.T,
+	network x402.Network,
+	accepts x402http.PaymentOptions,
+	facilitator *x402.X402Facilitator,
+	serverScheme x402.SchemeNetworkServer,
+	clientScheme x402.SchemeNetworkClient,
+) *x402.SettleResponse {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	facilitatorServer, _ := x402test.NewChaosServer(&localFacilitatorClient{facilitator: facilitator}, x402test.FaultConfig{})
+	t.Cleanup(facilitatorServer.Close)
+
+	server := x402http.Newx402HTTPResourceServer(
+		x402http.RoutesConfig{"GET /paid": {Accepts: accepts, Description: "Local chain loop"}},
+		x402.WithFacilitatorClient(x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: facilitatorServer.URL})),
+	)
+	server.Register(network, serverScheme)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	resource := httptest.NewServer(chimw.Middleware(server, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("paid content"))
+	})))
+	t.Cleanup(resource.Close)
+
+	client := x402http.Newx402HTTPClient(x402.Newx402Client().Register(network, clientScheme))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, resource.URL+"/paid", nil)
+	resp, err := x402http.WrapHTTPClientWithPayment(&http.Client{}, client).Do(req)
+	if err != nil {
+		t.Fatalf("Paid request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 after payment, got %d", resp.StatusCode)
+	}
+
+	headers := map[string]string{}
+	for name := range resp.Header {
+		headers[name] = resp.Header.Get(name)
+	}
+	settlement, err := client.GetPaymentSettleResponse(headers)
+	if err != nil {
+		t.Fatalf("Failed to decode the settlement: %v", err)
+	}
+	if !settlement.Success || settlement.Transaction == "" || settlement.Network != network {
+		t.Fatalf("Expected a successful settlement on %s, got %+v", network, settlement)
+	}
+	return settlement
+}
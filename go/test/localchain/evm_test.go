@@ -0,0 +1,287 @@
+//go:build localchain
+
+package localchain_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	evmclient "github.com/coinbase/x402/go/mechanisms/evm/exact/client"
+	evmfacilitator "github.com/coinbase/x402/go/mechanisms/evm/exact/facilitator"
+	evmserver "github.com/coinbase/x402/go/mechanisms/evm/exact/server"
+	evmsigners "github.com/coinbase/x402/go/signers/evm"
+	"github.com/coinbase/x402/go/test/mocks/eip3009"
+)
+
+const (
+	anvilNetwork x402.Network = "eip155:31337"
+
+	// anvilFacilitatorKey is anvil's first prefunded dev account
+	anvilFacilitatorKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+)
+
+// anvilSigner implements evm.FacilitatorEvmSigner and eip3009.Deployer
+// against a local node
+type anvilSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	client  *ethclient.Client
+	chainID *big.Int
+}
+
+func newAnvilSigner(ctx context.Context, rpcURL string, privateKeyHex string) (*anvilSigner, error) {
+	key, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	return &anvilSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey), client: client, chainID: chainID}, nil
+}
+
+func (s *anvilSigner) GetAddresses() []string {
+	return []string{s.address.Hex()}
+}
+
+func (s *anvilSigner) GetChainID(ctx context.Context) (*big.Int, error) {
+	return s.chainID, nil
+}
+
+func (s *anvilSigner) GetCode(ctx context.Context, address string) ([]byte, error) {
+	return s.client.CodeAt(ctx, common.HexToAddress(address), nil)
+}
+
+func (s *anvilSigner) GetBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	if tokenAddress == "" {
+		return s.client.BalanceAt(ctx, common.HexToAddress(address), nil)
+	}
+	result, err := s.ReadContract(ctx, tokenAddress, eip3009.ABI, "balanceOf", common.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := result.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected balance type: %T", result)
+	}
+	return balance, nil
+}
+
+func (s *anvilSigner) ReadContract(ctx context.Context, contractAddress string, abiJSON []byte, method string, args ...interface{}) (interface{}, error) {
+	contractABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack method call: %w", err)
+	}
+	to := common.HexToAddress(contractAddress)
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+	output, err := contractABI.Methods[method].Outputs.Unpack(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack result: %w", err)
+	}
+	if len(output) == 0 {
+		return nil, nil
+	}
+	return output[0], nil
+}
+
+func (s *anvilSigner) WriteContract(ctx context.Context, contractAddress string, abiJSON []byte, method string, args ...interface{}) (string, error) {
+	contractABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack method call: %w", err)
+	}
+	return s.send(ctx, contractAddress, data)
+}
+
+func (s *anvilSigner) SendTransaction(ctx context.Context, to string, data []byte) (string, error) {
+	return s.send(ctx, to, data)
+}
+
+func (s *anvilSigner) DeployContract(ctx context.Context, bytecode []byte) (string, string, error) {
+	nonce, err := s.client.PendingNonceAt(ctx, s.address)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+	txHash, err := s.send(ctx, "", bytecode)
+	if err != nil {
+		return "", "", err
+	}
+	return crypto.CreateAddress(s.address, nonce).Hex(), txHash, nil
+}
+
+// send sends data to to; an empty to deploys data as a contract
+func (s *anvilSigner) send(ctx context.Context, to string, data []byte) (string, error) {
+	nonce, err := s.client.PendingNonceAt(ctx, s.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	var tx *ethtypes.Transaction
+	if to == "" {
+		tx = ethtypes.NewContractCreation(nonce, big.NewInt(0), 1_000_000, gasPrice, data)
+	} else {
+		tx = ethtypes.NewTransaction(nonce, common.HexToAddress(to), big.NewInt(0), 300_000, gasPrice, data)
+	}
+	signed, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(s.chainID), s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := s.client.SendTransaction(ctx, signed); err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signed.Hash().Hex(), nil
+}
+
+func (s *anvilSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	for {
+		receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+		if err == nil {
+			return &evm.TransactionReceipt{
+				Status:            receipt.Status,
+				BlockNumber:       receipt.BlockNumber.Uint64(),
+				TxHash:            receipt.TxHash.Hex(),
+				GasUsed:           receipt.GasUsed,
+				EffectiveGasPrice: receipt.EffectiveGasPrice,
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction receipt not found: %w", ctx.Err())
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func (s *anvilSigner) VerifyTypedData(
+	ctx context.Context,
+	address string,
+	domain evm.TypedDataDomain,
+	types map[string][]evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+	signature []byte,
+) (bool, error) {
+	digest, err := evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return false, err
+	}
+	recovered, err := evm.RecoverSigner(digest, signature)
+	if err != nil {
+		return false, err
+	}
+	return recovered == common.HexToAddress(address), nil
+}
+
+// mustSucceed waits for txHash and fails the test unless it succeeded
+func (s *anvilSigner) mustSucceed(t *testing.T, ctx context.Context, txHash string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	receipt, err := s.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil || receipt.Status != evm.TxStatusSuccess {
+		t.Fatalf("Transaction %s did not succeed: %+v %v", txHash, receipt, err)
+	}
+}
+
+// deployToken deploys the mock EIP-3009 token and registers it as the
+// network's default asset, so "$" prices resolve to it
+func deployToken(t *testing.T, ctx context.Context, signer *anvilSigner) *eip3009.Token {
+	t.Helper()
+	token, err := eip3009.Deploy(ctx, signer)
+	if err != nil {
+		t.Fatalf("Failed to deploy the token: %v", err)
+	}
+
+	previous, registered := evm.NetworkConfigs[string(anvilNetwork)]
+	evm.NetworkConfigs[string(anvilNetwork)] = evm.NetworkConfig{
+		ChainID:      signer.chainID,
+		DefaultAsset: token.AssetInfo(),
+	}
+	t.Cleanup(func() {
+		if registered {
+			evm.NetworkConfigs[string(anvilNetwork)] = previous
+		} else {
+			delete(evm.NetworkConfigs, string(anvilNetwork))
+		}
+	})
+	return token
+}
+
+func TestEVMLocalChain(t *testing.T) {
+	rpcURL := nodeRPC(t, "LOCALCHAIN_EVM_RPC", defaultAnvilImage, "LOCALCHAIN_ANVIL_IMAGE", "8545/tcp", "anvil", "--host", "0.0.0.0")
+	waitForRPC(t, rpcURL, "eth_chainId")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	facilitatorSigner, err := newAnvilSigner(ctx, rpcURL, anvilFacilitatorKey)
+	if err != nil {
+		t.Fatalf("Failed to create facilitator signer: %v", err)
+	}
+	token := deployToken(t, ctx, facilitatorSigner)
+
+	clientKey, _ := crypto.GenerateKey()
+	clientSigner, err := evmsigners.NewClientSignerFromPrivateKey(common.Bytes2Hex(crypto.FromECDSA(clientKey)))
+	if err != nil {
+		t.Fatalf("Failed to create client signer: %v", err)
+	}
+	payer := common.HexToAddress(clientSigner.Address())
+	payTo := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	if _, err := token.Mint(ctx, payer.Hex(), big.NewInt(5_000_000)); err != nil {
+		t.Fatalf("Failed to mint: %v", err)
+	}
+
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{anvilNetwork}, evmfacilitator.NewExactEvmScheme(facilitatorSigner, nil))
+
+	settlement := paidLoop(t, anvilNetwork,
+		x402http.PaymentOptions{{Scheme: evm.SchemeExact, Network: anvilNetwork, PayTo: payTo.Hex(), Price: "$0.25"}},
+		facilitator, evmserver.NewExactEvmScheme(), evmclient.NewExactEvmScheme(clientSigner),
+	)
+
+	facilitatorSigner.mustSucceed(t, ctx, settlement.Transaction, nil)
+	for account, want := range map[common.Address]int64{payer: 4_750_000, payTo: 250_000} {
+		balance, err := token.BalanceOf(ctx, account.Hex())
+		if err != nil || balance.Int64() != want {
+			t.Errorf("Expected %s to hold %d after settlement, got %v (%v)", account.Hex(), want, balance, err)
+		}
+	}
+	if !strings.EqualFold(settlement.Payer, payer.Hex()) {
+		t.Errorf("Expected payer %s, got %s", payer.Hex(), settlement.Payer)
+	}
+}
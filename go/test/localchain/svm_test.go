@@ -0,0 +1,239 @@
+//go:build localchain
+
+package localchain_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	solana "github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/mechanisms/svm"
+	svmclient "github.com/coinbase/x402/go/mechanisms/svm/exact/client"
+	svmfacilitator "github.com/coinbase/x402/go/mechanisms/svm/exact/facilitator"
+	svmserver "github.com/coinbase/x402/go/mechanisms/svm/exact/server"
+	svmsigners "github.com/coinbase/x402/go/signers/svm"
+	"github.com/coinbase/x402/go/x402test"
+)
+
+// validatorSigner implements svm.FacilitatorSvmSigner against a local validator
+type validatorSigner struct {
+	key    solana.PrivateKey
+	client *rpc.Client
+}
+
+func (s *validatorSigner) GetAddresses(ctx context.Context, network string) []solana.PublicKey {
+	return []solana.PublicKey{s.key.PublicKey()}
+}
+
+func (s *validatorSigner) SignTransaction(ctx context.Context, tx *solana.Transaction, feePayer solana.PublicKey, network string) error {
+	if feePayer != s.key.PublicKey() {
+		return fmt.Errorf("no signer for feePayer %s", feePayer)
+	}
+	messageBytes, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	signature, err := s.key.Sign(messageBytes)
+	if err != nil {
+		return fmt.Errorf("failed to sign: %w", err)
+	}
+	index, err := tx.GetAccountIndex(feePayer)
+	if err != nil {
+		return fmt.Errorf("failed to get account index: %w", err)
+	}
+	if len(tx.Signatures) <= int(index) {
+		signatures := make([]solana.Signature, index+1)
+		copy(signatures, tx.Signatures)
+		tx.Signatures = signatures
+	}
+	tx.Signatures[index] = signature
+	return nil
+}
+
+func (s *validatorSigner) SimulateTransaction(ctx context.Context, tx *solana.Transaction, network string) error {
+	result, err := s.client.SimulateTransactionWithOpts(ctx, tx, &rpc.SimulateTransactionOpts{
+		SigVerify:  true,
+		Commitment: svm.CommitmentFromContext(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("simulation failed: %w", err)
+	}
+	if result != nil && result.Value != nil && result.Value.Err != nil {
+		return fmt.Errorf("simulation failed: %v", result.Value.Err)
+	}
+	return nil
+}
+
+func (s *validatorSigner) SendTransaction(ctx context.Context, tx *solana.Transaction, network string) (solana.Signature, error) {
+	return s.client.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
+		SkipPreflight:       true,
+		PreflightCommitment: svm.CommitmentFromContext(ctx),
+	})
+}
+
+func (s *validatorSigner) ConfirmTransaction(ctx context.Context, signature solana.Signature, network string) error {
+	for attempt := 0; attempt < svm.MaxConfirmAttempts; attempt++ {
+		statuses, err := s.client.GetSignatureStatuses(ctx, true, signature)
+		if err == nil && statuses != nil && len(statuses.Value) > 0 && statuses.Value[0] != nil {
+			status := statuses.Value[0]
+			if status.Err != nil {
+				return fmt.Errorf("transaction failed on-chain: %v", status.Err)
+			}
+			if svm.CommitmentReached(status.ConfirmationStatus, svm.CommitmentFromContext(ctx)) {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(svm.ConfirmRetryDelay):
+		}
+	}
+	return fmt.Errorf("transaction confirmation timed out after %d attempts", svm.MaxConfirmAttempts)
+}
+
+// send signs instructions with the facilitator (as fee payer) and extra
+// signers, then waits for confirmation
+func (s *validatorSigner) send(t *testing.T, ctx context.Context, signers []solana.PrivateKey, instructions ...solana.Instruction) {
+	t.Helper()
+	blockhash, err := s.client.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to get blockhash: %v", err)
+	}
+	tx, err := solana.NewTransaction(instructions, blockhash.Value.Blockhash, solana.TransactionPayer(s.key.PublicKey()))
+	if err != nil {
+		t.Fatalf("Failed to build transaction: %v", err)
+	}
+	keys := append([]solana.PrivateKey{s.key}, signers...)
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range keys {
+			if keys[i].PublicKey() == key {
+				return &keys[i]
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+	signature, err := s.SendTransaction(ctx, tx, "")
+	if err == nil {
+		err = s.ConfirmTransaction(ctx, signature, "")
+	}
+	if err != nil {
+		t.Fatalf("Setup transaction failed: %v", err)
+	}
+}
+
+// createMint creates a 6-decimal SPL mint with token accounts for owners,
+// minting supply to the first, and registers it as the network's default asset
+func createMint(t *testing.T, ctx context.Context, signer *validatorSigner, network x402.Network, rpcURL string, supply uint64, owners ...solana.PublicKey) solana.PublicKey {
+	t.Helper()
+	mint := solana.NewWallet().PrivateKey
+	rent, err := signer.client.GetMinimumBalanceForRentExemption(ctx, token.MINT_SIZE, rpc.CommitmentConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to get rent: %v", err)
+	}
+
+	instructions := []solana.Instruction{
+		system.NewCreateAccountInstruction(rent, token.MINT_SIZE, solana.TokenProgramID, signer.key.PublicKey(), mint.PublicKey()).Build(),
+		token.NewInitializeMint2Instruction(svm.DefaultDecimals, signer.key.PublicKey(), signer.key.PublicKey(), mint.PublicKey()).Build(),
+	}
+	for _, owner := range owners {
+		instructions = append(instructions, associatedtokenaccount.NewCreateInstruction(signer.key.PublicKey(), owner, mint.PublicKey()).Build())
+	}
+	source, _, _ := solana.FindAssociatedTokenAddress(owners[0], mint.PublicKey())
+	instructions = append(instructions, token.NewMintToInstruction(supply, mint.PublicKey(), source, signer.key.PublicKey(), nil).Build())
+	signer.send(t, ctx, []solana.PrivateKey{mint}, instructions...)
+
+	previous, registered := svm.NetworkConfigs[string(network)]
+	svm.NetworkConfigs[string(network)] = svm.NetworkConfig{
+		Name:   "Solana Local",
+		CAIP2:  string(network),
+		RPCURL: rpcURL,
+		DefaultAsset: svm.AssetInfo{
+			Address:  mint.PublicKey().String(),
+			Symbol:   "USDC",
+			Decimals: svm.DefaultDecimals,
+		},
+	}
+	t.Cleanup(func() {
+		if registered {
+			svm.NetworkConfigs[string(network)] = previous
+		} else {
+			delete(svm.NetworkConfigs, string(network))
+		}
+	})
+	return mint.PublicKey()
+}
+
+// tokenBalance reads the balance of owner's associated token account
+func tokenBalance(ctx context.Context, client *rpc.Client, owner solana.PublicKey, mint solana.PublicKey) (string, error) {
+	account, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return "", err
+	}
+	balance, err := client.GetTokenAccountBalance(ctx, account, rpc.CommitmentConfirmed)
+	if err != nil {
+		return "", err
+	}
+	return balance.Value.Amount, nil
+}
+
+func TestSVMLocalChain(t *testing.T) {
+	rpcURL := nodeRPC(t, "LOCALCHAIN_SVM_RPC", defaultSolanaImage, "LOCALCHAIN_SOLANA_IMAGE", "8899/tcp", "solana-test-validator",
+		"--ledger", "/tmp/test-ledger", "--bind-address", "0.0.0.0", "--quiet")
+	waitForRPC(t, rpcURL, "getHealth")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	client := rpc.New(rpcURL)
+	genesis, err := client.GetGenesisHash(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get genesis hash: %v", err)
+	}
+	network := x402.Network("solana:" + genesis.String()[:32])
+
+	facilitatorSigner := &validatorSigner{key: solana.NewWallet().PrivateKey, client: client}
+	airdrop, err := x402test.NewSolanaAirdropFaucet(rpcURL, 0).Fund(ctx, network, "", facilitatorSigner.key.PublicKey().String())
+	if err == nil {
+		err = facilitatorSigner.ConfirmTransaction(ctx, solana.MustSignatureFromBase58(airdrop), string(network))
+	}
+	if err != nil {
+		t.Fatalf("Failed to fund the facilitator: %v", err)
+	}
+
+	clientKey := solana.NewWallet().PrivateKey
+	clientSigner, err := svmsigners.NewClientSignerFromPrivateKey(clientKey.String())
+	if err != nil {
+		t.Fatalf("Failed to create client signer: %v", err)
+	}
+	payTo := solana.NewWallet().PublicKey()
+	mint := createMint(t, ctx, facilitatorSigner, network, rpcURL, 5_000_000, clientKey.PublicKey(), payTo)
+
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{network}, svmfacilitator.NewExactSvmScheme(facilitatorSigner, nil))
+
+	settlement := paidLoop(t, network,
+		x402http.PaymentOptions{{Scheme: svm.SchemeExact, Network: network, PayTo: payTo.String(), Price: "$0.25"}},
+		facilitator, svmserver.NewExactSvmScheme(), svmclient.NewExactSvmScheme(clientSigner),
+	)
+
+	if settlement.Payer != clientKey.PublicKey().String() {
+		t.Errorf("Expected payer %s, got %s", clientKey.PublicKey(), settlement.Payer)
+	}
+	for owner, want := range map[solana.PublicKey]string{clientKey.PublicKey(): "4750000", payTo: "250000"} {
+		if balance, err := tokenBalance(ctx, client, owner, mint); err != nil || balance != want {
+			t.Errorf("Expected %s to hold %s after settlement, got %s (%v)", owner, want, balance, err)
+		}
+	}
+}
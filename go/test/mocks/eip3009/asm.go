@@ -0,0 +1,140 @@
+package eip3009
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// EVM opcodes used by the token
+const (
+	opSTOP         byte = 0x00
+	opADD          byte = 0x01
+	opSUB          byte = 0x03
+	opLT           byte = 0x10
+	opGT           byte = 0x11
+	opEQ           byte = 0x14
+	opISZERO       byte = 0x15
+	opAND          byte = 0x16
+	opSHR          byte = 0x1c
+	opKECCAK256    byte = 0x20
+	opADDRESS      byte = 0x30
+	opCALLER       byte = 0x33
+	opCALLDATALOAD byte = 0x35
+	opCALLDATASIZE byte = 0x36
+	opCALLDATACOPY byte = 0x37
+	opCODECOPY     byte = 0x39
+	opEXTCODESIZE  byte = 0x3b
+	opTIMESTAMP    byte = 0x42
+	opCHAINID      byte = 0x46
+	opPOP          byte = 0x50
+	opMLOAD        byte = 0x51
+	opMSTORE       byte = 0x52
+	opMSTORE8      byte = 0x53
+	opSLOAD        byte = 0x54
+	opSSTORE       byte = 0x55
+	opJUMP         byte = 0x56
+	opJUMPI        byte = 0x57
+	opGAS          byte = 0x5a
+	opJUMPDEST     byte = 0x5b
+	opPUSH1        byte = 0x60
+	opPUSH2        byte = 0x61
+	opDUP1         byte = 0x80
+	opSWAP1        byte = 0x90
+	opLOG3         byte = 0xa3
+	opRETURN       byte = 0xf3
+	opSTATICCALL   byte = 0xfa
+	opREVERT       byte = 0xfd
+)
+
+// assembler builds EVM bytecode with named jump labels
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string // offset of a PUSH2 operand -> label
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, refs: map[int]string{}}
+}
+
+// op appends opcodes
+func (a *assembler) op(codes ...byte) *assembler {
+	a.code = append(a.code, codes...)
+	return a
+}
+
+// push appends the smallest PUSH of value (at least PUSH1)
+func (a *assembler) push(value []byte) *assembler {
+	for len(value) > 1 && value[0] == 0 {
+		value = value[1:]
+	}
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	a.code = append(a.code, opPUSH1+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+// pushInt appends the smallest PUSH of n
+func (a *assembler) pushInt(n uint64) *assembler {
+	return a.push(new(big.Int).SetUint64(n).Bytes())
+}
+
+// pushWord appends a PUSH32 of a 32-byte word
+func (a *assembler) pushWord(word [32]byte) *assembler {
+	a.code = append(a.code, opPUSH1+31)
+	a.code = append(a.code, word[:]...)
+	return a
+}
+
+// pushLabel appends a PUSH2 of a label's offset, resolved by bytecode
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, opPUSH2)
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+// dup appends DUPn
+func (a *assembler) dup(n int) *assembler {
+	return a.op(opDUP1 + byte(n-1))
+}
+
+// swap appends SWAPn
+func (a *assembler) swap(n int) *assembler {
+	return a.op(opSWAP1 + byte(n-1))
+}
+
+// label marks a jump destination
+func (a *assembler) label(name string) *assembler {
+	if _, exists := a.labels[name]; exists {
+		panic(fmt.Sprintf("eip3009: duplicate label %s", name))
+	}
+	a.labels[name] = len(a.code)
+	return a.op(opJUMPDEST)
+}
+
+// jump jumps to a label
+func (a *assembler) jump(name string) *assembler {
+	return a.pushLabel(name).op(opJUMP)
+}
+
+// jumpi jumps to a label when the top of the stack is non-zero
+func (a *assembler) jumpi(name string) *assembler {
+	return a.pushLabel(name).op(opJUMPI)
+}
+
+// bytecode resolves labels and returns the code
+func (a *assembler) bytecode() []byte {
+	code := append([]byte(nil), a.code...)
+	for offset, name := range a.refs {
+		target, ok := a.labels[name]
+		if !ok {
+			panic(fmt.Sprintf("eip3009: undefined label %s", name))
+		}
+		code[offset] = byte(target >> 8)
+		code[offset+1] = byte(target)
+	}
+	return code
+}
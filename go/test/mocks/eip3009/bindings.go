@@ -0,0 +1,196 @@
+package eip3009
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// ABI is the token's JSON ABI. transferWithAuthorization is overloaded; the
+// bytes signature form is also available alone as
+// evm.TransferWithAuthorizationBytesABI.
+var ABI = []byte(`[
+	{"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"name":"symbol","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"name":"version","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"name":"totalSupply","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"name":"transfer","type":"function","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"name":"mint","type":"function","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[]},
+	{"name":"DOMAIN_SEPARATOR","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+	{"name":"authorizationState","type":"function","stateMutability":"view","inputs":[{"name":"authorizer","type":"address"},{"name":"nonce","type":"bytes32"}],"outputs":[{"name":"","type":"bool"}]},
+	{"name":"transferWithAuthorization","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},
+		{"name":"validAfter","type":"uint256"},{"name":"validBefore","type":"uint256"},{"name":"nonce","type":"bytes32"},
+		{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]},
+	{"name":"transferWithAuthorization","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},
+		{"name":"validAfter","type":"uint256"},{"name":"validBefore","type":"uint256"},{"name":"nonce","type":"bytes32"},
+		{"name":"signature","type":"bytes"}],"outputs":[]},
+	{"name":"Transfer","type":"event","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"name":"AuthorizationUsed","type":"event","anonymous":false,"inputs":[
+		{"name":"authorizer","type":"address","indexed":true},{"name":"nonce","type":"bytes32","indexed":true}]}
+]`)
+
+// Backend reads and writes contracts on a chain. Every
+// evm.FacilitatorEvmSigner is a Backend.
+type Backend interface {
+	ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error)
+	WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error)
+	WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error)
+}
+
+// Deployer is a Backend that can also create contracts
+type Deployer interface {
+	Backend
+
+	// DeployContract sends a contract creation transaction for bytecode and
+	// returns the new contract's address and the transaction hash
+	DeployContract(ctx context.Context, bytecode []byte) (address string, txHash string, err error)
+}
+
+// Token is a deployed test token. Its write methods wait for the
+// transaction to be mined and fail if it reverted.
+type Token struct {
+	address string
+	backend Backend
+}
+
+// Deploy deploys a new token and waits for it to be mined
+func Deploy(ctx context.Context, deployer Deployer) (*Token, error) {
+	address, txHash, err := deployer.DeployContract(ctx, Bytecode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy token: %w", err)
+	}
+	if err := waitForSuccess(ctx, deployer, txHash); err != nil {
+		return nil, fmt.Errorf("failed to deploy token: %w", err)
+	}
+	return NewToken(address, deployer), nil
+}
+
+// NewToken binds a token already deployed at address
+func NewToken(address string, backend Backend) *Token {
+	return &Token{address: address, backend: backend}
+}
+
+// Address returns the token's address
+func (t *Token) Address() string {
+	return t.address
+}
+
+// AssetInfo describes the token for evm.NetworkConfig's DefaultAsset, so
+// "$" prices on a local network resolve to it
+func (t *Token) AssetInfo() evm.AssetInfo {
+	return evm.AssetInfo{
+		Address:  t.address,
+		Name:     Name,
+		Version:  Version,
+		Decimals: Decimals,
+	}
+}
+
+// BalanceOf returns account's balance in atomic units
+func (t *Token) BalanceOf(ctx context.Context, account string) (*big.Int, error) {
+	return t.readUint(ctx, "balanceOf", common.HexToAddress(account))
+}
+
+// TotalSupply returns the amount minted so far in atomic units
+func (t *Token) TotalSupply(ctx context.Context) (*big.Int, error) {
+	return t.readUint(ctx, "totalSupply")
+}
+
+// AuthorizationState reports whether authorizer's nonce (0x-prefixed
+// bytes32) has been used
+func (t *Token) AuthorizationState(ctx context.Context, authorizer string, nonce string) (bool, error) {
+	nonceBytes, err := evm.HexToBytes(nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return false, errors.New("invalid nonce format")
+	}
+	result, err := t.backend.ReadContract(ctx, t.address, ABI, "authorizationState", common.HexToAddress(authorizer), [32]byte(nonceBytes))
+	if err != nil {
+		return false, err
+	}
+	used, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected authorizationState result type: %T", result)
+	}
+	return used, nil
+}
+
+// Mint credits value atomic units to to. Anyone may mint.
+func (t *Token) Mint(ctx context.Context, to string, value *big.Int) (string, error) {
+	return t.write(ctx, ABI, "mint", common.HexToAddress(to), value)
+}
+
+// Transfer moves value atomic units from the backend's account to to
+func (t *Token) Transfer(ctx context.Context, to string, value *big.Int) (string, error) {
+	return t.write(ctx, ABI, "transfer", common.HexToAddress(to), value)
+}
+
+// TransferWithAuthorization settles a signed EIP-3009 authorization
+// through the bytes signature form, which accepts both EOA (65-byte) and
+// EIP-1271 contract signatures
+func (t *Token) TransferWithAuthorization(ctx context.Context, authorization evm.ExactEIP3009Authorization, signature []byte) (string, error) {
+	value, ok := new(big.Int).SetString(authorization.Value, 10)
+	if !ok {
+		return "", errors.New("invalid authorization value")
+	}
+	validAfter, ok := new(big.Int).SetString(authorization.ValidAfter, 10)
+	if !ok {
+		return "", errors.New("invalid validAfter")
+	}
+	validBefore, ok := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok {
+		return "", errors.New("invalid validBefore")
+	}
+	nonceBytes, err := evm.HexToBytes(authorization.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return "", errors.New("invalid nonce format")
+	}
+	return t.write(ctx, evm.TransferWithAuthorizationBytesABI, evm.FunctionTransferWithAuthorization,
+		common.HexToAddress(authorization.From), common.HexToAddress(authorization.To),
+		value, validAfter, validBefore, [32]byte(nonceBytes), signature,
+	)
+}
+
+func (t *Token) readUint(ctx context.Context, method string, args ...interface{}) (*big.Int, error) {
+	result, err := t.backend.ReadContract(ctx, t.address, ABI, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := result.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s result type: %T", method, result)
+	}
+	return value, nil
+}
+
+func (t *Token) write(ctx context.Context, abi []byte, method string, args ...interface{}) (string, error) {
+	txHash, err := t.backend.WriteContract(ctx, t.address, abi, method, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", method, err)
+	}
+	if err := waitForSuccess(ctx, t.backend, txHash); err != nil {
+		return txHash, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return txHash, nil
+}
+
+// waitForSuccess waits for txHash to be mined and checks it did not revert
+func waitForSuccess(ctx context.Context, backend Backend, txHash string) error {
+	receipt, err := backend.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return fmt.Errorf("transaction %s reverted", txHash)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package eip3009
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+// chainBackend implements Deployer on a testChain, sending as sender
+type chainBackend struct {
+	chain    *testChain
+	sender   common.Address
+	receipts map[string]*evm.TransactionReceipt
+}
+
+func newChainBackend(chain *testChain, sender common.Address) *chainBackend {
+	return &chainBackend{chain: chain, sender: sender, receipts: map[string]*evm.TransactionReceipt{}}
+}
+
+func (b *chainBackend) ReadContract(ctx context.Context, address string, abiJSON []byte, method string, args ...interface{}) (interface{}, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	ret, _, err := b.chain.call(b.sender, common.HexToAddress(address), nil, input)
+	if err != nil {
+		return nil, err
+	}
+	out, err := contractABI.Methods[method].Outputs.Unpack(ret)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func (b *chainBackend) WriteContract(ctx context.Context, address string, abiJSON []byte, method string, args ...interface{}) (string, error) {
+	contractABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return "", err
+	}
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return "", err
+	}
+	_, _, err = b.chain.call(b.sender, common.HexToAddress(address), nil, input)
+	return b.mine(err), nil
+}
+
+func (b *chainBackend) DeployContract(ctx context.Context, bytecode []byte) (string, string, error) {
+	address, err := b.chain.deploy(bytecode)
+	return address.Hex(), b.mine(err), nil
+}
+
+// mine records a receipt for a transaction that failed with err (or not)
+func (b *chainBackend) mine(err error) string {
+	txHash := common.BigToHash(big.NewInt(int64(len(b.receipts) + 1))).Hex()
+	status := uint64(evm.TxStatusSuccess)
+	if err != nil {
+		status = evm.TxStatusFailed
+	}
+	b.receipts[txHash] = &evm.TransactionReceipt{Status: status, TxHash: txHash}
+	return txHash
+}
+
+func (b *chainBackend) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction %s", txHash)
+	}
+	return receipt, nil
+}
+
+func TestBindings(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(testChainID, 1000)
+	key, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(key.PublicKey)
+	facilitator := newChainBackend(chain, common.HexToAddress("0xfac"))
+
+	token, err := Deploy(ctx, facilitator)
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if info := token.AssetInfo(); info.Address != token.Address() || info.Name != Name || info.Version != Version || info.Decimals != Decimals {
+		t.Errorf("Unexpected asset info %+v", info)
+	}
+
+	if _, err := token.Mint(ctx, payer.Hex(), big.NewInt(1_000_000)); err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if _, err := NewToken(token.Address(), newChainBackend(chain, payer)).Transfer(ctx, "0xb0b", big.NewInt(100_000)); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	authorization := evm.ExactEIP3009Authorization{
+		From:        payer.Hex(),
+		To:          "0x00000000000000000000000000000000005e11e7",
+		Value:       "250000",
+		ValidAfter:  "0",
+		ValidBefore: "2000",
+		Nonce:       common.BytesToHash([]byte{1}).Hex(),
+	}
+	tk := &testToken{t: t, chain: chain, address: common.HexToAddress(token.Address())}
+	signature := tk.sign(key, authorization)
+	if _, err := token.TransferWithAuthorization(ctx, authorization, signature); err != nil {
+		t.Fatalf("TransferWithAuthorization failed: %v", err)
+	}
+	if used, err := token.AuthorizationState(ctx, payer.Hex(), authorization.Nonce); err != nil || !used {
+		t.Errorf("Expected the nonce to be used, got %v %v", used, err)
+	}
+	if txHash, err := token.TransferWithAuthorization(ctx, authorization, signature); err == nil || txHash == "" {
+		t.Errorf("Expected a replay to fail with its transaction hash, got %q %v", txHash, err)
+	}
+
+	for account, want := range map[string]int64{payer.Hex(): 650_000, "0xb0b": 100_000, authorization.To: 250_000} {
+		if balance, err := token.BalanceOf(ctx, account); err != nil || balance.Int64() != want {
+			t.Errorf("Expected %s to hold %d, got %v %v", account, want, balance, err)
+		}
+	}
+	if supply, err := token.TotalSupply(ctx); err != nil || supply.Int64() != 1_000_000 {
+		t.Errorf("Expected supply 1000000, got %v %v", supply, err)
+	}
+}
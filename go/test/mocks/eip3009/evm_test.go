@@ -0,0 +1,259 @@
+package eip3009
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testChain is a minimal EVM covering the opcodes the token uses, so the
+// bytecode can be exercised without a node. Static calls reach the
+// ecrecover precompile or other deployed code.
+type testChain struct {
+	chainID   *big.Int
+	timestamp uint64
+	code      map[common.Address][]byte
+	storage   map[common.Address]map[common.Hash]*big.Int
+	deployed  uint64
+}
+
+// testLog is an emitted event
+type testLog struct {
+	topics []common.Hash
+	data   []byte
+}
+
+var (
+	errReverted = errors.New("execution reverted")
+	tt256       = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+func newTestChain(chainID int64, timestamp uint64) *testChain {
+	return &testChain{
+		chainID:   big.NewInt(chainID),
+		timestamp: timestamp,
+		code:      map[common.Address][]byte{},
+		storage:   map[common.Address]map[common.Hash]*big.Int{},
+	}
+}
+
+// deploy runs init code and installs the returned runtime code
+func (c *testChain) deploy(initCode []byte) (common.Address, error) {
+	c.deployed++
+	address := common.BigToAddress(new(big.Int).SetUint64(0x3009_0000 + c.deployed))
+	runtime, _, err := c.call(common.Address{}, address, initCode, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	c.code[address] = runtime
+	return address, nil
+}
+
+// call executes to's code; storage writes are discarded on revert
+func (c *testChain) call(caller common.Address, to common.Address, code []byte, input []byte) ([]byte, []testLog, error) {
+	if code == nil {
+		code = c.code[to]
+	}
+	snapshot := map[common.Hash]*big.Int{}
+	for k, v := range c.storage[to] {
+		snapshot[k] = v
+	}
+	if c.storage[to] == nil {
+		c.storage[to] = map[common.Hash]*big.Int{}
+	}
+	ret, logs, err := c.run(caller, to, code, input)
+	if err != nil {
+		c.storage[to] = snapshot
+		return nil, nil, err
+	}
+	return ret, logs, nil
+}
+
+func (c *testChain) run(caller common.Address, self common.Address, code []byte, input []byte) ([]byte, []testLog, error) {
+	var (
+		stack []*big.Int
+		mem   []byte
+		logs  []testLog
+	)
+	push := func(v *big.Int) { stack = append(stack, new(big.Int).Mod(v, tt256)) }
+	pop := func() *big.Int {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+	memory := func(offset, size *big.Int) []byte {
+		end := int(offset.Int64() + size.Int64())
+		if end > len(mem) {
+			mem = append(mem, make([]byte, end-len(mem))...)
+		}
+		return mem[offset.Int64():end]
+	}
+	word := func(b []byte) *big.Int { return new(big.Int).SetBytes(b) }
+
+	for pc := 0; pc < len(code); pc++ {
+		op := code[pc]
+		switch {
+		case op >= opPUSH1 && op <= opPUSH1+31:
+			n := int(op-opPUSH1) + 1
+			push(word(code[pc+1 : pc+1+n]))
+			pc += n
+			continue
+		case op >= opDUP1 && op <= opDUP1+15:
+			push(stack[len(stack)-1-int(op-opDUP1)])
+			continue
+		case op >= opSWAP1 && op <= opSWAP1+15:
+			i, j := len(stack)-1, len(stack)-2-int(op-opSWAP1)
+			stack[i], stack[j] = stack[j], stack[i]
+			continue
+		}
+
+		if len(stack) > 1024 {
+			return nil, nil, fmt.Errorf("stack overflow at pc %d", pc)
+		}
+		switch op {
+		case opSTOP:
+			return nil, logs, nil
+		case opADD:
+			a, b := pop(), pop()
+			push(new(big.Int).Add(a, b))
+		case opSUB:
+			a, b := pop(), pop()
+			push(new(big.Int).Sub(a, b))
+		case opLT, opGT, opEQ:
+			a, b := pop(), pop()
+			cmp := a.Cmp(b)
+			if (op == opLT && cmp < 0) || (op == opGT && cmp > 0) || (op == opEQ && cmp == 0) {
+				push(big.NewInt(1))
+			} else {
+				push(big.NewInt(0))
+			}
+		case opISZERO:
+			if pop().Sign() == 0 {
+				push(big.NewInt(1))
+			} else {
+				push(big.NewInt(0))
+			}
+		case opAND:
+			a, b := pop(), pop()
+			push(new(big.Int).And(a, b))
+		case opSHR:
+			shift, value := pop(), pop()
+			push(new(big.Int).Rsh(value, uint(shift.Uint64())))
+		case opKECCAK256:
+			offset, size := pop(), pop()
+			push(word(crypto.Keccak256(memory(offset, size))))
+		case opADDRESS:
+			push(word(self.Bytes()))
+		case opCALLER:
+			push(word(caller.Bytes()))
+		case opCALLDATALOAD:
+			offset := int(pop().Int64())
+			var data [32]byte
+			if offset < len(input) {
+				copy(data[:], input[offset:])
+			}
+			push(word(data[:]))
+		case opCALLDATASIZE:
+			push(big.NewInt(int64(len(input))))
+		case opCALLDATACOPY, opCODECOPY:
+			dest, offset, size := pop(), pop(), pop()
+			source := input
+			if op == opCODECOPY {
+				source = code
+			}
+			out := memory(dest, size)
+			for i := range out {
+				out[i] = 0
+				if src := int(offset.Int64()) + i; src < len(source) {
+					out[i] = source[src]
+				}
+			}
+		case opEXTCODESIZE:
+			push(big.NewInt(int64(len(c.code[common.BigToAddress(pop())]))))
+		case opTIMESTAMP:
+			push(new(big.Int).SetUint64(c.timestamp))
+		case opCHAINID:
+			push(c.chainID)
+		case opPOP:
+			pop()
+		case opMLOAD:
+			push(word(memory(pop(), big.NewInt(32))))
+		case opMSTORE:
+			offset, value := pop(), pop()
+			copy(memory(offset, big.NewInt(32)), common.LeftPadBytes(value.Bytes(), 32))
+		case opMSTORE8:
+			offset, value := pop(), pop()
+			memory(offset, big.NewInt(1))[0] = byte(value.Uint64())
+		case opSLOAD:
+			value, ok := c.storage[self][common.BigToHash(pop())]
+			if !ok {
+				value = new(big.Int)
+			}
+			push(value)
+		case opSSTORE:
+			key, value := pop(), pop()
+			c.storage[self][common.BigToHash(key)] = value
+		case opJUMP, opJUMPI:
+			dest := pop()
+			if op == opJUMPI && pop().Sign() == 0 {
+				continue
+			}
+			if !dest.IsInt64() || dest.Int64() >= int64(len(code)) || code[dest.Int64()] != opJUMPDEST {
+				return nil, nil, fmt.Errorf("invalid jump from pc %d", pc)
+			}
+			pc = int(dest.Int64())
+		case opGAS:
+			push(big.NewInt(1_000_000))
+		case opJUMPDEST:
+		case opLOG3:
+			offset, size := pop(), pop()
+			topics := []common.Hash{common.BigToHash(pop()), common.BigToHash(pop()), common.BigToHash(pop())}
+			logs = append(logs, testLog{topics: topics, data: append([]byte(nil), memory(offset, size)...)})
+		case opRETURN:
+			offset, size := pop(), pop()
+			return append([]byte(nil), memory(offset, size)...), logs, nil
+		case opSTATICCALL:
+			_, address, argsOffset, argsSize, retOffset, retSize := pop(), pop(), pop(), pop(), pop(), pop()
+			args := append([]byte(nil), memory(argsOffset, argsSize)...)
+			var (
+				ret []byte
+				err error
+			)
+			if address.Cmp(big.NewInt(1)) == 0 {
+				ret = ecrecover(args)
+			} else {
+				ret, _, err = c.call(self, common.BigToAddress(address), nil, args)
+			}
+			if err != nil {
+				push(big.NewInt(0))
+				continue
+			}
+			copy(memory(retOffset, retSize), ret)
+			push(big.NewInt(1))
+		case opREVERT:
+			return nil, nil, errReverted
+		default:
+			return nil, nil, fmt.Errorf("unsupported opcode 0x%02x at pc %d", op, pc)
+		}
+	}
+	return nil, logs, nil
+}
+
+// ecrecover mirrors the precompile: a padded address, or nil on bad input
+func ecrecover(input []byte) []byte {
+	padded := make([]byte, 128)
+	copy(padded, input)
+	v := new(big.Int).SetBytes(padded[32:64])
+	if v.Cmp(big.NewInt(27)) != 0 && v.Cmp(big.NewInt(28)) != 0 {
+		return nil
+	}
+	sig := append(append([]byte(nil), padded[64:128]...), byte(v.Uint64()-27))
+	pub, err := crypto.Ecrecover(padded[:32], sig)
+	if err != nil {
+		return nil
+	}
+	return common.LeftPadBytes(crypto.Keccak256(pub[1:])[12:], 32)
+}
@@ -0,0 +1,322 @@
+// Package eip3009 provides a minimal EIP-3009 test token for local chains.
+//
+// The token is hand-assembled so it can be built and deployed without a
+// Solidity toolchain. It exposes the USDC surface the exact EVM scheme uses:
+// name "USDC", version "2", 6 decimals, balanceOf, transfer, an open mint,
+// DOMAIN_SEPARATOR, authorizationState and transferWithAuthorization in both
+// its (v, r, s) and bytes signature forms. As in USDC, an authorization whose
+// from address holds code is checked with EIP-1271 isValidSignature instead
+// of ecrecover, so smart wallet payments can be settled too.
+// It is a test fixture only: mint is unrestricted and arithmetic is unchecked
+// beyond the sender's balance.
+package eip3009
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Token metadata, matching USDC so the default EIP-712 domain applies
+const (
+	Name     = "USDC"
+	Symbol   = "USDC"
+	Version  = "2"
+	Decimals = 6
+)
+
+// Storage slots, laid out as the equivalent Solidity contract would
+const (
+	balancesSlot           = 0 // mapping(address => uint256)
+	totalSupplySlot        = 1 // uint256
+	authorizationStateSlot = 2 // mapping(address => mapping(bytes32 => bool))
+)
+
+// Memory used while checking an authorization's signature. The signature is
+// kept at signatureMemory, which is where isValidSignature's bytes argument
+// lands in calldata laid out from isValidSignatureMemory.
+const (
+	isValidSignatureMemory = 0x300
+	signatureMemory        = isValidSignatureMemory + 0x64
+)
+
+var (
+	// RuntimeBytecode is the code the token runs once deployed
+	RuntimeBytecode = assembleRuntime()
+
+	// Bytecode is the deployment bytecode: a constructor that returns RuntimeBytecode
+	Bytecode = assembleConstructor(RuntimeBytecode)
+
+	// TransferTopic is the Transfer(address,address,uint256) event topic
+	TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// AuthorizationUsedTopic is the AuthorizationUsed(address,bytes32) event topic
+	AuthorizationUsedTopic = crypto.Keccak256Hash([]byte("AuthorizationUsed(address,bytes32)"))
+
+	// TransferWithAuthorizationTypeHash is the EIP-3009 struct type hash
+	TransferWithAuthorizationTypeHash = crypto.Keccak256Hash([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
+
+	// EIP712DomainTypeHash is the type hash of the token's EIP-712 domain
+	EIP712DomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+)
+
+var (
+	// isValidSignatureSelector is EIP-1271's isValidSignature(bytes32,bytes)
+	// selector, which is also its magic return value
+	isValidSignatureSelector = selector("isValidSignature(bytes32,bytes)")
+
+	// isValidSignatureWord is isValidSignatureSelector left-aligned in a word
+	isValidSignatureWord = [32]byte(append(append([]byte(nil), isValidSignatureSelector...), make([]byte, 28)...))
+)
+
+// selector returns the 4-byte function selector of a signature
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// assembleConstructor copies runtime into memory and returns it
+func assembleConstructor(runtime []byte) []byte {
+	const constructorSize = 13
+	size := []byte{byte(len(runtime) >> 8), byte(len(runtime))}
+	code := []byte{opPUSH2, size[0], size[1], opDUP1, opPUSH2, 0, constructorSize, opPUSH1, 0, opCODECOPY, opPUSH1, 0, opRETURN}
+	return append(code, runtime...)
+}
+
+// assembleRuntime builds the token's dispatcher and function bodies
+func assembleRuntime() []byte {
+	a := newAssembler()
+
+	functions := []struct {
+		signature string
+		label     string
+	}{
+		{"name()", "name"},
+		{"symbol()", "symbol"},
+		{"version()", "version"},
+		{"decimals()", "decimals"},
+		{"totalSupply()", "totalSupply"},
+		{"balanceOf(address)", "balanceOf"},
+		{"transfer(address,uint256)", "transfer"},
+		{"mint(address,uint256)", "mint"},
+		{"DOMAIN_SEPARATOR()", "domainSeparator"},
+		{"authorizationState(address,bytes32)", "authorizationState"},
+		{"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)", "transferWithAuthorization"},
+		{"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,bytes)", "transferWithAuthorizationBytes"},
+	}
+
+	// Dispatch on the selector; short calldata and unknown selectors revert
+	a.pushInt(4).op(opCALLDATASIZE, opLT).jumpi("revert")
+	a.pushInt(0).op(opCALLDATALOAD).pushInt(0xe0).op(opSHR)
+	for _, fn := range functions {
+		a.dup(1).push(selector(fn.signature)).op(opEQ).jumpi(fn.label)
+	}
+	a.label("revert").pushInt(0).dup(1).op(opREVERT)
+
+	// Metadata
+	for _, field := range [][2]string{{"name", Name}, {"symbol", Symbol}, {"version", Version}} {
+		a.label(field[0])
+		returnString(a, field[1])
+	}
+	a.label("decimals").pushInt(Decimals)
+	returnWord(a)
+
+	// ERC-20 reads
+	a.label("totalSupply").pushInt(totalSupplySlot).op(opSLOAD)
+	returnWord(a)
+	a.label("balanceOf")
+	addressArg(a, 0)
+	balanceSlot(a)
+	a.op(opSLOAD)
+	returnWord(a)
+
+	// transfer(to, value) moves the caller's balance
+	a.label("transfer").op(opCALLER)
+	addressArg(a, 0)
+	arg(a, 1)
+	move(a)
+	a.pushInt(1)
+	returnWord(a)
+
+	// mint(to, value) credits anyone; this is a test token
+	a.label("mint")
+	arg(a, 1)
+	addressArg(a, 0)
+	balanceSlot(a)                                       // [value, slot]
+	a.dup(1).op(opSLOAD).dup(3).op(opADD)                // [value, slot, balance+value]
+	a.swap(1).op(opSSTORE)                               // [value]
+	a.dup(1).pushInt(totalSupplySlot).op(opSLOAD, opADD) // [value, supply+value]
+	a.pushInt(totalSupplySlot).op(opSSTORE)              // [value]
+	a.pushInt(0).op(opMSTORE)
+	addressArg(a, 0)
+	a.pushInt(0).pushWord(TransferTopic).pushInt(0x20).pushInt(0).op(opLOG3, opSTOP)
+
+	// EIP-712 domain separator, computed so it tracks the chain and address
+	a.label("domainSeparator")
+	domainSeparator(a)
+	returnWord(a)
+
+	a.label("authorizationState")
+	arg(a, 1)
+	addressArg(a, 0)
+	authorizationSlot(a)
+	a.op(opSLOAD)
+	returnWord(a)
+
+	// transferWithAuthorization(from, to, value, validAfter, validBefore, nonce, v, r, s)
+	// packs the signature as r . s . v at signatureMemory
+	a.label("transferWithAuthorization")
+	arg(a, 7)
+	a.pushInt(signatureMemory).op(opMSTORE)
+	arg(a, 8)
+	a.pushInt(signatureMemory + 0x20).op(opMSTORE)
+	arg(a, 6)
+	a.pushInt(signatureMemory + 0x40).op(opMSTORE8)
+	a.pushInt(65).jump("authorize") // [signatureLength]
+
+	// transferWithAuthorization(from, to, value, validAfter, validBefore, nonce, signature)
+	// copies the signature bytes to signatureMemory
+	a.label("transferWithAuthorizationBytes")
+	arg(a, 6)
+	a.pushInt(4).op(opADD)                                                              // [lengthOffset]
+	a.dup(1).op(opCALLDATALOAD)                                                         // [lengthOffset, signatureLength]
+	a.dup(1).dup(3).pushInt(0x20).op(opADD).pushInt(signatureMemory).op(opCALLDATACOPY) // [lengthOffset, signatureLength]
+	a.swap(1).op(opPOP)                                                                 // [signatureLength]
+
+	a.label("authorize")
+	arg(a, 3)
+	a.op(opTIMESTAMP, opGT, opISZERO).jumpi("revert") // now > validAfter
+	arg(a, 4)
+	a.op(opTIMESTAMP, opLT, opISZERO).jumpi("revert") // now < validBefore
+	arg(a, 5)
+	addressArg(a, 0)
+	authorizationSlot(a)
+	a.op(opSLOAD).jumpi("revert") // nonce unused
+
+	// structHash = keccak256(typeHash . from . to . value . validAfter . validBefore . nonce)
+	a.pushWord(TransferWithAuthorizationTypeHash).pushInt(0x80).op(opMSTORE)
+	a.pushInt(0xc0).pushInt(4).pushInt(0xa0).op(opCALLDATACOPY)
+	a.pushInt(0xe0).pushInt(0x80).op(opKECCAK256) // [signatureLength, structHash]
+
+	// digest = keccak256(0x1901 . domainSeparator . structHash), laid out from 0x1e
+	a.pushInt(0x40).op(opMSTORE)
+	domainSeparator(a)
+	a.pushInt(0x20).op(opMSTORE)
+	a.pushInt(0x1901).pushInt(0).op(opMSTORE)
+	a.pushInt(0x42).pushInt(0x1e).op(opKECCAK256)
+	a.pushInt(0).op(opMSTORE) // [signatureLength], digest at 0
+
+	// Like USDC's SignatureChecker, contract signers are asked through EIP-1271
+	addressArg(a, 0)
+	a.op(opEXTCODESIZE).jumpi("contractSignature")
+
+	// ecrecover(digest, v, r, s) must return from
+	a.pushInt(65).op(opEQ, opISZERO).jumpi("revert")
+	a.pushInt(signatureMemory + 0x40).op(opMLOAD).pushInt(0xf8).op(opSHR).pushInt(0x20).op(opMSTORE)
+	a.pushInt(signatureMemory).op(opMLOAD).pushInt(0x40).op(opMSTORE)
+	a.pushInt(signatureMemory + 0x20).op(opMLOAD).pushInt(0x60).op(opMSTORE)
+	a.pushInt(0).pushInt(0x80).op(opMSTORE)
+	a.pushInt(0x20).pushInt(0x80).pushInt(0x80).pushInt(0).pushInt(1).op(opGAS, opSTATICCALL, opISZERO).jumpi("revert")
+	a.pushInt(0x80).op(opMLOAD)
+	a.dup(1).op(opISZERO).jumpi("revert")
+	addressArg(a, 0)
+	a.op(opEQ, opISZERO).jumpi("revert")
+	a.jump("authorized")
+
+	// from.isValidSignature(digest, signature) must return the EIP-1271 magic
+	// value; its calldata is laid out so the signature is already in place
+	a.label("contractSignature")
+	a.pushWord(isValidSignatureWord).pushInt(isValidSignatureMemory).op(opMSTORE)
+	a.pushInt(0).op(opMLOAD).pushInt(isValidSignatureMemory + 0x04).op(opMSTORE)
+	a.pushInt(0x40).pushInt(isValidSignatureMemory + 0x24).op(opMSTORE)
+	a.dup(1).pushInt(isValidSignatureMemory + 0x44).op(opMSTORE)
+	a.pushInt(0x64).op(opADD) // [argsSize]
+	a.pushInt(0).pushInt(0).op(opMSTORE)
+	a.pushInt(0x20).swap(1).pushInt(0).swap(1).pushInt(isValidSignatureMemory)
+	addressArg(a, 0)
+	a.op(opGAS, opSTATICCALL, opISZERO).jumpi("revert")
+	a.pushInt(0).op(opMLOAD).pushInt(0xe0).op(opSHR).push(isValidSignatureSelector).op(opEQ, opISZERO).jumpi("revert")
+
+	a.label("authorized")
+	// Consume the nonce, then move the funds
+	a.pushInt(1)
+	arg(a, 5)
+	addressArg(a, 0)
+	authorizationSlot(a)
+	a.op(opSSTORE)
+	arg(a, 5)
+	addressArg(a, 0)
+	a.pushWord(AuthorizationUsedTopic).pushInt(0).pushInt(0).op(opLOG3)
+	addressArg(a, 0)
+	addressArg(a, 1)
+	arg(a, 2)
+	move(a)
+	a.op(opSTOP)
+
+	return a.bytecode()
+}
+
+// arg pushes the i-th 32-byte calldata argument
+func arg(a *assembler, i int) {
+	a.pushInt(uint64(4 + 32*i)).op(opCALLDATALOAD)
+}
+
+// addressArg pushes the i-th calldata argument masked to an address
+func addressArg(a *assembler, i int) {
+	arg(a, i)
+	a.push([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}).op(opAND)
+}
+
+// returnWord returns the top of the stack as a single word
+func returnWord(a *assembler) {
+	a.pushInt(0).op(opMSTORE).pushInt(0x20).pushInt(0).op(opRETURN)
+}
+
+// returnString returns an ABI-encoded string of at most 32 bytes
+func returnString(a *assembler, value string) {
+	var word [32]byte
+	copy(word[:], value)
+	a.pushInt(0x20).pushInt(0).op(opMSTORE)
+	a.pushInt(uint64(len(value))).pushInt(0x20).op(opMSTORE)
+	a.pushWord(word).pushInt(0x40).op(opMSTORE)
+	a.pushInt(0x60).pushInt(0).op(opRETURN)
+}
+
+// balanceSlot replaces an address on the stack with its balance slot
+func balanceSlot(a *assembler) {
+	a.pushInt(0).op(opMSTORE)
+	a.pushInt(balancesSlot).pushInt(0x20).op(opMSTORE)
+	a.pushInt(0x40).pushInt(0).op(opKECCAK256)
+}
+
+// authorizationSlot replaces [nonce, authorizer] with the authorization state slot
+func authorizationSlot(a *assembler) {
+	a.pushInt(0).op(opMSTORE)
+	a.pushInt(authorizationStateSlot).pushInt(0x20).op(opMSTORE)
+	a.pushInt(0x40).pushInt(0).op(opKECCAK256)
+	a.pushInt(0x20).op(opMSTORE)
+	a.pushInt(0).op(opMSTORE)
+	a.pushInt(0x40).pushInt(0).op(opKECCAK256)
+}
+
+// domainSeparator pushes the EIP-712 domain separator, using memory from 0x200
+func domainSeparator(a *assembler) {
+	a.pushWord(EIP712DomainTypeHash).pushInt(0x200).op(opMSTORE)
+	a.pushWord(crypto.Keccak256Hash([]byte(Name))).pushInt(0x220).op(opMSTORE)
+	a.pushWord(crypto.Keccak256Hash([]byte(Version))).pushInt(0x240).op(opMSTORE)
+	a.op(opCHAINID).pushInt(0x260).op(opMSTORE)
+	a.op(opADDRESS).pushInt(0x280).op(opMSTORE)
+	a.pushInt(0xa0).pushInt(0x200).op(opKECCAK256)
+}
+
+// move consumes [from, to, value], moving value between balances and
+// emitting Transfer; it reverts when from's balance is short
+func move(a *assembler) {
+	a.dup(3)
+	balanceSlot(a)
+	a.dup(1).op(opSLOAD)                            // [from, to, value, fromSlot, fromBalance]
+	a.dup(1).dup(4).op(opGT).jumpi("revert")        // value > fromBalance
+	a.dup(3).swap(1).op(opSUB).swap(1).op(opSSTORE) // [from, to, value]
+	a.dup(2)
+	balanceSlot(a)
+	a.dup(1).op(opSLOAD).dup(3).op(opADD).swap(1).op(opSSTORE)
+	a.pushInt(0).op(opMSTORE) // [from, to]
+	a.swap(1).pushWord(TransferTopic).pushInt(0x20).pushInt(0).op(opLOG3)
+}
@@ -0,0 +1,297 @@
+package eip3009
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/coinbase/x402/go/mechanisms/evm"
+)
+
+const testChainID = 31337
+
+// testToken is the token deployed on a testChain
+type testToken struct {
+	t       *testing.T
+	chain   *testChain
+	address common.Address
+	abi     abi.ABI
+}
+
+func deployTestToken(t *testing.T, timestamp uint64) *testToken {
+	t.Helper()
+	chain := newTestChain(testChainID, timestamp)
+	address, err := chain.deploy(Bytecode)
+	if err != nil {
+		t.Fatalf("Failed to deploy: %v", err)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(ABI))
+	if err != nil {
+		t.Fatalf("Failed to parse ABI: %v", err)
+	}
+	return &testToken{t: t, chain: chain, address: address, abi: parsed}
+}
+
+// send calls method from caller, packed with contractABI
+func (tk *testToken) send(caller common.Address, contractABI abi.ABI, method string, args ...interface{}) ([]byte, []testLog, error) {
+	tk.t.Helper()
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		tk.t.Fatalf("Failed to pack %s: %v", method, err)
+	}
+	return tk.chain.call(caller, tk.address, nil, input)
+}
+
+// read calls a view method and unpacks its single output
+func (tk *testToken) read(method string, args ...interface{}) interface{} {
+	tk.t.Helper()
+	ret, _, err := tk.send(common.Address{}, tk.abi, method, args...)
+	if err != nil {
+		tk.t.Fatalf("%s failed: %v", method, err)
+	}
+	out, err := tk.abi.Methods[method].Outputs.Unpack(ret)
+	if err != nil {
+		tk.t.Fatalf("Failed to unpack %s: %v", method, err)
+	}
+	return out[0]
+}
+
+func (tk *testToken) balanceOf(account common.Address) *big.Int {
+	tk.t.Helper()
+	return tk.read("balanceOf", account).(*big.Int)
+}
+
+// sign signs authorization for the token like the exact EVM client, with v as 27 or 28
+func (tk *testToken) sign(key *ecdsa.PrivateKey, authorization evm.ExactEIP3009Authorization) []byte {
+	tk.t.Helper()
+	digest, err := evm.HashEIP3009Authorization(authorization, big.NewInt(testChainID), tk.address.Hex(), Name, Version)
+	if err != nil {
+		tk.t.Fatalf("Failed to hash authorization: %v", err)
+	}
+	sig, err := crypto.Sign(digest, key)
+	if err != nil {
+		tk.t.Fatalf("Failed to sign: %v", err)
+	}
+	sig[64] += 27
+	return sig
+}
+
+// walletRuntime assembles an EIP-1271 wallet whose isValidSignature accepts
+// 65-byte signatures by owner
+func walletRuntime(owner common.Address) []byte {
+	a := newAssembler()
+	a.pushInt(0).op(opCALLDATALOAD).pushInt(0xe0).op(opSHR).push(isValidSignatureSelector).op(opEQ, opISZERO).jumpi("revert")
+	arg(a, 0)
+	a.pushInt(0).op(opMSTORE)
+	arg(a, 1)
+	a.pushInt(0x24).op(opADD) // signature data
+	a.dup(1).op(opCALLDATALOAD).pushInt(0x40).op(opMSTORE)
+	a.dup(1).pushInt(0x20).op(opADD, opCALLDATALOAD).pushInt(0x60).op(opMSTORE)
+	a.pushInt(0x40).op(opADD, opCALLDATALOAD).pushInt(0xf8).op(opSHR).pushInt(0x20).op(opMSTORE)
+	a.pushInt(0x20).pushInt(0x80).pushInt(0x80).pushInt(0).pushInt(1).op(opGAS, opSTATICCALL, opPOP)
+	a.pushInt(0x80).op(opMLOAD).push(owner.Bytes()).op(opEQ, opISZERO).jumpi("revert")
+	a.pushWord(isValidSignatureWord).pushInt(0).op(opMSTORE).pushInt(0x20).pushInt(0).op(opRETURN)
+	a.label("revert").pushInt(0).dup(1).op(opREVERT)
+	return a.bytecode()
+}
+
+func TestDeploy(t *testing.T) {
+	tk := deployTestToken(t, 1000)
+	if !bytes.Equal(tk.chain.code[tk.address], RuntimeBytecode) {
+		t.Fatal("Expected the constructor to install the runtime bytecode")
+	}
+	if name, symbol, version := tk.read("name"), tk.read("symbol"), tk.read("version"); name != Name || symbol != Symbol || version != Version {
+		t.Errorf("Unexpected metadata %v %v %v", name, symbol, version)
+	}
+	if decimals := tk.read("decimals"); decimals != uint8(Decimals) {
+		t.Errorf("Expected %d decimals, got %v", Decimals, decimals)
+	}
+	if _, _, err := tk.chain.call(common.Address{}, tk.address, nil, []byte{0xde, 0xad, 0xbe, 0xef}); err != errReverted {
+		t.Errorf("Expected an unknown selector to revert, got %v", err)
+	}
+}
+
+func TestMintAndTransfer(t *testing.T) {
+	tk := deployTestToken(t, 1000)
+	alice, bob := common.HexToAddress("0xa11ce"), common.HexToAddress("0xb0b")
+
+	_, logs, err := tk.send(bob, tk.abi, "mint", alice, big.NewInt(5_000_000))
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if len(logs) != 1 || logs[0].topics[0] != TransferTopic || logs[0].topics[1] != (common.Hash{}) || logs[0].topics[2] != common.BytesToHash(alice.Bytes()) {
+		t.Errorf("Expected a Transfer from the zero address, got %+v", logs)
+	}
+
+	ret, logs, err := tk.send(alice, tk.abi, "transfer", bob, big.NewInt(1_500_000))
+	if err != nil || new(big.Int).SetBytes(ret).Int64() != 1 || len(logs) != 1 {
+		t.Fatalf("Transfer failed: %x %v", ret, err)
+	}
+	if a, b := tk.balanceOf(alice), tk.balanceOf(bob); a.Int64() != 3_500_000 || b.Int64() != 1_500_000 {
+		t.Errorf("Unexpected balances %s %s", a, b)
+	}
+	if supply := tk.read("totalSupply").(*big.Int); supply.Int64() != 5_000_000 {
+		t.Errorf("Expected supply 5000000, got %s", supply)
+	}
+
+	if _, _, err := tk.send(bob, tk.abi, "transfer", alice, big.NewInt(1_500_001)); err != errReverted {
+		t.Errorf("Expected an overdraft to revert, got %v", err)
+	}
+	if b := tk.balanceOf(bob); b.Int64() != 1_500_000 {
+		t.Errorf("Expected the reverted transfer to leave %s unchanged", b)
+	}
+}
+
+func TestTransferWithAuthorization(t *testing.T) {
+	tk := deployTestToken(t, 1000)
+	facilitatorABI, err := abi.JSON(bytes.NewReader(evm.TransferWithAuthorizationVRSABI))
+	if err != nil {
+		t.Fatalf("Failed to parse ABI: %v", err)
+	}
+	stateABI, _ := abi.JSON(bytes.NewReader(evm.AuthorizationStateABI))
+
+	key, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(key.PublicKey)
+	payTo := common.HexToAddress("0x5e11e7")
+	if _, _, err := tk.send(payer, tk.abi, "mint", payer, big.NewInt(1_000_000)); err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+
+	// authorize signs like the exact EVM client and returns transferWithAuthorization's arguments
+	authorize := func(signer *ecdsa.PrivateKey, value int64, validAfter, validBefore int64, nonce byte) []interface{} {
+		t.Helper()
+		authorization := evm.ExactEIP3009Authorization{
+			From:        payer.Hex(),
+			To:          payTo.Hex(),
+			Value:       big.NewInt(value).String(),
+			ValidAfter:  big.NewInt(validAfter).String(),
+			ValidBefore: big.NewInt(validBefore).String(),
+			Nonce:       common.BytesToHash([]byte{nonce}).Hex(),
+		}
+		sig := tk.sign(signer, authorization)
+		return []interface{}{
+			payer, payTo, big.NewInt(value), big.NewInt(validAfter), big.NewInt(validBefore),
+			[32]byte(common.BytesToHash([]byte{nonce})), sig[64], [32]byte(sig[:32]), [32]byte(sig[32:64]),
+		}
+	}
+	settle := func(args []interface{}) ([]testLog, error) {
+		t.Helper()
+		_, logs, err := tk.send(common.HexToAddress("0xfac"), facilitatorABI, evm.FunctionTransferWithAuthorization, args...)
+		return logs, err
+	}
+
+	args := authorize(key, 250_000, 0, 2000, 1)
+	logs, err := settle(args)
+	if err != nil {
+		t.Fatalf("Expected the authorization to settle, got %v", err)
+	}
+	if len(logs) != 2 || logs[0].topics[0] != AuthorizationUsedTopic || logs[1].topics[0] != TransferTopic {
+		t.Errorf("Expected AuthorizationUsed then Transfer, got %+v", logs)
+	}
+	if p, r := tk.balanceOf(payer), tk.balanceOf(payTo); p.Int64() != 750_000 || r.Int64() != 250_000 {
+		t.Errorf("Unexpected balances %s %s", p, r)
+	}
+	used, _, _ := tk.send(common.Address{}, stateABI, evm.FunctionAuthorizationState, payer, [32]byte(common.BytesToHash([]byte{1})))
+	if new(big.Int).SetBytes(used).Int64() != 1 {
+		t.Error("Expected the nonce to be marked used")
+	}
+
+	other, _ := crypto.GenerateKey()
+	tampered := authorize(key, 1, 0, 2000, 6)
+	tampered[2] = big.NewInt(2)
+	for name, args := range map[string][]interface{}{
+		"replayed nonce":  args,
+		"expired":         authorize(key, 1, 0, 1000, 2),
+		"not yet valid":   authorize(key, 1, 1000, 2000, 3),
+		"wrong signer":    authorize(other, 1, 0, 2000, 4),
+		"overdraft":       authorize(key, 750_001, 0, 2000, 5),
+		"tampered amount": tampered,
+	} {
+		if _, err := settle(args); err != errReverted {
+			t.Errorf("Expected %s to revert, got %v", name, err)
+		}
+	}
+	if p := tk.balanceOf(payer); p.Int64() != 750_000 {
+		t.Errorf("Expected rejected authorizations to leave %s unchanged", p)
+	}
+}
+
+func TestTransferWithAuthorizationBytes(t *testing.T) {
+	tk := deployTestToken(t, 1000)
+	bytesABI, _ := abi.JSON(bytes.NewReader(evm.TransferWithAuthorizationBytesABI))
+	vrsABI, _ := abi.JSON(bytes.NewReader(evm.TransferWithAuthorizationVRSABI))
+
+	owner, _ := crypto.GenerateKey()
+	eoa := crypto.PubkeyToAddress(owner.PublicKey)
+	wallet, err := tk.chain.deploy(assembleConstructor(walletRuntime(eoa)))
+	if err != nil {
+		t.Fatalf("Failed to deploy wallet: %v", err)
+	}
+	payTo := common.HexToAddress("0x5e11e7")
+	for _, account := range []common.Address{eoa, wallet} {
+		if _, _, err := tk.send(account, tk.abi, "mint", account, big.NewInt(1_000_000)); err != nil {
+			t.Fatalf("Mint failed: %v", err)
+		}
+	}
+
+	nonce := byte(0)
+	authorization := func(from common.Address) evm.ExactEIP3009Authorization {
+		nonce++
+		return evm.ExactEIP3009Authorization{
+			From: from.Hex(), To: payTo.Hex(), Value: "100000", ValidAfter: "0", ValidBefore: "2000",
+			Nonce: common.BytesToHash([]byte{nonce}).Hex(),
+		}
+	}
+	settle := func(auth evm.ExactEIP3009Authorization, sig []byte, vrs bool) error {
+		t.Helper()
+		from, to := common.HexToAddress(auth.From), common.HexToAddress(auth.To)
+		nonce := [32]byte(common.HexToHash(auth.Nonce))
+		if vrs {
+			_, _, err := tk.send(common.HexToAddress("0xfac"), vrsABI, evm.FunctionTransferWithAuthorization,
+				from, to, big.NewInt(100_000), big.NewInt(0), big.NewInt(2000), nonce, sig[64], [32]byte(sig[:32]), [32]byte(sig[32:64]))
+			return err
+		}
+		_, _, err := tk.send(common.HexToAddress("0xfac"), bytesABI, evm.FunctionTransferWithAuthorization,
+			from, to, big.NewInt(100_000), big.NewInt(0), big.NewInt(2000), nonce, sig)
+		return err
+	}
+
+	// Three settlements succeed: an EOA's bytes signature, and the wallet's in both forms
+	auth := authorization(eoa)
+	if err := settle(auth, tk.sign(owner, auth), false); err != nil {
+		t.Errorf("Expected an EOA bytes signature to settle, got %v", err)
+	}
+	settled := authorization(wallet)
+	settledSig := tk.sign(owner, settled)
+	if err := settle(settled, settledSig, false); err != nil {
+		t.Errorf("Expected an EIP-1271 bytes signature to settle, got %v", err)
+	}
+	auth = authorization(wallet)
+	if err := settle(auth, tk.sign(owner, auth), true); err != nil {
+		t.Errorf("Expected an EIP-1271 v, r, s signature to settle, got %v", err)
+	}
+	if w, r := tk.balanceOf(wallet), tk.balanceOf(payTo); w.Int64() != 800_000 || r.Int64() != 300_000 {
+		t.Errorf("Unexpected balances %s %s", w, r)
+	}
+
+	other, _ := crypto.GenerateKey()
+	auth = authorization(eoa)
+	long := append(tk.sign(owner, auth), 0)
+	walletAuth := authorization(wallet)
+	for name, err := range map[string]error{
+		"long EOA signature":     settle(auth, long, false),
+		"wallet rejection":       settle(walletAuth, tk.sign(other, walletAuth), false),
+		"wallet rejection (vrs)": settle(walletAuth, tk.sign(other, walletAuth), true),
+		"empty EOA signature":    settle(authorization(eoa), nil, false),
+		"wallet replayed nonce":  settle(settled, settledSig, false),
+	} {
+		if err != errReverted {
+			t.Errorf("Expected %s to revert, got %v", name, err)
+		}
+	}
+}
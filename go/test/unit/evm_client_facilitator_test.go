@@ -2,11 +2,19 @@ package unit_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402 "github.com/coinbase/x402/go"
 	"github.com/coinbase/x402/go/mechanisms/evm"
 	evmclient "github.com/coinbase/x402/go/mechanisms/evm/exact/client"
 	evmfacilitator "github.com/coinbase/x402/go/mechanisms/evm/exact/facilitator"
@@ -60,6 +68,10 @@ type mockFacilitatorSigner struct {
 	verifyTypedDataError   error
 	code                   []byte
 	authorizationStateUsed bool
+	paused                 *bool
+	blacklisted            map[string]bool
+	receiptGasUsed         uint64
+	receiptGasPrice        *big.Int
 }
 
 func (m *mockFacilitatorSigner) GetAddresses() []string {
@@ -107,6 +119,16 @@ func (m *mockFacilitatorSigner) ReadContract(
 	case "isValidSignature":
 		// EIP-1271 magic value
 		return []byte{0x16, 0x26, 0xba, 0x7e}, nil
+	case "paused":
+		if m.paused == nil {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		return *m.paused, nil
+	case "isBlacklisted":
+		if m.blacklisted == nil {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		return m.blacklisted[strings.ToLower(fmt.Sprint(args[0]))], nil
 	default:
 		return nil, fmt.Errorf("unsupported function: %s", functionName)
 	}
@@ -141,9 +163,11 @@ func (m *mockFacilitatorSigner) WaitForTransactionReceipt(ctx context.Context, t
 		status = evm.TxStatusSuccess
 	}
 	return &evm.TransactionReceipt{
-		Status:      status,
-		BlockNumber: 1,
-		TxHash:      txHash,
+		Status:            status,
+		BlockNumber:       1,
+		TxHash:            txHash,
+		GasUsed:           m.receiptGasUsed,
+		EffectiveGasPrice: m.receiptGasPrice,
 	}, nil
 }
 
@@ -234,6 +258,39 @@ func TestCreatePaymentPayloadEIP3009(t *testing.T) {
 		}
 	})
 
+	t.Run("Honors the validity window and MaxTimeoutSeconds", func(t *testing.T) {
+		requirements := types.PaymentRequirements{
+			Scheme:            evm.SchemeExact,
+			Network:           "eip155:84532",
+			Asset:             "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+			Amount:            "1000000",
+			PayTo:             "0x9876543210987654321098765432109876543210",
+			MaxTimeoutSeconds: 300,
+		}
+		window := func(client *evmclient.ExactEvmScheme) (int64, int64) {
+			payload, err := client.CreatePaymentPayload(ctx, requirements)
+			if err != nil {
+				t.Fatalf("Failed to create payload: %v", err)
+			}
+			eip3009Payload, err := evm.PayloadFromMap(payload.Payload)
+			if err != nil {
+				t.Fatalf("Failed to parse payload: %v", err)
+			}
+			now := time.Now().Unix()
+			validAfter, _ := strconv.ParseInt(eip3009Payload.Authorization.ValidAfter, 10, 64)
+			validBefore, _ := strconv.ParseInt(eip3009Payload.Authorization.ValidBefore, 10, 64)
+			return now - validAfter, validBefore - now
+		}
+
+		if buffer, validFor := window(client); buffer < 29 || buffer > 31 || validFor < 299 || validFor > 300 {
+			t.Errorf("Expected a 30s buffer and the 300s server timeout, got %d and %d", buffer, validFor)
+		}
+		configured := evmclient.NewExactEvmScheme(signer).SetValidityWindow(evm.ValidityWindowConfig{ValidAfterBuffer: 5 * time.Second, ValidFor: time.Minute})
+		if buffer, validFor := window(configured); buffer < 4 || buffer > 6 || validFor < 59 || validFor > 60 {
+			t.Errorf("Expected a 5s buffer and a 60s window, got %d and %d", buffer, validFor)
+		}
+	})
+
 	t.Run("Fails for invalid network", func(t *testing.T) {
 		requirements := types.PaymentRequirements{
 			Scheme:  evm.SchemeExact,
@@ -760,3 +817,533 @@ func TestExactEvmFacilitatorScheme(t *testing.T) {
 		}
 	})
 }
+
+// testPermit2Payment builds a Permit2 payment from payer to payTo that the
+// mock facilitator signer verifies (smart wallet signature via EIP-1271)
+func testPermit2Payment(payer, payTo string) (types.PaymentPayload, types.PaymentRequirements) {
+	requirements := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:84532",
+		Asset:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		Amount:  "1000000",
+		PayTo:   payTo,
+	}
+	// Smart wallet signature, verified through the mocked EIP-1271 call
+	permit2Payload := &evm.ExactPermit2Payload{
+		Signature: "0x" + strings.Repeat("00", 66),
+		Permit2Authorization: evm.Permit2Authorization{
+			From:    payer,
+			Spender: evm.X402ExactPermit2ProxyAddress,
+			Permitted: evm.Permit2TokenPermissions{
+				Token:  requirements.Asset,
+				Amount: "1000000",
+			},
+			Nonce:    "12345",
+			Deadline: "9999999999",
+			Witness: evm.Permit2Witness{
+				To:         payTo,
+				ValidAfter: "0",
+				Extra:      "0x",
+			},
+		},
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     permit2Payload.ToMap(),
+	}
+	return payload, requirements
+}
+
+func TestExactEvmFacilitatorTokenRestrictions(t *testing.T) {
+	ctx := context.Background()
+	payer := "0x1234567890123456789012345678901234567890"
+	payTo := "0x9876543210987654321098765432109876543210"
+	payload, requirements := testPermit2Payment(payer, payTo)
+
+	paused, notPaused := true, false
+	tests := []struct {
+		name     string
+		signer   *mockFacilitatorSigner
+		disabled bool
+		reason   string
+	}{
+		{name: "token without restriction views", signer: &mockFacilitatorSigner{}},
+		{name: "unrestricted token", signer: &mockFacilitatorSigner{paused: &notPaused, blacklisted: map[string]bool{}}},
+		{name: "paused token", signer: &mockFacilitatorSigner{paused: &paused}, reason: evmfacilitator.ErrTokenPaused},
+		{name: "blacklisted payer", signer: &mockFacilitatorSigner{blacklisted: map[string]bool{payer: true}}, reason: evmfacilitator.ErrPayerBlacklisted},
+		{name: "blacklisted payee", signer: &mockFacilitatorSigner{blacklisted: map[string]bool{payTo: true}}, reason: evmfacilitator.ErrRecipientBlacklisted},
+		{name: "checks disabled", signer: &mockFacilitatorSigner{paused: &paused}, disabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.signer.code = []byte{0x01}
+			scheme := evmfacilitator.NewExactEvmScheme(tt.signer, &evmfacilitator.ExactEvmSchemeConfig{
+				CheckTokenRestrictions: !tt.disabled,
+			})
+
+			resp, err := scheme.Verify(ctx, payload, requirements)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("Expected payment to verify, got %v", err)
+				}
+				if !resp.IsValid {
+					t.Error("Expected payment to be valid")
+				}
+				return
+			}
+
+			ve, ok := err.(*x402.VerifyError)
+			if !ok {
+				t.Fatalf("Expected VerifyError, got %v", err)
+			}
+			if ve.InvalidReason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, ve.InvalidReason)
+			}
+			if ve.Payer != payer {
+				t.Errorf("Expected payer %s, got %s", payer, ve.Payer)
+			}
+		})
+	}
+}
+
+// mockGasEstimatingSigner adds evm.GasEstimator to the mock facilitator signer
+type mockGasEstimatingSigner struct {
+	*mockFacilitatorSigner
+	gas    uint64
+	err    error
+	writes int
+}
+
+func (m *mockGasEstimatingSigner) EstimateContractGas(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (uint64, error) {
+	return m.gas, m.err
+}
+
+func (m *mockGasEstimatingSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	m.writes++
+	return m.mockFacilitatorSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+func TestExactEvmFacilitatorGasPreflight(t *testing.T) {
+	ctx := context.Background()
+	payload, requirements := testPermit2Payment(
+		"0x1234567890123456789012345678901234567890",
+		"0x9876543210987654321098765432109876543210",
+	)
+
+	tests := []struct {
+		name        string
+		gas         uint64
+		estimateErr error
+		maxGas      uint64
+		disabled    bool
+		reason      string
+	}{
+		{name: "sends when the estimate fits the cap", gas: 90000, maxGas: 100000},
+		{name: "sends without a cap", gas: 900000},
+		{name: "rejects an estimate above the cap", gas: 150000, maxGas: 100000, reason: evmfacilitator.ErrGasLimitExceeded},
+		{name: "rejects a reverting estimate", estimateErr: fmt.Errorf("execution reverted: InvalidNonce"), reason: evmfacilitator.ErrGasEstimationFailed},
+		{name: "skips estimation when disabled", gas: 150000, maxGas: 100000, disabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &mockGasEstimatingSigner{
+				mockFacilitatorSigner: &mockFacilitatorSigner{code: []byte{0x01}},
+				gas:                   tt.gas,
+				err:                   tt.estimateErr,
+			}
+			var events []evmfacilitator.GasEstimateEvent
+			scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{
+				EstimateGas:      !tt.disabled,
+				MaxSettlementGas: tt.maxGas,
+				OnGasEstimate: func(_ context.Context, event evmfacilitator.GasEstimateEvent) {
+					events = append(events, event)
+				},
+			})
+
+			resp, err := scheme.Settle(ctx, payload, requirements)
+
+			if tt.disabled {
+				if len(events) != 0 {
+					t.Errorf("Expected no gas estimates, got %d", len(events))
+				}
+			} else if len(events) != 1 || events[0].Function != evm.FunctionSettle || events[0].Gas != tt.gas {
+				t.Errorf("Expected one settle gas estimate of %d, got %+v", tt.gas, events)
+			}
+
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("Expected settlement to succeed, got %v", err)
+				}
+				if !resp.Success || signer.writes != 1 {
+					t.Errorf("Expected one successful write, got %d", signer.writes)
+				}
+				return
+			}
+
+			se, ok := err.(*x402.SettleError)
+			if !ok {
+				t.Fatalf("Expected SettleError, got %v", err)
+			}
+			if se.ErrorReason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, se.ErrorReason)
+			}
+			if signer.writes != 0 {
+				t.Errorf("Expected no transaction to be sent, got %d", signer.writes)
+			}
+			var preflightErr *evmfacilitator.GasPreflightError
+			if !errors.As(events[0].Err, &preflightErr) || preflightErr.Reason != tt.reason {
+				t.Errorf("Expected GasPreflightError with reason %s in event, got %v", tt.reason, events[0].Err)
+			}
+		})
+	}
+
+	t.Run("fails when the signer cannot estimate gas", func(t *testing.T) {
+		scheme := evmfacilitator.NewExactEvmScheme(&mockFacilitatorSigner{code: []byte{0x01}}, &evmfacilitator.ExactEvmSchemeConfig{
+			EstimateGas: true,
+		})
+		_, err := scheme.Settle(ctx, payload, requirements)
+		se, ok := err.(*x402.SettleError)
+		if !ok || se.ErrorReason != evmfacilitator.ErrGasEstimationFailed {
+			t.Errorf("Expected %s, got %v", evmfacilitator.ErrGasEstimationFailed, err)
+		}
+	})
+}
+
+// mockGasPricingSigner adds evm.GasPriceReader to the gas estimating mock
+type mockGasPricingSigner struct {
+	*mockGasEstimatingSigner
+	price *big.Int
+}
+
+func (m *mockGasPricingSigner) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return m.price, nil
+}
+
+func TestExactEvmFacilitatorSimulate(t *testing.T) {
+	ctx := context.Background()
+	payload, requirements := testPermit2Payment(
+		"0x1234567890123456789012345678901234567890",
+		"0x9876543210987654321098765432109876543210",
+	)
+
+	t.Run("projects gas and fee without sending", func(t *testing.T) {
+		estimator := &mockGasEstimatingSigner{
+			mockFacilitatorSigner: &mockFacilitatorSigner{code: []byte{0x01}},
+			gas:                   86000,
+		}
+		signer := &mockGasPricingSigner{mockGasEstimatingSigner: estimator, price: big.NewInt(1000000)}
+		scheme := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		resp, err := scheme.Simulate(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected simulation to succeed, got %v", err)
+		}
+		if !resp.IsValid || resp.GasUsed != 86000 {
+			t.Errorf("Expected 86000 gas, got %+v", resp)
+		}
+		if resp.GasPrice != "1000000" || resp.Fee != "86000000000" {
+			t.Errorf("Expected fee 86000000000 at price 1000000, got %s at %s", resp.Fee, resp.GasPrice)
+		}
+		if estimator.writes != 0 {
+			t.Errorf("Expected no transaction to be sent, got %d", estimator.writes)
+		}
+	})
+
+	t.Run("omits the fee without a gas price", func(t *testing.T) {
+		signer := &mockGasEstimatingSigner{
+			mockFacilitatorSigner: &mockFacilitatorSigner{code: []byte{0x01}},
+			gas:                   86000,
+		}
+		resp, err := evmfacilitator.NewExactEvmScheme(signer, nil).Simulate(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected simulation to succeed, got %v", err)
+		}
+		if resp.GasUsed != 86000 || resp.Fee != "" {
+			t.Errorf("Expected gas without fee, got %+v", resp)
+		}
+	})
+
+	t.Run("reports settlement failures as verify errors", func(t *testing.T) {
+		signer := &mockGasEstimatingSigner{
+			mockFacilitatorSigner: &mockFacilitatorSigner{code: []byte{0x01}},
+			gas:                   150000,
+		}
+		scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{MaxSettlementGas: 100000})
+
+		_, err := scheme.Simulate(ctx, payload, requirements)
+		ve, ok := err.(*x402.VerifyError)
+		if !ok {
+			t.Fatalf("Expected VerifyError, got %v", err)
+		}
+		if ve.InvalidReason != evmfacilitator.ErrGasLimitExceeded {
+			t.Errorf("Expected reason %s, got %s", evmfacilitator.ErrGasLimitExceeded, ve.InvalidReason)
+		}
+		if signer.writes != 0 {
+			t.Errorf("Expected no transaction to be sent, got %d", signer.writes)
+		}
+	})
+}
+
+func TestExactEvmFacilitatorSettlementCost(t *testing.T) {
+	ctx := context.Background()
+	payload, requirements := testPermit2Payment(
+		"0x1234567890123456789012345678901234567890",
+		"0x9876543210987654321098765432109876543210",
+	)
+
+	t.Run("reports the receipt's gas and fee", func(t *testing.T) {
+		signer := &mockFacilitatorSigner{code: []byte{0x01}, receiptGasUsed: 61000, receiptGasPrice: big.NewInt(2000000)}
+		resp, err := evmfacilitator.NewExactEvmScheme(signer, nil).Settle(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected settlement to succeed, got %v", err)
+		}
+		if resp.Cost == nil {
+			t.Fatal("Expected settlement cost")
+		}
+		if resp.Cost.GasUsed != 61000 || resp.Cost.GasPrice != "2000000" || resp.Cost.Fee != "122000000000" {
+			t.Errorf("Unexpected settlement cost: %+v", resp.Cost)
+		}
+	})
+
+	t.Run("omits the cost when the receipt has no gas", func(t *testing.T) {
+		signer := &mockFacilitatorSigner{code: []byte{0x01}}
+		resp, err := evmfacilitator.NewExactEvmScheme(signer, nil).Settle(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected settlement to succeed, got %v", err)
+		}
+		if resp.Cost != nil {
+			t.Errorf("Expected no settlement cost, got %+v", resp.Cost)
+		}
+	})
+}
+
+func TestExactEvmFacilitatorSettlementJournal(t *testing.T) {
+	ctx := context.Background()
+	payer := "0x1234567890123456789012345678901234567890"
+	payload, requirements := testPermit2Payment(payer, "0x9876543210987654321098765432109876543210")
+	journal := x402.NewMemorySettlementJournal()
+
+	// The receipt never arrives, as if the facilitator crashed while waiting
+	signer := &mockFacilitatorSigner{code: []byte{0x01}, receiptError: errors.New("context deadline exceeded")}
+	scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{Journal: journal})
+	if _, err := scheme.Settle(ctx, payload, requirements); err == nil {
+		t.Fatal("Expected settlement to fail without a receipt")
+	}
+
+	pending, _ := journal.Pending(ctx)
+	if len(pending) != 1 {
+		t.Fatalf("Expected the sent transaction to be journaled, got %d entries", len(pending))
+	}
+	if pending[0].Payer != payer || pending[0].Network != x402.Network(requirements.Network) || pending[0].Transaction == "" {
+		t.Errorf("Unexpected journal entry: %+v", pending[0])
+	}
+
+	// After a restart the receipt is available
+	signer.receiptError = nil
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{x402.Network(requirements.Network)}, scheme)
+	recovered, err := facilitator.RecoverSettlements(ctx, journal)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0].Err != nil || !recovered[0].Response.Success {
+		t.Fatalf("Expected the settlement to be recovered, got %+v", recovered)
+	}
+	if recovered[0].Response.Transaction != pending[0].Transaction {
+		t.Errorf("Expected transaction %s, got %s", pending[0].Transaction, recovered[0].Response.Transaction)
+	}
+	if pending, _ := journal.Pending(ctx); len(pending) != 0 {
+		t.Errorf("Expected the journal to be empty, got %+v", pending)
+	}
+
+	// Confirmed settlements are not left in the journal
+	if _, err := scheme.Settle(ctx, payload, requirements); err != nil {
+		t.Fatalf("Expected settlement to succeed, got %v", err)
+	}
+	if pending, _ := journal.Pending(ctx); len(pending) != 0 {
+		t.Errorf("Expected confirmed settlements to be resolved, got %+v", pending)
+	}
+}
+
+// slowReceiptSigner never sees settlement transactions mined
+type slowReceiptSigner struct {
+	*mockFacilitatorSigner
+}
+
+func (s *slowReceiptSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestExactEvmFacilitatorReceiptTimeout(t *testing.T) {
+	ctx := context.Background()
+	payer := "0x1234567890123456789012345678901234567890"
+	payload, requirements := testPermit2Payment(payer, "0x9876543210987654321098765432109876543210")
+
+	t.Run("returns a pending response after the timeout", func(t *testing.T) {
+		signer := &slowReceiptSigner{&mockFacilitatorSigner{code: []byte{0x01}}}
+		scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{ReceiptTimeout: 10 * time.Millisecond})
+		resp, err := scheme.Settle(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected a pending response, got %v", err)
+		}
+		if !resp.Success || !resp.Pending || resp.Transaction == "" || resp.Payer != payer {
+			t.Errorf("Unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("fails when the caller's context ends first", func(t *testing.T) {
+		signer := &slowReceiptSigner{&mockFacilitatorSigner{code: []byte{0x01}}}
+		scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{ReceiptTimeout: time.Minute})
+		shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := scheme.Settle(shortCtx, payload, requirements); err == nil {
+			t.Error("Expected settlement to fail")
+		}
+	})
+
+	t.Run("confirms within the timeout", func(t *testing.T) {
+		signer := &mockFacilitatorSigner{code: []byte{0x01}}
+		scheme := evmfacilitator.NewExactEvmScheme(signer, &evmfacilitator.ExactEvmSchemeConfig{ReceiptTimeout: time.Minute})
+		resp, err := scheme.Settle(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Expected settlement to succeed, got %v", err)
+		}
+		if resp.Pending {
+			t.Error("Expected a confirmed response")
+		}
+	})
+}
+
+// testEIP3009Payment returns an EIP-3009 payment on Base Sepolia signed by key
+func testEIP3009Payment(t *testing.T, key *ecdsa.PrivateKey, nonce byte, value string) (types.PaymentPayload, types.PaymentRequirements) {
+	t.Helper()
+	asset := evm.NetworkConfigs["eip155:84532"].DefaultAsset
+	payTo := "0x9876543210987654321098765432109876543210"
+	authorization := evm.ExactEIP3009Authorization{
+		From:        crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		To:          payTo,
+		Value:       value,
+		ValidAfter:  "0",
+		ValidBefore: fmt.Sprint(time.Now().Add(time.Hour).Unix()),
+		Nonce:       evm.BytesToHex(append(make([]byte, 31), nonce)),
+	}
+	hash, err := evm.HashEIP3009Authorization(authorization, evm.ChainIDBaseSepolia, asset.Address, asset.Name, asset.Version)
+	if err != nil {
+		t.Fatalf("Failed to hash authorization: %v", err)
+	}
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	requirements := types.PaymentRequirements{
+		Scheme:            evm.SchemeExact,
+		Network:           "eip155:84532",
+		Asset:             asset.Address,
+		Amount:            value,
+		PayTo:             payTo,
+		MaxTimeoutSeconds: 3600,
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload: map[string]interface{}{
+			"signature": evm.BytesToHex(signature),
+			"authorization": map[string]interface{}{
+				"from":        authorization.From,
+				"to":          authorization.To,
+				"value":       authorization.Value,
+				"validAfter":  authorization.ValidAfter,
+				"validBefore": authorization.ValidBefore,
+				"nonce":       authorization.Nonce,
+			},
+		},
+	}
+	return payload, requirements
+}
+
+// recordingSigner records the contract writes it sends
+type recordingSigner struct {
+	*mockFacilitatorSigner
+	writes []string
+	args   [][]interface{}
+}
+
+func (s *recordingSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	s.writes = append(s.writes, address+":"+functionName)
+	s.args = append(s.args, args)
+	return s.mockFacilitatorSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+func TestExactEvmFacilitatorSettleBatch(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	first, requirements := testEIP3009Payment(t, key, 1, "1000")
+	second, _ := testEIP3009Payment(t, key, 2, "1000")
+
+	t.Run("batches payments from one payer", func(t *testing.T) {
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{receiptGasUsed: 90000, receiptGasPrice: big.NewInt(10)}}
+		facilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		keyA, settleBy, ok := facilitator.BatchKey(first, requirements)
+		keyB, _, _ := facilitator.BatchKey(second, requirements)
+		if !ok || keyA != keyB || time.Until(settleBy) <= 0 {
+			t.Fatalf("Expected payments to share a batch, got %q and %q (settle by %v)", keyA, keyB, settleBy)
+		}
+
+		results := facilitator.SettleBatch(ctx, []types.PaymentPayload{first, second}, []types.PaymentRequirements{requirements, requirements})
+		if len(signer.writes) != 1 || signer.writes[0] != evm.Multicall3Address+":"+evm.FunctionAggregate3 {
+			t.Fatalf("Expected one multicall transaction, got %v", signer.writes)
+		}
+		parsed, _ := abi.JSON(strings.NewReader(string(evm.Multicall3Aggregate3ABI)))
+		if _, err := parsed.Pack(evm.FunctionAggregate3, signer.args[0]...); err != nil {
+			t.Errorf("Batch calls do not encode: %v", err)
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				t.Fatalf("Payment %d: unexpected error: %v", i, result.Err)
+			}
+			if result.Response.Cost == nil || result.Response.Cost.GasUsed != 45000 || result.Response.Cost.Fee != "450000" {
+				t.Errorf("Payment %d: expected half the transaction cost, got %+v", i, result.Response.Cost)
+			}
+		}
+		if results[0].Response.Transaction != results[1].Response.Transaction {
+			t.Error("Expected payments to share a transaction")
+		}
+	})
+
+	t.Run("rejects payments the balance cannot cover", func(t *testing.T) {
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{balance: big.NewInt(1500)}}
+		facilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		results := facilitator.SettleBatch(ctx, []types.PaymentPayload{first, second}, []types.PaymentRequirements{requirements, requirements})
+		if results[0].Err != nil {
+			t.Fatalf("Expected the first payment to settle, got %v", results[0].Err)
+		}
+		var se *x402.SettleError
+		if !errors.As(results[1].Err, &se) || se.ErrorReason != evmfacilitator.ErrInsufficientBalance {
+			t.Errorf("Expected insufficient balance for the second payment, got %v", results[1].Err)
+		}
+	})
+
+	t.Run("fails every payment when the batch reverts", func(t *testing.T) {
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{receiptStatus: 2}}
+		results := evmfacilitator.NewExactEvmScheme(signer, nil).SettleBatch(ctx, []types.PaymentPayload{first, second}, []types.PaymentRequirements{requirements, requirements})
+		for i, result := range results {
+			if result.Err == nil {
+				t.Errorf("Payment %d: expected the reverted batch to fail", i)
+			}
+		}
+	})
+
+	t.Run("settles Permit2 payments alone", func(t *testing.T) {
+		payload, requirements := testPermit2Payment("0x1234567890123456789012345678901234567890", "0x9876543210987654321098765432109876543210")
+		if _, _, ok := evmfacilitator.NewExactEvmScheme(&mockFacilitatorSigner{}, nil).BatchKey(payload, requirements); ok {
+			t.Error("Expected Permit2 payments not to be batched")
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package unit_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/mechanisms/evm/tab"
+	tabclient "github.com/coinbase/x402/go/mechanisms/evm/tab/client"
+	tabfacilitator "github.com/coinbase/x402/go/mechanisms/evm/tab/facilitator"
+	tabserver "github.com/coinbase/x402/go/mechanisms/evm/tab/server"
+	"github.com/coinbase/x402/go/types"
+)
+
+const testTabContract = "0x7ab0000000000000000000000000000000000001"
+
+// keyClientSigner implements evm.ClientEvmSigner with a real private key
+type keyClientSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *keyClientSigner) Address() string {
+	return crypto.PubkeyToAddress(s.key.PublicKey).Hex()
+}
+
+func (s *keyClientSigner) SignTypedData(
+	ctx context.Context,
+	domain evm.TypedDataDomain,
+	types map[string][]evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	hash, err := evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, s.key)
+}
+
+// testTabRequirements returns tab requirements on Base Sepolia, enhanced by
+// the tab server
+func testTabRequirements(t *testing.T, deposit string) types.PaymentRequirements {
+	t.Helper()
+	requirements, err := tabserver.NewTabEvmScheme(tabserver.TabEvmSchemeConfig{Contract: testTabContract, Deposit: deposit}).EnhancePaymentRequirements(
+		context.Background(),
+		types.PaymentRequirements{
+			Scheme:            tab.SchemeTab,
+			Network:           "eip155:84532",
+			Asset:             evm.NetworkConfigs["eip155:84532"].DefaultAsset.Address,
+			Amount:            "1000",
+			PayTo:             "0x9876543210987654321098765432109876543210",
+			MaxTimeoutSeconds: 60,
+		},
+		types.SupportedKind{X402Version: 2, Scheme: tab.SchemeTab, Network: "eip155:84532"},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Failed to enhance requirements: %v", err)
+	}
+	return requirements
+}
+
+func TestTabEvmScheme(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+
+	pay := func(t *testing.T, client *tabclient.TabEvmScheme, requirements types.PaymentRequirements) types.PaymentPayload {
+		t.Helper()
+		payload, err := client.CreatePaymentPayload(ctx, requirements)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		payload.Accepted = requirements
+		return payload
+	}
+
+	t.Run("opens a tab, records vouchers, and closes it", func(t *testing.T) {
+		requirements := testTabRequirements(t, "5000")
+		var extra tab.ExtraTab
+		_ = types.UnmarshalExtra(requirements.Extra, &extra)
+		if extra.Deposit != "5000" || !strings.EqualFold(extra.Contract, testTabContract) {
+			t.Fatalf("Expected tab extra in requirements, got %+v", requirements.Extra)
+		}
+
+		client := tabclient.NewTabEvmScheme(&keyClientSigner{key: key})
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{}}
+		facilitator := tabfacilitator.NewTabEvmScheme(signer, nil)
+
+		first := pay(t, client, requirements)
+		if _, err := facilitator.Verify(ctx, first, requirements); err != nil {
+			t.Fatalf("Expected the opening payment to verify, got %v", err)
+		}
+		opened, err := facilitator.Settle(ctx, first, requirements)
+		if err != nil {
+			t.Fatalf("Expected the opening payment to settle, got %v", err)
+		}
+		if len(signer.writes) != 1 || !strings.EqualFold(signer.writes[0], testTabContract+":"+tab.FunctionOpen) {
+			t.Fatalf("Expected one open transaction, got %v", signer.writes)
+		}
+
+		for i := 0; i < 3; i++ {
+			resp, err := facilitator.Settle(ctx, pay(t, client, requirements), requirements)
+			if err != nil {
+				t.Fatalf("Voucher %d: unexpected error: %v", i, err)
+			}
+			if resp.Transaction != opened.Transaction || !strings.EqualFold(resp.Payer, crypto.PubkeyToAddress(key.PublicKey).Hex()) {
+				t.Errorf("Voucher %d: expected the open transaction and payer, got %+v", i, resp)
+			}
+		}
+		if len(signer.writes) != 1 {
+			t.Fatalf("Expected vouchers to stay off-chain, got %v", signer.writes)
+		}
+
+		tabs, _ := facilitator.Tabs(ctx)
+		if len(tabs) != 1 || tabs[0].Amount != "4000" {
+			t.Fatalf("Expected one tab owing 4000, got %+v", tabs)
+		}
+		closed, err := facilitator.CloseExpiring(ctx, 48*time.Hour)
+		if err != nil || len(closed) != 1 || closed[0].Err != nil {
+			t.Fatalf("Expected the tab to close, got %+v (%v)", closed, err)
+		}
+		if len(signer.writes) != 2 || !strings.EqualFold(signer.writes[1], testTabContract+":"+tab.FunctionClose) {
+			t.Fatalf("Expected a close transaction, got %v", signer.writes)
+		}
+		if amount := signer.args[1][1].(*big.Int); amount.String() != "4000" {
+			t.Errorf("Expected the tab to close for 4000, got %s", amount)
+		}
+
+		var se *x402.SettleError
+		if _, err := facilitator.Settle(ctx, pay(t, client, requirements), requirements); !errors.As(err, &se) || se.ErrorReason != tabfacilitator.ErrTabClosed {
+			t.Errorf("Expected vouchers for a closed tab to fail, got %v", err)
+		}
+	})
+
+	t.Run("rejects replayed and forged vouchers", func(t *testing.T) {
+		requirements := testTabRequirements(t, "")
+		client := tabclient.NewTabEvmScheme(&keyClientSigner{key: key})
+		facilitator := tabfacilitator.NewTabEvmScheme(&mockFacilitatorSigner{}, nil)
+
+		if _, err := facilitator.Settle(ctx, pay(t, client, requirements), requirements); err != nil {
+			t.Fatalf("Expected the opening payment to settle, got %v", err)
+		}
+		second := pay(t, client, requirements)
+		if _, err := facilitator.Settle(ctx, second, requirements); err != nil {
+			t.Fatalf("Expected the voucher to settle, got %v", err)
+		}
+
+		var ve *x402.VerifyError
+		if _, err := facilitator.Verify(ctx, second, requirements); !errors.As(err, &ve) || ve.InvalidReason != tabfacilitator.ErrVoucherTooLow {
+			t.Errorf("Expected a replayed voucher to be too low, got %v", err)
+		}
+
+		otherKey, _ := crypto.GenerateKey()
+		forged := pay(t, client, requirements)
+		forgedPayload, _ := tab.PayloadFromMap(forged.Payload)
+		hash, _ := tab.HashVoucher(forgedPayload.Voucher, evm.ChainIDBaseSepolia, testTabContract)
+		signature, _ := crypto.Sign(hash, otherKey)
+		forgedPayload.Signature = evm.BytesToHex(signature)
+		forged.Payload = forgedPayload.ToMap()
+		if _, err := facilitator.Verify(ctx, forged, requirements); !errors.As(err, &ve) || ve.InvalidReason != tabfacilitator.ErrInvalidVoucherSignature {
+			t.Errorf("Expected a voucher signed by another key to fail, got %v", err)
+		}
+	})
+
+	t.Run("reopens when the deposit runs out", func(t *testing.T) {
+		requirements := testTabRequirements(t, "2000")
+		client := tabclient.NewTabEvmScheme(&keyClientSigner{key: key})
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{}}
+		facilitator := tabfacilitator.NewTabEvmScheme(signer, nil)
+
+		for i := 0; i < 3; i++ {
+			if _, err := facilitator.Settle(ctx, pay(t, client, requirements), requirements); err != nil {
+				t.Fatalf("Payment %d: unexpected error: %v", i, err)
+			}
+		}
+		if len(signer.writes) != 2 {
+			t.Errorf("Expected a second tab to be opened, got %v", signer.writes)
+		}
+	})
+
+	t.Run("rejects deposits outside their validity window", func(t *testing.T) {
+		requirements := testTabRequirements(t, "")
+		signer := &recordingSigner{mockFacilitatorSigner: &mockFacilitatorSigner{}}
+		facilitator := tabfacilitator.NewTabEvmScheme(signer, nil)
+		now := time.Now().Unix()
+
+		tests := []struct {
+			name        string
+			validAfter  int64
+			validBefore int64
+			reason      string
+		}{
+			{"expired", now - 3600, now - 1, tabfacilitator.ErrValidBeforeExpired},
+			{"not yet valid", now + 3600, now + 7200, tabfacilitator.ErrValidAfterInFuture},
+		}
+		for _, tt := range tests {
+			client := tabclient.NewTabEvmScheme(&keyClientSigner{key: key})
+			payment := pay(t, client, requirements)
+			tabPayload, _ := tab.PayloadFromMap(payment.Payload)
+			tabPayload.Open.Authorization.ValidAfter = big.NewInt(tt.validAfter).String()
+			tabPayload.Open.Authorization.ValidBefore = big.NewInt(tt.validBefore).String()
+			payment.Payload = tabPayload.ToMap()
+
+			var se *x402.SettleError
+			if _, err := facilitator.Settle(ctx, payment, requirements); !errors.As(err, &se) || se.ErrorReason != tt.reason {
+				t.Errorf("%s: expected %s, got %v", tt.name, tt.reason, err)
+			}
+		}
+		if len(signer.writes) != 0 {
+			t.Errorf("Expected no open transaction, got %v", signer.writes)
+		}
+	})
+
+	t.Run("rejects deposits the payer cannot cover", func(t *testing.T) {
+		requirements := testTabRequirements(t, "")
+		client := tabclient.NewTabEvmScheme(&keyClientSigner{key: key})
+		facilitator := tabfacilitator.NewTabEvmScheme(&mockFacilitatorSigner{balance: big.NewInt(5000)}, nil)
+
+		var ve *x402.VerifyError
+		if _, err := facilitator.Verify(ctx, pay(t, client, requirements), requirements); !errors.As(err, &ve) || ve.InvalidReason != tabfacilitator.ErrInsufficientBalance {
+			t.Errorf("Expected insufficient balance for the deposit, got %v", err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/coinbase/x402/go/mechanisms/evm"
+	"github.com/coinbase/x402/go/types"
 )
 
 // TestEIP3009PayloadParsing tests EIP-3009 payload parsing and serialization
@@ -594,3 +595,57 @@ func TestSchemeExact(t *testing.T) {
 		t.Errorf("Expected 'exact', got %s", evm.SchemeExact)
 	}
 }
+
+// TestTypedExtra tests typed access to requirements.Extra
+func TestTypedExtra(t *testing.T) {
+	t.Run("UnmarshalExtra keeps defaults for missing fields", func(t *testing.T) {
+		domain := evm.ExtraEIP712{Name: "USD Coin", Version: "2"}
+		if err := types.UnmarshalExtra(map[string]interface{}{"version": "1", "other": 5}, &domain); err != nil {
+			t.Fatalf("UnmarshalExtra failed: %v", err)
+		}
+		if domain.Name != "USD Coin" || domain.Version != "1" {
+			t.Errorf("unexpected domain %+v", domain)
+		}
+
+		var transfer evm.ExtraAssetTransfer
+		if err := types.UnmarshalExtra(nil, &transfer); err != nil || transfer.AssetTransferMethod != "" {
+			t.Errorf("nil extra should leave the struct unchanged: %+v, %v", transfer, err)
+		}
+	})
+
+	t.Run("UnmarshalExtra rejects mistyped fields", func(t *testing.T) {
+		var domain evm.ExtraEIP712
+		if err := types.UnmarshalExtra(map[string]interface{}{"name": 42}, &domain); err == nil {
+			t.Error("expected error for a numeric name")
+		}
+	})
+
+	t.Run("MergeExtra fills missing keys only", func(t *testing.T) {
+		extra := map[string]interface{}{"name": "Custom", "memo": "keep"}
+		merged, err := types.MergeExtra(extra, evm.ExtraEIP712{Name: "USD Coin", Version: "2"}, false)
+		if err != nil {
+			t.Fatalf("MergeExtra failed: %v", err)
+		}
+		if merged["name"] != "Custom" || merged["version"] != "2" || merged["memo"] != "keep" {
+			t.Errorf("unexpected merge %v", merged)
+		}
+
+		encoded, err := types.MarshalExtra(evm.ExtraAssetTransfer{AssetTransferMethod: evm.AssetTransferMethodPermit2})
+		if err != nil || encoded["assetTransferMethod"] != "permit2" {
+			t.Errorf("unexpected encoding %v, %v", encoded, err)
+		}
+	})
+
+	t.Run("ExtraValue checks the type", func(t *testing.T) {
+		extra := map[string]interface{}{"feePayer": "addr", "decimals": float64(6)}
+		if v, ok := types.ExtraValue[string](extra, "feePayer"); !ok || v != "addr" {
+			t.Errorf("unexpected feePayer %q", v)
+		}
+		if _, ok := types.ExtraValue[string](extra, "decimals"); ok {
+			t.Error("expected type mismatch")
+		}
+		if _, ok := types.ExtraValue[string](nil, "feePayer"); ok {
+			t.Error("expected missing key")
+		}
+	})
+}
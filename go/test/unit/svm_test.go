@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	solana "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
 
 	x402 "github.com/coinbase/x402/go"
 	svm "github.com/coinbase/x402/go/mechanisms/svm"
@@ -328,3 +329,73 @@ func TestSolanaGetAssetInfo(t *testing.T) {
 		}
 	})
 }
+
+// TestSolanaGetTokenTransfer tests extracting the transfer from a payment transaction
+func TestSolanaGetTokenTransfer(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	payTo := solana.NewWallet().PublicKey()
+	mint := solana.MustPublicKeyFromBase58(svm.USDCDevnetAddress)
+	source, _, _ := solana.FindAssociatedTokenAddress(owner, mint)
+	destination, _, _ := solana.FindAssociatedTokenAddress(payTo, mint)
+
+	transfer, err := token.NewTransferCheckedInstructionBuilder().
+		SetAmount(1500).
+		SetDecimals(6).
+		SetSourceAccount(source).
+		SetMintAccount(mint).
+		SetDestinationAccount(destination).
+		SetOwnerAccount(owner).
+		ValidateAndBuild()
+	if err != nil {
+		t.Fatalf("Failed to build transfer: %v", err)
+	}
+	memo := solana.NewInstruction(solana.MustPublicKeyFromBase58(svm.MemoProgramAddress), solana.AccountMetaSlice{}, []byte("nonce"))
+	tx, err := solana.NewTransactionBuilder().
+		SetFeePayer(solana.NewWallet().PublicKey()).
+		SetRecentBlockHash(solana.Hash{}).
+		AddInstruction(memo).
+		AddInstruction(transfer).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build transaction: %v", err)
+	}
+	encoded, err := svm.EncodeTransaction(tx)
+	if err != nil {
+		t.Fatalf("Failed to encode transaction: %v", err)
+	}
+
+	got, err := svm.GetTokenTransferFromPayload(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := svm.TokenTransfer{
+		Payer:       owner.String(),
+		Source:      source.String(),
+		Mint:        mint.String(),
+		Destination: destination.String(),
+		Amount:      1500,
+		Decimals:    6,
+	}
+	if *got != want {
+		t.Errorf("Expected %+v, got %+v", want, *got)
+	}
+
+	if payer, err := svm.GetTokenPayerFromTransaction(tx); err != nil || payer != owner.String() {
+		t.Errorf("Expected payer %s, got %s, %v", owner, payer, err)
+	}
+
+	// The svm package registers its payloads with x402.InspectPayment
+	inspection, err := x402.InspectPayment([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"` + svm.SolanaDevnetCAIP2 + `","amount":"1000"},"payload":{"transaction":"` + encoded + `"}}`))
+	if err != nil || inspection.Payer != owner.String() || inspection.Amount != "1500" || inspection.Asset != mint.String() {
+		t.Errorf("Expected the transfer in the inspection, got %+v, %v", inspection, err)
+	}
+
+	noTransfer, _ := solana.NewTransactionBuilder().
+		SetFeePayer(owner).
+		SetRecentBlockHash(solana.Hash{}).
+		AddInstruction(memo).
+		Build()
+	if _, err := svm.GetTokenTransferFromTransaction(noTransfer); err == nil {
+		t.Error("Expected an error for a transaction without a transfer")
+	}
+}
@@ -0,0 +1,69 @@
+package unit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// TestCanonicalJSON tests canonical JSON encoding and hashing
+func TestCanonicalJSON(t *testing.T) {
+	t.Run("sorts keys and normalizes numbers", func(t *testing.T) {
+		value := map[string]interface{}{
+			"b":    1.0,
+			"a":    []interface{}{"<tag>", 1e21, 0.5, -0.0},
+			"c":    map[string]interface{}{"z": nil, "y": true},
+			"int":  int64(9007199254740991),
+			"text": "é\n",
+		}
+		got, err := types.CanonicalJSON(value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := `{"a":["<tag>",1e+21,0.5,0],"b":1,"c":{"y":true,"z":null},"int":9007199254740991,"text":"é\n"}`
+		if string(got) != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("decoded JSON hashes like the original", func(t *testing.T) {
+		requirements := types.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:8453",
+			Asset:             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			Amount:            "10000",
+			PayTo:             "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+			MaxTimeoutSeconds: 300,
+			Extra:             map[string]interface{}{"name": "USD Coin", "version": "2", "decimals": 6},
+			ExpiresAt:         1700000300,
+		}
+		data, _ := json.Marshal(requirements)
+		var decoded types.PaymentRequirements
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		original, _ := types.RequirementsHash(requirements)
+		roundTripped, _ := types.RequirementsHash(decoded)
+		if original != roundTripped {
+			t.Errorf("Expected equal hashes, got %s and %s", original, roundTripped)
+		}
+
+		decoded.ExpiresAt = 0
+		if hash, _ := types.RequirementsHash(decoded); hash != original {
+			t.Error("Expected expiresAt to be left out of the requirements hash")
+		}
+		decoded.Extra[types.ExpiryProofExtraKey] = "proof"
+		if hash, _ := types.RequirementsHash(decoded); hash != original {
+			t.Error("Expected the expiry proof to be left out of the requirements hash")
+		}
+		if _, ok := decoded.Extra[types.ExpiryProofExtraKey]; !ok {
+			t.Error("Expected hashing to leave the requirements unchanged")
+		}
+		decoded.Extra["decimals"] = 18
+		if hash, _ := types.RequirementsHash(decoded); hash == original {
+			t.Error("Expected a changed extra to change the requirements hash")
+		}
+	})
+}
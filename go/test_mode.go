@@ -0,0 +1,173 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/x402/go/networks"
+)
+
+// ============================================================================
+// Test Mode
+// ============================================================================
+
+// Faucet funds payers on test networks
+type Faucet interface {
+	// Fund sends test funds of asset to address and returns the funding
+	// transaction, or "" when the faucet does not report one
+	Fund(ctx context.Context, network Network, asset string, address string) (string, error)
+}
+
+// FaucetFunc adapts a function to the Faucet interface
+type FaucetFunc func(ctx context.Context, network Network, asset string, address string) (string, error)
+
+// Fund implements Faucet
+func (f FaucetFunc) Fund(ctx context.Context, network Network, asset string, address string) (string, error) {
+	return f(ctx, network, asset, address)
+}
+
+// TestModeConfig configures a facilitator's test mode
+type TestModeConfig struct {
+	// Faucets fund payers whose balance is too low, by network or CAIP
+	// family wildcard (e.g. "solana:*"). An exact network beats a wildcard.
+	Faucets map[Network]Faucet
+
+	// FundingWait is how long verification retries after funding a payer,
+	// waiting for the funds to arrive (default 30s)
+	FundingWait time.Duration
+
+	// FundingCooldown funds each payer at most once per period on a network,
+	// so a broken payment cannot drain the faucet (default 1 hour)
+	FundingCooldown time.Duration
+}
+
+// Default test mode limits
+const (
+	defaultFundingWait     = 30 * time.Second
+	defaultFundingCooldown = time.Hour
+)
+
+// fundingRetryDelay is the wait between verification attempts after funding
+var fundingRetryDelay = 2 * time.Second
+
+// EnableTestMode restricts the facilitator to test networks and marks every
+// verify, settle, and supported response with TestMode. Payments on other
+// networks fail with ErrMainnetInTestMode. When verification fails for lack
+// of funds, the payer is funded from the network's faucet and verification
+// is retried, so example apps and end-to-end tests can start from empty
+// wallets.
+func (f *x402Facilitator) EnableTestMode(config TestModeConfig) *x402Facilitator {
+	if config.FundingWait <= 0 {
+		config.FundingWait = defaultFundingWait
+	}
+	if config.FundingCooldown <= 0 {
+		config.FundingCooldown = defaultFundingCooldown
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.testMode = &config
+	f.funded = make(map[string]time.Time)
+	return f
+}
+
+// rejectedInTestMode reports whether test mode rejects payments on a
+// network. Callers must hold f.mu.
+func (f *x402Facilitator) rejectedInTestMode(network Network) bool {
+	return f.testMode != nil && !network.IsTestnet()
+}
+
+// testModeMessage explains why test mode rejected a network
+func testModeMessage(network Network) string {
+	return fmt.Sprintf("%s is not a test network", network)
+}
+
+// markTestMode tags a settle response in test mode
+func (f *x402Facilitator) markTestMode(response *SettleResponse) {
+	f.mu.RLock()
+	testMode := f.testMode != nil
+	f.mu.RUnlock()
+	if testMode && response != nil {
+		response.TestMode = true
+	}
+}
+
+// verifyFunded runs verify, funding the payer and retrying in test mode when
+// it fails for lack of funds
+func (f *x402Facilitator) verifyFunded(ctx context.Context, network Network, asset string, verify func() (*VerifyResponse, error)) (*VerifyResponse, error) {
+	f.mu.RLock()
+	config := f.testMode
+	f.mu.RUnlock()
+	if config == nil {
+		return verify()
+	}
+
+	network = Network(networks.Canonical(string(network)))
+	result, err := verify()
+	ve := &VerifyError{}
+	if err != nil && errors.As(err, &ve) && ve.Payer != "" && needsFunding(ve.InvalidReason) {
+		if f.fund(ctx, config, network, asset, ve.Payer) {
+			deadline := time.Now().Add(config.FundingWait)
+			for {
+				select {
+				case <-ctx.Done():
+					return nil, err
+				case <-time.After(fundingRetryDelay):
+				}
+				if result, err = verify(); err == nil || !time.Now().Before(deadline) {
+					break
+				}
+			}
+		}
+	}
+	if result != nil {
+		result.TestMode = true
+	}
+	return result, err
+}
+
+// fund funds a payer from the network's faucet, at most once per cooldown,
+// and reports whether funds were sent
+func (f *x402Facilitator) fund(ctx context.Context, config *TestModeConfig, network Network, asset string, payer string) bool {
+	faucet := faucetFor(config.Faucets, network)
+	if faucet == nil {
+		return false
+	}
+
+	key := strings.ToLower(string(network) + ":" + payer)
+	now := time.Now()
+	f.fundedMu.Lock()
+	if last, ok := f.funded[key]; ok && now.Sub(last) < config.FundingCooldown {
+		f.fundedMu.Unlock()
+		return false
+	}
+	f.funded[key] = now
+	f.fundedMu.Unlock()
+
+	_, err := faucet.Fund(ctx, network, asset, payer)
+	return err == nil
+}
+
+// faucetFor returns the faucet for a network (exact network beats wildcard family)
+func faucetFor(faucets map[Network]Faucet, network Network) Faucet {
+	if faucet, ok := faucets[network]; ok {
+		return faucet
+	}
+	for pattern, faucet := range faucets {
+		if network.Match(pattern) {
+			return faucet
+		}
+	}
+	return nil
+}
+
+// needsFunding reports whether a verify failure reason means the payer
+// lacks funds. Solana reports it as a failed simulation.
+func needsFunding(reason string) bool {
+	return strings.Contains(reason, "insufficient_balance") ||
+		strings.Contains(reason, "insufficient_funds") ||
+		strings.Contains(reason, "simulation_failed")
+}
@@ -0,0 +1,122 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func testModeBytes(network string) ([]byte, []byte) {
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: network, Asset: "0xusdc", Amount: "1000", PayTo: "0xrecipient"}
+	payloadBytes, _ := json.Marshal(types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}})
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+func TestTestModeRejectsMainnets(t *testing.T) {
+	ctx := context.Background()
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:*"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+	facilitator.EnableTestMode(TestModeConfig{})
+
+	payloadBytes, requirementsBytes := testModeBytes("eip155:8453")
+	var ve *VerifyError
+	if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); !errors.As(err, &ve) || ve.InvalidReason != ErrMainnetInTestMode {
+		t.Errorf("Expected %s verifying on Base, got %v", ErrMainnetInTestMode, err)
+	}
+	var se *SettleError
+	if _, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes); !errors.As(err, &se) || se.ErrorReason != ErrMainnetInTestMode {
+		t.Errorf("Expected %s settling on Base, got %v", ErrMainnetInTestMode, err)
+	}
+
+	payloadBytes, requirementsBytes = testModeBytes("eip155:84532")
+	verified, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+	if err != nil || !verified.TestMode {
+		t.Errorf("Expected a test-mode verification on Base Sepolia, got %+v (%v)", verified, err)
+	}
+	settled, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if err != nil || !settled.TestMode {
+		t.Errorf("Expected a test-mode settlement on Base Sepolia, got %+v (%v)", settled, err)
+	}
+	if !facilitator.GetSupported().TestMode {
+		t.Error("Expected supported to report test mode")
+	}
+}
+
+func TestTestModeFundsPayers(t *testing.T) {
+	previous := fundingRetryDelay
+	fundingRetryDelay = time.Millisecond
+	defer func() { fundingRetryDelay = previous }()
+
+	ctx := context.Background()
+	var mu sync.Mutex
+	var funded []string
+	faucet := FaucetFunc(func(ctx context.Context, network Network, asset string, address string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		funded = append(funded, string(network)+"/"+asset+"/"+address)
+		return "0xfaucet", nil
+	})
+	mechanism := &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(funded) == 0 {
+				return nil, NewVerifyError("invalid_exact_evm_insufficient_balance", "0xpayer", "")
+			}
+			return &VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+	}
+
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:84532"}, mechanism)
+	facilitator.EnableTestMode(TestModeConfig{Faucets: map[Network]Faucet{"eip155:*": faucet}})
+
+	payloadBytes, requirementsBytes := testModeBytes("eip155:84532")
+	response, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+	if err != nil || !response.TestMode {
+		t.Fatalf("Expected verification to pass after funding, got %+v (%v)", response, err)
+	}
+	if len(funded) != 1 || funded[0] != "eip155:84532/0xusdc/0xpayer" {
+		t.Errorf("Expected the payer to be funded once, got %v", funded)
+	}
+}
+
+func TestTestModeFundingCooldown(t *testing.T) {
+	previous := fundingRetryDelay
+	fundingRetryDelay = time.Millisecond
+	defer func() { fundingRetryDelay = previous }()
+
+	ctx := context.Background()
+	fundings := 0
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:84532"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+			return nil, NewVerifyError("invalid_exact_evm_insufficient_balance", "0xpayer", "")
+		},
+	})
+	facilitator.EnableTestMode(TestModeConfig{
+		Faucets: map[Network]Faucet{"eip155:84532": FaucetFunc(func(ctx context.Context, network Network, asset string, address string) (string, error) {
+			fundings++
+			return "", nil
+		})},
+		FundingWait: 5 * time.Millisecond,
+	})
+
+	payloadBytes, requirementsBytes := testModeBytes("eip155:84532")
+	for i := 0; i < 2; i++ {
+		if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); err == nil {
+			t.Fatal("Expected verification to keep failing")
+		}
+	}
+	if fundings != 1 {
+		t.Errorf("Expected the payer to be funded once per cooldown, got %d", fundings)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/coinbase/x402/go/networks"
 	"github.com/coinbase/x402/go/types"
 )
 
@@ -46,6 +47,32 @@ func (n Network) Match(pattern Network) bool {
 	return false
 }
 
+// Family returns the network family (EVM, SVM), or networks.FamilyUnknown
+func (n Network) Family() networks.Family {
+	return networks.FamilyOf(string(n))
+}
+
+// IsTestnet reports whether the network is a known test network
+func (n Network) IsTestnet() bool {
+	return networks.IsTestnet(string(n))
+}
+
+// NativeDecimals returns the decimals of the network's native coin (18 for
+// EVM, 9 for Solana), or 0 for unknown families
+func (n Network) NativeDecimals() int {
+	return networks.NativeDecimals(string(n))
+}
+
+// ExplorerURL returns the block explorer URL for a transaction on this network
+func (n Network) ExplorerURL(txHash string) (string, bool) {
+	return networks.TransactionURL(string(n), txHash)
+}
+
+// DefaultAsset returns the network's default payment asset, if one is known
+func (n Network) DefaultAsset() (networks.Asset, bool) {
+	return networks.DefaultAsset(string(n))
+}
+
 // Price represents a price that can be specified in various formats
 type Price interface{}
 
@@ -85,6 +112,9 @@ type VerifyResponse struct {
 	InvalidReason  string `json:"invalidReason,omitempty"`
 	InvalidMessage string `json:"invalidMessage,omitempty"`
 	Payer          string `json:"payer,omitempty"`
+
+	// TestMode is set by facilitators in test mode (see EnableTestMode)
+	TestMode bool `json:"testMode,omitempty"`
 }
 
 // SettleResponse contains the settlement result
@@ -96,6 +126,59 @@ type SettleResponse struct {
 	Payer        string  `json:"payer,omitempty"`
 	Transaction  string  `json:"transaction"`
 	Network      Network `json:"network"`
+
+	// Pending is set when the transaction was sent but not confirmed within
+	// the mechanism's receipt timeout; Success is still set, since the
+	// authorization is spent. The final outcome is available from StatusURL,
+	// if the facilitator has one.
+	Pending   bool   `json:"pending,omitempty"`
+	StatusURL string `json:"statusUrl,omitempty"`
+
+	// Deferred is set when the resource server accepted the payment but holds
+	// it to settle together with the payer's other small payments (see
+	// WithDeferredSettlement); there is no transaction yet.
+	Deferred bool `json:"deferred,omitempty"`
+
+	// TestMode is set by facilitators in test mode (see EnableTestMode)
+	TestMode bool `json:"testMode,omitempty"`
+
+	// Cost is what the settlement cost the facilitator, set by mechanisms
+	// whose signer can read it. It is for facilitator-side hooks (see the
+	// costs package) and is not sent to resource servers.
+	Cost *SettlementCost `json:"-"`
+}
+
+// SettlementCost is what a settlement transaction cost the facilitator in the
+// network's native token
+type SettlementCost struct {
+	// GasUsed is the gas (EVM) or compute units (SVM) the transaction consumed
+	GasUsed uint64 `json:"gasUsed"`
+
+	// GasPrice is the effective price per gas unit in the smallest native unit (EVM only)
+	GasPrice string `json:"gasPrice,omitempty"`
+
+	// Fee is the fee paid in the smallest native unit (wei, lamports); empty when unknown
+	Fee string `json:"fee,omitempty"`
+}
+
+// SimulateResponse contains the projected cost of settling a payment
+// If verification or simulation fails, an error (typically *VerifyError) is returned and this will be nil
+type SimulateResponse struct {
+	IsValid        bool    `json:"isValid"`
+	InvalidReason  string  `json:"invalidReason,omitempty"`
+	InvalidMessage string  `json:"invalidMessage,omitempty"`
+	Payer          string  `json:"payer,omitempty"`
+	Network        Network `json:"network"`
+
+	// GasUsed is the projected gas (EVM) or compute units (SVM) of the settlement transaction
+	GasUsed uint64 `json:"gasUsed"`
+
+	// GasPrice is the projected price per gas unit, in the network's smallest native unit (optional)
+	GasPrice string `json:"gasPrice,omitempty"`
+
+	// Fee is the projected fee paid by the facilitator, in the network's
+	// smallest native unit (wei, lamports); empty when the price is unknown
+	Fee string `json:"fee,omitempty"`
 }
 
 // ResourceConfig defines payment configuration for a protected resource
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AttributionExtraKey is the requirements extra field carrying attribution:
+// string metadata such as an order ID that ties a payment to a business
+// object. It flows into SVM payment memos and settlement records.
+const AttributionExtraKey = "attribution"
+
+// MaxAttributionBytes bounds the JSON encoding of attribution, so it fits in
+// a Solana transaction memo alongside the payment
+const MaxAttributionBytes = 256
+
+// Attribution returns the attribution in requirements extra, or nil when there
+// is none. Values that are not strings are left out.
+func Attribution(extra map[string]interface{}) map[string]string {
+	switch value := extra[AttributionExtraKey].(type) {
+	case map[string]string:
+		if len(value) == 0 {
+			return nil
+		}
+		attribution := make(map[string]string, len(value))
+		for k, v := range value {
+			attribution[k] = v
+		}
+		return attribution
+	case map[string]interface{}:
+		attribution := make(map[string]string, len(value))
+		for k, v := range value {
+			if s, ok := v.(string); ok {
+				attribution[k] = s
+			}
+		}
+		if len(attribution) == 0 {
+			return nil
+		}
+		return attribution
+	}
+	return nil
+}
+
+// ValidateAttribution checks that attribution fits in MaxAttributionBytes
+func ValidateAttribution(attribution map[string]string) error {
+	data, err := json.Marshal(attribution)
+	if err != nil {
+		return fmt.Errorf("failed to encode attribution: %w", err)
+	}
+	if len(data) > MaxAttributionBytes {
+		return fmt.Errorf("attribution is %d bytes encoded, more than %d", len(data), MaxAttributionBytes)
+	}
+	return nil
+}
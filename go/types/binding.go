@@ -0,0 +1,113 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ResourceOriginExtraKey is the requirements extra field binding a payment to
+// the origin of the resource it was issued for (e.g. "https://api.example.com").
+// Requirements carrying it ask the facilitator to check that the payload's
+// accepted requirements are exactly the ones the server sent. The binding is
+// advisory, since the payer's signature does not cover it.
+const ResourceOriginExtraKey = "resourceOrigin"
+
+// ExpiryProofExtraKey is the requirements extra field holding the resource
+// server's proof that it issued the requirements' ExpiresAt, so clients cannot
+// drop or extend the expiry of a challenge
+const ExpiryProofExtraKey = "expiresAtProof"
+
+// RequestBodyProofExtraKey is the requirements extra field holding the
+// resource server's proof that it issued the requirements for the request
+// body hash they carry, so clients cannot substitute their own
+const RequestBodyProofExtraKey = "requestBodyProof"
+
+// perRequestExtraKeys are the extra fields servers regenerate per request
+var perRequestExtraKeys = []string{ExpiryProofExtraKey, RequestBodyProofExtraKey}
+
+// RequirementsHash returns the canonical JSON digest of the requirements
+// ("sha256:<hex>", see HashCanonical). ExpiresAt and the proofs covering it
+// are left out, since servers regenerate them per request and check them on
+// their own.
+func RequirementsHash(requirements PaymentRequirements) (string, error) {
+	requirements.ExpiresAt = 0
+	for _, perRequest := range perRequestExtraKeys {
+		if _, ok := requirements.Extra[perRequest]; !ok {
+			continue
+		}
+		extra := make(map[string]interface{}, len(requirements.Extra))
+		for key, value := range requirements.Extra {
+			if !isPerRequestExtraKey(key) {
+				extra[key] = value
+			}
+		}
+		requirements.Extra = extra
+		break
+	}
+	hash, err := HashCanonical(requirements)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode requirements: %w", err)
+	}
+	return hash, nil
+}
+
+// isPerRequestExtraKey reports whether key is one of perRequestExtraKeys
+func isPerRequestExtraKey(key string) bool {
+	for _, perRequest := range perRequestExtraKeys {
+		if key == perRequest {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceOrigin returns the lowercase scheme and host of a resource URL,
+// e.g. "https://api.example.com:8443"
+func ResourceOrigin(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource URL: %w", err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("resource URL %q has no origin", rawURL)
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host), nil
+}
+
+// VerifyRequirementsBinding checks a payment against the requirements a server
+// sent when they carry ResourceOriginExtraKey: the accepted requirements must
+// hash to the same value, and the payload's resource must have the bound
+// origin. Requirements without the key are not bound and always pass. Both
+// values are unsigned, so this detects mismatches rather than tampering by
+// whoever relays the payment.
+func VerifyRequirementsBinding(payload PaymentPayload, requirements PaymentRequirements) error {
+	origin, bound := ExtraValue[string](requirements.Extra, ResourceOriginExtraKey)
+	if !bound {
+		return nil
+	}
+
+	expected, err := RequirementsHash(requirements)
+	if err != nil {
+		return err
+	}
+	accepted, err := RequirementsHash(payload.Accepted)
+	if err != nil {
+		return err
+	}
+	if accepted != expected {
+		return errors.New("accepted requirements do not match the requirements sent by the server")
+	}
+
+	if payload.Resource != nil {
+		resourceOrigin, err := ResourceOrigin(payload.Resource.URL)
+		if err != nil {
+			return err
+		}
+		if resourceOrigin != origin {
+			return fmt.Errorf("payment for %s is bound to origin %s", resourceOrigin, origin)
+		}
+	}
+	return nil
+}
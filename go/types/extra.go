@@ -0,0 +1,64 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Typed access to the free-form Extra objects of payment requirements and
+// supported kinds. Schemes define a struct with JSON tags for their fields
+// (e.g. evm.ExtraEIP712) and convert with UnmarshalExtra and MarshalExtra
+// instead of asserting map values.
+
+// ExtraValue returns extra[key] as T, reporting false when the key is missing
+// or holds a different type. JSON numbers are float64.
+func ExtraValue[T any](extra map[string]interface{}, key string) (T, bool) {
+	value, ok := extra[key].(T)
+	return value, ok
+}
+
+// UnmarshalExtra decodes extra into a typed struct through its JSON encoding.
+// A nil or empty extra leaves out unchanged.
+func UnmarshalExtra[T any](extra map[string]interface{}, out *T) error {
+	if len(extra) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(extra)
+	if err != nil {
+		return fmt.Errorf("failed to encode extra: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid extra: %w", err)
+	}
+	return nil
+}
+
+// MarshalExtra encodes a typed struct as an extra map
+func MarshalExtra(value interface{}) (map[string]interface{}, error) {
+	return MergeExtra(nil, value, true)
+}
+
+// MergeExtra adds the JSON fields of value to extra, allocating it when nil.
+// Existing keys are replaced only when overwrite is set, so defaults can be
+// filled in without clobbering values a caller chose. Other keys are kept.
+func MergeExtra(extra map[string]interface{}, value interface{}, overwrite bool) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extra: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("extra must encode as a JSON object: %w", err)
+	}
+
+	if extra == nil {
+		extra = make(map[string]interface{}, len(fields))
+	}
+	for key, field := range fields {
+		if _, exists := extra[key]; exists && !overwrite {
+			continue
+		}
+		extra[key] = field
+	}
+	return extra, nil
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // PaymentPayload represents a v2 payment payload structure
@@ -12,6 +13,11 @@ type PaymentPayload struct {
 	Accepted    PaymentRequirements    `json:"accepted"`
 	Resource    *ResourceInfo          `json:"resource,omitempty"`
 	Extensions  map[string]interface{} `json:"extensions,omitempty"`
+
+	// RequirementsHash commits to the accepted requirements (see
+	// RequirementsHash), letting servers and facilitators check that Accepted
+	// is exactly one of the offered requirements
+	RequirementsHash string `json:"requirementsHash,omitempty"`
 }
 
 // PaymentPayloadView interface implementation for V2
@@ -29,6 +35,10 @@ type PaymentRequirements struct {
 	PayTo             string                 `json:"payTo"`
 	MaxTimeoutSeconds int                    `json:"maxTimeoutSeconds"`
 	Extra             map[string]interface{} `json:"extra,omitempty"`
+
+	// ExpiresAt is the Unix time in seconds after which the requirements must
+	// not be paid, so long-cached 402s cannot be replayed. Zero means no expiry.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
 }
 
 // PaymentRequirementsView interface implementation for V2
@@ -40,6 +50,11 @@ func (r PaymentRequirements) GetPayTo() string                 { return r.PayTo
 func (r PaymentRequirements) GetMaxTimeoutSeconds() int        { return r.MaxTimeoutSeconds }
 func (r PaymentRequirements) GetExtra() map[string]interface{} { return r.Extra }
 
+// Expired reports whether the requirements have an expiry that has passed
+func (r PaymentRequirements) Expired(now time.Time) bool {
+	return r.ExpiresAt != 0 && now.Unix() > r.ExpiresAt
+}
+
 // PaymentRequired represents a v2 402 response structure
 type PaymentRequired struct {
 	X402Version int                    `json:"x402Version"`
@@ -54,6 +69,13 @@ type ResourceInfo struct {
 	URL         string `json:"url"`
 	Description string `json:"description,omitempty"`
 	MimeType    string `json:"mimeType,omitempty"`
+
+	// OutputSchema is a JSON Schema describing the response returned after payment,
+	// letting clients judge whether the resource is worth paying for
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+
+	// OutputExample is an example of the response returned after payment
+	OutputExample interface{} `json:"outputExample,omitempty"`
 }
 
 // SupportedKind represents a supported payment configuration
@@ -69,6 +91,12 @@ type SupportedResponse struct {
 	Kinds      []SupportedKind     `json:"kinds"`      // Array of kinds with version in each element
 	Extensions []string            `json:"extensions"` // Protocol extensions supported
 	Signers    map[string][]string `json:"signers"`    // CAIP family → Signer addresses
+
+	// NextCursor fetches the next page of a paginated response (empty on the last page)
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// TestMode is set when the facilitator only serves test networks
+	TestMode bool `json:"testMode,omitempty"`
 }
 
 // Unmarshal helpers
@@ -0,0 +1,335 @@
+// Package x402test provides test doubles for exercising x402 integrations.
+//
+// The helpers in this package are intended for use in tests only. They let
+// resource server operators verify that their retry, timeout, and
+// circuit-breaker configuration behaves correctly when a facilitator is slow,
+// flaky, or returns garbage. Its faucets fund payers for a facilitator in
+// test mode (see x402.TestModeConfig).
+package x402test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+)
+
+// Facilitator endpoint names used in FaultConfig.Endpoints
+const (
+	EndpointVerify    = "verify"
+	EndpointSettle    = "settle"
+	EndpointSupported = "supported"
+)
+
+// FaultConfig configures the faults injected by a ChaosFacilitator.
+// Rates are probabilities in the range [0, 1] and are evaluated independently
+// per request in the order: error, malformed, partial.
+type FaultConfig struct {
+	// Latency is added before every response
+	Latency time.Duration
+
+	// LatencyJitter adds a random delay in [0, LatencyJitter) on top of Latency
+	LatencyJitter time.Duration
+
+	// ErrorRate is the probability of returning a random 5xx without calling the backend
+	ErrorRate float64
+
+	// ErrorStatusCodes are the status codes used for injected errors.
+	// Defaults to 500, 502, 503 and 504.
+	ErrorStatusCodes []int
+
+	// MalformedRate is the probability of returning a 200 with a body that is not valid JSON
+	MalformedRate float64
+
+	// PartialFailureRate is the probability of calling the backend and then
+	// discarding its result in favour of a 502. For /settle this simulates a
+	// facilitator that settled on-chain but never delivered the response.
+	PartialFailureRate float64
+
+	// Endpoints restricts fault injection to the named endpoints
+	// (EndpointVerify, EndpointSettle, EndpointSupported). Empty means all endpoints.
+	Endpoints []string
+
+	// Seed seeds the random source so test runs are reproducible. Zero uses a fixed default.
+	Seed int64
+}
+
+// ChaosStats counts what a ChaosFacilitator has done so far
+type ChaosStats struct {
+	Requests        int
+	InjectedErrors  int
+	MalformedBodies int
+	PartialFailures int
+	BackendCalls    int
+}
+
+// ChaosFacilitator is an http.Handler that serves the facilitator HTTP API
+// (POST /verify, POST /settle, GET /supported) by delegating to a backend
+// FacilitatorClient while injecting latency, 5xx errors, malformed JSON, and
+// partial failures according to its FaultConfig.
+type ChaosFacilitator struct {
+	backend x402.FacilitatorClient
+
+	mu     sync.Mutex
+	config FaultConfig
+	rng    *rand.Rand
+	stats  ChaosStats
+}
+
+// NewChaosFacilitator creates a fault-injecting facilitator backed by the given client
+func NewChaosFacilitator(backend x402.FacilitatorClient, config FaultConfig) *ChaosFacilitator {
+	f := &ChaosFacilitator{backend: backend}
+	f.SetConfig(config)
+	return f
+}
+
+// NewChaosServer starts an httptest.Server serving a ChaosFacilitator.
+// The caller is responsible for closing the returned server.
+//
+// Example:
+//
+//	server, chaos := x402test.NewChaosServer(backend, x402test.FaultConfig{ErrorRate: 0.5})
+//	defer server.Close()
+//	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+func NewChaosServer(backend x402.FacilitatorClient, config FaultConfig) (*httptest.Server, *ChaosFacilitator) {
+	chaos := NewChaosFacilitator(backend, config)
+	return httptest.NewServer(chaos), chaos
+}
+
+// SetConfig replaces the fault configuration, resetting the random source.
+// It is safe to call while requests are in flight.
+func (f *ChaosFacilitator) SetConfig(config FaultConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(config.ErrorStatusCodes) == 0 {
+		config.ErrorStatusCodes = []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = 402
+	}
+
+	f.config = config
+	//nolint:gosec // Deterministic randomness is the point of a test double
+	f.rng = rand.New(rand.NewSource(seed))
+}
+
+// Stats returns a snapshot of the counters
+func (f *ChaosFacilitator) Stats() ChaosStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// ServeHTTP implements http.Handler
+func (f *ChaosFacilitator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(r.URL.Path)
+	switch endpoint {
+	case EndpointVerify, EndpointSettle:
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	case EndpointSupported:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	fault, delay, status := f.roll(endpoint)
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch fault {
+	case faultError:
+		writeJSON(w, status, map[string]string{"error": "injected failure"})
+		return
+	case faultMalformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"isValid": tru`)
+		return
+	}
+
+	f.mu.Lock()
+	f.stats.BackendCalls++
+	f.mu.Unlock()
+
+	status, body := f.callBackend(r.Context(), endpoint, r)
+
+	if fault == faultPartial {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "injected partial failure"})
+		return
+	}
+
+	writeJSON(w, status, body)
+}
+
+type faultKind int
+
+const (
+	faultNone faultKind = iota
+	faultError
+	faultMalformed
+	faultPartial
+)
+
+// roll decides which fault (if any) applies to this request and how long to delay
+func (f *ChaosFacilitator) roll(endpoint string) (faultKind, time.Duration, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stats.Requests++
+
+	delay := f.config.Latency
+	if f.config.LatencyJitter > 0 {
+		delay += time.Duration(f.rng.Int63n(int64(f.config.LatencyJitter)))
+	}
+
+	if !f.appliesTo(endpoint) {
+		return faultNone, delay, 0
+	}
+
+	if f.config.ErrorRate > 0 && f.rng.Float64() < f.config.ErrorRate {
+		f.stats.InjectedErrors++
+		codes := f.config.ErrorStatusCodes
+		return faultError, delay, codes[f.rng.Intn(len(codes))]
+	}
+	if f.config.MalformedRate > 0 && f.rng.Float64() < f.config.MalformedRate {
+		f.stats.MalformedBodies++
+		return faultMalformed, delay, 0
+	}
+	if f.config.PartialFailureRate > 0 && f.rng.Float64() < f.config.PartialFailureRate {
+		f.stats.PartialFailures++
+		return faultPartial, delay, 0
+	}
+	return faultNone, delay, 0
+}
+
+// appliesTo reports whether faults are enabled for the endpoint (must be called with lock held)
+func (f *ChaosFacilitator) appliesTo(endpoint string) bool {
+	if len(f.config.Endpoints) == 0 {
+		return true
+	}
+	for _, e := range f.config.Endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// supported serves /supported, applying any filter and pagination parameters
+func (f *ChaosFacilitator) supported(ctx context.Context, r *http.Request) (int, interface{}) {
+	query, err := x402http.ParseSupportedQuery(r.URL.Query())
+	if err != nil {
+		return http.StatusBadRequest, map[string]string{"error": err.Error()}
+	}
+
+	if querier, ok := f.backend.(x402.SupportedQueryClient); ok {
+		supported, err := querier.QuerySupported(ctx, query)
+		if err != nil {
+			return http.StatusInternalServerError, map[string]string{"error": err.Error()}
+		}
+		return http.StatusOK, supported
+	}
+
+	supported, err := f.backend.GetSupported(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, map[string]string{"error": err.Error()}
+	}
+	if query == (x402.SupportedQuery{}) {
+		return http.StatusOK, supported
+	}
+	filtered, err := x402.FilterSupported(supported, query)
+	if err != nil {
+		return http.StatusBadRequest, map[string]string{"error": err.Error()}
+	}
+	return http.StatusOK, filtered
+}
+
+// facilitatorRequest is the request body for /verify and /settle
+type facilitatorRequest struct {
+	X402Version         int             `json:"x402Version"`
+	PaymentPayload      json.RawMessage `json:"paymentPayload"`
+	PaymentRequirements json.RawMessage `json:"paymentRequirements"`
+}
+
+// callBackend forwards the request to the backend facilitator and returns the status and body to send
+func (f *ChaosFacilitator) callBackend(ctx context.Context, endpoint string, r *http.Request) (int, interface{}) {
+	if endpoint == EndpointSupported {
+		return f.supported(ctx, r)
+	}
+
+	var req facilitatorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)}
+	}
+
+	if endpoint == EndpointVerify {
+		response, err := f.backend.Verify(ctx, req.PaymentPayload, req.PaymentRequirements)
+		if err != nil {
+			var ve *x402.VerifyError
+			if errors.As(err, &ve) {
+				return http.StatusBadRequest, x402.VerifyResponse{
+					IsValid:        false,
+					InvalidReason:  ve.InvalidReason,
+					InvalidMessage: ve.InvalidMessage,
+					Payer:          ve.Payer,
+				}
+			}
+			return http.StatusInternalServerError, map[string]string{"error": err.Error()}
+		}
+		return http.StatusOK, response
+	}
+
+	response, err := f.backend.Settle(ctx, req.PaymentPayload, req.PaymentRequirements)
+	if err != nil {
+		var se *x402.SettleError
+		if errors.As(err, &se) {
+			return http.StatusBadRequest, x402.SettleResponse{
+				Success:      false,
+				ErrorReason:  se.ErrorReason,
+				ErrorMessage: se.ErrorMessage,
+				Payer:        se.Payer,
+				Transaction:  se.Transaction,
+				Network:      se.Network,
+			}
+		}
+		return http.StatusInternalServerError, map[string]string{"error": err.Error()}
+	}
+	return http.StatusOK, response
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
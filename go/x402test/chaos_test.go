@@ -0,0 +1,166 @@
+package x402test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	x402http "github.com/coinbase/x402/go/http"
+	"github.com/coinbase/x402/go/test/mocks/cash"
+	"github.com/coinbase/x402/go/types"
+)
+
+func newCashBackend() *cash.FacilitatorClient {
+	facilitator := x402.Newx402Facilitator()
+	facilitator.Register([]x402.Network{"x402:cash"}, cash.NewSchemeNetworkFacilitator())
+	return cash.NewFacilitatorClient(facilitator)
+}
+
+func cashPayment(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	requirements := cash.BuildPaymentRequirements("merchant", "USD", "1")
+	partial, err := cash.NewSchemeNetworkClient("alice").CreatePaymentPayload(context.Background(), requirements)
+	if err != nil {
+		t.Fatalf("failed to create payload: %v", err)
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Payload:     partial.Payload,
+		Accepted:    requirements,
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	return payloadBytes, requirementsBytes
+}
+
+func TestChaosFacilitatorPassThrough(t *testing.T) {
+	server, chaos := NewChaosServer(newCashBackend(), FaultConfig{})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+	payloadBytes, requirementsBytes := cashPayment(t)
+
+	verify, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+	if !verify.IsValid {
+		t.Fatal("expected valid payment")
+	}
+
+	settle, err := client.Settle(context.Background(), payloadBytes, requirementsBytes)
+	if err != nil {
+		t.Fatalf("unexpected settle error: %v", err)
+	}
+	if !settle.Success {
+		t.Fatal("expected successful settlement")
+	}
+
+	supported, err := client.GetSupported(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected supported error: %v", err)
+	}
+	if len(supported.Kinds) != 1 {
+		t.Fatalf("expected 1 kind, got %d", len(supported.Kinds))
+	}
+
+	stats := chaos.Stats()
+	if stats.Requests != 3 || stats.BackendCalls != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestChaosFacilitatorFiltersSupported(t *testing.T) {
+	server, _ := NewChaosServer(newCashBackend(), FaultConfig{})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+
+	supported, err := client.QuerySupported(context.Background(), x402.SupportedQuery{Scheme: "no-such-scheme"})
+	if err != nil {
+		t.Fatalf("unexpected supported error: %v", err)
+	}
+	if len(supported.Kinds) != 0 {
+		t.Fatalf("expected no kinds, got %+v", supported.Kinds)
+	}
+
+	if _, err := client.QuerySupported(context.Background(), x402.SupportedQuery{Cursor: "bogus"}); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+}
+
+func TestChaosFacilitatorInjectedErrors(t *testing.T) {
+	server, chaos := NewChaosServer(newCashBackend(), FaultConfig{ErrorRate: 1})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+	payloadBytes, requirementsBytes := cashPayment(t)
+
+	if _, err := client.Settle(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("expected settle error")
+	}
+	if _, err := client.GetSupported(context.Background()); err == nil {
+		t.Fatal("expected supported error")
+	}
+
+	stats := chaos.Stats()
+	if stats.InjectedErrors != 2 || stats.BackendCalls != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestChaosFacilitatorMalformedJSON(t *testing.T) {
+	server, _ := NewChaosServer(newCashBackend(), FaultConfig{MalformedRate: 1})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+	payloadBytes, requirementsBytes := cashPayment(t)
+
+	_, err := client.Verify(context.Background(), payloadBytes, requirementsBytes)
+	if err == nil {
+		t.Fatal("expected verify error")
+	}
+	ve, ok := err.(*x402.VerifyError)
+	if !ok || ve.InvalidReason != x402.ErrInvalidResponse {
+		t.Fatalf("expected invalid response error, got %v", err)
+	}
+}
+
+func TestChaosFacilitatorPartialFailureCallsBackend(t *testing.T) {
+	server, chaos := NewChaosServer(newCashBackend(), FaultConfig{
+		PartialFailureRate: 1,
+		Endpoints:          []string{EndpointSettle},
+	})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: server.URL})
+	payloadBytes, requirementsBytes := cashPayment(t)
+
+	if _, err := client.Verify(context.Background(), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("verify should not be affected: %v", err)
+	}
+	if _, err := client.Settle(context.Background(), payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("expected settle error")
+	}
+
+	stats := chaos.Stats()
+	if stats.PartialFailures != 1 || stats.BackendCalls != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestChaosFacilitatorLatency(t *testing.T) {
+	server, _ := NewChaosServer(newCashBackend(), FaultConfig{Latency: 200 * time.Millisecond})
+	defer server.Close()
+
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{
+		URL:     server.URL,
+		Timeout: 50 * time.Millisecond,
+	})
+
+	if _, err := client.GetSupported(context.Background()); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
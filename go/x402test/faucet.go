@@ -0,0 +1,111 @@
+package x402test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// CircleFaucetURL is Circle's testnet faucet API, which drips test USDC
+const CircleFaucetURL = "https://api.circle.com/v1/faucet/drips"
+
+// circleBlockchains names the networks Circle's faucet serves
+var circleBlockchains = map[x402.Network]string{
+	"eip155:84532":    "BASE-SEPOLIA",
+	"eip155:11155111": "ETH-SEPOLIA",
+	"eip155:421614":   "ARB-SEPOLIA",
+	"eip155:11155420": "OP-SEPOLIA",
+	"eip155:43113":    "AVAX-FUJI",
+	"eip155:80002":    "MATIC-AMOY",
+	"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1": "SOL-DEVNET",
+}
+
+// CircleFaucet is an x402.Faucet that funds payers with test USDC from
+// Circle's faucet (e.g. on Base Sepolia). Circle rate-limits drips per
+// address, so it suits occasional funding in CI rather than every test.
+type CircleFaucet struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+// NewCircleFaucet creates a faucet using a Circle API key
+func NewCircleFaucet(apiKey string) *CircleFaucet {
+	return &CircleFaucet{apiKey: apiKey, url: CircleFaucetURL, client: http.DefaultClient}
+}
+
+// WithURL points the faucet at another endpoint (e.g. a test server)
+func (f *CircleFaucet) WithURL(url string) *CircleFaucet {
+	f.url = url
+	return f
+}
+
+// Fund implements x402.Faucet. Circle does not report a transaction.
+func (f *CircleFaucet) Fund(ctx context.Context, network x402.Network, asset string, address string) (string, error) {
+	blockchain, ok := circleBlockchains[network]
+	if !ok {
+		return "", fmt.Errorf("circle faucet does not serve %s", network)
+	}
+	body, err := json.Marshal(map[string]interface{}{"address": address, "blockchain": blockchain, "usdc": true})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.apiKey)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("circle faucet returned %d: %s", resp.StatusCode, message)
+	}
+	return "", nil
+}
+
+// DefaultAirdropLamports is the airdrop a SolanaAirdropFaucet requests (1 SOL)
+const DefaultAirdropLamports = solana.LAMPORTS_PER_SOL
+
+// SolanaAirdropFaucet is an x402.Faucet that airdrops SOL to payers on
+// Solana devnet or testnet. It funds SOL only; pair it with a USDC faucet
+// for payers without test USDC.
+type SolanaAirdropFaucet struct {
+	client   *rpc.Client
+	lamports uint64
+}
+
+// NewSolanaAirdropFaucet creates a faucet airdropping lamports through an
+// RPC endpoint (e.g. rpc.DevNet_RPC); 0 lamports means DefaultAirdropLamports
+func NewSolanaAirdropFaucet(rpcURL string, lamports uint64) *SolanaAirdropFaucet {
+	if lamports == 0 {
+		lamports = DefaultAirdropLamports
+	}
+	return &SolanaAirdropFaucet{client: rpc.New(rpcURL), lamports: lamports}
+}
+
+// Fund implements x402.Faucet, returning the airdrop transaction
+func (f *SolanaAirdropFaucet) Fund(ctx context.Context, network x402.Network, asset string, address string) (string, error) {
+	account, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid solana address %q: %w", address, err)
+	}
+	signature, err := f.client.RequestAirdrop(ctx, account, f.lamports, rpc.CommitmentConfirmed)
+	if err != nil {
+		return "", err
+	}
+	return signature.String(), nil
+}
@@ -0,0 +1,57 @@
+package x402test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCircleFaucet(t *testing.T) {
+	var got map[string]interface{}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	faucet := NewCircleFaucet("key").WithURL(server.URL)
+	if _, err := faucet.Fund(context.Background(), "eip155:84532", "", "0xpayer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "Bearer key" || got["blockchain"] != "BASE-SEPOLIA" || got["address"] != "0xpayer" || got["usdc"] != true {
+		t.Errorf("unexpected faucet request %v (auth %q)", got, auth)
+	}
+
+	if _, err := faucet.Fund(context.Background(), "eip155:8453", "", "0xpayer"); err == nil {
+		t.Error("expected an error for a network the faucet does not serve")
+	}
+}
+
+func TestSolanaAirdropFaucet(t *testing.T) {
+	const signature = "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"
+	var params []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{}   `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		params = req.Params
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": signature})
+	}))
+	defer server.Close()
+
+	payer := "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	tx, err := NewSolanaAirdropFaucet(server.URL, 0).Fund(context.Background(), "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1", "", payer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx != signature || len(params) < 2 || params[0] != payer || params[1] != float64(DefaultAirdropLamports) {
+		t.Errorf("unexpected airdrop %s with params %v", tx, params)
+	}
+}
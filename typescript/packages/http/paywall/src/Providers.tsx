@@ -1,7 +1,8 @@
 import type { ReactNode } from "react";
 import { WagmiProvider, createConfig, http } from "wagmi";
 import { QueryClient, QueryClientProvider } from "@tanstack/react-query";
-import { injected, coinbaseWallet } from "wagmi/connectors";
+import { injected, coinbaseWallet, walletConnect } from "wagmi/connectors";
+import type { CreateConnectorFn } from "wagmi";
 import * as allChains from "viem/chains";
 import type { Chain } from "viem";
 import { isEvmNetwork } from "./paywallUtils";
@@ -22,7 +23,8 @@ const queryClient = new QueryClient();
  * @returns The Providers component
  */
 export function Providers({ children }: ProvidersProps) {
-  const { paymentRequired } = window.x402;
+  const { paymentRequired, appName, appLogo, walletConnectProjectId, walletDiscovery } =
+    window.x402;
 
   // Determine which chain to connect to
   let targetChain: Chain = allChains.base; // Default to Base
@@ -40,15 +42,35 @@ export function Providers({ children }: ProvidersProps) {
     }
   }
 
-  // Create Wagmi config
+  const connectors: CreateConnectorFn[] = [
+    injected(),
+    coinbaseWallet({
+      appName: appName || "x402 Paywall",
+    }),
+  ];
+
+  // WalletConnect v2 requires a project ID, so it is only offered when configured
+  if (walletConnectProjectId) {
+    connectors.push(
+      walletConnect({
+        projectId: walletConnectProjectId,
+        metadata: {
+          name: appName || "x402 Paywall",
+          description: "x402 payment",
+          url: window.location.origin,
+          icons: appLogo ? [appLogo] : [],
+        },
+        showQrModal: true,
+      }),
+    );
+  }
+
+  // Create Wagmi config. EIP-6963 discovery surfaces every installed extension
+  // wallet as its own connector instead of only window.ethereum.
   const config = createConfig({
     chains: [targetChain],
-    connectors: [
-      injected(),
-      coinbaseWallet({
-        appName: window.x402.appName || "x402 Paywall",
-      }),
-    ],
+    connectors,
+    multiInjectedProviderDiscovery: walletDiscovery ?? true,
     transports: {
       [targetChain.id]: http(),
     },
@@ -46,6 +46,8 @@ export const evmPaywall: PaywallNetworkHandler = {
       testnet: config.testnet ?? true,
       appName: config.appName,
       appLogo: config.appLogo,
+      walletConnectProjectId: config.walletConnectProjectId,
+      walletDiscovery: !config.disableWalletDiscovery,
     });
   },
 };
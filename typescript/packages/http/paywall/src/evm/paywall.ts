@@ -42,6 +42,8 @@ interface EvmPaywallOptions {
   testnet: boolean;
   appName?: string;
   appLogo?: string;
+  walletConnectProjectId?: string;
+  walletDiscovery?: boolean;
 }
 
 /**
@@ -54,6 +56,8 @@ interface EvmPaywallOptions {
  * @param options.testnet - Whether to use testnet or mainnet
  * @param options.appName - The name of the application to display in the wallet connection modal
  * @param options.appLogo - The logo of the application to display in the wallet connection modal
+ * @param options.walletConnectProjectId - WalletConnect v2 project ID; enables the WalletConnect connector when set
+ * @param options.walletDiscovery - Whether to discover injected wallets via EIP-6963
  * @returns HTML string for the paywall page
  */
 export function getEvmPaywallHtml(options: EvmPaywallOptions): string {
@@ -63,7 +67,16 @@ export function getEvmPaywallHtml(options: EvmPaywallOptions): string {
     return `<!DOCTYPE html><html><body><h1>EVM Paywall (run pnpm build:paywall to generate full template)</h1></body></html>`;
   }
 
-  const { amount, testnet, paymentRequired, currentUrl, appName, appLogo } = options;
+  const {
+    amount,
+    testnet,
+    paymentRequired,
+    currentUrl,
+    appName,
+    appLogo,
+    walletConnectProjectId,
+    walletDiscovery,
+  } = options;
 
   const logOnTestnet = testnet
     ? "console.log('EVM Payment required initialized:', window.x402);"
@@ -83,6 +96,8 @@ export function getEvmPaywallHtml(options: EvmPaywallOptions): string {
       },
       appName: "${escapeString(appName || "")}",
       appLogo: "${escapeString(appLogo || "")}",
+      walletConnectProjectId: "${escapeString(walletConnectProjectId || "")}",
+      walletDiscovery: ${walletDiscovery ?? true},
     };
     ${logOnTestnet}
   </script>`;
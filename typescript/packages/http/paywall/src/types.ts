@@ -6,6 +6,8 @@ export interface PaywallConfig {
   appLogo?: string;
   currentUrl?: string;
   testnet?: boolean;
+  walletConnectProjectId?: string;
+  disableWalletDiscovery?: boolean;
 }
 
 /**
@@ -9,6 +9,8 @@ declare global {
       currentUrl: string;
       appName?: string;
       appLogo?: string;
+      walletConnectProjectId?: string;
+      walletDiscovery?: boolean;
       config: {
         chainConfig: Record<
           string,